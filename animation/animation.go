@@ -132,6 +132,12 @@ func (anim *Animation) Update(delta float32) {
 	}
 }
 
+// Channels returns the channels of the animation.
+func (anim *Animation) Channels() []IChannel {
+
+	return anim.channels
+}
+
 // AddChannel adds a channel to the animation.
 func (anim *Animation) AddChannel(ch IChannel) {
 
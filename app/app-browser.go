@@ -19,12 +19,13 @@ const canvasId = "g3n-canvas"
 
 // Application
 type Application struct {
-	window.IWindow                    // Embedded WebGLCanvas
-	keyState       *window.KeyState   // Keep track of keyboard state
-	renderer       *renderer.Renderer // Renderer object
-	startTime      time.Time          // Application start time
-	frameStart     time.Time          // Frame start time
-	frameDelta     time.Duration      // Duration of last frame
+	window.IWindow                      // Embedded WebGLCanvas
+	keyState       *window.KeyState     // Keep track of keyboard state
+	gamepadState   *window.GamepadState // Keep track of gamepads state
+	renderer       *renderer.Renderer   // Renderer object
+	startTime      time.Time            // Application start time
+	frameStart     time.Time            // Frame start time
+	frameDelta     time.Duration        // Duration of last frame
 	exit           bool
 	cbid           js.Value
 }
@@ -44,7 +45,8 @@ func App() *Application {
 	}
 	a.IWindow = window.Get()
 	// TODO audio setup here
-	a.keyState = window.NewKeyState(a) // Create KeyState
+	a.keyState = window.NewKeyState(a)         // Create KeyState
+	a.gamepadState = window.NewGamepadState(a) // Create GamepadState
 	// Create renderer and add default shaders
 	a.renderer = renderer.NewRenderer(a.Gls())
 	err = a.renderer.AddDefaultShaders()
@@ -72,6 +74,8 @@ func (a *Application) Run(update func(rend *renderer.Renderer, deltaTime time.Du
 		now := time.Now()
 		a.frameDelta = now.Sub(a.frameStart)
 		a.frameStart = now
+		// Poll gamepads
+		a.IWindow.(*window.WebGlCanvas).PollEvents()
 		// Call user's update function
 		update(a.renderer, a.frameDelta)
 		// Set up new callback if not exiting
@@ -114,6 +118,12 @@ func (a *Application) KeyState() *window.KeyState {
 	return a.keyState
 }
 
+// GamepadState returns the application's GamepadState.
+func (a *Application) GamepadState() *window.GamepadState {
+
+	return a.gamepadState
+}
+
 // RunTime returns the elapsed duration since the call to Run().
 func (a *Application) RunTime() time.Duration {
 
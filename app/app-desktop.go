@@ -24,13 +24,14 @@ const (
 
 // Application
 type Application struct {
-	window.IWindow                    // Embedded GlfwWindow
-	keyState       *window.KeyState   // Keep track of keyboard state
-	renderer       *renderer.Renderer // Renderer object
-	audioDev       *al.Device         // Default audio device
-	startTime      time.Time          // Application start time
-	frameStart     time.Time          // Frame start time
-	frameDelta     time.Duration      // Duration of last frame
+	window.IWindow                      // Embedded GlfwWindow
+	keyState       *window.KeyState     // Keep track of keyboard state
+	gamepadState   *window.GamepadState // Keep track of gamepads state
+	renderer       *renderer.Renderer   // Renderer object
+	audioDev       *al.Device           // Default audio device
+	startTime      time.Time            // Application start time
+	frameStart     time.Time            // Frame start time
+	frameDelta     time.Duration        // Duration of last frame
 }
 
 // App returns the Application singleton, creating it the first time.
@@ -47,8 +48,9 @@ func App() *Application {
 		panic(err)
 	}
 	a.IWindow = window.Get()
-	a.openDefaultAudioDevice()         // Set up audio
-	a.keyState = window.NewKeyState(a) // Create KeyState
+	a.openDefaultAudioDevice()                 // Set up audio
+	a.keyState = window.NewKeyState(a)         // Create KeyState
+	a.gamepadState = window.NewGamepadState(a) // Create GamepadState
 	// Create renderer and add default shaders
 	a.renderer = renderer.NewRenderer(a.Gls())
 	err = a.renderer.AddDefaultShaders()
@@ -118,6 +120,12 @@ func (a *Application) KeyState() *window.KeyState {
 	return a.keyState
 }
 
+// GamepadState returns the application's GamepadState.
+func (a *Application) GamepadState() *window.GamepadState {
+
+	return a.gamepadState
+}
+
 // RunTime returns the elapsed duration since the call to Run().
 func (a *Application) RunTime() time.Duration {
 
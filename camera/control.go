@@ -0,0 +1,275 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"math"
+	"time"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gui"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// ControlAction is a camera control action which can be bound to keys.
+// The meaning of the actions depends on the control, as described by their default key bindings.
+type ControlAction int
+
+// The camera control actions.
+const (
+	ActionForward = ControlAction(iota)
+	ActionBackward
+	ActionLeft
+	ActionRight
+	ActionUp
+	ActionDown
+	ActionRotateLeft
+	ActionRotateRight
+	ActionFast
+)
+
+// KeyBindings maps camera control actions to the keys which perform them.
+type KeyBindings map[ControlAction][]window.Key
+
+// control contains the key, mouse and scroll state shared by the camera controls
+// which are updated every frame, such as FirstPersonControl and FlyControl.
+// The cursor is captured while dragging with the mouse buttons accepted by the
+// capture function and optionally locked, hiding it and providing unlimited movement.
+type control struct {
+	core.Dispatcher                                      // Embedded event dispatcher
+	cam             *Camera                              // Controlled camera
+	enabled         bool                                 // Whether the control responds to input
+	keys            KeyBindings                          // Key bindings of the actions
+	pressed         map[window.Key]bool                  // Keys currently pressed
+	capture         func(button window.MouseButton) bool // Whether pressing the button captures the cursor
+	lock            bool                                 // Lock the cursor while captured
+	captured        bool                                 // Cursor captured
+	locked          bool                                 // Cursor locked by this control
+	button          window.MouseButton                   // Button which captured the cursor
+	cursor          math32.Vector2                       // Last cursor position
+	moved           math32.Vector2                       // Cursor movement since the last update
+	scrolled        float32                              // Vertical scroll since the last update
+}
+
+// initialize initializes the control for the specified camera and default key bindings
+// and subscribes to the GUI manager events.
+func (c *control) initialize(cam *Camera, keys KeyBindings, capture func(button window.MouseButton) bool) {
+
+	c.Dispatcher.Initialize()
+	c.cam = cam
+	c.enabled = true
+	c.keys = keys
+	c.pressed = make(map[window.Key]bool)
+	c.capture = capture
+
+	gui.Manager().SubscribeID(window.OnKeyDown, c, c.onKey)
+	gui.Manager().SubscribeID(window.OnKeyUp, c, c.onKey)
+	gui.Manager().SubscribeID(window.OnMouseDown, c, c.onMouse)
+	gui.Manager().SubscribeID(window.OnMouseUp, c, c.onMouse)
+	gui.Manager().SubscribeID(window.OnScroll, c, c.onScroll)
+	c.SubscribeID(window.OnCursor, c, c.onCursor)
+}
+
+// Dispose releases the cursor and unsubscribes from all events.
+func (c *control) Dispose() {
+
+	c.release()
+	gui.Manager().UnsubscribeID(window.OnKeyDown, c)
+	gui.Manager().UnsubscribeID(window.OnKeyUp, c)
+	gui.Manager().UnsubscribeID(window.OnMouseDown, c)
+	gui.Manager().UnsubscribeID(window.OnMouseUp, c)
+	gui.Manager().UnsubscribeID(window.OnScroll, c)
+	c.UnsubscribeID(window.OnCursor, c)
+}
+
+// SetEnabled sets whether the control responds to input.
+func (c *control) SetEnabled(enabled bool) {
+
+	c.enabled = enabled
+	if !enabled {
+		c.release()
+		c.pressed = make(map[window.Key]bool)
+		c.moved.Zero()
+		c.scrolled = 0
+	}
+}
+
+// Enabled returns whether the control responds to input.
+func (c *control) Enabled() bool {
+
+	return c.enabled
+}
+
+// SetKeys replaces the keys bound to the specified action.
+func (c *control) SetKeys(action ControlAction, keys ...window.Key) {
+
+	c.keys[action] = append([]window.Key(nil), keys...)
+}
+
+// Keys returns the keys bound to the specified action.
+func (c *control) Keys(action ControlAction) []window.Key {
+
+	return c.keys[action]
+}
+
+// active returns whether any of the keys bound to the specified action is pressed.
+func (c *control) active(action ControlAction) bool {
+
+	for _, key := range c.keys[action] {
+		if c.pressed[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// axis returns 1, -1 or 0 depending on which of the specified opposite actions is active.
+func (c *control) axis(pos, neg ControlAction) float32 {
+
+	var v float32
+	if c.active(pos) {
+		v++
+	}
+	if c.active(neg) {
+		v--
+	}
+	return v
+}
+
+// takeMoved returns and clears the cursor movement since the last call.
+func (c *control) takeMoved() math32.Vector2 {
+
+	moved := c.moved
+	c.moved.Zero()
+	return moved
+}
+
+// takeScrolled returns and clears the vertical scroll since the last call.
+func (c *control) takeScrolled() float32 {
+
+	scrolled := c.scrolled
+	c.scrolled = 0
+	return scrolled
+}
+
+// update releases the cursor if the lock was released by the window, as browsers do
+// when Escape is pressed, and returns the specified frame time in seconds.
+func (c *control) update(deltaTime time.Duration) float32 {
+
+	if c.locked && window.Get().CursorMode() != window.CursorDisabled {
+		c.release()
+	}
+	return float32(deltaTime.Seconds())
+}
+
+// release releases the captured cursor.
+func (c *control) release() {
+
+	if !c.captured {
+		return
+	}
+	c.captured = false
+	gui.Manager().SetCursorFocus(nil)
+	if c.locked {
+		c.locked = false
+		window.Get().SetCursorMode(window.CursorNormal)
+	}
+}
+
+// onKey is called when an OnKeyDown/OnKeyUp event is received.
+func (c *control) onKey(evname string, ev interface{}) {
+
+	if !c.enabled {
+		return
+	}
+	kev := ev.(*window.KeyEvent)
+	if evname == window.OnKeyUp {
+		delete(c.pressed, kev.Key)
+		return
+	}
+	if kev.Key == window.KeyEscape && c.locked {
+		c.release()
+		return
+	}
+	c.pressed[kev.Key] = true
+}
+
+// onMouse is called when an OnMouseDown/OnMouseUp event is received.
+func (c *control) onMouse(evname string, ev interface{}) {
+
+	if !c.enabled {
+		return
+	}
+	mev := ev.(*window.MouseEvent)
+	switch evname {
+	case window.OnMouseDown:
+		if c.captured || c.capture == nil || !c.capture(mev.Button) {
+			return
+		}
+		c.captured = true
+		c.button = mev.Button
+		c.cursor.Set(mev.Xpos, mev.Ypos)
+		gui.Manager().SetCursorFocus(c)
+		if c.lock {
+			c.locked = true
+			window.Get().SetCursorMode(window.CursorDisabled)
+		}
+	case window.OnMouseUp:
+		if c.captured && !c.locked && mev.Button == c.button {
+			c.release()
+		}
+	}
+}
+
+// onCursor is called when an OnCursor event is received while the cursor is captured.
+func (c *control) onCursor(evname string, ev interface{}) {
+
+	cev := ev.(*window.CursorEvent)
+	if c.captured {
+		c.moved.X += cev.Xpos - c.cursor.X
+		c.moved.Y += cev.Ypos - c.cursor.Y
+	}
+	c.cursor.Set(cev.Xpos, cev.Ypos)
+}
+
+// onScroll is called when an OnScroll event is received.
+func (c *control) onScroll(evname string, ev interface{}) {
+
+	if c.enabled {
+		c.scrolled += ev.(*window.ScrollEvent).Yoffset
+	}
+}
+
+// smoothing returns the fraction of the remaining distance to a target value which
+// is covered in the specified time in seconds with the specified damping factor.
+// Zero or negative damping covers the whole distance immediately.
+func smoothing(damping, dt float32) float32 {
+
+	if damping <= 0 {
+		return 1
+	}
+	return 1 - float32(math.Exp(float64(-damping*dt)))
+}
+
+// winSize returns the window height or width based on the camera reference axis.
+func (c *control) winSize() float32 {
+
+	width, size := window.Get().GetSize()
+	if c.cam.Axis() == Horizontal {
+		size = width
+	}
+	return float32(size)
+}
+
+// viewScale returns the conversion factor between an on-screen cursor movement
+// and its projection on the plane at the specified distance from the camera.
+func (c *control) viewScale(dist float32) float32 {
+
+	if c.cam.Projection() == Orthographic {
+		return c.cam.Size() / c.winSize()
+	}
+	return 2 * dist * math32.Tan((c.cam.Fov()/2.0)*math32.Pi/180.0) / c.winSize()
+}
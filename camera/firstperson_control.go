@@ -0,0 +1,155 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"time"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// FirstPersonControl is a camera controller which walks on the horizontal plane
+// and looks around with the mouse, as in first-person games.
+// Clicking the left mouse button captures and locks the cursor for mouse look
+// until Escape is pressed. The default key bindings are:
+//
+//	ActionForward:  W, Up          ActionBackward: S, Down
+//	ActionLeft:     A, Left        ActionRight:    D, Right
+//	ActionUp:       Space          ActionDown:     LeftControl
+//	ActionFast:     LeftShift
+//
+// Update must be called every frame to move the camera.
+type FirstPersonControl struct {
+	control                  // Embedded control state
+	yaw       float32        // Current rotation around the vertical axis in radians
+	pitch     float32        // Current rotation around the horizontal axis in radians
+	yawGoal   float32        // Rotation around the vertical axis being approached
+	pitchGoal float32        // Rotation around the horizontal axis being approached
+	velocity  math32.Vector3 // Current velocity
+
+	// Public properties
+	MoveSpeed   float32 // Movement speed in units per second (default is 5)
+	FastFactor  float32 // Speed multiplier while the ActionFast keys are pressed (default is 3)
+	LookSpeed   float32 // Rotation in radians per pixel of cursor movement (default is 0.003)
+	MinPitch    float32 // Minimum pitch angle in radians (default is the equivalent of -85 degrees)
+	MaxPitch    float32 // Maximum pitch angle in radians (default is the equivalent of 85 degrees)
+	InvertY     bool    // Invert the vertical mouse look (default is false)
+	MoveDamping float32 // How fast the velocity approaches the desired velocity (default is 10)
+	LookDamping float32 // How fast the rotation approaches the mouse look rotation (default is 25)
+}
+
+// NewFirstPersonControl creates and returns a pointer to a new first-person control for the specified camera.
+// The initial orientation is taken from the current direction of the camera.
+func NewFirstPersonControl(cam *Camera) *FirstPersonControl {
+
+	fpc := new(FirstPersonControl)
+	fpc.initialize(cam, KeyBindings{
+		ActionForward:  {window.KeyW, window.KeyUp},
+		ActionBackward: {window.KeyS, window.KeyDown},
+		ActionLeft:     {window.KeyA, window.KeyLeft},
+		ActionRight:    {window.KeyD, window.KeyRight},
+		ActionUp:       {window.KeySpace},
+		ActionDown:     {window.KeyLeftControl},
+		ActionFast:     {window.KeyLeftShift},
+	}, func(button window.MouseButton) bool {
+		return button == window.MouseButtonLeft
+	})
+	fpc.lock = true
+
+	fpc.MoveSpeed = 5
+	fpc.FastFactor = 3
+	fpc.LookSpeed = 0.003
+	fpc.MinPitch = -85 * math32.Pi / 180
+	fpc.MaxPitch = 85 * math32.Pi / 180
+	fpc.MoveDamping = 10
+	fpc.LookDamping = 25
+
+	// Get the initial angles from the camera direction
+	dir := math32.Vector3{0, 0, -1}
+	quat := cam.Quaternion()
+	dir.ApplyQuaternion(&quat)
+	fpc.SetAngles(math32.Atan2(-dir.X, -dir.Z), math32.Asin(math32.Clamp(dir.Y, -1, 1)))
+	return fpc
+}
+
+// SetPointerLock sets whether the cursor is locked and hidden during mouse look (default is true).
+// If false, the mouse looks around only while dragging with the left button.
+func (fpc *FirstPersonControl) SetPointerLock(lock bool) {
+
+	fpc.lock = lock
+}
+
+// PointerLock returns whether the cursor is locked and hidden during mouse look.
+func (fpc *FirstPersonControl) PointerLock() bool {
+
+	return fpc.lock
+}
+
+// SetAngles sets the camera yaw (rotation around the vertical axis) and
+// pitch (rotation around the horizontal axis) in radians, without damping.
+func (fpc *FirstPersonControl) SetAngles(yaw, pitch float32) {
+
+	fpc.yaw = yaw
+	fpc.pitch = math32.Clamp(pitch, fpc.MinPitch, fpc.MaxPitch)
+	fpc.yawGoal = fpc.yaw
+	fpc.pitchGoal = fpc.pitch
+	fpc.orient()
+}
+
+// Angles returns the current camera yaw and pitch in radians.
+func (fpc *FirstPersonControl) Angles() (yaw, pitch float32) {
+
+	return fpc.yaw, fpc.pitch
+}
+
+// Update rotates and moves the camera according to the input received since
+// the last update. It should be called every frame with the frame time.
+func (fpc *FirstPersonControl) Update(deltaTime time.Duration) {
+
+	dt := fpc.update(deltaTime)
+
+	// Mouse look
+	moved := fpc.takeMoved()
+	if fpc.InvertY {
+		moved.Y = -moved.Y
+	}
+	fpc.yawGoal -= moved.X * fpc.LookSpeed
+	fpc.pitchGoal = math32.Clamp(fpc.pitchGoal-moved.Y*fpc.LookSpeed, fpc.MinPitch, fpc.MaxPitch)
+	k := smoothing(fpc.LookDamping, dt)
+	fpc.yaw += (fpc.yawGoal - fpc.yaw) * k
+	fpc.pitch += (fpc.pitchGoal - fpc.pitch) * k
+	fpc.orient()
+
+	// Movement on the horizontal plane and vertically
+	sin, cos := math32.Sin(fpc.yaw), math32.Cos(fpc.yaw)
+	forward := fpc.axis(ActionForward, ActionBackward)
+	right := fpc.axis(ActionRight, ActionLeft)
+	goal := math32.Vector3{
+		X: right*cos - forward*sin,
+		Y: fpc.axis(ActionUp, ActionDown),
+		Z: -right*sin - forward*cos,
+	}
+	if goal.Length() > 0 {
+		speed := fpc.MoveSpeed
+		if fpc.active(ActionFast) {
+			speed *= fpc.FastFactor
+		}
+		goal.Normalize().MultiplyScalar(speed)
+	}
+	fpc.velocity.Lerp(&goal, smoothing(fpc.MoveDamping, dt))
+	pos := fpc.cam.Position()
+	pos.Add(fpc.velocity.Clone().MultiplyScalar(dt))
+	fpc.cam.SetPositionVec(&pos)
+}
+
+// orient sets the camera orientation from the current yaw and pitch.
+func (fpc *FirstPersonControl) orient() {
+
+	var yaw, pitch math32.Quaternion
+	yaw.SetFromAxisAngle(&math32.Vector3{0, 1, 0}, fpc.yaw)
+	pitch.SetFromAxisAngle(&math32.Vector3{1, 0, 0}, fpc.pitch)
+	fpc.cam.SetQuaternionQuat(yaw.Multiply(&pitch))
+}
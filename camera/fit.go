@@ -0,0 +1,59 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// FitToBox moves the camera along its current view direction so that the specified box
+// is centered and entirely visible, and returns the center of the box, which is normally
+// used as the OrbitControl target. Perspective cameras are moved to the distance at which
+// the bounding sphere of the box fits the field of view. The size of orthographic cameras
+// is set to fit the bounding sphere, and they are moved to the matching distance.
+func (c *Camera) FitToBox(box math32.Box3) math32.Vector3 {
+
+	pos, target, size := c.fitBox(&box)
+	c.SetPositionVec(&pos)
+	if c.proj == Orthographic {
+		c.SetSize(size)
+	}
+	return target
+}
+
+// fitBox returns the camera position, target and orthographic size which fit the specified box
+// in the view along the current view direction.
+func (c *Camera) fitBox(box *math32.Box3) (pos, target math32.Vector3, size float32) {
+
+	var sphere math32.Sphere
+	box.GetBoundingSphere(&sphere)
+	target = sphere.Center
+	radius := math32.Max(sphere.Radius, c.near)
+
+	// Tangents of the half field-of-view along the reference and the other axis
+	tanRef := math32.Tan(c.fov * math32.Pi / 360)
+	tanOther := tanRef * c.aspect
+	if c.axis == Horizontal {
+		tanOther = tanRef / c.aspect
+	}
+
+	var dist float32
+	if c.proj == Perspective {
+		half := math32.Atan(math32.Min(tanRef, tanOther))
+		dist = radius / math32.Sin(half)
+		size = 2 * dist * tanRef
+	} else {
+		size = 2 * radius * math32.Max(1, tanRef/tanOther)
+		dist = math32.Max(size/(2*tanRef), radius+c.near)
+	}
+
+	// Move back from the target along the view direction
+	quat := c.Quaternion()
+	dir := math32.Vector3{0, 0, -1}
+	dir.ApplyQuaternion(&quat)
+	pos = target
+	pos.Add(dir.MultiplyScalar(-dist))
+	return pos, target, size
+}
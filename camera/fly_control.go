@@ -0,0 +1,140 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"time"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// FlyControl is a camera controller for free flight in all directions.
+// The keys accelerate the camera along its own axes and the velocity decays
+// with damping when they are released. Dragging with the left mouse button
+// turns the camera around its own axes, so there are no pitch limits. The default key bindings are:
+//
+//	ActionForward:    W, Up          ActionBackward:    S, Down
+//	ActionLeft:       A, Left        ActionRight:       D, Right
+//	ActionUp:         R, Space       ActionDown:        F, LeftControl
+//	ActionRotateLeft: Q (roll)       ActionRotateRight: E (roll)
+//	ActionFast:       LeftShift
+//
+// Update must be called every frame to move the camera.
+type FlyControl struct {
+	control                 // Embedded control state
+	velocity math32.Vector3 // Current velocity
+	turn     math32.Vector2 // Mouse look rotation in radians not yet applied
+
+	// Public properties
+	Acceleration float32 // Acceleration in units per second squared (default is 20)
+	MaxSpeed     float32 // Maximum speed in units per second (default is 10)
+	FastFactor   float32 // Acceleration and maximum speed multiplier while the ActionFast keys are pressed (default is 3)
+	Damping      float32 // How fast the velocity decays (default is 2)
+	LookSpeed    float32 // Rotation in radians per pixel of cursor movement (default is 0.003)
+	RollSpeed    float32 // Roll speed in radians per second (default is 1.5)
+	InvertY      bool    // Invert the vertical mouse look (default is false)
+	LookDamping  float32 // How fast the rotation approaches the mouse look rotation (default is 25)
+}
+
+// NewFlyControl creates and returns a pointer to a new fly control for the specified camera.
+func NewFlyControl(cam *Camera) *FlyControl {
+
+	fc := new(FlyControl)
+	fc.initialize(cam, KeyBindings{
+		ActionForward:     {window.KeyW, window.KeyUp},
+		ActionBackward:    {window.KeyS, window.KeyDown},
+		ActionLeft:        {window.KeyA, window.KeyLeft},
+		ActionRight:       {window.KeyD, window.KeyRight},
+		ActionUp:          {window.KeyR, window.KeySpace},
+		ActionDown:        {window.KeyF, window.KeyLeftControl},
+		ActionRotateLeft:  {window.KeyQ},
+		ActionRotateRight: {window.KeyE},
+		ActionFast:        {window.KeyLeftShift},
+	}, func(button window.MouseButton) bool {
+		return button == window.MouseButtonLeft
+	})
+
+	fc.Acceleration = 20
+	fc.MaxSpeed = 10
+	fc.FastFactor = 3
+	fc.Damping = 2
+	fc.LookSpeed = 0.003
+	fc.RollSpeed = 1.5
+	fc.LookDamping = 25
+	return fc
+}
+
+// SetPointerLock sets whether the cursor is locked and hidden during mouse look (default is false).
+// If true, clicking the left button starts mouse look until Escape is pressed.
+func (fc *FlyControl) SetPointerLock(lock bool) {
+
+	fc.lock = lock
+}
+
+// PointerLock returns whether the cursor is locked and hidden during mouse look.
+func (fc *FlyControl) PointerLock() bool {
+
+	return fc.lock
+}
+
+// Velocity returns the current velocity of the camera.
+func (fc *FlyControl) Velocity() math32.Vector3 {
+
+	return fc.velocity
+}
+
+// Stop stops the camera immediately.
+func (fc *FlyControl) Stop() {
+
+	fc.velocity.Zero()
+	fc.turn.Zero()
+}
+
+// Update rotates and moves the camera according to the input received since
+// the last update. It should be called every frame with the frame time.
+func (fc *FlyControl) Update(deltaTime time.Duration) {
+
+	dt := fc.update(deltaTime)
+
+	// Turn around the camera axes
+	moved := fc.takeMoved()
+	if fc.InvertY {
+		moved.Y = -moved.Y
+	}
+	fc.turn.X -= moved.X * fc.LookSpeed
+	fc.turn.Y -= moved.Y * fc.LookSpeed
+	step := fc.turn
+	step.MultiplyScalar(smoothing(fc.LookDamping, dt))
+	fc.turn.Sub(&step)
+	fc.cam.RotateY(step.X)
+	fc.cam.RotateX(step.Y)
+	fc.cam.RotateZ(fc.axis(ActionRotateLeft, ActionRotateRight) * fc.RollSpeed * dt)
+
+	// Accelerate along the camera axes
+	thrust := math32.Vector3{
+		X: fc.axis(ActionRight, ActionLeft),
+		Y: fc.axis(ActionUp, ActionDown),
+		Z: -fc.axis(ActionForward, ActionBackward),
+	}
+	maxSpeed := fc.MaxSpeed
+	if thrust.Length() > 0 {
+		accel := fc.Acceleration
+		if fc.active(ActionFast) {
+			accel *= fc.FastFactor
+			maxSpeed *= fc.FastFactor
+		}
+		quat := fc.cam.Quaternion()
+		thrust.Normalize().ApplyQuaternion(&quat).MultiplyScalar(accel * dt)
+		fc.velocity.Add(&thrust)
+	}
+	fc.velocity.MultiplyScalar(1 - smoothing(fc.Damping, dt))
+	if fc.velocity.Length() > maxSpeed {
+		fc.velocity.SetLength(maxSpeed)
+	}
+	pos := fc.cam.Position()
+	pos.Add(fc.velocity.Clone().MultiplyScalar(dt))
+	fc.cam.SetPositionVec(&pos)
+}
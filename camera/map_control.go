@@ -0,0 +1,251 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"time"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// MapControl is a camera controller for map views and real-time strategy games.
+// The camera looks down at a target point on the horizontal plane with a fixed pitch
+// and can be panned with the keys, by dragging with the right mouse button or by moving
+// the cursor to the edges of the window, rotated around the vertical axis by dragging with
+// the middle button and zoomed by scrolling. The default key bindings are:
+//
+//	ActionForward:    W, Up          ActionBackward:    S, Down
+//	ActionLeft:       A, Left        ActionRight:       D, Right
+//	ActionRotateLeft: Q              ActionRotateRight: E
+//	ActionUp:         PageUp (zoom out)
+//	ActionDown:       PageDown (zoom in)
+//	ActionFast:       LeftShift
+//
+// Update must be called every frame to move the camera.
+type MapControl struct {
+	control                     // Embedded control state
+	target       math32.Vector3 // Current target point
+	heading      float32        // Current rotation around the vertical axis in radians
+	distance     float32        // Current distance from the target
+	targetGoal   math32.Vector3 // Target point being approached
+	headingGoal  float32        // Rotation being approached
+	distanceGoal float32        // Distance being approached
+	bounded      bool           // Whether the target is limited to the bounds
+	boundsMin    math32.Vector3 // Minimum target coordinates
+	boundsMax    math32.Vector3 // Maximum target coordinates
+	edge         math32.Vector2 // Last cursor position in the window for edge panning
+	hasEdge      bool           // Whether the cursor position is known
+
+	// Public properties
+	Pitch        float32 // Angle of the view direction below the horizon in radians (default is the equivalent of 50 degrees)
+	MinDistance  float32 // Minimum distance from target (default is 5)
+	MaxDistance  float32 // Maximum distance from target (default is 200)
+	PanSpeed     float32 // Key and edge panning speed in distances per second (default is 1)
+	FastFactor   float32 // Panning speed multiplier while the ActionFast keys are pressed (default is 3)
+	EdgeSize     float32 // Width in pixels of the window edges which pan the camera, 0 to disable (default is 16)
+	KeyRotSpeed  float32 // Rotation speed in radians per second of the rotation keys (default is 1.5)
+	ZoomSpeed    float32 // Zoom speed factor (default is 0.1)
+	KeyZoomSpeed float32 // Zoom speed of the zoom keys (default is 1)
+	Damping      float32 // How fast the camera approaches the desired position (default is 10)
+}
+
+// NewMapControl creates and returns a pointer to a new map control for the specified camera.
+// The camera is positioned looking at the origin from the default distance of 20.
+func NewMapControl(cam *Camera) *MapControl {
+
+	mc := new(MapControl)
+	mc.initialize(cam, KeyBindings{
+		ActionForward:     {window.KeyW, window.KeyUp},
+		ActionBackward:    {window.KeyS, window.KeyDown},
+		ActionLeft:        {window.KeyA, window.KeyLeft},
+		ActionRight:       {window.KeyD, window.KeyRight},
+		ActionRotateLeft:  {window.KeyQ},
+		ActionRotateRight: {window.KeyE},
+		ActionUp:          {window.KeyPageUp},
+		ActionDown:        {window.KeyPageDown},
+		ActionFast:        {window.KeyLeftShift},
+	}, func(button window.MouseButton) bool {
+		return button == window.MouseButtonRight || button == window.MouseButtonMiddle
+	})
+
+	mc.Pitch = 50 * math32.Pi / 180
+	mc.MinDistance = 5
+	mc.MaxDistance = 200
+	mc.PanSpeed = 1
+	mc.FastFactor = 3
+	mc.EdgeSize = 16
+	mc.KeyRotSpeed = 1.5
+	mc.ZoomSpeed = 0.1
+	mc.KeyZoomSpeed = 1
+	mc.Damping = 10
+
+	// Edge panning needs the cursor position even when it is over the GUI
+	window.Get().SubscribeID(window.OnCursor, mc, mc.onWindowCursor)
+
+	mc.SetDistance(20)
+	return mc
+}
+
+// Dispose unsubscribes from all events.
+func (mc *MapControl) Dispose() {
+
+	mc.control.Dispose()
+	window.Get().UnsubscribeID(window.OnCursor, mc)
+}
+
+// Target returns the current target point.
+func (mc *MapControl) Target() math32.Vector3 {
+
+	return mc.target
+}
+
+// SetTarget moves the target point to the specified position without damping.
+func (mc *MapControl) SetTarget(v math32.Vector3) {
+
+	mc.targetGoal = v
+	mc.clampTarget()
+	mc.target = mc.targetGoal
+	mc.place()
+}
+
+// MoveTo moves the target point smoothly to the specified position.
+func (mc *MapControl) MoveTo(v math32.Vector3) {
+
+	mc.targetGoal = v
+	mc.clampTarget()
+}
+
+// Heading returns the current rotation of the camera around the vertical axis in radians.
+func (mc *MapControl) Heading() float32 {
+
+	return mc.heading
+}
+
+// SetHeading sets the rotation of the camera around the vertical axis in radians without damping.
+func (mc *MapControl) SetHeading(heading float32) {
+
+	mc.heading = heading
+	mc.headingGoal = heading
+	mc.place()
+}
+
+// Distance returns the current distance of the camera from the target point.
+func (mc *MapControl) Distance() float32 {
+
+	return mc.distance
+}
+
+// SetDistance sets the distance of the camera from the target point without damping.
+func (mc *MapControl) SetDistance(dist float32) {
+
+	mc.distance = math32.Clamp(dist, mc.MinDistance, mc.MaxDistance)
+	mc.distanceGoal = mc.distance
+	mc.place()
+}
+
+// SetBounds limits the target point to the box with the specified minimum and maximum coordinates.
+func (mc *MapControl) SetBounds(min, max math32.Vector3) {
+
+	mc.bounded = true
+	mc.boundsMin = min
+	mc.boundsMax = max
+	mc.clampTarget()
+}
+
+// ClearBounds removes the limits of the target point.
+func (mc *MapControl) ClearBounds() {
+
+	mc.bounded = false
+}
+
+// Update moves the camera according to the input received since the last update.
+// It should be called every frame with the frame time.
+func (mc *MapControl) Update(deltaTime time.Duration) {
+
+	dt := mc.update(deltaTime)
+	sin, cos := math32.Sin(mc.headingGoal), math32.Cos(mc.headingGoal)
+	forward := math32.Vector3{X: -sin, Z: -cos}
+	right := math32.Vector3{X: cos, Z: -sin}
+
+	// Key and edge panning
+	pan := math32.Vector2{X: mc.axis(ActionRight, ActionLeft), Y: mc.axis(ActionForward, ActionBackward)}
+	if mc.EdgeSize > 0 && mc.hasEdge && mc.enabled {
+		width, height := window.Get().GetSize()
+		if mc.edge.X >= 0 && mc.edge.X < mc.EdgeSize {
+			pan.X--
+		} else if mc.edge.X <= float32(width) && mc.edge.X > float32(width)-mc.EdgeSize {
+			pan.X++
+		}
+		if mc.edge.Y >= 0 && mc.edge.Y < mc.EdgeSize {
+			pan.Y++
+		} else if mc.edge.Y <= float32(height) && mc.edge.Y > float32(height)-mc.EdgeSize {
+			pan.Y--
+		}
+	}
+	if pan.Length() > 0 {
+		speed := mc.PanSpeed * mc.distanceGoal * dt
+		if mc.active(ActionFast) {
+			speed *= mc.FastFactor
+		}
+		pan.Normalize().MultiplyScalar(speed)
+	}
+
+	// Mouse dragging grabs the ground with the right button and rotates with the middle button
+	moved := mc.takeMoved()
+	if mc.captured && mc.button == window.MouseButtonRight {
+		c := mc.viewScale(mc.distanceGoal)
+		pan.X -= moved.X * c
+		pan.Y += moved.Y * c / math32.Sin(mc.Pitch)
+	} else if mc.captured {
+		mc.headingGoal -= moved.X * 2 * math32.Pi / mc.winSize()
+	}
+	mc.targetGoal.Add(right.MultiplyScalar(pan.X))
+	mc.targetGoal.Add(forward.MultiplyScalar(pan.Y))
+	mc.clampTarget()
+
+	// Rotation and zoom
+	mc.headingGoal += mc.axis(ActionRotateLeft, ActionRotateRight) * mc.KeyRotSpeed * dt
+	zoom := 1 - mc.takeScrolled()*mc.ZoomSpeed + mc.axis(ActionUp, ActionDown)*mc.KeyZoomSpeed*dt
+	mc.distanceGoal = math32.Clamp(mc.distanceGoal*math32.Max(zoom, 0.1), mc.MinDistance, mc.MaxDistance)
+
+	// Approach the goals
+	k := smoothing(mc.Damping, dt)
+	mc.target.Lerp(&mc.targetGoal, k)
+	mc.heading += (mc.headingGoal - mc.heading) * k
+	mc.distance += (mc.distanceGoal - mc.distance) * k
+	mc.place()
+}
+
+// place positions the camera looking at the target from the current heading and distance.
+func (mc *MapControl) place() {
+
+	sin, cos := math32.Sin(mc.heading), math32.Cos(mc.heading)
+	horizontal := mc.distance * math32.Cos(mc.Pitch)
+	pos := math32.Vector3{
+		X: mc.target.X + horizontal*sin,
+		Y: mc.target.Y + mc.distance*math32.Sin(mc.Pitch),
+		Z: mc.target.Z + horizontal*cos,
+	}
+	mc.cam.SetPositionVec(&pos)
+	mc.cam.LookAt(&mc.target, &math32.Vector3{Y: 1})
+	mc.cam.UpdateSize(mc.distance)
+}
+
+// clampTarget limits the target point being approached to the bounds.
+func (mc *MapControl) clampTarget() {
+
+	if mc.bounded {
+		mc.targetGoal.Clamp(&mc.boundsMin, &mc.boundsMax)
+	}
+}
+
+// onWindowCursor is called when an OnCursor event is received from the window.
+func (mc *MapControl) onWindowCursor(evname string, ev interface{}) {
+
+	cev := ev.(*window.CursorEvent)
+	mc.edge.Set(cev.Xpos, cev.Ypos)
+	mc.hasEdge = !mc.locked
+}
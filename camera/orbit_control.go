@@ -36,14 +36,16 @@ const (
 )
 
 // OrbitControl is a camera controller that allows orbiting a target point while looking at it.
-// It allows the user to rotate, zoom, and pan a 3D scene using the mouse or keyboard.
+// It allows the user to rotate, zoom, and pan a 3D scene using the mouse, keyboard or touch gestures:
+// a single finger drag rotates, pinching zooms and a two finger drag pans.
 type OrbitControl struct {
-	core.Dispatcher                // Embedded event dispatcher
-	cam             *Camera        // Controlled camera
-	target          math32.Vector3 // Camera target, around which the camera orbits
-	up              math32.Vector3 // The orbit axis (Y+)
-	enabled         OrbitEnabled   // Which controls are enabled
-	state           orbitState     // Current control state
+	core.Dispatcher                           // Embedded event dispatcher
+	cam             *Camera                   // Controlled camera
+	target          math32.Vector3            // Camera target, around which the camera orbits
+	up              math32.Vector3            // The orbit axis (Y+)
+	enabled         OrbitEnabled              // Which controls are enabled
+	state           orbitState                // Current control state
+	gestures        *window.GestureRecognizer // Touch gesture recognizer
 
 	// Public properties
 	MinDistance     float32 // Minimum distance from target (default is 1)
@@ -93,6 +95,10 @@ func NewOrbitControl(cam *Camera) *OrbitControl {
 	gui.Manager().SubscribeID(window.OnKeyDown, &oc, oc.onKey)
 	gui.Manager().SubscribeID(window.OnKeyRepeat, &oc, oc.onKey)
 	oc.SubscribeID(window.OnCursor, &oc, oc.onCursor)
+	oc.gestures = window.NewGestureRecognizer(window.Get())
+	oc.gestures.Subscribe(window.OnDrag, oc.onGesture)
+	oc.gestures.Subscribe(window.OnPinch, oc.onGesture)
+	oc.gestures.Subscribe(window.OnPan, oc.onGesture)
 
 	return oc
 }
@@ -106,6 +112,7 @@ func (oc *OrbitControl) Dispose() {
 	gui.Manager().UnsubscribeID(window.OnKeyDown, &oc)
 	gui.Manager().UnsubscribeID(window.OnKeyRepeat, &oc)
 	oc.UnsubscribeID(window.OnCursor, &oc)
+	oc.gestures.Dispose()
 }
 
 // Reset resets the orbit control.
@@ -276,6 +283,28 @@ func (oc *OrbitControl) onScroll(evname string, ev interface{}) {
 	}
 }
 
+// onGesture is called when an OnDrag/OnPinch/OnPan touch gesture event is received.
+func (oc *OrbitControl) onGesture(evname string, ev interface{}) {
+
+	gev := ev.(*window.GestureEvent)
+	switch evname {
+	case window.OnDrag:
+		if oc.enabled&OrbitRot != 0 {
+			c := -2 * math32.Pi * oc.RotSpeed / oc.winSize()
+			oc.Rotate(c*gev.DX, c*gev.DY)
+		}
+	case window.OnPinch:
+		// Spreading the fingers apart moves the camera closer by the same ratio
+		if oc.enabled&OrbitZoom != 0 && gev.Scale > 0 {
+			oc.Zoom(10 * (1/gev.Scale - 1))
+		}
+	case window.OnPan:
+		if oc.enabled&OrbitPan != 0 {
+			oc.Pan(gev.DX, gev.DY)
+		}
+	}
+}
+
 // onKey is called when an OnKeyDown/OnKeyRepeat event is received.
 func (oc *OrbitControl) onKey(evname string, ev interface{}) {
 
@@ -0,0 +1,159 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"sort"
+	"time"
+
+	"github.com/g3n/engine/math32"
+)
+
+// Path moves a camera along a curve, such as the splines created by math32.NewCatmullRomSpline,
+// at constant speed modulated by the easing function. By default the camera looks ahead
+// along the curve, but it can also look at a fixed target or at a point moving along
+// another curve. If an OrbitControl is specified, its target follows the camera target.
+// Update must be called every frame until the path finishes.
+type Path struct {
+	timeline                   // Embedded timeline
+	points    []math32.Vector3 // Points of the camera curve
+	lengths   []float32        // Curve length up to each point
+	target    *math32.Vector3  // Fixed target (may be nil)
+	tpoints   []math32.Vector3 // Points of the target curve (may be nil)
+	tlengths  []float32        // Target curve length up to each point
+	lookAhead float32          // Fraction of the curve ahead of the camera which it looks at
+}
+
+// NewPath creates and returns a pointer to a new path of the specified camera along
+// the specified curve with the specified duration. The default easing is EaseLinear.
+func NewPath(cam *Camera, curve *math32.Curve, duration time.Duration) *Path {
+
+	p := new(Path)
+	p.initialize(cam, duration)
+	p.easing = EaseLinear
+	p.points, p.lengths = curvePoints(curve)
+	p.lookAhead = 0.01
+	return p
+}
+
+// SetEasing sets the easing function.
+func (p *Path) SetEasing(easing Easing) *Path {
+
+	p.easing = easing
+	return p
+}
+
+// SetLoop sets whether the camera restarts from the beginning of the curve when it reaches its end.
+func (p *Path) SetLoop(loop bool) *Path {
+
+	p.loop = loop
+	return p
+}
+
+// SetOrbitControl sets the orbit control whose target follows the camera target.
+func (p *Path) SetOrbitControl(oc *OrbitControl) *Path {
+
+	p.orbit = oc
+	return p
+}
+
+// SetTarget sets a fixed target for the camera to look at.
+func (p *Path) SetTarget(target math32.Vector3) *Path {
+
+	p.target = &target
+	p.tpoints = nil
+	return p
+}
+
+// SetTargetCurve sets a curve along which the camera target moves in step with the camera.
+func (p *Path) SetTargetCurve(curve *math32.Curve) *Path {
+
+	p.target = nil
+	p.tpoints, p.tlengths = curvePoints(curve)
+	return p
+}
+
+// SetLookAhead sets the fraction of the curve length ahead of the camera which it looks at
+// when there is no target (default is 0.01).
+func (p *Path) SetLookAhead(fraction float32) *Path {
+
+	p.target = nil
+	p.tpoints = nil
+	p.lookAhead = fraction
+	return p
+}
+
+// Update advances the camera along the path by the specified time.
+// It returns whether the path is still running.
+func (p *Path) Update(deltaTime time.Duration) bool {
+
+	if !p.running || len(p.points) == 0 {
+		return false
+	}
+	t := p.advance(deltaTime)
+	pos := pointAt(p.points, p.lengths, t)
+	p.cam.SetPositionVec(&pos)
+
+	var target math32.Vector3
+	switch {
+	case p.target != nil:
+		target = *p.target
+	case p.tpoints != nil:
+		target = pointAt(p.tpoints, p.tlengths, t)
+	default:
+		// Look ahead, or back from the end of the curve
+		ahead := t + p.lookAhead
+		if p.loop && ahead > 1 {
+			ahead--
+		}
+		if ahead <= 1 {
+			target = pointAt(p.points, p.lengths, ahead)
+		} else {
+			back := pointAt(p.points, p.lengths, t-p.lookAhead)
+			target = pos
+			target.MultiplyScalar(2).Sub(&back)
+		}
+	}
+	if p.orbit != nil {
+		p.orbit.SetTarget(target)
+	}
+	if !target.Equals(&pos) {
+		p.cam.LookAt(&target, &math32.Vector3{Y: 1})
+	}
+	p.finish()
+	return p.running
+}
+
+// curvePoints returns the points of the specified curve and the curve length up to each point.
+func curvePoints(curve *math32.Curve) ([]math32.Vector3, []float32) {
+
+	points := curve.GetPoints()
+	lengths := make([]float32, len(points))
+	for i := 1; i < len(points); i++ {
+		lengths[i] = lengths[i-1] + points[i].DistanceTo(&points[i-1])
+	}
+	return points, lengths
+}
+
+// pointAt returns the point at the specified fraction of the length of the curve with the specified
+// points and lengths up to each point.
+func pointAt(points []math32.Vector3, lengths []float32, t float32) math32.Vector3 {
+
+	last := len(points) - 1
+	if t <= 0 || lengths[last] == 0 {
+		return points[0]
+	}
+	if t >= 1 {
+		return points[last]
+	}
+	dist := t * lengths[last]
+	i := sort.Search(len(lengths), func(i int) bool { return lengths[i] >= dist })
+	seg := lengths[i] - lengths[i-1]
+	point := points[i-1]
+	if seg > 0 {
+		point.Lerp(&points[i], (dist-lengths[i-1])/seg)
+	}
+	return point
+}
@@ -0,0 +1,104 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Stereo is a stereo camera rig which derives the left and right eye views from a camera.
+// The eyes are separated horizontally and use asymmetric (off-axis) perspective frustums
+// which converge at the focal distance, where objects appear at the screen depth.
+// The eyes are normally rendered with renderer.RenderStereo.
+type Stereo struct {
+	cam           *Camera   // Camera from which the eye views are derived
+	left          stereoEye // Left eye
+	right         stereoEye // Right eye
+	EyeSeparation float32   // Distance between the eyes (default is 0.064)
+	FocalDistance float32   // Distance from the camera of the plane with zero parallax (default is 10)
+}
+
+// stereoEye is an ICamera with the view and projection matrices computed by the Stereo rig.
+type stereoEye struct {
+	view math32.Matrix4
+	proj math32.Matrix4
+}
+
+// ViewMatrix returns the view matrix of the eye.
+func (e *stereoEye) ViewMatrix(m *math32.Matrix4) {
+
+	*m = e.view
+}
+
+// ProjMatrix returns the projection matrix of the eye.
+func (e *stereoEye) ProjMatrix(m *math32.Matrix4) {
+
+	*m = e.proj
+}
+
+// NewStereo creates and returns a pointer to a new stereo rig for the specified camera.
+func NewStereo(cam *Camera) *Stereo {
+
+	s := new(Stereo)
+	s.cam = cam
+	s.EyeSeparation = 0.064
+	s.FocalDistance = 10
+	return s
+}
+
+// Camera returns the camera from which the eye views are derived.
+func (s *Stereo) Camera() *Camera {
+
+	return s.cam
+}
+
+// Left returns the left eye camera, updated by the last call to Update.
+func (s *Stereo) Left() ICamera {
+
+	return &s.left
+}
+
+// Right returns the right eye camera, updated by the last call to Update.
+func (s *Stereo) Right() ICamera {
+
+	return &s.right
+}
+
+// Update computes the eye views from the current camera transform and parameters
+// for the specified aspect ratio (width/height) of each eye view.
+func (s *Stereo) Update(aspect float32) {
+
+	// The eyes are displaced along the camera X axis
+	var view, offset math32.Matrix4
+	s.cam.ViewMatrix(&view)
+	half := s.EyeSeparation / 2
+	offset.MakeTranslation(half, 0, 0)
+	s.left.view.MultiplyMatrices(&offset, &view)
+	offset.MakeTranslation(-half, 0, 0)
+	s.right.view.MultiplyMatrices(&offset, &view)
+
+	// Orthographic projections have no parallax
+	c := s.cam
+	if c.proj == Orthographic {
+		size := c.size / 2
+		h, w := size, size*aspect
+		if c.axis == Horizontal {
+			h, w = size/aspect, size
+		}
+		s.left.proj.MakeOrthographic(-w, w, h, -h, c.near, c.far)
+		s.right.proj = s.left.proj
+		return
+	}
+
+	// The frustums are shifted horizontally so that they coincide at the focal distance
+	t := c.near * math32.Tan(math32.DegToRad(c.fov*0.5))
+	ymax, xmax := t, t*aspect
+	if c.axis == Horizontal {
+		ymax, xmax = t/aspect, t
+	}
+	shift := half * c.near / math32.Max(s.FocalDistance, c.near)
+	s.left.proj.MakeFrustum(-xmax+shift, xmax+shift, -ymax, ymax, c.near, c.far)
+	s.right.proj.MakeFrustum(-xmax-shift, xmax-shift, -ymax, ymax, c.near, c.far)
+}
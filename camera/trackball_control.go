@@ -0,0 +1,189 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"math"
+	"time"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// TrackballControl is a camera controller which rotates the camera around a target point
+// like a trackball. Unlike OrbitControl there is no fixed up axis: the camera rotates around
+// axes perpendicular to the cursor movement and its up vector rotates with it, so it can
+// go over the poles without gimbal lock. The motion continues with damping after the
+// mouse is released. Dragging with the left button rotates, dragging with the right button
+// pans and scrolling zooms. The default key bindings are:
+//
+//	ActionLeft, ActionRight:       Left, Right (rotate horizontally)
+//	ActionForward, ActionBackward: Up, Down (rotate vertically)
+//	ActionUp, ActionDown:          PageUp, PageDown (zoom in and out)
+//
+// Update must be called every frame to move the camera.
+type TrackballControl struct {
+	control                 // Embedded control state
+	target   math32.Vector3 // Camera target, around which the camera rotates
+	rotation math32.Vector2 // Rotation in radians not yet applied
+	pan      math32.Vector2 // Pan in pixels not yet applied
+	zoom     float32        // Zoom not yet applied
+
+	// Public properties
+	MinDistance  float32 // Minimum distance from target (default is 1)
+	MaxDistance  float32 // Maximum distance from target (default is infinity)
+	RotSpeed     float32 // Rotation speed factor (default is 1)
+	ZoomSpeed    float32 // Zoom speed factor (default is 0.1)
+	KeyRotSpeed  float32 // Rotation speed in radians per second of the rotation keys (default is 1.5)
+	KeyZoomSpeed float32 // Zoom speed of the zoom keys (default is 1)
+	Damping      float32 // How fast the motion stops (default is 8)
+}
+
+// NewTrackballControl creates and returns a pointer to a new trackball control for the specified camera.
+func NewTrackballControl(cam *Camera) *TrackballControl {
+
+	tc := new(TrackballControl)
+	tc.initialize(cam, KeyBindings{
+		ActionForward:  {window.KeyUp},
+		ActionBackward: {window.KeyDown},
+		ActionLeft:     {window.KeyLeft},
+		ActionRight:    {window.KeyRight},
+		ActionUp:       {window.KeyPageUp},
+		ActionDown:     {window.KeyPageDown},
+	}, func(button window.MouseButton) bool {
+		return button == window.MouseButtonLeft || button == window.MouseButtonRight
+	})
+
+	tc.MinDistance = 1.0
+	tc.MaxDistance = float32(math.Inf(1))
+	tc.RotSpeed = 1.0
+	tc.ZoomSpeed = 0.1
+	tc.KeyRotSpeed = 1.5
+	tc.KeyZoomSpeed = 1.0
+	tc.Damping = 8
+	return tc
+}
+
+// Target returns the current target.
+func (tc *TrackballControl) Target() math32.Vector3 {
+
+	return tc.target
+}
+
+// SetTarget sets the target and points the camera at it.
+func (tc *TrackballControl) SetTarget(v math32.Vector3) {
+
+	tc.target = v
+	up := tc.up()
+	tc.cam.LookAt(&tc.target, &up)
+}
+
+// Stop stops the motion in progress.
+func (tc *TrackballControl) Stop() {
+
+	tc.rotation.Zero()
+	tc.pan.Zero()
+	tc.zoom = 0
+}
+
+// Rotate rotates the camera around the target by the specified angles in radians,
+// corresponding to horizontal and vertical cursor movements.
+func (tc *TrackballControl) Rotate(dx, dy float32) {
+
+	angle := math32.Sqrt(dx*dx + dy*dy)
+	if angle == 0 {
+		return
+	}
+
+	// The rotation axis is perpendicular to the movement direction and to the view direction
+	quat := tc.cam.Quaternion()
+	right := math32.Vector3{1, 0, 0}
+	right.ApplyQuaternion(&quat).MultiplyScalar(dx)
+	up := tc.up()
+	move := up.Clone().MultiplyScalar(-dy)
+	move.Add(&right)
+	position := tc.cam.Position()
+	eye := position.Clone().Sub(&tc.target)
+	var axis math32.Vector3
+	axis.CrossVectors(move, eye).Normalize()
+
+	// Rotate the camera position and up vector
+	var rot math32.Quaternion
+	rot.SetFromAxisAngle(&axis, angle)
+	eye.ApplyQuaternion(&rot)
+	up.ApplyQuaternion(&rot)
+	tc.cam.SetPositionVec(eye.Add(&tc.target))
+	tc.cam.LookAt(&tc.target, &up)
+}
+
+// Zoom moves the camera closer or farther from the target the specified amount
+// and also updates the camera's orthographic size to match.
+func (tc *TrackballControl) Zoom(delta float32) {
+
+	position := tc.cam.Position()
+	eye := position.Clone().Sub(&tc.target)
+	dist := math32.Clamp(eye.Length()*(1+delta), tc.MinDistance, tc.MaxDistance)
+	eye.SetLength(dist)
+	tc.cam.UpdateSize(dist)
+	tc.cam.SetPositionVec(eye.Add(&tc.target))
+}
+
+// Pan moves the camera and target the specified amount in pixels on the plane perpendicular to the viewing direction.
+func (tc *TrackballControl) Pan(dx, dy float32) {
+
+	position := tc.cam.Position()
+	c := tc.viewScale(position.DistanceTo(&tc.target))
+	quat := tc.cam.Quaternion()
+	right := math32.Vector3{1, 0, 0}
+	right.ApplyQuaternion(&quat).MultiplyScalar(-dx * c)
+	pan := tc.up()
+	pan.MultiplyScalar(dy * c).Add(&right)
+	tc.cam.SetPositionVec(position.Add(&pan))
+	tc.target.Add(&pan)
+}
+
+// Update applies the input received since the last update with damping.
+// It should be called every frame with the frame time.
+func (tc *TrackballControl) Update(deltaTime time.Duration) {
+
+	dt := tc.update(deltaTime)
+
+	// Accumulate the mouse, scroll and key input
+	moved := tc.takeMoved()
+	if tc.captured && tc.button == window.MouseButtonRight {
+		tc.pan.Add(&moved)
+	} else {
+		tc.rotation.Add(moved.MultiplyScalar(2 * math32.Pi * tc.RotSpeed / tc.winSize()))
+	}
+	tc.rotation.X += tc.axis(ActionRight, ActionLeft) * tc.KeyRotSpeed * dt
+	tc.rotation.Y += tc.axis(ActionBackward, ActionForward) * tc.KeyRotSpeed * dt
+	tc.zoom -= tc.takeScrolled() * tc.ZoomSpeed
+	tc.zoom -= tc.axis(ActionUp, ActionDown) * tc.KeyZoomSpeed * dt
+
+	// Apply part of the pending motion
+	k := smoothing(tc.Damping, dt)
+	rot := tc.rotation
+	rot.MultiplyScalar(k)
+	tc.rotation.Sub(&rot)
+	tc.Rotate(rot.X, rot.Y)
+	pan := tc.pan
+	pan.MultiplyScalar(k)
+	tc.pan.Sub(&pan)
+	tc.Pan(pan.X, pan.Y)
+	zoom := tc.zoom * k
+	tc.zoom -= zoom
+	if zoom != 0 {
+		tc.Zoom(zoom)
+	}
+}
+
+// up returns the current up vector of the camera.
+func (tc *TrackballControl) up() math32.Vector3 {
+
+	quat := tc.cam.Quaternion()
+	up := math32.Vector3{0, 1, 0}
+	up.ApplyQuaternion(&quat)
+	return up
+}
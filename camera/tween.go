@@ -0,0 +1,254 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package camera
+
+import (
+	"time"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// OnAnimationEnd is the event dispatched by Tween and Path when they finish.
+const OnAnimationEnd = "camera.OnAnimationEnd"
+
+// Easing maps the linear progress of an animation, from 0 to 1, to the eased progress.
+type Easing func(t float32) float32
+
+// Easing functions
+var (
+	EaseLinear    Easing = func(t float32) float32 { return t }
+	EaseInQuad    Easing = func(t float32) float32 { return t * t }
+	EaseOutQuad   Easing = func(t float32) float32 { return t * (2 - t) }
+	EaseInCubic   Easing = func(t float32) float32 { return t * t * t }
+	EaseOutCubic  Easing = func(t float32) float32 { t--; return t*t*t + 1 }
+	EaseInOutSine Easing = func(t float32) float32 { return (1 - math32.Cos(math32.Pi*t)) / 2 }
+	EaseInOutQuad Easing = func(t float32) float32 {
+		if t < 0.5 {
+			return 2 * t * t
+		}
+		return -1 + (4-2*t)*t
+	}
+	EaseInOutCubic Easing = func(t float32) float32 {
+		if t < 0.5 {
+			return 4 * t * t * t
+		}
+		t = 2*t - 2
+		return t*t*t/2 + 1
+	}
+)
+
+// timeline contains the timing state shared by the camera animations.
+type timeline struct {
+	core.Dispatcher               // Embedded event dispatcher
+	cam             *Camera       // Animated camera
+	orbit           *OrbitControl // Orbit control whose target follows the animation (may be nil)
+	duration        time.Duration // Duration of the animation
+	elapsed         time.Duration // Time elapsed since the start of the animation
+	easing          Easing        // Easing function
+	loop            bool          // Whether the animation restarts when it finishes
+	running         bool          // Whether the animation is running
+}
+
+// initialize initializes the timeline for the specified camera and duration.
+func (tl *timeline) initialize(cam *Camera, duration time.Duration) {
+
+	tl.Dispatcher.Initialize()
+	tl.cam = cam
+	tl.duration = duration
+	tl.easing = EaseInOutCubic
+	tl.running = true
+}
+
+// Running returns whether the animation is running.
+func (tl *timeline) Running() bool {
+
+	return tl.running
+}
+
+// Stop stops the animation where it is, without dispatching OnAnimationEnd.
+func (tl *timeline) Stop() {
+
+	tl.running = false
+}
+
+// advance advances the animation by the specified time and returns its eased progress.
+func (tl *timeline) advance(deltaTime time.Duration) float32 {
+
+	tl.elapsed += deltaTime
+	if tl.elapsed >= tl.duration {
+		if tl.loop && tl.duration > 0 {
+			tl.elapsed %= tl.duration
+		} else {
+			tl.elapsed = tl.duration
+			tl.running = false
+		}
+	}
+	t := float32(1)
+	if tl.duration > 0 {
+		t = float32(tl.elapsed.Seconds() / tl.duration.Seconds())
+	}
+	return tl.easing(t)
+}
+
+// finish dispatches OnAnimationEnd if the animation is no longer running.
+func (tl *timeline) finish() {
+
+	if !tl.running {
+		tl.Dispatch(OnAnimationEnd, nil)
+	}
+}
+
+// Tween animates the position, target and field-of-view or orthographic size of a camera
+// from their current values to the specified values. Only the properties which are set
+// are animated. If an OrbitControl is specified, its target follows the animated target.
+// Update must be called every frame until the tween finishes.
+type Tween struct {
+	timeline                  // Embedded timeline
+	started    bool           // Whether the start values were taken
+	hasPos     bool           // Whether the position is animated
+	hasTarget  bool           // Whether the target is animated
+	hasFov     bool           // Whether the field-of-view is animated
+	hasSize    bool           // Whether the orthographic size is animated
+	fromPos    math32.Vector3 // Initial position
+	toPos      math32.Vector3 // Final position
+	fromTarget math32.Vector3 // Initial target
+	toTarget   math32.Vector3 // Final target
+	fromFov    float32        // Initial field-of-view
+	toFov      float32        // Final field-of-view
+	fromSize   float32        // Initial orthographic size
+	toSize     float32        // Final orthographic size
+}
+
+// NewTween creates and returns a pointer to a new tween of the specified camera with the specified duration.
+// The default easing is EaseInOutCubic. The start values are taken in the first update.
+func NewTween(cam *Camera, duration time.Duration) *Tween {
+
+	tw := new(Tween)
+	tw.initialize(cam, duration)
+	return tw
+}
+
+// SetEasing sets the easing function.
+func (tw *Tween) SetEasing(easing Easing) *Tween {
+
+	tw.easing = easing
+	return tw
+}
+
+// SetOrbitControl sets the orbit control whose target is the initial target and follows the animated target.
+func (tw *Tween) SetOrbitControl(oc *OrbitControl) *Tween {
+
+	tw.orbit = oc
+	return tw
+}
+
+// MoveTo sets the final position of the camera.
+func (tw *Tween) MoveTo(pos math32.Vector3) *Tween {
+
+	tw.hasPos = true
+	tw.toPos = pos
+	return tw
+}
+
+// LookAt sets the final target the camera looks at.
+func (tw *Tween) LookAt(target math32.Vector3) *Tween {
+
+	tw.hasTarget = true
+	tw.toTarget = target
+	return tw
+}
+
+// FovTo sets the final field-of-view in degrees.
+func (tw *Tween) FovTo(fov float32) *Tween {
+
+	tw.hasFov = true
+	tw.toFov = fov
+	return tw
+}
+
+// SizeTo sets the final orthographic size.
+func (tw *Tween) SizeTo(size float32) *Tween {
+
+	tw.hasSize = true
+	tw.toSize = size
+	return tw
+}
+
+// FitToBox sets the final position, target and orthographic size which frame the specified box
+// keeping the current view direction, as done immediately by Camera.FitToBox.
+func (tw *Tween) FitToBox(box math32.Box3) *Tween {
+
+	pos, target, size := tw.cam.fitBox(&box)
+	tw.MoveTo(pos).LookAt(target)
+	if tw.cam.Projection() == Orthographic {
+		tw.SizeTo(size)
+	}
+	return tw
+}
+
+// Update advances the tween by the specified time and updates the camera.
+// It returns whether the tween is still running.
+func (tw *Tween) Update(deltaTime time.Duration) bool {
+
+	if !tw.running {
+		return false
+	}
+	if !tw.started {
+		tw.start()
+	}
+	t := tw.advance(deltaTime)
+
+	var pos math32.Vector3
+	if tw.hasPos {
+		pos = tw.fromPos
+		pos.Lerp(&tw.toPos, t)
+		tw.cam.SetPositionVec(&pos)
+	} else {
+		pos = tw.cam.Position()
+	}
+	if tw.hasTarget || (tw.hasPos && tw.orbit != nil) {
+		target := tw.fromTarget
+		target.Lerp(&tw.toTarget, t)
+		if tw.orbit != nil {
+			tw.orbit.SetTarget(target)
+		}
+		if !target.Equals(&pos) {
+			tw.cam.LookAt(&target, &math32.Vector3{Y: 1})
+		}
+	}
+	if tw.hasFov {
+		tw.cam.SetFov(tw.fromFov + (tw.toFov-tw.fromFov)*t)
+	}
+	if tw.hasSize {
+		tw.cam.SetSize(tw.fromSize + (tw.toSize-tw.fromSize)*t)
+	}
+	tw.finish()
+	return tw.running
+}
+
+// start takes the start values from the camera and the orbit control.
+func (tw *Tween) start() {
+
+	tw.started = true
+	tw.fromPos = tw.cam.Position()
+	tw.fromFov = tw.cam.Fov()
+	tw.fromSize = tw.cam.Size()
+	if !tw.hasTarget && tw.orbit != nil {
+		tw.toTarget = tw.orbit.Target()
+	}
+	if tw.orbit != nil {
+		tw.fromTarget = tw.orbit.Target()
+		return
+	}
+
+	// Without an orbit control the initial target is in front of the camera
+	// at the same distance as the final target
+	quat := tw.cam.Quaternion()
+	dir := math32.Vector3{0, 0, -1}
+	dir.ApplyQuaternion(&quat)
+	tw.fromTarget = tw.fromPos
+	tw.fromTarget.Add(dir.MultiplyScalar(tw.fromPos.DistanceTo(&tw.toTarget)))
+}
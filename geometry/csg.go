@@ -0,0 +1,626 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Constructive solid geometry (CSG) operations between closed triangle meshes,
+// implemented with binary space partitioning (BSP) trees as in the csg.js library.
+// The vertex normals and texture coordinates are interpolated where triangles are split
+// and the triangles of the result keep the groups, and so the materials, of the triangles
+// they come from. The operands must be in the same coordinate system, so ApplyMatrix
+// should be used to transform them first if needed.
+
+// Tolerance used to classify points as coplanar
+const csgEpsilon = 1e-5
+
+// Union returns a new geometry with the volume inside any of the specified closed geometries.
+// It returns an error if any of them is not a closed manifold mesh.
+func Union(a, b *Geometry) (*Geometry, error) {
+
+	na, nb, err := csgNodes(a, b)
+	if err != nil {
+		return nil, err
+	}
+	na.clipTo(nb)
+	nb.clipTo(na)
+	nb.invert()
+	nb.clipTo(na)
+	nb.invert()
+	na.build(nb.allPolygons(nil))
+	return csgGeometry(na.allPolygons(nil), a, b), nil
+}
+
+// Subtract returns a new geometry with the volume inside the closed geometry a and outside the closed geometry b.
+// The triangles of b which bound the result keep the groups of b.
+// It returns an error if any of them is not a closed manifold mesh.
+func Subtract(a, b *Geometry) (*Geometry, error) {
+
+	na, nb, err := csgNodes(a, b)
+	if err != nil {
+		return nil, err
+	}
+	na.invert()
+	na.clipTo(nb)
+	nb.clipTo(na)
+	nb.invert()
+	nb.clipTo(na)
+	nb.invert()
+	na.build(nb.allPolygons(nil))
+	na.invert()
+	return csgGeometry(na.allPolygons(nil), a, b), nil
+}
+
+// Intersect returns a new geometry with the volume inside both of the specified closed geometries.
+// It returns an error if any of them is not a closed manifold mesh.
+func Intersect(a, b *Geometry) (*Geometry, error) {
+
+	na, nb, err := csgNodes(a, b)
+	if err != nil {
+		return nil, err
+	}
+	na.invert()
+	nb.clipTo(na)
+	nb.invert()
+	na.clipTo(nb)
+	nb.clipTo(na)
+	na.build(nb.allPolygons(nil))
+	na.invert()
+	return csgGeometry(na.allPolygons(nil), a, b), nil
+}
+
+// CheckManifold returns an error if the geometry is not a closed manifold triangle mesh,
+// in which each edge is shared by exactly two triangles with opposite orientations.
+// Vertices closer than a small fraction of the geometry size are considered the same, so the
+// vertices split because of different normals or texture coordinates do not open the mesh.
+// Degenerate triangles, such as the ones at the poles of spheres, are ignored.
+func (g *Geometry) CheckManifold() error {
+
+	vd := g.readVertexData()
+	posOff := vd.offset(gls.VertexPosition)
+	tris := g.triangles()
+	if posOff < 0 || len(tris) == 0 {
+		return errors.New("geometry has no triangles")
+	}
+	box := g.BoundingBox()
+	snap := newCSGSnap(&box)
+	key := func(idx uint32) csgPointID {
+		v := vd.vector3(int(idx), posOff)
+		return snap.id(&v)
+	}
+
+	type edge struct{ a, b csgPointID }
+	edges := make(map[edge]int)
+	for f := 0; f < len(tris); f += 3 {
+		p := [3]csgPointID{key(tris[f]), key(tris[f+1]), key(tris[f+2])}
+		if p[0] == p[1] || p[1] == p[2] || p[2] == p[0] {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			edges[edge{p[c], p[(c+1)%3]}]++
+		}
+	}
+	if len(edges) == 0 {
+		return errors.New("geometry has no triangles")
+	}
+	for e, count := range edges {
+		if reverse := edges[edge{e.b, e.a}]; count != 1 || reverse != 1 {
+			return fmt.Errorf("geometry is not a closed manifold: the edge from %v to %v is used by %d triangles in one direction and %d in the other",
+				snap.positions[e.a], snap.positions[e.b], count, reverse)
+		}
+	}
+	return nil
+}
+
+// csgVertex is a polygon vertex with its position and interpolated attributes.
+type csgVertex struct {
+	pos    math32.Vector3
+	normal math32.Vector3
+	uv     math32.Vector2
+}
+
+// interpolate returns the vertex between this vertex and other at the specified fraction.
+func (v *csgVertex) interpolate(other *csgVertex, t float32) csgVertex {
+
+	r := *v
+	r.pos.Lerp(&other.pos, t)
+	r.normal.Lerp(&other.normal, t)
+	r.uv.Lerp(&other.uv, t)
+	return r
+}
+
+// csgPlane is the plane normal·p = w.
+type csgPlane struct {
+	normal math32.Vector3
+	w      float32
+}
+
+// csgPolygon is a convex polygon with the group of the triangle it comes from.
+type csgPolygon struct {
+	vertices []csgVertex
+	plane    csgPlane
+	group    int
+}
+
+// flip reverses the orientation of the polygon.
+func (p *csgPolygon) flip() {
+
+	for i, j := 0, len(p.vertices)-1; i < j; i, j = i+1, j-1 {
+		p.vertices[i], p.vertices[j] = p.vertices[j], p.vertices[i]
+	}
+	for i := range p.vertices {
+		p.vertices[i].normal.Negate()
+	}
+	p.plane.normal.Negate()
+	p.plane.w = -p.plane.w
+}
+
+// Classification of points and polygons relative to a plane
+const (
+	csgCoplanar = 0
+	csgFront    = 1
+	csgBack     = 2
+	csgSpanning = 3
+)
+
+// split puts the specified polygon, or its parts if it spans the plane, in the appropriate lists.
+// Coplanar polygons go into either coplanarFront or coplanarBack depending on their orientation.
+func (pl *csgPlane) split(p *csgPolygon, coplanarFront, coplanarBack, front, back *[]*csgPolygon) {
+
+	ptype := 0
+	types := make([]int, len(p.vertices))
+	for i := range p.vertices {
+		t := pl.normal.Dot(&p.vertices[i].pos) - pl.w
+		if t < -csgEpsilon {
+			types[i] = csgBack
+		} else if t > csgEpsilon {
+			types[i] = csgFront
+		}
+		ptype |= types[i]
+	}
+
+	switch ptype {
+	case csgCoplanar:
+		if pl.normal.Dot(&p.plane.normal) > 0 {
+			*coplanarFront = append(*coplanarFront, p)
+		} else {
+			*coplanarBack = append(*coplanarBack, p)
+		}
+	case csgFront:
+		*front = append(*front, p)
+	case csgBack:
+		*back = append(*back, p)
+	case csgSpanning:
+		var f, b []csgVertex
+		n := len(p.vertices)
+		for i := 0; i < n; i++ {
+			j := (i + 1) % n
+			ti, tj := types[i], types[j]
+			vi, vj := &p.vertices[i], &p.vertices[j]
+			if ti != csgBack {
+				f = append(f, *vi)
+			}
+			if ti != csgFront {
+				b = append(b, *vi)
+			}
+			if ti|tj == csgSpanning {
+				var d math32.Vector3
+				d.SubVectors(&vj.pos, &vi.pos)
+				t := (pl.w - pl.normal.Dot(&vi.pos)) / pl.normal.Dot(&d)
+				v := vi.interpolate(vj, t)
+				f = append(f, v)
+				b = append(b, v)
+			}
+		}
+		if len(f) >= 3 {
+			*front = append(*front, &csgPolygon{f, p.plane, p.group})
+		}
+		if len(b) >= 3 {
+			*back = append(*back, &csgPolygon{b, p.plane, p.group})
+		}
+	}
+}
+
+// csgNode is a node of a BSP tree. Each node has a splitting plane, the polygons which lie
+// on it, and the subtrees in front of and behind it.
+type csgNode struct {
+	plane    *csgPlane
+	front    *csgNode
+	back     *csgNode
+	polygons []*csgPolygon
+}
+
+// invert converts the solid space to empty space and empty space to solid space.
+func (n *csgNode) invert() {
+
+	for _, p := range n.polygons {
+		p.flip()
+	}
+	if n.plane != nil {
+		n.plane.normal.Negate()
+		n.plane.w = -n.plane.w
+	}
+	if n.front != nil {
+		n.front.invert()
+	}
+	if n.back != nil {
+		n.back.invert()
+	}
+	n.front, n.back = n.back, n.front
+}
+
+// clipPolygons returns the parts of the specified polygons which are outside the solid of this tree.
+func (n *csgNode) clipPolygons(polygons []*csgPolygon) []*csgPolygon {
+
+	if n.plane == nil {
+		return append([]*csgPolygon(nil), polygons...)
+	}
+	var front, back []*csgPolygon
+	for _, p := range polygons {
+		n.plane.split(p, &front, &back, &front, &back)
+	}
+	if n.front != nil {
+		front = n.front.clipPolygons(front)
+	}
+	if n.back != nil {
+		back = n.back.clipPolygons(back)
+	} else {
+		back = nil
+	}
+	return append(front, back...)
+}
+
+// clipTo removes the polygons of this tree which are inside the solid of the specified tree.
+func (n *csgNode) clipTo(other *csgNode) {
+
+	n.polygons = other.clipPolygons(n.polygons)
+	if n.front != nil {
+		n.front.clipTo(other)
+	}
+	if n.back != nil {
+		n.back.clipTo(other)
+	}
+}
+
+// allPolygons appends all the polygons of this tree to the specified list and returns it.
+func (n *csgNode) allPolygons(list []*csgPolygon) []*csgPolygon {
+
+	list = append(list, n.polygons...)
+	if n.front != nil {
+		list = n.front.allPolygons(list)
+	}
+	if n.back != nil {
+		list = n.back.allPolygons(list)
+	}
+	return list
+}
+
+// build adds the specified polygons to this tree, splitting them by the node planes.
+// The plane of the first polygon is used as the splitting plane of new nodes.
+func (n *csgNode) build(polygons []*csgPolygon) {
+
+	if len(polygons) == 0 {
+		return
+	}
+	if n.plane == nil {
+		plane := polygons[0].plane
+		n.plane = &plane
+	}
+	var front, back []*csgPolygon
+	for _, p := range polygons {
+		n.plane.split(p, &n.polygons, &n.polygons, &front, &back)
+	}
+	if len(front) > 0 {
+		if n.front == nil {
+			n.front = new(csgNode)
+		}
+		n.front.build(front)
+	}
+	if len(back) > 0 {
+		if n.back == nil {
+			n.back = new(csgNode)
+		}
+		n.back.build(back)
+	}
+}
+
+// csgNodes checks the specified geometries and returns their BSP trees.
+// The groups of the triangles of b are numbered after the groups of a.
+func csgNodes(a, b *Geometry) (*csgNode, *csgNode, error) {
+
+	if err := a.CheckManifold(); err != nil {
+		return nil, nil, fmt.Errorf("first operand: %v", err)
+	}
+	if err := b.CheckManifold(); err != nil {
+		return nil, nil, fmt.Errorf("second operand: %v", err)
+	}
+	na := new(csgNode)
+	na.build(csgPolygons(a, 0))
+	nb := new(csgNode)
+	nb.build(csgPolygons(b, len(csgGroups(a))))
+	return na, nb, nil
+}
+
+// csgGroups returns the groups of the geometry or, if it has none, a single group with all the triangles.
+func csgGroups(g *Geometry) []Group {
+
+	if len(g.groups) > 0 {
+		return g.groups
+	}
+	return []Group{{Start: 0, Count: len(g.triangles()), Matindex: 0}}
+}
+
+// csgPolygons returns the triangles of the geometry as polygons,
+// numbering the groups from the specified first group.
+func csgPolygons(g *Geometry, firstGroup int) []*csgPolygon {
+
+	vd := g.readVertexData()
+	posOff := vd.offset(gls.VertexPosition)
+	norOff := vd.offset(gls.VertexNormal)
+	uvOff := vd.offset(gls.VertexTexcoord)
+	tris := g.triangles()
+	groups := csgGroups(g)
+
+	var polygons []*csgPolygon
+	for f := 0; f < len(tris); f += 3 {
+		group := firstGroup
+		for i, gr := range groups {
+			if f >= gr.Start && f < gr.Start+gr.Count {
+				group = firstGroup + i
+				break
+			}
+		}
+		p := &csgPolygon{vertices: make([]csgVertex, 3), group: group}
+		for c := 0; c < 3; c++ {
+			v := &p.vertices[c]
+			idx := int(tris[f+c])
+			v.pos = vd.vector3(idx, posOff)
+			if norOff >= 0 {
+				v.normal = vd.vector3(idx, norOff)
+			}
+			if uvOff >= 0 {
+				uv := vd.vertex(idx)[uvOff:]
+				v.uv.Set(uv[0], uv[1])
+			}
+		}
+		var e1, e2 math32.Vector3
+		e1.SubVectors(&p.vertices[1].pos, &p.vertices[0].pos)
+		e2.SubVectors(&p.vertices[2].pos, &p.vertices[0].pos)
+		if p.plane.normal.CrossVectors(&e1, &e2).Length() < csgEpsilon*csgEpsilon {
+			continue
+		}
+		p.plane.normal.Normalize()
+		p.plane.w = p.plane.normal.Dot(&p.vertices[0].pos)
+		if norOff < 0 {
+			for c := range p.vertices {
+				p.vertices[c].normal = p.plane.normal
+			}
+		}
+		polygons = append(polygons, p)
+	}
+	return polygons
+}
+
+// csgTriangle is a triangle of the result of an operation.
+type csgTriangle struct {
+	vertices [3]csgVertex
+	group    int
+}
+
+// csgGeometry returns a new indexed geometry with the triangulated polygons sorted by group
+// and the groups of the original geometries with triangles in the result.
+func csgGeometry(polygons []*csgPolygon, a, b *Geometry) *Geometry {
+
+	sources := append(append([]Group(nil), csgGroups(a)...), csgGroups(b)...)
+	var box math32.Box3
+	box.MakeEmpty()
+	var tris []csgTriangle
+	for _, p := range polygons {
+		for j := 2; j < len(p.vertices); j++ {
+			tris = append(tris, csgTriangle{[3]csgVertex{p.vertices[0], p.vertices[j-1], p.vertices[j]}, p.group})
+		}
+		for i := range p.vertices {
+			box.ExpandByPoint(&p.vertices[i].pos)
+		}
+	}
+	tris = csgFixTJunctions(tris, newCSGSnap(&box))
+
+	byGroup := make([][]*csgTriangle, len(sources))
+	for i := range tris {
+		t := &tris[i]
+		byGroup[t.group] = append(byGroup[t.group], t)
+	}
+	positions := math32.NewArrayF32(0, 0)
+	normals := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	geom := NewGeometry()
+	for i, list := range byGroup {
+		start := positions.Size() / 3
+		for _, t := range list {
+			for j := range t.vertices {
+				v := &t.vertices[j]
+				normal := v.normal
+				positions.AppendVector3(&v.pos)
+				normals.AppendVector3(normal.Normalize())
+				uvs.AppendVector2(&v.uv)
+			}
+		}
+		if count := positions.Size()/3 - start; count > 0 {
+			group := geom.AddGroup(start, count, sources[i].Matindex)
+			group.Matid = sources[i].Matid
+		}
+	}
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	geom.Weld(0)
+	return geom
+}
+
+// csgPoint is a position snapped to a grid.
+type csgPoint [3]int64
+
+// csgSnap identifies positions closer than a tolerance as the same point.
+// Each new position is compared with the known points in the neighboring grid cells,
+// so close positions on different sides of a cell border are also identified.
+type csgSnap struct {
+	tolerance float64                   // Distance within which positions are the same point
+	cells     map[csgPoint][]csgPointID // Known points in each grid cell
+	positions []math32.Vector3          // Position of each known point
+}
+
+// csgPointID identifies a point of a csgSnap.
+type csgPointID int
+
+// newCSGSnap creates and returns a pointer to a new csgSnap for geometry
+// with the specified bounding box.
+func newCSGSnap(box *math32.Box3) *csgSnap {
+
+	var size math32.Vector3
+	box.Size(&size)
+	s := new(csgSnap)
+	s.tolerance = float64(math32.Max(size.Length(), 1)) * csgEpsilon
+	s.cells = make(map[csgPoint][]csgPointID)
+	return s
+}
+
+// id returns the identifier of the point at the specified position.
+func (s *csgSnap) id(v *math32.Vector3) csgPointID {
+
+	cell := csgPoint{
+		int64(math.Floor(float64(v.X) / s.tolerance)),
+		int64(math.Floor(float64(v.Y) / s.tolerance)),
+		int64(math.Floor(float64(v.Z) / s.tolerance)),
+	}
+	tol := float32(s.tolerance)
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for dz := int64(-1); dz <= 1; dz++ {
+				for _, id := range s.cells[csgPoint{cell[0] + dx, cell[1] + dy, cell[2] + dz}] {
+					if s.positions[id].DistanceTo(v) <= tol {
+						return id
+					}
+				}
+			}
+		}
+	}
+	id := csgPointID(len(s.positions))
+	s.positions = append(s.positions, *v)
+	s.cells[cell] = append(s.cells[cell], id)
+	return id
+}
+
+// csgFixTJunctions splits the triangles which have vertices of other triangles in the middle
+// of their edges, as left by the BSP splits, so that the result is a closed manifold mesh
+// which can be used in further operations.
+func csgFixTJunctions(tris []csgTriangle, snap *csgSnap) []csgTriangle {
+
+	type edge struct{ a, b csgPointID }
+	for pass := 0; pass < 8; pass++ {
+		// Find the edges without a matching opposite edge and their end points
+		edges := make(map[edge]int)
+		for i := range tris {
+			for c := 0; c < 3; c++ {
+				a := snap.id(&tris[i].vertices[c].pos)
+				b := snap.id(&tris[i].vertices[(c+1)%3].pos)
+				edges[edge{a, b}]++
+			}
+		}
+		var points []math32.Vector3
+		seen := make(map[csgPointID]bool)
+		for i := range tris {
+			for c := 0; c < 3; c++ {
+				v := &tris[i].vertices[c]
+				a := snap.id(&v.pos)
+				b := snap.id(&tris[i].vertices[(c+1)%3].pos)
+				if edges[edge{b, a}] == 0 && !seen[a] {
+					seen[a] = true
+					points = append(points, v.pos)
+				}
+				if edges[edge{b, a}] == 0 && !seen[b] {
+					seen[b] = true
+					points = append(points, tris[i].vertices[(c+1)%3].pos)
+				}
+			}
+		}
+		if len(points) == 0 {
+			return tris
+		}
+
+		// Split the unmatched edges at the points in their interior
+		changed := false
+		out := make([]csgTriangle, 0, len(tris))
+		for _, t := range tris {
+			split := false
+			for c := 0; c < 3 && !split; c++ {
+				va, vb, vc := &t.vertices[c], &t.vertices[(c+1)%3], &t.vertices[(c+2)%3]
+				if edges[edge{snap.id(&vb.pos), snap.id(&va.pos)}] != 0 {
+					continue
+				}
+				ts := csgEdgePoints(&va.pos, &vb.pos, points, snap.tolerance)
+				if len(ts) == 0 {
+					continue
+				}
+				prev := *va
+				for _, f := range append(ts, 1) {
+					next := va.interpolate(vb, f)
+					if f == 1 {
+						next = *vb
+					}
+					out = append(out, csgTriangle{[3]csgVertex{prev, next, *vc}, t.group})
+					prev = next
+				}
+				split = true
+			}
+			if split {
+				changed = true
+			} else {
+				out = append(out, t)
+			}
+		}
+		tris = out
+		if !changed {
+			break
+		}
+	}
+	return tris
+}
+
+// csgEdgePoints returns the sorted fractions along the edge from a to b
+// of the specified points which are in the interior of the edge.
+func csgEdgePoints(a, b *math32.Vector3, points []math32.Vector3, tolerance float64) []float32 {
+
+	var dir math32.Vector3
+	dir.SubVectors(b, a)
+	length := dir.Length()
+	if length == 0 {
+		return nil
+	}
+	dir.MultiplyScalar(1 / length)
+	tol := float32(tolerance)
+	var ts []float32
+	for i := range points {
+		var ap math32.Vector3
+		ap.SubVectors(&points[i], a)
+		along := ap.Dot(&dir)
+		if along <= tol || along >= length-tol {
+			continue
+		}
+		ap.Sub(dir.Clone().MultiplyScalar(along))
+		if ap.Length() <= tol {
+			ts = append(ts, along/length)
+		}
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i] < ts[j] })
+	return ts
+}
@@ -0,0 +1,172 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/math32"
+)
+
+// volume returns the signed volume enclosed by the triangles of the geometry.
+func volume(g *Geometry) float32 {
+
+	var vol float32
+	g.ReadFaces(func(a, b, c math32.Vector3) bool {
+		var cross math32.Vector3
+		cross.CrossVectors(&b, &c)
+		vol += a.Dot(&cross) / 6
+		return false
+	})
+	return vol
+}
+
+// translatedBox returns a box with the specified size translated by the specified offset.
+func translatedBox(size, x, y, z float32) *Geometry {
+
+	box := NewBox(size, size, size)
+	box.ApplyMatrix(math32.NewMatrix4().MakeTranslation(x, y, z))
+	return box
+}
+
+// Tests the volumes and bounds of the results of the operations between boxes
+func TestCSGBoxes(t *testing.T) {
+
+	ops := map[string]func(a, b *Geometry) (*Geometry, error){
+		"union": Union, "subtract": Subtract, "intersect": Intersect,
+	}
+	cases := []struct {
+		name     string
+		a, b     *Geometry
+		volumes  map[string]float32
+		min, max map[string]math32.Vector3
+	}{
+		// Boxes overlapping by half, whose faces are partly coplanar
+		{"overlapping", translatedBox(2, 0, 0, 0), translatedBox(2, 1, 0, 0),
+			map[string]float32{"union": 12, "subtract": 4, "intersect": 4},
+			map[string]math32.Vector3{"union": {-1, -1, -1}, "subtract": {-1, -1, -1}, "intersect": {0, -1, -1}},
+			map[string]math32.Vector3{"union": {2, 1, 1}, "subtract": {0, 1, 1}, "intersect": {1, 1, 1}}},
+		// Box inside another box, which is hollowed by the subtraction
+		{"inside", translatedBox(4, 0, 0, 0), translatedBox(2, 0.5, 0.5, 0.5),
+			map[string]float32{"union": 64, "subtract": 56, "intersect": 8},
+			map[string]math32.Vector3{"union": {-2, -2, -2}, "subtract": {-2, -2, -2}, "intersect": {-0.5, -0.5, -0.5}},
+			map[string]math32.Vector3{"union": {2, 2, 2}, "subtract": {2, 2, 2}, "intersect": {1.5, 1.5, 1.5}}},
+		// Boxes overlapping at a corner
+		{"corner", translatedBox(2, 0, 0, 0), translatedBox(2, 1, 1, 1),
+			map[string]float32{"union": 15, "subtract": 7, "intersect": 1},
+			map[string]math32.Vector3{"union": {-1, -1, -1}, "subtract": {-1, -1, -1}, "intersect": {0, 0, 0}},
+			map[string]math32.Vector3{"union": {2, 2, 2}, "subtract": {1, 1, 1}, "intersect": {1, 1, 1}}},
+	}
+	for _, c := range cases {
+		for name, op := range ops {
+			res, err := op(c.a, c.b)
+			if err != nil {
+				t.Fatalf("%s %s: %v", c.name, name, err)
+			}
+			if err := res.CheckManifold(); err != nil {
+				t.Errorf("%s %s: %v", c.name, name, err)
+			}
+			if vol := volume(res); math32.Abs(vol-c.volumes[name]) > 1e-3 {
+				t.Errorf("%s %s: volume:%v expected:%v", c.name, name, vol, c.volumes[name])
+			}
+			box := res.BoundingBox()
+			min, max := c.min[name], c.max[name]
+			if box.Min.DistanceTo(&min) > 1e-5 || box.Max.DistanceTo(&max) > 1e-5 {
+				t.Errorf("%s %s: bounds:%v expected:%v %v", c.name, name, box, min, max)
+			}
+		}
+	}
+}
+
+// Tests the operations between disjoint geometries
+func TestCSGDisjoint(t *testing.T) {
+
+	a, b := translatedBox(2, 0, 0, 0), translatedBox(2, 5, 0, 0)
+	res, err := Union(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vol := volume(res); math32.Abs(vol-16) > 1e-3 {
+		t.Errorf("union volume:%v", vol)
+	}
+	res, err = Subtract(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vol := volume(res); math32.Abs(vol-8) > 1e-3 {
+		t.Errorf("subtract volume:%v", vol)
+	}
+	res, err = Intersect(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.triangles()) != 0 {
+		t.Errorf("intersection with %d triangles", len(res.triangles())/3)
+	}
+}
+
+// Tests that curved geometries are subtracted into a closed geometry of the expected volume
+func TestCSGSphere(t *testing.T) {
+
+	// The sphere is inside the box, so the subtraction removes the volume of its triangles
+	box := translatedBox(4, 0, 0, 0)
+	sphere := NewSphere(1, 16, 12)
+	res, err := Subtract(box, sphere)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := res.CheckManifold(); err != nil {
+		t.Error(err)
+	}
+	if vol, expected := volume(res), 64-volume(sphere); math32.Abs(vol-expected) > 1e-3 {
+		t.Errorf("volume:%v expected:%v", vol, expected)
+	}
+
+	// Half of the sphere is outside the box
+	res, err = Intersect(translatedBox(4, 0, 2, 0), sphere)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := res.CheckManifold(); err != nil {
+		t.Error(err)
+	}
+	if vol, expected := volume(res), volume(sphere)/2; math32.Abs(vol-expected) > 1e-3 {
+		t.Errorf("half sphere volume:%v expected:%v", vol, expected)
+	}
+}
+
+// Tests that the triangles of the result keep the groups of their operands
+func TestCSGGroups(t *testing.T) {
+
+	a := translatedBox(2, 0, 0, 0)
+	b := translatedBox(2, 1, 0, 0)
+	b.groups = []Group{{Start: 0, Count: len(b.triangles()), Matindex: 7, Matid: "b"}}
+	res, err := Subtract(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The result has the 5 faces of a outside b, clipped, and the face of b inside a
+	matindices := make(map[int]bool)
+	for _, g := range res.groups {
+		matindices[g.Matindex] = true
+		if (g.Matindex == 7) != (g.Matid == "b") {
+			t.Errorf("group:%+v", g)
+		}
+	}
+	if len(res.groups) != 6 || !matindices[7] {
+		t.Errorf("groups:%+v", res.groups)
+	}
+}
+
+// Tests the errors of operands which are not closed
+func TestCSGErrors(t *testing.T) {
+
+	if _, err := Union(NewPlane(1, 1), NewBox(1, 1, 1)); err == nil {
+		t.Error("expected error for open first operand")
+	}
+	if _, err := Intersect(NewBox(1, 1, 1), NewPlane(1, 1)); err == nil {
+		t.Error("expected error for open second operand")
+	}
+}
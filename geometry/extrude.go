@@ -0,0 +1,284 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Angle between adjacent side faces of extruded and lathe geometries
+// below which the edge between them is smoothed
+const smoothAngle = math32.Pi / 4
+
+// ExtrudeOptions contains the options of extruded geometries.
+type ExtrudeOptions struct {
+	Depth          float32       // Depth of the extrusion along the Z axis, when there is no path (default is 1)
+	Path           *math32.Curve // Curve along which the shapes are extruded instead of the Z axis (may be nil)
+	BevelThickness float32       // Depth of the bevel at each end (0 for no bevel)
+	BevelSize      float32       // Distance the bevel extends the outline of the shapes
+	BevelSegments  int           // Number of segments of each bevel (default is 3)
+}
+
+// NewExtrude creates and returns a pointer to a new geometry which extrudes the specified shapes.
+// If a path is specified, the shapes are swept along it with their X and Y axes kept perpendicular
+// to the path, otherwise they are extruded along the Z axis from 0 to the depth. If a bevel is
+// specified, the caps are moved out by the bevel thickness and joined to the sides by rounded bevels,
+// along which the outline of the shapes grows by the bevel size.
+// The geometry has two groups: the caps (material index 0) followed by the sides (material index 1).
+func NewExtrude(shapes []*Shape, opts *ExtrudeOptions) *Geometry {
+
+	var o ExtrudeOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Path == nil && o.Depth == 0 {
+		o.Depth = 1
+	}
+	if o.BevelSegments <= 0 {
+		o.BevelSegments = 3
+	}
+	frames := newExtrudeFrames(&o)
+
+	// Layers of the extrusion: distance along the extrusion and outline offset
+	type layer struct{ dist, offset float32 }
+	var layers []layer
+	bevel := o.BevelThickness > 0 || o.BevelSize > 0
+	if bevel {
+		for k := 0; k < o.BevelSegments; k++ {
+			t := float32(k) / float32(o.BevelSegments) * math32.Pi / 2
+			layers = append(layers, layer{-o.BevelThickness * math32.Cos(t), o.BevelSize * math32.Sin(t)})
+		}
+	}
+	for _, d := range frames.dists {
+		layers = append(layers, layer{d, o.BevelSize})
+	}
+	if bevel {
+		length := frames.dists[len(frames.dists)-1]
+		for k := o.BevelSegments - 1; k >= 0; k-- {
+			t := float32(k) / float32(o.BevelSegments) * math32.Pi / 2
+			layers = append(layers, layer{length + o.BevelThickness*math32.Cos(t), o.BevelSize * math32.Sin(t)})
+		}
+	} else {
+		for i := range layers {
+			layers[i].offset = 0
+		}
+	}
+
+	// Build the sides, which use separate vertices for each ring, and smooth their normals
+	sides := NewGeometry()
+	positions := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	for _, shape := range shapes {
+		for _, ring := range shape.rings() {
+			n := len(ring)
+			if n < 3 {
+				continue
+			}
+			dirs := ringOffsets(ring)
+			base := uint32(positions.Size() / 3)
+			for j, l := range layers {
+				var u float32
+				for i := 0; i <= n; i++ {
+					if i > 0 {
+						u += ring[i%n].DistanceTo(&ring[i-1])
+					}
+					p := frames.point(&ring[i%n], &dirs[i%n], l.offset, l.dist)
+					positions.AppendVector3(&p)
+					uvs.Append(u, l.dist)
+				}
+				if j == 0 {
+					continue
+				}
+				for i := 0; i < n; i++ {
+					a := base + uint32((j-1)*(n+1)+i)
+					b, c, d := a+1, a+uint32(n+2), a+uint32(n+1)
+					indices.Append(a, b, c, a, c, d)
+				}
+			}
+		}
+	}
+	sides.SetIndices(indices)
+	sides.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	sides.AddVBO(gls.NewVBO(math32.NewArrayF32(positions.Size(), positions.Size())).AddAttrib(gls.VertexNormal))
+	sides.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	sides.ComputeNormals(smoothAngle)
+
+	// Build the caps, at the first and last layers, with the triangulation of the shapes
+	geom := NewGeometry()
+	positions = math32.NewArrayF32(0, 0)
+	normals := math32.NewArrayF32(0, 0)
+	uvs = math32.NewArrayF32(0, 0)
+	indices = math32.NewArrayU32(0, 0)
+	for _, shape := range shapes {
+		points, tris := shape.Triangulate()
+		if len(tris) == 0 {
+			continue
+		}
+		for c, l := range []layer{layers[0], layers[len(layers)-1]} {
+			base := uint32(positions.Size() / 3)
+			normal := frames.tangent(l.dist)
+			if c == 0 {
+				normal.Negate()
+			}
+			var zero math32.Vector2
+			for i := range points {
+				p := frames.point(&points[i], &zero, 0, l.dist)
+				positions.AppendVector3(&p)
+				normals.AppendVector3(&normal)
+				uvs.Append(points[i].X, points[i].Y)
+			}
+			for i := 0; i < len(tris); i += 3 {
+				if c == 0 {
+					indices.Append(base+tris[i], base+tris[i+2], base+tris[i+1])
+				} else {
+					indices.Append(base+tris[i], base+tris[i+1], base+tris[i+2])
+				}
+			}
+		}
+	}
+	caps := indices.Size()
+
+	// Append the sides after the caps
+	base := uint32(positions.Size() / 3)
+	positions = append(positions, *sides.VBO(gls.VertexPosition).Buffer()...)
+	normals = append(normals, *sides.VBO(gls.VertexNormal).Buffer()...)
+	uvs = append(uvs, *sides.VBO(gls.VertexTexcoord).Buffer()...)
+	for _, idx := range sides.Indices() {
+		indices.Append(base + idx)
+	}
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	geom.AddGroup(0, caps, 0)
+	geom.AddGroup(caps, indices.Size()-caps, 1)
+	return geom
+}
+
+// ringOffsets returns the directions in which the points of the specified ring are moved to offset
+// the ring outwards by a unit distance, which for a counterclockwise contour is to its right.
+func ringOffsets(ring []math32.Vector2) []math32.Vector2 {
+
+	n := len(ring)
+	dirs := make([]math32.Vector2, n)
+	for i := range ring {
+		var d1, d2 math32.Vector2
+		d1.SubVectors(&ring[i], &ring[(i+n-1)%n]).Normalize()
+		d2.SubVectors(&ring[(i+1)%n], &ring[i]).Normalize()
+		n1 := math32.Vector2{X: d1.Y, Y: -d1.X}
+		n2 := math32.Vector2{X: d2.Y, Y: -d2.X}
+		dir := n1
+		dir.Add(&n2).Normalize()
+		// Scale the miter so that the offset edges are at a unit distance, limiting sharp corners
+		scale := math32.Max(dir.Dot(&n1), 0.25)
+		dirs[i] = *dir.MultiplyScalar(1 / scale)
+	}
+	return dirs
+}
+
+// extrudeFrames maps the shape coordinates and distances along the extrusion to positions.
+// The frames are computed at the points of the path by parallel transport, so that they
+// do not twist, and extend beyond the ends of the path along its end tangents.
+type extrudeFrames struct {
+	points   []math32.Vector3 // Points of the path
+	dists    []float32        // Distance along the path of each point
+	tangents []math32.Vector3 // Tangent of the path at each point
+	normals  []math32.Vector3 // Direction of the shape X axis at each point
+	binorms  []math32.Vector3 // Direction of the shape Y axis at each point
+}
+
+// newExtrudeFrames returns the frames of the extrusion with the specified options.
+func newExtrudeFrames(o *ExtrudeOptions) *extrudeFrames {
+
+	f := new(extrudeFrames)
+	if o.Path != nil {
+		for _, p := range o.Path.GetPoints() {
+			if n := len(f.points); n == 0 || !f.points[n-1].Equals(&p) {
+				f.points = append(f.points, p)
+			}
+		}
+	}
+	if len(f.points) < 2 {
+		f.points = []math32.Vector3{{}, {Z: o.Depth}}
+	}
+	n := len(f.points)
+	f.dists = make([]float32, n)
+	f.tangents = make([]math32.Vector3, n)
+	f.normals = make([]math32.Vector3, n)
+	f.binorms = make([]math32.Vector3, n)
+	for i := range f.points {
+		if i > 0 {
+			f.dists[i] = f.dists[i-1] + f.points[i].DistanceTo(&f.points[i-1])
+		}
+		prev, next := i, i
+		if i > 0 {
+			prev--
+		}
+		if i < n-1 {
+			next++
+		}
+		f.tangents[i].SubVectors(&f.points[next], &f.points[prev]).Normalize()
+	}
+
+	// The initial Y axis is the projection of the world Y axis, or Z axis if the path is vertical
+	t0 := &f.tangents[0]
+	up := math32.Vector3{Y: 1}
+	if math32.Abs(t0.Dot(&up)) > 0.99 {
+		up = math32.Vector3{Z: -1}
+	}
+	var proj math32.Vector3
+	f.binorms[0] = up
+	f.binorms[0].Sub(proj.Copy(t0).MultiplyScalar(t0.Dot(&up))).Normalize()
+	f.normals[0].CrossVectors(&f.binorms[0], t0)
+	for i := 1; i < n; i++ {
+		t := &f.tangents[i]
+		f.normals[i] = f.normals[i-1]
+		f.normals[i].Sub(proj.Copy(t).MultiplyScalar(t.Dot(&f.normals[i-1]))).Normalize()
+		f.binorms[i].CrossVectors(t, &f.normals[i])
+	}
+	return f
+}
+
+// index returns the index of the path point at the specified distance, which must be one
+// of the point distances or beyond the ends of the path.
+func (f *extrudeFrames) index(dist float32) int {
+
+	last := len(f.dists) - 1
+	if dist <= 0 {
+		return 0
+	}
+	if dist >= f.dists[last] {
+		return last
+	}
+	for i, d := range f.dists {
+		if d >= dist {
+			return i
+		}
+	}
+	return last
+}
+
+// point returns the position of the specified shape point moved by the specified offset
+// along the specified direction at the specified distance along the extrusion.
+func (f *extrudeFrames) point(p, dir *math32.Vector2, offset, dist float32) math32.Vector3 {
+
+	i := f.index(dist)
+	x := p.X + dir.X*offset
+	y := p.Y + dir.Y*offset
+	var tmp math32.Vector3
+	pos := f.points[i]
+	pos.Add(tmp.Copy(&f.tangents[i]).MultiplyScalar(dist - f.dists[i]))
+	pos.Add(tmp.Copy(&f.normals[i]).MultiplyScalar(x))
+	pos.Add(tmp.Copy(&f.binorms[i]).MultiplyScalar(y))
+	return pos
+}
+
+// tangent returns the direction of the extrusion at the specified distance.
+func (f *extrudeFrames) tangent(dist float32) math32.Vector3 {
+
+	return f.tangents[f.index(dist)]
+}
@@ -0,0 +1,246 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"image"
+	"image/color"
+	_ "image/jpeg" // Decodes jpeg heightmaps
+	_ "image/png"  // Decodes png heightmaps
+	"os"
+
+	"github.com/g3n/engine/math32"
+)
+
+// HeightSource is the interface for sources of terrain heights.
+// Height returns the height of the terrain at the specified X and Z coordinates.
+type HeightSource interface {
+	Height(x, z float32) float32
+}
+
+// HeightFunc is a function which satisfies the HeightSource interface, such as a noise function.
+type HeightFunc func(x, z float32) float32
+
+// Height returns the result of the function for the specified coordinates.
+func (f HeightFunc) Height(x, z float32) float32 {
+
+	return f(x, z)
+}
+
+// HeightMap is a regular grid of heights in the XZ plane, with the first sample at the origin
+// and the samples of each row along the X axis. It satisfies the HeightSource interface.
+type HeightMap struct {
+	cols    int       // Number of samples along the X axis
+	rows    int       // Number of samples along the Z axis
+	spacing float32   // Distance between adjacent samples
+	heights []float32 // Heights of the samples, row after row
+}
+
+// NewHeightMap creates and returns a pointer to a new flat heightmap with the specified
+// number of samples along the X and Z axes and distance between the samples.
+func NewHeightMap(cols, rows int, spacing float32) *HeightMap {
+
+	if cols < 2 {
+		cols = 2
+	}
+	if rows < 2 {
+		rows = 2
+	}
+	h := new(HeightMap)
+	h.cols = cols
+	h.rows = rows
+	h.spacing = spacing
+	h.heights = make([]float32, cols*rows)
+	return h
+}
+
+// NewHeightMapFromImage creates and returns a pointer to a new heightmap with a sample for each pixel
+// of the specified image, with the specified distance between the samples. The height of each sample
+// is the luminance of its pixel, from 0 for black to the specified scale for white.
+// The top row of the image is at Z=0. Images with 16 bits per channel keep their precision.
+func NewHeightMapFromImage(img image.Image, spacing, scale float32) *HeightMap {
+
+	bounds := img.Bounds()
+	h := NewHeightMap(bounds.Dx(), bounds.Dy(), spacing)
+	for r := 0; r < bounds.Dy(); r++ {
+		for c := 0; c < bounds.Dx(); c++ {
+			gray := color.Gray16Model.Convert(img.At(bounds.Min.X+c, bounds.Min.Y+r)).(color.Gray16)
+			h.Set(c, r, float32(gray.Y)/0xFFFF*scale)
+		}
+	}
+	return h
+}
+
+// NewHeightMapFromFile creates and returns a pointer to a new heightmap from the specified
+// png or jpeg image file as done by NewHeightMapFromImage.
+func NewHeightMapFromFile(imgfile string, spacing, scale float32) (*HeightMap, error) {
+
+	file, err := os.Open(imgfile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	return NewHeightMapFromImage(img, spacing, scale), nil
+}
+
+// NewHeightMapFromSource creates and returns a pointer to a new heightmap with the specified number of
+// samples and distance between the samples, with the heights of the specified source starting at x0, z0.
+// It can be used to sample noise functions or to build the heightfield of a region of a terrain.
+func NewHeightMapFromSource(src HeightSource, cols, rows int, spacing, x0, z0 float32) *HeightMap {
+
+	h := NewHeightMap(cols, rows, spacing)
+	for r := 0; r < h.rows; r++ {
+		for c := 0; c < h.cols; c++ {
+			h.Set(c, r, src.Height(x0+float32(c)*spacing, z0+float32(r)*spacing))
+		}
+	}
+	return h
+}
+
+// Cols returns the number of samples along the X axis.
+func (h *HeightMap) Cols() int {
+
+	return h.cols
+}
+
+// Rows returns the number of samples along the Z axis.
+func (h *HeightMap) Rows() int {
+
+	return h.rows
+}
+
+// Spacing returns the distance between adjacent samples.
+func (h *HeightMap) Spacing() float32 {
+
+	return h.spacing
+}
+
+// Size returns the size of the heightmap along the X and Z axes.
+func (h *HeightMap) Size() (float32, float32) {
+
+	return float32(h.cols-1) * h.spacing, float32(h.rows-1) * h.spacing
+}
+
+// Heights returns the heights of the samples, row after row, as used by physics heightfields.
+// The returned slice is not a copy.
+func (h *HeightMap) Heights() []float32 {
+
+	return h.heights
+}
+
+// At returns the height of the sample at the specified column and row,
+// which are clamped to the heightmap.
+func (h *HeightMap) At(col, row int) float32 {
+
+	if col < 0 {
+		col = 0
+	} else if col >= h.cols {
+		col = h.cols - 1
+	}
+	if row < 0 {
+		row = 0
+	} else if row >= h.rows {
+		row = h.rows - 1
+	}
+	return h.heights[row*h.cols+col]
+}
+
+// Set sets the height of the sample at the specified column and row.
+func (h *HeightMap) Set(col, row int, height float32) {
+
+	if col < 0 || col >= h.cols || row < 0 || row >= h.rows {
+		return
+	}
+	h.heights[row*h.cols+col] = height
+}
+
+// MinMax returns the minimum and maximum heights of the samples.
+func (h *HeightMap) MinMax() (float32, float32) {
+
+	min, max := h.heights[0], h.heights[0]
+	for _, v := range h.heights {
+		min = math32.Min(min, v)
+		max = math32.Max(max, v)
+	}
+	return min, max
+}
+
+// Height returns the height at the specified X and Z coordinates on the triangles of the heightmap,
+// as built by NewTerrain. Coordinates outside of the heightmap are clamped to its borders.
+func (h *HeightMap) Height(x, z float32) float32 {
+
+	height, _ := h.surface(x, z)
+	return height
+}
+
+// Normal returns the normal at the specified X and Z coordinates of the triangle
+// of the heightmap which contains them.
+func (h *HeightMap) Normal(x, z float32) math32.Vector3 {
+
+	_, normal := h.surface(x, z)
+	return normal
+}
+
+// surface returns the height and normal at the specified coordinates clamped to the heightmap.
+func (h *HeightMap) surface(x, z float32) (float32, math32.Vector3) {
+
+	width, depth := h.Size()
+	return gridSurface(h.At, h.spacing, math32.Clamp(x, 0, width), math32.Clamp(z, 0, depth))
+}
+
+// GridHeight returns the height at the specified X and Z coordinates on the triangles of a grid
+// with the specified distance between the samples of the source, as built by NewTerrain.
+func GridHeight(src HeightSource, spacing, x, z float32) float32 {
+
+	height, _ := gridSurface(sourceSampler(src, spacing), spacing, x, z)
+	return height
+}
+
+// GridNormal returns the normal at the specified X and Z coordinates of the triangle of a grid
+// with the specified distance between the samples of the source which contains them.
+func GridNormal(src HeightSource, spacing, x, z float32) math32.Vector3 {
+
+	_, normal := gridSurface(sourceSampler(src, spacing), spacing, x, z)
+	return normal
+}
+
+// sourceSampler returns a function which returns the height of the source at a grid sample.
+func sourceSampler(src HeightSource, spacing float32) func(c, r int) float32 {
+
+	return func(c, r int) float32 {
+		return src.Height(float32(c)*spacing, float32(r)*spacing)
+	}
+}
+
+// gridSurface returns the height and normal at the specified coordinates of a grid with the specified
+// sample function and spacing. Each cell is split in two triangles by the diagonal from its corner
+// at the next row to its corner at the next column.
+func gridSurface(sample func(c, r int) float32, spacing, x, z float32) (float32, math32.Vector3) {
+
+	fx, fz := x/spacing, z/spacing
+	c, r := math32.Floor(fx), math32.Floor(fz)
+	fx -= c
+	fz -= r
+	ic, ir := int(c), int(r)
+	h00 := sample(ic, ir)
+	h10 := sample(ic+1, ir)
+	h01 := sample(ic, ir+1)
+	var height, dx, dz float32
+	if fx+fz <= 1 {
+		dx, dz = h10-h00, h01-h00
+		height = h00 + dx*fx + dz*fz
+	} else {
+		h11 := sample(ic+1, ir+1)
+		dx, dz = h11-h01, h11-h10
+		height = h11 - dx*(1-fx) - dz*(1-fz)
+	}
+	normal := math32.Vector3{X: -dx, Y: spacing, Z: -dz}
+	normal.Normalize()
+	return height, normal
+}
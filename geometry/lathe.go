@@ -0,0 +1,50 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// NewLathe creates and returns a pointer to a new geometry which revolves the specified profile
+// around the Y axis. The X coordinate of each profile point is its distance to the axis and the
+// Y coordinate its height. The profile is revolved with the specified number of segments from
+// the start angle through the specified angle (in radians), which is 2*Pi for a full revolution.
+// The texture coordinates go from 0 to 1 around the axis and along the profile.
+func NewLathe(points []math32.Vector2, segments int, phiStart, phiLength float32) *Geometry {
+
+	if segments < 1 {
+		segments = 1
+	}
+	positions := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	n := len(points)
+	for i := 0; i <= segments; i++ {
+		phi := phiStart + float32(i)/float32(segments)*phiLength
+		sin, cos := math32.Sin(phi), math32.Cos(phi)
+		for j := range points {
+			positions.Append(points[j].X*sin, points[j].Y, points[j].X*cos)
+			uvs.Append(float32(i)/float32(segments), float32(j)/float32(math32.Max(float32(n-1), 1)))
+		}
+		if i == 0 {
+			continue
+		}
+		for j := 0; j < n-1; j++ {
+			a := uint32((i-1)*n + j)
+			b, c, d := a+uint32(n), a+uint32(n+1), a+1
+			indices.Append(a, b, d, b, c, d)
+		}
+	}
+
+	lathe := NewGeometry()
+	lathe.SetIndices(indices)
+	lathe.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	lathe.AddVBO(gls.NewVBO(math32.NewArrayF32(positions.Size(), positions.Size())).AddAttrib(gls.VertexNormal))
+	lathe.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	lathe.ComputeNormals(smoothAngle)
+	return lathe
+}
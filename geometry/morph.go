@@ -16,6 +16,7 @@ type MorphGeometry struct {
 	baseGeometry *Geometry   // The base geometry
 	targets      []*Geometry // The morph target geometries (containing deltas)
 	weights      []float32   // The weights for each morph target
+	names        []string    // The names of the morph targets (may be nil)
 	uniWeights   gls.Uniform // Texture unit uniform location cache
 	morphGeom    *Geometry   // Cache of the last CPU-morphed geometry
 }
@@ -58,6 +59,35 @@ func (mg *MorphGeometry) Weights() []float32 {
 	return mg.weights
 }
 
+// MorphTargets returns the morph target geometries, which contain the deltas from the base geometry.
+func (mg *MorphGeometry) MorphTargets() []*Geometry {
+
+	return mg.targets
+}
+
+// SetTargetNames sets the names of the morph targets, in the same order as the targets.
+func (mg *MorphGeometry) SetTargetNames(names []string) {
+
+	mg.names = names
+}
+
+// TargetNames returns the names of the morph targets, which may be nil.
+func (mg *MorphGeometry) TargetNames() []string {
+
+	return mg.names
+}
+
+// TargetIndex returns the index of the morph target with the specified name or -1 if not found.
+func (mg *MorphGeometry) TargetIndex(name string) int {
+
+	for i, n := range mg.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
 // AddMorphTargets add multiple morph targets to the morph geometry.
 // Morph target deltas are calculated internally and the morph target geometries are altered to hold the deltas instead.
 func (mg *MorphGeometry) AddMorphTargets(morphTargets ...*Geometry) {
@@ -0,0 +1,104 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// ComputeNormals recomputes the vertex normals of the geometry, adding them if needed.
+// The normal at each corner of a triangle is the area weighted average of the normals
+// of the triangles which share the corner position and which make an angle smaller than
+// the specified crease angle (in radians) with the triangle, so the edges between triangles
+// meeting at a larger angle are kept sharp. A crease angle of Pi smooths all edges.
+// Indexed vertices which need different normals at different corners are duplicated.
+func (g *Geometry) ComputeNormals(creaseAngle float32) {
+
+	g.addAttribute(gls.VertexNormal, 3)
+	vd := g.readVertexData()
+	posOff := vd.offset(gls.VertexPosition)
+	if posOff < 0 {
+		return
+	}
+	norOff := vd.offset(gls.VertexNormal)
+	tris := g.triangles()
+
+	// Compute the area weighted and the unit normals of the triangles
+	// and the triangles which share each position
+	faces := len(tris) / 3
+	weighted := make([]math32.Vector3, faces)
+	unit := make([]math32.Vector3, faces)
+	shared := make(map[math32.Vector3][]int)
+	for f := 0; f < faces; f++ {
+		var p [3]math32.Vector3
+		for c := 0; c < 3; c++ {
+			p[c] = vd.vector3(int(tris[3*f+c]), posOff)
+			shared[p[c]] = append(shared[p[c]], f)
+		}
+		var e1, e2 math32.Vector3
+		e1.SubVectors(&p[1], &p[0])
+		e2.SubVectors(&p[2], &p[0])
+		weighted[f].CrossVectors(&e1, &e2).MultiplyScalar(0.5)
+		unit[f] = weighted[f]
+		unit[f].Normalize()
+	}
+
+	// Compute the normal of each corner and assign it to the corner vertex,
+	// duplicating the vertex if it already has a different normal
+	cosCrease := math32.Cos(creaseAngle) - 1e-4
+	assigned := make([]bool, vd.count())
+	copies := make(map[uint32][]uint32)
+	indices := math32.NewArrayU32(len(tris), len(tris))
+	for i, idx := range tris {
+		f := i / 3
+		var normal math32.Vector3
+		for _, other := range shared[vd.vector3(int(idx), posOff)] {
+			if other == f || unit[f].Dot(&unit[other]) >= cosCrease {
+				normal.Add(&weighted[other])
+			}
+		}
+		if normal.Length() == 0 {
+			normal = unit[f]
+		}
+		normal.Normalize()
+
+		indices[i] = idx
+		if !assigned[idx] {
+			assigned[idx] = true
+			vd.setVector3(int(idx), norOff, &normal)
+			continue
+		}
+		if vec := vd.vector3(int(idx), norOff); vec.Equals(&normal) {
+			continue
+		}
+		found := false
+		for _, cidx := range copies[idx] {
+			if vec := vd.vector3(int(cidx), norOff); vec.Equals(&normal) {
+				indices[i] = cidx
+				found = true
+				break
+			}
+		}
+		if !found {
+			cidx := uint32(vd.count())
+			vd.data = append(vd.data, vd.vertex(int(idx))...)
+			vd.setVector3(int(cidx), norOff, &normal)
+			copies[idx] = append(copies[idx], cidx)
+			indices[i] = cidx
+		}
+	}
+	if !g.Indexed() {
+		indices = nil
+	}
+	vd.write(g, indices)
+}
+
+// ComputeFlatNormals recomputes the vertex normals of the geometry so that each triangle
+// is flat shaded with its own normal. It is the same as ComputeNormals(0).
+func (g *Geometry) ComputeFlatNormals() {
+
+	g.ComputeNormals(0)
+}
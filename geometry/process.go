@@ -0,0 +1,212 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"math"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// vertexData contains a copy of the vertices of all the VBOs of a geometry,
+// with the attributes of each vertex from all the VBOs concatenated,
+// which is used by the mesh processing functions.
+type vertexData struct {
+	vbos    []*gls.VBO // VBOs of the geometry
+	offsets []int      // Offset of the attributes of each VBO in a vertex
+	stride  int        // Number of elements of a vertex
+	data    []float32  // Concatenated vertices
+}
+
+// readVertexData returns a copy of the vertices of the geometry.
+func (g *Geometry) readVertexData() *vertexData {
+
+	vd := new(vertexData)
+	vd.vbos = g.vbos
+	for _, vbo := range g.vbos {
+		vd.offsets = append(vd.offsets, vd.stride)
+		vd.stride += vbo.Stride()
+	}
+	count := g.Items()
+	vd.data = make([]float32, count*vd.stride)
+	for i, vbo := range g.vbos {
+		stride := vbo.Stride()
+		buf := *vbo.Buffer()
+		for v := 0; v < count; v++ {
+			copy(vd.data[v*vd.stride+vd.offsets[i]:], buf[v*stride:(v+1)*stride])
+		}
+	}
+	return vd
+}
+
+// count returns the number of vertices.
+func (vd *vertexData) count() int {
+
+	if vd.stride == 0 {
+		return 0
+	}
+	return len(vd.data) / vd.stride
+}
+
+// offset returns the offset of the specified attribute in a vertex or -1 if not found.
+func (vd *vertexData) offset(atype gls.AttribType) int {
+
+	for i, vbo := range vd.vbos {
+		if vbo.Attrib(atype) != nil {
+			return vd.offsets[i] + vbo.AttribOffset(atype)
+		}
+	}
+	return -1
+}
+
+// vertex returns the elements of the vertex with the specified index.
+func (vd *vertexData) vertex(idx int) []float32 {
+
+	return vd.data[idx*vd.stride : (idx+1)*vd.stride]
+}
+
+// vector3 returns the 3 elements at the specified offset of the vertex with the specified index.
+func (vd *vertexData) vector3(idx, offset int) math32.Vector3 {
+
+	v := vd.data[idx*vd.stride+offset:]
+	return math32.Vector3{X: v[0], Y: v[1], Z: v[2]}
+}
+
+// setVector3 sets the 3 elements at the specified offset of the vertex with the specified index.
+func (vd *vertexData) setVector3(idx, offset int, vec *math32.Vector3) {
+
+	v := vd.data[idx*vd.stride+offset:]
+	v[0], v[1], v[2] = vec.X, vec.Y, vec.Z
+}
+
+// write writes the vertices back to the VBOs and sets the specified indices in the geometry.
+func (vd *vertexData) write(g *Geometry, indices math32.ArrayU32) {
+
+	count := vd.count()
+	for i, vbo := range vd.vbos {
+		stride := vbo.Stride()
+		buf := math32.NewArrayF32(count*stride, count*stride)
+		for v := 0; v < count; v++ {
+			copy(buf[v*stride:(v+1)*stride], vd.data[v*vd.stride+vd.offsets[i]:])
+		}
+		vbo.SetBuffer(buf)
+	}
+	g.SetIndices(indices)
+	g.invalidate()
+}
+
+// triangles returns the vertex indices of the triangles of the geometry,
+// which are the geometry indices or, if the geometry is not indexed, all the vertices in order.
+func (g *Geometry) triangles() []uint32 {
+
+	if g.Indexed() {
+		return g.indices[:g.indices.Size()/3*3]
+	}
+	count := g.Items() / 3 * 3
+	tris := make([]uint32, count)
+	for i := range tris {
+		tris[i] = uint32(i)
+	}
+	return tris
+}
+
+// invalidate marks all the geometric properties as invalid.
+func (g *Geometry) invalidate() {
+
+	g.boundingBoxValid = false
+	g.boundingSphereValid = false
+	g.areaValid = false
+	g.volumeValid = false
+	g.rotInertiaValid = false
+}
+
+// addAttribute adds a VBO with the specified attribute initialized to zero to the geometry,
+// if the geometry does not have it.
+func (g *Geometry) addAttribute(atype gls.AttribType, size int) {
+
+	if g.VBO(atype) != nil {
+		return
+	}
+	count := g.Items()
+	vbo := gls.NewVBO(math32.NewArrayF32(count*size, count*size)).AddAttrib(atype)
+	vbo.Attrib(atype).NumElements = int32(size)
+	g.AddVBO(vbo)
+}
+
+// ToNonIndexed converts the geometry to a non-indexed geometry in which each triangle
+// has its own vertices. The order of the triangles, and so the groups, are preserved.
+func (g *Geometry) ToNonIndexed() {
+
+	if !g.Indexed() {
+		return
+	}
+	vd := g.readVertexData()
+	tris := g.triangles()
+	data := make([]float32, 0, len(tris)*vd.stride)
+	for _, idx := range tris {
+		data = append(data, vd.vertex(int(idx))...)
+	}
+	vd.data = data
+	vd.write(g, nil)
+}
+
+// ToIndexed converts the geometry to an indexed geometry in which identical vertices are shared.
+// It is the same as Weld(0).
+func (g *Geometry) ToIndexed() {
+
+	g.Weld(0)
+}
+
+// Weld merges the vertices whose attributes are all equal within the specified tolerance
+// and converts the geometry to an indexed geometry which shares the merged vertices.
+// The attribute values are snapped to a grid with the tolerance as cell size when compared,
+// so values closer than the tolerance on different sides of a grid line are not merged.
+// A tolerance of 0 merges only identical vertices. The order of the triangles,
+// and so the groups, are preserved. It returns the number of vertices removed.
+func (g *Geometry) Weld(tolerance float32) int {
+
+	vd := g.readVertexData()
+	count := vd.count()
+	if count == 0 {
+		return 0
+	}
+	tris := g.triangles()
+
+	// Map each vertex to the first vertex with the same key
+	remap := make([]uint32, count)
+	unique := make(map[string]uint32)
+	key := make([]byte, 8*vd.stride)
+	data := make([]float32, 0, len(vd.data))
+	for v := 0; v < count; v++ {
+		for i, f := range vd.vertex(v) {
+			var bits uint64
+			if tolerance > 0 {
+				bits = uint64(int64(math.Floor(float64(f/tolerance) + 0.5)))
+			} else if f != 0 {
+				// Negative zero is the same as zero
+				bits = uint64(math.Float32bits(f))
+			}
+			for b := 0; b < 8; b++ {
+				key[8*i+b] = byte(bits >> (8 * uint(b)))
+			}
+		}
+		idx, ok := unique[string(key)]
+		if !ok {
+			idx = uint32(len(data) / vd.stride)
+			unique[string(key)] = idx
+			data = append(data, vd.vertex(v)...)
+		}
+		remap[v] = idx
+	}
+
+	indices := math32.NewArrayU32(len(tris), len(tris))
+	for i, idx := range tris {
+		indices[i] = remap[idx]
+	}
+	vd.data = data
+	vd.write(g, indices)
+	return count - vd.count()
+}
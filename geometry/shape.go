@@ -0,0 +1,375 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Shape is a two-dimensional closed contour, which can have holes, used to generate
+// flat, extruded and text geometries. The contour is built from lines, arcs and curves
+// which are approximated by line segments. It is implicitly closed.
+type Shape struct {
+	CurveSegments int              // Number of line segments used for each curve and quarter circle (default is 12)
+	points        []math32.Vector2 // Points of the contour
+	holes         []*Shape         // Holes of the shape
+}
+
+// NewShape creates and returns a pointer to a new empty shape.
+func NewShape() *Shape {
+
+	s := new(Shape)
+	s.CurveSegments = 12
+	return s
+}
+
+// NewShapeFromPoints creates and returns a pointer to a new shape with the specified contour points.
+func NewShapeFromPoints(points []math32.Vector2) *Shape {
+
+	s := NewShape()
+	s.points = append(s.points, points...)
+	return s
+}
+
+// MoveTo starts the contour at the specified point, discarding any previous points.
+func (s *Shape) MoveTo(x, y float32) *Shape {
+
+	s.points = append(s.points[:0], math32.Vector2{X: x, Y: y})
+	return s
+}
+
+// LineTo adds a line from the current point to the specified point.
+func (s *Shape) LineTo(x, y float32) *Shape {
+
+	s.points = append(s.points, math32.Vector2{X: x, Y: y})
+	return s
+}
+
+// QuadTo adds a quadratic Bézier curve from the current point to the specified point
+// with the specified control point.
+func (s *Shape) QuadTo(cx, cy, x, y float32) *Shape {
+
+	p0 := s.current()
+	return s.AddCurve(math32.NewBezierQuadratic(&math32.Vector3{X: p0.X, Y: p0.Y},
+		&math32.Vector3{X: cx, Y: cy}, &math32.Vector3{X: x, Y: y}, s.CurveSegments))
+}
+
+// CubicTo adds a cubic Bézier curve from the current point to the specified point
+// with the specified control points.
+func (s *Shape) CubicTo(c1x, c1y, c2x, c2y, x, y float32) *Shape {
+
+	p0 := s.current()
+	return s.AddCurve(math32.NewBezierCubic(&math32.Vector3{X: p0.X, Y: p0.Y},
+		&math32.Vector3{X: c1x, Y: c1y}, &math32.Vector3{X: c2x, Y: c2y}, &math32.Vector3{X: x, Y: y}, s.CurveSegments))
+}
+
+// Arc adds a circular arc with the specified center and radius from the start angle to
+// the end angle (in radians), preceded by a line from the current point to the arc start.
+func (s *Shape) Arc(cx, cy, radius, startAngle, endAngle float32, clockwise bool) *Shape {
+
+	sweep := endAngle - startAngle
+	if clockwise && sweep > 0 {
+		sweep -= 2 * math32.Pi
+	} else if !clockwise && sweep < 0 {
+		sweep += 2 * math32.Pi
+	}
+	segments := int(math32.Ceil(math32.Abs(sweep) / (math32.Pi / 2) * float32(s.CurveSegments)))
+	if segments < 1 {
+		segments = 1
+	}
+	for i := 0; i <= segments; i++ {
+		angle := startAngle + sweep*float32(i)/float32(segments)
+		s.addPoint(math32.Vector2{X: cx + radius*math32.Cos(angle), Y: cy + radius*math32.Sin(angle)})
+	}
+	return s
+}
+
+// AddCurve adds the points of the specified curve, using their X and Y coordinates.
+func (s *Shape) AddCurve(curve *math32.Curve) *Shape {
+
+	for _, p := range curve.GetPoints() {
+		s.addPoint(math32.Vector2{X: p.X, Y: p.Y})
+	}
+	return s
+}
+
+// AddHole adds a hole to the shape. The hole should be inside the contour and not intersect other holes.
+func (s *Shape) AddHole(hole *Shape) *Shape {
+
+	s.holes = append(s.holes, hole)
+	return s
+}
+
+// Points returns the points of the contour.
+func (s *Shape) Points() []math32.Vector2 {
+
+	return s.points
+}
+
+// Holes returns the holes of the shape.
+func (s *Shape) Holes() []*Shape {
+
+	return s.holes
+}
+
+// Triangulate triangulates the shape and its holes by ear clipping.
+// It returns the points of the contour, in counterclockwise order, followed by the
+// points of the holes, in clockwise order, and the indices of the counterclockwise triangles.
+func (s *Shape) Triangulate() ([]math32.Vector2, []uint32) {
+
+	var points []math32.Vector2
+	rings := s.rings()
+	for _, ring := range rings {
+		points = append(points, ring...)
+	}
+	return points, triangulate(rings)
+}
+
+// current returns the current point.
+func (s *Shape) current() math32.Vector2 {
+
+	if len(s.points) == 0 {
+		return math32.Vector2{}
+	}
+	return s.points[len(s.points)-1]
+}
+
+// addPoint adds the specified point if it is not the same as the current point.
+func (s *Shape) addPoint(p math32.Vector2) {
+
+	if len(s.points) > 0 && s.points[len(s.points)-1].Equals(&p) {
+		return
+	}
+	s.points = append(s.points, p)
+}
+
+// rings returns the contour in counterclockwise order followed by the holes in clockwise order,
+// without repeated consecutive points.
+func (s *Shape) rings() [][]math32.Vector2 {
+
+	rings := [][]math32.Vector2{cleanRing(s.points, true)}
+	for _, hole := range s.holes {
+		if ring := cleanRing(hole.points, false); len(ring) >= 3 {
+			rings = append(rings, ring)
+		}
+	}
+	return rings
+}
+
+// cleanRing returns a copy of the specified closed ring without repeated consecutive points,
+// in counterclockwise order if ccw is true or clockwise order otherwise.
+func cleanRing(points []math32.Vector2, ccw bool) []math32.Vector2 {
+
+	var ring []math32.Vector2
+	for i := range points {
+		if len(ring) == 0 || !ring[len(ring)-1].Equals(&points[i]) {
+			ring = append(ring, points[i])
+		}
+	}
+	for len(ring) > 1 && ring[0].Equals(&ring[len(ring)-1]) {
+		ring = ring[:len(ring)-1]
+	}
+	if (ringArea(ring) > 0) != ccw {
+		for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+			ring[i], ring[j] = ring[j], ring[i]
+		}
+	}
+	return ring
+}
+
+// ringArea returns the signed area of the specified ring, which is positive if it is counterclockwise.
+func ringArea(ring []math32.Vector2) float32 {
+
+	var area float32
+	for i := range ring {
+		a, b := &ring[i], &ring[(i+1)%len(ring)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+	return area / 2
+}
+
+// cross2 returns twice the signed area of the triangle a, b, c, which is positive if it is counterclockwise.
+func cross2(a, b, c *math32.Vector2) float32 {
+
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+// triangulate triangulates the polygon with the specified counterclockwise contour and
+// clockwise holes and returns the triangle indices into the concatenated rings.
+// The holes are first joined to the contour by bridges to their rightmost points,
+// producing a single polygon which is then triangulated by ear clipping.
+func triangulate(rings [][]math32.Vector2) []uint32 {
+
+	var points []math32.Vector2
+	var starts []int
+	for _, ring := range rings {
+		starts = append(starts, len(points))
+		points = append(points, ring...)
+	}
+	if len(rings[0]) < 3 {
+		return nil
+	}
+	poly := make([]uint32, len(rings[0]))
+	for i := range poly {
+		poly[i] = uint32(i)
+	}
+
+	// Join the holes from right to left
+	type hole struct{ start, count, right int }
+	var holes []hole
+	for h := 1; h < len(rings); h++ {
+		hl := hole{start: starts[h], count: len(rings[h]), right: starts[h]}
+		for i := hl.start; i < hl.start+hl.count; i++ {
+			if points[i].X > points[hl.right].X {
+				hl.right = i
+			}
+		}
+		holes = append(holes, hl)
+	}
+	sort.Slice(holes, func(i, j int) bool { return points[holes[i].right].X > points[holes[j].right].X })
+	for _, hl := range holes {
+		k := bridgeVertex(points, poly, hl.right)
+		if k < 0 {
+			continue
+		}
+		joined := make([]uint32, 0, len(poly)+hl.count+2)
+		joined = append(joined, poly[:k+1]...)
+		for i := 0; i <= hl.count; i++ {
+			joined = append(joined, uint32(hl.start+(hl.right-hl.start+i)%hl.count))
+		}
+		joined = append(joined, poly[k])
+		joined = append(joined, poly[k+1:]...)
+		poly = joined
+	}
+
+	// Clip ears until a triangle remains
+	var indices []uint32
+	i := 0
+	failed := 0
+	for len(poly) > 3 {
+		n := len(poly)
+		i %= n
+		a, b, c := poly[(i+n-1)%n], poly[i], poly[(i+1)%n]
+		area := cross2(&points[a], &points[b], &points[c])
+		if area == 0 || (area > 0 && isEar(points, poly, a, b, c)) || failed >= n {
+			// Collinear vertices are clipped with a flat triangle, so that the triangulation has
+			// no T-junctions, and repeated vertices without one; if there are no ears because
+			// of self intersections the vertex is clipped anyway
+			pa, pb, pc := &points[a], &points[b], &points[c]
+			if area != 0 || !(pa.Equals(pb) || pb.Equals(pc) || pc.Equals(pa)) {
+				indices = append(indices, a, b, c)
+			}
+			poly = append(poly[:i], poly[i+1:]...)
+			failed = 0
+			continue
+		}
+		i++
+		failed++
+	}
+	if len(poly) == 3 && cross2(&points[poly[0]], &points[poly[1]], &points[poly[2]]) != 0 {
+		indices = append(indices, poly...)
+	}
+	return indices
+}
+
+// isEar returns whether no other vertex of the polygon is inside the triangle a, b, c.
+func isEar(points []math32.Vector2, poly []uint32, a, b, c uint32) bool {
+
+	pa, pb, pc := &points[a], &points[b], &points[c]
+	for _, idx := range poly {
+		p := &points[idx]
+		if p.Equals(pa) || p.Equals(pb) || p.Equals(pc) {
+			continue
+		}
+		if cross2(pa, pb, p) >= 0 && cross2(pb, pc, p) >= 0 && cross2(pc, pa, p) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bridgeVertex returns the position in the polygon of the vertex visible from the specified
+// hole vertex to which the hole is joined, or -1 if there is none.
+func bridgeVertex(points []math32.Vector2, poly []uint32, holeVertex int) int {
+
+	// Find the nearest edge to the right of the hole vertex
+	m := points[holeVertex]
+	n := len(poly)
+	best := -1
+	var bestX float32
+	for i := 0; i < n; i++ {
+		a, b := &points[poly[i]], &points[poly[(i+1)%n]]
+		if (a.Y > m.Y) == (b.Y > m.Y) || a.Y == b.Y {
+			continue
+		}
+		x := a.X + (m.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y)
+		if x >= m.X && (best < 0 || x < bestX) {
+			best, bestX = i, x
+		}
+	}
+	if best < 0 {
+		return -1
+	}
+
+	// The candidate is the edge end with the largest X, unless other vertices are inside the
+	// triangle formed by the hole vertex, the intersection and the candidate, in which case
+	// the one with the smallest angle to the ray is used
+	k := best
+	if points[poly[(best+1)%n]].X > points[poly[best]].X {
+		k = (best + 1) % n
+	}
+	p := points[poly[k]]
+	inter := math32.Vector2{X: bestX, Y: m.Y}
+	tri := [3]*math32.Vector2{&m, &inter, &p}
+	if cross2(tri[0], tri[1], tri[2]) < 0 {
+		tri[1], tri[2] = tri[2], tri[1]
+	}
+	minTan := float32(-1)
+	for i := 0; i < n; i++ {
+		q := &points[poly[i]]
+		if i == k || q.X < m.X || q.Equals(&p) {
+			continue
+		}
+		if cross2(tri[0], tri[1], q) >= 0 && cross2(tri[1], tri[2], q) >= 0 && cross2(tri[2], tri[0], q) >= 0 {
+			tan := math32.Abs(q.Y-m.Y) / math32.Max(q.X-m.X, 1e-12)
+			if minTan < 0 || tan < minTan || (tan == minTan && q.X > points[poly[k]].X) {
+				minTan = tan
+				k = i
+			}
+		}
+	}
+	return k
+}
+
+// NewShapeGeometry creates and returns a pointer to a new flat geometry with the specified shapes
+// in the XY plane, facing the Z axis. The texture coordinates are the X and Y coordinates.
+func NewShapeGeometry(shapes []*Shape) *Geometry {
+
+	geom := NewGeometry()
+	positions := math32.NewArrayF32(0, 0)
+	normals := math32.NewArrayF32(0, 0)
+	uvs := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	for _, shape := range shapes {
+		points, tris := shape.Triangulate()
+		base := uint32(positions.Size() / 3)
+		for i := range points {
+			positions.Append(points[i].X, points[i].Y, 0)
+			normals.Append(0, 0, 1)
+			uvs.Append(points[i].X, points[i].Y)
+		}
+		for _, idx := range tris {
+			indices.Append(base + idx)
+		}
+	}
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return geom
+}
@@ -0,0 +1,378 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"container/heap"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Weight of the planes which keep the open edges of the mesh in place
+// relative to the planes of the triangles.
+const simplifyBorderWeight = 100
+
+// Simplify reduces the number of triangles of the geometry to the specified target, or as
+// close to it as possible, using quadric error metric edge collapse (Garland and Heckbert).
+// The edges whose collapse causes the least change of the surface are collapsed first, moving
+// the remaining vertex to the edge end or middle point with the least error and interpolating
+// its attributes. Open edges, including the seams where vertices are split because of
+// different normals or texture coordinates, are preserved as much as possible.
+// The geometry is converted to an indexed geometry, the triangle order is preserved and
+// the groups are updated. Graphics created before simplifying the geometry must have
+// their materials updated if they use groups. It returns the resulting number of triangles.
+func (g *Geometry) Simplify(targetTriangles int) int {
+
+	vd := g.readVertexData()
+	posOff := vd.offset(gls.VertexPosition)
+	tris := append([]uint32(nil), g.triangles()...)
+	faces := len(tris) / 3
+	if posOff < 0 || faces <= targetTriangles {
+		return faces
+	}
+	s := &simplifier{vd: vd, posOff: posOff, norOff: vd.offset(gls.VertexNormal), tris: tris}
+	s.init()
+
+	// Collapse the cheapest edges until reaching the target
+	live := faces
+	for live > targetTriangles && s.heap.Len() > 0 {
+		e := heap.Pop(&s.heap).(simplifyEdge)
+		if s.removed[e.v1] || s.removed[e.v2] || s.version[e.v1] != e.ver1 || s.version[e.v2] != e.ver2 {
+			continue
+		}
+		if !s.canCollapse(e) {
+			continue
+		}
+		live -= s.collapse(e)
+	}
+
+	// Keep the live triangles in order and the vertices they use
+	remap := make([]int, vd.count())
+	for i := range remap {
+		remap[i] = -1
+	}
+	data := make([]float32, 0, len(vd.data))
+	indices := math32.NewArrayU32(0, 3*live)
+	liveBefore := make([]int, faces+1)
+	for f := 0; f < faces; f++ {
+		liveBefore[f+1] = liveBefore[f]
+		if s.faceRemoved[f] {
+			continue
+		}
+		liveBefore[f+1]++
+		for c := 0; c < 3; c++ {
+			v := tris[3*f+c]
+			if remap[v] < 0 {
+				remap[v] = len(data) / vd.stride
+				data = append(data, vd.vertex(int(v))...)
+			}
+			indices.Append(uint32(remap[v]))
+		}
+	}
+	vd.data = data
+	vd.write(g, indices)
+
+	// Update the groups for the removed triangles
+	for i := range g.groups {
+		group := &g.groups[i]
+		first := group.Start / 3
+		last := (group.Start + group.Count) / 3
+		if first > faces {
+			first = faces
+		}
+		if last > faces {
+			last = faces
+		}
+		group.Start = 3 * liveBefore[first]
+		group.Count = 3*liveBefore[last] - group.Start
+	}
+	return live
+}
+
+// quadric is a symmetric 4x4 matrix which sums the squared distances of a point
+// to a set of planes, stored as its upper triangle.
+type quadric [10]float64
+
+// addPlane adds the plane ax+by+cz+d=0 with the specified weight to the quadric.
+func (q *quadric) addPlane(a, b, c, d, weight float64) {
+
+	q[0] += weight * a * a
+	q[1] += weight * a * b
+	q[2] += weight * a * c
+	q[3] += weight * a * d
+	q[4] += weight * b * b
+	q[5] += weight * b * c
+	q[6] += weight * b * d
+	q[7] += weight * c * c
+	q[8] += weight * c * d
+	q[9] += weight * d * d
+}
+
+// add adds the specified quadric to this one.
+func (q *quadric) add(other *quadric) {
+
+	for i := range q {
+		q[i] += other[i]
+	}
+}
+
+// eval returns the error of the specified point.
+func (q *quadric) eval(p *math32.Vector3) float64 {
+
+	x, y, z := float64(p.X), float64(p.Y), float64(p.Z)
+	return q[0]*x*x + 2*q[1]*x*y + 2*q[2]*x*z + 2*q[3]*x +
+		q[4]*y*y + 2*q[5]*y*z + 2*q[6]*y +
+		q[7]*z*z + 2*q[8]*z + q[9]
+}
+
+// simplifyEdge is an edge candidate for collapse.
+type simplifyEdge struct {
+	cost       float64 // Error of the collapse
+	v1, v2     uint32  // Vertices of the edge; v2 is collapsed into v1
+	ver1, ver2 int     // Versions of the vertices when the edge was evaluated
+	t          float32 // Position of the collapsed vertex along the edge from v1 to v2
+}
+
+// edgeHeap is a priority queue of edges ordered by cost.
+type edgeHeap []simplifyEdge
+
+func (h edgeHeap) Len() int            { return len(h) }
+func (h edgeHeap) Less(i, j int) bool  { return h[i].cost < h[j].cost }
+func (h edgeHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *edgeHeap) Push(x interface{}) { *h = append(*h, x.(simplifyEdge)) }
+func (h *edgeHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
+
+// simplifier contains the state of a mesh simplification.
+type simplifier struct {
+	vd          *vertexData // Vertices, modified in place by the collapses
+	posOff      int         // Offset of the position in a vertex
+	norOff      int         // Offset of the normal in a vertex or -1
+	tris        []uint32    // Triangle vertex indices, updated by the collapses
+	faceRemoved []bool      // Whether each triangle was removed
+	vertFaces   [][]int     // Triangles which use each vertex (may include removed triangles)
+	quadrics    []quadric   // Quadric of each vertex
+	removed     []bool      // Whether each vertex was collapsed
+	version     []int       // Number of times each vertex was changed
+	heap        edgeHeap    // Candidate edges
+}
+
+// init computes the vertex quadrics and the initial candidate edges.
+func (s *simplifier) init() {
+
+	count := s.vd.count()
+	faces := len(s.tris) / 3
+	s.faceRemoved = make([]bool, faces)
+	s.vertFaces = make([][]int, count)
+	s.quadrics = make([]quadric, count)
+	s.removed = make([]bool, count)
+	s.version = make([]int, count)
+
+	// Add the plane of each triangle, weighted by its area, to its vertices
+	type edgeKey struct{ a, b uint32 }
+	edgeFaces := make(map[edgeKey][]int)
+	var edges []edgeKey
+	for f := 0; f < faces; f++ {
+		normal, area := s.faceNormal(f, 0, nil)
+		p0 := s.position(s.tris[3*f])
+		d := -float64(normal.Dot(&p0))
+		for c := 0; c < 3; c++ {
+			v := s.tris[3*f+c]
+			s.vertFaces[v] = append(s.vertFaces[v], f)
+			s.quadrics[v].addPlane(float64(normal.X), float64(normal.Y), float64(normal.Z), d, float64(area))
+			a, b := v, s.tris[3*f+(c+1)%3]
+			if a > b {
+				a, b = b, a
+			}
+			key := edgeKey{a, b}
+			if edgeFaces[key] == nil {
+				edges = append(edges, key)
+			}
+			edgeFaces[key] = append(edgeFaces[key], f)
+		}
+	}
+
+	// Add the planes perpendicular to the triangles through the open edges to keep them in place
+	for _, key := range edges {
+		if fs := edgeFaces[key]; len(fs) == 1 {
+			pa := s.position(key.a)
+			pb := s.position(key.b)
+			normal, _ := s.faceNormal(fs[0], 0, nil)
+			var dir, plane math32.Vector3
+			dir.SubVectors(&pb, &pa)
+			weight := float64(dir.Dot(&dir)) * simplifyBorderWeight
+			plane.CrossVectors(&dir, &normal).Normalize()
+			d := -float64(plane.Dot(&pa))
+			for _, v := range []uint32{key.a, key.b} {
+				s.quadrics[v].addPlane(float64(plane.X), float64(plane.Y), float64(plane.Z), d, weight)
+			}
+		}
+	}
+	for _, key := range edges {
+		s.heap = append(s.heap, s.edge(key.a, key.b))
+	}
+	heap.Init(&s.heap)
+}
+
+// position returns the position of the specified vertex.
+func (s *simplifier) position(v uint32) math32.Vector3 {
+
+	return s.vd.vector3(int(v), s.posOff)
+}
+
+// faceNormal returns the unit normal and the area of the specified triangle with
+// the vertex v, if pos is not nil, moved to the specified position.
+func (s *simplifier) faceNormal(f int, v uint32, pos *math32.Vector3) (math32.Vector3, float32) {
+
+	var p [3]math32.Vector3
+	for c := 0; c < 3; c++ {
+		idx := s.tris[3*f+c]
+		if pos != nil && idx == v {
+			p[c] = *pos
+		} else {
+			p[c] = s.position(idx)
+		}
+	}
+	var e1, e2, normal math32.Vector3
+	e1.SubVectors(&p[1], &p[0])
+	e2.SubVectors(&p[2], &p[0])
+	normal.CrossVectors(&e1, &e2)
+	area := normal.Length() / 2
+	normal.Normalize()
+	return normal, area
+}
+
+// edge returns the edge between the specified vertices with the cost of its collapse.
+func (s *simplifier) edge(v1, v2 uint32) simplifyEdge {
+
+	var q quadric
+	q = s.quadrics[v1]
+	q.add(&s.quadrics[v2])
+	p1 := s.position(v1)
+	p2 := s.position(v2)
+	e := simplifyEdge{v1: v1, v2: v2, ver1: s.version[v1], ver2: s.version[v2]}
+	e.cost = q.eval(&p1)
+	if cost := q.eval(&p2); cost < e.cost {
+		e.cost, e.t = cost, 1
+	}
+	mid := p1
+	mid.Lerp(&p2, 0.5)
+	if cost := q.eval(&mid); cost < e.cost {
+		e.cost, e.t = cost, 0.5
+	}
+	return e
+}
+
+// canCollapse returns whether the collapse of the specified edge keeps the mesh manifold
+// and does not flip any triangle.
+func (s *simplifier) canCollapse(e simplifyEdge) bool {
+
+	p1 := s.position(e.v1)
+	p2 := s.position(e.v2)
+	pos := p1
+	pos.Lerp(&p2, e.t)
+
+	// The vertices adjacent to both edge vertices must be the ones opposite to the edge
+	neighbors := make(map[uint32]int)
+	shared := 0
+	for _, v := range []uint32{e.v1, e.v2} {
+		for _, f := range s.vertFaces[v] {
+			if s.faceRemoved[f] {
+				continue
+			}
+			if s.hasVertex(f, e.v1) && s.hasVertex(f, e.v2) {
+				if v == e.v1 {
+					shared++
+				}
+				continue
+			}
+			normal, _ := s.faceNormal(f, 0, nil)
+			moved, area := s.faceNormal(f, v, &pos)
+			if area == 0 || normal.Dot(&moved) < 0.2 {
+				return false
+			}
+			bit := 1
+			if v == e.v2 {
+				bit = 2
+			}
+			for c := 0; c < 3; c++ {
+				if n := s.tris[3*f+c]; n != v {
+					neighbors[n] |= bit
+				}
+			}
+		}
+	}
+	common := 0
+	for _, mask := range neighbors {
+		if mask == 3 {
+			common++
+		}
+	}
+	return common <= shared
+}
+
+// hasVertex returns whether the specified triangle uses the specified vertex.
+func (s *simplifier) hasVertex(f int, v uint32) bool {
+
+	return s.tris[3*f] == v || s.tris[3*f+1] == v || s.tris[3*f+2] == v
+}
+
+// collapse collapses the specified edge and returns the number of triangles removed.
+func (s *simplifier) collapse(e simplifyEdge) int {
+
+	// Interpolate the attributes of the remaining vertex
+	a := s.vd.vertex(int(e.v1))
+	b := s.vd.vertex(int(e.v2))
+	for i := range a {
+		a[i] += (b[i] - a[i]) * e.t
+	}
+	if s.norOff >= 0 {
+		normal := s.vd.vector3(int(e.v1), s.norOff)
+		s.vd.setVector3(int(e.v1), s.norOff, normal.Normalize())
+	}
+	s.quadrics[e.v1].add(&s.quadrics[e.v2])
+	s.removed[e.v2] = true
+	s.version[e.v1]++
+
+	// Remove the triangles of the edge and move the other triangles to the remaining vertex
+	removed := 0
+	for _, f := range s.vertFaces[e.v2] {
+		if s.faceRemoved[f] {
+			continue
+		}
+		if s.hasVertex(f, e.v1) {
+			s.faceRemoved[f] = true
+			removed++
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			if s.tris[3*f+c] == e.v2 {
+				s.tris[3*f+c] = e.v1
+			}
+		}
+		s.vertFaces[e.v1] = append(s.vertFaces[e.v1], f)
+	}
+	s.vertFaces[e.v2] = nil
+
+	// Evaluate again the edges of the remaining vertex
+	pushed := make(map[uint32]bool)
+	for _, f := range s.vertFaces[e.v1] {
+		if s.faceRemoved[f] {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			if n := s.tris[3*f+c]; n != e.v1 && !pushed[n] {
+				pushed[n] = true
+				heap.Push(&s.heap, s.edge(e.v1, n))
+			}
+		}
+	}
+	return removed
+}
@@ -0,0 +1,374 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"errors"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// mikkMinFloat is the smallest normal float32, below which MikkTSpace considers values to be zero.
+const mikkMinFloat = 1.17549435e-38
+
+// mikkCosThreshold is the cosine of the angle between the tangents of the triangles
+// around a vertex above which they are averaged, which is the MikkTSpace default of 180 degrees.
+const mikkCosThreshold = -1
+
+// mikkFace contains the tangent space information of a triangle.
+type mikkFace struct {
+	os, ot     math32.Vector3 // Unit tangent and bitangent, negated if the orientation isn't preserved
+	preserving bool           // Whether the texture coordinates preserve the orientation of the triangle
+	any        bool           // Whether the texture coordinates are degenerate, so the triangle can join any group
+	degenerate bool           // Whether two corners of the triangle have the same position
+	neighbors  [3]int         // Triangles sharing the edge which starts at each corner, or -1
+	groups     [3]*mikkGroup  // Group of each corner
+}
+
+// mikkGroup is a group of triangles sharing a vertex, connected by their edges,
+// whose texture coordinates have the same orientation.
+type mikkGroup struct {
+	vertex     uint32 // Shared index of the vertex
+	preserving bool   // Whether the texture coordinates of the triangles preserve their orientation
+	faces      []int  // Triangles of the group
+}
+
+// ComputeTangents computes the vertex tangents used for normal mapping from the vertex
+// positions, normals and texture coordinates, adding them if needed. The tangents are
+// computed with the MikkTSpace algorithm, used by the glTF specification and most tools,
+// so normal maps baked with MikkTSpace tangents are displayed without seams.
+// The tangent space of each triangle corner is the angle weighted average of the tangents,
+// projected on the plane of the normal, of the triangles which share the corner vertex,
+// are connected to the corner by edges and whose texture coordinates have the same orientation.
+// The bitangent handedness (1 or -1) is in the W component,
+// so that bitangent = cross(normal, tangent.xyz) * tangent.w.
+// Indexed vertices which need different tangents at different corners are duplicated.
+// If the geometry already has tangents with 3 elements, the handedness is not written.
+func (g *Geometry) ComputeTangents() error {
+
+	if g.VBO(gls.VertexPosition) == nil || g.VBO(gls.VertexNormal) == nil || g.VBO(gls.VertexTexcoord) == nil {
+		return errors.New("geometry needs vertex positions, normals and texture coordinates to compute tangents")
+	}
+	g.addAttribute(gls.VertexTangent, 4)
+	vd := g.readVertexData()
+	posOff := vd.offset(gls.VertexPosition)
+	norOff := vd.offset(gls.VertexNormal)
+	uvOff := vd.offset(gls.VertexTexcoord)
+	tanOff := vd.offset(gls.VertexTangent)
+	tanSize := int(g.VBO(gls.VertexTangent).Attrib(gls.VertexTangent).NumElements)
+	tris := g.triangles()
+
+	// Share the indices of the vertices with the same position, normal and texture coordinates
+	type vertexKey struct {
+		pos, normal math32.Vector3
+		uv          math32.Vector2
+	}
+	sharedIdx := make(map[vertexKey]uint32)
+	shared := make([]uint32, len(tris))
+	for i, idx := range tris {
+		v := vd.vertex(int(idx))
+		key := vertexKey{vd.vector3(int(idx), posOff), vd.vector3(int(idx), norOff), math32.Vector2{X: v[uvOff], Y: v[uvOff+1]}}
+		sidx, ok := sharedIdx[key]
+		if !ok {
+			sidx = idx
+			sharedIdx[key] = idx
+		}
+		shared[i] = sidx
+	}
+
+	// Compute the tangent and bitangent of each triangle and the orientation of its texture coordinates
+	faces := make([]mikkFace, len(tris)/3)
+	for f := range faces {
+		mf := &faces[f]
+		mf.neighbors = [3]int{-1, -1, -1}
+		mf.any = true
+		var p [3]math32.Vector3
+		var uv [3]math32.Vector2
+		for c := 0; c < 3; c++ {
+			v := vd.vertex(int(shared[3*f+c]))
+			p[c] = math32.Vector3{X: v[posOff], Y: v[posOff+1], Z: v[posOff+2]}
+			uv[c] = math32.Vector2{X: v[uvOff], Y: v[uvOff+1]}
+		}
+		if p[0].Equals(&p[1]) || p[0].Equals(&p[2]) || p[1].Equals(&p[2]) {
+			mf.degenerate = true
+			continue
+		}
+		var d1, d2, tmp math32.Vector3
+		d1.SubVectors(&p[1], &p[0])
+		d2.SubVectors(&p[2], &p[0])
+		t21x, t21y := uv[1].X-uv[0].X, uv[1].Y-uv[0].Y
+		t31x, t31y := uv[2].X-uv[0].X, uv[2].Y-uv[0].Y
+		area := t21x*t31y - t21y*t31x
+		mf.preserving = area > 0
+		mf.os.Copy(&d1).MultiplyScalar(t31y).Sub(tmp.Copy(&d2).MultiplyScalar(t21y))
+		mf.ot.Copy(&d2).MultiplyScalar(t21x).Sub(tmp.Copy(&d1).MultiplyScalar(t31x))
+		if math32.Abs(area) > mikkMinFloat {
+			sign := float32(1)
+			if !mf.preserving {
+				sign = -1
+			}
+			lenOs, lenOt := mf.os.Length(), mf.ot.Length()
+			if lenOs > mikkMinFloat {
+				mf.os.MultiplyScalar(sign / lenOs)
+			}
+			if lenOt > mikkMinFloat {
+				mf.ot.MultiplyScalar(sign / lenOt)
+			}
+			mf.any = lenOs <= mikkMinFloat || lenOt <= mikkMinFloat
+		}
+	}
+
+	// Find the neighbors of the triangles, which share an edge in the opposite direction
+	type edge struct{ from, to uint32 }
+	edges := make(map[edge][]int)
+	for f := range faces {
+		if faces[f].degenerate {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			e := edge{shared[3*f+c], shared[3*f+(c+1)%3]}
+			edges[e] = append(edges[e], 3*f+c)
+		}
+	}
+	for f := range faces {
+		if faces[f].degenerate {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			if faces[f].neighbors[c] >= 0 {
+				continue
+			}
+			for _, corner := range edges[edge{shared[3*f+(c+1)%3], shared[3*f+c]}] {
+				other, oc := corner/3, corner%3
+				if other != f && faces[other].neighbors[oc] < 0 {
+					faces[f].neighbors[c] = other
+					faces[other].neighbors[oc] = f
+					break
+				}
+			}
+		}
+	}
+
+	// corner returns the corner of the triangle at the vertex with the specified shared index
+	corner := func(f int, sidx uint32) int {
+		for c := 0; c < 3; c++ {
+			if shared[3*f+c] == sidx {
+				return c
+			}
+		}
+		return -1
+	}
+
+	// Group the triangles around each vertex which are connected by their edges and whose
+	// texture coordinates have the same orientation. A triangle with degenerate texture
+	// coordinates takes the orientation of the first group it joins.
+	var groups []*mikkGroup
+	var assign func(f int, group *mikkGroup)
+	assign = func(f int, group *mikkGroup) {
+		mf := &faces[f]
+		c := corner(f, group.vertex)
+		if mf.groups[c] != nil {
+			return
+		}
+		if mf.any && mf.groups[0] == nil && mf.groups[1] == nil && mf.groups[2] == nil {
+			mf.preserving = group.preserving
+		}
+		if mf.preserving != group.preserving {
+			return
+		}
+		group.faces = append(group.faces, f)
+		mf.groups[c] = group
+		if n := mf.neighbors[c]; n >= 0 {
+			assign(n, group)
+		}
+		if n := mf.neighbors[(c+2)%3]; n >= 0 {
+			assign(n, group)
+		}
+	}
+	for f := range faces {
+		if faces[f].degenerate || faces[f].any {
+			continue
+		}
+		for c := 0; c < 3; c++ {
+			if faces[f].groups[c] == nil {
+				group := &mikkGroup{vertex: shared[3*f+c], preserving: faces[f].preserving}
+				assign(f, group)
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	// Compute the tangent space of each corner of the groups as the average of the tangents of the
+	// triangles of the group which are within the angle threshold of the tangents of its triangle
+	tangents := make([][4]float32, len(tris))
+	computed := make([]bool, len(tris))
+	for _, group := range groups {
+		normal := vd.vector3(int(group.vertex), norOff)
+		normal.Normalize()
+		w := float32(1)
+		if !group.preserving {
+			w = -1
+		}
+		var subgroups [][]int
+		var subTangents []math32.Vector3
+		for _, f := range group.faces {
+			os := mikkProject(faces[f].os, &normal)
+			ot := mikkProject(faces[f].ot, &normal)
+			var members []int
+			for _, other := range group.faces {
+				os2 := mikkProject(faces[other].os, &normal)
+				ot2 := mikkProject(faces[other].ot, &normal)
+				if faces[f].any || faces[other].any || f == other ||
+					(os.Dot(&os2) > mikkCosThreshold && ot.Dot(&ot2) > mikkCosThreshold) {
+					members = append(members, other)
+				}
+			}
+			s := 0
+			for s < len(subgroups) && !sameFaces(subgroups[s], members) {
+				s++
+			}
+			if s == len(subgroups) {
+				subgroups = append(subgroups, members)
+				subTangents = append(subTangents, mikkTangent(vd, posOff, faces, shared, members, group.vertex, &normal))
+			}
+			t := subTangents[s]
+			c := 3*f + corner(f, group.vertex)
+			tangents[c] = [4]float32{t.X, t.Y, t.Z, w}
+			computed[c] = true
+		}
+	}
+
+	// The corners of degenerate triangles take the tangent space of another corner at the same vertex.
+	// Corners without tangent space, such as those of isolated triangles with degenerate texture
+	// coordinates, take a tangent orthogonal to their normal.
+	vertexTangents := make(map[uint32][4]float32)
+	for i, sidx := range shared {
+		if _, ok := vertexTangents[sidx]; !ok && computed[i] {
+			vertexTangents[sidx] = tangents[i]
+		}
+	}
+	for i, sidx := range shared {
+		if computed[i] {
+			continue
+		}
+		if t, ok := vertexTangents[sidx]; ok {
+			tangents[i] = t
+			continue
+		}
+		normal := vd.vector3(int(sidx), norOff)
+		normal.Normalize()
+		t, _ := normal.RandomTangents()
+		tangents[i] = [4]float32{t.X, t.Y, t.Z, 1}
+	}
+
+	// Assign the tangent of each corner to its vertex,
+	// duplicating the vertex if it already has a different tangent
+	assigned := make([]bool, vd.count())
+	copies := make(map[uint32][]uint32)
+	indices := math32.NewArrayU32(len(tris), len(tris))
+	setTangent := func(idx uint32, t [4]float32) {
+		out := vd.vertex(int(idx))[tanOff:]
+		copy(out[:tanSize], t[:tanSize])
+	}
+	hasTangent := func(idx uint32, t [4]float32) bool {
+		out := vd.vertex(int(idx))[tanOff:]
+		for i := 0; i < tanSize; i++ {
+			if out[i] != t[i] {
+				return false
+			}
+		}
+		return true
+	}
+	for i, idx := range tris {
+		indices[i] = idx
+		if !assigned[idx] {
+			assigned[idx] = true
+			setTangent(idx, tangents[i])
+			continue
+		}
+		if hasTangent(idx, tangents[i]) {
+			continue
+		}
+		found := false
+		for _, cidx := range copies[idx] {
+			if hasTangent(cidx, tangents[i]) {
+				indices[i] = cidx
+				found = true
+				break
+			}
+		}
+		if !found {
+			cidx := uint32(vd.count())
+			vd.data = append(vd.data, vd.vertex(int(idx))...)
+			setTangent(cidx, tangents[i])
+			copies[idx] = append(copies[idx], cidx)
+			indices[i] = cidx
+		}
+	}
+	if !g.Indexed() {
+		indices = nil
+	}
+	vd.write(g, indices)
+	return nil
+}
+
+// mikkProject returns the unit projection of the vector on the plane of the unit normal,
+// or the projection itself if it is zero.
+func mikkProject(v math32.Vector3, normal *math32.Vector3) math32.Vector3 {
+
+	var proj math32.Vector3
+	v.Sub(proj.Copy(normal).MultiplyScalar(normal.Dot(&v)))
+	if v.Length() > mikkMinFloat {
+		v.Normalize()
+	}
+	return v
+}
+
+// mikkTangent returns the average of the tangents of the specified triangles at the vertex with
+// the specified shared index, weighted by the angles of the triangles at the vertex.
+// Triangles with degenerate texture coordinates don't contribute to the average.
+func mikkTangent(vd *vertexData, posOff int, faces []mikkFace, shared []uint32, members []int, sidx uint32, normal *math32.Vector3) math32.Vector3 {
+
+	var res math32.Vector3
+	for _, f := range members {
+		if faces[f].any {
+			continue
+		}
+		c := 0
+		for shared[3*f+c] != sidx {
+			c++
+		}
+		os := mikkProject(faces[f].os, normal)
+		p0 := vd.vector3(int(shared[3*f+(c+2)%3]), posOff)
+		p1 := vd.vector3(int(shared[3*f+c]), posOff)
+		p2 := vd.vector3(int(shared[3*f+(c+1)%3]), posOff)
+		var v1, v2 math32.Vector3
+		v1.SubVectors(&p0, &p1)
+		v2.SubVectors(&p2, &p1)
+		v1 = mikkProject(v1, normal)
+		v2 = mikkProject(v2, normal)
+		angle := math32.Acos(math32.Clamp(v1.Dot(&v2), -1, 1))
+		res.Add(os.MultiplyScalar(angle))
+	}
+	if res.Length() > mikkMinFloat {
+		res.Normalize()
+	}
+	return res
+}
+
+// sameFaces returns whether the two lists of triangles are equal.
+func sameFaces(a, b []int) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"testing"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// vertexTangents returns the tangents of the vertices of the geometry.
+func vertexTangents(g *Geometry) []math32.Vector4 {
+
+	vd := g.readVertexData()
+	off := vd.offset(gls.VertexTangent)
+	tangents := make([]math32.Vector4, vd.count())
+	for i := range tangents {
+		v := vd.vertex(i)[off:]
+		tangents[i] = math32.Vector4{X: v[0], Y: v[1], Z: v[2], W: v[3]}
+	}
+	return tangents
+}
+
+// Tests the tangents of a plane whose texture coordinates are aligned with its axes
+func TestComputeTangentsPlane(t *testing.T) {
+
+	plane := NewSegmentedPlane(2, 2, 2, 2)
+	if err := plane.ComputeTangents(); err != nil {
+		t.Fatal(err)
+	}
+	expected := math32.Vector4{X: 1, Y: 0, Z: 0, W: 1}
+	for i, tan := range vertexTangents(plane) {
+		if !tan.Equals(&expected) {
+			t.Errorf("vertex:%d tangent:%v", i, tan)
+		}
+	}
+}
+
+// Tests that the vertices shared by triangles with mirrored texture coordinates are split
+func TestComputeTangentsMirrored(t *testing.T) {
+
+	g := NewGeometry()
+	positions := math32.NewArrayF32(0, 12)
+	positions.Append(0, 0, 0, 0, 1, 0, 1, 0, 0, -1, 0, 0)
+	normals := math32.NewArrayF32(0, 12)
+	normals.Append(0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1)
+	uvs := math32.NewArrayF32(0, 8)
+	uvs.Append(0, 0, 0, 1, 1, 0, 1, 0)
+	indices := math32.NewArrayU32(0, 6)
+	indices.Append(0, 2, 1, 0, 1, 3)
+	g.SetIndices(indices)
+	g.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	g.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	g.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	if err := g.ComputeTangents(); err != nil {
+		t.Fatal(err)
+	}
+
+	tangents := vertexTangents(g)
+	if len(tangents) != 6 {
+		t.Fatalf("vertices:%d", len(tangents))
+	}
+	right := math32.Vector4{X: 1, Y: 0, Z: 0, W: 1}
+	left := math32.Vector4{X: -1, Y: 0, Z: 0, W: -1}
+	for i, idx := range g.Indices() {
+		expected := right
+		if i >= 3 {
+			expected = left
+		}
+		if !tangents[idx].Equals(&expected) {
+			t.Errorf("corner:%d tangent:%v expected:%v", i, tangents[idx], expected)
+		}
+	}
+}
+
+// Tests that the tangents of the vertices of a sphere are unit vectors orthogonal to their normals
+func TestComputeTangentsSphere(t *testing.T) {
+
+	sphere := NewSphere(1, 16, 12)
+	if err := sphere.ComputeTangents(); err != nil {
+		t.Fatal(err)
+	}
+	vd := sphere.readVertexData()
+	norOff := vd.offset(gls.VertexNormal)
+	tangents := vertexTangents(sphere)
+	for _, i := range sphere.Indices() {
+		tan := tangents[i]
+		normal := vd.vector3(int(i), norOff)
+		t3 := math32.Vector3{X: tan.X, Y: tan.Y, Z: tan.Z}
+		if math32.Abs(t3.Length()-1) > 1e-4 || math32.Abs(t3.Dot(&normal)) > 1e-4 || math32.Abs(tan.W) != 1 {
+			t.Errorf("vertex:%d tangent:%v normal:%v", i, tan, normal)
+		}
+	}
+}
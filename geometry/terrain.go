@@ -0,0 +1,99 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// NewTerrain creates and returns a pointer to a new terrain geometry, which is a grid in the XZ plane
+// starting at x0, z0 with the specified number of cells along each axis and cell size, with the heights
+// of the specified source at its vertices. The vertex normals are computed from the neighbour samples,
+// so they are continuous between adjacent grids with the same cell size.
+// If the skirt depth is positive, a vertical strip of that depth hangs from the border of the grid
+// to hide the cracks between adjacent grids with different cell sizes.
+// The texture coordinates are the X and Z coordinates of the vertices.
+func NewTerrain(src HeightSource, x0, z0 float32, cells int, cellSize, skirt float32) *Geometry {
+
+	if cells < 1 {
+		cells = 1
+	}
+	n := cells + 1
+
+	// Sample the grid with a border of one sample for the normals
+	samples := make([]float32, (n+2)*(n+2))
+	sample := func(c, r int) float32 {
+		return samples[(r+1)*(n+2)+c+1]
+	}
+	for r := -1; r <= n; r++ {
+		for c := -1; c <= n; c++ {
+			samples[(r+1)*(n+2)+c+1] = src.Height(x0+float32(c)*cellSize, z0+float32(r)*cellSize)
+		}
+	}
+
+	positions := math32.NewArrayF32(0, n*n*3)
+	normals := math32.NewArrayF32(0, n*n*3)
+	uvs := math32.NewArrayF32(0, n*n*2)
+	indices := math32.NewArrayU32(0, cells*cells*6)
+	vertex := func(c, r int, y float32) {
+		x, z := x0+float32(c)*cellSize, z0+float32(r)*cellSize
+		normal := math32.Vector3{
+			X: sample(c-1, r) - sample(c+1, r),
+			Y: 2 * cellSize,
+			Z: sample(c, r-1) - sample(c, r+1),
+		}
+		normal.Normalize()
+		positions.Append(x, y, z)
+		normals.AppendVector3(&normal)
+		uvs.Append(x, z)
+	}
+	for r := 0; r < n; r++ {
+		for c := 0; c < n; c++ {
+			vertex(c, r, sample(c, r))
+		}
+	}
+	for r := 0; r < cells; r++ {
+		for c := 0; c < cells; c++ {
+			a := uint32(r*n + c)
+			b := a + uint32(n)
+			indices.Append(a, b, a+1, b, b+1, a+1)
+		}
+	}
+
+	// The skirt goes around the border with its faces outwards
+	if skirt > 0 {
+		var border [][2]int
+		for c := 0; c < cells; c++ {
+			border = append(border, [2]int{c, 0})
+		}
+		for r := 0; r < cells; r++ {
+			border = append(border, [2]int{cells, r})
+		}
+		for c := cells; c > 0; c-- {
+			border = append(border, [2]int{c, cells})
+		}
+		for r := cells; r > 0; r-- {
+			border = append(border, [2]int{0, r})
+		}
+		base := uint32(positions.Size() / 3)
+		for _, p := range border {
+			vertex(p[0], p[1], sample(p[0], p[1])-skirt)
+		}
+		count := uint32(len(border))
+		for i := uint32(0); i < count; i++ {
+			j := (i + 1) % count
+			p, q := uint32(border[i][1]*n+border[i][0]), uint32(border[j][1]*n+border[j][0])
+			indices.Append(p, q, base+j, p, base+j, base+i)
+		}
+	}
+
+	geom := NewGeometry()
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(uvs).AddAttrib(gls.VertexTexcoord))
+	return geom
+}
@@ -0,0 +1,110 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package geometry
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+)
+
+// Number of line segments used for each curve of the glyph outlines
+const textCurveSegments = 6
+
+// NewText creates and returns a pointer to a new geometry with the glyphs of the specified text,
+// which can contain line breaks (\n), in the XY plane with the origin at the left of the baseline
+// of the first line. The size is the font size (the em height) in geometry units.
+// If extrude options are specified the glyphs are extruded as done by NewExtrude,
+// otherwise the geometry is flat as done by NewShapeGeometry.
+func NewText(font *text.Font, str string, size float32, opts *ExtrudeOptions) *Geometry {
+
+	shapes := TextShapes(font, str, size)
+	if opts == nil {
+		return NewShapeGeometry(shapes)
+	}
+	return NewExtrude(shapes, opts)
+}
+
+// TextShapes returns the shapes of the glyphs of the specified text, scaled to the specified font size.
+// The outer contours of the glyph outlines become shapes and the contours inside them become their holes.
+func TextShapes(font *text.Font, str string, size float32) []*Shape {
+
+	// Build a contour from each outline contour
+	var contours []*Shape
+	var current *Shape
+	for _, seg := range font.Outline(str) {
+		p := seg.Points
+		for i := range p {
+			p[i].MultiplyScalar(size)
+		}
+		switch seg.Op {
+		case text.OutlineMoveTo:
+			current = NewShape()
+			current.CurveSegments = textCurveSegments
+			current.MoveTo(p[0].X, p[0].Y)
+			contours = append(contours, current)
+		case text.OutlineLineTo:
+			current.LineTo(p[0].X, p[0].Y)
+		case text.OutlineQuadTo:
+			current.QuadTo(p[0].X, p[0].Y, p[1].X, p[1].Y)
+		case text.OutlineCubeTo:
+			current.CubicTo(p[0].X, p[0].Y, p[1].X, p[1].Y, p[2].X, p[2].Y)
+		}
+	}
+	return nestContours(contours)
+}
+
+// nestContours returns the shapes formed by the specified contours, in which the contours inside an odd
+// number of other contours are holes of the smallest contour containing them. This does not depend on
+// the orientation of the contours, which is different in TrueType and PostScript fonts.
+func nestContours(contours []*Shape) []*Shape {
+
+	areas := make(map[*Shape]float32)
+	for _, c := range contours {
+		c.points = cleanRing(c.points, true)
+		areas[c] = ringArea(c.points)
+	}
+	sort.SliceStable(contours, func(i, j int) bool { return areas[contours[i]] > areas[contours[j]] })
+
+	var shapes []*Shape
+	depths := make(map[*Shape]int)
+	for i, c := range contours {
+		if len(c.points) < 3 {
+			continue
+		}
+		// The containing contours are larger so they were already processed
+		var parent *Shape
+		for j := i - 1; j >= 0; j-- {
+			if len(contours[j].points) >= 3 && pointInRing(&c.points[0], contours[j].points) {
+				parent = contours[j]
+				break
+			}
+		}
+		if parent != nil && depths[parent]%2 == 0 {
+			depths[c] = depths[parent] + 1
+			parent.AddHole(c)
+			continue
+		}
+		if parent != nil {
+			depths[c] = depths[parent] + 1
+		}
+		shapes = append(shapes, c)
+	}
+	return shapes
+}
+
+// pointInRing returns whether the specified point is inside the specified ring.
+func pointInRing(p *math32.Vector2, ring []math32.Vector2) bool {
+
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := &ring[i], &ring[j]
+		if (a.Y > p.Y) != (b.Y > p.Y) && p.X < (b.X-a.X)*(p.Y-a.Y)/(b.Y-a.Y)+a.X {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -27,6 +27,7 @@ type GLS struct {
 	viewportY           int32       // cached last set viewport y
 	viewportWidth       int32       // cached last set viewport width
 	viewportHeight      int32       // cached last set viewport height
+	clearColor          [4]float32  // cached last set clear color
 	lineWidth           float32     // cached last set line width
 	sideView            int         // cached last set triangle side view mode
 	frontFace           uint32      // cached last set glFrontFace value
@@ -292,6 +293,7 @@ func (gs *GLS) ClearColor(r, g, b, a float32) {
 
 	gs.gl.Call("clearColor", r, g, b, a)
 	gs.checkError("ClearColor")
+	gs.clearColor = [4]float32{r, g, b, a}
 }
 
 // ClearDepth specifies the depth value used by Clear to clear the depth buffer.
@@ -316,6 +318,13 @@ func (gs *GLS) Clear(mask uint) {
 	gs.checkError("Clear")
 }
 
+// ColorMask enables or disables writing of the frame buffer color components.
+func (gs *GLS) ColorMask(red, green, blue, alpha bool) {
+
+	gs.gl.Call("colorMask", red, green, blue, alpha)
+	gs.checkError("ColorMask")
+}
+
 // CompileShader compiles the source code strings that
 // have been stored in the specified shader object.
 func (gs *GLS) CompileShader(shader uint32) {
@@ -606,6 +615,12 @@ func (gs *GLS) GetViewport() (x, y, width, height int32) {
 	return gs.viewportX, gs.viewportY, gs.viewportWidth, gs.viewportHeight
 }
 
+// GetClearColor returns the current clear color.
+func (gs *GLS) GetClearColor() (r, g, b, a float32) {
+
+	return gs.clearColor[0], gs.clearColor[1], gs.clearColor[2], gs.clearColor[3]
+}
+
 // LineWidth specifies the rasterized width of both aliased and antialiased lines.
 func (gs *GLS) LineWidth(width float32) {
 
@@ -26,16 +26,17 @@ type GLS struct {
 	checkErrors bool              // check openGL API errors flag
 
 	// Cache OpenGL state to avoid making unnecessary API calls
-	activeTexture  uint32  // cached last set active texture unit
-	viewportX      int32   // cached last set viewport x
-	viewportY      int32   // cached last set viewport y
-	viewportWidth  int32   // cached last set viewport width
-	viewportHeight int32   // cached last set viewport height
-	lineWidth      float32 // cached last set line width
-	sideView       int     // cached last set triangle side view mode
-	frontFace      uint32  // cached last set glFrontFace value
-	depthFunc      uint32  // cached last set depth function
-	depthMask      int     // cached last set depth mask
+	activeTexture  uint32     // cached last set active texture unit
+	viewportX      int32      // cached last set viewport x
+	viewportY      int32      // cached last set viewport y
+	viewportWidth  int32      // cached last set viewport width
+	viewportHeight int32      // cached last set viewport height
+	clearColor     [4]float32 // cached last set clear color
+	lineWidth      float32    // cached last set line width
+	sideView       int        // cached last set triangle side view mode
+	frontFace      uint32     // cached last set glFrontFace value
+	depthFunc      uint32     // cached last set depth function
+	depthMask      int        // cached last set depth mask
 	//stencilFunc
 	stencilMask         uint32      // cached last set stencil mask
 	capabilities        map[int]int // cached capabilities (Enable/Disable)
@@ -259,6 +260,7 @@ func (gs *GLS) BufferData(target uint32, size int, data interface{}, usage uint3
 func (gs *GLS) ClearColor(r, g, b, a float32) {
 
 	C.glClearColor(C.GLfloat(r), C.GLfloat(g), C.GLfloat(b), C.GLfloat(a))
+	gs.clearColor = [4]float32{r, g, b, a}
 }
 
 // ClearDepth specifies the depth value used by Clear to clear the depth buffer.
@@ -280,6 +282,12 @@ func (gs *GLS) Clear(mask uint) {
 	C.glClear(C.GLbitfield(mask))
 }
 
+// ColorMask enables or disables writing of the frame buffer color components.
+func (gs *GLS) ColorMask(red, green, blue, alpha bool) {
+
+	C.glColorMask(bool2c(red), bool2c(green), bool2c(blue), bool2c(alpha))
+}
+
 // CompileShader compiles the source code strings that
 // have been stored in the specified shader object.
 func (gs *GLS) CompileShader(shader uint32) {
@@ -552,6 +560,12 @@ func (gs *GLS) GetViewport() (x, y, width, height int32) {
 	return gs.viewportX, gs.viewportY, gs.viewportWidth, gs.viewportHeight
 }
 
+// GetClearColor returns the current clear color.
+func (gs *GLS) GetClearColor() (r, g, b, a float32) {
+
+	return gs.clearColor[0], gs.clearColor[1], gs.clearColor[2], gs.clearColor[3]
+}
+
 // LineWidth specifies the rasterized width of both aliased and antialiased lines.
 func (gs *GLS) LineWidth(width float32) {
 
@@ -292,6 +292,19 @@ func (grmat *GraphicMaterial) IGraphic() IGraphic {
 	return grmat.igraphic
 }
 
+// Start returns the index of the first element of the geometry drawn with the material.
+func (grmat *GraphicMaterial) Start() int {
+
+	return grmat.start
+}
+
+// Count returns the number of elements of the geometry drawn with the material,
+// which is 0 if the material applies to all the elements.
+func (grmat *GraphicMaterial) Count() int {
+
+	return grmat.count
+}
+
 // Render is called by the renderer to render this graphic material.
 func (grmat *GraphicMaterial) Render(gs *gls.GLS, rinfo *core.RenderInfo) {
 
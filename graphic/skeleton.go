@@ -46,6 +46,12 @@ func (sk *Skeleton) Bones() []*core.Node {
 	return sk.bones
 }
 
+// InverseBindMatrices returns the inverse bind matrices of the bones in the skeleton.
+func (sk *Skeleton) InverseBindMatrices() []math32.Matrix4 {
+
+	return sk.inverseBindMatrices
+}
+
 // BoneMatrices calculates and returns the bone world matrices to be sent to the shader.
 func (sk *Skeleton) BoneMatrices(invMat *math32.Matrix4) []math32.Matrix4 {
 
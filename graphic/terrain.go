@@ -0,0 +1,269 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// TerrainOptions contains the options of a terrain.
+type TerrainOptions struct {
+	ChunkCells   int     // Number of cells along each side of a chunk at the highest detail (default is 32)
+	CellSize     float32 // Size of the cells at the highest detail (default is the spacing of a HeightMap source or 1)
+	LODLevels    int     // Number of detail levels, each with half the cells along each side of the previous one (default is 4)
+	LODDistance  float32 // Distance up to which the chunks have the highest detail, doubled for each next level (default is the chunk size)
+	ViewDistance float32 // Distance beyond which the chunks are not loaded (default is 8 times the chunk size)
+	Skirt        float32 // Depth of the skirts which hide the cracks between chunks (default is the size of the cells at the lowest detail)
+	Width        float32 // Size of the terrain along the X axis from the origin (default is the size of a HeightMap source or unlimited)
+	Depth        float32 // Size of the terrain along the Z axis from the origin (default is the size of a HeightMap source or unlimited)
+	MaxBuilds    int     // Maximum number of chunks built by each Update, nearest first (default is 4)
+}
+
+// Terrain is a node which streams the chunks of a terrain around a viewer from a height source,
+// such as a heightmap or a noise function. Each chunk is a mesh whose level of detail depends
+// on its distance to the viewer, with skirts to hide the cracks between adjacent chunks with
+// different detail. All chunks use the same material, usually a material.Terrain.
+// The terrain is in the XZ plane of the node starting at its origin.
+type Terrain struct {
+	core.Node                              // Embedded node
+	src       geometry.HeightSource        // Source of the heights
+	mat       material.IMaterial           // Material of the chunks
+	opts      TerrainOptions               // Options with the defaults applied
+	chunks    map[terrainKey]*terrainChunk // Loaded chunks
+}
+
+// terrainKey is the position of a chunk in the grid of chunks.
+type terrainKey struct {
+	x, z int
+}
+
+// terrainChunk is a loaded chunk of the terrain.
+type terrainChunk struct {
+	mesh *Mesh // Mesh of the chunk
+	lod  int   // Level of detail of the mesh
+}
+
+// NewTerrain creates and returns a pointer to a new terrain with the heights of the specified source,
+// the specified material and options, which may be nil for the defaults. No chunks are loaded until
+// Update is called.
+func NewTerrain(src geometry.HeightSource, imat material.IMaterial, opts *TerrainOptions) *Terrain {
+
+	t := new(Terrain)
+	t.Node.Init(t)
+	t.src = src
+	t.mat = imat
+	t.chunks = make(map[terrainKey]*terrainChunk)
+	if opts != nil {
+		t.opts = *opts
+	}
+	o := &t.opts
+	if o.ChunkCells <= 0 {
+		o.ChunkCells = 32
+	}
+	if o.LODLevels <= 0 {
+		o.LODLevels = 4
+	}
+	if hm, ok := src.(*geometry.HeightMap); ok {
+		if o.CellSize <= 0 {
+			o.CellSize = hm.Spacing()
+		}
+		width, depth := hm.Size()
+		if o.Width <= 0 {
+			o.Width = width
+		}
+		if o.Depth <= 0 {
+			o.Depth = depth
+		}
+	}
+	if o.CellSize <= 0 {
+		o.CellSize = 1
+	}
+	size := float32(o.ChunkCells) * o.CellSize
+	if o.LODDistance <= 0 {
+		o.LODDistance = size
+	}
+	if o.ViewDistance <= 0 {
+		o.ViewDistance = 8 * size
+	}
+	if o.Skirt <= 0 {
+		o.Skirt = o.CellSize * float32(int(1)<<uint(o.LODLevels-1))
+	}
+	if o.MaxBuilds <= 0 {
+		o.MaxBuilds = 4
+	}
+	return t
+}
+
+// Source returns the source of the heights of the terrain.
+func (t *Terrain) Source() geometry.HeightSource {
+
+	return t.src
+}
+
+// Material returns the material of the terrain chunks.
+func (t *Terrain) Material() material.IMaterial {
+
+	return t.mat
+}
+
+// Options returns the options of the terrain with the defaults applied.
+func (t *Terrain) Options() TerrainOptions {
+
+	return t.opts
+}
+
+// ChunkCount returns the number of loaded chunks.
+func (t *Terrain) ChunkCount() int {
+
+	return len(t.chunks)
+}
+
+// Height returns the height of the terrain at the specified X and Z coordinates of the node,
+// on the triangles of the chunks at the highest detail. It can be used to place objects on
+// the terrain and is the surface of the heightfield returned by HeightMap.
+func (t *Terrain) Height(x, z float32) float32 {
+
+	return geometry.GridHeight(t.src, t.opts.CellSize, x, z)
+}
+
+// Normal returns the normal of the terrain at the specified X and Z coordinates of the node,
+// of the triangle of the chunks at the highest detail which contains them.
+func (t *Terrain) Normal(x, z float32) math32.Vector3 {
+
+	return geometry.GridNormal(t.src, t.opts.CellSize, x, z)
+}
+
+// HeightMap returns the heightmap with the specified number of samples along the X and Z axes
+// starting at the specified coordinates of the node, sampled at the highest detail.
+// It can be used to build the heightfield of a region of the terrain for physics.
+func (t *Terrain) HeightMap(x0, z0 float32, cols, rows int) *geometry.HeightMap {
+
+	cs := t.opts.CellSize
+	x0 = math32.Floor(x0/cs) * cs
+	z0 = math32.Floor(z0/cs) * cs
+	return geometry.NewHeightMapFromSource(t.src, cols, rows, cs, x0, z0)
+}
+
+// Update loads the chunks around the specified viewer position in world coordinates, usually
+// the position of the camera, and unloads the chunks beyond the view distance. The chunks whose
+// level of detail changed are rebuilt. At most MaxBuilds chunks are built by each call, nearest first,
+// so it should be called for every frame.
+func (t *Terrain) Update(viewer *math32.Vector3) {
+
+	// Viewer position in the coordinates of the node
+	t.UpdateMatrixWorld()
+	mw := t.MatrixWorld()
+	var inv math32.Matrix4
+	if err := inv.GetInverse(&mw); err != nil {
+		return
+	}
+	local := *viewer
+	local.ApplyMatrix4(&inv)
+
+	// Chunks within the view distance and their levels of detail
+	o := &t.opts
+	size := float32(o.ChunkCells) * o.CellSize
+	xmin, xmax := t.chunkRange(local.X, o.Width, size)
+	zmin, zmax := t.chunkRange(local.Z, o.Depth, size)
+	type build struct {
+		key  terrainKey
+		lod  int
+		dist float32
+	}
+	var builds []build
+	wanted := make(map[terrainKey]bool)
+	for cz := zmin; cz <= zmax; cz++ {
+		for cx := xmin; cx <= xmax; cx++ {
+			// Horizontal distance from the viewer to the chunk
+			x0, z0 := float32(cx)*size, float32(cz)*size
+			dx := math32.Max(math32.Max(x0-local.X, local.X-x0-size), 0)
+			dz := math32.Max(math32.Max(z0-local.Z, local.Z-z0-size), 0)
+			dist := math32.Sqrt(dx*dx + dz*dz)
+			if dist > o.ViewDistance {
+				continue
+			}
+			key := terrainKey{cx, cz}
+			wanted[key] = true
+			lod := t.lod(dist)
+			if chunk, ok := t.chunks[key]; !ok || chunk.lod != lod {
+				builds = append(builds, build{key, lod, dist})
+			}
+		}
+	}
+
+	// Unload the chunks which are not wanted
+	for key, chunk := range t.chunks {
+		if !wanted[key] {
+			t.Remove(chunk.mesh)
+			chunk.mesh.Dispose()
+			delete(t.chunks, key)
+		}
+	}
+
+	// Build the nearest chunks, replacing the ones with a different level of detail
+	sort.Slice(builds, func(i, j int) bool { return builds[i].dist < builds[j].dist })
+	if len(builds) > o.MaxBuilds {
+		builds = builds[:o.MaxBuilds]
+	}
+	for _, b := range builds {
+		if chunk, ok := t.chunks[b.key]; ok {
+			t.Remove(chunk.mesh)
+			chunk.mesh.Dispose()
+		}
+		cells := o.ChunkCells >> uint(b.lod)
+		if cells < 1 {
+			cells = 1
+		}
+		cellSize := size / float32(cells)
+		geom := geometry.NewTerrain(t.src, float32(b.key.x)*size, float32(b.key.z)*size, cells, cellSize, o.Skirt)
+		t.mat.GetMaterial().Incref()
+		mesh := NewMesh(geom, t.mat)
+		t.Add(mesh)
+		t.chunks[b.key] = &terrainChunk{mesh, b.lod}
+	}
+}
+
+// Dispose releases the resources of the loaded chunks and the reference to the material.
+func (t *Terrain) Dispose() {
+
+	for key, chunk := range t.chunks {
+		t.Remove(chunk.mesh)
+		chunk.mesh.Dispose()
+		delete(t.chunks, key)
+	}
+	t.mat.Dispose()
+}
+
+// chunkRange returns the range of chunk indices within the view distance of the specified
+// viewer coordinate, limited to the specified terrain size if it is positive.
+func (t *Terrain) chunkRange(viewer, limit, size float32) (int, int) {
+
+	min := int(math32.Floor((viewer - t.opts.ViewDistance) / size))
+	max := int(math32.Floor((viewer + t.opts.ViewDistance) / size))
+	if limit > 0 {
+		if min < 0 {
+			min = 0
+		}
+		if last := int(math32.Ceil(limit/size)) - 1; max > last {
+			max = last
+		}
+	}
+	return min, max
+}
+
+// lod returns the level of detail of a chunk at the specified distance from the viewer.
+func (t *Terrain) lod(dist float32) int {
+
+	lod := 0
+	for limit := t.opts.LODDistance; dist > limit && lod < t.opts.LODLevels-1; limit *= 2 {
+		lod++
+	}
+	return lod
+}
@@ -0,0 +1,169 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+)
+
+// Text is a text in the 3D scene drawn with the chars of a text.Atlas.
+// The text is drawn in the XY plane of the node around its anchor point
+// and by default it is billboarded so it always faces the camera.
+// Changing the text only updates the vertex buffer of its mesh.
+type Text struct {
+	Graphic                     // Embedded graphic
+	mat         *material.Text  // Text material
+	msg         string          // Text being displayed
+	size        float32         // Font size in world units
+	lineSpacing float32         // Spacing between lines (in terms of font height)
+	anchorX     float32         // Horizontal anchor point (0 = left, 1 = right)
+	anchorY     float32         // Vertical anchor point (0 = top, 1 = bottom)
+	billboard   bool            // Whether the text always faces the camera
+	width       float32         // Width of the text in world units
+	height      float32         // Height of the text in world units
+	glyphs      []text.Glyph    // Positioned chars
+	positions   math32.ArrayF32 // Vertex positions and texture coordinates
+	vbo         *gls.VBO        // Vertex buffer
+	uniMVPM     gls.Uniform     // Model view projection matrix uniform location cache
+}
+
+// NewText creates and returns a pointer to a new text graphic with the specified
+// atlas, text and font size in world units, anchored at its center.
+func NewText(atlas *text.Atlas, msg string, size float32) *Text {
+
+	t := new(Text)
+	t.size = size
+	t.lineSpacing = 1
+	t.anchorX = 0.5
+	t.anchorY = 0.5
+	t.billboard = true
+
+	// Creates geometry with the vertex buffer which is updated when the text changes
+	geom := geometry.NewGeometry()
+	t.positions = math32.NewArrayF32(0, 0)
+	t.vbo = gls.NewVBO(t.positions).
+		AddAttrib(gls.VertexPosition).
+		AddAttrib(gls.VertexTexcoord)
+	geom.AddVBO(t.vbo)
+
+	t.Graphic.Init(t, geom, gls.TRIANGLES)
+	// The geometry bounds change with the text and billboarding
+	t.SetCullable(false)
+	t.mat = material.NewText(atlas)
+	t.AddMaterial(t, t.mat, 0, 0)
+	t.uniMVPM.Init("MVP")
+
+	t.SetText(msg)
+	return t
+}
+
+// SetText sets the text to display.
+// The text can contain line break escape sequences (\n).
+func (t *Text) SetText(msg string) {
+
+	t.msg = msg
+	t.update()
+}
+
+// Text returns the text being displayed
+func (t *Text) Text() string {
+
+	return t.msg
+}
+
+// SetSize sets the font size in world units
+func (t *Text) SetSize(size float32) {
+
+	t.size = size
+	t.update()
+}
+
+// Size returns the font size in world units
+func (t *Text) Size() float32 {
+
+	return t.size
+}
+
+// SetLineSpacing sets the spacing between lines (in terms of font height)
+func (t *Text) SetLineSpacing(spacing float32) {
+
+	t.lineSpacing = spacing
+	t.update()
+}
+
+// SetAnchor sets the point of the text placed at the node position,
+// as fractions of the text width and height from its top left corner.
+// The default anchor (0.5, 0.5) is the center of the text.
+func (t *Text) SetAnchor(x, y float32) {
+
+	t.anchorX = x
+	t.anchorY = y
+	t.update()
+}
+
+// SetBillboard sets whether the text always faces the camera
+func (t *Text) SetBillboard(state bool) {
+
+	t.billboard = state
+}
+
+// Billboard returns whether the text always faces the camera
+func (t *Text) Billboard() bool {
+
+	return t.billboard
+}
+
+// Dims returns the width and height of the text in world units
+func (t *Text) Dims() (float32, float32) {
+
+	return t.width, t.height
+}
+
+// Material returns the text material, which sets the text color, outline and shadow
+func (t *Text) Material() *material.Text {
+
+	return t.mat
+}
+
+// update rebuilds the vertex buffer of the text reusing its arrays
+func (t *Text) update() {
+
+	t.glyphs, t.width, t.height = t.mat.Atlas().Layout(t.msg, t.size, t.lineSpacing, t.glyphs[:0])
+	t.positions = t.positions[:0]
+	text.AppendGlyphVertices(&t.positions, t.glyphs, -t.anchorX*t.width, -t.anchorY*t.height, true)
+	t.vbo.SetBuffer(t.positions)
+}
+
+// RenderSetup is called by the renderer before drawing this graphic
+// and transfers the model view projection matrix to the shader.
+func (t *Text) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	mw := t.MatrixWorld()
+	var mvm math32.Matrix4
+	mvm.MultiplyMatrices(&rinfo.ViewMatrix, &mw)
+
+	// Removes any rotation in X and Y axes to face the camera
+	if t.billboard {
+		var position math32.Vector3
+		var quaternion math32.Quaternion
+		var scale math32.Vector3
+		mvm.Decompose(&position, &quaternion, &scale)
+		rotation := t.Rotation()
+		rotation.X = 0
+		rotation.Y = 0
+		quaternion.SetFromEuler(&rotation)
+		mvm.Compose(&position, &quaternion, &scale)
+	}
+
+	var mvpm math32.Matrix4
+	mvpm.MultiplyMatrices(&rinfo.ProjMatrix, &mvm)
+	location := t.uniMVPM.Location(gs)
+	gs.UniformMatrix4fv(location, 1, false, &mvpm[0])
+}
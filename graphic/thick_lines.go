@@ -0,0 +1,216 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package graphic
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// ThickLines is a Graphic which is rendered as independent or connected lines with a real width,
+// which is not limited by the OpenGL line width. Each segment is drawn as a quad expanded in the
+// vertex shader and the corners between connected segments are filled with joins.
+// It is used with a material.ThickLine, which sets the width, dashes, caps and joins.
+type ThickLines struct {
+	Graphic                   // Embedded graphic
+	strip    bool             // Connected lines flag
+	points   []math32.Vector3 // Points of the lines
+	colors   []math32.Color   // Colors of the points (may be nil)
+	vbo      *gls.VBO         // Vertex buffer
+	uniMVm   gls.Uniform      // Model view matrix uniform location cache
+	uniPm    gls.Uniform      // Projection matrix uniform location cache
+	uniViewp gls.Uniform      // Viewport size uniform location cache
+}
+
+// Number of floats of each vertex: position, other point, next point, color and info
+const thickLineVertexSize = 16
+
+// NewThickLines creates and returns a pointer to a new ThickLines graphic with independent lines
+// between each pair of the specified points, with the specified point colors, which may be nil,
+// and material.
+func NewThickLines(points []math32.Vector3, colors []math32.Color, imat material.IMaterial) *ThickLines {
+
+	l := new(ThickLines)
+	l.Init(points, colors, false, imat)
+	return l
+}
+
+// NewThickLineStrip creates and returns a pointer to a new ThickLines graphic with connected lines
+// through the specified points, with the specified point colors, which may be nil, and material.
+func NewThickLineStrip(points []math32.Vector3, colors []math32.Color, imat material.IMaterial) *ThickLines {
+
+	l := new(ThickLines)
+	l.Init(points, colors, true, imat)
+	return l
+}
+
+// NewThickLinesFromGeometry creates and returns a pointer to a new ThickLines graphic with the
+// vertex positions and colors of the specified geometry, such as the geometry of a Lines graphic
+// if strip is false or of a LineStrip graphic if strip is true, and the specified material.
+func NewThickLinesFromGeometry(geom *geometry.Geometry, strip bool, imat material.IMaterial) *ThickLines {
+
+	var points []math32.Vector3
+	geom.ReadVertices(func(vertex math32.Vector3) bool {
+		points = append(points, vertex)
+		return false
+	})
+	var colors []math32.Color
+	if vbo := geom.VBO(gls.VertexColor); vbo != nil {
+		vbo.ReadVectors3(gls.VertexColor, func(vec math32.Vector3) bool {
+			colors = append(colors, math32.Color{vec.X, vec.Y, vec.Z})
+			return false
+		})
+	}
+	if geom.Indexed() {
+		indexed := make([]math32.Vector3, 0, len(geom.Indices()))
+		var indexedColors []math32.Color
+		for _, idx := range geom.Indices() {
+			indexed = append(indexed, points[idx])
+			if colors != nil {
+				indexedColors = append(indexedColors, colors[idx])
+			}
+		}
+		points, colors = indexed, indexedColors
+	}
+	l := new(ThickLines)
+	l.Init(points, colors, strip, imat)
+	return l
+}
+
+// Init initializes the ThickLines graphic with the specified points, point colors,
+// connected lines flag and material.
+func (l *ThickLines) Init(points []math32.Vector3, colors []math32.Color, strip bool, imat material.IMaterial) {
+
+	l.strip = strip
+	l.vbo = gls.NewVBO(math32.NewArrayF32(0, 0)).
+		AddAttrib(gls.VertexPosition).
+		AddCustomAttrib("LinePrev", 3).
+		AddCustomAttrib("LineNext", 3).
+		AddAttrib(gls.VertexColor).
+		AddCustomAttrib("LineInfo", 4)
+	geom := geometry.NewGeometry()
+	geom.AddVBO(l.vbo)
+	l.Graphic.Init(l, geom, gls.TRIANGLES)
+	l.AddMaterial(l, imat, 0, 0)
+	l.uniMVm.Init("ModelViewMatrix")
+	l.uniPm.Init("ProjMatrix")
+	l.uniViewp.Init("LineViewport")
+	l.SetPoints(points, colors)
+}
+
+// SetPoints sets the points of the lines and their colors, which may be nil.
+// If there are colors there must be one for each point.
+func (l *ThickLines) SetPoints(points []math32.Vector3, colors []math32.Color) {
+
+	l.points = points
+	l.colors = colors
+	if colors != nil && len(colors) < len(points) {
+		l.colors = nil
+	}
+	l.build()
+}
+
+// Points returns the points of the lines.
+func (l *ThickLines) Points() []math32.Vector3 {
+
+	return l.points
+}
+
+// Colors returns the colors of the points, which may be nil.
+func (l *ThickLines) Colors() []math32.Color {
+
+	return l.colors
+}
+
+// Strip returns whether the lines are connected.
+func (l *ThickLines) Strip() bool {
+
+	return l.strip
+}
+
+// RenderSetup is called by the engine before drawing this geometry.
+func (l *ThickLines) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	mvm := l.ModelViewMatrix()
+	gs.UniformMatrix4fv(l.uniMVm.Location(gs), 1, false, &mvm[0])
+	gs.UniformMatrix4fv(l.uniPm.Location(gs), 1, false, &rinfo.ProjMatrix[0])
+	_, _, width, height := gs.GetViewport()
+	gs.Uniform2f(l.uniViewp.Location(gs), float32(width), float32(height))
+}
+
+// build builds the vertices of the segment quads and joins.
+func (l *ThickLines) build() {
+
+	data := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, 0)
+	white := math32.Color{1, 1, 1}
+	color := func(i int) *math32.Color {
+		if l.colors == nil {
+			return &white
+		}
+		return &l.colors[i]
+	}
+	vertex := func(p, prev, next *math32.Vector3, c *math32.Color, info0, info1, dist, interior float32) {
+		data.Append(p.X, p.Y, p.Z, prev.X, prev.Y, prev.Z, next.X, next.Y, next.Z, c.R, c.G, c.B, info0, info1, dist, interior)
+	}
+
+	// Each segment is a quad whose vertices have the side of the segment and which of its ends
+	// they are at. The side is negated at the end as the direction to the other end is reversed.
+	segment := func(a, b int, dist float32, interiorA, interiorB bool) {
+		var flagsA, flagsB float32
+		if interiorA {
+			flagsA, flagsB = flagsA+1, flagsB+2
+		}
+		if interiorB {
+			flagsA, flagsB = flagsA+2, flagsB+1
+		}
+		pa, pb := &l.points[a], &l.points[b]
+		end := dist + pa.DistanceTo(pb)
+		base := uint32(len(data) / thickLineVertexSize)
+		vertex(pa, pb, pb, color(a), 1, 0, dist, flagsA)
+		vertex(pa, pb, pb, color(a), -1, 0, dist, flagsA)
+		vertex(pb, pa, pa, color(b), -1, 1, end, flagsB)
+		vertex(pb, pa, pa, color(b), 1, 1, end, flagsB)
+		indices.Append(base, base+1, base+2, base+1, base+3, base+2)
+	}
+
+	// Each join has the corner point, the ends of the outer edges of its two segments and the
+	// miter point, which the vertex shader moves according to the join of the material
+	join := func(prev, i, next int, dist float32) {
+		base := uint32(len(data) / thickLineVertexSize)
+		for corner := 0; corner < 4; corner++ {
+			vertex(&l.points[i], &l.points[prev], &l.points[next], color(i), float32(corner), 2, dist, 0)
+		}
+		indices.Append(base, base+1, base+3, base, base+3, base+2)
+	}
+
+	if l.strip {
+		// Skip repeated points, which have no direction
+		var pts []int
+		for i := range l.points {
+			if len(pts) == 0 || !l.points[i].Equals(&l.points[pts[len(pts)-1]]) {
+				pts = append(pts, i)
+			}
+		}
+		var dist float32
+		for k := 0; k+1 < len(pts); k++ {
+			segment(pts[k], pts[k+1], dist, k > 0, k+2 < len(pts))
+			if k > 0 {
+				join(pts[k-1], pts[k], pts[k+1], dist)
+			}
+			dist += l.points[pts[k]].DistanceTo(&l.points[pts[k+1]])
+		}
+	} else {
+		for i := 0; i+1 < len(l.points); i += 2 {
+			segment(i, i+1, 0, false, false)
+		}
+	}
+
+	l.vbo.SetBuffer(data)
+	l.GetGeometry().SetIndices(indices)
+}
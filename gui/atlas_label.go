@@ -0,0 +1,277 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+)
+
+// AtlasLabel is a panel which draws its text with a mesh of the chars of a text.Atlas
+// instead of a texture rendered for the text as Label does.
+// Changing the text only updates the vertex buffer of the mesh, and text drawn with
+// an atlas of signed distance fields stays sharp at any size and can have an outline and a drop shadow.
+// The content size of the label panel is the size of the text.
+type AtlasLabel struct {
+	Panel                      // Embedded Panel
+	mesh         *atlasMesh    // Panel with the text mesh
+	msg          string        // Text being displayed
+	size         float32       // Font size in pixels
+	lineSpacing  float32       // Spacing between lines (in terms of font height)
+	outlineWidth float32       // Outline width in pixels
+	outlineColor math32.Color4 // Outline color
+	shadowX      float32       // Shadow horizontal offset in pixels
+	shadowY      float32       // Shadow vertical offset in pixels
+	shadowSoft   float32       // Shadow softness in pixels
+	shadowColor  math32.Color4 // Shadow color
+}
+
+// atlasMesh is the panel with TRIANGLES geometry which draws the text of an AtlasLabel
+type atlasMesh struct {
+	Panel                     // Embedded panel
+	mat       *material.Text  // Text material
+	glyphs    []text.Glyph    // Positioned chars
+	positions math32.ArrayF32 // Vertex positions and texture coordinates
+	vbo       *gls.VBO        // Vertex buffer
+	uniMVP    gls.Uniform     // Model view projection matrix uniform location cache
+}
+
+// defaultAtlas is the atlas created from the default style font, shared by all atlas labels
+var defaultAtlas *text.Atlas
+
+// DefaultAtlas returns the atlas of signed distance fields of the Latin-1 chars of the
+// default style font, which is created the first time it is requested.
+func DefaultAtlas() *text.Atlas {
+
+	if defaultAtlas == nil {
+		defaultAtlas = text.NewSDFAtlas(StyleDefault().Font, 32, 255, 48, 6)
+	}
+	return defaultAtlas
+}
+
+// NewAtlasLabel creates and returns an atlas label with the specified
+// text drawn using the default atlas.
+func NewAtlasLabel(msg string) *AtlasLabel {
+
+	return NewAtlasLabelWithAtlas(msg, DefaultAtlas())
+}
+
+// NewAtlasLabelWithAtlas creates and returns an atlas label with the
+// specified text drawn using the specified atlas.
+func NewAtlasLabelWithAtlas(msg string, atlas *text.Atlas) *AtlasLabel {
+
+	l := new(AtlasLabel)
+	l.Panel.Initialize(l, 0, 0)
+	l.Panel.SetAccessibleRole(RoleLabel)
+	l.Panel.mat.SetTransparent(true)
+	l.Panel.SetPaddings(2, 0, 2, 0)
+	l.Panel.SetColor4(&StyleDefault().Label.BgColor)
+	l.size = float32(StyleDefault().Label.PointSize)
+	l.lineSpacing = float32(StyleDefault().Label.LineSpacing)
+	l.mesh = newAtlasMesh(atlas)
+	l.mesh.mat.SetColor(&StyleDefault().Label.FgColor)
+	l.Add(l.mesh)
+	l.SetText(msg)
+	return l
+}
+
+// newAtlasMesh creates and returns a panel which draws text with the specified atlas
+func newAtlasMesh(atlas *text.Atlas) *atlasMesh {
+
+	m := new(atlasMesh)
+	geom := geometry.NewGeometry()
+	m.positions = math32.NewArrayF32(0, 0)
+	m.vbo = gls.NewVBO(m.positions).
+		AddAttrib(gls.VertexPosition).
+		AddAttrib(gls.VertexTexcoord)
+	geom.AddVBO(m.vbo)
+
+	gr := graphic.NewGraphic(m, geom, gls.TRIANGLES)
+	m.mat = material.NewText(atlas)
+	gr.AddMaterial(m, m.mat, 0, 0)
+	m.Panel.InitializeGraphic(0, 0, gr)
+	m.uniMVP.Init("MVP")
+	return m
+}
+
+// SetText sets the label text, which can contain line break escape sequences (\n).
+func (l *AtlasLabel) SetText(msg string) {
+
+	l.msg = msg
+	l.update()
+}
+
+// Text returns the label text
+func (l *AtlasLabel) Text() string {
+
+	return l.msg
+}
+
+// Atlas returns the atlas used to draw the text
+func (l *AtlasLabel) Atlas() *text.Atlas {
+
+	return l.mesh.mat.Atlas()
+}
+
+// SetColor sets the text color
+func (l *AtlasLabel) SetColor(color *math32.Color) *AtlasLabel {
+
+	l.mesh.mat.SetColor(&math32.Color4{color.R, color.G, color.B, 1})
+	return l
+}
+
+// SetColor4 sets the text color
+func (l *AtlasLabel) SetColor4(color4 *math32.Color4) *AtlasLabel {
+
+	l.mesh.mat.SetColor(color4)
+	return l
+}
+
+// Color returns the text color
+func (l *AtlasLabel) Color() math32.Color4 {
+
+	return l.mesh.mat.Color()
+}
+
+// SetBgColor sets the background color
+func (l *AtlasLabel) SetBgColor(color *math32.Color) *AtlasLabel {
+
+	l.Panel.SetColor(color)
+	return l
+}
+
+// SetBgColor4 sets the background color
+func (l *AtlasLabel) SetBgColor4(color *math32.Color4) *AtlasLabel {
+
+	l.Panel.SetColor4(color)
+	return l
+}
+
+// BgColor returns the background color
+func (l *AtlasLabel) BgColor() math32.Color4 {
+
+	return l.Panel.Color4()
+}
+
+// SetFontSize sets the font size in pixels
+func (l *AtlasLabel) SetFontSize(size float32) *AtlasLabel {
+
+	l.size = size
+	l.updateEffects()
+	l.update()
+	return l
+}
+
+// FontSize returns the font size in pixels
+func (l *AtlasLabel) FontSize() float32 {
+
+	return l.size
+}
+
+// SetLineSpacing sets the spacing between lines (in terms of font height)
+func (l *AtlasLabel) SetLineSpacing(spacing float32) *AtlasLabel {
+
+	l.lineSpacing = spacing
+	l.update()
+	return l
+}
+
+// LineSpacing returns the spacing between lines
+func (l *AtlasLabel) LineSpacing() float32 {
+
+	return l.lineSpacing
+}
+
+// SetOutline sets the width in pixels and the color of the text outline.
+// Outlines are only drawn with atlases of signed distance fields and their
+// width is limited by the atlas spread. A width of 0 removes the outline.
+func (l *AtlasLabel) SetOutline(width float32, color *math32.Color4) *AtlasLabel {
+
+	l.outlineWidth = width
+	l.outlineColor = *color
+	l.updateEffects()
+	return l
+}
+
+// SetShadow sets the offset and softness in pixels and the color of the text drop shadow.
+// Shadows are only drawn with atlases of signed distance fields and their
+// offset and softness are limited by the atlas spread. A transparent color removes the shadow.
+func (l *AtlasLabel) SetShadow(dx, dy, softness float32, color *math32.Color4) *AtlasLabel {
+
+	l.shadowX = dx
+	l.shadowY = dy
+	l.shadowSoft = softness
+	l.shadowColor = *color
+	l.updateEffects()
+	return l
+}
+
+// updateEffects converts the outline and shadow sizes from pixels to atlas pixels
+// and sets them in the text material
+func (l *AtlasLabel) updateEffects() {
+
+	if l.size <= 0 {
+		return
+	}
+	scale := l.Atlas().Size / l.size
+	l.mesh.mat.SetOutline(l.outlineWidth*scale, &l.outlineColor)
+	l.mesh.mat.SetShadow(l.shadowX*scale, l.shadowY*scale, l.shadowSoft*scale, &l.shadowColor)
+}
+
+// update rebuilds the text mesh and resizes the label to fit the text
+func (l *AtlasLabel) update() {
+
+	m := l.mesh
+	var width, height float32
+	m.glyphs, width, height = l.Atlas().Layout(l.msg, l.size, l.lineSpacing, m.glyphs[:0])
+	m.positions = m.positions[:0]
+	text.AppendGlyphVertices(&m.positions, m.glyphs, 0, 0, false)
+	m.vbo.SetBuffer(m.positions)
+	m.SetSize(width, height)
+	l.Panel.SetContentSize(width, height)
+}
+
+// RenderSetup is called by the renderer before drawing this graphic
+// It overrides the original panel RenderSetup
+// Calculates the matrix which converts the positions of the chars in pixels
+// to OpenGL clip coordinates and the clipping bounds and transfer them to OpenGL.
+func (m *atlasMesh) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
+
+	// Get scale of window (for HiDPI support) and the current viewport
+	sX, sY := Manager().win.GetScale()
+	vx, vy, vwidth, vheight := gs.GetViewport()
+	fX := 2 * float32(sX) / float32(vwidth)
+	fY := 2 * float32(sY) / float32(vheight)
+
+	// The Y coordinates of the chars increase downwards
+	var mvp math32.Matrix4
+	mvp.Set(
+		fX, 0, 0, fX*m.pospix.X-1,
+		0, -fY, 0, 1-fY*m.pospix.Y,
+		0, 0, 1, m.Position().Z,
+		0, 0, 0, 1,
+	)
+	location := m.uniMVP.Location(gs)
+	gs.UniformMatrix4fv(location, 1, false, &mvp[0])
+
+	// Sets the panel bounds in OpenGL window coordinates to clip the chars
+	if m.xmax <= m.xmin || m.ymax <= m.ymin {
+		// Panel fully clipped: sets non empty bounds outside of the window
+		m.mat.SetBounds(-2, -2, -1, -1)
+	} else {
+		top := float32(vy + vheight)
+		m.mat.SetBounds(
+			float32(vx)+m.xmin*float32(sX), top-m.ymax*float32(sY),
+			float32(vx)+m.xmax*float32(sX), top-m.ymin*float32(sY),
+		)
+	}
+	// The material was set up before this graphic, so its uniforms are transferred again with the new bounds
+	m.mat.RenderSetup(gs)
+}
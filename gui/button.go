@@ -53,6 +53,9 @@ func NewButton(text string) *Button {
 
 	// Initializes the button panel
 	b.Panel.Initialize(b, 0, 0)
+	b.SetFocusable(true)
+	b.SetAccessibleRole(RoleButton)
+	b.SetAccessibleName(text)
 
 	// Subscribe to panel events
 	b.Subscribe(OnKeyDown, b.onKey)
@@ -162,7 +165,7 @@ func (b *Button) onMouse(evname string, ev interface{}) {
 func (b *Button) onKey(evname string, ev interface{}) {
 
 	kev := ev.(*window.KeyEvent)
-	if kev.Key != window.KeyEnter {
+	if kev.Key != window.KeyEnter && kev.Key != window.KeySpace {
 		return
 	}
 	switch evname {
@@ -24,16 +24,21 @@ func init() {
 
 //
 // Chart implements a panel which can contain a title, an x scale,
-// an y scale and several graphs
+// an y scale, optional secondary y scales, a legend and several graphs
 //
 type Chart struct {
 	Panel                   // Embedded panel
 	left       float32      // Left margin in pixels
+	right      float32      // Right margin in pixels (used by the secondary y scales)
 	bottom     float32      // Bottom margin in pixels
 	top        float32      // Top margin in pixels
 	firstX     float32      // Value for the first x label
 	stepX      float32      // Step for the next x label
 	countStepX float32      // Number of values per x step
+	minX       float32      // Minimum X value (auto range or changed view only)
+	maxX       float32      // Maximum X value (auto range or changed view only)
+	autoX      bool         // Auto range flag for X values
+	viewX      bool         // X range was changed by zooming or panning
 	minY       float32      // Minimum Y value
 	maxY       float32      // Maximum Y value
 	autoY      bool         // Auto range flag for Y values
@@ -46,13 +51,41 @@ type Chart struct {
 	scaleY     *chartScaleY // Y scale panel
 	labelsX    []*Label     // Array of scale X labels
 	labelsY    []*Label     // Array of scale Y labels
-	graphs     []*Graph     // Array of line graphs
+	axes       []*ChartAxis // Array of secondary Y scales
+	graphs     []*Graph     // Array of graphs
+	legend     *chartLegend // Optional legend panel
+	hover      bool         // Show readout of the data point under the cursor
+	readout    *Label       // Readout label of the hovered data point
+	marker     *Panel       // Marker of the hovered data point
+	zoomX      bool         // X range can be zoomed and panned with the mouse
+	zoomY      bool         // Y ranges can be zoomed and panned with the mouse
+	panning    bool         // Range is being panned by dragging
+	cursorX    float32      // Last cursor position in content coordinates
+	cursorY    float32      // Last cursor position in content coordinates
+	saved      *chartView   // Ranges before the view was zoomed or panned
 }
 
+// GraphKind specifies how a graph draws its data points.
+type GraphKind int
+
+// Kinds of graphs
+const (
+	GraphLine    = GraphKind(iota) // Data points joined by lines
+	GraphScatter                   // Marker at each data point
+	GraphBar                       // Vertical bar from the baseline to each data point
+	GraphArea                      // Filled area between the baseline and the lines joining the data points
+)
+
 const (
 	deltaLine = 0.001 // Delta in NDC for lines over the boundary
 )
 
+// Default graph marker size and bar width factor
+const (
+	defaultMarkerSize = 6   // Size of scatter markers in pixels
+	defaultBarFactor  = 0.8 // Automatic bar width relative to the smallest distance between data points
+)
+
 // NewChart creates and returns a new chart panel with
 // the specified dimensions in pixels.
 func NewChart(width, height float32) *Chart {
@@ -261,9 +294,27 @@ func (ch *Chart) SetRangeX(firstX float32, stepX float32, countStepX float32) {
 	ch.firstX = firstX
 	ch.stepX = stepX
 	ch.countStepX = countStepX
+	ch.viewX = false
 	ch.updateGraphs()
 }
 
+// SetRangeXauto sets the state of the X auto range.
+// When set the X scale shows the range of the X values of all graphs.
+func (ch *Chart) SetRangeXauto(auto bool) {
+
+	ch.autoX = auto
+	ch.updateGraphs()
+}
+
+// RangeX returns the current x range
+func (ch *Chart) RangeX() (minX, maxX float32) {
+
+	if ch.autoX || ch.viewX {
+		return ch.minX, ch.maxX
+	}
+	return ch.firstX, ch.firstX + float32(ch.linesX())*ch.stepX
+}
+
 // SetRangeY sets the minimum and maximum values of the y scale
 func (ch *Chart) SetRangeY(min float32, max float32) {
 
@@ -291,17 +342,85 @@ func (ch *Chart) RangeY() (minY, maxY float32) {
 	return ch.minY, ch.maxY
 }
 
-// AddLineGraph adds a line graph to the chart
+// AddLineGraph adds a line graph to the chart with evenly spaced x values
 func (ch *Chart) AddLineGraph(color *math32.Color, data []float32) *Graph {
 
-	graph := newGraph(ch, color, data)
+	graph := newGraph(ch, GraphLine, color)
+	graph.setData(data)
+	return ch.addGraph(graph)
+}
+
+// AddGraph adds an empty graph of the specified kind to the chart.
+// Its data can be set with SetData, SetDataXY or Push.
+func (ch *Chart) AddGraph(kind GraphKind, color *math32.Color) *Graph {
+
+	return ch.addGraph(newGraph(ch, kind, color))
+}
+
+// AddScatterGraph adds a scatter graph to the chart with the specified x and y values
+func (ch *Chart) AddScatterGraph(color *math32.Color, x, y []float32) *Graph {
+
+	graph := newGraph(ch, GraphScatter, color)
+	graph.setDataXY(x, y)
+	return ch.addGraph(graph)
+}
+
+// AddBarGraph adds a bar graph to the chart with evenly spaced x values
+func (ch *Chart) AddBarGraph(color *math32.Color, data []float32) *Graph {
+
+	graph := newGraph(ch, GraphBar, color)
+	graph.setData(data)
+	return ch.addGraph(graph)
+}
+
+// AddAreaGraph adds an area graph to the chart with evenly spaced x values
+func (ch *Chart) AddAreaGraph(color *math32.Color, data []float32) *Graph {
+
+	graph := newGraph(ch, GraphArea, color)
+	graph.setData(data)
+	return ch.addGraph(graph)
+}
+
+// AddHistogram adds a bar graph to the chart with the histogram of the
+// specified values divided in the specified number of bins
+func (ch *Chart) AddHistogram(color *math32.Color, values []float32, bins int) *Graph {
+
+	graph := newGraph(ch, GraphBar, color)
+	graph.setHistogram(values, bins)
+	return ch.addGraph(graph)
+}
+
+// AddRingGraph adds a graph of the specified kind to the chart which keeps
+// at most the specified number of data points added with Push.
+// When it is full the oldest points are overwritten, so streaming data
+// can be plotted without reallocating.
+func (ch *Chart) AddRingGraph(kind GraphKind, color *math32.Color, capacity int) *Graph {
+
+	graph := newGraph(ch, kind, color)
+	graph.ring = true
+	graph.data = make([]float32, capacity)
+	graph.dataX = make([]float32, capacity)
+	graph.positions = math32.NewArrayF32(0, capacity*graph.vertsPerPoint()*3)
+	return ch.addGraph(graph)
+}
+
+// addGraph adds the specified graph to the chart and returns it
+func (ch *Chart) addGraph(graph *Graph) *Graph {
+
 	ch.graphs = append(ch.graphs, graph)
 	ch.Add(graph)
 	ch.recalc()
 	ch.updateGraphs()
+	ch.updateLegend()
 	return graph
 }
 
+// Graphs returns the graphs of the chart
+func (ch *Chart) Graphs() []*Graph {
+
+	return ch.graphs
+}
+
 // RemoveGraph removes and disposes of the specified graph from the chart
 func (ch *Chart) RemoveGraph(g *Graph) {
 
@@ -315,7 +434,9 @@ func (ch *Chart) RemoveGraph(g *Graph) {
 			break
 		}
 	}
-	if !ch.autoY {
+	ch.hideReadout()
+	ch.updateLegend()
+	if !ch.autoRange() {
 		return
 	}
 	ch.updateGraphs()
@@ -327,32 +448,31 @@ func (ch *Chart) updateLabelsX() {
 	if ch.scaleX == nil {
 		return
 	}
-	pstep := (ch.ContentWidth() - ch.left) / float32(len(ch.labelsX))
-	value := ch.firstX
+	px, _, width, _ := ch.plotArea()
+	minX, maxX := ch.RangeX()
+	pstep := width / float32(len(ch.labelsX))
+	vstep := (maxX - minX) / float32(len(ch.labelsX))
 	for i := 0; i < len(ch.labelsX); i++ {
 		label := ch.labelsX[i]
-		label.SetText(fmt.Sprintf(ch.formatX, value))
-		px := ch.left + float32(i)*pstep
-		label.SetPosition(px, ch.ContentHeight()-ch.bottom)
-		value += ch.stepX
+		label.SetText(fmt.Sprintf(ch.formatX, minX+float32(i)*vstep))
+		label.SetPosition(px+float32(i)*pstep, ch.ContentHeight()-ch.bottom)
 	}
 }
 
 // updateLabelsY updates the Y scale labels text and positions
 func (ch *Chart) updateLabelsY() {
 
+	for _, axis := range ch.axes {
+		axis.updateLabels()
+	}
 	if ch.scaleY == nil {
 		return
 	}
 
-	th := float32(0)
-	if ch.title != nil {
-		th = ch.title.height
-	}
-
+	_, _, _, height := ch.plotArea()
 	nlines := ch.scaleY.lines
 	vstep := (ch.maxY - ch.minY) / float32(nlines-1)
-	pstep := (ch.ContentHeight() - th - ch.top - ch.bottom) / float32(nlines-1)
+	pstep := height / float32(nlines-1)
 	value := ch.minY
 	for i := 0; i < nlines; i++ {
 		label := ch.labelsY[i]
@@ -367,34 +487,140 @@ func (ch *Chart) updateLabelsY() {
 	}
 }
 
+// calcRangeX calculates the minimum and maximum x values for all visible graphs
+func (ch *Chart) calcRangeX() {
+
+	if !ch.autoX || ch.viewX {
+		return
+	}
+	minX := float32(math.MaxFloat32)
+	maxX := -float32(math.MaxFloat32)
+	for _, graph := range ch.graphs {
+		if !graph.Visible() {
+			continue
+		}
+		half := float32(0)
+		if graph.kind == GraphBar {
+			half = graph.barWidth() / 2
+		}
+		for i := 0; i < graph.count; i++ {
+			vx, _ := graph.At(i)
+			minX = math32.Min(minX, vx-half)
+			maxX = math32.Max(maxX, vx+half)
+		}
+	}
+	if minX > maxX {
+		return
+	}
+	ch.minX = minX
+	ch.maxX = maxX
+}
+
 // calcRangeY calculates the minimum and maximum y values for all graphs
+// of the primary and the secondary y scales with auto range
 func (ch *Chart) calcRangeY() {
 
-	if !ch.autoY || len(ch.graphs) == 0 {
-		return
+	if ch.autoY {
+		if minY, maxY, ok := ch.dataRangeY(nil); ok {
+			ch.minY = minY
+			ch.maxY = maxY
+		}
 	}
-	minY := float32(math.MaxFloat32)
-	maxY := -float32(math.MaxFloat32)
-	for g := 0; g < len(ch.graphs); g++ {
-		graph := ch.graphs[g]
-		for x := 0; x < len(graph.data); x++ {
-			vy := graph.data[x]
-			if vy < minY {
-				minY = vy
-			}
-			if vy > maxY {
-				maxY = vy
+	for _, axis := range ch.axes {
+		if axis.auto {
+			if minY, maxY, ok := ch.dataRangeY(axis); ok {
+				axis.min = minY
+				axis.max = maxY
 			}
 		}
 	}
-	ch.minY = minY
-	ch.maxY = maxY
+}
+
+// dataRangeY returns the minimum and maximum y values for all visible graphs
+// of the specified y scale (nil for the primary y scale)
+func (ch *Chart) dataRangeY(axis *ChartAxis) (minY, maxY float32, ok bool) {
+
+	minY = float32(math.MaxFloat32)
+	maxY = -float32(math.MaxFloat32)
+	for _, graph := range ch.graphs {
+		if graph.axis != axis || !graph.Visible() || graph.count == 0 {
+			continue
+		}
+		for i := 0; i < graph.count; i++ {
+			_, vy := graph.At(i)
+			minY = math32.Min(minY, vy)
+			maxY = math32.Max(maxY, vy)
+		}
+		// Bars and areas are drawn from the baseline
+		if graph.kind == GraphBar || graph.kind == GraphArea {
+			minY = math32.Min(minY, graph.baseline)
+			maxY = math32.Max(maxY, graph.baseline)
+		}
+		ok = true
+	}
+	return minY, maxY, ok
+}
+
+// rangeY returns the range of the specified y scale (nil for the primary y scale)
+func (ch *Chart) rangeY(axis *ChartAxis) (minY, maxY float32) {
+
+	if axis == nil {
+		return ch.minY, ch.maxY
+	}
+	return axis.min, axis.max
+}
+
+// autoRange returns whether any of the chart ranges is automatic
+func (ch *Chart) autoRange() bool {
+
+	if ch.autoX || ch.autoY {
+		return true
+	}
+	for _, axis := range ch.axes {
+		if axis.auto {
+			return true
+		}
+	}
+	return false
+}
+
+// linesX returns the number of vertical lines of the X scale
+func (ch *Chart) linesX() int {
+
+	if ch.scaleX != nil {
+		return ch.scaleX.lines
+	}
+	return 1
+}
+
+// linesY returns the number of horizontal lines of the Y scale
+func (ch *Chart) linesY() int {
+
+	if ch.scaleY != nil {
+		return ch.scaleY.lines
+	}
+	return 2
+}
+
+// plotArea returns the position and size in pixels of the area
+// where the graphs are drawn inside the chart content area
+func (ch *Chart) plotArea() (x, y, width, height float32) {
+
+	y = ch.top
+	if ch.title != nil {
+		y += ch.title.Height()
+	}
+	x = ch.left
+	width = ch.ContentWidth() - ch.left - ch.right
+	height = ch.ContentHeight() - y - ch.bottom
+	return x, y, width, height
 }
 
 // updateGraphs should be called when the range the scales change or
 // any graph data changes
 func (ch *Chart) updateGraphs() {
 
+	ch.calcRangeX()
 	ch.calcRangeY()
 	ch.updateLabelsX()
 	ch.updateLabelsY()
@@ -425,11 +651,11 @@ func (ch *Chart) recalc() {
 		ch.updateLabelsX()
 	}
 
-	// Recalc scale Y and its labels
+	// Recalc scale Y, the secondary scales and their labels
 	if ch.scaleY != nil {
 		ch.scaleY.recalc()
-		ch.updateLabelsY()
 	}
+	ch.updateLabelsY()
 
 	// Recalc graphs
 	for i := 0; i < len(ch.graphs); i++ {
@@ -437,6 +663,16 @@ func (ch *Chart) recalc() {
 		g.recalc()
 		ch.SetTopChild(g)
 	}
+
+	// Keep the legend and the readout over the graphs
+	if ch.legend != nil {
+		ch.legend.recalc()
+		ch.SetTopChild(ch.legend)
+	}
+	if ch.readout != nil {
+		ch.SetTopChild(ch.marker)
+		ch.SetTopChild(ch.readout)
+	}
 }
 
 //
@@ -491,12 +727,9 @@ func newChartScaleX(chart *Chart, lines int, color *math32.Color) *chartScaleX {
 // recalc recalculates the position and size of this scale inside its parent
 func (sx *chartScaleX) recalc() {
 
-	py := sx.chart.top
-	if sx.chart.title != nil {
-		py += sx.chart.title.Height()
-	}
-	sx.SetPosition(sx.chart.left, py)
-	sx.SetSize(sx.chart.ContentWidth()-sx.chart.left, sx.chart.ContentHeight()-py-sx.chart.bottom)
+	px, py, width, height := sx.chart.plotArea()
+	sx.SetPosition(px, py)
+	sx.SetSize(width, height)
 }
 
 // RenderSetup is called by the renderer before drawing this graphic
@@ -576,12 +809,9 @@ func newChartScaleY(chart *Chart, lines int, color *math32.Color) *chartScaleY {
 // recalc recalculates the position and size of this scale inside its parent
 func (sy *chartScaleY) recalc() {
 
-	py := sy.chart.top
-	if sy.chart.title != nil {
-		py += sy.chart.title.Height()
-	}
-	sy.SetPosition(sy.chart.left, py)
-	sy.SetSize(sy.chart.ContentWidth()-sy.chart.left, sy.chart.ContentHeight()-py-sy.chart.bottom)
+	px, py, width, height := sy.chart.plotArea()
+	sy.SetPosition(px, py)
+	sy.SetSize(width, height)
 }
 
 // RenderSetup is called by the renderer before drawing this graphic
@@ -604,28 +834,39 @@ func (sy *chartScaleY) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 }
 
 //
-// Graph is the GUI element that represents a single plotted function.
-// A Chart has an array of Graph objects.
+// Graph is the GUI element that represents a single plotted function
+// or set of data points. A Chart has an array of Graph objects.
 //
 type Graph struct {
-	Panel                   // Embedded panel
-	chart     *Chart        // Container chart
-	color     math32.Color  // Line color
-	data      []float32     // Data y
-	mat       chartMaterial // Chart material
-	vbo       *gls.VBO
-	positions math32.ArrayF32
-	uniBounds gls.Uniform // Bounds uniform location cache
+	Panel                    // Embedded panel
+	chart      *Chart        // Container chart
+	kind       GraphKind     // Kind of graph
+	name       string        // Name shown in the chart legend
+	color      math32.Color  // Line color
+	data       []float32     // Data y
+	dataX      []float32     // Data x (nil for evenly spaced x values)
+	count      int           // Number of data points
+	ring       bool          // Data is a ring buffer
+	head       int           // Index of the oldest data point in the ring buffer
+	axis       *ChartAxis    // Secondary y scale (nil for the primary y scale)
+	markerSize float32       // Size of the scatter markers in pixels
+	barW       float32       // Width of the bars in x units (0 for automatic)
+	baseline   float32       // Y value where bars and areas start
+	mat        chartMaterial // Chart material
+	vbo        *gls.VBO
+	positions  math32.ArrayF32
+	uniBounds  gls.Uniform // Bounds uniform location cache
 }
 
-// newGraph creates and returns a pointer to a new graph for the specified chart
-func newGraph(chart *Chart, color *math32.Color, data []float32) *Graph {
+// newGraph creates and returns a pointer to a new empty graph of the specified kind for the specified chart
+func newGraph(chart *Chart, kind GraphKind, color *math32.Color) *Graph {
 
 	lg := new(Graph)
 	lg.uniBounds.Init("Bounds")
 	lg.chart = chart
+	lg.kind = kind
 	lg.color = *color
-	lg.data = data
+	lg.markerSize = defaultMarkerSize
 
 	// Creates geometry and adds VBO with positions
 	geom := geometry.NewGeometry()
@@ -634,26 +875,136 @@ func newGraph(chart *Chart, color *math32.Color, data []float32) *Graph {
 	geom.AddVBO(lg.vbo)
 
 	// Initializes the panel with this graphic
-	gr := graphic.NewGraphic(lg, geom, gls.LINE_STRIP)
+	mode := uint32(gls.TRIANGLES)
+	if kind == GraphLine {
+		mode = gls.LINE_STRIP
+	}
+	gr := graphic.NewGraphic(lg, geom, mode)
 	lg.mat.Init(&lg.color)
 	gr.AddMaterial(lg, &lg.mat, 0, 0)
 	lg.Panel.InitializeGraphic(lg.chart.ContentWidth(), lg.chart.ContentHeight(), gr)
-
-	lg.SetData(data)
 	return lg
 }
 
+// Kind returns the kind of the graph
+func (lg *Graph) Kind() GraphKind {
+
+	return lg.kind
+}
+
+// SetName sets the name of the graph shown in the chart legend
+func (lg *Graph) SetName(name string) {
+
+	lg.name = name
+	lg.chart.updateLegend()
+}
+
+// Name returns the name of the graph
+func (lg *Graph) Name() string {
+
+	return lg.name
+}
+
 // SetColor sets the color of the graph
 func (lg *Graph) SetColor(color *math32.Color) {
 
 	lg.color = *color
+	lg.mat.color = *color
+	lg.chart.updateLegend()
 }
 
-// SetData sets the graph data
+// Color returns the color of the graph
+func (lg *Graph) Color() math32.Color {
+
+	return lg.color
+}
+
+// SetData sets the graph y values with evenly spaced x values
+// as specified by the chart SetRangeX
 func (lg *Graph) SetData(data []float32) {
 
-	lg.data = data
-	lg.updateData()
+	lg.setData(data)
+	lg.update()
+}
+
+// SetDataXY sets the graph x and y values.
+// If the slices have different lengths the extra values are ignored.
+func (lg *Graph) SetDataXY(x, y []float32) {
+
+	lg.setDataXY(x, y)
+	lg.update()
+}
+
+// SetHistogram sets the graph data to the histogram of the specified
+// values divided in the specified number of bins.
+// The x values are the centers of the bins and the y values their counts.
+func (lg *Graph) SetHistogram(values []float32, bins int) {
+
+	lg.setHistogram(values, bins)
+	lg.update()
+}
+
+// Push adds a data point to the graph.
+// For graphs created with AddRingGraph the oldest point is overwritten
+// when the graph is full. For other graphs the point is appended to its data.
+func (lg *Graph) Push(x, y float32) {
+
+	lg.push(x, y)
+	lg.update()
+}
+
+// PushN adds the data points with the specified x and y values to the graph.
+// It is equivalent to calling Push for each point but updates the chart only once.
+func (lg *Graph) PushN(x, y []float32) {
+
+	for i := 0; i < len(x) && i < len(y); i++ {
+		lg.push(x[i], y[i])
+	}
+	lg.update()
+}
+
+// Clear removes all the data points of the graph
+func (lg *Graph) Clear() {
+
+	lg.count = 0
+	lg.head = 0
+	lg.update()
+}
+
+// Len returns the number of data points of the graph
+func (lg *Graph) Len() int {
+
+	return lg.count
+}
+
+// At returns the x and y values of the data point at the specified index.
+// For ring graphs the index 0 is the oldest point.
+func (lg *Graph) At(i int) (x, y float32) {
+
+	pos := i
+	if lg.ring {
+		pos = (lg.head + i) % len(lg.data)
+	}
+	if lg.dataX != nil {
+		x = lg.dataX[pos]
+	} else {
+		x = lg.chart.firstX + float32(i)*lg.chart.stepX/lg.chart.countStepX
+	}
+	return x, lg.data[pos]
+}
+
+// SetAxis sets the y scale used by the graph.
+// Passing nil uses the primary y scale of the chart.
+func (lg *Graph) SetAxis(axis *ChartAxis) {
+
+	lg.axis = axis
+	lg.chart.updateGraphs()
+}
+
+// Axis returns the secondary y scale used by the graph or nil if it uses the primary y scale.
+func (lg *Graph) Axis() *ChartAxis {
+
+	return lg.axis
 }
 
 // SetLineWidth sets the graph line width
@@ -662,23 +1013,223 @@ func (lg *Graph) SetLineWidth(width float32) {
 	lg.mat.SetLineWidth(width)
 }
 
-// updateData regenerates the lines for the current data
-func (lg *Graph) updateData() {
+// SetMarkerSize sets the size in pixels of the markers of scatter graphs
+func (lg *Graph) SetMarkerSize(size float32) {
+
+	lg.markerSize = size
+	lg.updateData()
+}
+
+// SetBarWidth sets the width of the bars of bar graphs in x units.
+// A width of 0 uses a fraction of the smallest distance between data points.
+func (lg *Graph) SetBarWidth(width float32) {
+
+	lg.barW = width
+	lg.update()
+}
+
+// SetBaseline sets the y value where the bars and areas of bar and area graphs start.
+// The default baseline is 0.
+func (lg *Graph) SetBaseline(baseline float32) {
+
+	lg.baseline = baseline
+	lg.update()
+}
+
+// setData sets the graph y values with evenly spaced x values without updating the chart
+func (lg *Graph) setData(data []float32) {
+
+	lg.data = data
+	lg.dataX = nil
+	lg.count = len(data)
+	lg.ring = false
+	lg.head = 0
+}
+
+// setDataXY sets the graph x and y values without updating the chart
+func (lg *Graph) setDataXY(x, y []float32) {
+
+	n := len(x)
+	if len(y) < n {
+		n = len(y)
+	}
+	lg.data = y[:n]
+	lg.dataX = x[:n]
+	lg.count = n
+	lg.ring = false
+	lg.head = 0
+}
 
-	lines := 1
-	if lg.chart.scaleX != nil {
-		lines = lg.chart.scaleX.lines
+// setHistogram sets the graph data to the histogram of the specified values without updating the chart
+func (lg *Graph) setHistogram(values []float32, bins int) {
+
+	if bins < 1 {
+		bins = 1
+	}
+	minV := float32(math.MaxFloat32)
+	maxV := -float32(math.MaxFloat32)
+	for _, v := range values {
+		minV = math32.Min(minV, v)
+		maxV = math32.Max(maxV, v)
+	}
+	if len(values) == 0 {
+		minV = 0
+		maxV = 1
+	}
+	width := (maxV - minV) / float32(bins)
+	if width <= 0 {
+		width = 1
+	}
+	x := make([]float32, bins)
+	y := make([]float32, bins)
+	for i := range x {
+		x[i] = minV + (float32(i)+0.5)*width
 	}
-	step := 1.0 / (float32(lines) * lg.chart.countStepX)
+	for _, v := range values {
+		bin := int((v - minV) / width)
+		if bin >= bins {
+			bin = bins - 1
+		}
+		y[bin]++
+	}
+	lg.setDataXY(x, y)
+	lg.barW = width
+}
 
-	positions := math32.NewArrayF32(0, 0)
-	rangeY := lg.chart.maxY - lg.chart.minY
-	for i := 0; i < len(lg.data); i++ {
-		px := float32(i) * step
-		vy := lg.data[i]
-		py := -1 + ((vy - lg.chart.minY) / rangeY)
-		positions.Append(px, py, 0)
+// push adds a data point to the graph without updating the chart
+func (lg *Graph) push(x, y float32) {
+
+	if !lg.ring {
+		// Evenly spaced x values must be converted to explicit values
+		if lg.dataX == nil {
+			lg.dataX = make([]float32, lg.count)
+			for i := range lg.dataX {
+				lg.dataX[i], _ = lg.At(i)
+			}
+		}
+		lg.dataX = append(lg.dataX[:lg.count], x)
+		lg.data = append(lg.data[:lg.count], y)
+		lg.count++
+		return
+	}
+	size := len(lg.data)
+	if size == 0 {
+		return
+	}
+	pos := (lg.head + lg.count) % size
+	if lg.count == size {
+		lg.head = (lg.head + 1) % size
+	} else {
+		lg.count++
+	}
+	lg.dataX[pos] = x
+	lg.data[pos] = y
+}
+
+// update updates the chart after the graph data changed
+func (lg *Graph) update() {
+
+	if lg.chart.autoRange() {
+		lg.chart.updateGraphs()
+		return
+	}
+	lg.updateData()
+}
+
+// barWidth returns the width of the bars in x units
+func (lg *Graph) barWidth() float32 {
+
+	if lg.barW > 0 {
+		return lg.barW
+	}
+	if lg.dataX == nil {
+		return defaultBarFactor * lg.chart.stepX / lg.chart.countStepX
+	}
+	gap := float32(math.MaxFloat32)
+	if lg.count > 1 {
+		prev, _ := lg.At(0)
+		for i := 1; i < lg.count; i++ {
+			vx, _ := lg.At(i)
+			if d := math32.Abs(vx - prev); d > 0 && d < gap {
+				gap = d
+			}
+			prev = vx
+		}
+	}
+	if gap == math.MaxFloat32 {
+		return 1
+	}
+	return defaultBarFactor * gap
+}
+
+// vertsPerPoint returns the number of vertices generated for each data point
+func (lg *Graph) vertsPerPoint() int {
+
+	if lg.kind == GraphLine {
+		return 1
 	}
+	return 6
+}
+
+// updateData regenerates the geometry for the current data and chart ranges
+func (lg *Graph) updateData() {
+
+	minX, maxX := lg.chart.RangeX()
+	minY, maxY := lg.chart.rangeY(lg.axis)
+	sx := rangeFactor(minX, maxX)
+	sy := rangeFactor(minY, maxY)
+	nx := func(vx float32) float32 { return (vx - minX) * sx }
+	ny := func(vy float32) float32 { return -1 + (vy-minY)*sy }
+
+	// Reuses the positions buffer to avoid reallocations when streaming data
+	positions := lg.positions[:0]
+	switch lg.kind {
+	case GraphLine:
+		for i := 0; i < lg.count; i++ {
+			vx, vy := lg.At(i)
+			positions.Append(nx(vx), ny(vy), 0)
+		}
+	case GraphScatter:
+		// Diamond markers with fixed size in pixels
+		var hx, hy float32
+		if lg.width > 0 && lg.height > 0 {
+			hx = lg.markerSize / 2 / lg.width
+			hy = lg.markerSize / 2 / lg.height
+		}
+		for i := 0; i < lg.count; i++ {
+			vx, vy := lg.At(i)
+			x, y := nx(vx), ny(vy)
+			positions.Append(x-hx, y, 0, x, y+hy, 0, x+hx, y, 0)
+			positions.Append(x-hx, y, 0, x+hx, y, 0, x, y-hy, 0)
+		}
+	case GraphBar:
+		hw := lg.barWidth() / 2 * sx
+		base := ny(lg.baseline)
+		for i := 0; i < lg.count; i++ {
+			vx, vy := lg.At(i)
+			x, y := nx(vx), ny(vy)
+			positions.Append(x-hw, base, 0, x+hw, base, 0, x+hw, y, 0)
+			positions.Append(x-hw, base, 0, x+hw, y, 0, x-hw, y, 0)
+		}
+	case GraphArea:
+		base := ny(lg.baseline)
+		for i := 1; i < lg.count; i++ {
+			vx0, vy0 := lg.At(i - 1)
+			vx1, vy1 := lg.At(i)
+			x0, y0 := nx(vx0), ny(vy0)
+			x1, y1 := nx(vx1), ny(vy1)
+			// Splits the segment where it crosses the baseline
+			if (y0-base)*(y1-base) < 0 {
+				xc := x0 + (x1-x0)*(base-y0)/(y1-y0)
+				positions.Append(x0, base, 0, xc, base, 0, x0, y0, 0)
+				positions.Append(xc, base, 0, x1, base, 0, x1, y1, 0)
+				continue
+			}
+			positions.Append(x0, base, 0, x1, base, 0, x1, y1, 0)
+			positions.Append(x0, base, 0, x1, y1, 0, x0, y0, 0)
+		}
+	}
+	lg.positions = positions
 	lg.vbo.SetBuffer(positions)
 	lg.SetChanged(true)
 }
@@ -686,15 +1237,13 @@ func (lg *Graph) updateData() {
 // recalc recalculates the position and width of the this panel
 func (lg *Graph) recalc() {
 
-	py := lg.chart.top
-	if lg.chart.title != nil {
-		py += lg.chart.title.Height()
-	}
-	px := lg.chart.left
-	w := lg.chart.ContentWidth() - lg.chart.left
-	h := lg.chart.ContentHeight() - py - lg.chart.bottom
+	px, py, width, height := lg.chart.plotArea()
 	lg.SetPosition(px, py)
-	lg.SetSize(w, h)
+	lg.SetSize(width, height)
+	// The size of the markers in pixels depends on the panel size
+	if lg.kind == GraphScatter {
+		lg.updateData()
+	}
 }
 
 // RenderSetup is called by the renderer before drawing this graphic
@@ -716,6 +1265,15 @@ func (lg *Graph) RenderSetup(gs *gls.GLS, rinfo *core.RenderInfo) {
 	gs.Uniform4f(location, lg.pospix.X, float32(height)-lg.pospix.Y, lg.width, lg.height)
 }
 
+// rangeFactor returns the factor which converts values in the specified range to the 0 to 1 range
+func rangeFactor(min, max float32) float32 {
+
+	if max == min {
+		return 1
+	}
+	return 1 / (max - min)
+}
+
 //
 // Chart material
 //
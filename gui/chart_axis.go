@@ -0,0 +1,186 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/math32"
+)
+
+// ChartAxis is a secondary Y scale of a Chart shown at its right side.
+// It shares the horizontal lines of the primary Y scale but has its own range,
+// so graphs with values of different magnitudes can be shown together.
+// Graphs are associated with the scale using Graph.SetAxis.
+type ChartAxis struct {
+	chart    *Chart        // Container chart
+	min      float32       // Minimum Y value
+	max      float32       // Maximum Y value
+	auto     bool          // Auto range flag
+	format   string        // String format of the labels
+	fontSize float64       // Font size of the labels
+	color    math32.Color4 // Color of the labels
+	width    float32       // Width in pixels reserved for the labels
+	labels   []*Label      // Array of labels
+}
+
+// defaultAxisWidth is the default width in pixels reserved for the labels of a secondary Y scale
+const defaultAxisWidth = 40
+
+// AddAxisY adds a secondary Y scale to the chart with labels of the specified color.
+// Secondary scales are placed from left to right after the right side of the chart.
+func (ch *Chart) AddAxisY(color *math32.Color) *ChartAxis {
+
+	axis := new(ChartAxis)
+	axis.chart = ch
+	axis.min = -10
+	axis.max = 10
+	axis.format = "%v"
+	axis.fontSize = ch.fontSizeY
+	axis.color = math32.Color4{color.R, color.G, color.B, 1}
+	axis.width = defaultAxisWidth
+	ch.axes = append(ch.axes, axis)
+	ch.recalcRight()
+	return axis
+}
+
+// RemoveAxisY removes the specified secondary Y scale from the chart.
+// The graphs which used it are changed to use the primary Y scale.
+func (ch *Chart) RemoveAxisY(axis *ChartAxis) {
+
+	for pos, current := range ch.axes {
+		if current == axis {
+			copy(ch.axes[pos:], ch.axes[pos+1:])
+			ch.axes[len(ch.axes)-1] = nil
+			ch.axes = ch.axes[:len(ch.axes)-1]
+			break
+		}
+	}
+	for _, label := range axis.labels {
+		ch.Remove(label)
+		label.Dispose()
+	}
+	axis.labels = nil
+	for _, graph := range ch.graphs {
+		if graph.axis == axis {
+			graph.axis = nil
+		}
+	}
+	ch.recalcRight()
+}
+
+// AxesY returns the secondary Y scales of the chart
+func (ch *Chart) AxesY() []*ChartAxis {
+
+	return ch.axes
+}
+
+// recalcRight recalculates the right margin of the chart from the widths of the secondary Y scales
+func (ch *Chart) recalcRight() {
+
+	ch.right = 0
+	for _, axis := range ch.axes {
+		ch.right += axis.width
+	}
+	ch.recalc()
+	ch.updateGraphs()
+}
+
+// SetRange sets the minimum and maximum values of the scale
+func (axis *ChartAxis) SetRange(min, max float32) {
+
+	if axis.auto {
+		return
+	}
+	axis.min = min
+	axis.max = max
+	axis.chart.updateGraphs()
+}
+
+// SetRangeAuto sets the state of the auto range of the scale
+func (axis *ChartAxis) SetRangeAuto(auto bool) {
+
+	axis.auto = auto
+	if !auto {
+		return
+	}
+	axis.chart.updateGraphs()
+}
+
+// Range returns the current range of the scale
+func (axis *ChartAxis) Range() (min, max float32) {
+
+	return axis.min, axis.max
+}
+
+// SetFormat sets the string format of the scale labels
+func (axis *ChartAxis) SetFormat(format string) {
+
+	axis.format = format
+	axis.updateLabels()
+}
+
+// SetFontSize sets the font size of the scale labels
+func (axis *ChartAxis) SetFontSize(size float64) {
+
+	axis.fontSize = size
+	for _, label := range axis.labels {
+		label.SetFontSize(size)
+	}
+	axis.updateLabels()
+}
+
+// SetWidth sets the width in pixels reserved at the right of the chart for the scale labels
+func (axis *ChartAxis) SetWidth(width float32) {
+
+	axis.width = width
+	axis.chart.recalcRight()
+}
+
+// offset returns the horizontal position of the scale labels inside the chart content area
+func (axis *ChartAxis) offset() float32 {
+
+	px, _, width, _ := axis.chart.plotArea()
+	px += width
+	for _, current := range axis.chart.axes {
+		if current == axis {
+			break
+		}
+		px += current.width
+	}
+	return px
+}
+
+// updateLabels updates the number, text and positions of the scale labels
+func (axis *ChartAxis) updateLabels() {
+
+	ch := axis.chart
+	nlines := ch.linesY()
+
+	// Keeps one label for each horizontal line of the primary scale
+	for len(axis.labels) > nlines {
+		label := axis.labels[len(axis.labels)-1]
+		ch.Remove(label)
+		label.Dispose()
+		axis.labels = axis.labels[:len(axis.labels)-1]
+	}
+	for len(axis.labels) < nlines {
+		label := NewLabel("")
+		label.SetColor4(&axis.color)
+		label.SetFontSize(axis.fontSize)
+		ch.Add(label)
+		axis.labels = append(axis.labels, label)
+	}
+
+	_, _, _, height := ch.plotArea()
+	vstep := (axis.max - axis.min) / float32(nlines-1)
+	pstep := height / float32(nlines-1)
+	px := axis.offset() + 4
+	for i, label := range axis.labels {
+		label.SetText(fmt.Sprintf(axis.format, axis.min+float32(i)*vstep))
+		py := ch.ContentHeight() - ch.bottom - float32(i)*pstep
+		label.SetPosition(px, py-label.Height()/2)
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// chartLegend is the panel which shows the colors and names of the named graphs of a chart.
+// Clicking an entry toggles the visibility of its graph.
+type chartLegend struct {
+	Panel        // Embedded panel
+	chart *Chart // Container chart
+}
+
+// Legend dimensions in pixels
+const (
+	legendSwatch  = 10 // Size of the color swatch of each entry
+	legendSpacing = 4  // Spacing between the swatch and the name and between the legend and the plot area borders
+)
+
+// SetLegend sets whether the chart shows a legend with the colors and names of its graphs.
+// Only graphs with names set by Graph.SetName are shown.
+func (ch *Chart) SetLegend(state bool) {
+
+	if state == (ch.legend != nil) {
+		return
+	}
+	if !state {
+		ch.Remove(ch.legend)
+		ch.legend.Dispose()
+		ch.legend = nil
+		return
+	}
+	ch.legend = newChartLegend(ch)
+	ch.Add(ch.legend)
+	ch.updateLegend()
+	ch.recalc()
+}
+
+// Legend returns whether the chart shows a legend.
+func (ch *Chart) Legend() bool {
+
+	return ch.legend != nil
+}
+
+// updateLegend rebuilds the legend entries after the graphs changed
+func (ch *Chart) updateLegend() {
+
+	if ch.legend == nil {
+		return
+	}
+	ch.legend.rebuild()
+	ch.legend.recalc()
+}
+
+// newChartLegend creates and returns a pointer to a new empty legend for the specified chart
+func newChartLegend(chart *Chart) *chartLegend {
+
+	cl := new(chartLegend)
+	cl.Panel.Initialize(cl, 0, 0)
+	cl.chart = chart
+	cl.SetBorders(1, 1, 1, 1)
+	cl.SetPaddings(legendSpacing, legendSpacing, legendSpacing, legendSpacing)
+	cl.SetBordersColor4(math32.NewColor4("gray"))
+	cl.SetColor4(&math32.Color4{1, 1, 1, 0.9})
+	return cl
+}
+
+// rebuild removes all the legend entries and adds one entry for each named graph
+func (cl *chartLegend) rebuild() {
+
+	cl.DisposeChildren(true)
+	var width, height float32
+	for _, graph := range cl.chart.graphs {
+		if graph.name == "" {
+			continue
+		}
+		entry := cl.newEntry(graph)
+		entry.SetPosition(0, height)
+		cl.Add(entry)
+		width = math32.Max(width, entry.Width())
+		height += entry.Height()
+	}
+	cl.SetContentSize(width, height)
+	cl.SetVisible(height > 0)
+}
+
+// newEntry creates and returns a new legend entry for the specified graph
+func (cl *chartLegend) newEntry(graph *Graph) *Panel {
+
+	swatch := NewPanel(legendSwatch, legendSwatch)
+	swatch.SetColor(&graph.color)
+	label := NewLabel(graph.name)
+	label.SetFontSize(cl.chart.fontSizeY)
+	cl.setEntryColor(label, graph)
+	swatch.SetPosition(0, (label.Height()-legendSwatch)/2)
+	label.SetPosition(legendSwatch+legendSpacing, 0)
+
+	entry := NewPanel(legendSwatch+legendSpacing+label.Width(), label.Height())
+	entry.Add(swatch)
+	entry.Add(label)
+	entry.Subscribe(OnMouseDown, func(evname string, ev interface{}) {
+		graph.SetVisible(!graph.Visible())
+		cl.setEntryColor(label, graph)
+		cl.chart.hideReadout()
+		cl.chart.updateGraphs()
+	})
+	return entry
+}
+
+// setEntryColor sets the color of the name of the specified graph, which is dimmed when the graph is hidden
+func (cl *chartLegend) setEntryColor(label *Label, graph *Graph) {
+
+	if graph.Visible() {
+		label.SetColor4(math32.NewColor4("black"))
+	} else {
+		label.SetColor4(math32.NewColor4("gray"))
+	}
+}
+
+// recalc places the legend at the top right corner of the plot area
+func (cl *chartLegend) recalc() {
+
+	px, py, width, _ := cl.chart.plotArea()
+	cl.SetPosition(px+width-cl.Width()-legendSpacing, py+legendSpacing)
+}
@@ -0,0 +1,346 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/window"
+)
+
+// chartView keeps the chart ranges before the view is zoomed or panned
+type chartView struct {
+	autoY bool            // Auto range flag of the primary Y scale
+	minY  float32         // Minimum value of the primary Y scale
+	maxY  float32         // Maximum value of the primary Y scale
+	axes  []chartAxisView // Ranges of the secondary Y scales
+}
+
+// chartAxisView keeps the range of a secondary Y scale before the view is zoomed or panned
+type chartAxisView struct {
+	axis *ChartAxis
+	auto bool
+	min  float32
+	max  float32
+}
+
+const (
+	chartZoomStep      = 1.2 // Zoom factor for each mouse wheel step
+	chartHoverDistance = 20  // Maximum distance in pixels from the cursor to the hovered data point
+	chartMarkerSize    = 9   // Size in pixels of the hovered data point marker
+	chartReadoutOffset = 8   // Distance in pixels between the hovered data point and its readout
+)
+
+// SetHoverReadout sets whether the chart shows the values of the
+// data point nearest to the cursor.
+func (ch *Chart) SetHoverReadout(state bool) {
+
+	ch.hover = state
+	if !state {
+		ch.hideReadout()
+	}
+	ch.updateSubscriptions()
+}
+
+// HoverReadout returns whether the chart shows the values of the data point nearest to the cursor.
+func (ch *Chart) HoverReadout() bool {
+
+	return ch.hover
+}
+
+// SetZoomPan sets whether the X and/or Y ranges of the chart can be zoomed
+// with the mouse wheel and panned by dragging with the left mouse button.
+// Zooming or panning a range disables its auto range until ResetView is called.
+func (ch *Chart) SetZoomPan(x, y bool) {
+
+	ch.zoomX = x
+	ch.zoomY = y
+	ch.updateSubscriptions()
+}
+
+// ZoomPan returns whether the X and Y ranges of the chart can be zoomed and panned.
+func (ch *Chart) ZoomPan() (x, y bool) {
+
+	return ch.zoomX, ch.zoomY
+}
+
+// ResetView restores the ranges the chart had before they were zoomed or panned.
+func (ch *Chart) ResetView() {
+
+	ch.viewX = false
+	if ch.saved != nil {
+		ch.autoY = ch.saved.autoY
+		ch.minY = ch.saved.minY
+		ch.maxY = ch.saved.maxY
+		for _, av := range ch.saved.axes {
+			av.axis.auto = av.auto
+			av.axis.min = av.min
+			av.axis.max = av.max
+		}
+		ch.saved = nil
+	}
+	ch.hideReadout()
+	ch.updateGraphs()
+}
+
+// NearestPoint returns the visible graph and the index of its data point nearest
+// to the specified position in window coordinates.
+// Returns nil if there is no data point near the position.
+func (ch *Chart) NearestPoint(x, y float32) (*Graph, int) {
+
+	cx, cy := ch.ContentCoords(x, y)
+	px, py, width, height := ch.plotArea()
+	var nearest *Graph
+	index := -1
+	best := float32(chartHoverDistance * chartHoverDistance)
+	for _, graph := range ch.graphs {
+		if !graph.Visible() {
+			continue
+		}
+		for i := 0; i < graph.count; i++ {
+			gx, gy := ch.pointPos(graph, i)
+			// Ignore points outside of the plot area
+			if gx < px || gx > px+width || gy < py || gy > py+height {
+				continue
+			}
+			dx := gx - cx
+			dy := gy - cy
+			if d := dx*dx + dy*dy; d <= best {
+				best = d
+				nearest = graph
+				index = i
+			}
+		}
+	}
+	return nearest, index
+}
+
+// pointPos returns the position in the chart content area of the specified data point of a graph
+func (ch *Chart) pointPos(graph *Graph, i int) (x, y float32) {
+
+	px, py, width, height := ch.plotArea()
+	minX, maxX := ch.RangeX()
+	minY, maxY := ch.rangeY(graph.axis)
+	vx, vy := graph.At(i)
+	x = px + (vx-minX)*rangeFactor(minX, maxX)*width
+	y = py + height - (vy-minY)*rangeFactor(minY, maxY)*height
+	return x, y
+}
+
+// updateSubscriptions subscribes to the events needed by the enabled interactions only,
+// so the events are still received by the chart ancestors otherwise.
+func (ch *Chart) updateSubscriptions() {
+
+	ch.UnsubscribeAllID(ch)
+	if ch.hover || ch.zoomX || ch.zoomY {
+		ch.SubscribeID(OnCursor, ch, ch.onCursor)
+		ch.SubscribeID(OnCursorLeave, ch, ch.onCursor)
+	}
+	if ch.zoomX || ch.zoomY {
+		ch.SubscribeID(OnMouseDown, ch, ch.onMouse)
+		ch.SubscribeID(OnMouseUp, ch, ch.onMouse)
+		ch.SubscribeID(OnMouseUpOut, ch, ch.onMouse)
+		ch.SubscribeID(OnScroll, ch, ch.onScroll)
+	}
+}
+
+// onCursor process OnCursor and OnCursorLeave events for this chart
+func (ch *Chart) onCursor(evname string, ev interface{}) {
+
+	if evname == OnCursorLeave {
+		if !ch.panning {
+			ch.hideReadout()
+		}
+		return
+	}
+	cev := ev.(*window.CursorEvent)
+	cx, cy := ch.ContentCoords(cev.Xpos, cev.Ypos)
+	if ch.panning {
+		ch.pan(cx-ch.cursorX, cy-ch.cursorY)
+		ch.cursorX = cx
+		ch.cursorY = cy
+		return
+	}
+	ch.cursorX = cx
+	ch.cursorY = cy
+	if !ch.hover {
+		return
+	}
+	graph, index := ch.NearestPoint(cev.Xpos, cev.Ypos)
+	if graph == nil {
+		ch.hideReadout()
+		return
+	}
+	ch.showReadout(graph, index)
+}
+
+// onMouse process OnMouseDown, OnMouseUp and OnMouseUpOut events for this chart
+func (ch *Chart) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft {
+		return
+	}
+	switch evname {
+	case OnMouseDown:
+		cx, cy := ch.ContentCoords(mev.Xpos, mev.Ypos)
+		px, py, width, height := ch.plotArea()
+		if cx < px || cx > px+width || cy < py || cy > py+height {
+			return
+		}
+		ch.panning = true
+		ch.cursorX = cx
+		ch.cursorY = cy
+		ch.hideReadout()
+		Manager().SetCursorFocus(ch)
+	case OnMouseUp, OnMouseUpOut:
+		if ch.panning {
+			ch.panning = false
+			Manager().SetCursorFocus(nil)
+		}
+	}
+}
+
+// onScroll process OnScroll events for this chart
+func (ch *Chart) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	factor := float32(math.Pow(chartZoomStep, float64(-sev.Yoffset)))
+	ch.zoom(factor, ch.cursorX, ch.cursorY)
+}
+
+// zoom scales the enabled ranges by the specified factor keeping the values
+// at the specified position in the content area fixed
+func (ch *Chart) zoom(factor, cx, cy float32) {
+
+	ch.saveView()
+	px, py, width, height := ch.plotArea()
+	if ch.zoomX {
+		minX, maxX := ch.RangeX()
+		ch.minX, ch.maxX = zoomRange(minX, maxX, (cx-px)/width, factor)
+		ch.viewX = true
+	}
+	if ch.zoomY {
+		fy := (py + height - cy) / height
+		ch.minY, ch.maxY = zoomRange(ch.minY, ch.maxY, fy, factor)
+		ch.autoY = false
+		for _, axis := range ch.axes {
+			axis.min, axis.max = zoomRange(axis.min, axis.max, fy, factor)
+			axis.auto = false
+		}
+	}
+	ch.hideReadout()
+	ch.updateGraphs()
+}
+
+// pan shifts the enabled ranges by the specified displacement in pixels
+func (ch *Chart) pan(dx, dy float32) {
+
+	ch.saveView()
+	_, _, width, height := ch.plotArea()
+	if ch.zoomX {
+		minX, maxX := ch.RangeX()
+		shift := -dx / width * (maxX - minX)
+		ch.minX = minX + shift
+		ch.maxX = maxX + shift
+		ch.viewX = true
+	}
+	if ch.zoomY {
+		shift := dy / height * (ch.maxY - ch.minY)
+		ch.minY += shift
+		ch.maxY += shift
+		ch.autoY = false
+		for _, axis := range ch.axes {
+			shift := dy / height * (axis.max - axis.min)
+			axis.min += shift
+			axis.max += shift
+			axis.auto = false
+		}
+	}
+	ch.updateGraphs()
+}
+
+// saveView saves the current Y ranges if they were not yet changed by zooming or panning
+func (ch *Chart) saveView() {
+
+	if ch.saved != nil {
+		return
+	}
+	ch.saved = &chartView{autoY: ch.autoY, minY: ch.minY, maxY: ch.maxY}
+	for _, axis := range ch.axes {
+		ch.saved.axes = append(ch.saved.axes, chartAxisView{axis, axis.auto, axis.min, axis.max})
+	}
+}
+
+// showReadout shows the marker and the values of the specified data point of a graph
+func (ch *Chart) showReadout(graph *Graph, i int) {
+
+	if ch.readout == nil {
+		ch.marker = NewPanel(chartMarkerSize, chartMarkerSize)
+		ch.marker.SetBorders(2, 2, 2, 2)
+		ch.marker.SetColor4(&math32.Color4{0, 0, 0, 0})
+		ch.Add(ch.marker)
+		ch.readout = NewLabel("")
+		ch.readout.SetColor4(math32.NewColor4("black"))
+		ch.readout.SetBgColor4(&math32.Color4{1, 1, 1, 0.9})
+		ch.readout.SetBorders(1, 1, 1, 1)
+		ch.readout.SetBordersColor4(math32.NewColor4("gray"))
+		ch.readout.SetPaddings(2, 4, 2, 4)
+		ch.Add(ch.readout)
+	}
+
+	// Sets the readout text
+	vx, vy := graph.At(i)
+	formatY := ch.formatY
+	if graph.axis != nil {
+		formatY = graph.axis.format
+	}
+	text := fmt.Sprintf(ch.formatX, vx) + ", " + fmt.Sprintf(formatY, vy)
+	if graph.name != "" {
+		text = graph.name + ": " + text
+	}
+	ch.readout.SetText(text)
+
+	// Places the marker over the data point
+	px, py := ch.pointPos(graph, i)
+	ch.marker.SetBordersColor4(&math32.Color4{graph.color.R, graph.color.G, graph.color.B, 1})
+	ch.marker.SetPosition(px-chartMarkerSize/2, py-chartMarkerSize/2)
+
+	// Places the readout above and to the right of the data point, keeping it inside the chart
+	rx := px + chartReadoutOffset
+	if rx+ch.readout.Width() > ch.ContentWidth() {
+		rx = px - chartReadoutOffset - ch.readout.Width()
+	}
+	ry := py - chartReadoutOffset - ch.readout.Height()
+	if ry < 0 {
+		ry = py + chartReadoutOffset
+	}
+	ch.readout.SetPosition(rx, ry)
+
+	ch.marker.SetVisible(true)
+	ch.readout.SetVisible(true)
+	ch.SetTopChild(ch.marker)
+	ch.SetTopChild(ch.readout)
+}
+
+// hideReadout hides the marker and the values of the hovered data point
+func (ch *Chart) hideReadout() {
+
+	if ch.readout == nil {
+		return
+	}
+	ch.marker.SetVisible(false)
+	ch.readout.SetVisible(false)
+}
+
+// zoomRange scales the specified range by the specified factor keeping
+// the value at the specified fraction of the range fixed
+func zoomRange(min, max, frac, factor float32) (float32, float32) {
+
+	anchor := min + frac*(max-min)
+	return anchor - (anchor-min)*factor, anchor + (max-anchor)*factor
+}
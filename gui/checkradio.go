@@ -76,6 +76,13 @@ func newCheckRadio(check bool, text string) *CheckRadio {
 
 	// Initialize panel
 	cb.Panel.Initialize(cb, 0, 0)
+	cb.SetFocusable(true)
+	cb.SetAccessibleName(text)
+	if check {
+		cb.SetAccessibleRole(RoleCheckBox)
+	} else {
+		cb.SetAccessibleRole(RoleRadio)
+	}
 
 	// Subscribe to events
 	cb.Panel.Subscribe(OnKeyDown, cb.onKey)
@@ -196,7 +203,7 @@ func (cb *CheckRadio) onCursor(evname string, ev interface{}) {
 func (cb *CheckRadio) onKey(evname string, ev interface{}) {
 
 	kev := ev.(*window.KeyEvent)
-	if evname == OnKeyDown && kev.Key == window.KeyEnter {
+	if evname == OnKeyDown && (kev.Key == window.KeyEnter || kev.Key == window.KeySpace) {
 		cb.toggleState()
 		cb.update()
 		cb.Dispatch(OnClick, nil)
@@ -0,0 +1,437 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/engine/window"
+)
+
+/***************************************
+
+ ColorDialog
+ +---------------------------------------+
+ |                 Title                 |
+ +---------------------------------------+
+ |   .-----.         +---------------+   |
+ |  /  HSV  \        |    preview    |   |
+ | |  wheel  |       +---------------+   |
+ |  \       /        R [   ]  G [   ]    |
+ |   '-----'         B [   ]  A [   ]    |
+ | [==== Value ====] Hex [          ]    |
+ | [==== Alpha ====]                     |
+ |                       [OK] [Cancel]   |
+ +---------------------------------------+
+
+****************************************/
+
+// ColorDialog is a modal dialog to choose a color with an HSV wheel,
+// RGBA or hexadecimal input and an alpha slider.
+// OnChange is dispatched whenever the color is changed and OnDialogAccept
+// when the color is chosen. The color can then be obtained with Color().
+type ColorDialog struct {
+	Dialog                      // Embedded dialog
+	color    math32.Color4      // Current color
+	hue      float32            // Current hue (0 to 1)
+	sat      float32            // Current saturation (0 to 1)
+	val      float32            // Current value (0 to 1)
+	wheel    *Image             // HSV wheel image
+	wheelTex *texture.Texture2D // HSV wheel texture
+	wheelRGB *image.RGBA        // HSV wheel image data
+	wheelVal float32            // Value used to draw the wheel image
+	marker   *Panel             // Marker of the current hue and saturation over the wheel
+	pressed  bool               // Mouse button pressed over the wheel
+	sValue   *Slider            // Value slider
+	sAlpha   *Slider            // Alpha slider
+	preview  *Panel             // Preview of the current color
+	edits    [4]*Edit           // Red, green, blue and alpha edits (0 to 255)
+	labels   [4]*Label          // Labels of the RGBA edits
+	hex      *Edit              // Hexadecimal edit
+	lhex     *Label             // Label of the hexadecimal edit
+	updating bool               // Internal panels are being updated
+}
+
+// colorWheelSize is the diameter in pixels of the color dialog HSV wheel
+const colorWheelSize = 180
+
+// NewColorDialog creates and returns a pointer to a new color dialog with the specified title.
+// The initial color is opaque white.
+func NewColorDialog(title string) *ColorDialog {
+
+	const width = 420
+	const height = 320
+
+	cd := new(ColorDialog)
+	cd.Dialog.initialize(width, height, title, "OK")
+
+	// HSV wheel and marker
+	cd.wheelRGB = image.NewRGBA(image.Rect(0, 0, colorWheelSize, colorWheelSize))
+	cd.wheelVal = -1
+	cd.wheelTex = texture.NewTexture2DFromRGBA(cd.wheelRGB)
+	cd.wheel = NewImageFromTex(cd.wheelTex)
+	cd.wheel.Subscribe(OnMouseDown, cd.onWheel)
+	cd.wheel.Subscribe(OnMouseUp, cd.onWheel)
+	cd.wheel.Subscribe(OnCursor, cd.onWheel)
+	cd.Add(cd.wheel)
+	cd.marker = NewPanel(10, 10)
+	cd.marker.SetBorders(2, 2, 2, 2)
+	cd.marker.SetBordersColor4(&math32.Color4{1, 1, 1, 1})
+	cd.marker.SetColor4(&math32.Color4{0, 0, 0, 0})
+	cd.marker.SetBounded(false)
+	cd.wheel.Add(cd.marker)
+
+	// Value and alpha sliders
+	cd.sValue = NewHSlider(colorWheelSize, 20)
+	cd.sValue.SetText("Value")
+	cd.sValue.Subscribe(OnChange, func(evname string, ev interface{}) {
+		if !cd.updating {
+			cd.setHSV(cd.hue, cd.sat, cd.sValue.Value())
+		}
+	})
+	cd.Add(cd.sValue)
+	cd.sAlpha = NewHSlider(colorWheelSize, 20)
+	cd.sAlpha.SetText("Alpha")
+	cd.sAlpha.Subscribe(OnChange, func(evname string, ev interface{}) {
+		if !cd.updating {
+			c := cd.color
+			c.A = cd.sAlpha.Value()
+			cd.setColor(&c, cd.sAlpha)
+		}
+	})
+	cd.Add(cd.sAlpha)
+
+	// Color preview
+	cd.preview = NewPanel(0, 40)
+	cd.preview.SetBorders(1, 1, 1, 1)
+	cd.preview.SetBordersColor4(&StyleDefault().Window.Normal.BorderColor)
+	cd.Add(cd.preview)
+
+	// RGBA and hexadecimal edits
+	for i, name := range []string{"R", "G", "B", "A"} {
+		cd.labels[i] = NewLabel(name)
+		cd.Add(cd.labels[i])
+		ed := NewEdit(48, "")
+		ed.MaxLength = 3
+		ed.Subscribe(OnChange, cd.onEdit)
+		ed.Subscribe(OnKeyDown, cd.onKey)
+		cd.edits[i] = ed
+		cd.Add(ed)
+	}
+	cd.lhex = NewLabel("Hex")
+	cd.Add(cd.lhex)
+	cd.hex = NewEdit(120, "#RRGGBBAA")
+	cd.hex.MaxLength = 9
+	cd.hex.Subscribe(OnChange, cd.onHex)
+	cd.hex.Subscribe(OnKeyDown, cd.onKey)
+	cd.Add(cd.hex)
+
+	cd.recalc()
+	cd.SetColor(&math32.Color4{1, 1, 1, 1})
+	return cd
+}
+
+// SetColor sets the current color of the dialog.
+func (cd *ColorDialog) SetColor(c *math32.Color4) {
+
+	cd.setColor(c, nil)
+}
+
+// Color returns the current color of the dialog.
+func (cd *ColorDialog) Color() math32.Color4 {
+
+	return cd.color
+}
+
+// setColor sets the current color from its RGBA components and updates
+// all internal panels except the specified one, which originated the change.
+func (cd *ColorDialog) setColor(c *math32.Color4, except IPanel) {
+
+	cd.color = *c
+	h, s, v := rgbToHSV(c.R, c.G, c.B)
+	// Keep the hue and saturation when they are undefined
+	if v > 0 {
+		if s > 0 {
+			cd.hue = h
+		}
+		cd.sat = s
+	}
+	cd.val = v
+	cd.update(except)
+}
+
+// setHSV sets the current color from its hue, saturation and value keeping its alpha
+func (cd *ColorDialog) setHSV(h, s, v float32) {
+
+	cd.hue = h
+	cd.sat = s
+	cd.val = v
+	cd.color.R, cd.color.G, cd.color.B = hsvToRGB(h, s, v)
+	cd.update(nil)
+}
+
+// update updates all the internal panels except the specified one
+// from the current color and dispatches OnChange.
+func (cd *ColorDialog) update(except IPanel) {
+
+	cd.updating = true
+	c := &cd.color
+	comps := []float32{c.R, c.G, c.B, c.A}
+	for i, ed := range cd.edits {
+		if ed != except {
+			ed.SetText(strconv.Itoa(int(comps[i]*255 + 0.5)))
+		}
+	}
+	if cd.hex != except {
+		cd.hex.SetText(colorToHex(c))
+	}
+	if cd.sValue != except {
+		cd.sValue.SetValue(cd.val)
+	}
+	if cd.sAlpha != except {
+		cd.sAlpha.SetValue(c.A)
+	}
+	cd.preview.SetColor4(c)
+	cd.drawWheel()
+	cd.updating = false
+	cd.Dispatch(OnChange, nil)
+}
+
+// onWheel receives mouse and cursor events over the HSV wheel
+func (cd *ColorDialog) onWheel(evname string, ev interface{}) {
+
+	var x, y float32
+	switch evname {
+	case OnMouseDown:
+		mev := ev.(*window.MouseEvent)
+		if mev.Button != window.MouseButtonLeft {
+			return
+		}
+		cd.pressed = true
+		Manager().SetCursorFocus(cd.wheel)
+		x, y = mev.Xpos, mev.Ypos
+	case OnMouseUp:
+		cd.pressed = false
+		Manager().SetCursorFocus(nil)
+		return
+	case OnCursor:
+		if !cd.pressed {
+			return
+		}
+		cev := ev.(*window.CursorEvent)
+		x, y = cev.Xpos, cev.Ypos
+	}
+
+	// Convert the position to hue and saturation
+	cx, cy := cd.wheel.ContentCoords(x, y)
+	r := float32(colorWheelSize) / 2
+	dx := (cx - r) / r
+	dy := (r - cy) / r
+	s := math32.Sqrt(dx*dx + dy*dy)
+	if s > 1 {
+		s = 1
+	}
+	h := math32.Atan2(dy, dx) / (2 * math32.Pi)
+	if h < 0 {
+		h += 1
+	}
+	cd.setHSV(h, s, cd.val)
+}
+
+// onEdit receives OnChange events from the RGBA edits
+func (cd *ColorDialog) onEdit(evname string, ev interface{}) {
+
+	if cd.updating {
+		return
+	}
+	var comps [4]float32
+	for i, ed := range cd.edits {
+		v, err := strconv.Atoi(strings.TrimSpace(ed.Text()))
+		if err != nil || v < 0 || v > 255 {
+			return
+		}
+		comps[i] = float32(v) / 255
+	}
+	var except IPanel
+	for _, ed := range cd.edits {
+		if Manager().keyFocus == ed {
+			except = ed
+		}
+	}
+	cd.setColor(&math32.Color4{comps[0], comps[1], comps[2], comps[3]}, except)
+}
+
+// onHex receives OnChange events from the hexadecimal edit
+func (cd *ColorDialog) onHex(evname string, ev interface{}) {
+
+	if cd.updating {
+		return
+	}
+	c, ok := hexToColor(cd.hex.Text())
+	if !ok {
+		return
+	}
+	cd.setColor(&c, cd.hex)
+}
+
+// drawWheel draws the HSV wheel image for the current value if necessary
+// and positions the marker of the current hue and saturation.
+func (cd *ColorDialog) drawWheel() {
+
+	r := float32(colorWheelSize) / 2
+	if cd.wheelVal != cd.val {
+		cd.wheelVal = cd.val
+		for py := 0; py < colorWheelSize; py++ {
+			for px := 0; px < colorWheelSize; px++ {
+				dx := (float32(px) + 0.5 - r) / r
+				dy := (r - float32(py) - 0.5) / r
+				dist := math32.Sqrt(dx*dx + dy*dy)
+				// Antialiased border
+				alpha := math32.Clamp((1-dist)*r+0.5, 0, 1)
+				if alpha == 0 {
+					cd.wheelRGB.SetRGBA(px, py, color.RGBA{})
+					continue
+				}
+				h := math32.Atan2(dy, dx) / (2 * math32.Pi)
+				if h < 0 {
+					h += 1
+				}
+				red, green, blue := hsvToRGB(h, math32.Min(dist, 1), cd.val)
+				cd.wheelRGB.SetRGBA(px, py, color.RGBA{
+					R: uint8(red * alpha * 255),
+					G: uint8(green * alpha * 255),
+					B: uint8(blue * alpha * 255),
+					A: uint8(alpha * 255),
+				})
+			}
+		}
+		cd.wheelTex.SetFromRGBA(cd.wheelRGB)
+	}
+	angle := cd.hue * 2 * math32.Pi
+	mx := r + math32.Cos(angle)*cd.sat*r
+	my := r - math32.Sin(angle)*cd.sat*r
+	cd.marker.SetPosition(mx-cd.marker.Width()/2, my-cd.marker.Height()/2)
+}
+
+// recalc recalculates the positions and sizes of the dialog panels
+func (cd *ColorDialog) recalc() {
+
+	width := cd.client.ContentWidth()
+	x := float32(dialogSpacing)
+	y := float32(dialogSpacing)
+
+	// Wheel and sliders on the left
+	cd.wheel.SetPosition(x, y)
+	y += cd.wheel.Height() + dialogSpacing
+	cd.sValue.SetPosition(x, y)
+	y += cd.sValue.Height() + dialogSpacing
+	cd.sAlpha.SetPosition(x, y)
+
+	// Preview and edits on the right
+	x += cd.wheel.Width() + 3*dialogSpacing
+	y = dialogSpacing
+	cd.preview.SetPosition(x, y)
+	cd.preview.SetWidth(width - x - dialogSpacing)
+	y += cd.preview.Height() + 2*dialogSpacing
+	col := (width - x) / 2
+	for i, ed := range cd.edits {
+		ex := x + float32(i%2)*col
+		ey := y + float32(i/2)*(ed.Height()+dialogSpacing)
+		l := cd.labels[i]
+		l.SetPosition(ex, ey+(ed.Height()-l.Height())/2)
+		ed.SetPosition(ex+20, ey)
+	}
+	y += 2 * (cd.hex.Height() + dialogSpacing)
+	cd.lhex.SetPosition(x, y+(cd.hex.Height()-cd.lhex.Height())/2)
+	cd.hex.SetPosition(x+cd.lhex.Width()+dialogSpacing, y)
+}
+
+// colorToHex returns the hexadecimal representation (#RRGGBBAA) of the specified color
+func colorToHex(c *math32.Color4) string {
+
+	comp := func(v float32) int { return int(math32.Clamp(v, 0, 1)*255 + 0.5) }
+	return fmt.Sprintf("#%02X%02X%02X%02X", comp(c.R), comp(c.G), comp(c.B), comp(c.A))
+}
+
+// hexToColor parses a color in the #RRGGBB or #RRGGBBAA hexadecimal formats.
+// The leading '#' is optional.
+func hexToColor(s string) (math32.Color4, bool) {
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) != 6 && len(s) != 8 {
+		return math32.Color4{}, false
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return math32.Color4{}, false
+	}
+	if len(s) == 6 {
+		v = v<<8 | 0xFF
+	}
+	return math32.Color4{
+		R: float32(v>>24&0xFF) / 255,
+		G: float32(v>>16&0xFF) / 255,
+		B: float32(v>>8&0xFF) / 255,
+		A: float32(v&0xFF) / 255,
+	}, true
+}
+
+// hsvToRGB converts the specified hue, saturation and value (0 to 1) to RGB components
+func hsvToRGB(h, s, v float32) (float32, float32, float32) {
+
+	h = (h - math32.Floor(h)) * 6
+	i := int(h)
+	f := h - float32(i)
+	p := v * (1 - s)
+	q := v * (1 - s*f)
+	t := v * (1 - s*(1-f))
+	switch i {
+	case 0:
+		return v, t, p
+	case 1:
+		return q, v, p
+	case 2:
+		return p, v, t
+	case 3:
+		return p, q, v
+	case 4:
+		return t, p, v
+	default:
+		return v, p, q
+	}
+}
+
+// rgbToHSV converts the specified RGB components to hue, saturation and value (0 to 1)
+func rgbToHSV(r, g, b float32) (float32, float32, float32) {
+
+	max := math32.Max(r, math32.Max(g, b))
+	min := math32.Min(r, math32.Min(g, b))
+	d := max - min
+	var h, s float32
+	if max > 0 {
+		s = d / max
+	}
+	if d > 0 {
+		switch max {
+		case r:
+			h = (g - b) / d
+			if h < 0 {
+				h += 6
+			}
+		case g:
+			h = (b-r)/d + 2
+		default:
+			h = (r-g)/d + 4
+		}
+		h /= 6
+	}
+	return h, s, max
+}
@@ -0,0 +1,149 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"github.com/g3n/engine/window"
+)
+
+// Dialog is a modal window with accept and cancel buttons.
+// It is the base of the standard file and color dialogs.
+type Dialog struct {
+	Window               // Embedded window
+	bAccept  *Button     // Accept button
+	bCancel  *Button     // Cancel button
+	validate func() bool // Called before accepting the dialog (may be nil)
+	focus    IPanel      // Panel which receives the key focus when the dialog is opened (may be nil)
+	open     bool        // Dialog is currently shown
+}
+
+// Dialog events
+const (
+	OnDialogAccept = "gui.OnDialogAccept" // Dispatched when the dialog is closed with the accept button or the Enter key
+	OnDialogCancel = "gui.OnDialogCancel" // Dispatched when the dialog is closed with the cancel button or the Escape key
+)
+
+// dialogZLayer is the Z-layer of open dialogs, below popovers and tooltips
+const dialogZLayer = overlayZLayer / 2
+
+// dialogSpacing is the spacing in pixels between the internal panels of the standard dialogs
+const dialogSpacing = 6
+
+// NewDialog creates and returns a pointer to a new empty dialog with the
+// specified dimensions, title and accept button text.
+// Panels added to the dialog must be placed above ButtonsY.
+func NewDialog(width, height float32, title, accept string) *Dialog {
+
+	d := new(Dialog)
+	d.initialize(width, height, title, accept)
+	return d
+}
+
+// initialize initializes the dialog with the specified dimensions, title and accept button text.
+// It is normally used when the dialog is embedded in another object.
+func (d *Dialog) initialize(width, height float32, title, accept string) {
+
+	d.Window.initialize(width, height)
+	d.Window.SetTitle(title)
+	d.Window.SetCloseButton(false)
+	d.zLayerDelta = dialogZLayer
+	d.SetVisible(false)
+
+	d.bAccept = NewButton(accept)
+	d.bAccept.Subscribe(OnClick, func(evname string, ev interface{}) { d.Accept() })
+	d.Window.Add(d.bAccept)
+	d.bCancel = NewButton("Cancel")
+	d.bCancel.Subscribe(OnClick, func(evname string, ev interface{}) { d.Cancel() })
+	d.Window.Add(d.bCancel)
+
+	d.Subscribe(OnKeyDown, d.onKey)
+	d.client.Subscribe(OnResize, func(evname string, ev interface{}) { d.recalcButtons() })
+	d.recalcButtons()
+}
+
+// Open shows the dialog centered in the application window and makes it
+// the modal panel of the GUI manager until it is closed.
+func (d *Dialog) Open() {
+
+	m := Manager()
+	m.addOverlay(d)
+	width, height := m.win.GetSize()
+	d.SetPosition((float32(width)-d.Width())/2, (float32(height)-d.Height())/2)
+	d.open = true
+	d.SetVisible(true)
+	m.SetModal(d)
+	if d.focus != nil {
+		m.SetKeyFocus(d.focus)
+	} else {
+		m.SetKeyFocus(d)
+	}
+}
+
+// Close hides the dialog without dispatching any event.
+func (d *Dialog) Close() {
+
+	if !d.open {
+		return
+	}
+	d.open = false
+	d.SetVisible(false)
+	m := Manager()
+	if m.modal == IPanel(d) {
+		m.SetModal(nil)
+	}
+}
+
+// IsOpen returns whether the dialog is currently shown.
+func (d *Dialog) IsOpen() bool {
+
+	return d.open
+}
+
+// Accept closes the dialog and dispatches OnDialogAccept if its contents are valid.
+func (d *Dialog) Accept() {
+
+	if d.validate != nil && !d.validate() {
+		return
+	}
+	d.Close()
+	d.Dispatch(OnDialogAccept, nil)
+}
+
+// Cancel closes the dialog and dispatches OnDialogCancel.
+func (d *Dialog) Cancel() {
+
+	d.Close()
+	d.Dispatch(OnDialogCancel, nil)
+}
+
+// ButtonsY returns the vertical position in the dialog client area
+// of the accept and cancel buttons.
+func (d *Dialog) ButtonsY() float32 {
+
+	return d.bAccept.Position().Y
+}
+
+// onKey receives key events when the dialog or one of its edits has the key focus.
+func (d *Dialog) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	switch kev.Key {
+	case window.KeyEnter, window.KeyKPEnter:
+		d.Accept()
+	case window.KeyEscape:
+		d.Cancel()
+	}
+}
+
+// recalcButtons places the accept and cancel buttons at the bottom right of the client area.
+func (d *Dialog) recalcButtons() {
+
+	width := d.client.ContentWidth()
+	height := d.client.ContentHeight()
+	x := width - d.bCancel.Width() - dialogSpacing
+	y := height - d.bCancel.Height() - dialogSpacing
+	d.bCancel.SetPosition(x, y)
+	d.bAccept.SetPosition(x-d.bAccept.Width()-dialogSpacing, y)
+}
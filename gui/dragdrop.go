@@ -0,0 +1,286 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+// DragData is the payload carried by a drag and drop operation.
+type DragData struct {
+	Type    string      // Payload type, used by drop targets to accept or ignore the drag
+	Value   interface{} // Payload value
+	Preview IPanel      // Panel shown next to the cursor while dragging (may be nil)
+}
+
+// DragEvent is the event dispatched to drag sources and drop targets.
+type DragEvent struct {
+	Data    *DragData // Payload of the drag
+	Source  IPanel    // Panel where the drag started
+	Target  IPanel    // Drop target under the cursor or nil if the cursor is not over a drop target
+	Xpos    float32   // Cursor horizontal position in window coordinates
+	Ypos    float32   // Cursor vertical position in window coordinates
+	Accept  bool      // Whether the target accepts the drop. Can be cleared by OnDragEnter/OnDragOver subscribers
+	Dropped bool      // Whether the payload was dropped (OnDragEnd only)
+}
+
+// DragSourceFunc is the type of the function called when a drag starts over a drag source panel.
+// It receives the cursor position in window coordinates and returns the payload
+// of the drag or nil to not start the drag.
+type DragSourceFunc func(x, y float32) *DragData
+
+// Drag and drop events
+const (
+	OnDragStart = "gui.OnDragStart" // Dispatched to the drag source when a drag starts
+	OnDragEnter = "gui.OnDragEnter" // Dispatched to a drop target when the cursor enters it while dragging
+	OnDragOver  = "gui.OnDragOver"  // Dispatched to the drop target under the cursor at each cursor movement
+	OnDragLeave = "gui.OnDragLeave" // Dispatched to a drop target when the cursor leaves it or the drag finishes
+	OnDrop      = "gui.OnDrop"      // Dispatched to the drop target when the payload is dropped on it
+	OnDragEnd   = "gui.OnDragEnd"   // Dispatched to the drag source when the drag finishes or is cancelled
+)
+
+// DragAnyType can be passed to SetDropTypes to accept payloads of any type.
+const DragAnyType = "*"
+
+// DragThreshold is the distance in pixels the cursor must move with
+// the left mouse button pressed before a drag is started.
+const DragThreshold = 4
+
+// dragPreviewOffset is the offset of the drag preview from the cursor position
+const dragPreviewOffset = 12
+
+// dragState keeps the state of the drag and drop operation being done by the manager.
+type dragState struct {
+	source IPanel    // Drag source panel
+	startX float32   // Cursor position when the left button was pressed
+	startY float32   // Cursor position when the left button was pressed
+	active bool      // Drag was started (the cursor moved more than DragThreshold)
+	data   *DragData // Payload of the drag
+	target IPanel    // Current drop target (may be nil)
+	accept bool      // Whether the current drop target accepts the payload
+}
+
+// SetDragSource sets the function called when the user starts dragging this panel
+// or one of its descendants without its own drag source.
+// Passing nil makes this panel no longer a drag source.
+func (p *Panel) SetDragSource(f DragSourceFunc) {
+
+	p.dragSource = f
+}
+
+// DragSource returns the drag source function of this panel or nil if none.
+func (p *Panel) DragSource() DragSourceFunc {
+
+	return p.dragSource
+}
+
+// SetDropTypes sets the payload types this panel accepts as a drop target.
+// DragAnyType accepts payloads of any type.
+// Calling it without types makes this panel no longer a drop target.
+func (p *Panel) SetDropTypes(types ...string) {
+
+	p.dropTypes = types
+}
+
+// DropTypes returns the payload types this panel accepts as a drop target.
+func (p *Panel) DropTypes() []string {
+
+	return p.dropTypes
+}
+
+// AcceptsDropType returns whether this panel is a drop target for payloads of the specified type.
+func (p *Panel) AcceptsDropType(dtype string) bool {
+
+	for _, t := range p.dropTypes {
+		if t == dtype || t == DragAnyType {
+			return true
+		}
+	}
+	return false
+}
+
+// Dragging returns whether a drag and drop operation is in progress.
+func (gm *manager) Dragging() bool {
+
+	return gm.drag != nil && gm.drag.active
+}
+
+// CancelDrag cancels the drag and drop operation in progress, if any.
+// The drag is also cancelled when the Escape key is pressed while dragging.
+func (gm *manager) CancelDrag() {
+
+	if gm.drag == nil {
+		return
+	}
+	if gm.drag.active {
+		gm.endDrag(false)
+	}
+	gm.drag = nil
+}
+
+// beginDrag records the drag source under the cursor when the left mouse button is pressed.
+// The drag only starts when the cursor moves more than DragThreshold.
+func (gm *manager) beginDrag(x, y float32) {
+
+	gm.drag = nil
+	if gm.target == nil || gm.cursorFocus != nil {
+		return
+	}
+	src := dragSourceOf(gm.target)
+	if src == nil || (gm.modal != nil && !gm.modal.IsAncestorOf(src)) {
+		return
+	}
+	gm.drag = &dragState{source: src, startX: x, startY: y}
+}
+
+// updateDrag starts the pending drag if the cursor moved enough and
+// updates the drop target and the preview of the drag in progress.
+func (gm *manager) updateDrag(x, y float32) {
+
+	d := gm.drag
+	if !d.active {
+		dx := x - d.startX
+		dy := y - d.startY
+		if dx*dx+dy*dy < DragThreshold*DragThreshold {
+			return
+		}
+		d.data = d.source.GetPanel().dragSource(d.startX, d.startY)
+		if d.data == nil {
+			gm.drag = nil
+			return
+		}
+		d.active = true
+		d.accept = true
+		gm.hideTooltip()
+		d.source.Dispatch(OnDragStart, gm.dragEvent(x, y))
+		if d.data.Preview != nil {
+			if gm.dragPreview == nil {
+				gm.dragPreview = NewPopover(nil)
+				gm.dragPreview.SetAutoClose(false)
+				gm.dragPreview.ApplyStyle(&PanelStyle{})
+			}
+			gm.dragPreview.SetContent(d.data.Preview)
+		}
+	}
+	if d.data.Preview != nil {
+		gm.dragPreview.ShowAt(x+dragPreviewOffset, y+dragPreviewOffset)
+	}
+
+	// Find the drop target under the cursor
+	var target IPanel
+	if gm.target != nil && (gm.modal == nil || gm.modal.IsAncestorOf(gm.target)) {
+		target = dropTargetOf(gm.target, d.data.Type)
+	}
+	if target != d.target {
+		if d.target != nil {
+			d.target.Dispatch(OnDragLeave, gm.dragEvent(x, y))
+		}
+		d.target = target
+		d.accept = true
+		if target != nil {
+			ev := gm.dragEvent(x, y)
+			target.Dispatch(OnDragEnter, ev)
+			d.accept = ev.Accept
+		}
+	}
+
+	// Dispatch OnDragOver to the drop target or to non-GUI if the cursor is not over the GUI
+	ev := gm.dragEvent(x, y)
+	if target != nil {
+		target.Dispatch(OnDragOver, ev)
+		d.accept = ev.Accept
+	} else if gm.target == nil && gm.modal == nil {
+		gm.Dispatch(OnDragOver, ev)
+		d.accept = ev.Accept
+	}
+}
+
+// finishDrag drops the payload of the drag in progress at the specified position.
+// If the cursor is not over the GUI the drop is dispatched to non-GUI,
+// which allows dropping payloads in the 3D scene.
+func (gm *manager) finishDrag(x, y float32) {
+
+	d := gm.drag
+	if !d.active {
+		gm.drag = nil
+		return
+	}
+	dropped := false
+	if d.accept {
+		ev := gm.dragEvent(x, y)
+		if d.target != nil {
+			dropped = d.target.Dispatch(OnDrop, ev) > 0 && ev.Accept
+		} else if gm.target == nil && gm.modal == nil {
+			dropped = gm.Dispatch(OnDrop, ev) > 0 && ev.Accept
+		}
+	}
+	gm.endDrag(dropped)
+	gm.drag = nil
+}
+
+// endDrag notifies the current drop target and the drag source that the drag finished
+// and hides the drag preview.
+func (gm *manager) endDrag(dropped bool) {
+
+	d := gm.drag
+	var x, y float32
+	if gm.cev != nil {
+		x = gm.cev.Xpos
+		y = gm.cev.Ypos
+	}
+	if d.target != nil {
+		d.target.Dispatch(OnDragLeave, gm.dragEvent(x, y))
+	}
+	if gm.dragPreview != nil {
+		gm.dragPreview.Close()
+		gm.dragPreview.SetContent(nil)
+	}
+	ev := gm.dragEvent(x, y)
+	ev.Dropped = dropped
+	d.source.Dispatch(OnDragEnd, ev)
+}
+
+// dragEvent returns a new DragEvent for the drag in progress at the specified position.
+func (gm *manager) dragEvent(x, y float32) *DragEvent {
+
+	d := gm.drag
+	return &DragEvent{
+		Data:   d.data,
+		Source: d.source,
+		Target: d.target,
+		Xpos:   x,
+		Ypos:   y,
+		Accept: d.accept,
+	}
+}
+
+// dragSourceOf returns the specified panel or its closest ancestor which is a drag source.
+func dragSourceOf(ipan IPanel) IPanel {
+
+	var ok bool
+	for ipan != nil {
+		if ipan.GetPanel().dragSource != nil {
+			return ipan
+		}
+		ipan, ok = ipan.Parent().(IPanel)
+		if !ok {
+			break
+		}
+	}
+	return nil
+}
+
+// dropTargetOf returns the specified panel or its closest ancestor
+// which accepts payloads of the specified type.
+func dropTargetOf(ipan IPanel, dtype string) IPanel {
+
+	var ok bool
+	for ipan != nil {
+		if ipan.GetPanel().AcceptsDropType(dtype) {
+			return ipan
+		}
+		ipan, ok = ipan.Parent().(IPanel)
+		if !ok {
+			break
+		}
+	}
+	return nil
+}
@@ -40,6 +40,8 @@ func NewDropDown(width float32, item *ImageLabel) *DropDown {
 	dd.litem = item
 
 	dd.Panel.Initialize(dd, width, 0)
+	dd.Panel.SetFocusable(true)
+	dd.Panel.SetAccessibleRole(RoleComboBox)
 	dd.Panel.Subscribe(OnMouseDown, dd.onMouse)
 	dd.Panel.Subscribe(OnCursorEnter, dd.onCursor)
 	dd.Panel.Subscribe(OnCursorLeave, dd.onCursor)
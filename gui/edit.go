@@ -20,6 +20,8 @@ type Edit struct {
 	placeHolder string // place holder string
 	text        string // current edit text
 	col         int    // current column
+	composition string // IME composition text shown at the caret
+	compCursor  int    // byte offset of the caret in the composition text
 	focus       bool   // key focus flag
 	cursorOver  bool
 	blinkID     int
@@ -65,13 +67,20 @@ func NewEdit(width int, placeHolder string) *Edit {
 	ed.focus = false
 
 	ed.Label.initialize("", StyleDefault().Font)
+	ed.Label.SetFocusable(true)
+	ed.Label.SetAccessibleRole(RoleTextBox)
+	ed.Label.SetAccessibleName(placeHolder)
 	ed.Label.Subscribe(OnKeyDown, ed.onKey)
 	ed.Label.Subscribe(OnKeyRepeat, ed.onKey)
 	ed.Label.Subscribe(OnChar, ed.onChar)
+	ed.Label.Subscribe(OnCompositionStart, ed.onComposition)
+	ed.Label.Subscribe(OnCompositionUpdate, ed.onComposition)
+	ed.Label.Subscribe(OnCompositionEnd, ed.onComposition)
 	ed.Label.Subscribe(OnMouseDown, ed.onMouse)
 	ed.Label.Subscribe(OnCursorEnter, ed.onCursor)
 	ed.Label.Subscribe(OnCursorLeave, ed.onCursor)
 	ed.Label.Subscribe(OnEnable, func(evname string, ev interface{}) { ed.update() })
+	ed.Subscribe(OnFocus, ed.onFocus)
 	ed.Subscribe(OnFocusLost, ed.OnFocusLost)
 
 	ed.update()
@@ -113,8 +122,28 @@ func (ed *Edit) SetStyles(es *EditStyles) {
 func (ed *Edit) OnFocusLost(evname string, ev interface{}) {
 
 	ed.focus = false
+	ed.composition = ""
 	ed.update()
 	Manager().ClearTimeout(ed.blinkID)
+	if w, ok := window.Get().(window.IIMEWindow); ok {
+		w.SetIMEEnabled(false)
+	}
+}
+
+// onFocus is called when the edit receives the key focus
+// and starts blinking the cursor
+func (ed *Edit) onFocus(evname string, ev interface{}) {
+
+	if ed.focus {
+		return
+	}
+	ed.focus = true
+	ed.blinkID = Manager().SetInterval(750*time.Millisecond, nil, ed.blink)
+	if w, ok := window.Get().(window.IIMEWindow); ok {
+		w.SetIMEEnabled(true)
+	}
+	ed.update()
+	ed.redraw(ed.focus)
 }
 
 // CursorPos sets the position of the cursor at the
@@ -127,7 +156,8 @@ func (ed *Edit) CursorPos(col int) {
 	}
 }
 
-// CursorLeft moves the edit cursor one character left if possible
+// CursorLeft moves the edit cursor one char left if possible.
+// A char is a user perceived char which may be composed of several runes.
 func (ed *Edit) CursorLeft() {
 
 	if ed.col > 0 {
@@ -201,8 +231,9 @@ func (ed *Edit) CursorInput(s string) {
 		return
 	}
 
+	// Combining chars may join the char before the cursor
+	ed.col = text.StrCount(text.StrPrefix(ed.text, ed.col) + s)
 	ed.text = newText
-	ed.col++
 
 	ed.Dispatch(OnChange, nil)
 	ed.redraw(ed.focus)
@@ -215,7 +246,24 @@ func (ed *Edit) redraw(caret bool) {
 	if !caret {
 		line = -1
 	}
-	ed.Label.setTextCaret(ed.text, editMarginX, ed.width, line, ed.col)
+
+	// Shows the IME composition text underlined at the caret
+	msg := ed.text
+	prefix := text.StrPrefix(ed.text, ed.col)
+	offset := len(prefix)
+	col := ed.col
+	if ed.composition != "" {
+		msg = prefix + ed.composition + ed.text[len(prefix):]
+		offset += ed.compCursor
+		col = text.StrCount(msg[:offset])
+	}
+	ed.Label.setTextCaretMarked(msg, editMarginX, ed.width, line, col, len(prefix), len(prefix)+len(ed.composition))
+
+	// Places the IME candidate window at the caret if the window supports the IME composition
+	if w, ok := window.Get().(window.IIMEWindow); ok && ed.focus {
+		x := ed.pospix.X + editMarginX + ed.Label.font.CaretX(msg, offset)
+		w.SetIMERect(int(x), int(ed.pospix.Y), 1, int(ed.Height()))
+	}
 }
 
 // onKey receives subscribed key events
@@ -247,6 +295,22 @@ func (ed *Edit) onChar(evname string, ev interface{}) {
 	ed.CursorInput(string(cev.Char))
 }
 
+// onComposition receives subscribed IME composition events
+func (ed *Edit) onComposition(evname string, ev interface{}) {
+
+	cev := ev.(*window.CompositionEvent)
+	switch evname {
+	case OnCompositionUpdate:
+		ed.composition = cev.Text
+		ed.compCursor = cev.Cursor
+	default:
+		// The committed text is received as char events
+		ed.composition = ""
+		ed.compCursor = 0
+	}
+	ed.redraw(ed.focus)
+}
+
 // onMouseEvent receives subscribed mouse down events
 func (ed *Edit) onMouse(evname string, ev interface{}) {
 
@@ -258,20 +322,9 @@ func (ed *Edit) onMouse(evname string, ev interface{}) {
 	// Set key focus to this panel
 	Manager().SetKeyFocus(ed)
 
-	// Find clicked column
-	var nchars int
-	for nchars = 1; nchars <= text.StrCount(ed.text); nchars++ {
-		width, _ := ed.Label.font.MeasureText(text.StrPrefix(ed.text, nchars))
-		posx := e.Xpos - ed.pospix.X
-		if posx < editMarginX+float32(width) {
-			break
-		}
-	}
-	if !ed.focus {
-		ed.focus = true
-		ed.blinkID = Manager().SetInterval(750*time.Millisecond, nil, ed.blink)
-	}
-	ed.CursorPos(nchars - 1)
+	// Find clicked column from the nearest caret position of the shaped text
+	offset := ed.Label.font.CaretOffset(ed.text, e.Xpos-ed.pospix.X-editMarginX)
+	ed.CursorPos(text.StrCount(ed.text[:offset]))
 }
 
 // onCursor receives subscribed cursor events
@@ -37,6 +37,11 @@ const (
 	OnKeyUp     = window.OnKeyUp     // A key is released
 	OnKeyRepeat = window.OnKeyRepeat // A key was pressed and is now automatically repeating
 	OnChar      = window.OnChar      // A unicode key is pressed
+
+	// Input method editor (IME) events sent to the key-focused IDispatcher
+	OnCompositionStart  = window.OnCompositionStart  // IME composition started
+	OnCompositionUpdate = window.OnCompositionUpdate // IME composition text changed
+	OnCompositionEnd    = window.OnCompositionEnd    // IME composition committed or canceled
 )
 
 const (
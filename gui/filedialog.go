@@ -0,0 +1,470 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/g3n/engine/gui/assets/icon"
+	"github.com/g3n/engine/window"
+)
+
+/***************************************
+
+ FileDialog
+ +-------------------------------------+
+ |               Title                 |
+ +-------------------------------------+
+ | [^] [Location / recent locations v] |
+ | +---------------------------------+ |
+ | | Directory entries               | |
+ | |                                 | |
+ | +---------------------------------+ |
+ | Name: [file name      ] [Filter  v] |
+ | status          [Open] [Cancel]     |
+ +-------------------------------------+
+
+****************************************/
+
+// FileDialog is a modal dialog to choose a file to open or save.
+// OnDialogAccept is dispatched when a file is chosen and its path
+// can then be obtained with Path().
+type FileDialog struct {
+	Dialog                  // Embedded dialog
+	mode     FileDialogMode // Open or save mode
+	dir      string         // Current directory
+	path     string         // Chosen file path
+	filters  []FileFilter   // Extension filters
+	filter   int            // Current filter
+	bUp      *Button        // Parent directory button
+	location *DropDown      // Current directory and recent locations
+	list     *List          // Entries of the current directory
+	lname    *Label         // File name label
+	name     *Edit          // File name edit
+	ddFilter *DropDown      // Extension filter selector
+	status   *Label         // Status message
+	lastSel  IPanel         // Last selected list item
+	lastTime time.Time      // Time of the last list selection
+}
+
+// FileDialogMode specifies whether a FileDialog chooses a file to open or to save.
+type FileDialogMode int
+
+// File dialog modes
+const (
+	FileOpen = FileDialogMode(iota) // Chooses an existing file
+	FileSave                        // Chooses an existing or new file
+)
+
+// FileFilter selects the files shown by a FileDialog by their extensions.
+type FileFilter struct {
+	Name       string   // Name shown in the filter selector
+	Extensions []string // Extensions including the dot (e.g. ".png"). If empty all files are shown
+}
+
+// fileEntry is the user data of the file dialog list items
+type fileEntry struct {
+	name string
+	dir  bool
+}
+
+// MaxRecentLocations is the maximum number of directories remembered as recent locations.
+var MaxRecentLocations = 8
+
+// recentLocations contains the directories recently used by file dialogs, most recent first
+var recentLocations []string
+
+// doubleClickTime is the maximum time between two selections of the same item to open it
+const doubleClickTime = 500 * time.Millisecond
+
+// NewFileDialog creates and returns a pointer to a new file dialog
+// with the specified mode and title, showing the current working directory.
+func NewFileDialog(mode FileDialogMode, title string) *FileDialog {
+
+	const width = 480
+	const height = 360
+
+	fd := new(FileDialog)
+	accept := "Open"
+	if mode == FileSave {
+		accept = "Save"
+	}
+	fd.Dialog.initialize(width, height, title, accept)
+	fd.mode = mode
+	fd.validate = fd.onAccept
+
+	// Parent directory button and location selector
+	fd.bUp = NewButton("")
+	fd.bUp.SetIcon(icon.ArrowUpward)
+	fd.bUp.SetTooltip("Parent directory")
+	fd.bUp.Subscribe(OnClick, func(evname string, ev interface{}) {
+		fd.SetDir(filepath.Dir(fd.dir))
+	})
+	fd.Add(fd.bUp)
+	fd.location = NewDropDown(0, NewImageLabel(""))
+	fd.location.Subscribe(OnChange, fd.onLocation)
+	fd.Add(fd.location)
+
+	// List of directory entries
+	fd.list = NewVList(0, 0)
+	fd.list.Subscribe(OnChange, fd.onSelect)
+	fd.list.Subscribe(OnKeyDown, fd.onListKey)
+	fd.Add(fd.list)
+
+	// File name and filter selector
+	fd.lname = NewLabel("Name:")
+	fd.Add(fd.lname)
+	fd.ddFilter = NewDropDown(140, NewImageLabel(""))
+	fd.ddFilter.SetVisible(false)
+	fd.ddFilter.Subscribe(OnChange, func(evname string, ev interface{}) {
+		if pos := fd.ddFilter.SelectedPos(); pos >= 0 && pos != fd.filter {
+			fd.filter = pos
+			fd.refresh()
+		}
+	})
+	fd.Add(fd.ddFilter)
+	fd.name = NewEdit(int(fd.client.ContentWidth()-fd.ddFilter.Width()-fd.lname.Width()-4*dialogSpacing), "")
+	fd.name.Subscribe(OnKeyDown, fd.onKey)
+	fd.Add(fd.name)
+
+	// Status message
+	fd.status = NewLabel("")
+	fd.Add(fd.status)
+
+	fd.recalc()
+	if mode == FileSave {
+		fd.focus = fd.name
+	} else {
+		fd.focus = fd.list
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		dir = "."
+	}
+	fd.SetDir(dir)
+	return fd
+}
+
+// Mode returns the mode of this file dialog.
+func (fd *FileDialog) Mode() FileDialogMode {
+
+	return fd.mode
+}
+
+// SetDir sets the directory shown by the dialog.
+// If the directory cannot be read the previous one is kept and the error returned.
+func (fd *FileDialog) SetDir(dir string) error {
+
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		fd.status.SetText(err.Error())
+		return err
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		fd.status.SetText(err.Error())
+		return err
+	}
+	fd.dir = dir
+	fd.status.SetText("")
+	fd.updateLocation()
+	fd.fill(infos)
+	return nil
+}
+
+// Dir returns the directory shown by the dialog.
+func (fd *FileDialog) Dir() string {
+
+	return fd.dir
+}
+
+// SetFileName sets the file name shown in the name edit.
+func (fd *FileDialog) SetFileName(name string) {
+
+	fd.name.SetText(name)
+}
+
+// Path returns the path of the chosen file.
+// It is only valid after the dialog was accepted.
+func (fd *FileDialog) Path() string {
+
+	return fd.path
+}
+
+// SetFilters sets the extension filters the user can choose from.
+// The first filter is selected. Without filters all files are shown.
+func (fd *FileDialog) SetFilters(filters ...FileFilter) {
+
+	fd.filters = filters
+	fd.filter = 0
+	for fd.ddFilter.Len() > 0 {
+		fd.ddFilter.RemoveAt(fd.ddFilter.Len() - 1)
+	}
+	for _, f := range filters {
+		fd.ddFilter.Add(NewImageLabel(f.Name))
+	}
+	fd.ddFilter.SetVisible(len(filters) > 0)
+	if len(filters) > 0 {
+		fd.ddFilter.SetSelected(fd.ddFilter.ItemAt(0))
+	}
+	fd.refresh()
+}
+
+// Filters returns the extension filters of this dialog.
+func (fd *FileDialog) Filters() []FileFilter {
+
+	return fd.filters
+}
+
+// RecentLocations returns the directories recently used by file dialogs, most recent first.
+func RecentLocations() []string {
+
+	return recentLocations
+}
+
+// AddRecentLocation adds the specified directory to the start of the recent locations
+// shown by file dialogs, keeping at most MaxRecentLocations.
+func AddRecentLocation(dir string) {
+
+	locs := []string{dir}
+	for _, loc := range recentLocations {
+		if loc != dir && len(locs) < MaxRecentLocations {
+			locs = append(locs, loc)
+		}
+	}
+	recentLocations = locs
+}
+
+// onAccept validates the chosen file name before the dialog is accepted.
+// Choosing a directory opens it instead.
+func (fd *FileDialog) onAccept() bool {
+
+	name := strings.TrimSpace(fd.name.Text())
+	if name == "" {
+		if entry := fd.selectedEntry(); entry != nil && entry.dir {
+			fd.SetDir(filepath.Join(fd.dir, entry.name))
+		}
+		return false
+	}
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(fd.dir, name)
+	}
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		if fd.SetDir(path) == nil {
+			fd.name.SetText("")
+		}
+		return false
+	}
+	switch fd.mode {
+	case FileOpen:
+		if err != nil {
+			fd.status.SetText("File not found")
+			return false
+		}
+	case FileSave:
+		if filepath.Ext(path) == "" {
+			if exts := fd.extensions(); len(exts) > 0 {
+				path += exts[0]
+			}
+		}
+		if _, err := os.Stat(filepath.Dir(path)); err != nil {
+			fd.status.SetText("Directory not found")
+			return false
+		}
+	}
+	fd.path = path
+	AddRecentLocation(filepath.Dir(path))
+	return true
+}
+
+// onSelect is called when a directory entry is selected.
+// Files set the file name and entries selected twice in a short time are opened.
+func (fd *FileDialog) onSelect(evname string, ev interface{}) {
+
+	sel := fd.list.Selected()
+	if len(sel) == 0 {
+		return
+	}
+	item := sel[0]
+	entry := item.GetPanel().UserData().(*fileEntry)
+	now := time.Now()
+	double := item == fd.lastSel && now.Sub(fd.lastTime) < doubleClickTime
+	fd.lastSel = item
+	fd.lastTime = now
+	if !entry.dir {
+		fd.name.SetText(entry.name)
+	}
+	if double {
+		fd.openEntry(entry)
+	}
+}
+
+// onListKey opens the selected entry when Enter is pressed over the list
+func (fd *FileDialog) onListKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	switch kev.Key {
+	case window.KeyEnter, window.KeyKPEnter:
+		if entry := fd.selectedEntry(); entry != nil {
+			fd.openEntry(entry)
+		}
+	case window.KeyBackspace:
+		fd.SetDir(filepath.Dir(fd.dir))
+	case window.KeyEscape:
+		fd.Cancel()
+	}
+}
+
+// onLocation is called when a location is chosen in the location selector
+func (fd *FileDialog) onLocation(evname string, ev interface{}) {
+
+	sel := fd.location.Selected()
+	if sel != nil && sel.Text() != fd.dir {
+		fd.SetDir(sel.Text())
+	}
+}
+
+// openEntry opens the specified directory entry:
+// directories are shown and files accept the dialog
+func (fd *FileDialog) openEntry(entry *fileEntry) {
+
+	if entry.dir {
+		fd.SetDir(filepath.Join(fd.dir, entry.name))
+		return
+	}
+	fd.name.SetText(entry.name)
+	fd.Accept()
+}
+
+// selectedEntry returns the selected directory entry or nil if none
+func (fd *FileDialog) selectedEntry() *fileEntry {
+
+	sel := fd.list.Selected()
+	if len(sel) == 0 {
+		return nil
+	}
+	return sel[0].GetPanel().UserData().(*fileEntry)
+}
+
+// extensions returns the extensions of the current filter
+func (fd *FileDialog) extensions() []string {
+
+	if fd.filter < 0 || fd.filter >= len(fd.filters) {
+		return nil
+	}
+	return fd.filters[fd.filter].Extensions
+}
+
+// matches returns whether the specified file name passes the current filter
+func (fd *FileDialog) matches(name string) bool {
+
+	exts := fd.extensions()
+	if len(exts) == 0 {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, e := range exts {
+		if strings.ToLower(e) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// refresh reads the current directory again and updates the list
+func (fd *FileDialog) refresh() {
+
+	if fd.dir == "" {
+		return
+	}
+	infos, err := ioutil.ReadDir(fd.dir)
+	if err != nil {
+		fd.status.SetText(err.Error())
+		return
+	}
+	fd.fill(infos)
+}
+
+// fill fills the list with the specified directory entries.
+// Directories are shown first and hidden entries are skipped.
+func (fd *FileDialog) fill(infos []os.FileInfo) {
+
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].IsDir() && !infos[j].IsDir()
+	})
+	fd.list.Clear()
+	fd.lastSel = nil
+	for _, info := range infos {
+		name := info.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !info.IsDir() && !fd.matches(name) {
+			continue
+		}
+		item := NewImageLabel(name)
+		if info.IsDir() {
+			item.SetIcon(icon.Folder)
+		} else {
+			item.SetIcon(icon.InsertDriveFile)
+		}
+		item.SetUserData(&fileEntry{name: name, dir: info.IsDir()})
+		fd.list.Add(item)
+	}
+}
+
+// updateLocation updates the location selector with the
+// current directory followed by the recent locations
+func (fd *FileDialog) updateLocation() {
+
+	for fd.location.Len() > 0 {
+		fd.location.RemoveAt(fd.location.Len() - 1)
+	}
+	cur := NewImageLabel(fd.dir)
+	cur.SetIcon(icon.Folder)
+	fd.location.Add(cur)
+	for _, loc := range recentLocations {
+		if loc == fd.dir {
+			continue
+		}
+		item := NewImageLabel(loc)
+		item.SetIcon(icon.History)
+		fd.location.Add(item)
+	}
+	fd.location.SetSelected(cur)
+}
+
+// recalc recalculates the positions and sizes of the dialog panels
+func (fd *FileDialog) recalc() {
+
+	width := fd.client.ContentWidth()
+	x := float32(dialogSpacing)
+	y := float32(dialogSpacing)
+
+	// Parent directory button and location selector
+	fd.bUp.SetPosition(x, y)
+	fd.location.SetPosition(x+fd.bUp.Width()+dialogSpacing, y)
+	fd.location.SetWidth(width - fd.location.Position().X - dialogSpacing)
+	y += fd.bUp.Height() + dialogSpacing
+
+	// Name edit, filter selector and status message above the buttons
+	by := fd.ButtonsY()
+	fd.status.SetPosition(x, by+(fd.bAccept.Height()-fd.status.Height())/2)
+	ny := by - fd.name.Height() - dialogSpacing
+	fd.lname.SetPosition(x, ny+(fd.name.Height()-fd.lname.Height())/2)
+	fd.name.SetPosition(x+fd.lname.Width()+dialogSpacing, ny)
+	fd.ddFilter.SetPosition(width-fd.ddFilter.Width()-dialogSpacing, ny)
+
+	// List fills the remaining space
+	fd.list.SetPosition(x, y)
+	fd.list.SetSize(width-2*dialogSpacing, ny-y-dialogSpacing)
+}
@@ -0,0 +1,234 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"sort"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// AccessRole describes the kind of widget a panel is.
+// It is used by assistive technologies and automation tools.
+type AccessRole string
+
+// Accessible roles of the standard widgets
+const (
+	RoleNone       = AccessRole("")
+	RoleButton     = AccessRole("button")
+	RoleCheckBox   = AccessRole("checkbox")
+	RoleRadio      = AccessRole("radio")
+	RoleTextBox    = AccessRole("textbox")
+	RoleLabel      = AccessRole("label")
+	RoleList       = AccessRole("list")
+	RoleListItem   = AccessRole("listitem")
+	RoleTree       = AccessRole("tree")
+	RoleTreeItem   = AccessRole("treeitem")
+	RoleMenu       = AccessRole("menu")
+	RoleMenuBar    = AccessRole("menubar")
+	RoleMenuItem   = AccessRole("menuitem")
+	RoleTabList    = AccessRole("tablist")
+	RoleTab        = AccessRole("tab")
+	RoleSlider     = AccessRole("slider")
+	RoleComboBox   = AccessRole("combobox")
+	RoleTable      = AccessRole("table")
+	RoleDialog     = AccessRole("dialog")
+	RoleTooltip    = AccessRole("tooltip")
+	RoleScrollArea = AccessRole("scrollarea")
+)
+
+// FocusRingStyle contains the styling of the ring drawn around
+// the panel with key focus when it was focused using the keyboard.
+type FocusRingStyle struct {
+	Width  float32       // Width of the ring
+	Offset float32       // Distance between the panel borders and the ring
+	Color  math32.Color4 // Color of the ring
+}
+
+// SetFocusable sets whether this panel can receive key focus by keyboard navigation.
+func (p *Panel) SetFocusable(state bool) {
+
+	p.focusable = state
+}
+
+// Focusable returns whether this panel can receive key focus by keyboard navigation.
+func (p *Panel) Focusable() bool {
+
+	return p.focusable
+}
+
+// SetTabIndex sets the focus order of this panel for keyboard navigation.
+// Panels with positive indexes are focused first, in increasing index order,
+// followed by the panels with index 0 in the order they appear in the scene.
+// Panels with negative indexes are skipped by keyboard navigation.
+func (p *Panel) SetTabIndex(index int) {
+
+	p.tabIndex = index
+}
+
+// TabIndex returns the focus order of this panel for keyboard navigation.
+func (p *Panel) TabIndex() int {
+
+	return p.tabIndex
+}
+
+// SetAccessibleName sets the name which describes this panel to the user.
+func (p *Panel) SetAccessibleName(name string) {
+
+	p.accName = name
+}
+
+// AccessibleName returns the name which describes this panel to the user.
+func (p *Panel) AccessibleName() string {
+
+	return p.accName
+}
+
+// SetAccessibleRole sets the kind of widget this panel is.
+func (p *Panel) SetAccessibleRole(role AccessRole) {
+
+	p.accRole = role
+}
+
+// AccessibleRole returns the kind of widget this panel is.
+func (p *Panel) AccessibleRole() AccessRole {
+
+	return p.accRole
+}
+
+// SetFocusNavigation sets whether Tab and Shift-Tab move the key focus
+// between focusable panels. It is enabled by default.
+// The keys are only consumed while a panel has the key focus and there are
+// focusable panels; otherwise they are dispatched to the key focus or to non-GUI.
+func (gm *manager) SetFocusNavigation(state bool) {
+
+	gm.focusNav = state
+}
+
+// FocusNavigation returns whether Tab and Shift-Tab move the key focus between focusable panels.
+func (gm *manager) FocusNavigation() bool {
+
+	return gm.focusNav
+}
+
+// KeyFocus returns the IDispatcher which currently has the key focus or nil if none.
+func (gm *manager) KeyFocus() core.IDispatcher {
+
+	return gm.keyFocus
+}
+
+// FocusNext moves the key focus to the next focusable panel and shows the focus ring.
+func (gm *manager) FocusNext() {
+
+	gm.moveFocus(1)
+}
+
+// FocusPrev moves the key focus to the previous focusable panel and shows the focus ring.
+func (gm *manager) FocusPrev() {
+
+	gm.moveFocus(-1)
+}
+
+// moveFocus moves the key focus forward (dir > 0) or backwards (dir < 0) in the focus order.
+func (gm *manager) moveFocus(dir int) {
+
+	order := gm.focusOrder()
+	if len(order) == 0 {
+		return
+	}
+	// Find the current position in the focus order
+	cur := -1
+	for i, ipan := range order {
+		if gm.keyFocus == ipan {
+			cur = i
+			break
+		}
+	}
+	var next int
+	if cur < 0 {
+		if dir < 0 {
+			next = len(order) - 1
+		}
+	} else {
+		next = (cur + dir + len(order)) % len(order)
+	}
+	gm.SetKeyFocus(order[next])
+	gm.focusVisible = true
+	gm.updateFocusRing()
+}
+
+// panelHasKeyFocus returns whether a GUI panel has the key focus and
+// there are panels which can receive it by keyboard navigation.
+func (gm *manager) panelHasKeyFocus() bool {
+
+	if _, ok := gm.keyFocus.(IPanel); !ok {
+		return false
+	}
+	return len(gm.focusOrder()) > 0
+}
+
+// focusOrder returns the panels which can receive key focus by keyboard navigation
+// in focus order. If there is a modal panel only its descendants are returned.
+func (gm *manager) focusOrder() []IPanel {
+
+	order := make([]IPanel, 0)
+	add := func(ipan IPanel) {
+		p := ipan.GetPanel()
+		if p.focusable && p.tabIndex >= 0 {
+			order = append(order, ipan)
+		}
+	}
+	if gm.modal != nil {
+		traverseIPanel(gm.modal, add)
+	} else if gm.scene != nil {
+		gm.forEachIPanel(add)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		ti := order[i].GetPanel().tabIndex
+		tj := order[j].GetPanel().tabIndex
+		if ti == 0 || tj == 0 {
+			return ti != 0 && tj == 0
+		}
+		return ti < tj
+	})
+	return order
+}
+
+// updateFocusRing shows the focus ring around the panel with key focus
+// if it was focused by keyboard navigation or hides it otherwise.
+func (gm *manager) updateFocusRing() {
+
+	ipan, _ := gm.keyFocus.(IPanel)
+	if !gm.focusVisible || ipan == nil || !ipan.Visible() || gm.scene == nil {
+		if gm.focusRing != nil {
+			gm.focusRing.SetVisible(false)
+		}
+		return
+	}
+	style := &StyleDefault().FocusRing
+	if gm.focusRing == nil {
+		gm.focusRing = NewPanel(0, 0)
+		gm.focusRing.zLayerDelta = overlayZLayer
+	}
+	w := style.Width
+	gm.focusRing.ApplyStyle(&PanelStyle{
+		Border:      RectBounds{w, w, w, w},
+		BorderColor: style.Color,
+	})
+	gm.addOverlay(gm.focusRing)
+
+	// Place the ring around the panel borders
+	p := ipan.GetPanel()
+	d := style.Offset + w
+	pos := p.Pospix()
+	x := pos.X + p.marginSizes.Left - d
+	y := pos.Y + p.marginSizes.Top - d
+	width := p.Width() - p.marginSizes.Left - p.marginSizes.Right + 2*d
+	height := p.Height() - p.marginSizes.Top - p.marginSizes.Bottom + 2*d
+	gm.focusRing.SetPosition(x, y)
+	gm.focusRing.SetSize(width, height)
+	gm.focusRing.SetVisible(true)
+}
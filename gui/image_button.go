@@ -64,6 +64,8 @@ func NewImageButton(normalImgPath string) (*ImageButton, error) {
 	b.Panel.SetContentSize(b.image.Width(), b.image.Height())
 	b.Panel.SetBorders(5, 5, 5, 5)
 	b.Panel.Add(b.image)
+	b.Panel.SetFocusable(true)
+	b.Panel.SetAccessibleRole(RoleButton)
 
 	// Subscribe to panel events
 	b.Panel.Subscribe(OnKeyDown, b.onKey)
@@ -200,13 +202,16 @@ func (b *ImageButton) onMouse(evname string, ev interface{}) {
 func (b *ImageButton) onKey(evname string, ev interface{}) {
 
 	kev := ev.(*window.KeyEvent)
-	if evname == OnKeyDown && kev.Key == window.KeyEnter {
+	if kev.Key != window.KeyEnter && kev.Key != window.KeySpace {
+		return
+	}
+	if evname == OnKeyDown {
 		b.pressed = true
 		b.update()
 		b.Dispatch(OnClick, nil)
 		return
 	}
-	if evname == OnKeyUp && kev.Key == window.KeyEnter {
+	if evname == OnKeyUp {
 		b.pressed = false
 		b.update()
 		return
@@ -56,6 +56,7 @@ func (l *Label) initialize(msg string, font *text.Font) {
 
 	l.font = font
 	l.Panel.Initialize(l, 0, 0)
+	l.Panel.SetAccessibleRole(RoleLabel)
 	l.Panel.mat.SetTransparent(true)
 
 	// TODO: Remove this hack in an elegant way e.g. set the label style depending of if it's an icon or text label and have two defaults (one for icon labels one for text tabels)
@@ -215,6 +216,15 @@ func (l *Label) LineSpacing() float64 {
 // It is normally used by the Edit widget.
 func (l *Label) setTextCaret(msg string, mx, width, line, col int) {
 
+	l.setTextCaretMarked(msg, mx, width, line, col, 0, 0)
+}
+
+// setTextCaretMarked sets the label text and draws a caret at the
+// specified line and column, and underlines the first line of text
+// between the specified byte offsets.
+// It is used by the Edit widget to show the IME composition text.
+func (l *Label) setTextCaretMarked(msg string, mx, width, line, col, markStart, markEnd int) {
+
 	// Set font properties
 	l.font.SetAttributes(&l.style.FontAttributes)
 	l.font.SetColor(&l.style.FgColor)
@@ -223,6 +233,9 @@ func (l *Label) setTextCaret(msg string, mx, width, line, col int) {
 	_, height := l.font.MeasureText(msg)
 	canvas := text.NewCanvas(width, height, &l.style.BgColor)
 	canvas.DrawTextCaret(mx, 0, msg, l.font, line, col)
+	if markEnd > markStart {
+		canvas.DrawUnderline(mx, 0, msg, l.font, markStart, markEnd)
+	}
 
 	// Creates texture if if doesnt exist.
 	if l.tex == nil {
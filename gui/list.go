@@ -17,6 +17,9 @@ type List struct {
 	dropdown     bool        // this is used as dropdown
 	keyNext      window.Key  // Code of key to select next item
 	keyPrev      window.Key  // Code of key to select previous item
+	dragItems    bool        // Items are drag sources
+	reorder      bool        // Items can be reordered by dragging
+	dropMark     *Panel      // Indicator of the drop position while reordering (may be nil)
 }
 
 // ListItem encapsulates each item inserted into the list
@@ -50,6 +53,20 @@ type ListItemStyle BasicStyle
 // OnListItemResize is the identifier of the event dispatched when a ListItem's child panel is resized
 const OnListItemResize = "gui.OnListItemResize"
 
+// OnListReorder is the identifier of the event dispatched when an item is moved by dragging it
+const OnListReorder = "gui.OnListReorder"
+
+// DragListItem is the payload type of list and tree items being dragged.
+// The payload value is the dragged item.
+const DragListItem = "gui.ListItem"
+
+// ListReorderEvent is the event dispatched when an item of a list is moved by dragging it.
+type ListReorderEvent struct {
+	Item IPanel // Moved item
+	From int    // Previous position of the item
+	To   int    // New position of the item
+}
+
 // NewVList creates and returns a pointer to a new vertical list panel
 // with the specified dimensions
 func NewVList(width, height float32) *List {
@@ -79,10 +96,16 @@ func (li *List) initialize(vert bool, width, height float32) {
 	li.single = true
 
 	li.ItemScroller.initialize(vert, width, height)
+	li.ItemScroller.SetFocusable(true)
+	li.ItemScroller.SetAccessibleRole(RoleList)
 	li.ItemScroller.SetStyles(li.styles.Scroller)
 	li.ItemScroller.adjustItem = true
 	li.ItemScroller.Subscribe(OnKeyDown, li.onKeyEvent)
 	li.ItemScroller.Subscribe(OnKeyRepeat, li.onKeyEvent)
+	li.ItemScroller.Subscribe(OnDragEnter, li.onDrag)
+	li.ItemScroller.Subscribe(OnDragOver, li.onDrag)
+	li.ItemScroller.Subscribe(OnDragLeave, li.onDrag)
+	li.ItemScroller.Subscribe(OnDrop, li.onDrag)
 
 	if vert {
 		li.keyNext = window.KeyDown
@@ -107,6 +130,25 @@ func (li *List) Single() bool {
 	return li.single
 }
 
+// SetDragReorder sets whether the list items can be reordered by dragging them.
+// OnListReorder is dispatched when an item is moved.
+func (li *List) SetDragReorder(state bool) {
+
+	li.reorder = state
+	li.setDragItems(state)
+	if state {
+		li.SetDropTypes(DragListItem)
+	} else {
+		li.SetDropTypes()
+	}
+}
+
+// DragReorder returns whether the list items can be reordered by dragging them.
+func (li *List) DragReorder() bool {
+
+	return li.reorder
+}
+
 // SetStyles set the listr styles overriding the default style
 func (li *List) SetStyles(s *ListStyles) {
 
@@ -129,6 +171,7 @@ func (li *List) InsertAt(pos int, item IPanel) *ListItem {
 	li.ItemScroller.InsertAt(pos, litem)
 	litem.Panel.Subscribe(OnMouseDown, litem.onMouse)
 	litem.Panel.Subscribe(OnCursorEnter, litem.onCursor)
+	litem.setDraggable(li.dragItems)
 	return litem
 }
 
@@ -441,6 +484,110 @@ func (li *List) setSelection(litem *ListItem, state bool, force bool, dispatch b
 	}
 }
 
+// setDragItems sets whether the list items are drag sources
+func (li *List) setDragItems(state bool) {
+
+	li.dragItems = state
+	for _, item := range li.items {
+		item.(*ListItem).setDraggable(state)
+	}
+}
+
+// onDrag receives drag and drop events over the list when reordering items
+func (li *List) onDrag(evname string, ev interface{}) {
+
+	if !li.reorder {
+		return
+	}
+	dev := ev.(*DragEvent)
+	litem, ok := dev.Source.(*ListItem)
+	if !ok || litem.list != li {
+		dev.Accept = false
+		li.setDropMark(-1)
+		return
+	}
+	switch evname {
+	case OnDragEnter, OnDragOver:
+		li.setDropMark(li.dropPosition(dev.Xpos, dev.Ypos))
+	case OnDragLeave:
+		li.setDropMark(-1)
+	case OnDrop:
+		li.setDropMark(-1)
+		from := li.ItemScroller.ItemPosition(litem)
+		to := li.dropPosition(dev.Xpos, dev.Ypos)
+		if to > from {
+			to--
+		}
+		if from < 0 || to == from {
+			return
+		}
+		li.ItemScroller.RemoveAt(from)
+		li.ItemScroller.InsertAt(to, litem)
+		li.Dispatch(OnListReorder, &ListReorderEvent{Item: litem.item, From: from, To: to})
+	}
+}
+
+// dropPosition returns the position where an item dropped at the
+// specified window coordinates would be inserted
+func (li *List) dropPosition(x, y float32) int {
+
+	pos := li.first
+	for i := li.first; i < len(li.items); i++ {
+		item := li.items[i].GetPanel()
+		if !item.Visible() {
+			break
+		}
+		ipos := item.Pospix()
+		if li.vert && y < ipos.Y+item.Height()/2 {
+			return i
+		}
+		if !li.vert && x < ipos.X+item.Width()/2 {
+			return i
+		}
+		pos = i + 1
+	}
+	return pos
+}
+
+// setDropMark shows the drop indicator before the item at the specified position.
+// A negative position hides the drop indicator.
+func (li *List) setDropMark(pos int) {
+
+	if pos < 0 || len(li.items) == 0 {
+		if li.dropMark != nil {
+			li.Panel.Remove(li.dropMark)
+			li.dropMark = nil
+		}
+		return
+	}
+	if li.dropMark == nil {
+		li.dropMark = NewPanel(0, 0)
+		li.dropMark.ApplyStyle(&StyleDefault().DropIndicator)
+		li.Panel.Add(li.dropMark)
+		li.Panel.SetTopChild(li.dropMark)
+	}
+	// Place the indicator at the start of the item or at the end of the previous one
+	const thick = 2
+	var item *Panel
+	var x, y float32
+	if pos < len(li.items) {
+		item = li.items[pos].GetPanel()
+		x = item.Position().X
+		y = item.Position().Y
+	} else {
+		item = li.items[pos-1].GetPanel()
+		x = item.Position().X + item.Width()
+		y = item.Position().Y + item.Height()
+	}
+	if li.vert {
+		li.dropMark.SetSize(item.Width(), thick)
+		li.dropMark.SetPosition(item.Position().X, y-thick/2)
+	} else {
+		li.dropMark.SetSize(thick, item.Height())
+		li.dropMark.SetPosition(x-thick/2, item.Position().Y)
+	}
+}
+
 // update updates the visual state the list and its items
 func (li *List) update() {
 
@@ -458,6 +605,7 @@ func newListItem(list *List, item IPanel) *ListItem {
 
 	litem := new(ListItem)
 	litem.Panel.Initialize(litem, 0, 0)
+	litem.Panel.SetAccessibleRole(RoleListItem)
 	litem.item = item
 	litem.list = list
 	litem.Panel.Add(item)
@@ -471,6 +619,25 @@ func newListItem(list *List, item IPanel) *ListItem {
 	return litem
 }
 
+// setDraggable sets whether this item is a drag source
+func (litem *ListItem) setDraggable(state bool) {
+
+	if state {
+		litem.SetDragSource(litem.dragData)
+	} else {
+		litem.SetDragSource(nil)
+	}
+}
+
+// dragData returns the payload used when this item is dragged
+func (litem *ListItem) dragData(x, y float32) *DragData {
+
+	if litem.list.dropdown {
+		return nil
+	}
+	return &DragData{Type: DragListItem, Value: litem.item, Preview: newItemDragPreview(litem.item)}
+}
+
 // onMouse receives mouse button events over the list item
 func (litem *ListItem) onMouse(evname string, ev interface{}) {
 
@@ -533,3 +700,28 @@ func (litem *ListItem) applyStyle(s *ListItemStyle) {
 	styleCopy.Padding.Left += litem.padLeft
 	litem.Panel.ApplyStyle(&styleCopy)
 }
+
+// newItemDragPreview creates and returns a panel showing the text of
+// the specified item, if any, to be used as drag preview
+func newItemDragPreview(item IPanel) IPanel {
+
+	var text string
+	switch it := item.(type) {
+	case *TreeNode:
+		text = it.label.Text()
+	case *RichText:
+		text = it.PlainText()
+	case interface{ Text() string }:
+		text = it.Text()
+	}
+	prev := NewPanel(0, 0)
+	prev.ApplyStyle(&StyleDefault().DragPreview)
+	if text == "" {
+		prev.SetContentSize(item.GetPanel().Width(), item.GetPanel().Height())
+		return prev
+	}
+	l := NewLabel(text)
+	prev.Add(l)
+	prev.SetContentSize(l.Width(), l.Height())
+	return prev
+}
@@ -5,6 +5,8 @@
 package gui
 
 import (
+	"time"
+
 	"github.com/g3n/engine/core"
 	"github.com/g3n/engine/window"
 )
@@ -23,8 +25,20 @@ type manager struct {
 	keyFocus          core.IDispatcher    // IDispatcher which will exclusively receive all key and char events
 	cursorFocus       core.IDispatcher    // IDispatcher which will exclusively receive all OnCursor events
 	cev               *window.CursorEvent // IDispatcher which will exclusively receive all OnCursor events
+	tipDelay          time.Duration       // Time the cursor must rest over a panel before its tooltip is shown
+	tipOwner          IPanel              // Panel whose tooltip is pending or being shown
+	tipTimer          int                 // Id of the pending tooltip timer (0 if none)
+	tip               *Popover            // Popover used to show tooltips
+	drag              *dragState          // Drag and drop operation pending or in progress (may be nil)
+	dragPreview       *Popover            // Popover used to show drag previews
+	focusNav          bool                // Tab and Shift-Tab move the key focus
+	focusVisible      bool                // Key focus was set by keyboard navigation and the focus ring is shown
+	focusRing         *Panel              // Panel drawn around the panel with key focus
 }
 
+// DefaultTooltipDelay is the default time the cursor must rest over a panel before its tooltip is shown
+const DefaultTooltipDelay = 500 * time.Millisecond
+
 // Manager returns the GUI manager singleton (creating it the first time)
 func Manager() *manager {
 
@@ -36,6 +50,8 @@ func Manager() *manager {
 	gm = new(manager)
 	gm.Dispatcher.Initialize()
 	gm.TimerManager.Initialize()
+	gm.tipDelay = DefaultTooltipDelay
+	gm.focusNav = true
 
 	// Subscribe to window events
 	gm.win = window.Get()
@@ -43,6 +59,9 @@ func Manager() *manager {
 	gm.win.Subscribe(window.OnKeyDown, gm.onKeyboard)
 	gm.win.Subscribe(window.OnKeyRepeat, gm.onKeyboard)
 	gm.win.Subscribe(window.OnChar, gm.onKeyboard)
+	gm.win.Subscribe(window.OnCompositionStart, gm.onKeyboard)
+	gm.win.Subscribe(window.OnCompositionUpdate, gm.onKeyboard)
+	gm.win.Subscribe(window.OnCompositionEnd, gm.onKeyboard)
 	gm.win.Subscribe(window.OnCursor, gm.onCursor)
 	gm.win.Subscribe(window.OnMouseUp, gm.onMouse)
 	gm.win.Subscribe(window.OnMouseDown, gm.onMouse)
@@ -67,6 +86,19 @@ func (gm *manager) SetModal(ipan IPanel) {
 	gm.SetCursorFocus(nil)
 }
 
+// SetTooltipDelay sets the time the cursor must rest over a panel before its tooltip is shown.
+// Tooltips are shown by the manager timers, so ProcessTimers must be called periodically.
+func (gm *manager) SetTooltipDelay(delay time.Duration) {
+
+	gm.tipDelay = delay
+}
+
+// TooltipDelay returns the time the cursor must rest over a panel before its tooltip is shown.
+func (gm *manager) TooltipDelay() time.Duration {
+
+	return gm.tipDelay
+}
+
 // SetKeyFocus sets the key-focused IDispatcher, which will exclusively receive key and char events.
 func (gm *manager) SetKeyFocus(disp core.IDispatcher) {
 
@@ -77,6 +109,8 @@ func (gm *manager) SetKeyFocus(disp core.IDispatcher) {
 		gm.keyFocus.Dispatch(OnFocusLost, nil)
 	}
 	gm.keyFocus = disp
+	gm.focusVisible = false
+	gm.updateFocusRing()
 	if gm.keyFocus != nil {
 		gm.keyFocus.Dispatch(OnFocus, nil)
 	}
@@ -98,6 +132,31 @@ func (gm *manager) SetCursorFocus(disp core.IDispatcher) {
 // The events are dispatched to the focused IDispatcher or to non-GUI.
 func (gm *manager) onKeyboard(evname string, ev interface{}) {
 
+	if evname == OnKeyDown {
+		gm.hideTooltip()
+		// Escape cancels the drag in progress
+		if kev := ev.(*window.KeyEvent); kev.Key == window.KeyEscape && gm.Dragging() {
+			gm.CancelDrag()
+			return
+		}
+	}
+
+	// Tab and Shift-Tab move the key focus if a GUI panel has it,
+	// otherwise they are dispatched as any other key
+	if gm.focusNav && (evname == OnKeyDown || evname == OnKeyRepeat) && gm.panelHasKeyFocus() {
+		if kev := ev.(*window.KeyEvent); kev.Key == window.KeyTab {
+			switch kev.Mods {
+			case 0:
+				gm.FocusNext()
+				return
+			case window.ModShift:
+				gm.FocusPrev()
+				return
+			}
+		}
+	}
+	defer gm.updateFocusRing()
+
 	if gm.keyFocus != nil {
 		if gm.modal == nil {
 			gm.keyFocus.Dispatch(evname, ev)
@@ -114,12 +173,28 @@ func (gm *manager) onKeyboard(evname string, ev interface{}) {
 // OnMouseDownOut/OnMouseUpOut are dispatched to all non-target panels.
 func (gm *manager) onMouse(evname string, ev interface{}) {
 
+	gm.hideTooltip()
+	if evname == OnMouseDown {
+		gm.focusVisible = false
+	}
+	defer gm.updateFocusRing()
+
 	// Check if gm.scene is nil and if so then there are no IPanels to send events to
 	if gm.scene == nil {
 		gm.Dispatch(evname, ev) // Dispatch event to non-GUI since event was not filtered by any GUI component
 		return
 	}
 
+	// Start or finish drag and drop operations with the left mouse button
+	mev := ev.(*window.MouseEvent)
+	if mev.Button == window.MouseButtonLeft {
+		if evname == OnMouseDown {
+			gm.beginDrag(mev.Xpos, mev.Ypos)
+		} else if gm.drag != nil {
+			gm.finishDrag(mev.Xpos, mev.Ypos)
+		}
+	}
+
 	// Dispatch OnMouseDownOut/OnMouseUpOut to all panels except ancestors of target
 	gm.forEachIPanel(func(ipan IPanel) {
 		if gm.target == nil || !ipan.IsAncestorOf(gm.target) {
@@ -140,12 +215,24 @@ func (gm *manager) onMouse(evname string, ev interface{}) {
 	} else if gm.modal == nil {
 		gm.Dispatch(evname, ev)
 	}
+
+	// Open the context menu of the target panel or of its closest ancestor which has one
+	if evname == OnMouseDown && mev.Button == window.MouseButtonRight && gm.target != nil {
+		if gm.modal == nil || gm.modal.IsAncestorOf(gm.target) {
+			if m := contextMenuOf(gm.target); m != nil {
+				m.OpenAt(mev.Xpos, mev.Ypos)
+			}
+		}
+	}
 }
 
 // onScroll is called when scroll events are received.
 // The events are dispatched to the target panel or to non-GUI.
 func (gm *manager) onScroll(evname string, ev interface{}) {
 
+	gm.hideTooltip()
+	defer gm.updateFocusRing()
+
 	// Check if gm.scene is nil and if so then there are no IPanels to send events to
 	if gm.scene == nil {
 		gm.Dispatch(evname, ev) // Dispatch event to non-GUI since event was not filtered by any GUI component
@@ -188,9 +275,17 @@ func (gm *manager) onCursor(evname string, ev interface{}) {
 	// Find IPanel immediately under the cursor and store it in gm.target
 	gm.forEachIPanel(func(ipan IPanel) {
 		if ipan.InsideBorders(gm.cev.Xpos, gm.cev.Ypos) && (gm.target == nil || ipan.Position().Z < gm.target.GetPanel().Position().Z) {
+			// Tooltips, drag previews and the focus ring never receive cursor events
+			if gm.isPassive(ipan) {
+				return
+			}
 			gm.target = ipan
 		}
 	})
+	gm.updateTooltip()
+	if gm.drag != nil {
+		gm.updateDrag(gm.cev.Xpos, gm.cev.Ypos)
+	}
 
 	// If the cursor is now over a different panel, dispatch OnCursorLeave/OnCursorEnter
 	if gm.target != oldTarget {
@@ -219,6 +314,119 @@ func (gm *manager) onCursor(evname string, ev interface{}) {
 	}
 }
 
+// updateTooltip schedules the tooltip of the panel under the cursor to be shown
+// and hides the tooltip being shown if the cursor left its panel.
+func (gm *manager) updateTooltip() {
+
+	owner := tooltipOwner(gm.target)
+	if owner != nil && gm.modal != nil && !gm.modal.IsAncestorOf(owner) {
+		owner = nil
+	}
+	if owner == gm.tipOwner {
+		return
+	}
+	gm.hideTooltip()
+	gm.tipOwner = owner
+	if owner == nil {
+		return
+	}
+	gm.tipTimer = gm.SetTimeout(gm.tipDelay, nil, func(arg interface{}) {
+		gm.tipTimer = 0
+		gm.showTooltip()
+	})
+}
+
+// showTooltip shows the tooltip of the current tooltip owner next to the cursor.
+func (gm *manager) showTooltip() {
+
+	if gm.tipOwner == nil || gm.cev == nil {
+		return
+	}
+	content := gm.tipOwner.GetPanel().tooltip
+	if content == nil {
+		return
+	}
+	if gm.tip == nil {
+		gm.tip = NewPopover(content)
+		gm.tip.SetAutoClose(false)
+		gm.tip.ApplyStyle(&PanelStyle{})
+	} else {
+		gm.tip.SetContent(content)
+	}
+	style := &StyleDefault().Tooltip
+	gm.tip.ShowAt(gm.cev.Xpos+style.OffsetX, gm.cev.Ypos+style.OffsetY)
+}
+
+// hideTooltip cancels the pending tooltip and hides the tooltip being shown.
+// The tooltip is not shown again until the cursor leaves its panel.
+func (gm *manager) hideTooltip() {
+
+	if gm.tipTimer != 0 {
+		gm.ClearTimeout(gm.tipTimer)
+		gm.tipTimer = 0
+	}
+	if gm.tip != nil {
+		gm.tip.Close()
+	}
+}
+
+// isPassive returns whether the specified panel belongs to an overlay
+// which never receives cursor events.
+func (gm *manager) isPassive(ipan IPanel) bool {
+
+	if gm.tip != nil && gm.tip.IsOpen() && gm.tip.IsAncestorOf(ipan) {
+		return true
+	}
+	if gm.dragPreview != nil && gm.dragPreview.IsOpen() && gm.dragPreview.IsAncestorOf(ipan) {
+		return true
+	}
+	return gm.focusRing != nil && gm.focusRing == ipan
+}
+
+// addOverlay adds the specified panel to the top level of the current scene.
+func (gm *manager) addOverlay(ipan IPanel) {
+
+	if gm.scene == nil || ipan.Parent() == gm.scene {
+		return
+	}
+	if par := ipan.Parent(); par != nil {
+		par.GetNode().Remove(ipan)
+	}
+	gm.scene.GetNode().Add(ipan)
+}
+
+// tooltipOwner returns the specified panel or its closest ancestor which has a tooltip.
+func tooltipOwner(ipan IPanel) IPanel {
+
+	var ok bool
+	for ipan != nil {
+		if ipan.GetPanel().tooltip != nil {
+			return ipan
+		}
+		ipan, ok = ipan.Parent().(IPanel)
+		if !ok {
+			break
+		}
+	}
+	return nil
+}
+
+// contextMenuOf returns the context menu of the specified panel or of its closest ancestor which has one.
+func contextMenuOf(ipan IPanel) *Menu {
+
+	var ok bool
+	for ipan != nil {
+		if m := ipan.GetPanel().ctxMenu; m != nil {
+			return m
+		}
+		ipan, ok = ipan.Parent().(IPanel)
+		if !ok {
+			break
+		}
+	}
+	return nil
+}
+
 // sendAncestry sends the specified event (evname/ev) to the specified target panel and its ancestors.
 // If all is false, then the event is only sent to the lowest subscribed ancestor.
 // If uptoEx (i.e. excluding) is not nil then the event will not be dispatched to that ancestor nor any higher ancestors.
@@ -20,6 +20,7 @@ type Menu struct {
 	items    []*MenuItem // menu items
 	autoOpen bool        // open sub menus when mouse over if true
 	mitem    *MenuItem   // parent menu item for sub menu
+	popover  *Popover    // popover used when opened as a context menu
 }
 
 // MenuBodyStyle describes the style of the menu body
@@ -142,6 +143,8 @@ func NewMenuBar() *Menu {
 
 	m := NewMenu()
 	m.bar = true
+	m.SetFocusable(true)
+	m.SetAccessibleRole(RoleMenuBar)
 	m.Panel.Subscribe(OnMouseDownOut, m.onMouse)
 	return m
 }
@@ -151,6 +154,7 @@ func NewMenu() *Menu {
 
 	m := new(Menu)
 	m.Panel.Initialize(m, 0, 0)
+	m.Panel.SetAccessibleRole(RoleMenu)
 	m.styles = &StyleDefault().Menu
 	m.items = make([]*MenuItem, 0)
 	m.Panel.Subscribe(OnKeyDown, m.onKey)
@@ -207,6 +211,37 @@ func (m *Menu) AddMenu(text string, subm *Menu) *MenuItem {
 	return mi
 }
 
+// OpenAt opens this menu as a context menu popup at the specified window coordinates.
+// The popup is closed when an option is activated, the Escape key is pressed
+// or the mouse is pressed outside of it.
+func (m *Menu) OpenAt(x, y float32) {
+
+	if m.popover == nil {
+		m.popover = NewPopover(m)
+		m.popover.ApplyStyle(&PanelStyle{})
+		m.popover.Subscribe(OnPopoverClose, func(evname string, ev interface{}) {
+			m.setSelectedPos(-1)
+			if Manager().keyFocus == m {
+				Manager().SetKeyFocus(nil)
+			}
+		})
+		m.Subscribe(OnClick, func(evname string, ev interface{}) { m.ClosePopup() })
+	}
+	m.autoOpen = true
+	m.setSelectedPos(-1)
+	m.popover.ShowAt(x, y)
+	Manager().SetKeyFocus(m)
+}
+
+// ClosePopup closes this menu if it was opened as a context menu popup.
+func (m *Menu) ClosePopup() {
+
+	if m.popover == nil {
+		return
+	}
+	m.popover.Close()
+}
+
 // RemoveItem removes the specified menu item from this menu
 func (m *Menu) RemoveItem(mi *MenuItem) {
 
@@ -217,6 +252,17 @@ func (m *Menu) onKey(evname string, ev interface{}) {
 
 	sel := m.selectedPos()
 	kev := ev.(*window.KeyEvent)
+
+	// Arrow keys select the first enabled item if none is selected,
+	// as when the menu receives the key focus by keyboard navigation
+	if sel < 0 && len(m.items) > 0 {
+		switch kev.Key {
+		case window.KeyDown, window.KeyUp, window.KeyLeft, window.KeyRight:
+			m.setSelectedPos(m.nextItem(-1))
+			return
+		}
+	}
+
 	switch kev.Key {
 	// Select next enabled menu item
 	case window.KeyDown:
@@ -299,6 +345,13 @@ func (m *Menu) onKey(evname string, ev interface{}) {
 			m.mitem.menu.setSelectedPos(next)
 			Manager().SetKeyFocus(m.mitem.menu)
 		}
+	// Escape -> Close context menu popup
+	case window.KeyEscape:
+		root := m
+		for root.mitem != nil {
+			root = root.mitem.menu
+		}
+		root.ClosePopup()
 	// Enter -> Select menu option
 	case window.KeyEnter:
 		if sel < 0 {
@@ -561,6 +614,8 @@ func newMenuItem(text string, styles *MenuItemStyles) *MenuItem {
 
 	mi := new(MenuItem)
 	mi.Panel.Initialize(mi, 0, 0)
+	mi.Panel.SetAccessibleRole(RoleMenuItem)
+	mi.Panel.SetAccessibleName(text)
 	mi.styles = styles
 	if text != "" {
 		mi.label = NewLabel(text)
@@ -71,6 +71,17 @@ type Panel struct {
 	layout       ILayout     // current layout for children
 	layoutParams interface{} // current layout parameters used by container panel
 
+	tooltip IPanel // tooltip shown when the cursor rests over the panel (may be nil)
+	ctxMenu *Menu  // context menu opened by right clicking the panel (may be nil)
+
+	dragSource DragSourceFunc // returns the payload when a drag starts on the panel (may be nil)
+	dropTypes  []string       // payload types accepted when the panel is a drop target
+
+	focusable bool       // whether the panel receives key focus by keyboard navigation
+	tabIndex  int        // focus order for keyboard navigation
+	accName   string     // accessible name
+	accRole   AccessRole // accessible role
+
 	marginSizes  RectBounds // external margin sizes in pixel coordinates
 	borderSizes  RectBounds // border sizes in pixel coordinates
 	paddingSizes RectBounds // padding sizes in pixel coordinates
@@ -605,6 +616,52 @@ func (p *Panel) Enabled() bool {
 	return p.enabled
 }
 
+// SetTooltip sets the text of the tooltip shown when the cursor rests over this panel.
+// The text may contain RichText markup. An empty text removes the tooltip.
+func (p *Panel) SetTooltip(text string) {
+
+	if text == "" {
+		p.tooltip = nil
+		return
+	}
+	style := &StyleDefault().Tooltip
+	rt := NewRichText(0, "")
+	rt.SetStyles(&style.RichTextStyle)
+	rt.SetText(text)
+	if style.MaxWidth > 0 && rt.ContentWidth() > style.MaxWidth {
+		rt.SetContentWidth(style.MaxWidth)
+		rt.SetWrap(true)
+	}
+	p.tooltip = rt
+}
+
+// SetTooltipPanel sets the panel shown as tooltip when the cursor rests over this panel.
+// Passing nil removes the tooltip.
+func (p *Panel) SetTooltipPanel(tip IPanel) {
+
+	p.tooltip = tip
+}
+
+// Tooltip returns the tooltip panel of this panel or nil if none.
+func (p *Panel) Tooltip() IPanel {
+
+	return p.tooltip
+}
+
+// SetContextMenu sets the menu opened at the cursor position when this panel
+// or one of its descendants without its own context menu is right clicked.
+// Passing nil removes the context menu.
+func (p *Panel) SetContextMenu(m *Menu) {
+
+	p.ctxMenu = m
+}
+
+// ContextMenu returns the context menu of this panel or nil if none.
+func (p *Panel) ContextMenu() *Menu {
+
+	return p.ctxMenu
+}
+
 // SetLayout sets the layout to use to position the children of this panel
 // To remove the layout, call this function passing nil as parameter.
 func (p *Panel) SetLayout(ilayout ILayout) {
@@ -0,0 +1,213 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+// Popover is a floating panel shown above all other panels.
+// It can be shown at a point, such as the cursor position, or anchored to
+// one side of another panel. It is always repositioned to stay inside the
+// window and by default is closed when the mouse is pressed outside of it.
+type Popover struct {
+	Panel                 // Embedded panel
+	content   IPanel      // Panel shown inside the popover
+	anchor    IPanel      // Anchor panel (may be nil)
+	side      PopoverSide // Side of the anchor where the popover is shown
+	autoClose bool        // Close when mouse is pressed outside of the popover
+	open      bool        // Popover is currently shown
+}
+
+// TooltipStyle contains the styling of text tooltips.
+type TooltipStyle struct {
+	RichTextStyle
+	MaxWidth float32 // Maximum width before the text is wrapped (0 for no limit)
+	OffsetX  float32 // Horizontal offset from the cursor position
+	OffsetY  float32 // Vertical offset from the cursor position
+}
+
+// PopoverSide specifies the side of the anchor panel where a popover is shown.
+type PopoverSide int
+
+// Sides of the anchor panel where a popover can be shown.
+// If the popover doesn't fit on the specified side it is flipped to the opposite side.
+const (
+	PopoverBelow = PopoverSide(iota)
+	PopoverAbove
+	PopoverRight
+	PopoverLeft
+)
+
+// OnPopoverClose is the identifier of the event dispatched when a popover is closed
+const OnPopoverClose = "gui.OnPopoverClose"
+
+// overlayZLayer is the Z-layer of popovers, tooltips and drag previews
+const overlayZLayer = 1000
+
+// NewPopover creates and returns a pointer to a new popover with the specified content panel.
+func NewPopover(content IPanel) *Popover {
+
+	pop := new(Popover)
+	pop.Panel.Initialize(pop, 0, 0)
+	pop.zLayerDelta = overlayZLayer
+	pop.autoClose = true
+	pop.SetVisible(false)
+	pop.ApplyStyle(&StyleDefault().Popover)
+	pop.Subscribe(OnMouseDownOut, func(evname string, ev interface{}) {
+		if pop.open && pop.autoClose {
+			pop.Close()
+		}
+	})
+	pop.Subscribe(OnResize, func(evname string, ev interface{}) { pop.reposition() })
+	pop.SetContent(content)
+	return pop
+}
+
+// SetContent sets the panel shown inside the popover.
+// The popover is resized to fit the content.
+func (pop *Popover) SetContent(content IPanel) {
+
+	if pop.content == content {
+		return
+	}
+	if pop.content != nil {
+		pop.content.GetPanel().UnsubscribeID(OnResize, pop)
+		pop.Panel.Remove(pop.content)
+	}
+	pop.content = content
+	if content == nil {
+		pop.SetContentSize(0, 0)
+		return
+	}
+	content.SetPosition(0, 0)
+	content.GetPanel().SubscribeID(OnResize, pop, func(evname string, ev interface{}) { pop.recalc() })
+	pop.Panel.Add(content)
+	pop.recalc()
+}
+
+// Content returns the panel shown inside the popover.
+func (pop *Popover) Content() IPanel {
+
+	return pop.content
+}
+
+// SetAutoClose sets whether the popover is closed when the mouse is pressed outside of it.
+func (pop *Popover) SetAutoClose(state bool) {
+
+	pop.autoClose = state
+}
+
+// AutoClose returns whether the popover is closed when the mouse is pressed outside of it.
+func (pop *Popover) AutoClose() bool {
+
+	return pop.autoClose
+}
+
+// IsOpen returns whether the popover is currently shown.
+func (pop *Popover) IsOpen() bool {
+
+	return pop.open
+}
+
+// ShowAt shows the popover with its top left corner at the specified window coordinates.
+func (pop *Popover) ShowAt(x, y float32) {
+
+	pop.anchor = nil
+	pop.SetPosition(x, y)
+	pop.show()
+}
+
+// ShowAnchored shows the popover at the specified side of the anchor panel.
+func (pop *Popover) ShowAnchored(anchor IPanel, side PopoverSide) {
+
+	pop.anchor = anchor
+	pop.side = side
+	pop.show()
+}
+
+// Close hides the popover and dispatches OnPopoverClose.
+func (pop *Popover) Close() {
+
+	if !pop.open {
+		return
+	}
+	pop.open = false
+	pop.SetVisible(false)
+	pop.Dispatch(OnPopoverClose, nil)
+}
+
+// show attaches the popover to the GUI manager scene and makes it visible.
+func (pop *Popover) show() {
+
+	Manager().addOverlay(pop)
+	pop.open = true
+	pop.SetVisible(true)
+	pop.reposition()
+}
+
+// recalc resizes the popover to fit its content.
+func (pop *Popover) recalc() {
+
+	if pop.content == nil {
+		return
+	}
+	pop.SetContentSize(pop.content.Width(), pop.content.Height())
+}
+
+// reposition places the popover next to its anchor if any and keeps it inside the window.
+func (pop *Popover) reposition() {
+
+	if !pop.open {
+		return
+	}
+	width, height := Manager().win.GetSize()
+	winWidth := float32(width)
+	winHeight := float32(height)
+	x := pop.Position().X
+	y := pop.Position().Y
+
+	if pop.anchor != nil {
+		apan := pop.anchor.GetPanel()
+		apos := apan.Pospix()
+		switch pop.side {
+		case PopoverBelow:
+			x = apos.X
+			y = apos.Y + apan.Height()
+			if y+pop.height > winHeight {
+				y = apos.Y - pop.height
+			}
+		case PopoverAbove:
+			x = apos.X
+			y = apos.Y - pop.height
+			if y < 0 {
+				y = apos.Y + apan.Height()
+			}
+		case PopoverRight:
+			x = apos.X + apan.Width()
+			y = apos.Y
+			if x+pop.width > winWidth {
+				x = apos.X - pop.width
+			}
+		case PopoverLeft:
+			x = apos.X - pop.width
+			y = apos.Y
+			if x < 0 {
+				x = apos.X + apan.Width()
+			}
+		}
+	}
+
+	// Keep the popover inside the window
+	if x+pop.width > winWidth {
+		x = winWidth - pop.width
+	}
+	if y+pop.height > winHeight {
+		y = winHeight - pop.height
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	pop.SetPosition(x, y)
+}
@@ -0,0 +1,626 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gui
+
+import (
+	"image"
+	"image/draw"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/gui/assets/icon"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+	"github.com/g3n/engine/texture"
+	"github.com/g3n/engine/window"
+)
+
+/***************************************
+
+ RichText markup:
+
+ [b]bold[/b]
+ [i]italic[/i]
+ [u]underlined[/u]
+ [color=red]named color[/color]
+ [color=#FF8000]hex color[/color]
+ [size=20]point size[/size]
+ [link=id]clickable link[/link]
+ [icon=Settings] (Material icon name)
+ [[ (literal '[')
+
+****************************************/
+
+// RichText is a panel which displays text with inline markup.
+// Spans of text can be bold, italic, underlined, colored, resized,
+// contain inline icons or be clickable links.
+// If the rich text has a fixed width the text is word wrapped to it,
+// otherwise the panel is sized to fit its content.
+type RichText struct {
+	Panel                      // Embedded Panel
+	styles  *RichTextStyle     // Pointer to current style
+	tex     *texture.Texture2D // Texture with the rendered text
+	markup  string             // Current markup text
+	runs    []richRun          // Runs of text with the same attributes
+	links   []*richLink        // Links found in the markup
+	wrap    bool               // Word wrap to the content width
+	lwidth  float32            // Content width used in the last layout
+	over    *richLink          // Link under the cursor (may be nil)
+	pressed *richLink          // Link pressed by the mouse (may be nil)
+}
+
+// RichTextStyle contains the styling of a RichText.
+type RichTextStyle struct {
+	PanelStyle
+	text.FontAttributes
+	FgColor       math32.Color4
+	LinkColor     math32.Color4
+	LinkOverColor math32.Color4
+}
+
+// RichTextLinkEvent is the event dispatched with OnLinkClick
+type RichTextLinkEvent struct {
+	ID   string // Link id as specified in the markup
+	Text string // Link text
+}
+
+// OnLinkClick is the identifier of the event dispatched when a link of a RichText is clicked
+const OnLinkClick = "gui.OnLinkClick"
+
+// richAttr describes the attributes of a run of rich text
+type richAttr struct {
+	bold      bool
+	italic    bool
+	underline bool
+	color     math32.Color4
+	size      float64
+	link      *richLink
+}
+
+// richTag is an open tag of the markup with the change of the attributes it applies
+type richTag struct {
+	name  string          // tag name
+	apply func(*richAttr) // applies the tag to the attributes
+}
+
+// richRun is a run of text with the same attributes
+type richRun struct {
+	attr richAttr
+	text string
+	icon bool
+}
+
+// richLink describes a link and its areas in the content
+type richLink struct {
+	id    string
+	text  string
+	rects []Rect
+}
+
+// richFrag is a laid out fragment of a run
+type richFrag struct {
+	run     *richRun
+	text    string
+	x       int
+	width   int
+	ascent  int
+	descent int
+}
+
+// richLine is a laid out line of fragments
+type richLine struct {
+	frags   []richFrag
+	width   int
+	ascent  int
+	descent int
+	wrapped bool // line was started by word wrapping
+}
+
+// italicSlant is the horizontal shear used to synthesize italic text
+const italicSlant = 0.2
+
+// NewRichText creates and returns a pointer to a new rich text panel
+// with the specified content width and markup.
+// If width is zero, the text is not wrapped and the panel is sized to its content.
+func NewRichText(width float32, markup string) *RichText {
+
+	rt := new(RichText)
+	rt.Panel.Initialize(rt, 0, 0)
+	rt.Panel.mat.SetTransparent(true)
+	rt.styles = &StyleDefault().RichText
+	rt.wrap = width > 0
+	rt.lwidth = width
+
+	rt.Panel.ApplyStyle(&rt.styles.PanelStyle)
+	rt.SetText(markup)
+
+	rt.Subscribe(OnCursor, rt.onCursor)
+	rt.Subscribe(OnCursorLeave, rt.onCursor)
+	rt.Subscribe(OnMouseDown, rt.onMouse)
+	rt.Subscribe(OnMouseUp, rt.onMouse)
+	rt.Subscribe(OnResize, rt.onResize)
+	return rt
+}
+
+// SetText sets the markup text, parses and draws it.
+func (rt *RichText) SetText(markup string) {
+
+	rt.markup = markup
+	rt.over = nil
+	rt.pressed = nil
+	rt.parse()
+	rt.redraw()
+}
+
+// AppendText appends the specified markup to the current text.
+// It is useful for chat and log panels.
+func (rt *RichText) AppendText(markup string) {
+
+	rt.SetText(rt.markup + markup)
+}
+
+// Text returns the current markup text.
+func (rt *RichText) Text() string {
+
+	return rt.markup
+}
+
+// PlainText returns the current text without markup.
+func (rt *RichText) PlainText() string {
+
+	var sb strings.Builder
+	for i := range rt.runs {
+		if !rt.runs[i].icon {
+			sb.WriteString(rt.runs[i].text)
+		}
+	}
+	return sb.String()
+}
+
+// SetWrap sets whether the text is word wrapped to the current content width.
+func (rt *RichText) SetWrap(wrap bool) {
+
+	rt.wrap = wrap
+	rt.lwidth = rt.ContentWidth()
+	rt.redraw()
+}
+
+// Wrap returns whether the text is word wrapped to the content width.
+func (rt *RichText) Wrap() bool {
+
+	return rt.wrap
+}
+
+// SetStyles sets the rich text styles overriding the default style.
+func (rt *RichText) SetStyles(rs *RichTextStyle) {
+
+	rt.styles = rs
+	rt.Panel.ApplyStyle(&rt.styles.PanelStyle)
+	rt.SetText(rt.markup)
+}
+
+// parse parses the current markup into runs of text with the same attributes.
+// Unknown tags are kept as literal text.
+func (rt *RichText) parse() {
+
+	rt.runs = rt.runs[:0]
+	rt.links = rt.links[:0]
+	base := richAttr{color: rt.styles.FgColor, size: rt.styles.PointSize}
+	attr := base
+	var tags []richTag
+	var sb strings.Builder
+
+	flush := func() {
+		if sb.Len() == 0 {
+			return
+		}
+		rt.runs = append(rt.runs, richRun{attr: attr, text: sb.String()})
+		sb.Reset()
+	}
+
+	s := rt.markup
+	for len(s) > 0 {
+		if s[0] != '[' {
+			idx := strings.IndexByte(s, '[')
+			if idx < 0 {
+				idx = len(s)
+			}
+			sb.WriteString(s[:idx])
+			s = s[idx:]
+			continue
+		}
+		// Escaped bracket
+		if strings.HasPrefix(s, "[[") {
+			sb.WriteByte('[')
+			s = s[2:]
+			continue
+		}
+		end := strings.IndexByte(s, ']')
+		if end < 0 {
+			sb.WriteString(s)
+			break
+		}
+		tag := s[1:end]
+		name, value := tag, ""
+		if eq := strings.IndexByte(tag, '='); eq >= 0 {
+			name, value = tag[:eq], strings.TrimSpace(tag[eq+1:])
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		var apply func(*richAttr)
+		switch name {
+		case "b":
+			apply = func(a *richAttr) { a.bold = true }
+		case "i":
+			apply = func(a *richAttr) { a.italic = true }
+		case "u":
+			apply = func(a *richAttr) { a.underline = true }
+		case "color":
+			c, ok := parseRichColor(value)
+			if ok {
+				apply = func(a *richAttr) { a.color = c }
+			}
+		case "size":
+			size, err := strconv.ParseFloat(value, 64)
+			if err == nil && size > 0 {
+				apply = func(a *richAttr) { a.size = size }
+			}
+		case "link":
+			link := &richLink{id: value}
+			rt.links = append(rt.links, link)
+			apply = func(a *richAttr) { a.link = link }
+		case "icon":
+			cp := icon.Codepoint(value)
+			if cp == "" {
+				break
+			}
+			flush()
+			rt.runs = append(rt.runs, richRun{attr: attr, text: cp, icon: true})
+			s = s[end+1:]
+			continue
+		case "/b", "/i", "/u", "/color", "/size", "/link":
+			// Closes the last open tag with the same name, keeping the tags opened after it,
+			// and ignores the closing tag if there is none
+			flush()
+			for i := len(tags) - 1; i >= 0; i-- {
+				if tags[i].name == name[1:] {
+					tags = append(tags[:i], tags[i+1:]...)
+					attr = base
+					for _, t := range tags {
+						t.apply(&attr)
+					}
+					break
+				}
+			}
+			s = s[end+1:]
+			continue
+		}
+		if apply == nil {
+			sb.WriteString(s[:end+1])
+			s = s[end+1:]
+			continue
+		}
+		flush()
+		tags = append(tags, richTag{name, apply})
+		apply(&attr)
+		s = s[end+1:]
+	}
+	flush()
+
+	// Sets the text of the links
+	for i := range rt.runs {
+		run := &rt.runs[i]
+		if run.attr.link != nil && !run.icon {
+			run.attr.link.text += run.text
+		}
+	}
+}
+
+// parseRichColor parses a web color name or a "#RRGGBB" or "#RRGGBBAA" hex color
+func parseRichColor(value string) (math32.Color4, bool) {
+
+	if strings.HasPrefix(value, "#") {
+		hex := value[1:]
+		if len(hex) != 6 && len(hex) != 8 {
+			return math32.Color4{}, false
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return math32.Color4{}, false
+		}
+		alpha := float32(1)
+		if len(hex) == 8 {
+			alpha = float32(v&0xFF) / 255
+			v >>= 8
+		}
+		var c math32.Color4
+		c.SetHex(uint(v))
+		c.A = alpha
+		return c, true
+	}
+	c, ok := math32.IsColorName(value)
+	if !ok {
+		return math32.Color4{}, false
+	}
+	return math32.Color4{R: c.R, G: c.G, B: c.B, A: 1}, true
+}
+
+// fontFor returns the font for the specified run with its attributes set.
+func (rt *RichText) fontFor(run *richRun) *text.Font {
+
+	font := StyleDefault().Font
+	if run.icon {
+		font = StyleDefault().FontIcon
+	} else if run.attr.bold && StyleDefault().FontBold != nil {
+		font = StyleDefault().FontBold
+	}
+	attr := rt.styles.FontAttributes
+	attr.PointSize = run.attr.size
+	font.SetAttributes(&attr)
+	color := rt.colorFor(run)
+	font.SetColor(&color)
+	return font
+}
+
+// colorFor returns the text color of the specified run.
+func (rt *RichText) colorFor(run *richRun) math32.Color4 {
+
+	if run.attr.link == nil || run.icon {
+		return run.attr.color
+	}
+	if run.attr.link == rt.over {
+		return rt.styles.LinkOverColor
+	}
+	return rt.styles.LinkColor
+}
+
+// layout breaks the runs into lines of fragments no wider than the specified width.
+// If width is zero the lines are not wrapped.
+func (rt *RichText) layout(width int) []richLine {
+
+	lines := []richLine{{}}
+	line := &lines[0]
+	newLine := func() {
+		lines = append(lines, richLine{})
+		line = &lines[len(lines)-1]
+	}
+
+	for i := range rt.runs {
+		run := &rt.runs[i]
+		font := rt.fontFor(run)
+		metrics := font.Metrics()
+		ascent := metrics.Ascent.Ceil()
+		descent := metrics.Descent.Ceil()
+		for _, word := range splitWords(run.text, run.icon) {
+			if word == "\n" {
+				rt.closeLine(line, font)
+				newLine()
+				continue
+			}
+			blank := strings.TrimSpace(word) == ""
+			// Ignore blanks at the start of wrapped lines
+			if blank && len(line.frags) == 0 && line.wrapped {
+				continue
+			}
+			w, _ := font.MeasureText(word)
+			if width > 0 && !blank && line.width > 0 && line.width+w > width {
+				rt.closeLine(line, font)
+				newLine()
+				line.wrapped = true
+			}
+			line.frags = append(line.frags, richFrag{run: run, text: word, x: line.width, width: w, ascent: ascent, descent: descent})
+			line.width += w
+		}
+	}
+	rt.closeLine(line, StyleDefault().Font)
+	return lines
+}
+
+// closeLine calculates the vertical metrics of a line.
+// Empty lines use the metrics of the specified font.
+func (rt *RichText) closeLine(line *richLine, font *text.Font) {
+
+	if len(line.frags) == 0 {
+		attr := rt.styles.FontAttributes
+		font.SetAttributes(&attr)
+		metrics := font.Metrics()
+		line.ascent = metrics.Ascent.Ceil()
+		line.descent = metrics.Descent.Ceil()
+		return
+	}
+	// Trailing blanks do not count in the line width
+	for i := len(line.frags) - 1; i >= 0; i-- {
+		if strings.TrimSpace(line.frags[i].text) != "" {
+			break
+		}
+		line.width = line.frags[i].x
+	}
+	for _, f := range line.frags {
+		if f.ascent > line.ascent {
+			line.ascent = f.ascent
+		}
+		if f.descent > line.descent {
+			line.descent = f.descent
+		}
+	}
+}
+
+// splitWords splits the specified text in words, blanks and line breaks.
+func splitWords(s string, icon bool) []string {
+
+	if icon {
+		return []string{s}
+	}
+	words := []string{}
+	start := 0
+	blank := false
+	for i, r := range s {
+		if r == '\n' {
+			if i > start {
+				words = append(words, s[start:i])
+			}
+			words = append(words, "\n")
+			start = i + 1
+			continue
+		}
+		isBlank := unicode.IsSpace(r)
+		if i > start && isBlank != blank {
+			words = append(words, s[start:i])
+			start = i
+		}
+		blank = isBlank
+	}
+	if start < len(s) {
+		words = append(words, s[start:])
+	}
+	return words
+}
+
+// redraw lays out and draws the text in the panel texture.
+func (rt *RichText) redraw() {
+
+	wrapWidth := 0
+	if rt.wrap {
+		wrapWidth = int(rt.lwidth)
+	}
+	lines := rt.layout(wrapWidth)
+
+	// Calculates the image size
+	width := wrapWidth
+	height := 0
+	for _, line := range lines {
+		if !rt.wrap && line.width > width {
+			width = line.width
+		}
+		height += int(float64(line.ascent+line.descent) * rt.styles.LineSpacing)
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// Draws the fragments and records the link areas
+	for _, link := range rt.links {
+		link.rects = link.rects[:0]
+	}
+	y := 0
+	for _, line := range lines {
+		baseline := y + line.ascent
+		for _, f := range line.frags {
+			font := rt.fontFor(f.run)
+			top := baseline - f.ascent
+			if f.run.attr.italic && !f.run.icon {
+				drawItalic(font, f.text, f.x, top, f.ascent, f.ascent+f.descent, img)
+			} else {
+				font.DrawTextOnImage(f.text, f.x, top, img)
+			}
+			link := f.run.attr.link
+			if f.run.attr.underline || (link != nil && !f.run.icon) {
+				fg := rt.colorFor(f.run)
+				color := text.Color4RGBA(&fg)
+				for x := f.x; x < f.x+f.width; x++ {
+					img.Set(x, baseline+1, color)
+				}
+			}
+			if link != nil {
+				link.rects = append(link.rects, Rect{X: float32(f.x), Y: float32(top), Width: float32(f.width), Height: float32(f.ascent + f.descent)})
+			}
+		}
+		y += int(float64(line.ascent+line.descent) * rt.styles.LineSpacing)
+	}
+
+	// Creates texture if it doesn't exist yet, otherwise updates it
+	if rt.tex == nil {
+		rt.tex = texture.NewTexture2DFromRGBA(img)
+		rt.tex.SetMagFilter(gls.NEAREST)
+		rt.tex.SetMinFilter(gls.NEAREST)
+		rt.Panel.Material().AddTexture(rt.tex)
+	} else {
+		rt.tex.SetFromRGBA(img)
+	}
+	rt.lwidth = float32(width)
+	rt.Panel.SetContentSize(float32(width), float32(height))
+}
+
+// drawItalic draws the specified text synthesizing an italic face by shearing it.
+func drawItalic(font *text.Font, s string, x, y, ascent, height int, dst *image.RGBA) {
+
+	w, _ := font.MeasureText(s)
+	slant := int(float32(height)*italicSlant) + 1
+	tmp := image.NewRGBA(image.Rect(0, 0, w+slant, height))
+	font.DrawTextOnImage(s, 0, 0, tmp)
+	for row := 0; row < height; row++ {
+		shift := int(float32(ascent-row) * italicSlant)
+		dr := image.Rect(x+shift, y+row, x+shift+w+slant, y+row+1)
+		draw.Draw(dst, dr, tmp, image.Pt(0, row), draw.Over)
+	}
+}
+
+// linkAt returns the link at the specified window coordinates or nil.
+func (rt *RichText) linkAt(wx, wy float32) *richLink {
+
+	cx, cy := rt.ContentCoords(wx, wy)
+	for _, link := range rt.links {
+		for i := range link.rects {
+			if link.rects[i].Contains(cx, cy) {
+				return link
+			}
+		}
+	}
+	return nil
+}
+
+// onCursor processes subscribed cursor events
+func (rt *RichText) onCursor(evname string, ev interface{}) {
+
+	var over *richLink
+	if evname == OnCursor {
+		cev := ev.(*window.CursorEvent)
+		over = rt.linkAt(cev.Xpos, cev.Ypos)
+	}
+	if over == rt.over {
+		return
+	}
+	if over != nil {
+		window.Get().SetCursor(window.HandCursor)
+	} else {
+		window.Get().SetCursor(window.ArrowCursor)
+	}
+	rt.over = over
+	rt.redraw()
+}
+
+// onMouse processes subscribed mouse events
+func (rt *RichText) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	if mev.Button != window.MouseButtonLeft {
+		return
+	}
+	link := rt.linkAt(mev.Xpos, mev.Ypos)
+	switch evname {
+	case OnMouseDown:
+		rt.pressed = link
+	case OnMouseUp:
+		if link != nil && link == rt.pressed {
+			rt.Dispatch(OnLinkClick, &RichTextLinkEvent{ID: link.id, Text: link.text})
+		}
+		rt.pressed = nil
+	}
+}
+
+// onResize re-wraps the text when the content width changes
+func (rt *RichText) onResize(evname string, ev interface{}) {
+
+	if !rt.wrap || rt.ContentWidth() == rt.lwidth {
+		return
+	}
+	rt.lwidth = rt.ContentWidth()
+	rt.redraw()
+}
@@ -72,6 +72,8 @@ func newSlider(horiz bool, width, height float32) *Slider {
 
 	// Initialize main panel
 	s.Panel.Initialize(s, width, height)
+	s.Panel.SetFocusable(true)
+	s.Panel.SetAccessibleRole(RoleSlider)
 	s.Panel.Subscribe(OnMouseDown, s.onMouse)
 	s.Panel.Subscribe(OnMouseUp, s.onMouse)
 	s.Panel.Subscribe(OnCursor, s.onCursor)
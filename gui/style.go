@@ -14,7 +14,9 @@ type Style struct {
 	Color         ColorStyle
 	Font          *text.Font
 	FontIcon      *text.Font
+	FontBold      *text.Font
 	Label         LabelStyle
+	RichText      RichTextStyle
 	Button        ButtonStyles
 	CheckRadio    CheckRadioStyles
 	Edit          EditStyles
@@ -33,6 +35,11 @@ type Style struct {
 	Table         TableStyles
 	ImageButton   ImageButtonStyles
 	TabBar        TabBarStyles
+	Tooltip       TooltipStyle
+	Popover       PanelStyle
+	DragPreview   PanelStyle
+	DropIndicator PanelStyle
+	FocusRing     FocusRingStyle
 }
 
 // ColorStyle defines the main colors used.
@@ -17,6 +17,7 @@ func NewDarkStyle() *Style {
 	// Fonts to use
 	const textFont = "fonts/FreeSans.ttf"
 	const iconFont = "fonts/MaterialIcons-Regular.ttf"
+	const boldFont = "fonts/FreeSansBold.ttf"
 	s := new(Style)
 
 	// Creates text font
@@ -35,6 +36,14 @@ func NewDarkStyle() *Style {
 	}
 	s.FontIcon = fontIcon
 
+	// Creates bold text font
+	fontBoldData := assets.MustAsset(boldFont)
+	fontBold, err := text.NewFontFromData(fontBoldData)
+	if err != nil {
+		panic(err)
+	}
+	s.FontBold = fontBold
+
 	zeroBounds := RectBounds{0, 0, 0, 0}
 	oneBounds := RectBounds{1, 1, 1, 1}
 	twoBounds := RectBounds{2, 2, 2, 2}
@@ -68,6 +77,14 @@ func NewDarkStyle() *Style {
 	s.Label.BgColor = math32.Color4{1, 1, 1, 0}
 	s.Label.FgColor = math32.Color4{1, 1, 1, 1}
 
+	// RichText style
+	s.RichText = RichTextStyle{}
+	s.RichText.FontAttributes = s.Label.FontAttributes
+	s.RichText.BgColor = math32.Color4{0, 0, 0, 0}
+	s.RichText.FgColor = s.Color.Text
+	s.RichText.LinkColor = math32.Color4{0.45, 0.65, 1, 1}
+	s.RichText.LinkOverColor = math32.Color4{0.65, 0.8, 1, 1}
+
 	// Button styles
 	s.Button = ButtonStyles{}
 	s.Button.Normal = ButtonStyle{}
@@ -398,5 +415,36 @@ func NewDarkStyle() *Style {
 	s.TabBar.Tab.Selected = s.TabBar.Tab.Normal
 	s.TabBar.Tab.Selected.BgColor = s.Color.BgOver
 
+	// Tooltip style
+	s.Tooltip = TooltipStyle{}
+	s.Tooltip.RichTextStyle = s.RichText
+	s.Tooltip.Border = oneBounds
+	s.Tooltip.Padding = RectBounds{2, 4, 2, 4}
+	s.Tooltip.BorderColor = borderColor
+	s.Tooltip.BgColor = s.Color.BgOver
+	s.Tooltip.PointSize = 12
+	s.Tooltip.MaxWidth = 300
+	s.Tooltip.OffsetX = 12
+	s.Tooltip.OffsetY = 18
+
+	// Popover style
+	s.Popover = PanelStyle{}
+	s.Popover.Border = oneBounds
+	s.Popover.Padding = twoBounds
+	s.Popover.BorderColor = borderColor
+	s.Popover.BgColor = s.Color.BgNormal
+
+	// Drag and drop styles
+	s.DragPreview = PanelStyle{}
+	s.DragPreview.Border = oneBounds
+	s.DragPreview.Padding = twoBounds
+	s.DragPreview.BorderColor = s.Color.Highlight
+	s.DragPreview.BgColor = math32.Color4{s.Color.BgOver.R, s.Color.BgOver.G, s.Color.BgOver.B, 0.7}
+	s.DropIndicator = PanelStyle{}
+	s.DropIndicator.BgColor = s.Color.Highlight
+
+	// Focus ring style
+	s.FocusRing = FocusRingStyle{Width: 2, Offset: 1, Color: s.Color.Highlight}
+
 	return s
 }
@@ -17,6 +17,7 @@ func NewLightStyle() *Style {
 	// Fonts to use
 	const fontName = "fonts/FreeSans.ttf"
 	const iconName = "fonts/MaterialIcons-Regular.ttf"
+	const boldName = "fonts/FreeSansBold.ttf"
 	s := new(Style)
 
 	// Creates text font
@@ -35,6 +36,14 @@ func NewLightStyle() *Style {
 	}
 	s.FontIcon = fontIcon
 
+	// Creates bold text font
+	fontBoldData := assets.MustAsset(boldName)
+	fontBold, err := text.NewFontFromData(fontBoldData)
+	if err != nil {
+		panic(err)
+	}
+	s.FontBold = fontBold
+
 	zeroBounds := RectBounds{0, 0, 0, 0}
 	oneBounds := RectBounds{1, 1, 1, 1}
 	twoBounds := RectBounds{2, 2, 2, 2}
@@ -62,6 +71,14 @@ func NewLightStyle() *Style {
 	s.Label.BgColor = math32.Color4{0, 0, 0, 0}
 	s.Label.FgColor = math32.Color4{0, 0, 0, 1}
 
+	// RichText style
+	s.RichText = RichTextStyle{}
+	s.RichText.FontAttributes = s.Label.FontAttributes
+	s.RichText.BgColor = math32.Color4{0, 0, 0, 0}
+	s.RichText.FgColor = fgColor
+	s.RichText.LinkColor = math32.Color4{0, 0.2, 0.8, 1}
+	s.RichText.LinkOverColor = math32.Color4{0, 0.4, 1, 1}
+
 	// Button styles
 	s.Button = ButtonStyles{}
 	s.Button.Normal = ButtonStyle{}
@@ -394,5 +411,36 @@ func NewLightStyle() *Style {
 	s.TabBar.Tab.Selected = s.TabBar.Tab.Normal
 	s.TabBar.Tab.Selected.BgColor = math32.Color4{0.85, 0.85, 0.85, 1}
 
+	// Tooltip style
+	s.Tooltip = TooltipStyle{}
+	s.Tooltip.RichTextStyle = s.RichText
+	s.Tooltip.Border = oneBounds
+	s.Tooltip.Padding = RectBounds{2, 4, 2, 4}
+	s.Tooltip.BorderColor = borderColor
+	s.Tooltip.BgColor = math32.Color4{1, 1, 0.88, 1}
+	s.Tooltip.PointSize = 12
+	s.Tooltip.MaxWidth = 300
+	s.Tooltip.OffsetX = 12
+	s.Tooltip.OffsetY = 18
+
+	// Popover style
+	s.Popover = PanelStyle{}
+	s.Popover.Border = oneBounds
+	s.Popover.Padding = twoBounds
+	s.Popover.BorderColor = borderColor
+	s.Popover.BgColor = bgColor
+
+	// Drag and drop styles
+	s.DragPreview = PanelStyle{}
+	s.DragPreview.Border = oneBounds
+	s.DragPreview.Padding = twoBounds
+	s.DragPreview.BorderColor = borderColor
+	s.DragPreview.BgColor = math32.Color4{1, 1, 1, 0.7}
+	s.DropIndicator = PanelStyle{}
+	s.DropIndicator.BgColor = math32.Color4{0, 0.4, 1, 1}
+
+	// Focus ring style
+	s.FocusRing = FocusRingStyle{Width: 2, Offset: 1, Color: math32.Color4{0, 0.4, 1, 1}}
+
 	return s
 }
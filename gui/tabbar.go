@@ -61,6 +61,8 @@ func NewTabBar(width, height float32) *TabBar {
 	// Creates new TabBar
 	tb := new(TabBar)
 	tb.Initialize(tb, width, height)
+	tb.SetFocusable(true)
+	tb.SetAccessibleRole(RoleTabList)
 	tb.styles = &StyleDefault().TabBar
 	tb.tabs = make([]*Tab, 0)
 	tb.selected = -1
@@ -86,6 +88,8 @@ func NewTabBar(width, height float32) *TabBar {
 	// Subscribe to panel events
 	tb.Subscribe(OnCursorEnter, tb.onCursor)
 	tb.Subscribe(OnCursorLeave, tb.onCursor)
+	tb.Subscribe(OnKeyDown, tb.onKey)
+	tb.Subscribe(OnKeyRepeat, tb.onKey)
 	tb.Subscribe(OnEnable, func(name string, ev interface{}) { tb.update() })
 	tb.Subscribe(OnResize, func(name string, ev interface{}) { tb.recalc() })
 
@@ -252,6 +256,29 @@ func (tb *TabBar) onCursor(evname string, ev interface{}) {
 	}
 }
 
+// onKey process subscribed key events
+func (tb *TabBar) onKey(evname string, ev interface{}) {
+
+	if len(tb.tabs) == 0 {
+		return
+	}
+	kev := ev.(*window.KeyEvent)
+	switch kev.Key {
+	case window.KeyLeft:
+		if tb.selected > 0 {
+			tb.SetSelected(tb.selected - 1)
+		}
+	case window.KeyRight:
+		if tb.selected < len(tb.tabs)-1 {
+			tb.SetSelected(tb.selected + 1)
+		}
+	case window.KeyHome:
+		tb.SetSelected(0)
+	case window.KeyEnd:
+		tb.SetSelected(len(tb.tabs) - 1)
+	}
+}
+
 // onListButtonMouse process subscribed MouseButton events over the list button
 func (tb *TabBar) onListButton(evname string, ev interface{}) {
 
@@ -406,6 +433,8 @@ func newTab(text string, tb *TabBar, styles *TabStyles) *Tab {
 	tab.styles = styles
 	// Setup the header panel
 	tab.header.Initialize(&tab.header, 0, 0)
+	tab.header.SetAccessibleRole(RoleTab)
+	tab.header.SetAccessibleName(text)
 	tab.label = NewLabel(text)
 	tab.iconClose = NewIcon(styles.IconClose)
 	tab.header.Add(tab.label)
@@ -446,6 +475,7 @@ func (tab *Tab) onMouseHeader(evname string, ev interface{}) {
 
 	if evname == OnMouseDown && ev.(*window.MouseEvent).Button == window.MouseButtonLeft {
 		tab.tb.SetSelected(tab.tb.TabPosition(tab))
+		Manager().SetKeyFocus(tab.tb)
 	}
 }
 
@@ -464,6 +494,7 @@ func (tab *Tab) onMouseIcon(evname string, ev interface{}) {
 func (tab *Tab) SetText(text string) *Tab {
 
 	tab.label.SetText(text)
+	tab.header.SetAccessibleName(text)
 	// Needs to recalculate all Tabs because this Tab width will change
 	tab.tb.recalc()
 	return tab
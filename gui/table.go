@@ -194,6 +194,8 @@ func NewTable(width, height float32, cols []TableColumn) (*Table, error) {
 
 	t := new(Table)
 	t.Panel.Initialize(t, width, height)
+	t.Panel.SetFocusable(true)
+	t.Panel.SetAccessibleRole(RoleTable)
 	t.styles = &StyleDefault().Table
 	t.rowCursor = -1
 
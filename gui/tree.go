@@ -11,8 +11,10 @@ import (
 
 // Tree is the tree structure GUI element.
 type Tree struct {
-	List               // Embedded list panel
-	styles *TreeStyles // Pointer to styles
+	List                 // Embedded list panel
+	styles   *TreeStyles // Pointer to styles
+	dragMove bool        // Items can be moved by dragging
+	dropNode *TreeNode   // Node highlighted as drop target (may be nil)
 }
 
 // TreeStyles contains the styling of all tree components for each valid GUI state.
@@ -46,6 +48,15 @@ type TreeNode struct {
 	litem    *ListItem // Reference to ListItem
 }
 
+// OnTreeMove is the identifier of the event dispatched when a tree item is moved by dragging it
+const OnTreeMove = "gui.OnTreeMove"
+
+// TreeMoveEvent is the event dispatched when a tree item is moved by dragging it.
+type TreeMoveEvent struct {
+	Item   IPanel    // Moved item
+	Parent *TreeNode // New parent node of the item (nil if it was moved to the tree root)
+}
+
 // NewTree creates and returns a pointer to a new tree widget.
 func NewTree(width, height float32) *Tree {
 
@@ -59,9 +70,84 @@ func NewTree(width, height float32) *Tree {
 func (t *Tree) Initialize(width, height float32) {
 
 	t.List.initialize(true, width, height)
+	t.List.SetAccessibleRole(RoleTree)
 	t.SetStyles(&StyleDefault().Tree)
 	t.List.Subscribe(OnKeyDown, t.onKey)
 	t.List.Subscribe(OnKeyUp, t.onKey)
+	t.List.Subscribe(OnDragEnter, t.onDrag)
+	t.List.Subscribe(OnDragOver, t.onDrag)
+	t.List.Subscribe(OnDragLeave, t.onDrag)
+	t.List.Subscribe(OnDrop, t.onDrag)
+}
+
+// SetDragMove sets whether the tree items can be moved by dragging them.
+// Items dropped over a node are appended to it and items dropped over
+// other items are inserted before them. OnTreeMove is dispatched when an item is moved.
+func (t *Tree) SetDragMove(state bool) {
+
+	t.dragMove = state
+	t.List.setDragItems(state)
+	if state {
+		t.SetDropTypes(DragListItem)
+	} else {
+		t.SetDropTypes()
+	}
+}
+
+// DragMove returns whether the tree items can be moved by dragging them.
+func (t *Tree) DragMove() bool {
+
+	return t.dragMove
+}
+
+// MoveItem moves the specified item, with all its children if it's a node,
+// into the node dst (or to the tree root if dst is nil) before the item
+// specified by before (or to the end if before is nil).
+func (t *Tree) MoveItem(item IPanel, dst *TreeNode, before IPanel) {
+
+	// Remove the item from its current parent
+	par, pos := t.FindChild(item)
+	if pos < 0 {
+		return
+	}
+	if par != nil {
+		par.Remove(item)
+	} else {
+		t.Remove(item)
+	}
+
+	// Insert the item in the destination node
+	node, isNode := item.(*TreeNode)
+	if dst != nil {
+		pos = dst.Len()
+		for i, curr := range dst.items {
+			if curr == before {
+				pos = i
+				break
+			}
+		}
+		if isNode {
+			node.parNode = dst
+		}
+		dst.InsertAt(pos, item)
+		return
+	}
+
+	// Insert the item in the tree root
+	pos = t.List.Len()
+	if before != nil {
+		if p := t.ItemPosition(before); p >= 0 {
+			pos = p
+		}
+	}
+	if isNode {
+		node.parNode = nil
+		node.update()
+		node.litem = t.List.InsertAt(pos, node)
+		node.insertItems(pos + 1)
+		return
+	}
+	t.List.InsertAt(pos, item)
 }
 
 // SetStyles sets the tree styles overriding the default style.
@@ -176,15 +262,132 @@ func (t *Tree) onKey(evname string, ev interface{}) {
 		item.GetPanel().Dispatch(evname, ev)
 		return
 	}
-	// If not enter key pressed, ignore
-	kev := ev.(*window.KeyEvent)
-	if evname != OnKeyDown || kev.Key != window.KeyEnter {
+	if evname != OnKeyDown {
 		return
 	}
+	kev := ev.(*window.KeyEvent)
+	switch kev.Key {
 	// Toggles the expansion state of the node
-	node.expanded = !node.expanded
-	node.update()
-	node.updateItems()
+	case window.KeyEnter:
+		node.SetExpanded(!node.expanded)
+	// Expands the node
+	case window.KeyRight:
+		if !node.expanded {
+			node.SetExpanded(true)
+		}
+	// Collapses the node or selects its parent node
+	case window.KeyLeft:
+		if node.expanded {
+			node.SetExpanded(false)
+		} else if node.parNode != nil && node.parNode.litem != nil {
+			t.List.setSelection(node.parNode.litem, true, true, true)
+		}
+	}
+}
+
+// onDrag receives drag and drop events over the tree when moving items
+func (t *Tree) onDrag(evname string, ev interface{}) {
+
+	if !t.dragMove {
+		return
+	}
+	dev := ev.(*DragEvent)
+	litem, ok := dev.Source.(*ListItem)
+	if !ok || litem.list != &t.List {
+		dev.Accept = false
+		t.setDropFeedback(nil, -1)
+		return
+	}
+	dst, before, over, ok := t.dropLocation(litem.item, dev.Xpos, dev.Ypos)
+	switch evname {
+	case OnDragEnter, OnDragOver:
+		if !ok {
+			dev.Accept = false
+			t.setDropFeedback(nil, -1)
+			return
+		}
+		if dst != nil && before == nil {
+			t.setDropFeedback(dst, -1)
+		} else {
+			t.setDropFeedback(nil, over)
+		}
+	case OnDragLeave:
+		t.setDropFeedback(nil, -1)
+	case OnDrop:
+		t.setDropFeedback(nil, -1)
+		if !ok {
+			dev.Accept = false
+			return
+		}
+		t.MoveItem(litem.item, dst, before)
+		if dst != nil {
+			dst.SetExpanded(true)
+		}
+		t.Dispatch(OnTreeMove, &TreeMoveEvent{Item: litem.item, Parent: dst})
+	}
+}
+
+// dropLocation returns the destination node and the item before which the specified
+// item would be inserted if dropped at the specified window coordinates, and
+// the position of the list item under the cursor (or the list length if none).
+// Returns false if the item cannot be dropped there.
+func (t *Tree) dropLocation(item IPanel, x, y float32) (*TreeNode, IPanel, int, bool) {
+
+	// Find the item under the cursor
+	over := -1
+	for i := t.first; i < len(t.items); i++ {
+		curr := t.items[i].GetPanel()
+		if !curr.Visible() {
+			break
+		}
+		if curr.InsideBorders(x, y) {
+			over = i
+			break
+		}
+	}
+	if over < 0 {
+		return nil, nil, len(t.items), true
+	}
+	target := t.ItemAt(over)
+	if target == item {
+		return nil, nil, over, false
+	}
+
+	// Dropping over a node appends the item to it, otherwise inserts it before the target
+	dst, ok := target.(*TreeNode)
+	var before IPanel
+	if !ok {
+		dst, _ = t.FindChild(target)
+		before = target
+	}
+
+	// A node cannot be moved inside itself
+	if node, ok := item.(*TreeNode); ok {
+		for par := dst; par != nil; par = par.parNode {
+			if par == node {
+				return nil, nil, over, false
+			}
+		}
+	}
+	return dst, before, over, true
+}
+
+// setDropFeedback highlights the specified node as drop target or
+// shows the drop indicator before the list item at the specified position.
+func (t *Tree) setDropFeedback(node *TreeNode, pos int) {
+
+	if t.dropNode != node {
+		if t.dropNode != nil && t.dropNode.litem != nil {
+			t.dropNode.litem.SetHighlighted(false)
+			t.dropNode.litem.update()
+		}
+		t.dropNode = node
+		if node != nil && node.litem != nil {
+			node.litem.SetHighlighted(true)
+			node.litem.update()
+		}
+	}
+	t.List.setDropMark(pos)
 }
 
 //
@@ -197,6 +400,8 @@ func newTreeNode(text string, tree *Tree, parNode *TreeNode) *TreeNode {
 
 	n := new(TreeNode)
 	n.Panel.Initialize(n, 0, 0)
+	n.Panel.SetAccessibleRole(RoleTreeItem)
+	n.Panel.SetAccessibleName(text)
 
 	// Initialize node label
 	n.label.initialize(text, StyleDefault().Font)
@@ -70,9 +70,17 @@ type WindowStyles struct {
 func NewWindow(width, height float32) *Window {
 
 	w := new(Window)
-	w.styles = &StyleDefault().Window
+	w.initialize(width, height)
+	return w
+}
+
+// initialize initializes the window with the specified dimensions.
+// It is normally used when the window is embedded in another object.
+func (w *Window) initialize(width, height float32) {
 
+	w.styles = &StyleDefault().Window
 	w.Panel.Initialize(w, width, height)
+	w.Panel.SetAccessibleRole(RoleDialog)
 	w.Panel.Subscribe(OnMouseDown, w.onMouse)
 	w.Panel.Subscribe(OnMouseUp, w.onMouse)
 	w.Panel.Subscribe(OnCursor, w.onCursor)
@@ -87,7 +95,6 @@ func NewWindow(width, height float32) *Window {
 
 	w.recalc()
 	w.update()
-	return w
 }
 
 // SetResizable sets whether the window is resizable.
@@ -105,6 +112,7 @@ func (w *Window) SetCloseButton(state bool) {
 // SetTitle sets the title of the window.
 func (w *Window) SetTitle(text string) {
 
+	w.SetAccessibleName(text)
 	if w.title == nil {
 		w.title = newWindowTitle(w, text)
 		w.title.Subscribe(OnCursor, w.onCursor)
@@ -140,8 +148,9 @@ func (w *Window) onMouse(evname string, ev interface{}) {
 	switch evname {
 	case OnMouseDown:
 		// Move the window above everything contained in its parent
-		parent := w.Parent().(IPanel).GetPanel()
-		parent.SetTopChild(w)
+		if parent, ok := w.Parent().(IPanel); ok {
+			parent.GetPanel().SetTopChild(w)
+		}
 		// If the click happened inside the draggable area, then set drag to true
 		if w.overTop || w.overRight || w.overBottom || w.overLeft {
 			w.drag = true
@@ -0,0 +1,150 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package input
+
+import (
+	"sort"
+)
+
+// ActionMap is a named set of action and axis bindings used in an input context,
+// such as gameplay, vehicle or menu. The action maps of the active contexts
+// are kept by the Mapper.
+type ActionMap struct {
+	name     string               // Context name
+	blocking bool                 // Whether the contexts below are inactive while this context is active
+	actions  map[string][]Binding // Bindings of the actions
+	axes     map[string][]Binding // Bindings of the axes
+}
+
+// NewActionMap creates and returns a pointer to a new empty action map with the specified context name.
+func NewActionMap(name string) *ActionMap {
+
+	m := new(ActionMap)
+	m.name = name
+	m.actions = make(map[string][]Binding)
+	m.axes = make(map[string][]Binding)
+	return m
+}
+
+// Name returns the context name of this action map.
+func (m *ActionMap) Name() string {
+
+	return m.name
+}
+
+// SetBlocking sets whether the action maps below this one in the Mapper
+// context stack are inactive while this action map is active.
+func (m *ActionMap) SetBlocking(blocking bool) *ActionMap {
+
+	m.blocking = blocking
+	return m
+}
+
+// Blocking returns whether the action maps below this one in the Mapper context stack are inactive.
+func (m *ActionMap) Blocking() bool {
+
+	return m.blocking
+}
+
+// BindAction adds the specified bindings to the specified action.
+func (m *ActionMap) BindAction(action string, bindings ...Binding) *ActionMap {
+
+	m.actions[action] = append(m.actions[action], bindings...)
+	return m
+}
+
+// SetActionBindings replaces the bindings of the specified action.
+func (m *ActionMap) SetActionBindings(action string, bindings ...Binding) *ActionMap {
+
+	m.actions[action] = append([]Binding(nil), bindings...)
+	return m
+}
+
+// ActionBindings returns the bindings of the specified action.
+func (m *ActionMap) ActionBindings(action string) []Binding {
+
+	return m.actions[action]
+}
+
+// RebindAction replaces the binding with the specified index of the specified action,
+// or adds the binding if the index is out of range. It is normally used with
+// the binding obtained by Mapper.Capture to let the user change the controls.
+func (m *ActionMap) RebindAction(action string, index int, b Binding) {
+
+	if index < 0 || index >= len(m.actions[action]) {
+		m.actions[action] = append(m.actions[action], b)
+		return
+	}
+	m.actions[action][index] = b
+}
+
+// RemoveAction removes the specified action and its bindings.
+func (m *ActionMap) RemoveAction(action string) {
+
+	delete(m.actions, action)
+}
+
+// Actions returns the sorted names of the actions of this action map.
+func (m *ActionMap) Actions() []string {
+
+	return sortedNames(m.actions)
+}
+
+// BindAxis adds the specified bindings to the specified axis.
+// The value of the axis is the sum of the values of its active bindings
+// multiplied by their scales, so opposite keys can be bound with scales 1 and -1.
+func (m *ActionMap) BindAxis(axis string, bindings ...Binding) *ActionMap {
+
+	m.axes[axis] = append(m.axes[axis], bindings...)
+	return m
+}
+
+// SetAxisBindings replaces the bindings of the specified axis.
+func (m *ActionMap) SetAxisBindings(axis string, bindings ...Binding) *ActionMap {
+
+	m.axes[axis] = append([]Binding(nil), bindings...)
+	return m
+}
+
+// AxisBindings returns the bindings of the specified axis.
+func (m *ActionMap) AxisBindings(axis string) []Binding {
+
+	return m.axes[axis]
+}
+
+// RebindAxis replaces the binding with the specified index of the specified axis keeping its scale,
+// or adds the binding if the index is out of range.
+func (m *ActionMap) RebindAxis(axis string, index int, b Binding) {
+
+	if index < 0 || index >= len(m.axes[axis]) {
+		m.axes[axis] = append(m.axes[axis], b)
+		return
+	}
+	b.Scale = m.axes[axis][index].Scale
+	m.axes[axis][index] = b
+}
+
+// RemoveAxis removes the specified axis and its bindings.
+func (m *ActionMap) RemoveAxis(axis string) {
+
+	delete(m.axes, axis)
+}
+
+// Axes returns the sorted names of the axes of this action map.
+func (m *ActionMap) Axes() []string {
+
+	return sortedNames(m.axes)
+}
+
+// sortedNames returns the sorted keys of the specified bindings map
+func sortedNames(bindings map[string][]Binding) []string {
+
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,411 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package input maps keyboard, mouse and gamepad inputs to named actions and axes,
+// so game code doesn't depend on the physical inputs, which can be rebound at runtime
+// and saved to and loaded from a configuration file.
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/window"
+)
+
+// Device is the kind of device of an input.
+type Device int
+
+// The possible devices.
+const (
+	Keyboard      = Device(iota + 1) // Keyboard key (Code is a window.Key)
+	Modifier                         // Modifier keys (Code is a window.ModifierKey)
+	Mouse                            // Mouse button (Code is a window.MouseButton)
+	Scroll                           // Mouse wheel (Code is 0 for the X axis and 1 for the Y axis)
+	GamepadButton                    // Gamepad button (Code is a window.GamepadButton)
+	GamepadAxis                      // Gamepad axis (Code is a window.GamepadAxis)
+)
+
+// Input is a physical input.
+// Axis inputs (Scroll and GamepadAxis) may be restricted to one half of the axis with Sign,
+// which allows them to be bound to actions, for example a trigger or a stick direction.
+type Input struct {
+	Device Device
+	Code   int
+	Sign   int // Half of the axis (1 or -1) or 0 for the full axis
+}
+
+// Binding binds an input to an action or axis.
+// The binding is active while its input and all the inputs of its chord are pressed.
+// When several bindings with the same input are active, only the ones with the largest
+// chords are, so binding Ctrl+S to an action doesn't also trigger the action bound to S.
+type Binding struct {
+	Input         // Input which triggers the binding
+	Chord []Input // Inputs which must be held with the input, usually modifiers
+	Scale float32 // Multiplies the input value for axis bindings
+}
+
+// NewBinding creates and returns a binding of the specified input and chord inputs with scale 1.
+func NewBinding(in Input, chord ...Input) Binding {
+
+	return Binding{Input: in, Chord: chord, Scale: 1}
+}
+
+// Key returns the input of the specified keyboard key.
+func Key(key window.Key) Input {
+
+	return Input{Device: Keyboard, Code: int(key)}
+}
+
+// Mod returns the input of the specified modifier keys.
+func Mod(mods window.ModifierKey) Input {
+
+	return Input{Device: Modifier, Code: int(mods)}
+}
+
+// MouseButton returns the input of the specified mouse button.
+func MouseButton(button window.MouseButton) Input {
+
+	return Input{Device: Mouse, Code: int(button)}
+}
+
+// Button returns the input of the specified gamepad button.
+func Button(button window.GamepadButton) Input {
+
+	return Input{Device: GamepadButton, Code: int(button)}
+}
+
+// Axis returns the input of the specified gamepad axis.
+// The sign restricts the input to one half of the axis if not zero.
+func Axis(axis window.GamepadAxis, sign int) Input {
+
+	return Input{Device: GamepadAxis, Code: int(axis), Sign: sign}
+}
+
+// ScrollAxis returns the input of the vertical (y true) or horizontal mouse wheel.
+// The sign restricts the input to one direction if not zero.
+func ScrollAxis(y bool, sign int) Input {
+
+	in := Input{Device: Scroll, Sign: sign}
+	if y {
+		in.Code = 1
+	}
+	return in
+}
+
+// String returns the name of the input, as accepted by ParseInput.
+func (in Input) String() string {
+
+	sign := ""
+	if in.Sign > 0 {
+		sign = "+"
+	} else if in.Sign < 0 {
+		sign = "-"
+	}
+	switch in.Device {
+	case Keyboard:
+		if name, ok := keyNames[window.Key(in.Code)]; ok {
+			return name
+		}
+		return "Key:" + strconv.Itoa(in.Code)
+	case Modifier:
+		var names []string
+		for _, m := range modNames {
+			if in.Code&int(m.mod) != 0 {
+				names = append(names, m.name)
+			}
+		}
+		return strings.Join(names, " ")
+	case Mouse:
+		for name, button := range mouseNames {
+			if int(button) == in.Code {
+				return "Mouse:" + name
+			}
+		}
+		return "Mouse:" + strconv.Itoa(in.Code)
+	case Scroll:
+		if in.Code == 0 {
+			return "Scroll:X" + sign
+		}
+		return "Scroll:Y" + sign
+	case GamepadButton:
+		if in.Code >= 0 && in.Code < len(buttonNames) {
+			return "Pad:" + buttonNames[in.Code]
+		}
+		return "Pad:" + strconv.Itoa(in.Code)
+	case GamepadAxis:
+		if in.Code >= 0 && in.Code < len(axisNames) {
+			return "PadAxis:" + axisNames[in.Code] + sign
+		}
+		return "PadAxis:" + strconv.Itoa(in.Code) + sign
+	}
+	return "Unknown"
+}
+
+// ParseInput parses the name of an input, such as "W", "Space", "Ctrl", "Mouse:Left",
+// "Scroll:Y+", "Pad:A", "PadAxis:LeftX" or "PadAxis:RightTrigger+".
+func ParseInput(s string) (Input, error) {
+
+	s = strings.TrimSpace(s)
+	prefix, name := "", s
+	if i := strings.Index(s, ":"); i >= 0 {
+		prefix, name = s[:i], s[i+1:]
+	}
+	sign := 0
+	if prefix == "Scroll" || prefix == "PadAxis" {
+		if strings.HasSuffix(name, "+") {
+			sign, name = 1, name[:len(name)-1]
+		} else if strings.HasSuffix(name, "-") {
+			sign, name = -1, name[:len(name)-1]
+		}
+	}
+	number, numErr := strconv.Atoi(name)
+	switch prefix {
+	case "":
+		if key, ok := keysByName[strings.ToLower(name)]; ok {
+			return Key(key), nil
+		}
+		for _, m := range modNames {
+			if strings.EqualFold(m.name, name) {
+				return Mod(m.mod), nil
+			}
+		}
+	case "Key":
+		if numErr == nil {
+			return Input{Device: Keyboard, Code: number}, nil
+		}
+	case "Mouse":
+		if button, ok := mouseNames[name]; ok {
+			return MouseButton(button), nil
+		}
+		if numErr == nil {
+			return Input{Device: Mouse, Code: number}, nil
+		}
+	case "Scroll":
+		switch name {
+		case "X":
+			return ScrollAxis(false, sign), nil
+		case "Y":
+			return ScrollAxis(true, sign), nil
+		}
+	case "Pad":
+		for i, n := range buttonNames {
+			if n == name {
+				return Button(window.GamepadButton(i)), nil
+			}
+		}
+		if numErr == nil {
+			return Input{Device: GamepadButton, Code: number}, nil
+		}
+	case "PadAxis":
+		for i, n := range axisNames {
+			if n == name {
+				return Axis(window.GamepadAxis(i), sign), nil
+			}
+		}
+		if numErr == nil {
+			return Input{Device: GamepadAxis, Code: number, Sign: sign}, nil
+		}
+	}
+	return Input{}, fmt.Errorf("invalid input: %q", s)
+}
+
+// String returns the text of the binding, as accepted by ParseBinding,
+// which are the names of its chord inputs and input separated by spaces.
+// The scale is not included.
+func (b Binding) String() string {
+
+	names := make([]string, 0, len(b.Chord)+1)
+	for _, in := range b.Chord {
+		names = append(names, in.String())
+	}
+	return strings.Join(append(names, b.Input.String()), " ")
+}
+
+// ParseBinding parses the text of a binding: the names of the inputs which must be
+// pressed together separated by spaces, such as "Ctrl S" or "Pad:LeftBumper Pad:A".
+// The last input triggers the binding and the others form its chord.
+func ParseBinding(s string) (Binding, error) {
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return Binding{}, fmt.Errorf("empty binding")
+	}
+	inputs := make([]Input, len(fields))
+	for i, field := range fields {
+		in, err := ParseInput(field)
+		if err != nil {
+			return Binding{}, err
+		}
+		inputs[i] = in
+	}
+	return NewBinding(inputs[len(inputs)-1], inputs[:len(inputs)-1]...), nil
+}
+
+// modNames contains the names of the modifier keys
+var modNames = []struct {
+	name string
+	mod  window.ModifierKey
+}{
+	{"Shift", window.ModShift},
+	{"Ctrl", window.ModControl},
+	{"Alt", window.ModAlt},
+	{"Super", window.ModSuper},
+}
+
+// mouseNames contains the names of the mouse buttons
+var mouseNames = map[string]window.MouseButton{
+	"Left":   window.MouseButtonLeft,
+	"Right":  window.MouseButtonRight,
+	"Middle": window.MouseButtonMiddle,
+}
+
+// buttonNames contains the names of the standard gamepad buttons
+var buttonNames = []string{
+	window.GamepadButtonA:           "A",
+	window.GamepadButtonB:           "B",
+	window.GamepadButtonX:           "X",
+	window.GamepadButtonY:           "Y",
+	window.GamepadButtonLeftBumper:  "LeftBumper",
+	window.GamepadButtonRightBumper: "RightBumper",
+	window.GamepadButtonBack:        "Back",
+	window.GamepadButtonStart:       "Start",
+	window.GamepadButtonGuide:       "Guide",
+	window.GamepadButtonLeftThumb:   "LeftThumb",
+	window.GamepadButtonRightThumb:  "RightThumb",
+	window.GamepadButtonDpadUp:      "DpadUp",
+	window.GamepadButtonDpadRight:   "DpadRight",
+	window.GamepadButtonDpadDown:    "DpadDown",
+	window.GamepadButtonDpadLeft:    "DpadLeft",
+}
+
+// axisNames contains the names of the standard gamepad axes
+var axisNames = []string{
+	window.GamepadAxisLeftX:        "LeftX",
+	window.GamepadAxisLeftY:        "LeftY",
+	window.GamepadAxisRightX:       "RightX",
+	window.GamepadAxisRightY:       "RightY",
+	window.GamepadAxisLeftTrigger:  "LeftTrigger",
+	window.GamepadAxisRightTrigger: "RightTrigger",
+}
+
+// keyNames contains the names of the keyboard keys
+var keyNames = map[window.Key]string{
+	window.KeySpace:        "Space",
+	window.KeyApostrophe:   "Apostrophe",
+	window.KeyComma:        "Comma",
+	window.KeyMinus:        "Minus",
+	window.KeyPeriod:       "Period",
+	window.KeySlash:        "Slash",
+	window.Key0:            "0",
+	window.Key1:            "1",
+	window.Key2:            "2",
+	window.Key3:            "3",
+	window.Key4:            "4",
+	window.Key5:            "5",
+	window.Key6:            "6",
+	window.Key7:            "7",
+	window.Key8:            "8",
+	window.Key9:            "9",
+	window.KeySemicolon:    "Semicolon",
+	window.KeyEqual:        "Equal",
+	window.KeyA:            "A",
+	window.KeyB:            "B",
+	window.KeyC:            "C",
+	window.KeyD:            "D",
+	window.KeyE:            "E",
+	window.KeyF:            "F",
+	window.KeyG:            "G",
+	window.KeyH:            "H",
+	window.KeyI:            "I",
+	window.KeyJ:            "J",
+	window.KeyK:            "K",
+	window.KeyL:            "L",
+	window.KeyM:            "M",
+	window.KeyN:            "N",
+	window.KeyO:            "O",
+	window.KeyP:            "P",
+	window.KeyQ:            "Q",
+	window.KeyR:            "R",
+	window.KeyS:            "S",
+	window.KeyT:            "T",
+	window.KeyU:            "U",
+	window.KeyV:            "V",
+	window.KeyW:            "W",
+	window.KeyX:            "X",
+	window.KeyY:            "Y",
+	window.KeyZ:            "Z",
+	window.KeyLeftBracket:  "LeftBracket",
+	window.KeyBackslash:    "Backslash",
+	window.KeyRightBracket: "RightBracket",
+	window.KeyGraveAccent:  "GraveAccent",
+	window.KeyEscape:       "Escape",
+	window.KeyEnter:        "Enter",
+	window.KeyTab:          "Tab",
+	window.KeyBackspace:    "Backspace",
+	window.KeyInsert:       "Insert",
+	window.KeyDelete:       "Delete",
+	window.KeyRight:        "Right",
+	window.KeyLeft:         "Left",
+	window.KeyDown:         "Down",
+	window.KeyUp:           "Up",
+	window.KeyPageUp:       "PageUp",
+	window.KeyPageDown:     "PageDown",
+	window.KeyHome:         "Home",
+	window.KeyEnd:          "End",
+	window.KeyCapsLock:     "CapsLock",
+	window.KeyScrollLock:   "ScrollLock",
+	window.KeyNumLock:      "NumLock",
+	window.KeyPrintScreen:  "PrintScreen",
+	window.KeyPause:        "Pause",
+	window.KeyF1:           "F1",
+	window.KeyF2:           "F2",
+	window.KeyF3:           "F3",
+	window.KeyF4:           "F4",
+	window.KeyF5:           "F5",
+	window.KeyF6:           "F6",
+	window.KeyF7:           "F7",
+	window.KeyF8:           "F8",
+	window.KeyF9:           "F9",
+	window.KeyF10:          "F10",
+	window.KeyF11:          "F11",
+	window.KeyF12:          "F12",
+	window.KeyKP0:          "KP0",
+	window.KeyKP1:          "KP1",
+	window.KeyKP2:          "KP2",
+	window.KeyKP3:          "KP3",
+	window.KeyKP4:          "KP4",
+	window.KeyKP5:          "KP5",
+	window.KeyKP6:          "KP6",
+	window.KeyKP7:          "KP7",
+	window.KeyKP8:          "KP8",
+	window.KeyKP9:          "KP9",
+	window.KeyKPDecimal:    "KPDecimal",
+	window.KeyKPDivide:     "KPDivide",
+	window.KeyKPMultiply:   "KPMultiply",
+	window.KeyKPSubtract:   "KPSubtract",
+	window.KeyKPAdd:        "KPAdd",
+	window.KeyKPEnter:      "KPEnter",
+	window.KeyKPEqual:      "KPEqual",
+	window.KeyLeftShift:    "LeftShift",
+	window.KeyLeftControl:  "LeftControl",
+	window.KeyLeftAlt:      "LeftAlt",
+	window.KeyLeftSuper:    "LeftSuper",
+	window.KeyRightShift:   "RightShift",
+	window.KeyRightControl: "RightControl",
+	window.KeyRightAlt:     "RightAlt",
+	window.KeyRightSuper:   "RightSuper",
+	window.KeyMenu:         "Menu",
+}
+
+// keysByName contains the keyboard keys indexed by their lower case names
+var keysByName = map[string]window.Key{}
+
+func init() {
+
+	for key, name := range keyNames {
+		keysByName[strings.ToLower(name)] = key
+	}
+}
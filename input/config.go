@@ -0,0 +1,144 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// bindingsConfig is the JSON representation of the bindings of the known action maps:
+//
+//	{
+//	  "contexts": {
+//	    "gameplay": {
+//	      "actions": {"jump": ["Space", "Pad:A"], "save": ["Ctrl S"]},
+//	      "axes": {"moveX": [{"binding": "D", "scale": 1}, {"binding": "A", "scale": -1}]}
+//	    }
+//	  }
+//	}
+type bindingsConfig struct {
+	Contexts map[string]contextConfig `json:"contexts"`
+}
+
+// contextConfig is the JSON representation of the bindings of an action map
+type contextConfig struct {
+	Actions map[string][]string       `json:"actions,omitempty"`
+	Axes    map[string][]axisBindings `json:"axes,omitempty"`
+}
+
+// axisBindings is the JSON representation of an axis binding
+type axisBindings struct {
+	Binding string  `json:"binding"`
+	Scale   float32 `json:"scale"`
+}
+
+// SaveBindings writes the bindings of the known action maps in JSON format to the specified writer.
+func (m *Mapper) SaveBindings(w io.Writer) error {
+
+	cfg := bindingsConfig{Contexts: make(map[string]contextConfig)}
+	for name, am := range m.maps {
+		cc := contextConfig{Actions: make(map[string][]string), Axes: make(map[string][]axisBindings)}
+		for action, bindings := range am.actions {
+			list := make([]string, 0, len(bindings))
+			for _, b := range bindings {
+				list = append(list, b.String())
+			}
+			cc.Actions[action] = list
+		}
+		for axis, bindings := range am.axes {
+			list := make([]axisBindings, 0, len(bindings))
+			for _, b := range bindings {
+				list = append(list, axisBindings{b.String(), b.Scale})
+			}
+			cc.Axes[axis] = list
+		}
+		cfg.Contexts[name] = cc
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&cfg)
+}
+
+// LoadBindings reads bindings in JSON format from the specified reader, replacing the bindings
+// of the actions and axes of the known action maps which are in the configuration.
+// Action maps which are not known are created and added to the known action maps.
+func (m *Mapper) LoadBindings(r io.Reader) error {
+
+	var cfg bindingsConfig
+	if err := json.NewDecoder(r).Decode(&cfg); err != nil {
+		return err
+	}
+
+	// Parses all the bindings before changing any action map
+	actions := make(map[string]map[string][]Binding)
+	axes := make(map[string]map[string][]Binding)
+	for name, cc := range cfg.Contexts {
+		actions[name] = make(map[string][]Binding)
+		for action, list := range cc.Actions {
+			for _, text := range list {
+				b, err := ParseBinding(text)
+				if err != nil {
+					return fmt.Errorf("context %q action %q: %v", name, action, err)
+				}
+				actions[name][action] = append(actions[name][action], b)
+			}
+		}
+		axes[name] = make(map[string][]Binding)
+		for axis, list := range cc.Axes {
+			for _, ab := range list {
+				b, err := ParseBinding(ab.Binding)
+				if err != nil {
+					return fmt.Errorf("context %q axis %q: %v", name, axis, err)
+				}
+				b.Scale = ab.Scale
+				axes[name][axis] = append(axes[name][axis], b)
+			}
+		}
+	}
+
+	for name := range cfg.Contexts {
+		am := m.maps[name]
+		if am == nil {
+			am = NewActionMap(name)
+			m.AddMap(am)
+		}
+		for action, bindings := range actions[name] {
+			am.SetActionBindings(action, bindings...)
+		}
+		for axis, bindings := range axes[name] {
+			am.SetAxisBindings(axis, bindings...)
+		}
+	}
+	m.update()
+	return nil
+}
+
+// SaveFile saves the bindings of the known action maps to the specified JSON file.
+func (m *Mapper) SaveFile(filename string) error {
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = m.SaveBindings(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// LoadFile loads bindings from the specified JSON file.
+func (m *Mapper) LoadFile(filename string) error {
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.LoadBindings(f)
+}
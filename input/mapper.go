@@ -0,0 +1,570 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package input
+
+import (
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/window"
+)
+
+// Mapper events
+const (
+	OnActionDown = "input.OnActionDown" // An action became active
+	OnActionUp   = "input.OnActionUp"   // An action became inactive
+)
+
+// ActionEvent describes an action event
+type ActionEvent struct {
+	Action string
+}
+
+// padInput identifies a button or axis of a gamepad
+type padInput struct {
+	gamepad int
+	code    int
+}
+
+// Mapper keeps track of the state of the inputs of a window and maps them to the
+// actions and axes of the action maps of its active contexts.
+// The contexts form a stack: the action maps are active from the top of the stack
+// down to the first blocking action map.
+// Mapper dispatches OnActionDown and OnActionUp events when the actions change state.
+type Mapper struct {
+	core.Dispatcher                       // Embedded event dispatcher
+	win             core.IDispatcher      // Window whose input events are received
+	maps            map[string]*ActionMap // Known action maps by context name
+	contexts        []*ActionMap          // Context stack
+	keys            map[window.Key]bool
+	buttons         map[window.MouseButton]bool
+	padButtons      map[padInput]bool
+	padAxes         map[padInput]float32
+	scroll          [2]float32 // Scroll amounts since the last Update
+	scrollStep      [2]float32 // Scroll amounts of the event being processed
+	gamepad         int        // Gamepad used or -1 for all gamepads
+	deadZone        float32    // Gamepad axes dead zone
+	active          map[string]bool
+	justPressed     map[string]bool
+	justReleased    map[string]bool
+	capture         func(Binding) // Function which receives the next pressed input
+	actionEv        ActionEvent
+}
+
+// NewMapper creates and returns a pointer to a new Mapper which
+// receives the input events of the specified window.
+func NewMapper(win core.IDispatcher) *Mapper {
+
+	m := new(Mapper)
+	m.Dispatcher.Initialize()
+	m.win = win
+	m.maps = make(map[string]*ActionMap)
+	m.keys = make(map[window.Key]bool)
+	m.buttons = make(map[window.MouseButton]bool)
+	m.padButtons = make(map[padInput]bool)
+	m.padAxes = make(map[padInput]float32)
+	m.gamepad = -1
+	m.deadZone = window.DefaultGamepadDeadZone
+	m.active = make(map[string]bool)
+	m.justPressed = make(map[string]bool)
+	m.justReleased = make(map[string]bool)
+
+	// Subscribe to window input events
+	m.win.SubscribeID(window.OnKeyDown, m, m.onKey)
+	m.win.SubscribeID(window.OnKeyUp, m, m.onKey)
+	m.win.SubscribeID(window.OnMouseDown, m, m.onMouse)
+	m.win.SubscribeID(window.OnMouseUp, m, m.onMouse)
+	m.win.SubscribeID(window.OnScroll, m, m.onScroll)
+	m.win.SubscribeID(window.OnGamepadButtonDown, m, m.onGamepadButton)
+	m.win.SubscribeID(window.OnGamepadButtonUp, m, m.onGamepadButton)
+	m.win.SubscribeID(window.OnGamepadAxis, m, m.onGamepadAxis)
+	m.win.SubscribeID(window.OnGamepadDisconnect, m, m.onGamepadDisconnect)
+
+	return m
+}
+
+// Dispose unsubscribes from the window events.
+func (m *Mapper) Dispose() {
+
+	m.win.UnsubscribeAllID(m)
+}
+
+// AddMap adds the specified action map to the known action maps, which are the ones
+// saved by SaveBindings, replacing a previous action map with the same name.
+// Action maps pushed as contexts are added automatically.
+func (m *Mapper) AddMap(am *ActionMap) {
+
+	m.maps[am.name] = am
+}
+
+// Map returns the known action map with the specified context name or nil if not found.
+func (m *Mapper) Map(name string) *ActionMap {
+
+	return m.maps[name]
+}
+
+// PushContext pushes the specified action map to the top of the context stack.
+func (m *Mapper) PushContext(am *ActionMap) {
+
+	m.AddMap(am)
+	m.contexts = append(m.contexts, am)
+	m.update()
+}
+
+// PopContext removes and returns the action map at the top of the context stack.
+// Returns nil if the stack is empty.
+func (m *Mapper) PopContext() *ActionMap {
+
+	if len(m.contexts) == 0 {
+		return nil
+	}
+	am := m.contexts[len(m.contexts)-1]
+	m.contexts = m.contexts[:len(m.contexts)-1]
+	m.update()
+	return am
+}
+
+// RemoveContext removes the specified action map from the context stack.
+func (m *Mapper) RemoveContext(am *ActionMap) {
+
+	for i, c := range m.contexts {
+		if c == am {
+			m.contexts = append(m.contexts[:i], m.contexts[i+1:]...)
+			m.update()
+			return
+		}
+	}
+}
+
+// Contexts returns the context stack, from bottom to top.
+func (m *Mapper) Contexts() []*ActionMap {
+
+	return m.contexts
+}
+
+// SetGamepad sets the id of the gamepad whose inputs are used,
+// or -1 (the default) to use the inputs of all gamepads.
+func (m *Mapper) SetGamepad(gamepad int) {
+
+	m.gamepad = gamepad
+	m.update()
+}
+
+// SetDeadZone sets the dead zone of the gamepad axes, from 0 to 1.
+func (m *Mapper) SetDeadZone(deadZone float32) {
+
+	m.deadZone = deadZone
+}
+
+// Pressed returns whether the specified action is active.
+func (m *Mapper) Pressed(action string) bool {
+
+	return m.active[action]
+}
+
+// JustPressed returns whether the specified action became active since the last call to Update.
+func (m *Mapper) JustPressed(action string) bool {
+
+	return m.justPressed[action]
+}
+
+// JustReleased returns whether the specified action became inactive since the last call to Update.
+func (m *Mapper) JustReleased(action string) bool {
+
+	return m.justReleased[action]
+}
+
+// Axis returns the value of the specified axis: the sum of the values of its active
+// bindings in the active contexts multiplied by their scales. Keys and buttons have value 1,
+// gamepad axes have their values with the dead zone applied and the mouse wheel has the
+// scroll amount since the last call to Update.
+func (m *Mapper) Axis(axis string) float32 {
+
+	var value float32
+	for _, am := range m.activeMaps() {
+		for _, b := range am.axes[axis] {
+			if !m.chordHeld(b) || m.shadowed(b) {
+				continue
+			}
+			v := m.value(b.Input)
+			if b.Device == Scroll {
+				v = m.scrollValue(b.Input, m.scroll)
+			}
+			value += v * b.Scale
+		}
+	}
+	return value
+}
+
+// Update should be called once per frame after the actions and axes were read.
+// It clears the actions just pressed and released and the scroll amounts.
+func (m *Mapper) Update() {
+
+	for action := range m.justPressed {
+		delete(m.justPressed, action)
+	}
+	for action := range m.justReleased {
+		delete(m.justReleased, action)
+	}
+	m.scroll = [2]float32{}
+}
+
+// Capture sets a function which receives the binding of the next pressed input,
+// with the held modifier keys as its chord, instead of the input being mapped to actions.
+// It is used to let the user rebind the controls. A nil function cancels the capture.
+func (m *Mapper) Capture(f func(b Binding)) {
+
+	m.capture = f
+}
+
+// activeMaps returns the active action maps from the top of the context stack
+func (m *Mapper) activeMaps() []*ActionMap {
+
+	for i := len(m.contexts) - 1; i >= 0; i-- {
+		if m.contexts[i].blocking {
+			return m.contexts[i:]
+		}
+	}
+	return m.contexts
+}
+
+// value returns the value of the specified input from 0 to 1, or from -1 to 1 for full axes.
+// Scroll inputs have the value of the scroll event being processed.
+func (m *Mapper) value(in Input) float32 {
+
+	switch in.Device {
+	case Keyboard:
+		if m.keys[window.Key(in.Code)] {
+			return 1
+		}
+	case Modifier:
+		if in.Code != 0 && window.ModifierKey(in.Code)&m.mods() == window.ModifierKey(in.Code) {
+			return 1
+		}
+	case Mouse:
+		if m.buttons[window.MouseButton(in.Code)] {
+			return 1
+		}
+	case Scroll:
+		return m.scrollValue(in, m.scrollStep)
+	case GamepadButton:
+		for pi, pressed := range m.padButtons {
+			if pressed && pi.code == in.Code && (m.gamepad < 0 || pi.gamepad == m.gamepad) {
+				return 1
+			}
+		}
+	case GamepadAxis:
+		var value float32
+		for pi, v := range m.padAxes {
+			if pi.code == in.Code && (m.gamepad < 0 || pi.gamepad == m.gamepad) && abs(v) > abs(value) {
+				value = v
+			}
+		}
+		value = m.applyDeadZone(value)
+		if in.Sign != 0 {
+			value *= float32(in.Sign)
+			if value < 0 {
+				value = 0
+			}
+		}
+		return value
+	}
+	return 0
+}
+
+// scrollValue returns the value of the specified scroll input from the specified scroll amounts
+func (m *Mapper) scrollValue(in Input, scroll [2]float32) float32 {
+
+	if in.Code < 0 || in.Code > 1 {
+		return 0
+	}
+	value := scroll[in.Code]
+	if in.Sign != 0 {
+		value *= float32(in.Sign)
+		if value < 0 {
+			value = 0
+		}
+	}
+	return value
+}
+
+// held returns whether the specified input is pressed
+func (m *Mapper) held(in Input) bool {
+
+	v := m.value(in)
+	if in.Device == Scroll {
+		return v != 0
+	}
+	return abs(v) >= 0.5
+}
+
+// mods returns the modifier keys currently held
+func (m *Mapper) mods() window.ModifierKey {
+
+	var mods window.ModifierKey
+	if m.keys[window.KeyLeftShift] || m.keys[window.KeyRightShift] {
+		mods |= window.ModShift
+	}
+	if m.keys[window.KeyLeftControl] || m.keys[window.KeyRightControl] {
+		mods |= window.ModControl
+	}
+	if m.keys[window.KeyLeftAlt] || m.keys[window.KeyRightAlt] {
+		mods |= window.ModAlt
+	}
+	if m.keys[window.KeyLeftSuper] || m.keys[window.KeyRightSuper] {
+		mods |= window.ModSuper
+	}
+	return mods
+}
+
+// chordHeld returns whether all the chord inputs of the specified binding are pressed
+func (m *Mapper) chordHeld(b Binding) bool {
+
+	for _, in := range b.Chord {
+		if !m.held(in) {
+			return false
+		}
+	}
+	return true
+}
+
+// shadowed returns whether another active binding of the active contexts has the same input
+// as the specified binding and a larger chord which contains the chord of the binding
+func (m *Mapper) shadowed(b Binding) bool {
+
+	check := func(bindings map[string][]Binding) bool {
+		for _, list := range bindings {
+			for _, o := range list {
+				if o.Input == b.Input && len(o.Chord) > len(b.Chord) && containsChord(o.Chord, b.Chord) && m.chordHeld(o) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, am := range m.activeMaps() {
+		if check(am.actions) || check(am.axes) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsChord returns whether the chord inputs contain all the specified inputs
+func containsChord(chord, inputs []Input) bool {
+
+	for _, in := range inputs {
+		found := false
+		for _, c := range chord {
+			if c == in {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// update evaluates the actions of the active contexts and dispatches the events of the
+// actions which changed state
+func (m *Mapper) update() {
+
+	active := make(map[string]bool)
+	for _, am := range m.activeMaps() {
+		for action, bindings := range am.actions {
+			if active[action] {
+				continue
+			}
+			for _, b := range bindings {
+				if m.held(b.Input) && m.chordHeld(b) && !m.shadowed(b) {
+					active[action] = true
+					break
+				}
+			}
+		}
+	}
+	for action := range m.active {
+		if !active[action] {
+			delete(m.active, action)
+			m.justReleased[action] = true
+			m.actionEv.Action = action
+			m.Dispatch(OnActionUp, &m.actionEv)
+		}
+	}
+	for action := range active {
+		if !m.active[action] {
+			m.active[action] = true
+			m.justPressed[action] = true
+			m.actionEv.Action = action
+			m.Dispatch(OnActionDown, &m.actionEv)
+		}
+	}
+}
+
+// captured passes the binding of the specified pressed input to the capture function if set
+// and returns whether the input was captured
+func (m *Mapper) captured(in Input) bool {
+
+	if m.capture == nil {
+		return false
+	}
+	b := NewBinding(in)
+	if mods := m.mods(); mods != 0 && !isModifierKey(in) {
+		b.Chord = []Input{Mod(mods)}
+	}
+	f := m.capture
+	m.capture = nil
+	f(b)
+	return true
+}
+
+// isModifierKey returns whether the specified input is a modifier key
+func isModifierKey(in Input) bool {
+
+	if in.Device == Modifier {
+		return true
+	}
+	if in.Device != Keyboard {
+		return false
+	}
+	switch window.Key(in.Code) {
+	case window.KeyLeftShift, window.KeyRightShift, window.KeyLeftControl, window.KeyRightControl,
+		window.KeyLeftAlt, window.KeyRightAlt, window.KeyLeftSuper, window.KeyRightSuper:
+		return true
+	}
+	return false
+}
+
+// applyDeadZone returns the gamepad axis value rescaled from the dead zone to 1
+func (m *Mapper) applyDeadZone(v float32) float32 {
+
+	if abs(v) <= m.deadZone || m.deadZone >= 1 {
+		return 0
+	}
+	if v < 0 {
+		return (v + m.deadZone) / (1 - m.deadZone)
+	}
+	return (v - m.deadZone) / (1 - m.deadZone)
+}
+
+// onKey receives window key events
+func (m *Mapper) onKey(evname string, ev interface{}) {
+
+	kev := ev.(*window.KeyEvent)
+	pressed := evname == window.OnKeyDown
+	// Modifier keys are captured when released, so they can be used as chords
+	if pressed && !isModifierKey(Key(kev.Key)) && m.captured(Key(kev.Key)) {
+		return
+	}
+	if !pressed && isModifierKey(Key(kev.Key)) && m.keys[kev.Key] {
+		m.keys[kev.Key] = false
+		if m.captured(Key(kev.Key)) {
+			return
+		}
+	}
+	m.keys[kev.Key] = pressed
+	m.update()
+}
+
+// onMouse receives window mouse button events
+func (m *Mapper) onMouse(evname string, ev interface{}) {
+
+	mev := ev.(*window.MouseEvent)
+	pressed := evname == window.OnMouseDown
+	if pressed && m.captured(MouseButton(mev.Button)) {
+		return
+	}
+	m.buttons[mev.Button] = pressed
+	m.update()
+}
+
+// onScroll receives window scroll events.
+// The actions bound to the mouse wheel are pressed and released immediately.
+func (m *Mapper) onScroll(evname string, ev interface{}) {
+
+	sev := ev.(*window.ScrollEvent)
+	if m.capture != nil {
+		y, v := true, sev.Yoffset
+		if sev.Yoffset == 0 {
+			y, v = false, sev.Xoffset
+		}
+		sign := 1
+		if v < 0 {
+			sign = -1
+		}
+		m.captured(ScrollAxis(y, sign))
+		return
+	}
+	m.scroll[0] += sev.Xoffset
+	m.scroll[1] += sev.Yoffset
+	m.scrollStep = [2]float32{sev.Xoffset, sev.Yoffset}
+	m.update()
+	m.scrollStep = [2]float32{}
+	m.update()
+}
+
+// onGamepadButton receives window gamepad button events
+func (m *Mapper) onGamepadButton(evname string, ev interface{}) {
+
+	bev := ev.(*window.GamepadButtonEvent)
+	pressed := evname == window.OnGamepadButtonDown
+	if m.gamepad >= 0 && bev.Gamepad != m.gamepad {
+		return
+	}
+	if pressed && m.captured(Button(bev.Button)) {
+		return
+	}
+	m.padButtons[padInput{bev.Gamepad, int(bev.Button)}] = pressed
+	m.update()
+}
+
+// onGamepadAxis receives window gamepad axis events
+func (m *Mapper) onGamepadAxis(evname string, ev interface{}) {
+
+	aev := ev.(*window.GamepadAxisEvent)
+	if m.gamepad >= 0 && aev.Gamepad != m.gamepad {
+		return
+	}
+	pi := padInput{aev.Gamepad, int(aev.Axis)}
+	// Axes are captured when pushed past the middle of one of their halves
+	if m.capture != nil && abs(aev.Value) >= 0.5 && abs(m.padAxes[pi]) < 0.5 {
+		sign := 1
+		if aev.Value < 0 {
+			sign = -1
+		}
+		m.padAxes[pi] = aev.Value
+		m.captured(Axis(aev.Axis, sign))
+		return
+	}
+	m.padAxes[pi] = aev.Value
+	m.update()
+}
+
+// onGamepadDisconnect receives window gamepad disconnect events and releases the gamepad inputs
+func (m *Mapper) onGamepadDisconnect(evname string, ev interface{}) {
+
+	gev := ev.(*window.GamepadEvent)
+	for pi := range m.padButtons {
+		if pi.gamepad == gev.Gamepad {
+			delete(m.padButtons, pi)
+		}
+	}
+	for pi := range m.padAxes {
+		if pi.gamepad == gev.Gamepad {
+			delete(m.padAxes, pi)
+		}
+	}
+	m.update()
+}
+
+// abs returns the absolute value of v
+func abs(v float32) float32 {
+
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,221 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package threemf
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// maxDepth is the maximum depth of the components of an object
+const maxDepth = 32
+
+// defaultColor is the color of the triangles without base material
+var defaultColor = math32.Color4{0.7, 0.7, 0.7, 1}
+
+// matKey identifies the base material of a group of triangles by its group id and index.
+// The triangles without base material have a negative group id.
+type matKey struct {
+	pid   int
+	index int
+}
+
+// NewGroup creates and returns a group containing as children the nodes
+// of the decoded build items with their transforms.
+func (dec *Decoder) NewGroup() (*core.Node, error) {
+
+	group := core.NewNode()
+	for _, item := range dec.Build {
+		node, err := dec.newObjectNode(item.ObjectID, 0)
+		if err != nil {
+			return nil, err
+		}
+		node.GetNode().SetMatrix(&item.Transform)
+		group.Add(node)
+	}
+	return group, nil
+}
+
+// newObjectNode creates and returns a mesh for a mesh object or a node
+// with the nodes of its components for a components object.
+func (dec *Decoder) newObjectNode(id int, depth int) (core.INode, error) {
+
+	obj := dec.Objects[id]
+	if obj == nil {
+		return nil, fmt.Errorf("3MF object %d not found", id)
+	}
+	if depth > maxDepth {
+		return nil, fmt.Errorf("3MF object %d has recursive components", id)
+	}
+	if obj.Mesh != nil {
+		return dec.NewMesh(obj)
+	}
+	node := core.NewNode()
+	node.SetName(obj.Name)
+	for _, comp := range obj.Components {
+		child, err := dec.newObjectNode(comp.ObjectID, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		child.GetNode().SetMatrix(&comp.Transform)
+		node.Add(child)
+	}
+	return node, nil
+}
+
+// NewMesh creates and returns a mesh from the specified mesh object with a standard
+// material for each of its base materials. Colors from color groups are set as vertex colors,
+// which are only used by materials which support them, such as the basic material.
+func (dec *Decoder) NewMesh(obj *Object) (*graphic.Mesh, error) {
+
+	geom, keys, err := dec.newGeometry(obj)
+	if err != nil {
+		return nil, err
+	}
+	newMaterial := func(key matKey) *material.Standard {
+		color := defaultColor
+		if key.pid >= 0 {
+			color = dec.Materials[key.pid][key.index].Color
+		}
+		mat := material.NewStandard(&math32.Color{color.R, color.G, color.B})
+		if color.A < 1 {
+			mat.SetOpacity(color.A)
+			mat.SetTransparent(true)
+		}
+		return mat
+	}
+
+	var mesh *graphic.Mesh
+	if len(keys) == 1 {
+		mesh = graphic.NewMesh(geom, newMaterial(keys[0]))
+	} else {
+		mesh = graphic.NewMesh(geom, nil)
+		for idx, key := range keys {
+			mesh.AddGroupMaterial(newMaterial(key), idx)
+		}
+	}
+	mesh.SetName(obj.Name)
+	return mesh, nil
+}
+
+// NewGeometry generates and returns a geometry from the specified mesh object
+// with a group for each of its base materials. The vertices are not shared
+// by the triangles, whose normals are calculated from their vertices.
+func (dec *Decoder) NewGeometry(obj *Object) (*geometry.Geometry, error) {
+
+	geom, _, err := dec.newGeometry(obj)
+	return geom, err
+}
+
+// newGeometry generates and returns a geometry from the specified mesh object
+// and the base materials of its groups.
+func (dec *Decoder) newGeometry(obj *Object) (*geometry.Geometry, []matKey, error) {
+
+	if obj.Mesh == nil {
+		return nil, nil, fmt.Errorf("3MF object %d has no mesh", obj.ID)
+	}
+
+	// Groups the triangles by base material in order of first use
+	var keys []matKey
+	groups := make(map[matKey][]int)
+	hasColors := false
+	for i := range obj.Mesh.Triangles {
+		pid, index := dec.triangleProperty(obj, &obj.Mesh.Triangles[i])
+		key := matKey{-1, 0}
+		if mats, ok := dec.Materials[pid]; ok {
+			if index >= len(mats) {
+				return nil, nil, fmt.Errorf("invalid base material index:%d in object %d", index, obj.ID)
+			}
+			key = matKey{pid, index}
+		} else if _, ok := dec.Colors[pid]; ok {
+			hasColors = true
+		}
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], i)
+	}
+	if len(keys) == 0 {
+		keys = append(keys, matKey{-1, 0})
+	}
+
+	count := len(obj.Mesh.Triangles) * 3
+	positions := math32.NewArrayF32(0, count*3)
+	normals := math32.NewArrayF32(0, count*3)
+	colors := math32.NewArrayF32(0, 0)
+	indices := math32.NewArrayU32(0, count)
+	geom := geometry.NewGeometry()
+	for idx, key := range keys {
+		geom.AddGroup(indices.Size(), len(groups[key])*3, idx)
+		for _, i := range groups[key] {
+			tri := &obj.Mesh.Triangles[i]
+			a := &obj.Mesh.Vertices[tri.V[0]]
+			b := &obj.Mesh.Vertices[tri.V[1]]
+			c := &obj.Mesh.Vertices[tri.V[2]]
+			var ab, ac math32.Vector3
+			ab.SubVectors(b, a)
+			ac.SubVectors(c, a)
+			ab.Cross(&ac).Normalize()
+			for j, v := range []*math32.Vector3{a, b, c} {
+				indices.Append(uint32(positions.Size() / 3))
+				positions.AppendVector3(v)
+				normals.AppendVector3(&ab)
+				if hasColors {
+					color := dec.vertexColor(obj, tri, j)
+					colors.Append(color.R, color.G, color.B)
+				}
+			}
+		}
+	}
+
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normals).AddAttrib(gls.VertexNormal))
+	if hasColors {
+		geom.AddVBO(gls.NewVBO(colors).AddAttrib(gls.VertexColor))
+	}
+	return geom, keys, nil
+}
+
+// triangleProperty returns the property group id and the index of the property of the first
+// vertex of the specified triangle, which are the defaults of the object if not specified.
+func (dec *Decoder) triangleProperty(obj *Object, tri *Triangle) (int, int) {
+
+	if tri.PID < 0 {
+		if tri.P[0] >= 0 {
+			return obj.PID, tri.P[0]
+		}
+		return obj.PID, obj.PIndex
+	}
+	if tri.P[0] < 0 {
+		return tri.PID, obj.PIndex
+	}
+	return tri.PID, tri.P[0]
+}
+
+// vertexColor returns the color of the specified vertex of the specified triangle.
+func (dec *Decoder) vertexColor(obj *Object, tri *Triangle, vertex int) math32.Color4 {
+
+	pid, index := dec.triangleProperty(obj, tri)
+	if colors, ok := dec.Colors[pid]; ok {
+		if tri.P[vertex] >= 0 {
+			index = tri.P[vertex]
+		}
+		if index < len(colors) {
+			return colors[index]
+		}
+		return defaultColor
+	}
+	if mats, ok := dec.Materials[pid]; ok && index < len(mats) {
+		return mats[index].Color
+	}
+	return defaultColor
+}
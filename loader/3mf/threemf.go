@@ -0,0 +1,366 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package threemf is used to parse the 3D Manufacturing Format (*.3mf), which is a zip archive
+// with a XML model of meshes, components and build items. The base materials of the core
+// specification and the color groups of the materials extension are supported.
+// The coordinates, which are in the unit of the model, are not converted.
+// Basic format info: https://3mf.io/specification/
+package threemf
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/math32"
+)
+
+// relTypeModel is the type of the relationship of the package to its 3D model part
+const relTypeModel = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"
+
+// Decoder contains all decoded data from a 3MF file
+type Decoder struct {
+	Unit      string                  // unit of the coordinates, millimeter by default
+	Metadata  map[string]string       // metadata of the model by name
+	Objects   map[int]*Object         // objects by id
+	Materials map[int][]Material      // base materials groups by id
+	Colors    map[int][]math32.Color4 // color groups by id
+	Build     []Item                  // build items
+}
+
+// Object is an object resource, which is a mesh or a list of components
+type Object struct {
+	ID         int         // object id
+	Name       string      // object name
+	Type       string      // object type: model, support, solidsupport, surface or other
+	PID        int         // id of the default property group of the triangles or -1
+	PIndex     int         // index of the default property in its group
+	Mesh       *Mesh       // mesh of the object or nil
+	Components []Component // components of the object
+}
+
+// Mesh contains the vertices and triangles of a mesh object
+type Mesh struct {
+	Vertices  []math32.Vector3 // vertices positions
+	Triangles []Triangle       // triangles
+}
+
+// Triangle is a triangle of a mesh with its vertex indices and properties
+type Triangle struct {
+	V   [3]int // vertex indices
+	PID int    // id of the property group of the triangle or -1 to use the object default
+	P   [3]int // indices of the properties of the vertices in their group or -1 if not specified
+}
+
+// Component is a reference to an object with a transform
+type Component struct {
+	ObjectID  int            // id of the object
+	Transform math32.Matrix4 // transform of the object
+}
+
+// Item is a build item, which is a reference to an object with a transform
+type Item struct {
+	ObjectID   int            // id of the object
+	Transform  math32.Matrix4 // transform of the object
+	PartNumber string         // part number of the item
+}
+
+// Material is a base material
+type Material struct {
+	Name  string        // material name
+	Color math32.Color4 // display color
+}
+
+// xmlModel is the XML document of a 3D model part
+type xmlModel struct {
+	Unit     string `xml:"unit,attr"`
+	Metadata []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"metadata"`
+	Resources struct {
+		BaseMaterials []struct {
+			ID   int `xml:"id,attr"`
+			Base []struct {
+				Name         string `xml:"name,attr"`
+				DisplayColor string `xml:"displaycolor,attr"`
+			} `xml:"base"`
+		} `xml:"basematerials"`
+		ColorGroups []struct {
+			ID     int `xml:"id,attr"`
+			Colors []struct {
+				Color string `xml:"color,attr"`
+			} `xml:"color"`
+		} `xml:"colorgroup"`
+		Objects []struct {
+			ID     int    `xml:"id,attr"`
+			Name   string `xml:"name,attr"`
+			Type   string `xml:"type,attr"`
+			PID    string `xml:"pid,attr"`
+			PIndex string `xml:"pindex,attr"`
+			Mesh   *struct {
+				Vertices []struct {
+					X float32 `xml:"x,attr"`
+					Y float32 `xml:"y,attr"`
+					Z float32 `xml:"z,attr"`
+				} `xml:"vertices>vertex"`
+				Triangles []struct {
+					V1  int    `xml:"v1,attr"`
+					V2  int    `xml:"v2,attr"`
+					V3  int    `xml:"v3,attr"`
+					PID string `xml:"pid,attr"`
+					P1  string `xml:"p1,attr"`
+					P2  string `xml:"p2,attr"`
+					P3  string `xml:"p3,attr"`
+				} `xml:"triangles>triangle"`
+			} `xml:"mesh"`
+			Components []struct {
+				ObjectID  int    `xml:"objectid,attr"`
+				Transform string `xml:"transform,attr"`
+			} `xml:"components>component"`
+		} `xml:"object"`
+	} `xml:"resources"`
+	Items []struct {
+		ObjectID   int    `xml:"objectid,attr"`
+		Transform  string `xml:"transform,attr"`
+		PartNumber string `xml:"partnumber,attr"`
+	} `xml:"build>item"`
+}
+
+// Decode decodes the specified 3MF file returning a decoder object and an error.
+func Decode(filepath string) (*Decoder, error) {
+
+	zr, err := zip.OpenReader(filepath)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return decodeZip(&zr.Reader)
+}
+
+// DecodeReader decodes the specified 3MF reader returning a decoder object and an error.
+func DecodeReader(r io.Reader) (*Decoder, error) {
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	return decodeZip(zr)
+}
+
+// decodeZip decodes the 3D model part of the specified 3MF package.
+func decodeZip(zr *zip.Reader) (*Decoder, error) {
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[strings.ToLower(strings.TrimPrefix(f.Name, "/"))] = f
+	}
+	readFile := func(name string) ([]byte, error) {
+		f, ok := files[strings.ToLower(strings.TrimPrefix(name, "/"))]
+		if !ok {
+			return nil, fmt.Errorf("3MF part %s not found", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return ioutil.ReadAll(rc)
+	}
+
+	// Finds the 3D model part from the relationships of the package
+	modelPath := "3D/3dmodel.model"
+	if data, err := readFile("_rels/.rels"); err == nil {
+		var rels struct {
+			Relationships []struct {
+				Target string `xml:"Target,attr"`
+				Type   string `xml:"Type,attr"`
+			} `xml:"Relationship"`
+		}
+		if err := xml.Unmarshal(data, &rels); err != nil {
+			return nil, err
+		}
+		for _, rel := range rels.Relationships {
+			if rel.Type == relTypeModel {
+				modelPath = path.Clean(rel.Target)
+				break
+			}
+		}
+	}
+	data, err := readFile(modelPath)
+	if err != nil {
+		return nil, err
+	}
+	var model xmlModel
+	if err := xml.Unmarshal(data, &model); err != nil {
+		return nil, err
+	}
+
+	dec := new(Decoder)
+	if err := dec.decodeModel(&model); err != nil {
+		return nil, err
+	}
+	return dec, nil
+}
+
+// decodeModel converts the specified XML model to the decoded data.
+func (dec *Decoder) decodeModel(model *xmlModel) error {
+
+	dec.Unit = model.Unit
+	if dec.Unit == "" {
+		dec.Unit = "millimeter"
+	}
+	dec.Metadata = make(map[string]string)
+	for _, md := range model.Metadata {
+		dec.Metadata[md.Name] = md.Value
+	}
+
+	// Property groups
+	dec.Materials = make(map[int][]Material)
+	for _, bm := range model.Resources.BaseMaterials {
+		var mats []Material
+		for _, base := range bm.Base {
+			color, err := parseColor(base.DisplayColor)
+			if err != nil {
+				return err
+			}
+			mats = append(mats, Material{base.Name, color})
+		}
+		dec.Materials[bm.ID] = mats
+	}
+	dec.Colors = make(map[int][]math32.Color4)
+	for _, cg := range model.Resources.ColorGroups {
+		var colors []math32.Color4
+		for _, c := range cg.Colors {
+			color, err := parseColor(c.Color)
+			if err != nil {
+				return err
+			}
+			colors = append(colors, color)
+		}
+		dec.Colors[cg.ID] = colors
+	}
+
+	// Objects
+	dec.Objects = make(map[int]*Object)
+	for _, xo := range model.Resources.Objects {
+		obj := &Object{ID: xo.ID, Name: xo.Name, Type: xo.Type, PID: parseIndex(xo.PID), PIndex: parseIndex(xo.PIndex)}
+		if obj.Type == "" {
+			obj.Type = "model"
+		}
+		if obj.PIndex < 0 {
+			obj.PIndex = 0
+		}
+		if xo.Mesh != nil {
+			obj.Mesh = new(Mesh)
+			for _, v := range xo.Mesh.Vertices {
+				obj.Mesh.Vertices = append(obj.Mesh.Vertices, math32.Vector3{X: v.X, Y: v.Y, Z: v.Z})
+			}
+			for _, t := range xo.Mesh.Triangles {
+				tri := Triangle{
+					V:   [3]int{t.V1, t.V2, t.V3},
+					PID: parseIndex(t.PID),
+					P:   [3]int{parseIndex(t.P1), parseIndex(t.P2), parseIndex(t.P3)},
+				}
+				for _, vi := range tri.V {
+					if vi < 0 || vi >= len(obj.Mesh.Vertices) {
+						return fmt.Errorf("invalid vertex index:%d in object %d", vi, obj.ID)
+					}
+				}
+				obj.Mesh.Triangles = append(obj.Mesh.Triangles, tri)
+			}
+		}
+		for _, xc := range xo.Components {
+			m, err := parseTransform(xc.Transform)
+			if err != nil {
+				return err
+			}
+			obj.Components = append(obj.Components, Component{xc.ObjectID, *m})
+		}
+		dec.Objects[obj.ID] = obj
+	}
+
+	// Build items
+	for _, xi := range model.Items {
+		m, err := parseTransform(xi.Transform)
+		if err != nil {
+			return err
+		}
+		dec.Build = append(dec.Build, Item{xi.ObjectID, *m, xi.PartNumber})
+	}
+	return nil
+}
+
+// parseIndex parses the specified optional index attribute, returning -1 if it is empty or invalid.
+func parseIndex(s string) int {
+
+	v, err := strconv.Atoi(s)
+	if err != nil || v < 0 {
+		return -1
+	}
+	return v
+}
+
+// parseColor parses a color in the #RRGGBB or #RRGGBBAA formats.
+func parseColor(s string) (math32.Color4, error) {
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) != 6 && len(s) != 8 {
+		return math32.Color4{}, fmt.Errorf("invalid 3MF color:%s", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return math32.Color4{}, fmt.Errorf("invalid 3MF color:%s", s)
+	}
+	if len(s) == 6 {
+		v = v<<8 | 0xFF
+	}
+	return math32.Color4{
+		R: float32(v>>24&0xFF) / 255,
+		G: float32(v>>16&0xFF) / 255,
+		B: float32(v>>8&0xFF) / 255,
+		A: float32(v&0xFF) / 255,
+	}, nil
+}
+
+// parseTransform parses a transform, whose 12 values are the columns of a 4x3 matrix
+// which multiplies row vectors, returning the identity matrix if it is empty.
+func parseTransform(s string) (*math32.Matrix4, error) {
+
+	m := math32.NewMatrix4()
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return m, nil
+	}
+	if len(fields) != 12 {
+		return nil, fmt.Errorf("invalid 3MF transform:%s", s)
+	}
+	var v [12]float32
+	for i, f := range fields {
+		fv, err := strconv.ParseFloat(f, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid 3MF transform:%s", s)
+		}
+		v[i] = float32(fv)
+	}
+	m.Set(
+		v[0], v[3], v[6], v[9],
+		v[1], v[4], v[7], v[10],
+		v[2], v[5], v[8], v[11],
+		0, 0, 0, 1,
+	)
+	return m, nil
+}
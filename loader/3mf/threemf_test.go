@@ -0,0 +1,212 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package threemf
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// testRels are the relationships of the test package, whose model is not at the default path
+const testRels = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Target="/3D/model.model" Id="rel0" Type="http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"/>
+</Relationships>`
+
+// testModel has a two triangles mesh with a base material per triangle, a mesh with vertex colors
+// and an object with the two meshes as components, which is built with a translation
+const testModel = `<?xml version="1.0" encoding="UTF-8"?>
+<model unit="inch" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">
+  <metadata name="Title">Test</metadata>
+  <resources>
+    <basematerials id="1">
+      <base name="Red" displaycolor="#FF0000"/>
+      <base name="Glass" displaycolor="#0000FF80"/>
+    </basematerials>
+    <m:colorgroup id="2" xmlns:m="http://schemas.microsoft.com/3dmanufacturing/material/2015/02">
+      <m:color color="#00FF00"/>
+      <m:color color="#FFFFFF"/>
+    </m:colorgroup>
+    <object id="3" name="quad" pid="1" pindex="0">
+      <mesh>
+        <vertices>
+          <vertex x="0" y="0" z="0"/>
+          <vertex x="1" y="0" z="0"/>
+          <vertex x="1" y="1" z="0"/>
+          <vertex x="0" y="1" z="0"/>
+        </vertices>
+        <triangles>
+          <triangle v1="0" v2="1" v3="2"/>
+          <triangle v1="0" v2="2" v3="3" p1="1"/>
+        </triangles>
+      </mesh>
+    </object>
+    <object id="4" name="colored">
+      <mesh>
+        <vertices>
+          <vertex x="0" y="0" z="0"/>
+          <vertex x="0" y="1" z="0"/>
+          <vertex x="0" y="0" z="1"/>
+        </vertices>
+        <triangles>
+          <triangle v1="0" v2="1" v3="2" pid="2" p1="0" p2="1" p3="0"/>
+        </triangles>
+      </mesh>
+    </object>
+    <object id="5" name="assembly">
+      <components>
+        <component objectid="3"/>
+        <component objectid="4" transform="0 1 0 -1 0 0 0 0 1 5 0 0"/>
+      </components>
+    </object>
+  </resources>
+  <build>
+    <item objectid="5" transform="1 0 0 0 1 0 0 0 1 10 20 30" partnumber="A1"/>
+  </build>
+</model>`
+
+// newPackage returns a 3MF package with the specified files.
+func newPackage(t *testing.T, files map[string]string) []byte {
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// Tests the decoding of a package with materials, colors, components and build items
+func TestDecode(t *testing.T) {
+
+	data := newPackage(t, map[string]string{"_rels/.rels": testRels, "3D/model.model": testModel})
+	dec, err := DecodeReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Unit != "inch" || dec.Metadata["Title"] != "Test" {
+		t.Errorf("unit:%q metadata:%v", dec.Unit, dec.Metadata)
+	}
+	expectedMats := []Material{{"Red", math32.Color4{1, 0, 0, 1}}, {"Glass", math32.Color4{0, 0, 1, 128.0 / 255}}}
+	if !reflect.DeepEqual(dec.Materials[1], expectedMats) {
+		t.Errorf("materials:%v", dec.Materials)
+	}
+	if !reflect.DeepEqual(dec.Colors[2], []math32.Color4{{0, 1, 0, 1}, {1, 1, 1, 1}}) {
+		t.Errorf("colors:%v", dec.Colors)
+	}
+
+	quad := dec.Objects[3]
+	if quad == nil || quad.Type != "model" || quad.PID != 1 || quad.PIndex != 0 || len(quad.Mesh.Vertices) != 4 {
+		t.Fatalf("quad:%+v", quad)
+	}
+	if tri := quad.Mesh.Triangles[1]; tri.V != [3]int{0, 2, 3} || tri.PID != -1 || tri.P != [3]int{1, -1, -1} {
+		t.Errorf("triangle:%+v", tri)
+	}
+
+	// The transforms are 4x3 matrices which multiply row vectors
+	assembly := dec.Objects[5]
+	if assembly == nil || assembly.Mesh != nil || len(assembly.Components) != 2 {
+		t.Fatalf("assembly:%+v", assembly)
+	}
+	v := math32.Vector3{1, 0, 0}
+	v.ApplyMatrix4(&assembly.Components[1].Transform)
+	if !v.Equals(&math32.Vector3{5, 1, 0}) {
+		t.Errorf("component transform applied to (1,0,0):%v", v)
+	}
+	if len(dec.Build) != 1 || dec.Build[0].ObjectID != 5 || dec.Build[0].PartNumber != "A1" {
+		t.Fatalf("build:%+v", dec.Build)
+	}
+	var pos math32.Vector3
+	pos.SetFromMatrixPosition(&dec.Build[0].Transform)
+	if !pos.Equals(&math32.Vector3{10, 20, 30}) {
+		t.Errorf("build item position:%v", pos)
+	}
+}
+
+// Tests the meshes created from the decoded objects
+func TestNewGroup(t *testing.T) {
+
+	data := newPackage(t, map[string]string{"3D/3dmodel.model": testModel})
+	dec, err := DecodeReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	group, err := dec.NewGroup()
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := group.Children()
+	if len(items) != 1 || len(items[0].Children()) != 2 {
+		t.Fatalf("unexpected group hierarchy")
+	}
+	if pos := items[0].GetNode().Position(); !pos.Equals(&math32.Vector3{10, 20, 30}) {
+		t.Errorf("item position:%v", pos)
+	}
+
+	// The quad has a group and a material for each of its base materials
+	quad := items[0].Children()[0].(*graphic.Mesh)
+	geom := quad.GetGeometry()
+	if geom.GroupCount() != 2 || len(quad.Materials()) != 2 {
+		t.Fatalf("groups:%d materials:%d", geom.GroupCount(), len(quad.Materials()))
+	}
+	if g := geom.GroupAt(1); g.Start != 3 || g.Count != 3 {
+		t.Errorf("second group:%+v", g)
+	}
+	if n := len(*geom.VBO(gls.VertexPosition).Buffer()); n != 18 {
+		t.Errorf("quad with %d position values", n)
+	}
+	var normal math32.Vector3
+	geom.VBO(gls.VertexNormal).Buffer().GetVector3(0, &normal)
+	if !normal.Equals(&math32.Vector3{0, 0, 1}) {
+		t.Errorf("normal:%v", normal)
+	}
+
+	// The colors of the color group are vertex colors
+	colored := items[0].Children()[1].(*graphic.Mesh)
+	colors := *colored.GetGeometry().VBO(gls.VertexColor).Buffer()
+	if !reflect.DeepEqual([]float32(colors), []float32{0, 1, 0, 1, 1, 1, 0, 1, 0}) {
+		t.Errorf("colors:%v", colors)
+	}
+}
+
+// Tests the errors of invalid packages
+func TestDecodeErrors(t *testing.T) {
+
+	for name, files := range map[string]map[string]string{
+		"missing model":     {"_rels/.rels": testRels},
+		"invalid color":     {"3D/3dmodel.model": `<model><resources><basematerials id="1"><base displaycolor="red"/></basematerials></resources></model>`},
+		"invalid index":     {"3D/3dmodel.model": `<model><resources><object id="1"><mesh><vertices><vertex/></vertices><triangles><triangle v1="0" v2="0" v3="1"/></triangles></mesh></object></resources></model>`},
+		"invalid transform": {"3D/3dmodel.model": `<model><build><item objectid="1" transform="1 0 0"/></build></model>`},
+	} {
+		_, err := DecodeReader(bytes.NewReader(newPackage(t, files)))
+		if err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// Recursive components
+	model := `<model><resources><object id="1"><components><component objectid="1"/></components></object></resources>` +
+		`<build><item objectid="1"/></build></model>`
+	dec, err := DecodeReader(bytes.NewReader(newPackage(t, map[string]string{"3D/3dmodel.model": model})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.NewGroup(); err == nil {
+		t.Error("expected recursive components error")
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbx
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/g3n/engine/animation"
+	"github.com/g3n/engine/math32"
+)
+
+// ticksPerSecond is the number of FBX time units per second
+const ticksPerSecond = 46186158000
+
+// curveComponents are the names of the properties of the curve nodes
+// to which the curves of each component are connected
+var curveComponents = []string{"d|X", "d|Y", "d|Z"}
+
+// curve contains the keys of an animation curve
+type curve struct {
+	times  []int64   // key times in FBX time units
+	values []float64 // key values
+}
+
+// NewAnimations creates and returns an animation for each animation stack of the file,
+// whose channels animate the translation, rotation and scaling of the nodes created by
+// the last call to NewScene. The times of the animations start at the start time of the stacks.
+// Only the first layer of each stack is used and the curves are linearly interpolated.
+func (dec *Decoder) NewAnimations() ([]*animation.Animation, error) {
+
+	if dec.models == nil {
+		return nil, fmt.Errorf("the scene must be created before the animations")
+	}
+	var anims []*animation.Animation
+	for _, stack := range dec.objects {
+		if stack.Class != "AnimationStack" {
+			continue
+		}
+		anim, err := dec.newAnimation(stack)
+		if err != nil {
+			return nil, err
+		}
+		anims = append(anims, anim)
+	}
+	return anims, nil
+}
+
+// newAnimation creates and returns the animation of the specified animation stack.
+func (dec *Decoder) newAnimation(stack *Object) (*animation.Animation, error) {
+
+	anim := animation.NewAnimation()
+	anim.SetName(stack.Name)
+	layers := stack.ChildrenOf("AnimationLayer")
+	if len(layers) == 0 {
+		return anim, nil
+	}
+	if len(layers) > 1 {
+		dec.appendWarn("only the first layer of animation %q is used", stack.Name)
+	}
+	start := stack.propInt("LocalStart", 0)
+	warned := make(map[*Object]bool)
+
+	for _, cn := range layers[0].ChildrenOf("AnimationCurveNode") {
+		// Finds the model property animated by the curve node
+		var model *Object
+		var prop string
+		for _, p := range cn.Parents {
+			if p.Object.Class == "Model" {
+				model, prop = p.Object, p.Property
+				break
+			}
+		}
+		if model == nil {
+			continue
+		}
+		in, ok := dec.models[model.ID]
+		if !ok {
+			continue
+		}
+		if prop != "Lcl Translation" && prop != "Lcl Rotation" && prop != "Lcl Scaling" {
+			dec.appendWarn("animation of property %q of %q is not supported", prop, model.Name)
+			continue
+		}
+
+		// Gets the curves of each component and their union of key times
+		var curves [3]*curve
+		var times []int64
+		for i, name := range curveComponents {
+			for _, c := range cn.Children {
+				if c.Object.Class == "AnimationCurve" && c.Property == name {
+					curves[i] = &curve{
+						times:  c.Object.Node.ChildInts("KeyTime"),
+						values: c.Object.Node.ChildFloats("KeyValueFloat"),
+					}
+					times = append(times, curves[i].times...)
+					break
+				}
+			}
+		}
+		times = uniqueTimes(times)
+		if len(times) == 0 {
+			continue
+		}
+
+		// Evaluates the curves at each key time
+		defaults := [3]float64{0, 0, 0}
+		if prop == "Lcl Scaling" {
+			defaults = [3]float64{1, 1, 1}
+		}
+		defaults = model.propVec3(prop, defaults)
+		for i := range defaults {
+			defaults[i] = cn.propFloat(curveComponents[i], defaults[i])
+		}
+		keyframes := math32.NewArrayF32(0, len(times))
+		values := math32.NewArrayF32(0, len(times)*4)
+		for _, t := range times {
+			keyframes.Append(float32(float64(t-start) / ticksPerSecond))
+			var v [3]float64
+			for i := range v {
+				v[i] = curves[i].eval(t, defaults[i])
+			}
+			switch prop {
+			case "Lcl Translation", "Lcl Scaling":
+				values.Append(float32(v[0]), float32(v[1]), float32(v[2]))
+			case "Lcl Rotation":
+				q := modelRotation(model, v)
+				values.Append(q.X, q.Y, q.Z, q.W)
+			}
+		}
+
+		var ch animation.IChannel
+		switch prop {
+		case "Lcl Translation":
+			ch = animation.NewPositionChannel(in)
+		case "Lcl Rotation":
+			ch = animation.NewRotationChannel(in)
+		case "Lcl Scaling":
+			ch = animation.NewScaleChannel(in)
+		}
+		if !warned[model] {
+			dec.warnPivots(model)
+			warned[model] = true
+		}
+		ch.SetBuffers(keyframes, values)
+		anim.AddChannel(ch)
+	}
+	return anim, nil
+}
+
+// warnPivots appends a warning if the specified animated model has pivots or offsets,
+// which are not applied by the channels of the animations.
+func (dec *Decoder) warnPivots(model *Object) {
+
+	zero := [3]float64{0, 0, 0}
+	for _, name := range []string{"RotationOffset", "RotationPivot", "ScalingOffset", "ScalingPivot"} {
+		if model.propVec3(name, zero) != zero {
+			dec.appendWarn("pivots of animated model %q are not supported", model.Name)
+			return
+		}
+	}
+}
+
+// eval returns the value of the curve at the specified time, interpolating linearly
+// between its keys, or the specified default value if the curve has no keys.
+func (c *curve) eval(t int64, def float64) float64 {
+
+	if c == nil || len(c.times) == 0 || len(c.values) < len(c.times) {
+		return def
+	}
+	idx := sort.Search(len(c.times), func(i int) bool { return c.times[i] >= t })
+	if idx == 0 {
+		return c.values[0]
+	}
+	if idx == len(c.times) {
+		return c.values[len(c.times)-1]
+	}
+	t0, t1 := c.times[idx-1], c.times[idx]
+	k := float64(t-t0) / float64(t1-t0)
+	return c.values[idx-1] + (c.values[idx]-c.values[idx-1])*k
+}
+
+// uniqueTimes returns the specified times sorted and without duplicates.
+func uniqueTimes(times []int64) []int64 {
+
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	var res []int64
+	for i, t := range times {
+		if i == 0 || t != times[i-1] {
+			res = append(res, t)
+		}
+	}
+	return res
+}
@@ -0,0 +1,263 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Types of the tokens of ASCII FBX files
+const (
+	tokenEOF    = iota // end of file
+	tokenKey           // node name followed by a colon
+	tokenString        // quoted string
+	tokenWord          // number or unquoted word
+	tokenSymbol        // one of: { } , *
+)
+
+// asciiToken is a token of an ASCII FBX file
+type asciiToken struct {
+	kind int
+	text string
+}
+
+// asciiParser parses the nodes of an ASCII FBX file
+type asciiParser struct {
+	data []byte     // file data
+	pos  int        // current position
+	line int        // current line number
+	tok  asciiToken // current token
+}
+
+// parseASCII parses the specified ASCII FBX file data
+// returning its version and its top level nodes.
+func parseASCII(data []byte) (int, []*Node, error) {
+
+	p := &asciiParser{data: data, line: 1}
+	p.next()
+	nodes, err := p.parseNodes()
+	if err != nil {
+		return 0, nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return 0, nil, p.formatError("unexpected '" + p.tok.text + "'")
+	}
+
+	// Gets the version from the header extension node
+	for _, n := range nodes {
+		if n.Name == "FBXHeaderExtension" {
+			return int(n.Child("FBXVersion").PropInt(0)), nodes, nil
+		}
+	}
+	return 0, nil, fmt.Errorf("FBX header not found")
+}
+
+// next reads the next token into the current token.
+func (p *asciiParser) next() {
+
+	// Skips blanks and comments
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '\n' {
+			p.line++
+		}
+		if c == ';' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			break
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.data) {
+		p.tok = asciiToken{tokenEOF, ""}
+		return
+	}
+
+	c := p.data[p.pos]
+	switch c {
+	case '{', '}', ',', '*':
+		p.pos++
+		p.tok = asciiToken{tokenSymbol, string(c)}
+		return
+	case '"':
+		end := p.pos + 1
+		for end < len(p.data) && p.data[end] != '"' {
+			if p.data[end] == '\n' {
+				p.line++
+			}
+			end++
+		}
+		text := string(p.data[p.pos+1 : end])
+		p.pos = end + 1
+		p.tok = asciiToken{tokenString, strings.Replace(text, "&quot;", "\"", -1)}
+		return
+	}
+
+	// Reads a word which is a key if it is followed by a colon
+	start := p.pos
+	for p.pos < len(p.data) && !strings.ContainsRune(" \t\r\n{},*\":;", rune(p.data[p.pos])) {
+		p.pos++
+	}
+	text := string(p.data[start:p.pos])
+	if p.pos < len(p.data) && p.data[p.pos] == ':' {
+		p.pos++
+		p.tok = asciiToken{tokenKey, text}
+		return
+	}
+	if text == "" {
+		p.pos++
+		p.tok = asciiToken{tokenSymbol, string(c)}
+		return
+	}
+	p.tok = asciiToken{tokenWord, text}
+}
+
+// parseNodes parses a list of nodes until the end of the file or a closing brace.
+func (p *asciiParser) parseNodes() ([]*Node, error) {
+
+	var nodes []*Node
+	for p.tok.kind == tokenKey {
+		node, err := p.parseNode()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// parseNode parses a node which starts with the current key token.
+func (p *asciiParser) parseNode() (*Node, error) {
+
+	node := &Node{Name: p.tok.text}
+	p.next()
+
+	// Reads the properties separated by commas.
+	// Leading commas are found in the embedded content of some files.
+	for p.tok.kind == tokenSymbol && p.tok.text == "," {
+		p.next()
+	}
+	for p.tok.kind == tokenString || p.tok.kind == tokenWord || p.tok.text == "*" {
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.Properties = append(node.Properties, v)
+		if p.tok.kind != tokenSymbol || p.tok.text != "," {
+			break
+		}
+		p.next()
+	}
+
+	// Reads the nested nodes
+	if p.tok.kind == tokenSymbol && p.tok.text == "{" {
+		p.next()
+		children, err := p.parseNodes()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenSymbol || p.tok.text != "}" {
+			return nil, p.formatError("expected '}'")
+		}
+		p.next()
+		node.Children = children
+	}
+	return node, nil
+}
+
+// parseValue parses a property value or an array of numbers, such as "*3 { a: 1,2,3 }".
+func (p *asciiParser) parseValue() (interface{}, error) {
+
+	tok := p.tok
+	p.next()
+	switch tok.kind {
+	case tokenString:
+		return tok.text, nil
+	case tokenWord:
+		return parseNumber(tok.text), nil
+	}
+
+	// Array of numbers
+	if p.tok.kind != tokenWord {
+		return nil, p.formatError("expected array length")
+	}
+	count, err := strconv.Atoi(p.tok.text)
+	if err != nil {
+		return nil, p.formatError("invalid array length")
+	}
+	p.next()
+	if p.tok.kind != tokenSymbol || p.tok.text != "{" {
+		return nil, p.formatError("expected '{'")
+	}
+	p.next()
+	if p.tok.kind != tokenKey || p.tok.text != "a" {
+		return nil, p.formatError("expected array values")
+	}
+	p.next()
+	values := make([]interface{}, 0, count)
+	isFloat := false
+	for p.tok.kind == tokenWord {
+		v := parseNumber(p.tok.text)
+		switch v.(type) {
+		case float64:
+			isFloat = true
+		case int64:
+		default:
+			return nil, p.formatError("invalid array value:" + p.tok.text)
+		}
+		values = append(values, v)
+		p.next()
+		if p.tok.kind == tokenSymbol && p.tok.text == "," {
+			p.next()
+		}
+	}
+	if p.tok.kind != tokenSymbol || p.tok.text != "}" {
+		return nil, p.formatError("expected '}'")
+	}
+	p.next()
+
+	// Arrays with any fractional value are arrays of floats
+	if isFloat {
+		arr := make([]float64, len(values))
+		for i, v := range values {
+			switch n := v.(type) {
+			case float64:
+				arr[i] = n
+			case int64:
+				arr[i] = float64(n)
+			}
+		}
+		return arr, nil
+	}
+	arr := make([]int64, len(values))
+	for i, v := range values {
+		arr[i] = v.(int64)
+	}
+	return arr, nil
+}
+
+// parseNumber returns the specified word as an int64 or a float64 if it is a number
+// or as a string otherwise.
+func parseNumber(text string) interface{} {
+
+	if i, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil {
+		return f
+	}
+	return text
+}
+
+func (p *asciiParser) formatError(msg string) error {
+
+	return fmt.Errorf("%s in line:%d", msg, p.line)
+}
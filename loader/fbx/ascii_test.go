@@ -0,0 +1,83 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Tests the parsing of the values, arrays, comments and nested nodes of an ASCII file
+func TestParseASCII(t *testing.T) {
+
+	data := `; FBX 7.3.0 project file
+FBXHeaderExtension:  {
+	FBXVersion: 7300
+}
+; Comment between nodes
+Values: 1, -2, 0.5, 1e3, "Model::A &quot;B&quot;", Y {
+	Floats: *4 {
+		a: 1,2.5,
+		-3,4
+	}
+	Ints: *3 {
+		a: 0,1,-4
+	}
+	Empty: *0 {
+		a:
+	}
+	Content: , "base64" ; comment after a value
+}
+`
+	version, nodes, err := parseASCII([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version != 7300 {
+		t.Errorf("version:%d", version)
+	}
+	expected := []*Node{
+		{Name: "FBXHeaderExtension", Children: []*Node{{Name: "FBXVersion", Properties: []interface{}{int64(7300)}}}},
+		{Name: "Values", Properties: []interface{}{int64(1), int64(-2), 0.5, 1000.0, `Model::A "B"`, "Y"}, Children: []*Node{
+			{Name: "Floats", Properties: []interface{}{[]float64{1, 2.5, -3, 4}}},
+			{Name: "Ints", Properties: []interface{}{[]int64{0, 1, -4}}},
+			{Name: "Empty", Properties: []interface{}{[]int64{}}},
+			{Name: "Content", Properties: []interface{}{"base64"}},
+		}},
+	}
+	if !reflect.DeepEqual(nodes, expected) {
+		t.Errorf("parsed nodes differ")
+		for _, n := range nodes {
+			t.Logf("%+v", *n)
+		}
+	}
+}
+
+// Tests the errors of invalid ASCII files
+func TestParseASCIIErrors(t *testing.T) {
+
+	header := "FBXHeaderExtension: { FBXVersion: 7400 }\n"
+	for _, data := range []string{
+		"",
+		"Objects: { }",
+		header + "Objects: {",
+		header + "Objects: { } }",
+		header + "Vertices: *x { a: 1 }",
+		header + "Vertices: *1 a: 1 }",
+		header + "Vertices: *1 { 1 }",
+		header + "Vertices: *1 { a: 1, x }",
+	} {
+		if _, _, err := parseASCII([]byte(data)); err == nil {
+			t.Errorf("expected error parsing %q", data)
+		}
+	}
+
+	// The line of the error is reported
+	_, _, err := parseASCII([]byte(header + "\nObjects: {\n\tVertices: *1 { a: x }\n}"))
+	if err == nil || !strings.Contains(err.Error(), "line:4") {
+		t.Errorf("error:%v", err)
+	}
+}
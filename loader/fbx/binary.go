@@ -0,0 +1,278 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbx
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+)
+
+// binaryMagic is the identifier at the start of binary FBX files
+const binaryMagic = "Kaydara FBX Binary  \x00"
+
+// binaryHeaderSize is the size of the header of binary FBX files,
+// which is the identifier followed by 0x1A, 0x00 and the version as uint32
+const binaryHeaderSize = 27
+
+// scalarSizes maps the type codes of the scalar properties to their sizes
+var scalarSizes = map[byte]int{'Y': 2, 'C': 1, 'I': 4, 'F': 4, 'D': 8, 'L': 8}
+
+// arraySizes maps the type codes of the array properties to the sizes of their elements
+var arraySizes = map[byte]int{'f': 4, 'd': 8, 'l': 8, 'i': 4, 'b': 1}
+
+// binaryParser parses the node records of a binary FBX file
+type binaryParser struct {
+	data    []byte // file data
+	pos     int    // current position
+	version int    // file version
+}
+
+// parseBinary parses the specified binary FBX file data
+// returning its version and its top level nodes.
+func parseBinary(data []byte) (int, []*Node, error) {
+
+	if len(data) < binaryHeaderSize {
+		return 0, nil, fmt.Errorf("invalid FBX binary header")
+	}
+	p := &binaryParser{data: data, pos: binaryHeaderSize}
+	p.version = int(binary.LittleEndian.Uint32(data[23:27]))
+
+	var nodes []*Node
+	for {
+		node, err := p.readNode(len(data))
+		if err != nil {
+			return 0, nil, err
+		}
+		// The list of top level nodes ends with a null record
+		if node == nil {
+			break
+		}
+		nodes = append(nodes, node)
+	}
+	return p.version, nodes, nil
+}
+
+// need returns an error if there are less than the specified number of bytes to read.
+func (p *binaryParser) need(size int) error {
+
+	if size < 0 || p.pos+size > len(p.data) {
+		return fmt.Errorf("unexpected end of FBX binary data at offset:%d", p.pos)
+	}
+	return nil
+}
+
+// readUint reads an unsigned integer of 32 bits, or of 64 bits since version 7.5.
+func (p *binaryParser) readUint() (uint64, error) {
+
+	if p.version >= 7500 {
+		if err := p.need(8); err != nil {
+			return 0, err
+		}
+		v := binary.LittleEndian.Uint64(p.data[p.pos:])
+		p.pos += 8
+		return v, nil
+	}
+	if err := p.need(4); err != nil {
+		return 0, err
+	}
+	v := binary.LittleEndian.Uint32(p.data[p.pos:])
+	p.pos += 4
+	return uint64(v), nil
+}
+
+// readNode reads a node record and its nested records, which must end before the specified
+// end offset of their parent record. Returns nil if the record is a null record, which ends
+// a list of records, or if the end of the data was reached.
+func (p *binaryParser) readNode(end int) (*Node, error) {
+
+	if p.pos >= len(p.data) {
+		return nil, nil
+	}
+	endOffset, err := p.readUint()
+	if err != nil {
+		return nil, err
+	}
+	numProperties, err := p.readUint()
+	if err != nil {
+		return nil, err
+	}
+	_, err = p.readUint() // length of the property list
+	if err != nil {
+		return nil, err
+	}
+	if err := p.need(1); err != nil {
+		return nil, err
+	}
+	nameLen := int(p.data[p.pos])
+	p.pos++
+	if endOffset == 0 {
+		return nil, nil
+	}
+	if endOffset > uint64(end) {
+		return nil, fmt.Errorf("invalid FBX node record end offset:%d", endOffset)
+	}
+	if err := p.need(nameLen); err != nil {
+		return nil, err
+	}
+	node := &Node{Name: string(p.data[p.pos : p.pos+nameLen])}
+	p.pos += nameLen
+
+	// Reads the properties
+	for i := uint64(0); i < numProperties; i++ {
+		v, err := p.readProperty()
+		if err != nil {
+			return nil, err
+		}
+		node.Properties = append(node.Properties, v)
+	}
+	// The end offset must be after the properties, so the parser never moves backwards
+	if int(endOffset) < p.pos {
+		return nil, fmt.Errorf("invalid FBX node record end offset:%d", endOffset)
+	}
+
+	// Reads the nested records until the end of the record
+	for p.pos < int(endOffset) {
+		child, err := p.readNode(int(endOffset))
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			break
+		}
+		node.Children = append(node.Children, child)
+	}
+	p.pos = int(endOffset)
+	return node, nil
+}
+
+// readProperty reads a property value prefixed by its type code.
+func (p *binaryParser) readProperty() (interface{}, error) {
+
+	if err := p.need(1); err != nil {
+		return nil, err
+	}
+	code := p.data[p.pos]
+	p.pos++
+
+	// Scalar values
+	if size, ok := scalarSizes[code]; ok {
+		if err := p.need(size); err != nil {
+			return nil, err
+		}
+		b := p.data[p.pos : p.pos+size]
+		p.pos += size
+		switch code {
+		case 'Y':
+			return int16(binary.LittleEndian.Uint16(b)), nil
+		case 'C':
+			return b[0] != 0, nil
+		case 'I':
+			return int32(binary.LittleEndian.Uint32(b)), nil
+		case 'F':
+			return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+		case 'D':
+			return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+		default:
+			return int64(binary.LittleEndian.Uint64(b)), nil
+		}
+	}
+
+	switch code {
+	// Strings and raw data
+	case 'S', 'R':
+		if err := p.need(4); err != nil {
+			return nil, err
+		}
+		length := int(binary.LittleEndian.Uint32(p.data[p.pos:]))
+		p.pos += 4
+		if err := p.need(length); err != nil {
+			return nil, err
+		}
+		b := p.data[p.pos : p.pos+length]
+		p.pos += length
+		if code == 'S' {
+			return string(b), nil
+		}
+		return b, nil
+	// Arrays
+	case 'f', 'd', 'l', 'i', 'b':
+		return p.readArray(code)
+	}
+	return nil, fmt.Errorf("invalid FBX property type:%q at offset:%d", code, p.pos-1)
+}
+
+// readArray reads an array property, which may be compressed with zlib.
+func (p *binaryParser) readArray(code byte) (interface{}, error) {
+
+	if err := p.need(12); err != nil {
+		return nil, err
+	}
+	count := int(binary.LittleEndian.Uint32(p.data[p.pos:]))
+	encoding := binary.LittleEndian.Uint32(p.data[p.pos+4:])
+	length := int(binary.LittleEndian.Uint32(p.data[p.pos+8:]))
+	p.pos += 12
+	if err := p.need(length); err != nil {
+		return nil, err
+	}
+	b := p.data[p.pos : p.pos+length]
+	p.pos += length
+
+	switch encoding {
+	case 0:
+	case 1:
+		zr, err := zlib.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		// Decompresses at most the size of the array
+		b, err = ioutil.ReadAll(io.LimitReader(zr, int64(count)*int64(arraySizes[code])))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid FBX array encoding:%d", encoding)
+	}
+
+	if len(b) < count*arraySizes[code] {
+		return nil, fmt.Errorf("invalid FBX array length")
+	}
+	switch code {
+	case 'f':
+		arr := make([]float32, count)
+		for i := range arr {
+			arr[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[i*4:]))
+		}
+		return arr, nil
+	case 'd':
+		arr := make([]float64, count)
+		for i := range arr {
+			arr[i] = math.Float64frombits(binary.LittleEndian.Uint64(b[i*8:]))
+		}
+		return arr, nil
+	case 'l':
+		arr := make([]int64, count)
+		for i := range arr {
+			arr[i] = int64(binary.LittleEndian.Uint64(b[i*8:]))
+		}
+		return arr, nil
+	case 'i':
+		arr := make([]int32, count)
+		for i := range arr {
+			arr[i] = int32(binary.LittleEndian.Uint32(b[i*4:]))
+		}
+		return arr, nil
+	default:
+		arr := make([]bool, count)
+		for i := range arr {
+			arr[i] = b[i] != 0
+		}
+		return arr, nil
+	}
+}
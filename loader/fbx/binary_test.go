@@ -0,0 +1,233 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbx
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// binaryWriter writes nodes as the records of a binary FBX file
+type binaryWriter struct {
+	buf      bytes.Buffer
+	version  int
+	compress bool // compress the arrays with zlib
+}
+
+// encodeBinary returns a binary FBX file with the specified version and top level nodes.
+func encodeBinary(version int, compress bool, nodes []*Node) []byte {
+
+	w := &binaryWriter{version: version, compress: compress}
+	w.buf.WriteString(binaryMagic)
+	w.buf.Write([]byte{0x1A, 0})
+	binary.Write(&w.buf, binary.LittleEndian, uint32(version))
+	for _, n := range nodes {
+		w.writeNode(n)
+	}
+	w.writeNull()
+	return w.buf.Bytes()
+}
+
+// uintSize returns the size of the unsigned integers of the node records.
+func (w *binaryWriter) uintSize() int {
+
+	if w.version >= 7500 {
+		return 8
+	}
+	return 4
+}
+
+// putUint writes the specified unsigned integer at the specified offset.
+func (w *binaryWriter) putUint(offset int, v int) {
+
+	if w.version >= 7500 {
+		binary.LittleEndian.PutUint64(w.buf.Bytes()[offset:], uint64(v))
+	} else {
+		binary.LittleEndian.PutUint32(w.buf.Bytes()[offset:], uint32(v))
+	}
+}
+
+// writeNull writes a null record.
+func (w *binaryWriter) writeNull() {
+
+	w.buf.Write(make([]byte, 3*w.uintSize()+1))
+}
+
+// writeNode writes the record of the specified node and its nested records.
+func (w *binaryWriter) writeNode(n *Node) {
+
+	start := w.buf.Len()
+	w.writeNull()
+	w.buf.Bytes()[start+3*w.uintSize()] = byte(len(n.Name))
+	w.buf.WriteString(n.Name)
+	propStart := w.buf.Len()
+	for _, v := range n.Properties {
+		w.writeProperty(v)
+	}
+	propLen := w.buf.Len() - propStart
+	for _, c := range n.Children {
+		w.writeNode(c)
+	}
+	if len(n.Children) > 0 {
+		w.writeNull()
+	}
+	w.putUint(start, w.buf.Len())
+	w.putUint(start+w.uintSize(), len(n.Properties))
+	w.putUint(start+2*w.uintSize(), propLen)
+}
+
+// writeProperty writes the specified property value prefixed by its type code.
+func (w *binaryWriter) writeProperty(v interface{}) {
+
+	le := binary.LittleEndian
+	switch v := v.(type) {
+	case bool:
+		w.buf.WriteByte('C')
+		binary.Write(&w.buf, le, v)
+	case int16:
+		w.buf.WriteByte('Y')
+		binary.Write(&w.buf, le, v)
+	case int32:
+		w.buf.WriteByte('I')
+		binary.Write(&w.buf, le, v)
+	case int64:
+		w.buf.WriteByte('L')
+		binary.Write(&w.buf, le, v)
+	case float32:
+		w.buf.WriteByte('F')
+		binary.Write(&w.buf, le, v)
+	case float64:
+		w.buf.WriteByte('D')
+		binary.Write(&w.buf, le, v)
+	case string:
+		w.buf.WriteByte('S')
+		binary.Write(&w.buf, le, uint32(len(v)))
+		w.buf.WriteString(v)
+	case []byte:
+		w.buf.WriteByte('R')
+		binary.Write(&w.buf, le, uint32(len(v)))
+		w.buf.Write(v)
+	case []float32:
+		w.writeArray('f', len(v), v)
+	case []float64:
+		w.writeArray('d', len(v), v)
+	case []int64:
+		w.writeArray('l', len(v), v)
+	case []int32:
+		w.writeArray('i', len(v), v)
+	case []bool:
+		w.writeArray('b', len(v), v)
+	default:
+		panic("invalid property type")
+	}
+}
+
+// writeArray writes the specified array property.
+func (w *binaryWriter) writeArray(code byte, count int, values interface{}) {
+
+	var data bytes.Buffer
+	binary.Write(&data, binary.LittleEndian, values)
+	encoding := uint32(0)
+	if w.compress {
+		var zdata bytes.Buffer
+		zw := zlib.NewWriter(&zdata)
+		zw.Write(data.Bytes())
+		zw.Close()
+		data = zdata
+		encoding = 1
+	}
+	w.buf.WriteByte(code)
+	binary.Write(&w.buf, binary.LittleEndian, []uint32{uint32(count), encoding, uint32(data.Len())})
+	w.buf.Write(data.Bytes())
+}
+
+// Tests the parsing of the properties of all types and of nested records
+// with 32 and 64 bits offsets and with compressed and uncompressed arrays
+func TestParseBinary(t *testing.T) {
+
+	nodes := []*Node{
+		{Name: "Scalars", Properties: []interface{}{
+			true, int16(-2), int32(-70000), int64(1) << 40, float32(0.5), float64(-1.25), "name\x00\x01Class", []byte{1, 2, 3},
+		}},
+		{Name: "Arrays", Properties: []interface{}{
+			[]float32{1, -2.5}, []float64{0.125, 3}, []int64{-1, 1 << 40}, []int32{0, 1, -4}, []bool{true, false, true},
+		}, Children: []*Node{
+			{Name: "Empty"},
+			{Name: "Nested", Properties: []interface{}{int32(1)}, Children: []*Node{{Name: "Leaf", Properties: []interface{}{"x"}}}},
+		}},
+	}
+	for _, version := range []int{7400, 7500} {
+		for _, compress := range []bool{false, true} {
+			v, parsed, err := parseBinary(encodeBinary(version, compress, nodes))
+			if err != nil {
+				t.Fatalf("version:%d compress:%v %v", version, compress, err)
+			}
+			if v != version {
+				t.Errorf("version:%d parsed as:%d", version, v)
+			}
+			if !reflect.DeepEqual(parsed, nodes) {
+				t.Errorf("version:%d compress:%v parsed nodes differ", version, compress)
+			}
+		}
+	}
+}
+
+// Tests the errors of invalid node records and properties
+func TestParseBinaryErrors(t *testing.T) {
+
+	// The record of A starts at the end of the header and its properties after
+	// the 3 unsigned integers, the length of its name and its name
+	const recordA = binaryHeaderSize
+	const propsA = recordA + 3*4 + 1 + 1
+	nodes := []*Node{{Name: "A", Properties: []interface{}{int32(1)}, Children: []*Node{{Name: "B"}}}}
+	valid := encodeBinary(7400, false, nodes)
+	const recordB = propsA + 5
+	endA := int(binary.LittleEndian.Uint32(valid[recordA:]))
+
+	modify := func(offset int, v uint32) []byte {
+		data := append([]byte{}, valid...)
+		binary.LittleEndian.PutUint32(data[offset:], v)
+		return data
+	}
+	for name, data := range map[string][]byte{
+		"end offset before the record":         modify(recordA, recordA),
+		"end offset before the properties end": modify(recordA, propsA+1),
+		"end offset after the data":            modify(recordA, uint32(len(valid)+1)),
+		"child ends after its parent":          modify(recordB, uint32(endA+1)),
+		"too many properties":                  modify(recordA+4, 1000),
+		"invalid property type":                append(append(append([]byte{}, valid[:propsA]...), 'X'), valid[propsA+1:]...),
+	} {
+		if _, _, err := parseBinary(data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	// The data is truncated
+	for size := 0; size < len(valid)-1; size++ {
+		parseBinary(valid[:size])
+	}
+	if _, _, err := parseBinary(valid[:propsA+2]); err == nil {
+		t.Error("truncated property: expected error")
+	}
+
+	// Compressed array with less values than its count and with an invalid encoding
+	arrays := encodeBinary(7400, true, []*Node{{Name: "A", Properties: []interface{}{[]int32{1, 2, 3}}}})
+	if _, _, err := parseBinary(arrays); err != nil {
+		t.Fatal(err)
+	}
+	data := append([]byte{}, arrays...)
+	binary.LittleEndian.PutUint32(data[propsA+1:], 4)
+	if _, _, err := parseBinary(data); err == nil {
+		t.Error("short array: expected error")
+	}
+	data = append([]byte{}, arrays...)
+	binary.LittleEndian.PutUint32(data[propsA+5:], 2)
+	if _, _, err := parseBinary(data); err == nil {
+		t.Error("invalid array encoding: expected error")
+	}
+}
@@ -0,0 +1,431 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fbx is used to parse the Autodesk FBX file format (*.fbx), in its binary
+// and ASCII encodings of version 7 and later, and to create g3n meshes, skeletons
+// and animations from the decoded scene. Not all features of the FBX format are supported.
+package fbx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/texture"
+)
+
+// Decoder contains all decoded data from a FBX file
+type Decoder struct {
+	Version   int                          // FBX version, such as 7400 for FBX 7.4
+	Nodes     []*Node                      // top level nodes of the document
+	Objects   map[int64]*Object            // scene objects by id
+	Warnings  []string                     // warning messages
+	objects   []*Object                    // scene objects in document order
+	dirImages string                       // directory of the texture images
+	models    map[int64]core.INode         // scene nodes created for the models by id
+	materials map[int64]material.IMaterial // materials created by id
+	textures  map[int64]*texture.Texture2D // textures created by id
+	skins     map[core.INode]*Object       // skin deformers of the rigged meshes
+}
+
+// Node is a node of the FBX document tree.
+// The values of the properties are of the types bool, int16, int32, int64, float32,
+// float64, string, []byte or slices of bool, int32, int64, float32 and float64.
+type Node struct {
+	Name       string        // node name
+	Properties []interface{} // node property values
+	Children   []*Node       // nested nodes
+}
+
+// Object is an object of the FBX scene, such as a model, a geometry or a material,
+// with the objects connected to it.
+type Object struct {
+	ID       int64        // object id
+	Name     string       // object name
+	Class    string       // object class, such as "Model" or "Geometry"
+	Type     string       // object sub class, such as "Mesh" or "LimbNode"
+	Node     *Node        // document node of the object
+	Children []Connection // objects connected to this object
+	Parents  []Connection // objects this object is connected to
+}
+
+// Connection is a connection between two objects of the FBX scene,
+// which may be connected to a property of the parent object.
+type Connection struct {
+	Object   *Object // connected object
+	Property string  // name of the connected property of the parent object, if any
+}
+
+// Decode decodes the specified FBX file returning a decoder object and an error.
+// The images of the textures which are not embedded in the file are searched
+// relative to the directory of the file.
+func Decode(fbxpath string) (*Decoder, error) {
+
+	// Opens file
+	f, err := os.Open(fbxpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec, err := DecodeReader(f)
+	if err != nil {
+		return nil, err
+	}
+	dec.dirImages = filepath.Dir(fbxpath)
+	return dec, nil
+}
+
+// DecodeReader decodes the specified FBX reader, in binary or ASCII encoding,
+// returning a decoder object and an error.
+func DecodeReader(r io.Reader) (*Decoder, error) {
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := new(Decoder)
+	if bytes.HasPrefix(data, []byte(binaryMagic)) {
+		dec.Version, dec.Nodes, err = parseBinary(data)
+	} else {
+		dec.Version, dec.Nodes, err = parseASCII(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dec.Version < 7000 {
+		return nil, fmt.Errorf("unsupported FBX version:%d", dec.Version)
+	}
+	err = dec.decodeObjects()
+	if err != nil {
+		return nil, err
+	}
+	return dec, nil
+}
+
+// SetDirImages sets the directory where the images of the textures which are not
+// embedded in the file are searched.
+func (dec *Decoder) SetDirImages(path string) {
+
+	dec.dirImages = path
+}
+
+// Node returns the first top level node with the specified name or nil if not found.
+func (dec *Decoder) Node(name string) *Node {
+
+	for _, n := range dec.Nodes {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+// decodeObjects creates the scene objects from the "Objects" node
+// and connects them as described by the "Connections" node.
+func (dec *Decoder) decodeObjects() error {
+
+	dec.Objects = make(map[int64]*Object)
+	// The root of the scene has the id 0
+	dec.Objects[0] = &Object{Name: "RootNode", Class: "Model"}
+	if objects := dec.Node("Objects"); objects != nil {
+		for _, n := range objects.Children {
+			if len(n.Properties) < 3 {
+				continue
+			}
+			o := &Object{
+				ID:    n.PropInt(0),
+				Name:  objectName(n.PropString(1)),
+				Class: n.Name,
+				Type:  n.PropString(2),
+				Node:  n,
+			}
+			dec.Objects[o.ID] = o
+			dec.objects = append(dec.objects, o)
+		}
+	}
+
+	connections := dec.Node("Connections")
+	if connections == nil {
+		return nil
+	}
+	for _, c := range connections.Children {
+		if c.Name != "C" || len(c.Properties) < 3 {
+			continue
+		}
+		child := dec.Objects[c.PropInt(1)]
+		parent := dec.Objects[c.PropInt(2)]
+		if child == nil || parent == nil {
+			continue
+		}
+		prop := ""
+		if c.PropString(0) == "OP" {
+			prop = c.PropString(3)
+		}
+		parent.Children = append(parent.Children, Connection{child, prop})
+		child.Parents = append(child.Parents, Connection{parent, prop})
+	}
+	return nil
+}
+
+// objectName returns the name of an object without its class, which is
+// encoded as "Name\x00\x01Class" in binary files and "Class::Name" in ASCII files.
+func objectName(name string) string {
+
+	if idx := strings.Index(name, "\x00\x01"); idx >= 0 {
+		return name[:idx]
+	}
+	if idx := strings.Index(name, "::"); idx >= 0 {
+		return name[idx+2:]
+	}
+	return name
+}
+
+// Child returns the first child of the node with the specified name or nil if not found.
+func (n *Node) Child(name string) *Node {
+
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// PropInt returns the value of the property with the specified index as an integer.
+func (n *Node) PropInt(idx int) int64 {
+
+	if n == nil || idx >= len(n.Properties) {
+		return 0
+	}
+	switch v := n.Properties[idx].(type) {
+	case bool:
+		if v {
+			return 1
+		}
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case float32:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// PropFloat returns the value of the property with the specified index as a float.
+func (n *Node) PropFloat(idx int) float64 {
+
+	if n == nil || idx >= len(n.Properties) {
+		return 0
+	}
+	switch v := n.Properties[idx].(type) {
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return float64(n.PropInt(idx))
+}
+
+// PropString returns the value of the property with the specified index as a string.
+func (n *Node) PropString(idx int) string {
+
+	if n == nil || idx >= len(n.Properties) {
+		return ""
+	}
+	switch v := n.Properties[idx].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// PropBytes returns the value of the property with the specified index as raw data.
+func (n *Node) PropBytes(idx int) []byte {
+
+	if n == nil || idx >= len(n.Properties) {
+		return nil
+	}
+	switch v := n.Properties[idx].(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
+
+// PropFloats returns the value of the array property with the specified index as floats.
+func (n *Node) PropFloats(idx int) []float64 {
+
+	if n == nil || idx >= len(n.Properties) {
+		return nil
+	}
+	var res []float64
+	switch v := n.Properties[idx].(type) {
+	case []float64:
+		return v
+	case []float32:
+		res = make([]float64, len(v))
+		for i := range v {
+			res[i] = float64(v[i])
+		}
+	case []int32:
+		res = make([]float64, len(v))
+		for i := range v {
+			res[i] = float64(v[i])
+		}
+	case []int64:
+		res = make([]float64, len(v))
+		for i := range v {
+			res[i] = float64(v[i])
+		}
+	}
+	return res
+}
+
+// PropInts returns the value of the array property with the specified index as integers.
+func (n *Node) PropInts(idx int) []int64 {
+
+	if n == nil || idx >= len(n.Properties) {
+		return nil
+	}
+	var res []int64
+	switch v := n.Properties[idx].(type) {
+	case []int64:
+		return v
+	case []int32:
+		res = make([]int64, len(v))
+		for i := range v {
+			res[i] = int64(v[i])
+		}
+	case []float64:
+		res = make([]int64, len(v))
+		for i := range v {
+			res[i] = int64(v[i])
+		}
+	case []float32:
+		res = make([]int64, len(v))
+		for i := range v {
+			res[i] = int64(v[i])
+		}
+	}
+	return res
+}
+
+// ChildFloats returns the floats of the array property of the child with the specified name.
+func (n *Node) ChildFloats(name string) []float64 {
+
+	return n.Child(name).PropFloats(0)
+}
+
+// ChildInts returns the integers of the array property of the child with the specified name.
+func (n *Node) ChildInts(name string) []int64 {
+
+	return n.Child(name).PropInts(0)
+}
+
+// ChildString returns the string property of the child with the specified name.
+func (n *Node) ChildString(name string) string {
+
+	return n.Child(name).PropString(0)
+}
+
+// Property returns the node of the property with the specified name in the
+// "Properties70" node of the object or nil if not found.
+// The values of a property node start at its property with index 4.
+func (o *Object) Property(name string) *Node {
+
+	props := o.Node.Child("Properties70")
+	if props == nil {
+		return nil
+	}
+	for _, p := range props.Children {
+		if p.PropString(0) == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// propFloat returns the value of the specified float property of the object or
+// the specified default value if the property is not found.
+func (o *Object) propFloat(name string, def float64) float64 {
+
+	p := o.Property(name)
+	if p == nil || len(p.Properties) < 5 {
+		return def
+	}
+	return p.PropFloat(4)
+}
+
+// propInt returns the value of the specified integer property of the object or
+// the specified default value if the property is not found.
+func (o *Object) propInt(name string, def int64) int64 {
+
+	p := o.Property(name)
+	if p == nil || len(p.Properties) < 5 {
+		return def
+	}
+	return p.PropInt(4)
+}
+
+// propVec3 returns the value of the specified vector property of the object or
+// the specified default value if the property is not found.
+func (o *Object) propVec3(name string, def [3]float64) [3]float64 {
+
+	p := o.Property(name)
+	if p == nil || len(p.Properties) < 7 {
+		return def
+	}
+	return [3]float64{p.PropFloat(4), p.PropFloat(5), p.PropFloat(6)}
+}
+
+// Child returns the first object of the specified class connected to the object or nil if not found.
+func (o *Object) Child(class string) *Object {
+
+	for _, c := range o.Children {
+		if c.Object.Class == class {
+			return c.Object
+		}
+	}
+	return nil
+}
+
+// ChildrenOf returns the objects of the specified class connected to the object.
+func (o *Object) ChildrenOf(class string) []*Object {
+
+	var res []*Object
+	for _, c := range o.Children {
+		if c.Object.Class == class {
+			res = append(res, c.Object)
+		}
+	}
+	return res
+}
+
+// appendWarn appends a warning message to the decoder warnings.
+func (dec *Decoder) appendWarn(format string, v ...interface{}) {
+
+	msg := fmt.Sprintf(format, v...)
+	log.Warn("%s", msg)
+	dec.Warnings = append(dec.Warnings, msg)
+}
@@ -0,0 +1,119 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbx
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// quadScene is an ASCII file with a translated model of a quad
+const quadScene = `; FBX 7.4.0 project file
+FBXHeaderExtension:  {
+	FBXHeaderVersion: 1003
+	FBXVersion: 7400
+}
+Objects:  {
+	Geometry: 10, "Geometry::Quad", "Mesh" {
+		Vertices: *12 {
+			a: 0,0,0,1,0,0,1,1,0,0,1,0
+		}
+		PolygonVertexIndex: *4 {
+			a: 0,1,2,-4
+		}
+	}
+	Model: 20, "Model::Quad", "Mesh" {
+		Properties70:  {
+			P: "Lcl Translation", "Lcl Translation", "", "A",1,2,3
+		}
+	}
+}
+Connections:  {
+	C: "OO",10,20
+	C: "OO",20,0
+}
+`
+
+// Tests that the ASCII file and its binary encoding are decoded as the same scene
+func TestDecodeScene(t *testing.T) {
+
+	_, nodes, err := parseASCII([]byte(quadScene))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		"ascii":  []byte(quadScene),
+		"binary": encodeBinary(7400, true, nodes),
+	}
+	for name, data := range files {
+		dec, err := DecodeReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if dec.Version != 7400 || !reflect.DeepEqual(dec.Nodes, nodes) {
+			t.Errorf("%s: version:%d", name, dec.Version)
+		}
+
+		// Objects and connections
+		geomObj, model := dec.Objects[10], dec.Objects[20]
+		if geomObj == nil || geomObj.Name != "Quad" || geomObj.Class != "Geometry" || geomObj.Type != "Mesh" {
+			t.Fatalf("%s: geometry object:%+v", name, geomObj)
+		}
+		if model == nil || model.Child("Geometry") != geomObj || model.Parents[0].Object != dec.Objects[0] {
+			t.Fatalf("%s: model object:%+v", name, model)
+		}
+
+		// The quad is triangulated as a fan and its normals are calculated
+		geom, err := dec.NewGeometry(geomObj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		positions := *geom.VBO(gls.VertexPosition).Buffer()
+		if !reflect.DeepEqual([]float32(positions), []float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0}) {
+			t.Errorf("%s: positions:%v", name, positions)
+		}
+		if indices := geom.Indices(); !reflect.DeepEqual(indices.ToUint32(), []uint32{0, 1, 2, 0, 2, 3}) {
+			t.Errorf("%s: indices:%v", name, indices)
+		}
+		var normal math32.Vector3
+		geom.VBO(gls.VertexNormal).Buffer().GetVector3(9, &normal)
+		if !normal.Equals(&math32.Vector3{0, 0, 1}) {
+			t.Errorf("%s: normal:%v", name, normal)
+		}
+
+		// The scene has the model node with its translation
+		scene, err := dec.NewScene()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(scene.Children()) != 1 || dec.ModelNode(20) != scene.Children()[0] {
+			t.Fatalf("%s: unexpected scene hierarchy", name)
+		}
+		if pos := dec.ModelNode(20).GetNode().Position(); !pos.Equals(&math32.Vector3{1, 2, 3}) {
+			t.Errorf("%s: model position:%v", name, pos)
+		}
+	}
+}
+
+// Tests the errors of invalid files
+func TestDecodeErrors(t *testing.T) {
+
+	old := strings.Replace(quadScene, "FBXVersion: 7400", "FBXVersion: 6100", 1)
+	if _, err := DecodeReader(strings.NewReader(old)); err == nil {
+		t.Error("expected unsupported version error")
+	}
+	dec, err := DecodeReader(strings.NewReader(strings.Replace(quadScene, "0,1,2,-4", "0,1,7,-4", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.NewGeometry(dec.Objects[10]); err == nil {
+		t.Error("expected invalid vertex index error")
+	}
+}
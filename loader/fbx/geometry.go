@@ -0,0 +1,362 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbx
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// maxBoneInfluencers is the maximum number of bones which influence a vertex
+const maxBoneInfluencers = 4
+
+// layerElement contains the data of a layer element of a geometry, such as its normals,
+// and how it is mapped to the polygons, the polygon vertices or the control points.
+type layerElement struct {
+	mapping string    // mapping information type
+	values  []float64 // direct values
+	indices []int64   // indices of the values if the reference type is "IndexToDirect"
+	size    int       // number of components of each value
+}
+
+// findLayerElement returns the first layer element node with the specified name
+// and values of the specified geometry node or nil if not found.
+func findLayerElement(n *Node, name, valuesName string) *Node {
+
+	for _, c := range n.Children {
+		if c.Name == name && c.Child(valuesName) != nil {
+			return c
+		}
+	}
+	return nil
+}
+
+// newLayerElement creates and returns a layer element from the specified layer element node.
+func newLayerElement(ln *Node, valuesName, indexName string, size int) *layerElement {
+
+	if ln == nil {
+		return nil
+	}
+	le := &layerElement{
+		mapping: ln.ChildString("MappingInformationType"),
+		values:  ln.ChildFloats(valuesName),
+		size:    size,
+	}
+	ref := ln.ChildString("ReferenceInformationType")
+	if ref == "IndexToDirect" || ref == "Index" {
+		le.indices = ln.ChildInts(indexName)
+	}
+	return le
+}
+
+// get returns the value of the element for the specified polygon, polygon vertex
+// and control point indices or nil if there is no value.
+func (le *layerElement) get(polygon, polyVertex, vertex int) []float64 {
+
+	var idx int
+	switch le.mapping {
+	case "ByPolygonVertex":
+		idx = polyVertex
+	case "ByPolygon":
+		idx = polygon
+	case "ByVertex", "ByVertice", "ByControlPoint":
+		idx = vertex
+	case "AllSame":
+		idx = 0
+	default:
+		return nil
+	}
+	if le.indices != nil {
+		if idx < 0 || idx >= len(le.indices) {
+			return nil
+		}
+		idx = int(le.indices[idx])
+	}
+	if idx < 0 || (idx+1)*le.size > len(le.values) {
+		return nil
+	}
+	return le.values[idx*le.size : (idx+1)*le.size]
+}
+
+// boneInfluence is the influence of a bone over a vertex
+type boneInfluence struct {
+	bone   int
+	weight float64
+}
+
+// NewGeometry creates and returns a geometry from the specified geometry object.
+// The polygons are triangulated and grouped by their material indices, which are
+// the indices of the materials connected to the model of the geometry.
+// If the geometry has a skin deformer, the vertices have the indices and weights of
+// the bones which influence them, whose indices are the indices of the skin clusters.
+func (dec *Decoder) NewGeometry(obj *Object) (*geometry.Geometry, error) {
+
+	return dec.newGeometry(obj, nil)
+}
+
+// newGeometry creates a geometry from the specified geometry object whose
+// positions and normals are transformed by the specified matrix if not nil.
+func (dec *Decoder) newGeometry(obj *Object, matrix *math32.Matrix4) (*geometry.Geometry, error) {
+
+	if obj.Class != "Geometry" || obj.Type != "Mesh" {
+		return nil, fmt.Errorf("object %q is not a mesh geometry", obj.Name)
+	}
+	n := obj.Node
+	vertices := n.ChildFloats("Vertices")
+	polyIndices := n.ChildInts("PolygonVertexIndex")
+	if len(vertices) == 0 || len(polyIndices) == 0 {
+		return nil, fmt.Errorf("geometry %q has no polygons", obj.Name)
+	}
+
+	// Gets the layer elements of the geometry
+	normals := newLayerElement(findLayerElement(n, "LayerElementNormal", "Normals"), "Normals", "NormalsIndex", 3)
+	colors := newLayerElement(findLayerElement(n, "LayerElementColor", "Colors"), "Colors", "ColorIndex", 4)
+	materials := newLayerElement(findLayerElement(n, "LayerElementMaterial", "Materials"), "Materials", "", 1)
+	var uvs, uvs2 *layerElement
+	for _, c := range n.Children {
+		if c.Name != "LayerElementUV" || c.Child("UV") == nil {
+			continue
+		}
+		if uvs == nil {
+			uvs = newLayerElement(c, "UV", "UVIndex", 2)
+		} else if uvs2 == nil {
+			uvs2 = newLayerElement(c, "UV", "UVIndex", 2)
+		}
+	}
+
+	// Gets the bone influences of the control points
+	var influences [][]boneInfluence
+	if skin := skinOf(obj); skin != nil {
+		influences = make([][]boneInfluence, len(vertices)/3)
+		for bone, cluster := range clustersOf(skin) {
+			indexes := cluster.Node.ChildInts("Indexes")
+			weights := cluster.Node.ChildFloats("Weights")
+			for i := 0; i < len(indexes) && i < len(weights); i++ {
+				cp := int(indexes[i])
+				if cp >= 0 && cp < len(influences) && weights[i] > 0 {
+					influences[cp] = append(influences[cp], boneInfluence{bone, weights[i]})
+				}
+			}
+		}
+		dec.limitInfluences(obj, influences)
+	}
+
+	// Creates the buffers of the vertices of each polygon vertex
+	positions := math32.NewArrayF32(0, len(polyIndices)*3)
+	normalsBuf := math32.NewArrayF32(0, len(polyIndices)*3)
+	uvsBuf := math32.NewArrayF32(0, 0)
+	uvs2Buf := math32.NewArrayF32(0, 0)
+	colorsBuf := math32.NewArrayF32(0, 0)
+	skinIndices := math32.NewArrayF32(0, 0)
+	skinWeights := math32.NewArrayF32(0, 0)
+	trianglesByMaterial := make(map[int][]uint32)
+
+	polygon := 0
+	start := 0
+	for pv := range polyIndices {
+		// A negative index, whose bits are inverted, ends a polygon
+		if polyIndices[pv] >= 0 {
+			continue
+		}
+		count := pv - start + 1
+		first := positions.Size() / 3
+		for i := start; i <= pv; i++ {
+			cp := polyIndices[i]
+			if cp < 0 {
+				cp = ^cp
+			}
+			if (int(cp)+1)*3 > len(vertices) {
+				return nil, fmt.Errorf("invalid vertex index:%d in geometry %q", cp, obj.Name)
+			}
+		}
+
+		// Appends the polygon vertices
+		var faceNormal *math32.Vector3
+		for i := start; i <= pv; i++ {
+			cp := int(polyIndices[i])
+			if cp < 0 {
+				cp = ^cp
+			}
+			positions.Append(float32(vertices[cp*3]), float32(vertices[cp*3+1]), float32(vertices[cp*3+2]))
+			var v []float64
+			if normals != nil {
+				v = normals.get(polygon, i, cp)
+			}
+			if v != nil {
+				normalsBuf.Append(float32(v[0]), float32(v[1]), float32(v[2]))
+			} else {
+				// Uses the normal of the polygon if the vertex has no normal
+				if faceNormal == nil {
+					faceNormal = polygonNormal(vertices, polyIndices[start:pv+1])
+				}
+				normalsBuf.AppendVector3(faceNormal)
+			}
+			if uvs != nil {
+				if v = uvs.get(polygon, i, cp); v == nil {
+					v = []float64{0, 0}
+				}
+				uvsBuf.Append(float32(v[0]), float32(v[1]))
+			}
+			if uvs2 != nil {
+				if v = uvs2.get(polygon, i, cp); v == nil {
+					v = []float64{0, 0}
+				}
+				uvs2Buf.Append(float32(v[0]), float32(v[1]))
+			}
+			if colors != nil {
+				if v = colors.get(polygon, i, cp); v == nil {
+					v = []float64{1, 1, 1, 1}
+				}
+				colorsBuf.Append(float32(v[0]), float32(v[1]), float32(v[2]))
+			}
+			if influences != nil {
+				var idx, weight [maxBoneInfluencers]float32
+				for j, inf := range influences[cp] {
+					idx[j] = float32(inf.bone)
+					weight[j] = float32(inf.weight)
+				}
+				skinIndices.Append(idx[:]...)
+				skinWeights.Append(weight[:]...)
+			}
+		}
+
+		// Triangulates the polygon as a fan
+		matIndex := 0
+		if materials != nil {
+			if v := materials.get(polygon, start, 0); v != nil {
+				matIndex = int(v[0])
+			}
+		}
+		for i := 1; i < count-1; i++ {
+			trianglesByMaterial[matIndex] = append(trianglesByMaterial[matIndex],
+				uint32(first), uint32(first+i), uint32(first+i+1))
+		}
+		polygon++
+		start = pv + 1
+	}
+
+	// Transforms the positions and the normals
+	if matrix != nil {
+		var normalMatrix math32.Matrix3
+		if err := normalMatrix.GetNormalMatrix(matrix); err != nil {
+			return nil, err
+		}
+		var v math32.Vector3
+		for i := 0; i < positions.Size(); i += 3 {
+			positions.GetVector3(i, &v)
+			v.ApplyMatrix4(matrix)
+			positions.SetVector3(i, &v)
+			normalsBuf.GetVector3(i, &v)
+			v.ApplyMatrix3(&normalMatrix).Normalize()
+			normalsBuf.SetVector3(i, &v)
+		}
+	}
+
+	// Creates the groups of triangles sorted by material index
+	geom := geometry.NewGeometry()
+	matIndices := make([]int, 0, len(trianglesByMaterial))
+	for matIndex := range trianglesByMaterial {
+		matIndices = append(matIndices, matIndex)
+	}
+	sort.Ints(matIndices)
+	indices := math32.NewArrayU32(0, 0)
+	for _, matIndex := range matIndices {
+		tris := trianglesByMaterial[matIndex]
+		geom.AddGroup(indices.Size(), len(tris), matIndex)
+		indices.Append(tris...)
+	}
+
+	geom.SetIndices(indices)
+	geom.AddVBO(gls.NewVBO(positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(normalsBuf).AddAttrib(gls.VertexNormal))
+	if uvs != nil {
+		geom.AddVBO(gls.NewVBO(uvsBuf).AddAttrib(gls.VertexTexcoord))
+	}
+	if uvs2 != nil {
+		geom.AddVBO(gls.NewVBO(uvs2Buf).AddAttrib(gls.VertexTexcoord2))
+	}
+	if colors != nil {
+		geom.AddVBO(gls.NewVBO(colorsBuf).AddAttrib(gls.VertexColor))
+	}
+	if influences != nil {
+		geom.AddVBO(gls.NewVBO(skinIndices).AddAttrib(gls.SkinIndex))
+		geom.AddVBO(gls.NewVBO(skinWeights).AddAttrib(gls.SkinWeight))
+	}
+	return geom, nil
+}
+
+// limitInfluences keeps the bones with the largest weights of each control point,
+// up to the maximum number of bone influencers, and normalizes their weights.
+func (dec *Decoder) limitInfluences(obj *Object, influences [][]boneInfluence) {
+
+	limited := false
+	for cp, infs := range influences {
+		sort.Slice(infs, func(i, j int) bool { return infs[i].weight > infs[j].weight })
+		if len(infs) > maxBoneInfluencers {
+			infs = infs[:maxBoneInfluencers]
+			limited = true
+		}
+		total := 0.0
+		for _, inf := range infs {
+			total += inf.weight
+		}
+		for i := range infs {
+			infs[i].weight /= total
+		}
+		influences[cp] = infs
+	}
+	if limited {
+		dec.appendWarn("vertices of geometry %q are influenced by more than %d bones", obj.Name, maxBoneInfluencers)
+	}
+}
+
+// polygonNormal returns the normal of the polygon with the specified control point indices.
+func polygonNormal(vertices []float64, polygon []int64) *math32.Vector3 {
+
+	// Newell's method
+	var n math32.Vector3
+	for i := range polygon {
+		a, b := polygon[i], polygon[(i+1)%len(polygon)]
+		if a < 0 {
+			a = ^a
+		}
+		if b < 0 {
+			b = ^b
+		}
+		ax, ay, az := float32(vertices[a*3]), float32(vertices[a*3+1]), float32(vertices[a*3+2])
+		bx, by, bz := float32(vertices[b*3]), float32(vertices[b*3+1]), float32(vertices[b*3+2])
+		n.X += (ay - by) * (az + bz)
+		n.Y += (az - bz) * (ax + bx)
+		n.Z += (ax - bx) * (ay + by)
+	}
+	return n.Normalize()
+}
+
+// skinOf returns the skin deformer of the specified geometry object or nil if not found.
+func skinOf(obj *Object) *Object {
+
+	for _, d := range obj.ChildrenOf("Deformer") {
+		if d.Type == "Skin" {
+			return d
+		}
+	}
+	return nil
+}
+
+// clustersOf returns the clusters of the specified skin deformer, whose indices are the indices of the bones.
+func clustersOf(skin *Object) []*Object {
+
+	var clusters []*Object
+	for _, d := range skin.ChildrenOf("Deformer") {
+		if d.Type == "Cluster" {
+			clusters = append(clusters, d)
+		}
+	}
+	return clusters
+}
@@ -0,0 +1,209 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbx
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// GetMaterial returns a material created from the specified material object,
+// which is created only once.
+func (dec *Decoder) GetMaterial(obj *Object) (material.IMaterial, error) {
+
+	if mat, ok := dec.materials[obj.ID]; ok {
+		return mat, nil
+	}
+	mat, err := dec.NewMaterial(obj)
+	if err != nil {
+		return nil, err
+	}
+	if dec.materials == nil {
+		dec.materials = make(map[int64]material.IMaterial)
+	}
+	dec.materials[obj.ID] = mat
+	return mat, nil
+}
+
+// NewMaterial creates and returns a standard material from the specified material object.
+// Only the textures connected to the diffuse color of the material are used.
+func (dec *Decoder) NewMaterial(obj *Object) (material.IMaterial, error) {
+
+	if obj.Class != "Material" {
+		return nil, fmt.Errorf("object %q is not a material", obj.Name)
+	}
+
+	diffuse := propColor(obj, "DiffuseColor", propColor(obj, "Diffuse", math32.Color{0.8, 0.8, 0.8}))
+	diffuse.MultiplyScalar(float32(obj.propFloat("DiffuseFactor", 1)))
+	mat := material.NewStandard(&diffuse)
+
+	ambient := propColor(obj, "AmbientColor", mat.AmbientColor())
+	mat.SetAmbientColor(&ambient)
+	specular := propColor(obj, "SpecularColor", mat.SpecularColor())
+	specular.MultiplyScalar(float32(obj.propFloat("SpecularFactor", 1)))
+	mat.SetSpecularColor(&specular)
+	emissive := propColor(obj, "EmissiveColor", math32.Color{})
+	emissive.MultiplyScalar(float32(obj.propFloat("EmissiveFactor", 1)))
+	mat.SetEmissiveColor(&emissive)
+	mat.SetShininess(float32(obj.propFloat("Shininess", obj.propFloat("ShininessExponent", float64(mat.Shininess())))))
+
+	// Opacity, which some exporters only write as its complement
+	opacity := obj.propFloat("Opacity", 1-obj.propFloat("TransparencyFactor", 0))
+	if opacity < 1 {
+		mat.SetOpacity(float32(opacity))
+		mat.SetTransparent(true)
+	}
+
+	// Textures
+	for _, c := range obj.Children {
+		if c.Object.Class != "Texture" {
+			continue
+		}
+		if c.Property != "DiffuseColor" && c.Property != "Diffuse" {
+			dec.appendWarn("texture of property %q of material %q is not supported", c.Property, obj.Name)
+			continue
+		}
+		tex, err := dec.GetTexture2D(c.Object)
+		if err != nil {
+			return nil, err
+		}
+		mat.AddTexture(tex)
+	}
+	return mat, nil
+}
+
+// GetTexture2D returns a texture created from the specified texture object,
+// which is created only once.
+func (dec *Decoder) GetTexture2D(obj *Object) (*texture.Texture2D, error) {
+
+	if tex, ok := dec.textures[obj.ID]; ok {
+		return tex, nil
+	}
+	tex, err := dec.NewTexture2D(obj)
+	if err != nil {
+		return nil, err
+	}
+	if dec.textures == nil {
+		dec.textures = make(map[int64]*texture.Texture2D)
+	}
+	dec.textures[obj.ID] = tex
+	return tex, nil
+}
+
+// NewTexture2D creates and returns a texture from the specified texture object.
+// The image of the texture is the image embedded in its video object if any, or
+// the image file referenced by the texture otherwise, which is searched by its
+// relative path, by its absolute path and by its name in the images directory.
+func (dec *Decoder) NewTexture2D(obj *Object) (*texture.Texture2D, error) {
+
+	if obj.Class != "Texture" {
+		return nil, fmt.Errorf("object %q is not a texture", obj.Name)
+	}
+
+	var tex *texture.Texture2D
+	if content := videoContent(obj.Child("Video")); len(content) > 0 {
+		img, _, err := image.Decode(bytes.NewReader(content))
+		if err != nil {
+			return nil, fmt.Errorf("decoding embedded image of texture %q: %v", obj.Name, err)
+		}
+		rgba := image.NewRGBA(img.Bounds())
+		draw.Draw(rgba, rgba.Bounds(), img, image.Point{0, 0}, draw.Src)
+		tex = texture.NewTexture2DFromRGBA(rgba)
+	} else {
+		path, err := dec.findImage(obj)
+		if err != nil {
+			return nil, err
+		}
+		tex, err = texture.NewTexture2DFromImage(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The textures repeat unless their wrap modes are clamp
+	tex.SetWrapS(gls.REPEAT)
+	tex.SetWrapT(gls.REPEAT)
+	if obj.propInt("WrapModeU", 0) == 1 {
+		tex.SetWrapS(gls.CLAMP_TO_EDGE)
+	}
+	if obj.propInt("WrapModeV", 0) == 1 {
+		tex.SetWrapT(gls.CLAMP_TO_EDGE)
+	}
+	translation := obj.propVec3("Translation", [3]float64{0, 0, 0})
+	tex.SetOffset(float32(translation[0]), float32(translation[1]))
+	scaling := obj.propVec3("Scaling", [3]float64{1, 1, 1})
+	tex.SetRepeat(float32(scaling[0]), float32(scaling[1]))
+	return tex, nil
+}
+
+// findImage returns the path of the existing image file referenced by the specified texture object.
+func (dec *Decoder) findImage(obj *Object) (string, error) {
+
+	var paths []string
+	rel := obj.Node.ChildString("RelativeFilename")
+	abs := obj.Node.ChildString("FileName")
+	if rel != "" {
+		paths = append(paths, filepath.Join(dec.dirImages, filepath.FromSlash(strings.Replace(rel, "\\", "/", -1))))
+	}
+	if abs != "" {
+		paths = append(paths, abs)
+	}
+	for _, name := range []string{rel, abs} {
+		if name != "" {
+			base := name[strings.LastIndexAny(name, "/\\")+1:]
+			paths = append(paths, filepath.Join(dec.dirImages, base))
+		}
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("image file of texture %q not found", obj.Name)
+}
+
+// videoContent returns the embedded image data of the specified video object, if any.
+// In ASCII files the data is encoded in base64 and may be split in several strings.
+func videoContent(video *Object) []byte {
+
+	if video == nil {
+		return nil
+	}
+	content := video.Node.Child("Content")
+	if content == nil || len(content.Properties) == 0 {
+		return nil
+	}
+	if data, ok := content.Properties[0].([]byte); ok {
+		return data
+	}
+	var sb strings.Builder
+	for i := range content.Properties {
+		sb.WriteString(content.PropString(i))
+	}
+	data, err := base64.StdEncoding.DecodeString(sb.String())
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// propColor returns the value of the specified color property of the object or
+// the specified default color if the property is not found.
+func propColor(obj *Object, name string, def math32.Color) math32.Color {
+
+	v := obj.propVec3(name, [3]float64{float64(def.R), float64(def.G), float64(def.B)})
+	return math32.Color{float32(v[0]), float32(v[1]), float32(v[2])}
+}
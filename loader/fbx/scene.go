@@ -0,0 +1,299 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fbx
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// eulerOrders maps the FBX rotation orders to the order in which the rotations
+// about each axis are applied, where 0, 1 and 2 are the X, Y and Z axes.
+// The spheric XYZ order is handled as the XYZ order.
+var eulerOrders = [][3]int{
+	{0, 1, 2}, // eEulerXYZ
+	{0, 2, 1}, // eEulerXZY
+	{1, 2, 0}, // eEulerYZX
+	{1, 0, 2}, // eEulerYXZ
+	{2, 0, 1}, // eEulerZXY
+	{2, 1, 0}, // eEulerZYX
+	{0, 1, 2}, // eSphericXYZ
+}
+
+// NewScene creates and returns a node with the hierarchy of nodes created for the
+// models of the scene. The meshes of the models with skin deformers are rigged meshes
+// with skeletons whose bones are the nodes of the linked models.
+// The scene is converted from the axis system of the file to the Y up axis system.
+// The nodes created for the models are used as the targets of the channels of the
+// animations created by NewAnimations.
+func (dec *Decoder) NewScene() (*core.Node, error) {
+
+	dec.models = make(map[int64]core.INode)
+	dec.skins = make(map[core.INode]*Object)
+	root := core.NewNode()
+	dec.models[0] = root
+
+	// Creates the nodes of the models
+	var models []*Object
+	for _, obj := range dec.objects {
+		if obj.Class != "Model" {
+			continue
+		}
+		in, err := dec.newModel(obj)
+		if err != nil {
+			return nil, err
+		}
+		dec.models[obj.ID] = in
+		models = append(models, obj)
+	}
+
+	// Builds the hierarchy of the nodes
+	for _, obj := range models {
+		parent := root
+		for _, p := range obj.Parents {
+			if p.Object.Class == "Model" && p.Property == "" {
+				if pn, ok := dec.models[p.Object.ID]; ok {
+					parent = pn.GetNode()
+				}
+				break
+			}
+		}
+		parent.Add(dec.models[obj.ID])
+	}
+
+	// Creates the skeletons of the rigged meshes after the nodes of their bones
+	for in, skin := range dec.skins {
+		sk, err := dec.newSkeleton(skin)
+		if err != nil {
+			return nil, err
+		}
+		in.(*graphic.RiggedMesh).SetSkeleton(sk)
+	}
+
+	dec.setAxisSystem(root)
+	return root, nil
+}
+
+// ModelNode returns the node created by the last call to NewScene for the model
+// object with the specified id or nil if not found.
+func (dec *Decoder) ModelNode(id int64) core.INode {
+
+	return dec.models[id]
+}
+
+// newModel creates and returns the node of the specified model object, which is
+// a mesh, a rigged mesh or an empty node.
+func (dec *Decoder) newModel(obj *Object) (core.INode, error) {
+
+	var in core.INode
+	geomObj := obj.Child("Geometry")
+	if obj.Type == "Mesh" && geomObj != nil && geomObj.Type == "Mesh" {
+		mesh, err := dec.newMesh(obj, geomObj)
+		if err != nil {
+			return nil, err
+		}
+		in = mesh
+		if skin := skinOf(geomObj); skin != nil {
+			rm := graphic.NewRiggedMesh(mesh)
+			dec.skins[rm] = skin
+			in = rm
+		}
+	} else {
+		in = core.NewNode()
+	}
+
+	node := in.GetNode()
+	node.SetName(obj.Name)
+	node.SetMatrix(modelMatrix(obj))
+	if obj.propInt("Visibility", 1) == 0 {
+		node.SetVisible(false)
+	}
+	return in, nil
+}
+
+// newMesh creates and returns a mesh with the specified geometry and the materials of the specified model.
+// The geometry is transformed by the geometric transform of the model, which doesn't affect its children.
+func (dec *Decoder) newMesh(obj, geomObj *Object) (*graphic.Mesh, error) {
+
+	geom, err := dec.newGeometry(geomObj, geometricMatrix(obj))
+	if err != nil {
+		return nil, err
+	}
+
+	// Gets the materials of the model
+	var mats []material.IMaterial
+	for _, matObj := range obj.ChildrenOf("Material") {
+		mat, err := dec.GetMaterial(matObj)
+		if err != nil {
+			return nil, err
+		}
+		mats = append(mats, mat)
+	}
+	getMaterial := func(idx int) material.IMaterial {
+		if idx >= 0 && idx < len(mats) {
+			return mats[idx]
+		}
+		dec.appendWarn("could not find material %d of %q. using default material", idx, obj.Name)
+		return material.NewStandard(&math32.Color{0.8, 0.8, 0.8})
+	}
+
+	// Single material
+	if geom.GroupCount() == 1 {
+		return graphic.NewMesh(geom, getMaterial(geom.GroupAt(0).Matindex)), nil
+	}
+
+	// Multi material
+	mesh := graphic.NewMesh(geom, nil)
+	for idx := 0; idx < geom.GroupCount(); idx++ {
+		mesh.AddGroupMaterial(getMaterial(geom.GroupAt(idx).Matindex), idx)
+	}
+	return mesh, nil
+}
+
+// newSkeleton creates and returns a skeleton with a bone for each cluster of the specified skin deformer.
+func (dec *Decoder) newSkeleton(skin *Object) (*graphic.Skeleton, error) {
+
+	sk := graphic.NewSkeleton()
+	for _, cluster := range clustersOf(skin) {
+		boneObj := cluster.Child("Model")
+		if boneObj == nil {
+			return nil, fmt.Errorf("cluster %q has no bone", cluster.Name)
+		}
+		bone, ok := dec.models[boneObj.ID]
+		if !ok {
+			return nil, fmt.Errorf("bone %q not found", boneObj.Name)
+		}
+
+		// The inverse bind matrix transforms from the space of the mesh
+		// to the space of the bone at the time of binding
+		var transform, link, invLink math32.Matrix4
+		transform.Identity()
+		link.Identity()
+		if v := cluster.Node.ChildFloats("Transform"); len(v) == 16 {
+			transform.FromArray(toFloat32(v), 0)
+		}
+		if v := cluster.Node.ChildFloats("TransformLink"); len(v) == 16 {
+			link.FromArray(toFloat32(v), 0)
+		}
+		if err := invLink.GetInverse(&link); err != nil {
+			return nil, fmt.Errorf("invalid bind matrix of bone %q", boneObj.Name)
+		}
+		invBind := math32.NewMatrix4().MultiplyMatrices(&invLink, &transform)
+		sk.AddBone(bone.GetNode(), invBind)
+	}
+	return sk, nil
+}
+
+// setAxisSystem sets the transform of the specified root node which converts
+// the scene from the axis system of the file to the Y up axis system.
+func (dec *Decoder) setAxisSystem(root *core.Node) {
+
+	gs := &Object{Node: dec.Node("GlobalSettings")}
+	axis := func(name string, def int64) *math32.Vector3 {
+		var v [3]float32
+		idx := gs.propInt(name, def)
+		if idx < 0 || idx > 2 {
+			idx = def
+		}
+		v[idx] = float32(gs.propInt(name+"Sign", 1))
+		return math32.NewVector3(v[0], v[1], v[2])
+	}
+	coord := axis("CoordAxis", 0)
+	up := axis("UpAxis", 1)
+	front := axis("FrontAxis", 2)
+
+	// The rows of the conversion matrix are the axes of the file
+	var m math32.Matrix4
+	m.MakeBasis(coord, up, front).Transpose()
+	if m.Determinant() <= 0 {
+		dec.appendWarn("axis system of the file is not supported")
+		return
+	}
+	root.SetMatrix(&m)
+}
+
+// modelMatrix returns the local transform of the specified model object, which is
+// T * Roff * Rp * Rpre * R * Rpost^-1 * Rp^-1 * Soff * Sp * S * Sp^-1, where T, R and S are the translation, rotation and scaling, Roff and Soff their offsets,
+// Rp and Sp their pivots and Rpre and Rpost the pre and post rotations.
+func modelMatrix(obj *Object) *math32.Matrix4 {
+
+	zero := [3]float64{0, 0, 0}
+	translate := func(name string, sign float32) *math32.Matrix4 {
+		v := obj.propVec3(name, zero)
+		return math32.NewMatrix4().MakeTranslation(sign*float32(v[0]), sign*float32(v[1]), sign*float32(v[2]))
+	}
+	m := translate("Lcl Translation", 1)
+	m.Multiply(translate("RotationOffset", 1))
+	m.Multiply(translate("RotationPivot", 1))
+	m.Multiply(math32.NewMatrix4().MakeRotationFromQuaternion(modelRotation(obj, obj.propVec3("Lcl Rotation", zero))))
+	m.Multiply(translate("RotationPivot", -1))
+	m.Multiply(translate("ScalingOffset", 1))
+	m.Multiply(translate("ScalingPivot", 1))
+	s := obj.propVec3("Lcl Scaling", [3]float64{1, 1, 1})
+	m.Multiply(math32.NewMatrix4().MakeScale(float32(s[0]), float32(s[1]), float32(s[2])))
+	m.Multiply(translate("ScalingPivot", -1))
+	return m
+}
+
+// modelRotation returns the rotation of the specified model object with the
+// specified Euler angles in degrees, combined with its pre and post rotations.
+func modelRotation(obj *Object, angles [3]float64) *math32.Quaternion {
+
+	zero := [3]float64{0, 0, 0}
+	order := obj.propInt("RotationOrder", 0)
+	q := eulerQuaternion(obj.propVec3("PreRotation", zero), 0)
+	q.Multiply(eulerQuaternion(angles, order))
+	q.Multiply(eulerQuaternion(obj.propVec3("PostRotation", zero), 0).Inverse())
+	return q
+}
+
+// geometricMatrix returns the geometric transform of the specified model object
+// or nil if it is the identity.
+func geometricMatrix(obj *Object) *math32.Matrix4 {
+
+	zero := [3]float64{0, 0, 0}
+	one := [3]float64{1, 1, 1}
+	t := obj.propVec3("GeometricTranslation", zero)
+	r := obj.propVec3("GeometricRotation", zero)
+	s := obj.propVec3("GeometricScaling", one)
+	if t == zero && r == zero && s == one {
+		return nil
+	}
+	pos := math32.NewVector3(float32(t[0]), float32(t[1]), float32(t[2]))
+	scale := math32.NewVector3(float32(s[0]), float32(s[1]), float32(s[2]))
+	return math32.NewMatrix4().Compose(pos, eulerQuaternion(r, 0), scale)
+}
+
+// eulerQuaternion returns the quaternion of the rotation with the specified Euler angles
+// in degrees, whose rotations are applied in the specified FBX rotation order.
+func eulerQuaternion(angles [3]float64, order int64) *math32.Quaternion {
+
+	if order < 0 || int(order) >= len(eulerOrders) {
+		order = 0
+	}
+	axes := [3]*math32.Vector3{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	q := math32.NewQuaternion(0, 0, 0, 1)
+	for _, axis := range eulerOrders[order] {
+		var r math32.Quaternion
+		r.SetFromAxisAngle(axes[axis], math32.DegToRad(float32(angles[axis])))
+		// Each rotation is applied after the previous ones
+		q.MultiplyQuaternions(&r, q)
+	}
+	return q
+}
+
+// toFloat32 converts the specified slice of float64 to a slice of float32.
+func toFloat32(v []float64) []float32 {
+
+	res := make([]float32, len(v))
+	for i := range v {
+		res[i] = float32(v[i])
+	}
+	return res
+}
@@ -0,0 +1,82 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/animation"
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+)
+
+// Bundle contains a loaded scene and the objects of the asset which refer to it.
+type Bundle struct {
+	Root       core.INode             // Root node of the scene
+	Animations []*animation.Animation // Animations of the asset, whose channels target the nodes of the scene
+	Cameras    []camera.ICamera       // Cameras of the scene
+	Lights     []light.ILight         // Lights of the scene
+	Skeletons  []*graphic.Skeleton    // Skeletons of the rigged meshes of the scene
+	Materials  []material.IMaterial   // Materials of the asset
+}
+
+// LoadAll loads the default scene of the asset, or the first scene if there is no default,
+// with all the animations and materials of the asset and returns them in a bundle with
+// the cameras, lights and skeletons of the scene.
+// The extras of the nodes are set as their user data.
+func (g *GLTF) LoadAll() (*Bundle, error) {
+
+	sceneIdx := 0
+	if g.Scene != nil {
+		sceneIdx = *g.Scene
+	}
+	if len(g.Scenes) == 0 {
+		return nil, fmt.Errorf("asset has no scenes")
+	}
+	root, err := g.LoadScene(sceneIdx)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bundle{Root: root}
+
+	// Animations target the cached nodes of the scene
+	for i := range g.Animations {
+		anim, err := g.LoadAnimation(i)
+		if err != nil {
+			return nil, err
+		}
+		b.Animations = append(b.Animations, anim)
+	}
+
+	// Materials, including the ones which are not used by the scene
+	for i := range g.Materials {
+		mat, err := g.LoadMaterial(i)
+		if err != nil {
+			return nil, err
+		}
+		b.Materials = append(b.Materials, mat)
+	}
+
+	// Cameras, lights and skeletons of the scene
+	skeletons := make(map[*graphic.Skeleton]bool)
+	var collect func(inode core.INode)
+	collect = func(inode core.INode) {
+		switch n := inode.(type) {
+		case camera.ICamera:
+			b.Cameras = append(b.Cameras, n)
+		case light.ILight:
+			b.Lights = append(b.Lights, n)
+		case *graphic.RiggedMesh:
+			if sk := n.Skeleton(); sk != nil && !skeletons[sk] {
+				skeletons[sk] = true
+				b.Skeletons = append(b.Skeletons, sk)
+			}
+		}
+		for _, child := range inode.GetNode().Children() {
+			collect(child)
+		}
+	}
+	collect(root)
+	return b, nil
+}
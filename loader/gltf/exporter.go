@@ -0,0 +1,887 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/g3n/engine/animation"
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// exporter contains the state of the export of a scene to a glTF asset.
+type exporter struct {
+	g          *GLTF                           // Exported asset
+	data       []byte                          // Binary buffer data
+	nodes      map[*core.Node]int              // Indices of the exported nodes
+	morphs     map[*geometry.MorphGeometry]int // Indices of the nodes of the exported morph geometries
+	materials  map[material.IMaterial]int      // Indices of the exported materials
+	textures   map[*texture.Texture2D]int      // Indices of the exported textures
+	samplers   map[[4]int]int                  // Indices of the exported samplers
+	skeletons  map[*graphic.Skeleton]int       // Indices of the exported skins
+	skinned    []skinnedNode                   // Exported rigged mesh nodes
+	lights     []map[string]interface{}        // Exported punctual lights
+	extensions map[string]bool                 // Names of the used extensions
+	attributes map[gls.AttribType]string       // glTF attribute names of the vertex attribute types
+	keyframes  map[*float32]map[int]int        // Indices of the exported keyframe accessors
+}
+
+// skinnedNode is an exported rigged mesh node whose skin is exported after all the nodes.
+type skinnedNode struct {
+	node     int               // Index of the node
+	skeleton *graphic.Skeleton // Skeleton of the rigged mesh
+}
+
+// Export creates and returns a glTF asset with the scene of the specified node and the specified animations,
+// which can be written with WriteJSON or WriteBin. The node is exported as the scene and its children as the
+// root nodes of the scene, as returned by LoadScene. Meshes, lines and points are exported with their physical
+// or standard materials and textures, rigged meshes with their skins and morph geometries with their targets.
+// Cameras and point, spot and directional lights are also exported, the lights with the KHR_lights_punctual
+// extension. The channels of the animations must target nodes of the scene.
+// All the binary data, including the images of the textures, is stored in a single buffer.
+func Export(scene core.INode, anims ...*animation.Animation) (*GLTF, error) {
+
+	e := new(exporter)
+	e.g = new(GLTF)
+	e.g.Asset = Asset{Version: "2.0", Generator: "G3N"}
+	e.nodes = make(map[*core.Node]int)
+	e.morphs = make(map[*geometry.MorphGeometry]int)
+	e.materials = make(map[material.IMaterial]int)
+	e.textures = make(map[*texture.Texture2D]int)
+	e.samplers = make(map[[4]int]int)
+	e.skeletons = make(map[*graphic.Skeleton]int)
+	e.extensions = make(map[string]bool)
+	e.keyframes = make(map[*float32]map[int]int)
+	e.attributes = make(map[gls.AttribType]string)
+	for name, atype := range AttributeName {
+		e.attributes[atype] = name
+	}
+
+	// Export the nodes
+	root := scene.GetNode()
+	root.UpdateMatrixWorld()
+	sceneData := Scene{Name: root.Name()}
+	for _, child := range root.Children() {
+		ni, err := e.exportNode(child)
+		if err != nil {
+			return nil, err
+		}
+		sceneData.Nodes = append(sceneData.Nodes, ni)
+	}
+	e.g.Scenes = []Scene{sceneData}
+	sceneIdx := 0
+	e.g.Scene = &sceneIdx
+
+	// Export the skins after all the nodes which may be their joints
+	for _, sn := range e.skinned {
+		si, err := e.exportSkin(sn.skeleton)
+		if err != nil {
+			return nil, err
+		}
+		e.g.Nodes[sn.node].Skin = &si
+	}
+
+	// Export the animations
+	for _, anim := range anims {
+		err := e.exportAnimation(anim)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Punctual lights are defined by the root extension and referenced by the nodes
+	if len(e.lights) > 0 {
+		e.g.Extensions = map[string]interface{}{
+			KhrLightsPunctual: map[string]interface{}{"lights": e.lights},
+		}
+	}
+	for ext := range e.extensions {
+		e.g.ExtensionsUsed = append(e.g.ExtensionsUsed, ext)
+	}
+	sort.Strings(e.g.ExtensionsUsed)
+
+	// Single buffer with all the binary data
+	if len(e.data) > 0 {
+		e.g.Buffers = []Buffer{{ByteLength: len(e.data)}}
+		e.g.data = e.data
+	}
+	return e.g, nil
+}
+
+// exportNode exports the specified node and its children and returns the index of the exported node.
+func (e *exporter) exportNode(inode core.INode) (int, error) {
+
+	node := inode.GetNode()
+	ni := len(e.g.Nodes)
+	e.g.Nodes = append(e.g.Nodes, Node{})
+	e.nodes[node] = ni
+	nodeData := Node{Name: node.Name()}
+
+	// Local transformation
+	pos := node.Position()
+	if pos.X != 0 || pos.Y != 0 || pos.Z != 0 {
+		nodeData.Translation = &[3]float32{pos.X, pos.Y, pos.Z}
+	}
+	quat := node.Quaternion()
+	if !quat.IsIdentity() {
+		nodeData.Rotation = &[4]float32{quat.X, quat.Y, quat.Z, quat.W}
+	}
+	scale := node.Scale()
+	if scale.X != 1 || scale.Y != 1 || scale.Z != 1 {
+		nodeData.Scale = &[3]float32{scale.X, scale.Y, scale.Z}
+	}
+
+	// Contents of the node
+	var err error
+	var lightRot *math32.Quaternion
+	mi := -1
+	switch n := inode.(type) {
+	case *graphic.RiggedMesh:
+		mi, err = e.exportMesh(n, TRIANGLES)
+		if n.Skeleton() != nil {
+			e.skinned = append(e.skinned, skinnedNode{ni, n.Skeleton()})
+		}
+	case *graphic.Mesh:
+		mi, err = e.exportMesh(n, TRIANGLES)
+	case *graphic.Lines:
+		mi, err = e.exportMesh(n, LINES)
+	case *graphic.LineStrip:
+		mi, err = e.exportMesh(n, LINE_STRIP)
+	case *graphic.Points:
+		mi, err = e.exportMesh(n, POINTS)
+	case *camera.Camera:
+		ci := e.exportCamera(n)
+		nodeData.Camera = &ci
+	case *light.Point, *light.Spot, *light.Directional:
+		lightRot = e.exportLight(inode, &nodeData)
+	case *light.Ambient:
+		log.Warn("ambient light %q is not supported by glTF and is exported as an empty node", node.Name())
+	}
+	if err != nil {
+		return 0, err
+	}
+	if mi >= 0 {
+		nodeData.Mesh = &mi
+		if mg, ok := inode.(graphic.IGraphic).IGeometry().(*geometry.MorphGeometry); ok {
+			e.morphs[mg] = ni
+		}
+	}
+
+	// Children
+	for _, child := range node.Children() {
+		ci, err := e.exportNode(child)
+		if err != nil {
+			return 0, err
+		}
+		nodeData.Children = append(nodeData.Children, ci)
+	}
+
+	// Spot and directional lights shine along the -Z axis of their nodes, so the rotation of the node
+	// is replaced by the orientation of the light, unless it has children, which keep the rotation of
+	// the node and get an additional child with the light and its orientation relative to the node.
+	if lightRot != nil {
+		if len(nodeData.Children) == 0 {
+			nodeData.Rotation = &[4]float32{lightRot.X, lightRot.Y, lightRot.Z, lightRot.W}
+		} else {
+			rel := quat
+			rel.Inverse()
+			rel.Multiply(lightRot)
+			childData := Node{Name: nodeData.Name, Extensions: nodeData.Extensions}
+			childData.Rotation = &[4]float32{rel.X, rel.Y, rel.Z, rel.W}
+			nodeData.Extensions = nil
+			nodeData.Children = append(nodeData.Children, len(e.g.Nodes))
+			e.g.Nodes = append(e.g.Nodes, childData)
+		}
+	}
+
+	e.g.Nodes[ni] = nodeData
+	return ni, nil
+}
+
+// exportMesh exports the geometry and materials of the specified graphic as a mesh with the specified
+// primitive mode and returns the index of the exported mesh or -1 if the geometry has no vertices.
+func (e *exporter) exportMesh(igr graphic.IGraphic, mode int) (int, error) {
+
+	gr := igr.GetGraphic()
+	igeom := igr.IGeometry()
+	geom := igeom.GetGeometry()
+	attributes := e.exportAttributes(geom, false)
+	if _, ok := attributes["POSITION"]; !ok {
+		log.Warn("graphic %q without vertex positions is not exported", gr.Name())
+		return -1, nil
+	}
+	meshData := Mesh{Name: gr.Name()}
+
+	// Morph targets
+	var targets []map[string]int
+	if mg, ok := igeom.(*geometry.MorphGeometry); ok {
+		for _, target := range mg.MorphTargets() {
+			targets = append(targets, e.exportAttributes(target, true))
+		}
+		meshData.Weights = append(meshData.Weights, mg.Weights()...)
+		if names := mg.TargetNames(); len(names) > 0 {
+			meshData.Extras = map[string]interface{}{"targetNames": names}
+		}
+	}
+
+	// One primitive for each material, with the indices of its range of elements
+	indices := geom.Indices()
+	allIndices := -1
+	grmats := gr.Materials()
+	if len(grmats) == 0 {
+		grmats = []graphic.GraphicMaterial{{}}
+	}
+	for _, grmat := range grmats {
+		p := Primitive{Attributes: attributes, Targets: targets}
+		if mode != TRIANGLES {
+			m := mode
+			p.Mode = &m
+		}
+		start, count := grmat.Start(), grmat.Count()
+		if len(indices) > 0 {
+			if count == 0 && start == 0 {
+				if allIndices < 0 {
+					allIndices = e.addIndices(indices)
+				}
+				p.Indices = &allIndices
+			} else {
+				end := len(indices)
+				if count > 0 && start+count < end {
+					end = start + count
+				}
+				ai := e.addIndices(indices[start:end])
+				p.Indices = &ai
+			}
+		} else if count > 0 || start > 0 {
+			items := geom.Items()
+			if count == 0 || start+count > items {
+				count = items - start
+			}
+			seq := math32.NewArrayU32(0, count)
+			for i := 0; i < count; i++ {
+				seq = append(seq, uint32(start+i))
+			}
+			ai := e.addIndices(seq)
+			p.Indices = &ai
+		}
+		if imat := grmat.IMaterial(); imat != nil {
+			mi, err := e.exportMaterial(imat)
+			if err != nil {
+				return 0, err
+			}
+			p.Material = &mi
+		}
+		meshData.Primitives = append(meshData.Primitives, p)
+	}
+
+	e.g.Meshes = append(e.g.Meshes, meshData)
+	return len(e.g.Meshes) - 1, nil
+}
+
+// exportAttributes exports the vertex attributes of the specified geometry which have a glTF attribute name
+// and returns the indices of their accessors by attribute name. Morph targets only have positions, normals
+// and tangents.
+func (e *exporter) exportAttributes(geom *geometry.Geometry, target bool) map[string]int {
+
+	attributes := make(map[string]int)
+	for _, vbo := range geom.VBOs() {
+		buf := *vbo.Buffer()
+		stride := vbo.Stride()
+		if stride == 0 || len(buf) < stride {
+			continue
+		}
+		count := len(buf) / stride
+		for _, attrib := range vbo.Attributes() {
+			name, ok := e.attributes[attrib.Type]
+			if !ok {
+				continue
+			}
+			if target && name != "POSITION" && name != "NORMAL" {
+				continue
+			}
+			// De-interleave the values of the attribute
+			size := int(attrib.NumElements)
+			offset := int(attrib.ByteOffset) / 4
+			values := make([]float32, 0, count*size)
+			for i := 0; i < count; i++ {
+				base := i*stride + offset
+				values = append(values, buf[base:base+size]...)
+			}
+			atype := []string{SCALAR, SCALAR, VEC2, VEC3, VEC4}[size]
+			if attrib.Type == gls.SkinIndex {
+				attributes[name] = e.addAccessorU16(values, atype, ARRAY_BUFFER)
+			} else {
+				attributes[name] = e.addAccessor(values, atype, ARRAY_BUFFER, name == "POSITION")
+			}
+		}
+	}
+	return attributes
+}
+
+// exportMaterial exports the specified material and returns the index of the exported material.
+// Standard materials are exported with an approximate metallic-roughness model and with the
+// KHR_materials_common extension, which is used to load them back as standard materials.
+func (e *exporter) exportMaterial(imat material.IMaterial) (int, error) {
+
+	if mi, ok := e.materials[imat]; ok {
+		return mi, nil
+	}
+	mat := imat.GetMaterial()
+	matData := Material{AlphaCutoff: 0.5}
+	pbr := &PbrMetallicRoughness{}
+	matData.PbrMetallicRoughness = pbr
+
+	switch m := imat.(type) {
+	case *material.Physical:
+		c := m.BaseColorFactor()
+		pbr.BaseColorFactor = &[4]float32{c.R, c.G, c.B, c.A}
+		metallic := m.MetallicFactor()
+		pbr.MetallicFactor = &metallic
+		roughness := m.RoughnessFactor()
+		pbr.RoughnessFactor = &roughness
+		emissive := m.EmissiveFactor()
+		if emissive.R != 0 || emissive.G != 0 || emissive.B != 0 {
+			matData.EmissiveFactor = &[3]float32{emissive.R, emissive.G, emissive.B}
+		}
+		pbr.BaseColorTexture = e.exportTexture(m.BaseColorMap())
+		pbr.MetallicRoughnessTexture = e.exportTexture(m.MetallicRoughnessMap())
+		matData.EmissiveTexture = e.exportTexture(m.EmissiveMap())
+		if ti := e.exportTexture(m.NormalMap()); ti != nil {
+			matData.NormalTexture = &NormalTextureInfo{Index: ti.Index, Scale: 1}
+		}
+		if ti := e.exportTexture(m.OcclusionMap()); ti != nil {
+			matData.OcclusionTexture = &OcclusionTextureInfo{Index: ti.Index, Strength: 1}
+		}
+
+	case *material.Standard:
+		color := m.Color()
+		opacity := m.Opacity()
+		pbr.BaseColorFactor = &[4]float32{color.R, color.G, color.B, opacity}
+		metallic := float32(0)
+		pbr.MetallicFactor = &metallic
+		// Roughness which gives a specular highlight of similar size
+		roughness := math32.Sqrt(2 / (m.Shininess() + 2))
+		pbr.RoughnessFactor = &roughness
+		emissive := m.EmissiveColor()
+		if emissive.R != 0 || emissive.G != 0 || emissive.B != 0 {
+			matData.EmissiveFactor = &[3]float32{emissive.R, emissive.G, emissive.B}
+		}
+		ambient := m.AmbientColor()
+		specular := m.SpecularColor()
+		values := map[string]interface{}{
+			"ambient":      []float32{ambient.R, ambient.G, ambient.B, 1},
+			"diffuse":      []float32{color.R, color.G, color.B, 1},
+			"emission":     []float32{emissive.R, emissive.G, emissive.B, 1},
+			"specular":     []float32{specular.R, specular.G, specular.B, 1},
+			"shininess":    []float32{m.Shininess()},
+			"transparency": []float32{opacity},
+		}
+		if textures := m.Textures(); len(textures) > 0 {
+			pbr.BaseColorTexture = e.exportTexture(textures[0])
+			if pbr.BaseColorTexture != nil {
+				values["diffuse"] = []int{pbr.BaseColorTexture.Index}
+			}
+		}
+		matData.Extensions = map[string]interface{}{
+			KhrMaterialsCommon: map[string]interface{}{
+				"technique":   "PHONG",
+				"doubleSided": mat.Side() == material.SideDouble,
+				"transparent": mat.Transparent(),
+				"values":      values,
+			},
+		}
+		e.extensions[KhrMaterialsCommon] = true
+
+	default:
+		log.Warn("material with shader %q is exported as the default material", mat.Shader())
+	}
+
+	if mat.Side() == material.SideDouble {
+		matData.DoubleSided = true
+	}
+	if mat.Transparent() {
+		matData.AlphaMode = "BLEND"
+	}
+	e.g.Materials = append(e.g.Materials, matData)
+	mi := len(e.g.Materials) - 1
+	e.materials[imat] = mi
+	return mi, nil
+}
+
+// exportTexture exports the specified texture with its image encoded as PNG and returns a reference to it.
+// It returns nil if the texture is nil or has no RGBA image data.
+func (e *exporter) exportTexture(tex *texture.Texture2D) *TextureInfo {
+
+	if tex == nil {
+		return nil
+	}
+	if ti, ok := e.textures[tex]; ok {
+		return &TextureInfo{Index: ti}
+	}
+	rgba := tex.RGBA()
+	if rgba == nil {
+		log.Warn("texture without RGBA image data is not exported")
+		return nil
+	}
+
+	// Image
+	var buf bytes.Buffer
+	err := png.Encode(&buf, rgba)
+	if err != nil {
+		log.Warn("error encoding texture image:%v", err)
+		return nil
+	}
+	bvi := e.addBufferView(buf.Bytes(), 0)
+	e.g.Images = append(e.g.Images, Image{MimeType: mimePNG, BufferView: &bvi})
+	source := len(e.g.Images) - 1
+	texData := Texture{Source: source}
+
+	// Sampler shared by the textures with the same parameters
+	key := [4]int{int(tex.MagFilter()), int(tex.MinFilter()), int(tex.WrapS()), int(tex.WrapT())}
+	si, ok := e.samplers[key]
+	if !ok {
+		e.g.Samplers = append(e.g.Samplers, Sampler{MagFilter: &key[0], MinFilter: &key[1], WrapS: &key[2], WrapT: &key[3]})
+		si = len(e.g.Samplers) - 1
+		e.samplers[key] = si
+	}
+	texData.Sampler = &si
+
+	e.g.Textures = append(e.g.Textures, texData)
+	ti := len(e.g.Textures) - 1
+	e.textures[tex] = ti
+	return &TextureInfo{Index: ti}
+}
+
+// exportCamera exports the projection of the specified camera and returns the index of the exported camera.
+func (e *exporter) exportCamera(cam *camera.Camera) int {
+
+	camData := Camera{Name: cam.Name()}
+	aspect := cam.Aspect()
+	if cam.Projection() == camera.Orthographic {
+		// The magnifications are half the size of the view
+		ymag := cam.Size() / 2
+		if cam.Axis() == camera.Horizontal {
+			ymag /= aspect
+		}
+		camData.Type = "orthographic"
+		camData.Orthographic = &Orthographic{Xmag: ymag * aspect, Ymag: ymag, Zfar: cam.Far(), Znear: cam.Near()}
+	} else {
+		yfov := math32.DegToRad(cam.Fov())
+		if cam.Axis() == camera.Horizontal {
+			yfov = 2 * math32.Atan(math32.Tan(yfov/2)/aspect)
+		}
+		far := cam.Far()
+		camData.Type = "perspective"
+		camData.Perspective = &Perspective{AspectRatio: &aspect, Yfov: yfov, Zfar: &far, Znear: cam.Near()}
+	}
+	e.g.Cameras = append(e.g.Cameras, camData)
+	return len(e.g.Cameras) - 1
+}
+
+// exportLight exports the specified light with the KHR_lights_punctual extension and references it from
+// the specified node data. For spot and directional lights it returns the rotation from the -Z axis
+// to the direction of the light in the coordinates of the parent of the node.
+func (e *exporter) exportLight(inode core.INode, nodeData *Node) *math32.Quaternion {
+
+	node := inode.GetNode()
+	lightData := map[string]interface{}{}
+	if node.Name() != "" {
+		lightData["name"] = node.Name()
+	}
+	var color math32.Color
+	var intensity float32
+	var dir math32.Vector3 // Direction of the light in the coordinates of the parent of the node
+	switch l := inode.(type) {
+	case *light.Point:
+		lightData["type"] = "point"
+		color, intensity = l.Color(), l.Intensity()
+	case *light.Spot:
+		lightData["type"] = "spot"
+		color, intensity = l.Color(), l.Intensity()
+		lightData["spot"] = map[string]interface{}{
+			"innerConeAngle": 0,
+			"outerConeAngle": math32.DegToRad(l.CutoffAngle()),
+		}
+		dir = node.Direction()
+		quat := node.Quaternion()
+		dir.ApplyQuaternion(&quat)
+	case *light.Directional:
+		// The position of the light is the direction from which it shines
+		lightData["type"] = "directional"
+		color, intensity = l.Color(), l.Intensity()
+		l.WorldPosition(&dir)
+		dir.Negate()
+		if parent := node.Parent(); parent != nil {
+			var quat math32.Quaternion
+			parent.GetNode().WorldQuaternion(&quat)
+			quat.Inverse()
+			dir.ApplyQuaternion(&quat)
+		}
+	}
+	lightData["color"] = []float32{color.R, color.G, color.B}
+	lightData["intensity"] = intensity
+
+	nodeData.Extensions = map[string]interface{}{
+		KhrLightsPunctual: map[string]interface{}{"light": len(e.lights)},
+	}
+	e.lights = append(e.lights, lightData)
+	e.extensions[KhrLightsPunctual] = true
+
+	if dir.Length() == 0 {
+		return nil
+	}
+	dir.Normalize()
+	rot := new(math32.Quaternion)
+	rot.SetFromUnitVectors(&math32.Vector3{0, 0, -1}, &dir)
+	return rot
+}
+
+// exportSkin exports the specified skeleton and returns the index of the exported skin.
+// All the bones of the skeleton must have been exported.
+func (e *exporter) exportSkin(sk *graphic.Skeleton) (int, error) {
+
+	if si, ok := e.skeletons[sk]; ok {
+		return si, nil
+	}
+	skinData := Skin{}
+	for _, bone := range sk.Bones() {
+		ni, ok := e.nodes[bone]
+		if !ok {
+			return 0, fmt.Errorf("skeleton bone %q is not in the exported scene", bone.Name())
+		}
+		skinData.Joints = append(skinData.Joints, ni)
+	}
+	ibms := sk.InverseBindMatrices()
+	values := make([]float32, 0, 16*len(ibms))
+	for i := range ibms {
+		values = append(values, ibms[i][:]...)
+	}
+	skinData.InverseBindMatrices = e.addAccessor(values, MAT4, 0, false)
+	e.g.Skins = append(e.g.Skins, skinData)
+	si := len(e.g.Skins) - 1
+	e.skeletons[sk] = si
+	return si, nil
+}
+
+// exportAnimation exports the channels of the specified animation.
+func (e *exporter) exportAnimation(anim *animation.Animation) error {
+
+	animData := Animation{Name: anim.Name()}
+	for _, ich := range anim.Channels() {
+		var ni int
+		var ok bool
+		var path, atype string
+		switch ch := ich.(type) {
+		case *animation.PositionChannel:
+			ni, ok = e.nodes[ch.Target().GetNode()]
+			path, atype = "translation", VEC3
+		case *animation.RotationChannel:
+			ni, ok = e.nodes[ch.Target().GetNode()]
+			path, atype = "rotation", VEC4
+		case *animation.ScaleChannel:
+			ni, ok = e.nodes[ch.Target().GetNode()]
+			path, atype = "scale", VEC3
+		case *animation.MorphChannel:
+			ni, ok = e.morphs[ch.Target()]
+			path, atype = "weights", SCALAR
+		default:
+			return fmt.Errorf("unsupported animation channel type:%T", ich)
+		}
+		if !ok {
+			return fmt.Errorf("target of animation %q is not in the exported scene", anim.Name())
+		}
+		keyframes := ich.Keyframes()
+		values := ich.Values()
+		if len(keyframes) == 0 {
+			continue
+		}
+
+		// Interpolation and tangents of the concrete channel types
+		interp := animation.LINEAR
+		if ic, ok := ich.(interface {
+			InterpolationType() animation.InterpolationType
+		}); ok {
+			interp = ic.InterpolationType()
+		}
+		if interp == animation.CUBICSPLINE {
+			// Cubic spline outputs contain the in-tangent, value and out-tangent of each keyframe
+			var inTangent, outTangent math32.ArrayF32
+			if tc, ok := ich.(interface {
+				InterpolationTangents() (math32.ArrayF32, math32.ArrayF32)
+			}); ok {
+				inTangent, outTangent = tc.InterpolationTangents()
+			}
+			size := len(values) / len(keyframes)
+			spline := make([]float32, 0, 3*len(values))
+			for i := 0; i+size <= len(values); i += size {
+				spline = appendTangent(spline, inTangent, i, size)
+				spline = append(spline, values[i:i+size]...)
+				spline = appendTangent(spline, outTangent, i, size)
+			}
+			values = spline
+		}
+
+		samplerData := AnimationSampler{
+			Input:         e.addKeyframes(keyframes),
+			Output:        e.addAccessor(values, atype, 0, false),
+			Interpolation: string(interp),
+		}
+		animData.Samplers = append(animData.Samplers, samplerData)
+		animData.Channels = append(animData.Channels, Channel{
+			Sampler: len(animData.Samplers) - 1,
+			Target:  Target{Node: ni, Path: path},
+		})
+	}
+	e.g.Animations = append(e.g.Animations, animData)
+	return nil
+}
+
+// appendTangent appends the tangent with the specified offset and size to the specified values,
+// or zeros if there are no tangents.
+func appendTangent(values, tangents []float32, offset, size int) []float32 {
+
+	if offset+size <= len(tangents) {
+		return append(values, tangents[offset:offset+size]...)
+	}
+	for i := 0; i < size; i++ {
+		values = append(values, 0)
+	}
+	return values
+}
+
+// addKeyframes adds the specified keyframes, which are shared by the channels with the same keyframes,
+// and returns the index of their accessor.
+func (e *exporter) addKeyframes(keyframes math32.ArrayF32) int {
+
+	byLen, ok := e.keyframes[&keyframes[0]]
+	if !ok {
+		byLen = make(map[int]int)
+		e.keyframes[&keyframes[0]] = byLen
+	}
+	if ai, ok := byLen[len(keyframes)]; ok {
+		return ai
+	}
+	ai := e.addAccessor(keyframes, SCALAR, 0, true)
+	byLen[len(keyframes)] = ai
+	return ai
+}
+
+// addIndices adds the specified indices with the smallest component type which can hold them
+// and returns the index of their accessor.
+func (e *exporter) addIndices(indices math32.ArrayU32) int {
+
+	var max uint32
+	for _, idx := range indices {
+		if idx > max {
+			max = idx
+		}
+	}
+	var data []byte
+	var ctype int
+	if max < math.MaxUint16 {
+		ctype = UNSIGNED_SHORT
+		data = make([]byte, 2*len(indices))
+		for i, idx := range indices {
+			binary.LittleEndian.PutUint16(data[2*i:], uint16(idx))
+		}
+	} else {
+		ctype = UNSIGNED_INT
+		data = make([]byte, 4*len(indices))
+		for i, idx := range indices {
+			binary.LittleEndian.PutUint32(data[4*i:], idx)
+		}
+	}
+	bvi := e.addBufferView(data, ELEMENT_ARRAY_BUFFER)
+	e.g.Accessors = append(e.g.Accessors, Accessor{BufferView: &bvi, ComponentType: ctype, Count: len(indices), Type: SCALAR})
+	return len(e.g.Accessors) - 1
+}
+
+// addAccessor adds the specified float values with the specified accessor type and buffer view target
+// and returns the index of their accessor, which has the bounds of the values if minmax is true.
+func (e *exporter) addAccessor(values []float32, atype string, target int, minmax bool) int {
+
+	size := TypeSizes[atype]
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	bvi := e.addBufferView(data, target)
+	ac := Accessor{BufferView: &bvi, ComponentType: FLOAT, Count: len(values) / size, Type: atype}
+	if minmax && len(values) >= size {
+		ac.Min = append([]float32(nil), values[:size]...)
+		ac.Max = append([]float32(nil), values[:size]...)
+		for i, v := range values {
+			c := i % size
+			ac.Min[c] = math32.Min(ac.Min[c], v)
+			ac.Max[c] = math32.Max(ac.Max[c], v)
+		}
+	}
+	e.g.Accessors = append(e.g.Accessors, ac)
+	return len(e.g.Accessors) - 1
+}
+
+// addAccessorU16 adds the specified values as unsigned shorts, as used by joint indices,
+// with the specified accessor type and buffer view target and returns the index of their accessor.
+func (e *exporter) addAccessorU16(values []float32, atype string, target int) int {
+
+	data := make([]byte, 2*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(v))
+	}
+	bvi := e.addBufferView(data, target)
+	e.g.Accessors = append(e.g.Accessors, Accessor{BufferView: &bvi, ComponentType: UNSIGNED_SHORT, Count: len(values) / TypeSizes[atype], Type: atype})
+	return len(e.g.Accessors) - 1
+}
+
+// addBufferView appends the specified data to the buffer, aligned to 4 bytes,
+// and returns the index of a new buffer view of the data with the specified target.
+func (e *exporter) addBufferView(data []byte, target int) int {
+
+	for len(e.data)%4 != 0 {
+		e.data = append(e.data, 0)
+	}
+	offset := len(e.data)
+	e.data = append(e.data, data...)
+	bv := BufferView{Buffer: 0, ByteOffset: &offset, ByteLength: len(data)}
+	if target != 0 {
+		bv.Target = &target
+	}
+	e.g.BufferViews = append(e.g.BufferViews, bv)
+	return len(e.g.BufferViews) - 1
+}
+
+// WriteJSON writes the asset to the specified glTF file. The binary buffer of an exported
+// or binary asset is written to a file with the same name and the .bin extension.
+func (g *GLTF) WriteJSON(filename string) error {
+
+	uri := ""
+	if g.hasChunkBuffer() {
+		binfile := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".bin"
+		err := ioutil.WriteFile(binfile, g.data[:g.Buffers[0].ByteLength], 0644)
+		if err != nil {
+			return err
+		}
+		uri = filepath.Base(binfile)
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = g.writeJSON(f, uri)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteJSONWriter writes the asset as glTF JSON to the specified writer. The binary
+// buffer of an exported or binary asset is embedded as a base64 data URI.
+func (g *GLTF) WriteJSONWriter(w io.Writer) error {
+
+	uri := ""
+	if g.hasChunkBuffer() {
+		uri = dataURLprefix + mimeBIN + ";base64," + base64.StdEncoding.EncodeToString(g.data[:g.Buffers[0].ByteLength])
+	}
+	return g.writeJSON(w, uri)
+}
+
+// writeJSON writes the asset as JSON to the specified writer with the specified URI of the chunk buffer.
+func (g *GLTF) writeJSON(w io.Writer, uri string) error {
+
+	if uri != "" {
+		g.Buffers[0].Uri = uri
+		defer func() { g.Buffers[0].Uri = "" }()
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// WriteBin writes the asset to the specified binary glTF (.glb) file.
+func (g *GLTF) WriteBin(filename string) error {
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = g.WriteBinWriter(f)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteBinWriter writes the asset as binary glTF to the specified writer,
+// with the binary buffer of an exported or binary asset in the binary chunk.
+func (g *GLTF) WriteBinWriter(w io.Writer) error {
+
+	// JSON chunk padded with spaces
+	js, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	for len(js)%4 != 0 {
+		js = append(js, ' ')
+	}
+	length := 12 + 8 + len(js)
+
+	// Binary chunk padded with zeros
+	var bin []byte
+	if g.hasChunkBuffer() {
+		bin = append(bin, g.data[:g.Buffers[0].ByteLength]...)
+		for len(bin)%4 != 0 {
+			bin = append(bin, 0)
+		}
+		length += 8 + len(bin)
+	}
+
+	err = binary.Write(w, binary.LittleEndian, GLBHeader{Magic: GLBMagic, Version: 2, Length: uint32(length)})
+	if err != nil {
+		return err
+	}
+	err = binary.Write(w, binary.LittleEndian, GLBChunk{Length: uint32(len(js)), Type: GLBJson})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(js)
+	if err != nil || bin == nil {
+		return err
+	}
+	err = binary.Write(w, binary.LittleEndian, GLBChunk{Length: uint32(len(bin)), Type: GLBBin})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(bin)
+	return err
+}
+
+// hasChunkBuffer returns whether the first buffer of the asset has no URI and
+// its data is in the asset, as the buffers of exported assets and of binary chunks.
+func (g *GLTF) hasChunkBuffer() bool {
+
+	return len(g.Buffers) > 0 && g.Buffers[0].Uri == "" && len(g.data) >= g.Buffers[0].ByteLength
+}
@@ -0,0 +1,546 @@
+package gltf
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/g3n/engine/math32"
+)
+
+// meshoptBufferView describes the compressed data of a buffer view
+// with the EXT_meshopt_compression extension.
+type meshoptBufferView struct {
+	Buffer     int    `json:"buffer"`     // The index of the buffer with the compressed data. Required.
+	ByteOffset int    `json:"byteOffset"` // The offset into the buffer in bytes. Not required. Default is 0.
+	ByteLength int    `json:"byteLength"` // The length of the compressed data in bytes. Required.
+	ByteStride int    `json:"byteStride"` // The stride in bytes of the decompressed elements. Required.
+	Count      int    `json:"count"`      // The number of elements. Required.
+	Mode       string `json:"mode"`       // The compression mode: "ATTRIBUTES", "TRIANGLES" or "INDICES". Required.
+	Filter     string `json:"filter"`     // The filter applied to the decompressed data. Not required. Default is "NONE".
+}
+
+// Constants of the meshoptimizer vertex codec.
+const (
+	meshoptVertexHeader         = 0xa0
+	meshoptVertexBlockSizeBytes = 8192
+	meshoptVertexBlockMaxSize   = 256
+	meshoptByteGroupSize        = 16
+	meshoptByteGroupDecodeLimit = 24
+	meshoptTailMaxSize          = 32
+)
+
+// Constants of the meshoptimizer index codecs.
+const (
+	meshoptIndexHeader    = 0xe0
+	meshoptSequenceHeader = 0xd0
+)
+
+// loadMeshoptBufferView decodes and returns the data of a buffer view compressed with the
+// EXT_meshopt_compression extension, which is described by the specified extension object.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Vendor/EXT_meshopt_compression
+func (g *GLTF) loadMeshoptBufferView(ext interface{}) ([]byte, error) {
+
+	var desc meshoptBufferView
+	err := decodeExtension(ext, &desc)
+	if err != nil {
+		return nil, err
+	}
+	buf, err := g.loadBuffer(desc.Buffer)
+	if err != nil {
+		return nil, err
+	}
+	if desc.ByteOffset < 0 || desc.ByteLength < 0 || desc.ByteOffset+desc.ByteLength > len(buf) {
+		return nil, fmt.Errorf("invalid compressed buffer view range")
+	}
+	src := buf[desc.ByteOffset : desc.ByteOffset+desc.ByteLength]
+	if desc.Count < 0 || desc.ByteStride <= 0 {
+		return nil, fmt.Errorf("invalid compressed buffer view count or stride")
+	}
+	dst := make([]byte, desc.Count*desc.ByteStride)
+
+	switch desc.Mode {
+	case "ATTRIBUTES":
+		err = meshoptDecodeVertexBuffer(dst, desc.Count, desc.ByteStride, src)
+	case "TRIANGLES":
+		err = meshoptDecodeIndexBuffer(dst, desc.Count, desc.ByteStride, src)
+	case "INDICES":
+		err = meshoptDecodeIndexSequence(dst, desc.Count, desc.ByteStride, src)
+	default:
+		err = fmt.Errorf("unsupported compression mode:%s", desc.Mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch desc.Filter {
+	case "", "NONE":
+	case "OCTAHEDRAL":
+		err = meshoptDecodeFilterOct(dst, desc.Count, desc.ByteStride)
+	case "QUATERNION":
+		err = meshoptDecodeFilterQuat(dst, desc.Count, desc.ByteStride)
+	case "EXPONENTIAL":
+		err = meshoptDecodeFilterExp(dst, desc.Count, desc.ByteStride)
+	default:
+		err = fmt.Errorf("unsupported compression filter:%s", desc.Filter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// meshoptDecodeVertexBuffer decodes the specified number of vertices of the specified size
+// encoded by the meshoptimizer vertex codec into the specified destination.
+// The vertices are split in blocks whose bytes are transposed, delta encoded from the
+// previous vertex and packed in groups of 16 with 0, 2, 4 or 8 bits per byte.
+func meshoptDecodeVertexBuffer(dst []byte, count, size int, src []byte) error {
+
+	if size <= 0 || size > 256 || size%4 != 0 {
+		return fmt.Errorf("invalid compressed vertex size:%d", size)
+	}
+	if len(src) < 1+size {
+		return fmt.Errorf("invalid compressed vertex data")
+	}
+	if src[0]&0xf0 != meshoptVertexHeader || src[0]&0x0f > 0 {
+		return fmt.Errorf("unsupported compressed vertex data version")
+	}
+
+	// The tail of the data has the base values of the deltas of the first vertex
+	var last [256]byte
+	copy(last[:size], src[len(src)-size:])
+
+	blockSize := (meshoptVertexBlockSizeBytes / size) &^ (meshoptByteGroupSize - 1)
+	if blockSize > meshoptVertexBlockMaxSize {
+		blockSize = meshoptVertexBlockMaxSize
+	}
+	pos := 1
+	for offset := 0; offset < count; offset += blockSize {
+		n := count - offset
+		if n > blockSize {
+			n = blockSize
+		}
+		var err error
+		pos, err = meshoptDecodeVertexBlock(src, pos, dst[offset*size:], n, size, last[:size])
+		if err != nil {
+			return err
+		}
+	}
+
+	tailSize := size
+	if tailSize < meshoptTailMaxSize {
+		tailSize = meshoptTailMaxSize
+	}
+	if len(src)-pos != tailSize {
+		return fmt.Errorf("invalid compressed vertex data size")
+	}
+	return nil
+}
+
+// meshoptDecodeVertexBlock decodes a block of vertices starting at the specified position
+// of the source data and returns the position after the block.
+func meshoptDecodeVertexBlock(src []byte, pos int, dst []byte, count, size int, last []byte) (int, error) {
+
+	countAligned := (count + meshoptByteGroupSize - 1) &^ (meshoptByteGroupSize - 1)
+	var buffer [meshoptVertexBlockMaxSize]byte
+	var err error
+	for k := 0; k < size; k++ {
+		pos, err = meshoptDecodeBytes(src, pos, buffer[:countAligned])
+		if err != nil {
+			return 0, err
+		}
+		p := last[k]
+		for i := 0; i < count; i++ {
+			// Deltas are zigzag encoded
+			v := buffer[i]
+			p += -(v & 1) ^ (v >> 1)
+			dst[i*size+k] = p
+		}
+		last[k] = p
+	}
+	return pos, nil
+}
+
+// meshoptDecodeBytes decodes the groups of 16 bytes of the specified buffer starting
+// at the specified position of the source data and returns the position after them.
+func meshoptDecodeBytes(src []byte, pos int, buffer []byte) (int, error) {
+
+	// The header has the number of bits of each group
+	headerSize := (len(buffer)/meshoptByteGroupSize + 3) / 4
+	if len(src)-pos < headerSize {
+		return 0, fmt.Errorf("invalid compressed vertex data")
+	}
+	header := src[pos : pos+headerSize]
+	pos += headerSize
+
+	for i := 0; i < len(buffer); i += meshoptByteGroupSize {
+		if len(src)-pos < meshoptByteGroupDecodeLimit {
+			return 0, fmt.Errorf("invalid compressed vertex data")
+		}
+		group := i / meshoptByteGroupSize
+		bitslog2 := (header[group/4] >> (uint(group%4) * 2)) & 3
+		group16 := buffer[i : i+meshoptByteGroupSize]
+		switch bitslog2 {
+		case 0:
+			for j := range group16 {
+				group16[j] = 0
+			}
+		case 3:
+			copy(group16, src[pos:pos+meshoptByteGroupSize])
+			pos += meshoptByteGroupSize
+		default:
+			// Packed values with the highest value as an escape for a full byte after them
+			bits := uint(1) << bitslog2
+			escape := byte(1<<bits) - 1
+			packed := src[pos : pos+int(bits)*2]
+			pos += len(packed)
+			for j := range group16 {
+				b := packed[j*int(bits)/8]
+				v := (b >> (8 - bits - uint(j*int(bits)%8))) & escape
+				if v == escape {
+					v = src[pos]
+					pos++
+				}
+				group16[j] = v
+			}
+		}
+	}
+	return pos, nil
+}
+
+// meshoptDecodeIndexBuffer decodes the specified number of triangle indices of the specified
+// size encoded by the meshoptimizer index codec into the specified destination.
+// The triangles are encoded with the edges and vertices of FIFOs of the previous triangles.
+func meshoptDecodeIndexBuffer(dst []byte, count, size int, src []byte) error {
+
+	if count%3 != 0 || (size != 2 && size != 4) {
+		return fmt.Errorf("invalid compressed index count or size")
+	}
+	if len(src) < 1+count/3+16 {
+		return fmt.Errorf("invalid compressed index data")
+	}
+	if src[0]&0xf0 != meshoptIndexHeader || src[0]&0x0f > 1 {
+		return fmt.Errorf("unsupported compressed index data version")
+	}
+	version := src[0] & 0x0f
+
+	var edgefifo [16][2]uint32
+	var vertexfifo [16]uint32
+	for i := range edgefifo {
+		edgefifo[i] = [2]uint32{math.MaxUint32, math.MaxUint32}
+		vertexfifo[i] = math.MaxUint32
+	}
+	edgeOffset, vertexOffset := 0, 0
+	pushVertex := func(v uint32, cond bool) {
+		vertexfifo[vertexOffset] = v
+		if cond {
+			vertexOffset = (vertexOffset + 1) & 15
+		}
+	}
+	pushEdge := func(a, b uint32) {
+		edgefifo[edgeOffset] = [2]uint32{a, b}
+		edgeOffset = (edgeOffset + 1) & 15
+	}
+
+	var next, last uint32
+	fecmax := 15
+	if version >= 1 {
+		fecmax = 13
+	}
+
+	// The codes of the triangles are followed by their data and a table of 16 auxiliary codes
+	code := 1
+	pos := 1 + count/3
+	safeEnd := len(src) - 16
+	codeaux := src[safeEnd:]
+	decodeIndex := func() uint32 {
+		v := meshoptDecodeVByte(src, &pos)
+		return last + (v>>1 ^ -(v & 1))
+	}
+
+	for i := 0; i < count; i += 3 {
+		if pos > safeEnd {
+			return fmt.Errorf("invalid compressed index data")
+		}
+		codetri := src[code]
+		code++
+		var a, b, c uint32
+		if codetri < 0xf0 {
+			// Triangle with an edge of the FIFO
+			fe := int(codetri >> 4)
+			edge := edgefifo[(edgeOffset-1-fe)&15]
+			a, b = edge[0], edge[1]
+			fec := int(codetri & 15)
+			if fec < fecmax {
+				// Third vertex is new or from the FIFO
+				if fec == 0 {
+					c = next
+					next++
+				} else {
+					c = vertexfifo[(vertexOffset-1-fec)&15]
+				}
+				pushVertex(c, fec == 0)
+			} else {
+				// Third vertex is close to the last free index or a new free index
+				if fec != 15 {
+					c = last + uint32(fec-(fec^3))
+				} else {
+					c = decodeIndex()
+				}
+				last = c
+				pushVertex(c, true)
+			}
+			pushEdge(c, b)
+			pushEdge(a, c)
+		} else if codetri < 0xfe {
+			// Triangle with a new vertex and vertices of the auxiliary code table
+			aux := codeaux[codetri&15]
+			feb, fec := int(aux>>4), int(aux&15)
+			a = next
+			next++
+			if feb == 0 {
+				b = next
+				next++
+			} else {
+				b = vertexfifo[(vertexOffset-feb)&15]
+			}
+			if fec == 0 {
+				c = next
+				next++
+			} else {
+				c = vertexfifo[(vertexOffset-fec)&15]
+			}
+			pushVertex(a, true)
+			pushVertex(b, feb == 0)
+			pushVertex(c, fec == 0)
+			pushEdge(b, a)
+			pushEdge(c, b)
+			pushEdge(a, c)
+		} else {
+			// Triangle with an auxiliary code in the data
+			aux := src[pos]
+			pos++
+			if aux == 0 {
+				next = 0
+			}
+			feb, fec := int(aux>>4), int(aux&15)
+			if codetri == 0xfe {
+				a = next
+				next++
+			}
+			if feb == 0 {
+				b = next
+				next++
+			} else {
+				b = vertexfifo[(vertexOffset-feb)&15]
+			}
+			if fec == 0 {
+				c = next
+				next++
+			} else {
+				c = vertexfifo[(vertexOffset-fec)&15]
+			}
+			if codetri != 0xfe {
+				a = decodeIndex()
+				last = a
+			}
+			if feb == 15 {
+				b = decodeIndex()
+				last = b
+			}
+			if fec == 15 {
+				c = decodeIndex()
+				last = c
+			}
+			pushVertex(a, true)
+			pushVertex(b, feb == 0 || feb == 15)
+			pushVertex(c, fec == 0 || fec == 15)
+			pushEdge(b, a)
+			pushEdge(c, b)
+			pushEdge(a, c)
+		}
+		meshoptWriteIndex(dst, i, size, a)
+		meshoptWriteIndex(dst, i+1, size, b)
+		meshoptWriteIndex(dst, i+2, size, c)
+	}
+
+	if pos != safeEnd {
+		return fmt.Errorf("invalid compressed index data size")
+	}
+	return nil
+}
+
+// meshoptDecodeIndexSequence decodes the specified number of indices of the specified size
+// encoded by the meshoptimizer index sequence codec into the specified destination.
+// Each index is a delta from one of the two previous baselines.
+func meshoptDecodeIndexSequence(dst []byte, count, size int, src []byte) error {
+
+	if size != 2 && size != 4 {
+		return fmt.Errorf("invalid compressed index size")
+	}
+	if len(src) < 1+count+4 {
+		return fmt.Errorf("invalid compressed index data")
+	}
+	if src[0]&0xf0 != meshoptSequenceHeader || src[0]&0x0f > 1 {
+		return fmt.Errorf("unsupported compressed index data version")
+	}
+
+	var last [2]uint32
+	pos := 1
+	safeEnd := len(src) - 4
+	for i := 0; i < count; i++ {
+		if pos >= safeEnd {
+			return fmt.Errorf("invalid compressed index data")
+		}
+		v := meshoptDecodeVByte(src, &pos)
+		current := v & 1
+		v >>= 1
+		index := last[current] + (v>>1 ^ -(v & 1))
+		last[current] = index
+		meshoptWriteIndex(dst, i, size, index)
+	}
+
+	if pos != safeEnd {
+		return fmt.Errorf("invalid compressed index data size")
+	}
+	return nil
+}
+
+// meshoptDecodeVByte decodes the variable length integer at the specified position
+// of the source data and advances the position.
+func meshoptDecodeVByte(src []byte, pos *int) uint32 {
+
+	lead := src[*pos]
+	*pos++
+	if lead < 128 {
+		return uint32(lead)
+	}
+	result := uint32(lead & 127)
+	shift := uint(7)
+	for i := 0; i < 4; i++ {
+		group := src[*pos]
+		*pos++
+		result |= uint32(group&127) << shift
+		shift += 7
+		if group < 128 {
+			break
+		}
+	}
+	return result
+}
+
+// meshoptWriteIndex writes the index of the specified size at the specified position of the destination.
+func meshoptWriteIndex(dst []byte, i, size int, index uint32) {
+
+	if size == 2 {
+		binary.LittleEndian.PutUint16(dst[i*2:], uint16(index))
+	} else {
+		binary.LittleEndian.PutUint32(dst[i*4:], index)
+	}
+}
+
+// meshoptDecodeFilterOct decodes unit vectors encoded in octahedral coordinates as
+// 4 signed bytes or shorts, whose fourth component is preserved.
+func meshoptDecodeFilterOct(data []byte, count, stride int) error {
+
+	if stride != 4 && stride != 8 {
+		return fmt.Errorf("invalid stride for octahedral filter:%d", stride)
+	}
+	size := stride / 4
+	max := float32(int(1)<<uint(size*8-1) - 1)
+	get := func(i int) float32 {
+		if size == 1 {
+			return float32(int8(data[i]))
+		}
+		return float32(int16(binary.LittleEndian.Uint16(data[i*2:])))
+	}
+	set := func(i int, v int) {
+		if size == 1 {
+			data[i] = byte(int8(v))
+		} else {
+			binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(v)))
+		}
+	}
+	for i := 0; i < count; i++ {
+		// Reconstructs z from x and y, assuming that z encodes 1 at the same position
+		x := get(i * 4)
+		y := get(i*4 + 1)
+		z := get(i*4+2) - math32.Abs(x) - math32.Abs(y)
+		// Fixes the octahedral coordinates for z < 0
+		t := math32.Min(z, 0)
+		if x >= 0 {
+			x += t
+		} else {
+			x -= t
+		}
+		if y >= 0 {
+			y += t
+		} else {
+			y -= t
+		}
+		s := max / math32.Sqrt(x*x+y*y+z*z)
+		set(i*4, meshoptRound(x*s))
+		set(i*4+1, meshoptRound(y*s))
+		set(i*4+2, meshoptRound(z*s))
+	}
+	return nil
+}
+
+// meshoptDecodeFilterQuat decodes unit quaternions encoded as 4 shorts with the three smallest
+// components and the index of the largest one and the scale in the fourth component.
+func meshoptDecodeFilterQuat(data []byte, count, stride int) error {
+
+	if stride != 8 {
+		return fmt.Errorf("invalid stride for quaternion filter:%d", stride)
+	}
+	get := func(i int) int {
+		return int(int16(binary.LittleEndian.Uint16(data[i*2:])))
+	}
+	set := func(i int, v int) {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(v)))
+	}
+	scale := 1 / math32.Sqrt(2)
+	for i := 0; i < count; i++ {
+		// Recovers the scale from the high bits of the fourth component
+		w4 := get(i*4 + 3)
+		ss := scale / float32(w4|3)
+		x := float32(get(i*4)) * ss
+		y := float32(get(i*4+1)) * ss
+		z := float32(get(i*4+2)) * ss
+		// Reconstructs the largest component, which is positive
+		w := math32.Sqrt(math32.Max(1-x*x-y*y-z*z, 0))
+		qc := w4 & 3
+		set(i*4+(qc+1)&3, meshoptRound(x*32767))
+		set(i*4+(qc+2)&3, meshoptRound(y*32767))
+		set(i*4+(qc+3)&3, meshoptRound(z*32767))
+		set(i*4+qc, meshoptRound(w*32767))
+	}
+	return nil
+}
+
+// meshoptDecodeFilterExp decodes floats encoded with a 24 bit signed mantissa
+// and an 8 bit signed exponent.
+func meshoptDecodeFilterExp(data []byte, count, stride int) error {
+
+	if stride%4 != 0 {
+		return fmt.Errorf("invalid stride for exponential filter:%d", stride)
+	}
+	for i := 0; i < count*stride/4; i++ {
+		v := binary.LittleEndian.Uint32(data[i*4:])
+		m := int32(v<<8) >> 8
+		e := int32(v) >> 24
+		f := math.Float32frombits(uint32(e+127)<<23) * float32(m)
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(f))
+	}
+	return nil
+}
+
+// meshoptRound rounds the specified value to the nearest integer, away from zero.
+func meshoptRound(v float32) int {
+
+	if v >= 0 {
+		return int(v + 0.5)
+	}
+	return int(v - 0.5)
+}
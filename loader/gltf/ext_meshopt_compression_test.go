@@ -0,0 +1,312 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// meshoptIndexBuffer and meshoptIndexData are the triangles and their encoding
+// with the version 0 of the index codec of the meshoptimizer tests
+var meshoptIndexBuffer = []uint32{0, 1, 2, 2, 1, 3, 4, 6, 5, 7, 8, 9}
+var meshoptIndexData = []byte{
+	0xe0, 0xf0, 0x10, 0xfe, 0xff, 0xf0, 0x0c, 0xff, 0x02, 0x02, 0x02, 0x00, 0x76, 0x87, 0x56, 0x67,
+	0x78, 0xa9, 0x86, 0x65, 0x89, 0x68, 0x98, 0x01, 0x69, 0x00, 0x00,
+}
+
+// meshoptIndexSequence and meshoptSequenceData are the indices and their encoding
+// with the index sequence codec of the meshoptimizer tests
+var meshoptIndexSequence = []uint32{0, 1, 51, 2, 49, 1000}
+var meshoptSequenceData = []byte{0xd1, 0x00, 0x04, 0xcd, 0x01, 0x04, 0x07, 0x98, 0x1f, 0x00, 0x00, 0x00, 0x00}
+
+// meshoptEncodeVertexBuffer encodes the specified vertices with the meshoptimizer vertex codec,
+// using for each group of bytes the smallest of its encodings.
+func meshoptEncodeVertexBuffer(vertices []byte, count, size int) []byte {
+
+	out := []byte{meshoptVertexHeader}
+	last := make([]byte, size)
+	copy(last, vertices[:size])
+	blockSize := (meshoptVertexBlockSizeBytes / size) &^ (meshoptByteGroupSize - 1)
+	if blockSize > meshoptVertexBlockMaxSize {
+		blockSize = meshoptVertexBlockMaxSize
+	}
+	for offset := 0; offset < count; offset += blockSize {
+		n := count - offset
+		if n > blockSize {
+			n = blockSize
+		}
+		buffer := make([]byte, (n+meshoptByteGroupSize-1)&^(meshoptByteGroupSize-1))
+		for k := 0; k < size; k++ {
+			p := last[k]
+			for i := 0; i < n; i++ {
+				v := vertices[(offset+i)*size+k]
+				d := v - p
+				buffer[i] = d<<1 ^ byte(int8(d)>>7)
+				p = v
+			}
+			last[k] = p
+			out = meshoptEncodeBytes(out, buffer)
+		}
+	}
+
+	// The tail has the first vertex padded to 32 bytes
+	if size < meshoptTailMaxSize {
+		out = append(out, make([]byte, meshoptTailMaxSize-size)...)
+	}
+	return append(out, vertices[:size]...)
+}
+
+// meshoptEncodeBytes appends the groups of 16 bytes of the specified buffer,
+// preceded by the header with their number of bits, to the specified output.
+func meshoptEncodeBytes(out []byte, buffer []byte) []byte {
+
+	groups := len(buffer) / meshoptByteGroupSize
+	header := make([]byte, (groups+3)/4)
+	var data []byte
+	for g := 0; g < groups; g++ {
+		group := buffer[g*meshoptByteGroupSize : (g+1)*meshoptByteGroupSize]
+		var best []byte
+		bestBits := 0
+		for bitslog2 := 0; bitslog2 < 4; bitslog2++ {
+			enc, ok := meshoptEncodeGroup(group, bitslog2)
+			if ok && (best == nil || len(enc) < len(best)) {
+				best, bestBits = enc, bitslog2
+			}
+		}
+		header[g/4] |= byte(bestBits << uint(g%4*2))
+		data = append(data, best...)
+	}
+	return append(append(out, header...), data...)
+}
+
+// meshoptEncodeGroup encodes a group of 16 bytes with the specified log2 of the
+// number of bits per byte, returning false if the group can't be encoded.
+func meshoptEncodeGroup(group []byte, bitslog2 int) ([]byte, bool) {
+
+	switch bitslog2 {
+	case 0:
+		for _, v := range group {
+			if v != 0 {
+				return nil, false
+			}
+		}
+		return []byte{}, true
+	case 3:
+		return append([]byte{}, group...), true
+	}
+	bits := 1 << uint(bitslog2)
+	escape := byte(1<<uint(bits)) - 1
+	packed := make([]byte, bits*2)
+	var escaped []byte
+	for j, v := range group {
+		if v >= escape {
+			escaped = append(escaped, v)
+			v = escape
+		}
+		packed[j*bits/8] |= v << uint(8-bits-j*bits%8)
+	}
+	return append(packed, escaped...), true
+}
+
+// Tests that vertices encoded with the vertex codec are decoded unchanged
+func TestMeshoptDecodeVertexBuffer(t *testing.T) {
+
+	// Vertices of several blocks with small and large deltas
+	seed := uint32(1)
+	random := func() byte {
+		seed = seed*1664525 + 1013904223
+		return byte(seed >> 24)
+	}
+	for _, size := range []int{4, 12, 16, 64} {
+		for _, count := range []int{1, 17, 300, 1000} {
+			vertices := make([]byte, count*size)
+			for i := range vertices {
+				switch k := i % size; {
+				case k < 4:
+					vertices[i] = byte(i / size) // small deltas
+				case k < 8:
+					vertices[i] = 7 // constant
+				default:
+					vertices[i] = random()
+				}
+			}
+			src := meshoptEncodeVertexBuffer(vertices, count, size)
+			dst := make([]byte, count*size)
+			if err := meshoptDecodeVertexBuffer(dst, count, size, src); err != nil {
+				t.Fatalf("size:%d count:%d %v", size, count, err)
+			}
+			if !bytes.Equal(dst, vertices) {
+				t.Errorf("size:%d count:%d decoded vertices differ", size, count)
+			}
+		}
+	}
+
+	// Invalid data
+	vertices := make([]byte, 16*4)
+	src := meshoptEncodeVertexBuffer(vertices, 16, 4)
+	dst := make([]byte, len(vertices))
+	for name, data := range map[string][]byte{
+		"version":   append([]byte{0xa1}, src[1:]...),
+		"truncated": src[:len(src)-1],
+		"extra":     append(append([]byte{}, src...), 0),
+	} {
+		if err := meshoptDecodeVertexBuffer(dst, 16, 4, data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if err := meshoptDecodeVertexBuffer(dst, 8, 6, src); err == nil {
+		t.Error("expected invalid vertex size error")
+	}
+}
+
+// Tests the decoding of the triangles encoded with the versions 0 and 1 of the index codec
+func TestMeshoptDecodeIndexBuffer(t *testing.T) {
+
+	for _, size := range []int{2, 4} {
+		dst := make([]byte, len(meshoptIndexBuffer)*size)
+		if err := meshoptDecodeIndexBuffer(dst, len(meshoptIndexBuffer), size, meshoptIndexData); err != nil {
+			t.Fatal(err)
+		}
+		if indices := readIndices(dst, size); !reflect.DeepEqual(indices, meshoptIndexBuffer) {
+			t.Errorf("size:%d indices:%v", size, indices)
+		}
+	}
+
+	// A triangle with two new vertices and a free index, followed by triangles whose
+	// third vertex is the next and the previous free index, which only version 1 encodes
+	aux := make([]byte, 16)
+	data := append([]byte{0xe1, 0xfe, 0x0e, 0x0d, 0x0f, 20}, aux...)
+	dst := make([]byte, 9*4)
+	if err := meshoptDecodeIndexBuffer(dst, 9, 4, data); err != nil {
+		t.Fatal(err)
+	}
+	if indices := readIndices(dst, 4); !reflect.DeepEqual(indices, []uint32{0, 1, 10, 0, 10, 11, 0, 11, 10}) {
+		t.Errorf("version 1 indices:%v", indices)
+	}
+
+	// Invalid data
+	dst = make([]byte, len(meshoptIndexBuffer)*4)
+	for name, data := range map[string][]byte{
+		"version":   append([]byte{0xe2}, meshoptIndexData[1:]...),
+		"truncated": meshoptIndexData[:len(meshoptIndexData)-1],
+		"extra":     append(append(append([]byte{}, meshoptIndexData[:11]...), 0), meshoptIndexData[11:]...),
+	} {
+		if err := meshoptDecodeIndexBuffer(dst, len(meshoptIndexBuffer), 4, data); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// Tests the decoding of an index sequence
+func TestMeshoptDecodeIndexSequence(t *testing.T) {
+
+	for _, size := range []int{2, 4} {
+		dst := make([]byte, len(meshoptIndexSequence)*size)
+		if err := meshoptDecodeIndexSequence(dst, len(meshoptIndexSequence), size, meshoptSequenceData); err != nil {
+			t.Fatal(err)
+		}
+		if indices := readIndices(dst, size); !reflect.DeepEqual(indices, meshoptIndexSequence) {
+			t.Errorf("size:%d indices:%v", size, indices)
+		}
+	}
+	dst := make([]byte, len(meshoptIndexSequence)*4)
+	if err := meshoptDecodeIndexSequence(dst, len(meshoptIndexSequence), 4, meshoptSequenceData[:len(meshoptSequenceData)-1]); err == nil {
+		t.Error("expected error for truncated data")
+	}
+}
+
+// Tests the decoding of octahedral, quaternion and exponential filters
+func TestMeshoptDecodeFilters(t *testing.T) {
+
+	// Octahedral unit vectors: +Z, +X, -Z and a vector at 45 degrees between +X and +Z
+	oct := []byte{0, 0, 127, 5, 127, 0, 127, 6, 127, 127, 127, 7, 64, 0, 127, 8}
+	if err := meshoptDecodeFilterOct(oct, 4, 4); err != nil {
+		t.Fatal(err)
+	}
+	c45 := byte(math.Round(127 * math.Sqrt(0.5)))
+	expected := []byte{0, 0, 127, 5, 127, 0, 0, 6, 0, 0, 129, 7, c45, 0, c45, 8}
+	for i := range oct {
+		if d := int(int8(oct[i])) - int(int8(expected[i])); d < -1 || d > 1 {
+			t.Errorf("octahedral: %v expected:%v", oct, expected)
+			break
+		}
+	}
+
+	// Quaternions: identity and 90 degrees around Z, whose largest component is w
+	quat := make([]byte, 16)
+	for i, v := range []int16{0, 0, 0, 32767, 0, 0, 32767, 32767} {
+		binary.LittleEndian.PutUint16(quat[i*2:], uint16(v))
+	}
+	if err := meshoptDecodeFilterQuat(quat, 2, 8); err != nil {
+		t.Fatal(err)
+	}
+	var q []int16
+	for i := 0; i < 8; i++ {
+		q = append(q, int16(binary.LittleEndian.Uint16(quat[i*2:])))
+	}
+	if !reflect.DeepEqual(q, []int16{0, 0, 0, 32767, 0, 0, 23170, 23170}) {
+		t.Errorf("quaternions:%v", q)
+	}
+
+	// Exponential: mantissa and exponent of 1.5, -20 and 1
+	exp := make([]byte, 12)
+	for i, me := range [][2]int32{{3, -1}, {-5, 2}, {1, 0}} {
+		binary.LittleEndian.PutUint32(exp[i*4:], uint32(me[1])<<24|uint32(me[0])&0xffffff)
+	}
+	if err := meshoptDecodeFilterExp(exp, 3, 4); err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range []float32{1.5, -20, 1} {
+		if v := math.Float32frombits(binary.LittleEndian.Uint32(exp[i*4:])); v != f {
+			t.Errorf("exponential:%v expected:%v", v, f)
+		}
+	}
+}
+
+// Tests the decoding of a compressed buffer view
+func TestLoadMeshoptBufferView(t *testing.T) {
+
+	buf := append(make([]byte, 3), meshoptIndexData...)
+	g := &GLTF{Buffers: []Buffer{{ByteLength: len(buf), cache: buf}}}
+	ext := map[string]interface{}{
+		"buffer": 0, "byteOffset": 3, "byteLength": len(meshoptIndexData),
+		"byteStride": 2, "count": len(meshoptIndexBuffer), "mode": "TRIANGLES",
+	}
+	data, err := g.loadMeshoptBufferView(ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indices := readIndices(data, 2); !reflect.DeepEqual(indices, meshoptIndexBuffer) {
+		t.Errorf("indices:%v", indices)
+	}
+
+	ext["byteLength"] = len(buf)
+	if _, err := g.loadMeshoptBufferView(ext); err == nil {
+		t.Error("expected invalid range error")
+	}
+	ext["byteLength"] = len(meshoptIndexData)
+	ext["mode"] = "POINTS"
+	if _, err := g.loadMeshoptBufferView(ext); err == nil {
+		t.Error("expected unsupported mode error")
+	}
+}
+
+// readIndices returns the indices of the specified size of the specified data.
+func readIndices(data []byte, size int) []uint32 {
+
+	indices := make([]uint32, len(data)/size)
+	for i := range indices {
+		if size == 2 {
+			indices[i] = uint32(binary.LittleEndian.Uint16(data[i*2:]))
+		} else {
+			indices[i] = binary.LittleEndian.Uint32(data[i*4:])
+		}
+	}
+	return indices
+}
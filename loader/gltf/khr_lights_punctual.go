@@ -0,0 +1,168 @@
+package gltf
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/light"
+	"github.com/g3n/engine/math32"
+)
+
+// LightPunctual describes a light of the KHR_lights_punctual extension.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_lights_punctual
+type LightPunctual struct {
+	Name      string      `json:"name,omitempty"`      // The user-defined name of this light. Not required.
+	Color     *[3]float32 `json:"color,omitempty"`     // The RGB color of the light. Not required. Default is [1,1,1].
+	Intensity *float32    `json:"intensity,omitempty"` // The brightness of the light. Not required. Default is 1.
+	Type      string      `json:"type"`                // The type of the light: "directional", "point" or "spot". Required.
+	Range     *float32    `json:"range,omitempty"`     // The distance cutoff at which the light's intensity reaches zero. Not required.
+	Spot      *LightSpot  `json:"spot,omitempty"`      // The cone of a spot light. Not required.
+}
+
+// LightSpot describes the cone of a spot light of the KHR_lights_punctual extension.
+type LightSpot struct {
+	InnerConeAngle float32  `json:"innerConeAngle,omitempty"` // Angle in radians from the center where the falloff begins. Not required. Default is 0.
+	OuterConeAngle *float32 `json:"outerConeAngle,omitempty"` // Angle in radians from the center where the falloff ends. Not required. Default is PI/4.
+}
+
+// decodeExtension decodes the interface value of an extension into the specified struct.
+func decodeExtension(ext interface{}, v interface{}) error {
+
+	data, err := json.Marshal(ext)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Lights returns the lights defined by the KHR_lights_punctual extension of the asset.
+func (g *GLTF) Lights() ([]LightPunctual, error) {
+
+	ext, ok := g.Extensions[KhrLightsPunctual]
+	if !ok {
+		return nil, nil
+	}
+	var lights struct {
+		Lights []LightPunctual `json:"lights"`
+	}
+	err := decodeExtension(ext, &lights)
+	if err != nil {
+		return nil, err
+	}
+	return lights.Lights, nil
+}
+
+// LoadLight creates and returns a new light described by the specified index
+// in the lights of the KHR_lights_punctual extension.
+// In glTF the lights shine along the -Z axis of their nodes.
+func (g *GLTF) LoadLight(lightIdx int) (core.INode, error) {
+
+	lights, err := g.Lights()
+	if err != nil {
+		return nil, err
+	}
+	if lightIdx < 0 || lightIdx >= len(lights) {
+		return nil, fmt.Errorf("invalid light index")
+	}
+	log.Debug("Loading Light %d", lightIdx)
+	data := lights[lightIdx]
+
+	color := math32.Color{1, 1, 1}
+	if data.Color != nil {
+		color = math32.Color{data.Color[0], data.Color[1], data.Color[2]}
+	}
+	intensity := float32(1)
+	if data.Intensity != nil {
+		intensity = *data.Intensity
+	}
+
+	var in core.INode
+	switch data.Type {
+	case "directional":
+		// The position of the light is set by LoadScene, as g3n directional
+		// lights use their world position as the direction to the light
+		l := light.NewDirectional(&color, intensity)
+		l.SetPosition(0, 0, 1)
+		in = l
+	case "point":
+		// The intensity of punctual lights decreases with the inverse square of the distance
+		l := light.NewPoint(&color, intensity)
+		l.SetLinearDecay(0)
+		l.SetQuadraticDecay(1)
+		in = l
+	case "spot":
+		l := light.NewSpot(&color, intensity)
+		l.SetDirection(0, 0, -1)
+		l.SetLinearDecay(0)
+		l.SetQuadraticDecay(1)
+		outer := float32(math32.Pi / 4)
+		if data.Spot != nil && data.Spot.OuterConeAngle != nil {
+			outer = *data.Spot.OuterConeAngle
+		}
+		l.SetCutoffAngle(math32.RadToDeg(outer))
+		// The falloff is steeper as the inner cone gets closer to the outer one
+		if data.Spot != nil && data.Spot.InnerConeAngle > 0 && data.Spot.InnerConeAngle < outer {
+			l.SetAngularDecay(15 * outer / (outer - data.Spot.InnerConeAngle))
+		}
+		in = l
+	default:
+		return nil, fmt.Errorf("unsupported light type:%s", data.Type)
+	}
+	in.GetNode().SetName(data.Name)
+	return in, nil
+}
+
+// loadNodeLight loads the light referenced by the KHR_lights_punctual extension of a node.
+func (g *GLTF) loadNodeLight(ext interface{}) (core.INode, error) {
+
+	var nodeLight struct {
+		Light int `json:"light"`
+	}
+	err := decodeExtension(ext, &nodeLight)
+	if err != nil {
+		return nil, err
+	}
+	return g.LoadLight(nodeLight.Light)
+}
+
+// PlaceDirectionalLights sets the positions of the directional lights below the specified node
+// so that their world positions, which g3n uses as the direction to the light, are along the +Z axis
+// of their parents, as the glTF directional lights shine along the -Z axis of their nodes.
+// It is called by LoadScene. Nodes loaded with LoadNode have their directional lights
+// at (0,0,1), and this function should be called after they are added to their parents.
+func PlaceDirectionalLights(inode core.INode) {
+
+	// Updates the world matrices from the root, as the directions depend on all the ancestors
+	root := inode
+	for root.GetNode().Parent() != nil {
+		root = root.GetNode().Parent()
+	}
+	root.UpdateMatrixWorld()
+	placeDirectionalLights(inode)
+}
+
+// placeDirectionalLights sets the positions of the directional lights below the specified node,
+// whose world matrices must be updated.
+func placeDirectionalLights(inode core.INode) {
+
+	mw := inode.GetNode().MatrixWorld()
+	for _, child := range inode.GetNode().Children() {
+		placeDirectionalLights(child)
+		l, ok := child.(*light.Directional)
+		if !ok {
+			continue
+		}
+		var pos, scale math32.Vector3
+		var quat math32.Quaternion
+		mw.Decompose(&pos, &quat, &scale)
+		dir := math32.NewVector3(0, 0, 1).ApplyQuaternion(&quat)
+		var inv math32.Matrix4
+		if err := inv.GetInverse(&mw); err != nil {
+			continue
+		}
+		dir.ApplyMatrix4(&inv)
+		l.SetPositionVec(dir)
+	}
+}
@@ -72,6 +72,8 @@ func (g *GLTF) loadMaterialCommon(ext interface{}) (material.IMaterial, error) {
 					return nil, err
 				}
 				diffuse = []float32{1, 1, 1, 1}
+			} else {
+				diffuse = v
 			}
 		}
 
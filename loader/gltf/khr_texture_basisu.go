@@ -0,0 +1,20 @@
+package gltf
+
+import (
+	"fmt"
+	"image"
+)
+
+// loadTextureImage loads the image of the specified texture.
+// The KTX2 images of the KHR_texture_basisu extension are not supported,
+// so textures which use it are loaded from their fallback image.
+func (g *GLTF) loadTextureImage(texData *Texture) (*image.RGBA, error) {
+
+	if texData.noSource {
+		if _, ok := texData.Extensions[KhrTextureBasisu]; ok {
+			return nil, fmt.Errorf("%s is not supported and texture has no fallback image", KhrTextureBasisu)
+		}
+		return nil, fmt.Errorf("texture has no image source")
+	}
+	return g.LoadImage(texData.Source)
+}
@@ -0,0 +1,42 @@
+package gltf
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/texture"
+)
+
+// applyTextureTransform applies the offset, rotation and scale of the KHR_texture_transform
+// extension in the specified texture info extensions, if any, to the specified texture.
+// Only the first texture coordinate set is supported, so a transform which selects
+// another set returns an error.
+// The specification of this extension is at:
+// https://github.com/KhronosGroup/glTF/tree/master/extensions/2.0/Khronos/KHR_texture_transform
+func applyTextureTransform(tex *texture.Texture2D, extensions map[string]interface{}) error {
+
+	ext, ok := extensions[KhrTextureTransform]
+	if !ok {
+		return nil
+	}
+	var transform struct {
+		Offset   *[2]float32 `json:"offset"`
+		Rotation float32     `json:"rotation"`
+		Scale    *[2]float32 `json:"scale"`
+		TexCoord *int        `json:"texCoord"`
+	}
+	err := decodeExtension(ext, &transform)
+	if err != nil {
+		return err
+	}
+	if transform.Offset != nil {
+		tex.SetOffset(transform.Offset[0], transform.Offset[1])
+	}
+	if transform.Scale != nil {
+		tex.SetRepeat(transform.Scale[0], transform.Scale[1])
+	}
+	tex.SetRotation(transform.Rotation)
+	if transform.TexCoord != nil && *transform.TexCoord != 0 {
+		return fmt.Errorf("texture coordinate set %d of %s is not supported", *transform.TexCoord, KhrTextureTransform)
+	}
+	return nil
+}
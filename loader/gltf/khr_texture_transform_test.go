@@ -0,0 +1,42 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"encoding/json"
+	"image"
+	"testing"
+
+	"github.com/g3n/engine/texture"
+)
+
+// Tests that the offset, rotation and scale of texture transforms are applied to textures
+func TestApplyTextureTransform(t *testing.T) {
+
+	var extensions map[string]interface{}
+	err := json.Unmarshal([]byte(`{"KHR_texture_transform": {"offset": [0.5, 0.25], "rotation": 1.5, "scale": [2, 3], "texCoord": 0}}`), &extensions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tex := texture.NewTexture2DFromRGBA(image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	if err := applyTextureTransform(tex, extensions); err != nil {
+		t.Fatal(err)
+	}
+	if x, y := tex.Offset(); x != 0.5 || y != 0.25 {
+		t.Errorf("offset:%v,%v", x, y)
+	}
+	if x, y := tex.Repeat(); x != 2 || y != 3 {
+		t.Errorf("repeat:%v,%v", x, y)
+	}
+	if r := tex.Rotation(); r != 1.5 {
+		t.Errorf("rotation:%v", r)
+	}
+
+	// Other texture coordinate sets are not supported
+	extensions[KhrTextureTransform].(map[string]interface{})["texCoord"] = 1
+	if err := applyTextureTransform(tex, extensions); err == nil {
+		t.Error("expected texture coordinate set error")
+	}
+}
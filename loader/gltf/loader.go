@@ -14,6 +14,7 @@ import (
 	"image/draw"
 	"io"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
 	"strings"
@@ -171,11 +172,14 @@ func (g *GLTF) LoadScene(sceneIdx int) (core.INode, error) {
 		}
 		scene.Add(child)
 	}
+	PlaceDirectionalLights(scene)
 	return scene, nil
 }
 
 // LoadNode creates and returns a new Node described by the specified index
 // in the decoded GLTF Nodes array.
+// The directional lights of the node are placed by PlaceDirectionalLights,
+// which should be called once the node is added to its parent.
 func (g *GLTF) LoadNode(nodeIdx int) (core.INode, error) {
 
 	// Check if provided node index is valid
@@ -235,6 +239,12 @@ func (g *GLTF) LoadNode(nodeIdx int) (core.INode, error) {
 	// Get *core.Node from core.INode
 	node := in.GetNode()
 	node.SetName(nodeData.Name)
+	node.SetUserData(nodeData.Extras)
+
+	// Set the weights of the morph targets of the node mesh
+	if len(nodeData.Weights) > 0 {
+		setMorphWeights(in, nodeData.Weights)
+	}
 
 	// If defined, set node local transformation matrix
 	if nodeData.Matrix != nil {
@@ -255,6 +265,15 @@ func (g *GLTF) LoadNode(nodeIdx int) (core.INode, error) {
 		}
 	}
 
+	// Add the light of the node if any
+	if ext, ok := nodeData.Extensions[KhrLightsPunctual]; ok {
+		l, err := g.loadNodeLight(ext)
+		if err != nil {
+			return nil, err
+		}
+		node.Add(l)
+	}
+
 	// Cache node
 	g.Nodes[nodeIdx].cache = in
 
@@ -270,6 +289,26 @@ func (g *GLTF) LoadNode(nodeIdx int) (core.INode, error) {
 	return in, nil
 }
 
+// setMorphWeights sets the specified weights of the morph geometries of the specified mesh node
+// or of its primitives.
+func setMorphWeights(in core.INode, weights []float32) {
+
+	nodes := []core.INode{in}
+	if _, ok := in.(graphic.IGraphic); !ok {
+		nodes = in.GetNode().Children()
+	}
+	for _, n := range nodes {
+		igr, ok := n.(graphic.IGraphic)
+		if !ok {
+			continue
+		}
+		mg, ok := igr.IGeometry().(*geometry.MorphGeometry)
+		if ok && len(mg.Weights()) == len(weights) {
+			mg.SetWeights(append([]float32(nil), weights...))
+		}
+	}
+}
+
 // LoadSkin loads the skin with specified index.
 func (g *GLTF) LoadSkin(skinIdx int) (*graphic.Skeleton, error) {
 
@@ -365,12 +404,25 @@ func (g *GLTF) LoadAnimation(animIdx int) (*animation.Animation, error) {
 		} else if target.Path == "weights" {
 			validTypes = []string{SCALAR}
 			validComponentTypes = []int{FLOAT, BYTE, UNSIGNED_BYTE, SHORT, UNSIGNED_SHORT}
-			children := node.GetNode().Children()
-			if len(children) > 1 {
-				return nil, fmt.Errorf("animating meshes with more than a single primitive is not supported")
+			// Meshes with a single primitive are loaded as the graphic itself
+			igr, ok := node.(graphic.IGraphic)
+			if !ok {
+				children := node.GetNode().Children()
+				if len(children) != 1 {
+					return nil, fmt.Errorf("animating meshes with more than a single primitive is not supported")
+				}
+				igr, ok = children[0].(graphic.IGraphic)
+			}
+			var morphGeom *geometry.MorphGeometry
+			if ok {
+				morphGeom, ok = igr.IGeometry().(*geometry.MorphGeometry)
+			}
+			if !ok {
+				return nil, fmt.Errorf("animated weights of node without morph targets")
 			}
-			morphGeom := children[0].(graphic.IGraphic).IGeometry().(*geometry.MorphGeometry)
 			ch = animation.NewMorphChannel(morphGeom)
+		} else {
+			return nil, fmt.Errorf("unsupported animation target path:%s", target.Path)
 		}
 
 		// TODO what if Input and Output accessors are interleaved? probably de-interleave in these 2 cases
@@ -383,8 +435,30 @@ func (g *GLTF) LoadAnimation(animIdx int) (*animation.Animation, error) {
 		if err != nil {
 			return nil, err
 		}
+		interp := animation.InterpolationType(sampler.Interpolation)
+		if interp == "" {
+			interp = animation.LINEAR
+		}
+		// Cubic spline outputs contain the in-tangent, value and out-tangent of each keyframe
+		if interp == animation.CUBICSPLINE && len(keyframes) > 0 {
+			size := len(values) / (3 * len(keyframes))
+			inTangent := math32.NewArrayF32(0, len(keyframes)*size)
+			outTangent := math32.NewArrayF32(0, len(keyframes)*size)
+			points := math32.NewArrayF32(0, len(keyframes)*size)
+			for i := 0; i+3*size <= len(values); i += 3 * size {
+				inTangent = append(inTangent, values[i:i+size]...)
+				points = append(points, values[i+size:i+2*size]...)
+				outTangent = append(outTangent, values[i+2*size:i+3*size]...)
+			}
+			values = points
+			if tc, ok := ch.(interface {
+				SetInterpolationTangents(inTangent, outTangent math32.ArrayF32)
+			}); ok {
+				tc.SetInterpolationTangents(inTangent, outTangent)
+			}
+		}
 		ch.SetBuffers(keyframes, values)
-		ch.SetInterpolationType(animation.InterpolationType(sampler.Interpolation))
+		ch.SetInterpolationType(interp)
 		anim.AddChannel(ch)
 	}
 	return anim, nil
@@ -405,7 +479,7 @@ func (g *GLTF) LoadCamera(camIdx int) (core.INode, error) {
 
 	if camData.Type == "perspective" {
 		desc := camData.Perspective
-		fov := 360 * (desc.Yfov) / 2 * math32.Pi
+		fov := math32.RadToDeg(desc.Yfov)
 		if desc.AspectRatio != nil {
 			aspect = *desc.AspectRatio
 		}
@@ -419,7 +493,11 @@ func (g *GLTF) LoadCamera(camIdx int) (core.INode, error) {
 
 	if camData.Type == "orthographic" {
 		desc := camData.Orthographic
-		cam := camera.NewOrthographic(aspect, desc.Znear, desc.Zfar, desc.Ymag, camera.Vertical)
+		if desc.Ymag != 0 {
+			aspect = desc.Xmag / desc.Ymag
+		}
+		// The magnifications are half the size of the view
+		cam := camera.NewOrthographic(aspect, desc.Znear, desc.Zfar, 2*desc.Ymag, camera.Vertical)
 		return cam, nil
 
 	}
@@ -455,6 +533,15 @@ func (g *GLTF) LoadMesh(meshIdx int) (core.INode, error) {
 		// Get primitive information
 		p := meshData.Primitives[i]
 
+		// KHR_draco_mesh_compression is not supported, so compressed primitives
+		// are loaded from their uncompressed fallback data, which is optional
+		if _, ok := p.Extensions[KhrDracoMeshCompression]; ok {
+			if pi, ok := p.Attributes["POSITION"]; !ok || g.Accessors[pi].BufferView == nil {
+				return nil, fmt.Errorf("%s is not supported and primitive has no uncompressed fallback", KhrDracoMeshCompression)
+			}
+			log.Warn("loading uncompressed data of primitive with %s", KhrDracoMeshCompression)
+		}
+
 		// Indexed Geometry
 		indices := math32.NewArrayU32(0, 0)
 		if p.Indices != nil {
@@ -493,9 +580,6 @@ func (g *GLTF) LoadMesh(meshIdx int) (core.INode, error) {
 		if len(p.Targets) > 0 {
 			morphGeom := geometry.NewMorphGeometry(geom)
 
-			// TODO Load morph target names if present in extras under "targetNames"
-			// TODO Update morph target weights if present in Mesh.Weights
-
 			// Load targets
 			for i := range p.Targets {
 				tGeom := geometry.NewGeometry()
@@ -507,6 +591,22 @@ func (g *GLTF) LoadMesh(meshIdx int) (core.INode, error) {
 				morphGeom.AddMorphTargetDeltas(tGeom)
 			}
 
+			// Default weights of the targets
+			if len(meshData.Weights) == len(p.Targets) {
+				morphGeom.SetWeights(append([]float32(nil), meshData.Weights...))
+			}
+
+			// Names of the targets, which are commonly in the extras of the mesh
+			if extras, ok := meshData.Extras.(map[string]interface{}); ok {
+				if names, ok := extras["targetNames"].([]interface{}); ok && len(names) == len(p.Targets) {
+					targetNames := make([]string, len(names))
+					for i, name := range names {
+						targetNames[i], _ = name.(string)
+					}
+					morphGeom.SetTargetNames(targetNames)
+				}
+			}
+
 			igeom = morphGeom
 		}
 
@@ -559,14 +659,23 @@ func (g *GLTF) loadAttributes(geom *geometry.Geometry, attributes map[string]int
 		}
 
 		// Load data and add it to geometry's VBO
-		if g.isInterleaved(accessor) {
+		if accessor.ComponentType != FLOAT || accessor.Sparse != nil || accessor.BufferView == nil {
+			// Integer and sparse attributes are converted to floats in their own VBO
+			data, err := g.loadAccessorValues(accessor)
+			if err != nil {
+				return err
+			}
+			vbo := gls.NewVBO(data)
+			g.addAttributeToVBO(vbo, name, 0, accessor.Type)
+			geom.AddVBO(vbo)
+		} else if g.isInterleaved(accessor) {
 			bvIdx := *accessor.BufferView
 			// Check if we already loaded this buffer view
 			vbo, ok := interleavedVBOs[bvIdx]
 			if ok {
 				// Already created VBO for this buffer view
 				// Add attribute with correct byteOffset
-				g.addAttributeToVBO(vbo, name, uint32(*accessor.ByteOffset))
+				g.addAttributeToVBO(vbo, name, uint32(*accessor.ByteOffset), accessor.Type)
 			} else {
 				// Load data and create vbo
 				buf, err := g.loadBufferView(bvIdx)
@@ -582,7 +691,7 @@ func (g *GLTF) loadAttributes(geom *geometry.Geometry, attributes map[string]int
 					return err
 				}
 				vbo := gls.NewVBO(data)
-				g.addAttributeToVBO(vbo, name, 0)
+				g.addAttributeToVBO(vbo, name, 0, accessor.Type)
 				// Save reference to VBO keyed by index of the buffer view
 				interleavedVBOs[bvIdx] = vbo
 				// Add VBO to geometry
@@ -598,7 +707,7 @@ func (g *GLTF) loadAttributes(geom *geometry.Geometry, attributes map[string]int
 				return err
 			}
 			vbo := gls.NewVBO(data)
-			g.addAttributeToVBO(vbo, name, 0)
+			g.addAttributeToVBO(vbo, name, 0, accessor.Type)
 			// Add VBO to geometry
 			geom.AddVBO(vbo)
 		}
@@ -618,8 +727,10 @@ func (g *GLTF) loadIndices(ai int) (math32.ArrayU32, error) {
 	return g.loadAccessorU32(ai, "indices", []string{SCALAR}, []int{UNSIGNED_BYTE, UNSIGNED_SHORT, UNSIGNED_INT}) // TODO verify that it's ELEMENT_ARRAY_BUFFER
 }
 
-// addAttributeToVBO adds the appropriate attribute to the provided vbo based on the glTF attribute name.
-func (g *GLTF) addAttributeToVBO(vbo *gls.VBO, attribName string, byteOffset uint32) {
+// addAttributeToVBO adds the appropriate attribute to the provided vbo based on the glTF attribute name,
+// with the number of elements of the specified accessor type, such as VEC3 for the tangents of morph
+// targets and VEC4 for the tangents of primitives.
+func (g *GLTF) addAttributeToVBO(vbo *gls.VBO, attribName string, byteOffset uint32, accessorType string) {
 
 	aType, ok := AttributeName[attribName]
 	if !ok {
@@ -627,6 +738,7 @@ func (g *GLTF) addAttributeToVBO(vbo *gls.VBO, attribName string, byteOffset uin
 		return
 	}
 	vbo.AddAttribOffset(aType, byteOffset)
+	vbo.AttribAt(vbo.AttribCount() - 1).NumElements = int32(TypeSizes[accessorType])
 }
 
 // validateAccessorAttribute validates the specified accessor for the given attribute name.
@@ -637,15 +749,17 @@ func (g *GLTF) validateAccessorAttribute(ac Accessor, attribName string) error {
 
 	usage := "attribute " + attribName
 
+	// The integer component types of positions, normals, tangents and texture coordinates
+	// are allowed by the KHR_mesh_quantization extension.
 	if attribName == "POSITION" {
-		return g.validateAccessor(ac, usage, []string{VEC3}, []int{FLOAT})
+		return g.validateAccessor(ac, usage, []string{VEC3}, []int{FLOAT, BYTE, UNSIGNED_BYTE, SHORT, UNSIGNED_SHORT})
 	} else if attribName == "NORMAL" {
-		return g.validateAccessor(ac, usage, []string{VEC3}, []int{FLOAT})
+		return g.validateAccessor(ac, usage, []string{VEC3}, []int{FLOAT, BYTE, SHORT})
 	} else if attribName == "TANGENT" {
 		// Note that morph targets only support VEC3 whereas normal attributes only support VEC4.
-		return g.validateAccessor(ac, usage, []string{VEC3, VEC4}, []int{FLOAT})
+		return g.validateAccessor(ac, usage, []string{VEC3, VEC4}, []int{FLOAT, BYTE, SHORT})
 	} else if semantic == "TEXCOORD" {
-		return g.validateAccessor(ac, usage, []string{VEC2}, []int{FLOAT, UNSIGNED_BYTE, UNSIGNED_SHORT})
+		return g.validateAccessor(ac, usage, []string{VEC2}, []int{FLOAT, BYTE, UNSIGNED_BYTE, SHORT, UNSIGNED_SHORT})
 	} else if semantic == "COLOR" {
 		return g.validateAccessor(ac, usage, []string{VEC3, VEC4}, []int{FLOAT, UNSIGNED_BYTE, UNSIGNED_SHORT})
 	} else if semantic == "JOINTS" {
@@ -711,19 +825,19 @@ func (g *GLTF) LoadMaterial(matIdx int) (material.IMaterial, error) {
 	var err error
 	var imat material.IMaterial
 
-	// Check for material extensions
-	if matData.Extensions != nil {
-		for ext, extData := range matData.Extensions {
-			if ext == KhrMaterialsCommon {
-				imat, err = g.loadMaterialCommon(extData)
-			} else if ext == KhrMaterialsUnlit {
-				//imat, err = g.loadMaterialUnlit(matData, extData)
-				//} else if ext == KhrMaterialsPbrSpecularGlossiness {
-			} else {
-				return nil, fmt.Errorf("unsupported extension:%s", ext)
-			}
+	// Check for material extensions which replace the PBR material
+	// Other extensions, such as KHR_materials_emissive_strength, are applied by loadMaterialPBR
+	for ext, extData := range matData.Extensions {
+		if ext == KhrMaterialsCommon {
+			imat, err = g.loadMaterialCommon(extData)
+		} else if ext == KhrMaterialsUnlit {
+			//imat, err = g.loadMaterialUnlit(matData, extData)
+			//} else if ext == KhrMaterialsPbrSpecularGlossiness {
+		} else if ext != KhrMaterialsEmissiveStrength {
+			log.Warn("unsupported material extension:%s", ext)
 		}
-	} else {
+	}
+	if imat == nil && err == nil {
 		// Material is normally PBR
 		imat, err = g.loadMaterialPBR(&matData)
 	}
@@ -746,7 +860,7 @@ func (g *GLTF) LoadTexture(texIdx int) (*texture.Texture2D, error) {
 	log.Debug("Loading Texture %d", texIdx)
 
 	// Load texture image
-	img, err := g.LoadImage(texData.Source)
+	img, err := g.loadTextureImage(&texData)
 	if err != nil {
 		return nil, err
 	}
@@ -920,14 +1034,155 @@ func (g *GLTF) bytesToArrayF32(data []byte, componentType, count int) (math32.Ar
 	return (*[1 << 30]float32)(unsafe.Pointer(&data[0]))[:count], nil
 }
 
+// componentToFloat converts the component of the specified type at the start of the specified data
+// to a float, mapping it to the [0,1] or [-1,1] range if it is a normalized integer.
+func componentToFloat(data []byte, componentType int, normalized bool) float32 {
+
+	switch componentType {
+	case BYTE:
+		v := float32(int8(data[0]))
+		if normalized {
+			return math32.Max(v/127, -1)
+		}
+		return v
+	case UNSIGNED_BYTE:
+		v := float32(data[0])
+		if normalized {
+			return v / 255
+		}
+		return v
+	case SHORT:
+		v := float32(int16(binary.LittleEndian.Uint16(data)))
+		if normalized {
+			return math32.Max(v/32767, -1)
+		}
+		return v
+	case UNSIGNED_SHORT:
+		v := float32(binary.LittleEndian.Uint16(data))
+		if normalized {
+			return v / 65535
+		}
+		return v
+	case UNSIGNED_INT:
+		return float32(binary.LittleEndian.Uint32(data))
+	default:
+		return math.Float32frombits(binary.LittleEndian.Uint32(data))
+	}
+}
+
+// loadAccessorValues loads the values of the specified accessor as floats, converting integer
+// components, such as the quantized attributes of the KHR_mesh_quantization extension,
+// de-interleaving them if the buffer view has a stride and applying its sparse storage.
+// The values of an accessor without buffer view are initialized with zeros.
+func (g *GLTF) loadAccessorValues(ac Accessor) (math32.ArrayF32, error) {
+
+	compSize, ok := ComponentSizes[ac.ComponentType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported Accessor ComponentType:%v", ac.ComponentType)
+	}
+	itemSize := TypeSizes[ac.Type]
+	values := math32.NewArrayF32(ac.Count*itemSize, ac.Count*itemSize)
+
+	if ac.BufferView != nil {
+		data, err := g.loadBufferView(*ac.BufferView)
+		if err != nil {
+			return nil, err
+		}
+		offset := 0
+		if ac.ByteOffset != nil {
+			offset = *ac.ByteOffset
+		}
+
+		// The elements are tightly packed unless the buffer view has a stride
+		stride := compSize * itemSize
+		bv := g.BufferViews[*ac.BufferView]
+		if bv.ByteStride != nil && *bv.ByteStride > 0 {
+			stride = *bv.ByteStride
+		}
+		if ac.Count > 0 && offset+(ac.Count-1)*stride+compSize*itemSize > len(data) {
+			return nil, fmt.Errorf("accessor data exceeds its buffer view")
+		}
+		for i := 0; i < ac.Count; i++ {
+			elem := data[offset+i*stride:]
+			for j := 0; j < itemSize; j++ {
+				values[i*itemSize+j] = componentToFloat(elem[j*compSize:], ac.ComponentType, ac.Normalized)
+			}
+		}
+	}
+
+	// Replaces the values of the sparse storage
+	if ac.Sparse != nil {
+		indices, data, err := g.loadSparse(ac)
+		if err != nil {
+			return nil, err
+		}
+		for i, idx := range indices {
+			for j := 0; j < itemSize; j++ {
+				values[idx*itemSize+j] = componentToFloat(data[(i*itemSize+j)*compSize:], ac.ComponentType, ac.Normalized)
+			}
+		}
+	}
+	return values, nil
+}
+
+// loadSparse loads the indices of the elements of the sparse storage of the specified accessor
+// and the data of their values, which are tightly packed.
+func (g *GLTF) loadSparse(ac Accessor) ([]int, []byte, error) {
+
+	sparse := ac.Sparse
+	indexSize := 0
+	switch sparse.Indices.ComponentType {
+	case UNSIGNED_BYTE:
+		indexSize = 1
+	case UNSIGNED_SHORT:
+		indexSize = 2
+	case UNSIGNED_INT:
+		indexSize = 4
+	default:
+		return nil, nil, fmt.Errorf("invalid sparse indices ComponentType:%v", sparse.Indices.ComponentType)
+	}
+
+	// Indices
+	data, err := g.loadBufferView(sparse.Indices.BufferView)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sparse.Indices.ByteOffset+sparse.Count*indexSize > len(data) {
+		return nil, nil, fmt.Errorf("sparse indices exceed their buffer view")
+	}
+	data = data[sparse.Indices.ByteOffset:]
+	indices := make([]int, sparse.Count)
+	for i := range indices {
+		switch indexSize {
+		case 1:
+			indices[i] = int(data[i])
+		case 2:
+			indices[i] = int(binary.LittleEndian.Uint16(data[i*2:]))
+		default:
+			indices[i] = int(binary.LittleEndian.Uint32(data[i*4:]))
+		}
+		if indices[i] >= ac.Count {
+			return nil, nil, fmt.Errorf("invalid sparse index:%d", indices[i])
+		}
+	}
+
+	// Values
+	data, err = g.loadBufferView(sparse.Values.BufferView)
+	if err != nil {
+		return nil, nil, err
+	}
+	size := sparse.Count * TypeSizes[ac.Type] * ComponentSizes[ac.ComponentType]
+	if sparse.Values.ByteOffset+size > len(data) {
+		return nil, nil, fmt.Errorf("sparse values exceed their buffer view")
+	}
+	return indices, data[sparse.Values.ByteOffset : sparse.Values.ByteOffset+size], nil
+}
+
 // loadAccessorU32 loads data from the specified accessor and performs validation of the Type and ComponentType.
 func (g *GLTF) loadAccessorU32(ai int, usage string, validTypes []string, validComponentTypes []int) (math32.ArrayU32, error) {
 
 	// Get Accessor for the specified index
 	ac := g.Accessors[ai]
-	if ac.BufferView == nil {
-		return nil, fmt.Errorf("accessor.BufferView == nil NOT SUPPORTED YET") // TODO
-	}
 
 	// Validate type and component type
 	err := g.validateAccessor(ac, usage, validTypes, validComponentTypes)
@@ -935,13 +1190,45 @@ func (g *GLTF) loadAccessorU32(ai int, usage string, validTypes []string, validC
 		return nil, err
 	}
 
+	// Accessors with sparse storage or without buffer view are copied before the sparse values are applied
+	count := ac.Count * TypeSizes[ac.Type]
+	if ac.Sparse != nil || ac.BufferView == nil {
+		values := math32.NewArrayU32(count, count)
+		if ac.BufferView != nil {
+			data, err := g.loadAccessorBytes(ac)
+			if err != nil {
+				return nil, err
+			}
+			base, err := g.bytesToArrayU32(data, ac.ComponentType, count)
+			if err != nil {
+				return nil, err
+			}
+			copy(values, base)
+		}
+		if ac.Sparse != nil {
+			indices, data, err := g.loadSparse(ac)
+			if err != nil {
+				return nil, err
+			}
+			sparse, err := g.bytesToArrayU32(data, ac.ComponentType, len(indices)*TypeSizes[ac.Type])
+			if err != nil {
+				return nil, err
+			}
+			itemSize := TypeSizes[ac.Type]
+			for i, idx := range indices {
+				copy(values[idx*itemSize:(idx+1)*itemSize], sparse[i*itemSize:(i+1)*itemSize])
+			}
+		}
+		return values, nil
+	}
+
 	// Load bytes
 	data, err := g.loadAccessorBytes(ac)
 	if err != nil {
 		return nil, err
 	}
 
-	return g.bytesToArrayU32(data, ac.ComponentType, ac.Count*TypeSizes[ac.Type])
+	return g.bytesToArrayU32(data, ac.ComponentType, count)
 }
 
 // loadAccessorF32 loads data from the specified accessor and performs validation of the Type and ComponentType.
@@ -949,9 +1236,6 @@ func (g *GLTF) loadAccessorF32(ai int, usage string, validTypes []string, validC
 
 	// Get Accessor for the specified index
 	ac := g.Accessors[ai]
-	if ac.BufferView == nil {
-		return nil, fmt.Errorf("accessor.BufferView == nil NOT SUPPORTED YET") // TODO
-	}
 
 	// Validate type and component type
 	err := g.validateAccessor(ac, usage, validTypes, validComponentTypes)
@@ -959,6 +1243,11 @@ func (g *GLTF) loadAccessorF32(ai int, usage string, validTypes []string, validC
 		return nil, err
 	}
 
+	// Accessors with integer components, sparse storage or without buffer view are converted
+	if ac.ComponentType != FLOAT || ac.Sparse != nil || ac.BufferView == nil {
+		return g.loadAccessorValues(ac)
+	}
+
 	// Load bytes
 	data, err := g.loadAccessorBytes(ac)
 	if err != nil {
@@ -1003,8 +1292,6 @@ func (g *GLTF) loadAccessorBytes(ac Accessor) ([]byte, error) {
 		return nil, fmt.Errorf("data is interleaved - not supported for animation yet")
 	}
 
-	// TODO Sparse accessor
-
 	return data, nil
 }
 
@@ -1043,6 +1330,16 @@ func (g *GLTF) loadBufferView(bvIdx int) ([]byte, error) {
 	}
 	log.Debug("Loading BufferView %d", bvIdx)
 
+	// Decode buffer view compressed with the EXT_meshopt_compression extension
+	if ext, ok := bvData.Extensions[ExtMeshoptCompression]; ok {
+		bvBytes, err := g.loadMeshoptBufferView(ext)
+		if err != nil {
+			return nil, err
+		}
+		g.BufferViews[bvIdx].cache = bvBytes
+		return bvBytes, nil
+	}
+
 	// Load buffer view buffer
 	buf, err := g.loadBuffer(bvData.Buffer)
 	if err != nil {
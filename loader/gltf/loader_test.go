@@ -0,0 +1,180 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/math32"
+)
+
+// sparseJSON is a glTF document with accessors with sparse storage,
+// whose buffer is formatted into it as a data URI
+const sparseJSON = `{
+	"asset": {"version": "2.0"},
+	"buffers": [{"uri": "data:application/octet-stream;base64,%s", "byteLength": %d}],
+	"bufferViews": [
+		{"buffer": 0, "byteOffset": 0, "byteLength": 48},
+		{"buffer": 0, "byteOffset": 48, "byteLength": 2},
+		{"buffer": 0, "byteOffset": 52, "byteLength": 24},
+		{"buffer": 0, "byteOffset": 76, "byteLength": 32, "byteStride": 8},
+		{"buffer": 0, "byteOffset": 108, "byteLength": 4},
+		{"buffer": 0, "byteOffset": 112, "byteLength": 2},
+		{"buffer": 0, "byteOffset": 116, "byteLength": 12},
+		{"buffer": 0, "byteOffset": 128, "byteLength": 8},
+		{"buffer": 0, "byteOffset": 136, "byteLength": 4}
+	],
+	"accessors": [
+		{"bufferView": 0, "componentType": 5126, "count": 4, "type": "VEC3",
+		 "sparse": {"count": 2, "indices": {"bufferView": 1, "componentType": 5121}, "values": {"bufferView": 2}}},
+		{"componentType": 5126, "count": 4, "type": "VEC3",
+		 "sparse": {"count": 2, "indices": {"bufferView": 1, "componentType": 5121}, "values": {"bufferView": 2}}},
+		{"bufferView": 3, "componentType": 5122, "normalized": true, "count": 4, "type": "VEC2",
+		 "sparse": {"count": 1, "indices": {"bufferView": 5, "componentType": 5123}, "values": {"bufferView": 4}}},
+		{"bufferView": 6, "componentType": 5123, "count": 6, "type": "SCALAR",
+		 "sparse": {"count": 2, "indices": {"bufferView": 7, "componentType": 5125}, "values": {"bufferView": 8}}},
+		{"bufferView": 0, "componentType": 5126, "count": 2, "type": "VEC3",
+		 "sparse": {"count": 2, "indices": {"bufferView": 1, "componentType": 5121}, "values": {"bufferView": 2}}},
+		{"bufferView": 0, "componentType": 5126, "count": 4, "type": "VEC3",
+		 "sparse": {"count": 3, "indices": {"bufferView": 1, "componentType": 5121}, "values": {"bufferView": 2}}}
+	],
+	"meshes": [{"primitives": [{"attributes": {"POSITION": 0}, "targets": [{"POSITION": 1}]}]}]
+}`
+
+// sparseBuffer returns the data of the buffer of the sparse accessors document.
+func sparseBuffer() []byte {
+
+	var buf bytes.Buffer
+	put := func(values ...interface{}) {
+		for _, v := range values {
+			binary.Write(&buf, binary.LittleEndian, v)
+		}
+	}
+	// 0: positions of 4 vertices
+	put([]float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0})
+	// 48: sparse indices of the positions, padded
+	put([]uint8{3, 1, 0, 0})
+	// 52: sparse values of the positions
+	put([]float32{5, 6, 7, 8, 9, 10})
+	// 76: interleaved normalized shorts with 4 bytes of padding
+	for _, v := range []int16{32767, 0, 0, -32767, 16384, 16384, -32768, 32767} {
+		put(v)
+		if buf.Len()%8 == 0 {
+			put(uint32(0xffffffff))
+		}
+	}
+	// 108: sparse value of the shorts and 112: its index, padded
+	put([]int16{0, 32767}, []uint16{2, 0})
+	// 116: unsigned short indices
+	put([]uint16{0, 1, 2, 0, 2, 3})
+	// 128: sparse indices of the indices and 136: their values
+	put([]uint32{0, 5}, []uint16{9, 8})
+	return buf.Bytes()
+}
+
+// parseSparseJSON parses the sparse accessors document.
+func parseSparseJSON(t *testing.T) *GLTF {
+
+	data := sparseBuffer()
+	doc := fmt.Sprintf(sparseJSON, base64.StdEncoding.EncodeToString(data), len(data))
+	g, err := ParseJSONReader(strings.NewReader(doc), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// Tests the values of accessors with sparse storage
+func TestSparseAccessors(t *testing.T) {
+
+	g := parseSparseJSON(t)
+	floatTypes := []int{FLOAT, SHORT}
+	vecTypes := []string{VEC2, VEC3}
+
+	// Sparse values replacing the values of a buffer view and of an accessor without buffer view
+	values, err := g.loadAccessorF32(0, "test", vecTypes, floatTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []float32{0, 0, 0, 8, 9, 10, 1, 1, 0, 5, 6, 7}; !reflect.DeepEqual([]float32(values), expected) {
+		t.Errorf("values:%v expected:%v", values, expected)
+	}
+	values, err = g.loadAccessorF32(1, "test", vecTypes, floatTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []float32{0, 0, 0, 8, 9, 10, 0, 0, 0, 5, 6, 7}; !reflect.DeepEqual([]float32(values), expected) {
+		t.Errorf("values without buffer view:%v expected:%v", values, expected)
+	}
+
+	// Sparse normalized value replacing an interleaved normalized value
+	values, err = g.loadAccessorF32(2, "test", vecTypes, floatTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float32{1, 0, 0, -1, 0, 1, -1, 1}
+	for i := range expected {
+		if math32.Abs(values[i]-expected[i]) > 1e-6 {
+			t.Errorf("normalized values:%v expected:%v", values, expected)
+			break
+		}
+	}
+
+	// Sparse integer values
+	indices, err := g.loadAccessorU32(3, "test", []string{SCALAR}, []int{UNSIGNED_SHORT})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []uint32{9, 1, 2, 0, 2, 8}; !reflect.DeepEqual([]uint32(indices), expected) {
+		t.Errorf("indices:%v expected:%v", indices, expected)
+	}
+
+	// Sparse index out of the range of the accessor and indices exceeding their buffer view
+	if _, err := g.loadAccessorF32(4, "test", vecTypes, floatTypes); err == nil {
+		t.Error("expected invalid sparse index error")
+	}
+	if _, err := g.loadAccessorF32(5, "test", vecTypes, floatTypes); err == nil {
+		t.Error("expected sparse indices range error")
+	}
+}
+
+// Tests the geometry of a mesh whose positions and morph target have sparse storage
+func TestSparseMesh(t *testing.T) {
+
+	g := parseSparseJSON(t)
+	node, err := g.LoadMesh(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mesh, ok := node.(*graphic.Mesh)
+	if !ok {
+		t.Fatalf("mesh node:%T", node)
+	}
+	morph, ok := mesh.IGeometry().(*geometry.MorphGeometry)
+	if !ok {
+		t.Fatalf("geometry:%T", mesh.IGeometry())
+	}
+	positions := *morph.GetGeometry().VBO(gls.VertexPosition).Buffer()
+	if expected := []float32{0, 0, 0, 8, 9, 10, 1, 1, 0, 5, 6, 7}; !reflect.DeepEqual([]float32(positions), expected) {
+		t.Errorf("positions:%v expected:%v", positions, expected)
+	}
+	targets := morph.MorphTargets()
+	if len(targets) != 1 || len(targets[0].VBOs()) != 1 {
+		t.Fatalf("morph targets:%v", targets)
+	}
+	deltas := *targets[0].VBOs()[0].Buffer()
+	if expected := []float32{0, 0, 0, 8, 9, 10, 0, 0, 0, 5, 6, 7}; !reflect.DeepEqual([]float32(deltas), expected) {
+		t.Errorf("target deltas:%v expected:%v", deltas, expected)
+	}
+}
@@ -1,18 +1,16 @@
 package gltf
 
 import (
-	"fmt"
-
 	"github.com/g3n/engine/material"
 	"github.com/g3n/engine/math32"
 )
 
 func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 
-	// Get pbr information
+	// Get pbr information, which has default values when not supplied
 	pbr := m.PbrMetallicRoughness
 	if pbr == nil {
-		return nil, fmt.Errorf("PbrMetallicRoughness not supplied")
+		pbr = &PbrMetallicRoughness{}
 	}
 
 	// Create new physically based material
@@ -84,6 +82,19 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 			emissiveFactor = math32.Color{0,0,0}
 		}
 	}
+	// KHR_materials_emissive_strength multiplies the emissive factor
+	if ext, ok := m.Extensions[KhrMaterialsEmissiveStrength]; ok {
+		var strength struct {
+			EmissiveStrength *float32 `json:"emissiveStrength"`
+		}
+		err := decodeExtension(ext, &strength)
+		if err != nil {
+			return nil, err
+		}
+		if strength.EmissiveStrength != nil {
+			emissiveFactor.MultiplyScalar(*strength.EmissiveStrength)
+		}
+	}
 	pm.SetEmissiveFactor(&emissiveFactor)
 
 	// BaseColorTexture
@@ -92,6 +103,10 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 		if err != nil {
 			return nil, err
 		}
+		err = applyTextureTransform(tex, pbr.BaseColorTexture.Extensions)
+		if err != nil {
+			return nil, err
+		}
 		pm.SetBaseColorMap(tex)
 	}
 
@@ -101,6 +116,10 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 		if err != nil {
 			return nil, err
 		}
+		err = applyTextureTransform(tex, pbr.MetallicRoughnessTexture.Extensions)
+		if err != nil {
+			return nil, err
+		}
 		pm.SetMetallicRoughnessMap(tex)
 	}
 
@@ -110,6 +129,10 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 		if err != nil {
 			return nil, err
 		}
+		err = applyTextureTransform(tex, m.NormalTexture.Extensions)
+		if err != nil {
+			return nil, err
+		}
 		pm.SetNormalMap(tex)
 	}
 
@@ -119,6 +142,10 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 		if err != nil {
 			return nil, err
 		}
+		err = applyTextureTransform(tex, m.OcclusionTexture.Extensions)
+		if err != nil {
+			return nil, err
+		}
 		pm.SetOcclusionMap(tex)
 	}
 
@@ -128,6 +155,10 @@ func (g *GLTF) loadMaterialPBR(m *Material) (material.IMaterial, error) {
 		if err != nil {
 			return nil, err
 		}
+		err = applyTextureTransform(tex, m.EmissiveTexture.Extensions)
+		if err != nil {
+			return nil, err
+		}
 		pm.SetEmissiveMap(tex)
 	}
 
@@ -0,0 +1,158 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+)
+
+// encodedProperty is a vertex property written by Encode
+type encodedProperty struct {
+	name   string    // property name
+	dtype  string    // data type
+	values []float32 // values of all the vertices
+	offset int       // offset of the property in the values of each vertex
+	stride int       // number of values of each vertex
+}
+
+// Encode writes the vertices and the triangles of the specified geometry to the specified
+// writer in the specified PLY encoding. The positions, normals, texture coordinates and colors
+// of the vertices are written as the properties read by NewGeometry, the colors as unsigned chars.
+// Custom attributes with one element are written as float properties with their names.
+// The vertices are not transformed.
+func Encode(w io.Writer, igeom geometry.IGeometry, format Format) error {
+
+	geom := igeom.GetGeometry()
+	vbo := geom.VBO(gls.VertexPosition)
+	if vbo == nil {
+		return fmt.Errorf("geometry has no positions")
+	}
+	count := len(attribValues(vbo, *vbo.Attrib(gls.VertexPosition))) / 3
+
+	// Gets the vertex properties from the attributes of the geometry
+	var props []encodedProperty
+	add := func(values []float32, dtype string, names ...string) {
+		if len(values) != count*len(names) {
+			return
+		}
+		for i, name := range names {
+			props = append(props, encodedProperty{name, dtype, values, i, len(names)})
+		}
+	}
+	for _, vbo := range geom.VBOs() {
+		for _, attrib := range vbo.Attributes() {
+			values := attribValues(vbo, attrib)
+			switch attrib.Type {
+			case gls.VertexPosition:
+				add(values, "float", "x", "y", "z")
+			case gls.VertexNormal:
+				add(values, "float", "nx", "ny", "nz")
+			case gls.VertexTexcoord:
+				add(values, "float", "s", "t")
+			case gls.VertexColor:
+				add(values, "uchar", "red", "green", "blue")
+			case gls.Undefined:
+				if attrib.NumElements == 1 {
+					add(values, "float", attrib.Name)
+				}
+			}
+		}
+	}
+
+	// Gets the triangles, which are sequential if the geometry is not indexed
+	var faces []uint32
+	if geom.Indexed() {
+		faces = geom.Indices().ToUint32()
+	} else {
+		for i := 0; i+2 < count; i += 3 {
+			faces = append(faces, uint32(i), uint32(i+1), uint32(i+2))
+		}
+	}
+
+	// Writes the header
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "ply\nformat %s 1.0\n", formatNames[format])
+	fmt.Fprintf(bw, "element vertex %d\n", count)
+	for _, p := range props {
+		fmt.Fprintf(bw, "property %s %s\n", p.dtype, p.name)
+	}
+	fmt.Fprintf(bw, "element face %d\n", len(faces)/3)
+	fmt.Fprintf(bw, "property list uchar int vertex_indices\n")
+	fmt.Fprintf(bw, "end_header\n")
+
+	// Writes the data
+	var order binary.ByteOrder = binary.LittleEndian
+	if format == BinaryBigEndian {
+		order = binary.BigEndian
+	}
+	var buf [4]byte
+	write := func(v float64, dtype string, last bool) {
+		switch {
+		case format == ASCII && dtype == "float":
+			bw.WriteString(strconv.FormatFloat(v, 'g', -1, 32))
+		case format == ASCII:
+			bw.WriteString(strconv.FormatInt(int64(v), 10))
+		case dtype == "float":
+			order.PutUint32(buf[:], math.Float32bits(float32(v)))
+			bw.Write(buf[:4])
+		case dtype == "int":
+			order.PutUint32(buf[:], uint32(int32(v)))
+			bw.Write(buf[:4])
+		default:
+			bw.WriteByte(byte(v))
+		}
+		if format == ASCII {
+			if last {
+				bw.WriteByte('\n')
+			} else {
+				bw.WriteByte(' ')
+			}
+		}
+	}
+	for i := 0; i < count; i++ {
+		for j, p := range props {
+			v := float64(p.values[i*p.stride+p.offset])
+			if p.dtype == "uchar" {
+				v = math.Round(math.Max(0, math.Min(1, v)) * 255)
+			}
+			write(v, p.dtype, j == len(props)-1)
+		}
+	}
+	for i := 0; i+2 < len(faces); i += 3 {
+		write(3, "uchar", false)
+		write(float64(faces[i]), "int", false)
+		write(float64(faces[i+1]), "int", false)
+		write(float64(faces[i+2]), "int", true)
+	}
+	return bw.Flush()
+}
+
+// attribValues returns the values of the specified attribute of the specified VBO,
+// which may be interleaved with the values of other attributes.
+func attribValues(vbo *gls.VBO, attrib gls.VBOattrib) []float32 {
+
+	buf := *vbo.Buffer()
+	stride := vbo.Stride()
+	if stride == 0 {
+		return nil
+	}
+	size := int(attrib.NumElements)
+	offset := int(attrib.ByteOffset) / 4
+	count := len(buf) / stride
+	values := make([]float32, 0, count*size)
+	for i := 0; i < count; i++ {
+		base := i*stride + offset
+		values = append(values, buf[base:base+size]...)
+	}
+	return values
+}
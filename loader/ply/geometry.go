@@ -0,0 +1,138 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ply
+
+import (
+	"fmt"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// texcoordNames are the pairs of names of the texture coordinates properties used by PLY files
+var texcoordNames = [][2]string{{"s", "t"}, {"u", "v"}, {"texture_u", "texture_v"}, {"texture_s", "texture_t"}}
+
+// typeMax maps the integer data types to their maximum values, which are used to normalize colors
+var typeMax = map[string]float64{
+	"char": 127, "uchar": 255, "short": 32767, "ushort": 65535, "int": 2147483647, "uint": 4294967295,
+}
+
+// NewGeometry creates and returns a geometry from the decoded "vertex" and "face" elements.
+// The vertex properties x, y and z are the positions, nx, ny and nz the normals, s and t or u and v
+// the texture coordinates and red, green and blue the colors, whose integer values are normalized.
+// The other scalar vertex properties are added as custom attributes of one element with their names.
+// The faces, whose vertex indices are in their "vertex_indices" or "vertex_index" list property,
+// are triangulated. If there are no faces the geometry is not indexed.
+func (dec *Decoder) NewGeometry() (*geometry.Geometry, error) {
+
+	vertex := dec.Element("vertex")
+	if vertex == nil {
+		return nil, fmt.Errorf("PLY file has no vertex element")
+	}
+	used := make(map[string]bool)
+	values := func(names ...string) []*Property {
+		var props []*Property
+		for _, name := range names {
+			p := vertex.Property(name)
+			if p == nil || p.CountType != "" {
+				return nil
+			}
+			props = append(props, p)
+		}
+		for _, name := range names {
+			used[name] = true
+		}
+		return props
+	}
+	geom := geometry.NewGeometry()
+	// addVBO adds a VBO with the values of the specified properties, which are normalized if norm is true.
+	addVBO := func(props []*Property, norm bool) *gls.VBO {
+		buf := math32.NewArrayF32(0, vertex.Count*len(props))
+		for i := 0; i < vertex.Count; i++ {
+			for _, p := range props {
+				v := p.Values[i]
+				if max, ok := typeMax[p.Type]; ok && norm {
+					v /= max
+				}
+				buf.Append(float32(v))
+			}
+		}
+		vbo := gls.NewVBO(buf)
+		geom.AddVBO(vbo)
+		return vbo
+	}
+
+	positions := values("x", "y", "z")
+	if positions == nil {
+		return nil, fmt.Errorf("PLY vertex element has no x, y and z properties")
+	}
+	addVBO(positions, false).AddAttrib(gls.VertexPosition)
+	if normals := values("nx", "ny", "nz"); normals != nil {
+		addVBO(normals, false).AddAttrib(gls.VertexNormal)
+	}
+	for _, names := range texcoordNames {
+		if uvs := values(names[0], names[1]); uvs != nil {
+			addVBO(uvs, false).AddAttrib(gls.VertexTexcoord)
+			break
+		}
+	}
+	if colors := values("red", "green", "blue"); colors != nil {
+		addVBO(colors, true).AddAttrib(gls.VertexColor)
+	} else if colors := values("diffuse_red", "diffuse_green", "diffuse_blue"); colors != nil {
+		addVBO(colors, true).AddAttrib(gls.VertexColor)
+	}
+	for _, p := range vertex.Properties {
+		if !used[p.Name] && p.CountType == "" {
+			addVBO([]*Property{p}, false).AddCustomAttrib(p.Name, 1)
+		}
+	}
+
+	// Triangulates the faces as fans
+	face := dec.Element("face")
+	if face == nil {
+		return geom, nil
+	}
+	p := face.Property("vertex_indices")
+	if p == nil {
+		p = face.Property("vertex_index")
+	}
+	if p == nil || p.CountType == "" {
+		return nil, fmt.Errorf("PLY face element has no vertex_indices property")
+	}
+	indices := math32.NewArrayU32(0, len(p.Lists)*3)
+	for _, list := range p.Lists {
+		for _, idx := range list {
+			if idx < 0 || int(idx) >= vertex.Count {
+				return nil, fmt.Errorf("invalid PLY vertex index:%v", idx)
+			}
+		}
+		for i := 1; i < len(list)-1; i++ {
+			indices.Append(uint32(list[0]), uint32(list[i]), uint32(list[i+1]))
+		}
+	}
+	geom.SetIndices(indices)
+	return geom, nil
+}
+
+// NewMesh creates and returns a mesh with the decoded faces and the specified material.
+// If the material is nil, a light gray standard material is used.
+// The vertex colors are only used by materials which support them, such as the basic material.
+func (dec *Decoder) NewMesh(mat material.IMaterial) (*graphic.Mesh, error) {
+
+	if dec.Element("face") == nil {
+		return nil, fmt.Errorf("PLY file has no face element")
+	}
+	geom, err := dec.NewGeometry()
+	if err != nil {
+		return nil, err
+	}
+	if mat == nil {
+		mat = material.NewStandard(&math32.Color{0.7, 0.7, 0.7})
+	}
+	return graphic.NewMesh(geom, mat), nil
+}
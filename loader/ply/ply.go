@@ -0,0 +1,337 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ply is used to parse and write the Polygon File Format (*.ply), also known as
+// the Stanford Triangle Format, in its ASCII and binary encodings. All the elements and
+// properties of the files are decoded, including properties which are not used by g3n.
+// Basic format info: http://paulbourke.net/dataformats/ply/
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Format is the encoding of a PLY file
+type Format int
+
+// PLY file encodings
+const (
+	ASCII              = Format(iota) // ASCII encoding
+	BinaryLittleEndian                // Binary encoding with little endian byte order
+	BinaryBigEndian                   // Binary encoding with big endian byte order
+)
+
+// formatNames maps the encodings to their names in the header
+var formatNames = map[Format]string{
+	ASCII:              "ascii",
+	BinaryLittleEndian: "binary_little_endian",
+	BinaryBigEndian:    "binary_big_endian",
+}
+
+// typeSizes maps the names of the data types to their sizes in bytes
+var typeSizes = map[string]int{
+	"char": 1, "uchar": 1, "short": 2, "ushort": 2, "int": 4, "uint": 4, "float": 4, "double": 8,
+}
+
+// typeAliases maps the alternative names of the data types to their names
+var typeAliases = map[string]string{
+	"int8": "char", "uint8": "uchar", "int16": "short", "uint16": "ushort",
+	"int32": "int", "uint32": "uint", "float32": "float", "float64": "double",
+}
+
+// maxListCount is the maximum number of values of a list property of an element
+const maxListCount = 1 << 16
+
+// maxPrealloc is the maximum number of values allocated before they are read, so the
+// counts of the header, which may be invalid, don't make the decoder allocate too much memory
+const maxPrealloc = 1 << 16
+
+// Decoder contains all decoded data from a PLY file
+type Decoder struct {
+	Format   Format     // file encoding
+	Comments []string   // comments of the header
+	Elements []*Element // elements in file order
+}
+
+// Element contains the values of the properties of an element type, such as "vertex" or "face"
+type Element struct {
+	Name       string      // element name
+	Count      int         // number of elements
+	Properties []*Property // element properties in file order
+}
+
+// Property contains the values of a property of all the elements of an element type.
+// Scalar properties have a value per element and list properties have a list of values per element.
+type Property struct {
+	Name      string      // property name
+	Type      string      // data type of the values: char, uchar, short, ushort, int, uint, float or double
+	CountType string      // data type of the number of values of list properties or empty for scalar properties
+	Values    []float64   // values of scalar properties
+	Lists     [][]float64 // values of list properties
+}
+
+// Decode decodes the specified PLY file returning a decoder object and an error.
+func Decode(path string) (*Decoder, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DecodeReader(f)
+}
+
+// DecodeReader decodes the specified PLY reader returning a decoder object and an error.
+func DecodeReader(r io.Reader) (*Decoder, error) {
+
+	br := bufio.NewReader(r)
+	dec := new(Decoder)
+	err := dec.decodeHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if dec.Format == ASCII {
+		err = dec.decodeASCII(br)
+	} else {
+		err = dec.decodeBinary(br)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dec, nil
+}
+
+// Element returns the element type with the specified name or nil if not found.
+func (dec *Decoder) Element(name string) *Element {
+
+	for _, e := range dec.Elements {
+		if e.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Property returns the property with the specified name or nil if not found.
+func (e *Element) Property(name string) *Property {
+
+	if e == nil {
+		return nil
+	}
+	for _, p := range e.Properties {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// decodeHeader decodes the header of the file up to and including its "end_header" line.
+func (dec *Decoder) decodeHeader(br *bufio.Reader) error {
+
+	line := 0
+	readLine := func() (string, error) {
+		s, err := br.ReadString('\n')
+		if err != nil && (err != io.EOF || s == "") {
+			return "", fmt.Errorf("unexpected end of PLY header")
+		}
+		line++
+		return strings.TrimRight(s, "\r\n"), nil
+	}
+	formatError := func(msg string) error {
+		return fmt.Errorf("%s in PLY header line:%d", msg, line)
+	}
+
+	s, err := readLine()
+	if err != nil || s != "ply" {
+		return fmt.Errorf("invalid PLY file")
+	}
+	var element *Element
+	hasFormat := false
+	for {
+		s, err = readLine()
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(s)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 3 || fields[2] != "1.0" {
+				return formatError("unsupported format")
+			}
+			found := false
+			for f, name := range formatNames {
+				if name == fields[1] {
+					dec.Format = f
+					found = true
+				}
+			}
+			if !found {
+				return formatError("unsupported format")
+			}
+			hasFormat = true
+		case "comment", "obj_info":
+			dec.Comments = append(dec.Comments, strings.TrimSpace(strings.TrimPrefix(s, fields[0])))
+		case "element":
+			if len(fields) != 3 {
+				return formatError("invalid element")
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return formatError("invalid element count")
+			}
+			element = &Element{Name: fields[1], Count: count}
+			dec.Elements = append(dec.Elements, element)
+		case "property":
+			if element == nil {
+				return formatError("property without element")
+			}
+			p := new(Property)
+			if len(fields) == 5 && fields[1] == "list" {
+				p.CountType, p.Type, p.Name = dataType(fields[2]), dataType(fields[3]), fields[4]
+				if typeSizes[p.CountType] == 0 || p.CountType == "float" || p.CountType == "double" {
+					return formatError("invalid list count type")
+				}
+			} else if len(fields) == 3 {
+				p.Type, p.Name = dataType(fields[1]), fields[2]
+			} else {
+				return formatError("invalid property")
+			}
+			if typeSizes[p.Type] == 0 {
+				return formatError("invalid property type")
+			}
+			element.Properties = append(element.Properties, p)
+		case "end_header":
+			if !hasFormat {
+				return formatError("missing format")
+			}
+			return nil
+		default:
+			return formatError("unexpected " + fields[0])
+		}
+	}
+}
+
+// dataType returns the name of the specified data type or alias.
+func dataType(name string) string {
+
+	if t, ok := typeAliases[name]; ok {
+		return t
+	}
+	return name
+}
+
+// decodeASCII decodes the values of the elements of an ASCII file.
+func (dec *Decoder) decodeASCII(br *bufio.Reader) error {
+
+	scanner := bufio.NewScanner(br)
+	scanner.Split(bufio.ScanWords)
+	next := func(string) (float64, error) {
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return 0, err
+			}
+			return 0, fmt.Errorf("unexpected end of PLY data")
+		}
+		v, err := strconv.ParseFloat(scanner.Text(), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid PLY value:%s", scanner.Text())
+		}
+		return v, nil
+	}
+	return dec.decodeValues(next)
+}
+
+// decodeBinary decodes the values of the elements of a binary file.
+func (dec *Decoder) decodeBinary(br *bufio.Reader) error {
+
+	var order binary.ByteOrder = binary.LittleEndian
+	if dec.Format == BinaryBigEndian {
+		order = binary.BigEndian
+	}
+	var buf [8]byte
+	read := func(dtype string) (float64, error) {
+		b := buf[:typeSizes[dtype]]
+		if _, err := io.ReadFull(br, b); err != nil {
+			return 0, fmt.Errorf("unexpected end of PLY data")
+		}
+		switch dtype {
+		case "char":
+			return float64(int8(b[0])), nil
+		case "uchar":
+			return float64(b[0]), nil
+		case "short":
+			return float64(int16(order.Uint16(b))), nil
+		case "ushort":
+			return float64(order.Uint16(b)), nil
+		case "int":
+			return float64(int32(order.Uint32(b))), nil
+		case "uint":
+			return float64(order.Uint32(b)), nil
+		case "float":
+			return float64(math.Float32frombits(order.Uint32(b))), nil
+		default:
+			return math.Float64frombits(order.Uint64(b)), nil
+		}
+	}
+	return dec.decodeValues(read)
+}
+
+// decodeValues decodes the values of the elements with the specified function
+// which reads the next value of the specified data type.
+func (dec *Decoder) decodeValues(read func(dtype string) (float64, error)) error {
+
+	for _, e := range dec.Elements {
+		prealloc := e.Count
+		if prealloc > maxPrealloc {
+			prealloc = maxPrealloc
+		}
+		for _, p := range e.Properties {
+			if p.CountType == "" {
+				p.Values = make([]float64, 0, prealloc)
+			} else {
+				p.Lists = make([][]float64, 0, prealloc)
+			}
+		}
+		for i := 0; i < e.Count; i++ {
+			for _, p := range e.Properties {
+				if p.CountType == "" {
+					v, err := read(p.Type)
+					if err != nil {
+						return err
+					}
+					p.Values = append(p.Values, v)
+					continue
+				}
+				count, err := read(p.CountType)
+				if err != nil {
+					return err
+				}
+				if count < 0 || count > maxListCount {
+					return fmt.Errorf("invalid PLY list count:%v", count)
+				}
+				list := make([]float64, int(count))
+				for j := range list {
+					list[j], err = read(p.Type)
+					if err != nil {
+						return err
+					}
+				}
+				p.Lists = append(p.Lists, list)
+			}
+		}
+	}
+	return nil
+}
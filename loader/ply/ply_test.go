@@ -0,0 +1,217 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ply
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// asciiQuad is an ASCII file with a colored quad and a custom vertex property
+const asciiQuad = `ply
+format ascii 1.0
+comment made by hand
+element vertex 4
+property float x
+property float y
+property float z
+property uchar red
+property uchar green
+property uchar blue
+property float quality
+element face 1
+property list uchar int vertex_indices
+end_header
+0 0 0 255 0 0 0.5
+1 0 0 0 255 0 1
+1 1 0 0 0 255 1.5
+0 1 0 255 255 255 2
+4 0 1 2 3
+`
+
+// Tests the decoding of an ASCII file and the geometry created from it
+func TestDecodeASCII(t *testing.T) {
+
+	dec, err := DecodeReader(strings.NewReader(asciiQuad))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Format != ASCII {
+		t.Errorf("format:%v", dec.Format)
+	}
+	if !reflect.DeepEqual(dec.Comments, []string{"made by hand"}) {
+		t.Errorf("comments:%q", dec.Comments)
+	}
+	if x := dec.Element("vertex").Property("x"); x == nil || !reflect.DeepEqual(x.Values, []float64{0, 1, 1, 0}) {
+		t.Errorf("x:%v", x)
+	}
+	if p := dec.Element("face").Property("vertex_indices"); p == nil || !reflect.DeepEqual(p.Lists, [][]float64{{0, 1, 2, 3}}) {
+		t.Errorf("vertex_indices:%v", p)
+	}
+
+	geom, err := dec.NewGeometry()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkValues(t, "positions", attribValues(geom.VBO(gls.VertexPosition), *geom.VBO(gls.VertexPosition).Attrib(gls.VertexPosition)),
+		[]float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0})
+	checkValues(t, "colors", attribValues(geom.VBO(gls.VertexColor), *geom.VBO(gls.VertexColor).Attrib(gls.VertexColor)),
+		[]float32{1, 0, 0, 0, 1, 0, 0, 0, 1, 1, 1, 1})
+	checkValues(t, "quality", attribValues(geom.VBOName("quality"), *geom.VBOName("quality").AttribName("quality")),
+		[]float32{0.5, 1, 1.5, 2})
+	// The quad is triangulated as a fan
+	if indices := geom.Indices(); !reflect.DeepEqual(indices.ToUint32(), []uint32{0, 1, 2, 0, 2, 3}) {
+		t.Errorf("indices:%v", indices)
+	}
+}
+
+// Tests the decoding of a big endian binary file with signed and unsigned types and aliases
+func TestDecodeBinaryBigEndian(t *testing.T) {
+
+	var buf bytes.Buffer
+	buf.WriteString("ply\nformat binary_big_endian 1.0\nelement value 2\n" +
+		"property int8 a\nproperty ushort b\nproperty int c\nproperty double d\n" +
+		"property list uint8 uint16 l\nend_header\n")
+	for _, v := range []interface{}{
+		int8(-1), uint16(65535), int32(-70000), float64(0.25), uint8(2), uint16(7), uint16(8),
+		int8(127), uint16(1), int32(70000), float64(-2), uint8(0),
+	} {
+		binary.Write(&buf, binary.BigEndian, v)
+	}
+
+	dec, err := DecodeReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := dec.Element("value")
+	for name, expected := range map[string][]float64{
+		"a": {-1, 127}, "b": {65535, 1}, "c": {-70000, 70000}, "d": {0.25, -2},
+	} {
+		p := e.Property(name)
+		if p == nil || !reflect.DeepEqual(p.Values, expected) {
+			t.Errorf("%s:%v expected:%v", name, p, expected)
+		}
+	}
+	if p := e.Property("l"); p.Type != "ushort" || p.CountType != "uchar" || !reflect.DeepEqual(p.Lists, [][]float64{{7, 8}, {}}) {
+		t.Errorf("l:%+v", p)
+	}
+}
+
+// Tests the errors of invalid files
+func TestDecodeErrors(t *testing.T) {
+
+	header := "ply\nformat binary_little_endian 1.0\nelement vertex 1\nproperty list uint float x\nend_header\n"
+	for _, data := range []string{
+		"",
+		"ply\nend_header\n",
+		"ply\nformat ascii 2.0\nend_header\n",
+		"ply\nformat ascii 1.0\nproperty float x\nend_header\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty list float float x\nend_header\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nend_header\n",
+		"ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nend_header\nnan?\n",
+		// Too many elements and list values for the data
+		"ply\nformat binary_little_endian 1.0\nelement vertex 2000000000\nproperty float x\nend_header\n",
+		header + "\xff\xff\xff\xff",
+		header + "\x00\x00\x01\x00",
+	} {
+		if _, err := DecodeReader(strings.NewReader(data)); err == nil {
+			t.Errorf("expected error decoding %q", data)
+		}
+	}
+
+	// Face with an invalid vertex index
+	dec, err := DecodeReader(strings.NewReader(strings.Replace(asciiQuad, "4 0 1 2 3", "3 0 1 4", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dec.NewGeometry(); err == nil {
+		t.Error("expected invalid vertex index error")
+	}
+}
+
+// Tests that the geometries written in all encodings are decoded with the same attributes and faces
+func TestEncodeRoundTrip(t *testing.T) {
+
+	positions := []float32{0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0}
+	normals := []float32{0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1}
+	uvs := []float32{0, 0, 1, 0, 1, 1, 0, 1}
+	colors := []float32{1, 0, 0, 0, 1, 0, 0, 0, 1, 0.2, 0.4, 0.6}
+	weights := []float32{0.5, 1, 1.5, 2}
+	indices := []uint32{0, 1, 2, 0, 2, 3}
+
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(math32.ArrayF32(positions)).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(math32.ArrayF32(normals)).AddAttrib(gls.VertexNormal))
+	geom.AddVBO(gls.NewVBO(math32.ArrayF32(uvs)).AddAttrib(gls.VertexTexcoord))
+	geom.AddVBO(gls.NewVBO(math32.ArrayF32(colors)).AddAttrib(gls.VertexColor))
+	geom.AddVBO(gls.NewVBO(math32.ArrayF32(weights)).AddCustomAttrib("weight", 1))
+	geom.SetIndices(math32.ArrayU32(indices))
+
+	for _, format := range []Format{ASCII, BinaryLittleEndian, BinaryBigEndian} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, geom, format); err != nil {
+			t.Fatal(err)
+		}
+		dec, err := DecodeReader(&buf)
+		if err != nil {
+			t.Fatalf("format:%v %v", format, err)
+		}
+		if dec.Format != format {
+			t.Errorf("format:%v decoded as:%v", format, dec.Format)
+		}
+		decoded, err := dec.NewGeometry()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values := func(atype gls.AttribType) []float32 {
+			vbo := decoded.VBO(atype)
+			if vbo == nil {
+				return nil
+			}
+			return attribValues(vbo, *vbo.Attrib(atype))
+		}
+		checkValues(t, "positions", values(gls.VertexPosition), positions)
+		checkValues(t, "normals", values(gls.VertexNormal), normals)
+		checkValues(t, "uvs", values(gls.VertexTexcoord), uvs)
+		// The colors are written as unsigned chars
+		quantized := make([]float32, len(colors))
+		for i, c := range colors {
+			quantized[i] = float32(math.Round(float64(c)*255)) / 255
+		}
+		checkValues(t, "colors", values(gls.VertexColor), quantized)
+		if vbo := decoded.VBOName("weight"); vbo == nil {
+			t.Error("weight attribute not decoded")
+		} else {
+			checkValues(t, "weight", attribValues(vbo, *vbo.AttribName("weight")), weights)
+		}
+		if !reflect.DeepEqual(decoded.Indices().ToUint32(), indices) {
+			t.Errorf("indices:%v", decoded.Indices())
+		}
+	}
+}
+
+// checkValues checks that the specified values are the expected ones.
+func checkValues(t *testing.T, name string, values, expected []float32) {
+
+	t.Helper()
+	if len(values) != len(expected) {
+		t.Errorf("%s: got %d values, expected %d", name, len(values), len(expected))
+		return
+	}
+	for i := range values {
+		if math.Abs(float64(values[i]-expected[i])) > 1e-6 {
+			t.Errorf("%s: got %v, expected %v", name, values, expected)
+			return
+		}
+	}
+}
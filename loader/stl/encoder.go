@@ -0,0 +1,70 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// Encode writes the triangles of the specified geometry to the specified writer as a solid
+// with the specified name in the specified STL encoding. The normals of the facets are
+// calculated from their vertices, which are not transformed.
+func Encode(w io.Writer, igeom geometry.IGeometry, name string, format Format) error {
+
+	// Gets the triangles of the geometry
+	var triangles []math32.Vector3
+	igeom.GetGeometry().ReadFaces(func(a, b, c math32.Vector3) bool {
+		triangles = append(triangles, a, b, c)
+		return false
+	})
+	normal := func(i int) math32.Vector3 {
+		var ab, ac math32.Vector3
+		ab.SubVectors(&triangles[i+1], &triangles[i])
+		ac.SubVectors(&triangles[i+2], &triangles[i])
+		ab.Cross(&ac).Normalize()
+		return ab
+	}
+
+	bw := bufio.NewWriter(w)
+	if format == Binary {
+		// Header with the name followed by the number of facets
+		var header [headerSize]byte
+		copy(header[:], name)
+		bw.Write(header[:])
+		binary.Write(bw, binary.LittleEndian, uint32(len(triangles)/3))
+		var facet [facetSize]byte
+		for i := 0; i < len(triangles); i += 3 {
+			n := normal(i)
+			for j, v := range []*math32.Vector3{&n, &triangles[i], &triangles[i+1], &triangles[i+2]} {
+				binary.LittleEndian.PutUint32(facet[j*12:], math.Float32bits(v.X))
+				binary.LittleEndian.PutUint32(facet[j*12+4:], math.Float32bits(v.Y))
+				binary.LittleEndian.PutUint32(facet[j*12+8:], math.Float32bits(v.Z))
+			}
+			bw.Write(facet[:])
+		}
+		return bw.Flush()
+	}
+
+	fmt.Fprintf(bw, "solid %s\n", name)
+	for i := 0; i < len(triangles); i += 3 {
+		n := normal(i)
+		fmt.Fprintf(bw, "  facet normal %g %g %g\n", n.X, n.Y, n.Z)
+		fmt.Fprintf(bw, "    outer loop\n")
+		for _, v := range triangles[i : i+3] {
+			fmt.Fprintf(bw, "      vertex %g %g %g\n", v.X, v.Y, v.Z)
+		}
+		fmt.Fprintf(bw, "    endloop\n")
+		fmt.Fprintf(bw, "  endfacet\n")
+	}
+	fmt.Fprintf(bw, "endsolid %s\n", name)
+	return bw.Flush()
+}
@@ -0,0 +1,258 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package stl is used to parse and write the STL file format (*.stl) in its ASCII
+// and binary encodings. The facet colors of binary files in the VisCAM/SolidView
+// and Materialise Magics conventions are decoded as vertex colors.
+// Basic format info: https://en.wikipedia.org/wiki/STL_(file_format)
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/graphic"
+	"github.com/g3n/engine/material"
+	"github.com/g3n/engine/math32"
+)
+
+// Format is the encoding of a STL file
+type Format int
+
+// STL file encodings
+const (
+	ASCII  = Format(iota) // ASCII encoding
+	Binary                // Binary encoding
+)
+
+// Local constants
+const (
+	headerSize = 80 // size of the header of binary files
+	facetSize  = 50 // size of a facet of binary files
+)
+
+// Decoder contains all decoded data from a STL file
+type Decoder struct {
+	Name      string          // solid name of ASCII files or header of binary files
+	Format    Format          // file encoding
+	Positions math32.ArrayF32 // vertices positions array, three vertices per facet
+	Normals   math32.ArrayF32 // vertices normals, which are the normals of their facets
+	Colors    math32.ArrayF32 // vertices colors, which are the colors of their facets, if any
+}
+
+// Decode decodes the specified STL file returning a decoder object and an error.
+func Decode(path string) (*Decoder, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return DecodeReader(f)
+}
+
+// DecodeReader decodes the specified STL reader, in ASCII or binary encoding,
+// returning a decoder object and an error.
+func DecodeReader(r io.Reader) (*Decoder, error) {
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := new(Decoder)
+	dec.Positions = math32.NewArrayF32(0, 0)
+	dec.Normals = math32.NewArrayF32(0, 0)
+
+	// Binary files may also start with "solid", so they are
+	// detected by the size of the file given its number of facets
+	if len(data) >= headerSize+4 {
+		count := int(binary.LittleEndian.Uint32(data[headerSize:]))
+		if headerSize+4+count*facetSize == len(data) {
+			dec.Format = Binary
+			err = dec.decodeBinary(data, count)
+			return dec, err
+		}
+	}
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if !bytes.HasPrefix(trimmed, []byte("solid")) {
+		return nil, fmt.Errorf("invalid STL file")
+	}
+	dec.Format = ASCII
+	err = dec.decodeASCII(trimmed)
+	if err != nil {
+		return nil, err
+	}
+	return dec, nil
+}
+
+// decodeBinary decodes the specified number of facets of the specified binary file data.
+func (dec *Decoder) decodeBinary(data []byte, count int) error {
+
+	header := data[:headerSize]
+	dec.Name = strings.TrimRight(string(header), " \x00")
+
+	// Materialise Magics files have the default color in the header
+	magics := false
+	defColor := math32.Color{1, 1, 1}
+	if idx := bytes.Index(header, []byte("COLOR=")); idx >= 0 && idx+10 <= headerSize {
+		magics = true
+		c := header[idx+6:]
+		defColor = math32.Color{float32(c[0]) / 255, float32(c[1]) / 255, float32(c[2]) / 255}
+	}
+
+	colors := math32.NewArrayF32(0, 0)
+	hasColors := false
+	var normal, v math32.Vector3
+	var color math32.Color
+	for i := 0; i < count; i++ {
+		b := data[headerSize+4+i*facetSize:]
+		read := func(offset int, v *math32.Vector3) {
+			v.X = math.Float32frombits(binary.LittleEndian.Uint32(b[offset:]))
+			v.Y = math.Float32frombits(binary.LittleEndian.Uint32(b[offset+4:]))
+			v.Z = math.Float32frombits(binary.LittleEndian.Uint32(b[offset+8:]))
+		}
+		read(0, &normal)
+		for j := 1; j <= 3; j++ {
+			read(j*12, &v)
+			dec.Positions.AppendVector3(&v)
+		}
+		dec.appendNormal(&normal)
+
+		// Facet color in the attribute byte count
+		attr := binary.LittleEndian.Uint16(b[48:])
+		c5 := func(shift uint) float32 { return float32((attr>>shift)&0x1F) / 31 }
+		switch {
+		case magics && attr&0x8000 == 0:
+			color = math32.Color{c5(0), c5(5), c5(10)}
+			hasColors = true
+		case magics:
+			color = defColor
+		case attr&0x8000 != 0:
+			color = math32.Color{c5(10), c5(5), c5(0)}
+			hasColors = true
+		default:
+			color = defColor
+		}
+		colors.AppendColor(&color, &color, &color)
+	}
+	if hasColors {
+		dec.Colors = colors
+	}
+	return nil
+}
+
+// decodeASCII decodes the facets of the specified ASCII file data,
+// which may contain several solids.
+func (dec *Decoder) decodeASCII(data []byte) error {
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), len(data)+1)
+	line := 0
+	var normal, v math32.Vector3
+	vertices := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		readVec := func(fields []string, v *math32.Vector3) error {
+			if len(fields) < 3 {
+				return fmt.Errorf("expected 3 coordinates in line:%d", line)
+			}
+			var xyz [3]float32
+			for i := range xyz {
+				f, err := strconv.ParseFloat(fields[i], 32)
+				if err != nil {
+					return fmt.Errorf("invalid coordinate in line:%d", line)
+				}
+				xyz[i] = float32(f)
+			}
+			v.Set(xyz[0], xyz[1], xyz[2])
+			return nil
+		}
+		switch fields[0] {
+		case "solid":
+			if dec.Name == "" {
+				dec.Name = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(scanner.Text()), "solid"))
+			}
+		case "facet":
+			if len(fields) < 2 || fields[1] != "normal" {
+				return fmt.Errorf("expected facet normal in line:%d", line)
+			}
+			if err := readVec(fields[2:], &normal); err != nil {
+				return err
+			}
+			vertices = 0
+		case "vertex":
+			if err := readVec(fields[1:], &v); err != nil {
+				return err
+			}
+			dec.Positions.AppendVector3(&v)
+			vertices++
+		case "endfacet":
+			if vertices != 3 {
+				return fmt.Errorf("facet with %d vertices in line:%d", vertices, line)
+			}
+			dec.appendNormal(&normal)
+		case "outer", "endloop", "endsolid":
+		default:
+			return fmt.Errorf("unexpected %q in line:%d", fields[0], line)
+		}
+	}
+	return scanner.Err()
+}
+
+// appendNormal appends the specified normal of the last decoded facet to its three vertices.
+// The normal is calculated from the vertices of the facet if it is zero.
+func (dec *Decoder) appendNormal(normal *math32.Vector3) {
+
+	n := *normal
+	if n.LengthSq() == 0 {
+		var a, b, c math32.Vector3
+		size := dec.Positions.Size()
+		dec.Positions.GetVector3(size-9, &a)
+		dec.Positions.GetVector3(size-6, &b)
+		dec.Positions.GetVector3(size-3, &c)
+		b.Sub(&a)
+		c.Sub(&a)
+		n.CrossVectors(&b, &c).Normalize()
+	}
+	dec.Normals.AppendVector3(&n, &n, &n)
+}
+
+// NewGeometry creates and returns a non-indexed geometry with the decoded facets.
+func (dec *Decoder) NewGeometry() *geometry.Geometry {
+
+	geom := geometry.NewGeometry()
+	geom.AddVBO(gls.NewVBO(dec.Positions).AddAttrib(gls.VertexPosition))
+	geom.AddVBO(gls.NewVBO(dec.Normals).AddAttrib(gls.VertexNormal))
+	if dec.Colors != nil {
+		geom.AddVBO(gls.NewVBO(dec.Colors).AddAttrib(gls.VertexColor))
+	}
+	return geom
+}
+
+// NewMesh creates and returns a mesh with the decoded facets and the specified material.
+// If the material is nil, a light gray standard material is used.
+// The vertex colors are only used by materials which support them, such as the basic material.
+func (dec *Decoder) NewMesh(mat material.IMaterial) *graphic.Mesh {
+
+	if mat == nil {
+		mat = material.NewStandard(&math32.Color{0.7, 0.7, 0.7})
+	}
+	return graphic.NewMesh(dec.NewGeometry(), mat)
+}
@@ -0,0 +1,163 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package stl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/g3n/engine/geometry"
+	"github.com/g3n/engine/math32"
+)
+
+// asciiFacet is an ASCII file with a facet whose normal is not specified
+const asciiFacet = `solid triangle
+  facet normal 0 0 0
+    outer loop
+      vertex 0 0 0
+      vertex 1 0 0
+      vertex 0 2 0
+    endloop
+  endfacet
+endsolid triangle
+`
+
+// Tests the decoding of an ASCII file
+func TestDecodeASCII(t *testing.T) {
+
+	dec, err := DecodeReader(strings.NewReader(asciiFacet))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Format != ASCII || dec.Name != "triangle" {
+		t.Errorf("format:%v name:%q", dec.Format, dec.Name)
+	}
+	checkArray(t, "positions", dec.Positions, []float32{0, 0, 0, 1, 0, 0, 0, 2, 0})
+	// The normal is calculated from the vertices
+	checkArray(t, "normals", dec.Normals, []float32{0, 0, 1, 0, 0, 1, 0, 0, 1})
+	if dec.Colors != nil {
+		t.Errorf("unexpected colors:%v", dec.Colors)
+	}
+}
+
+// Tests the errors of invalid ASCII files
+func TestDecodeASCIIErrors(t *testing.T) {
+
+	for _, data := range []string{
+		"",
+		"not a solid",
+		strings.Replace(asciiFacet, "vertex 1 0 0", "vertex 1 0", 1),
+		strings.Replace(asciiFacet, "vertex 1 0 0\n", "", 1),
+		strings.Replace(asciiFacet, "outer loop", "outer loop\n bad", 1),
+	} {
+		if _, err := DecodeReader(strings.NewReader(data)); err == nil {
+			t.Errorf("expected error decoding %q", data)
+		}
+	}
+}
+
+// Tests the decoding of the facet colors of binary files
+func TestDecodeBinaryColors(t *testing.T) {
+
+	facets := func(header string, attrs ...uint16) []byte {
+		data := make([]byte, headerSize+4+len(attrs)*facetSize)
+		copy(data, header)
+		binary.LittleEndian.PutUint32(data[headerSize:], uint32(len(attrs)))
+		for i, attr := range attrs {
+			b := data[headerSize+4+i*facetSize:]
+			// Normal followed by the vertices (0,0,0), (1,0,0) and (0,1,0)
+			binary.LittleEndian.PutUint32(b[12*2:], math.Float32bits(1))
+			binary.LittleEndian.PutUint32(b[12*3+4:], math.Float32bits(1))
+			binary.LittleEndian.PutUint16(b[48:], attr)
+		}
+		return data
+	}
+
+	// VisCAM/SolidView: the valid bit is set and the colors are in BGR order
+	dec, err := DecodeReader(bytes.NewReader(facets("viscam", 0x8000|31<<10, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Format != Binary || dec.Name != "viscam" {
+		t.Errorf("format:%v name:%q", dec.Format, dec.Name)
+	}
+	checkArray(t, "positions", dec.Positions, []float32{0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 1, 0})
+	checkArray(t, "normals", dec.Normals, []float32{0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 1})
+	checkArray(t, "colors", dec.Colors, []float32{1, 0, 0, 1, 0, 0, 1, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 1})
+
+	// Materialise Magics: the default color is in the header, the valid bit is clear and the colors are in RGB order
+	header := "COLOR=\x00\xff\x00\xff"
+	dec, err = DecodeReader(bytes.NewReader(facets(header, 31<<10, 0x8000)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkArray(t, "colors", dec.Colors, []float32{0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 1, 0, 0, 1, 0, 0, 1, 0})
+
+	// Without colors
+	dec, err = DecodeReader(bytes.NewReader(facets("", 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Colors != nil {
+		t.Errorf("unexpected colors:%v", dec.Colors)
+	}
+}
+
+// Tests that the geometries written in both encodings are decoded with the same triangles
+func TestEncodeRoundTrip(t *testing.T) {
+
+	box := geometry.NewBox(1, 2, 3)
+	var expected []float32
+	box.ReadFaces(func(a, b, c math32.Vector3) bool {
+		expected = append(expected, a.X, a.Y, a.Z, b.X, b.Y, b.Z, c.X, c.Y, c.Z)
+		return false
+	})
+	if len(expected) != 12*9 {
+		t.Fatalf("box with %d values", len(expected))
+	}
+
+	for _, format := range []Format{ASCII, Binary} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, box, "box", format); err != nil {
+			t.Fatal(err)
+		}
+		dec, err := DecodeReader(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dec.Format != format || dec.Name != "box" {
+			t.Errorf("format:%v name:%q", dec.Format, dec.Name)
+		}
+		checkArray(t, "positions", dec.Positions, expected)
+		// The normals of the faces of a box are unit vectors along the axes
+		for i := 0; i < len(dec.Normals); i += 3 {
+			var n math32.Vector3
+			dec.Normals.GetVector3(i, &n)
+			if math32.Abs(n.Length()-1) > 1e-6 || math32.Abs(math32.Abs(n.X)+math32.Abs(n.Y)+math32.Abs(n.Z)-1) > 1e-6 {
+				t.Errorf("format:%v invalid normal:%v", format, n)
+				break
+			}
+		}
+	}
+}
+
+// checkArray checks that the specified array has the expected values.
+func checkArray(t *testing.T, name string, values math32.ArrayF32, expected []float32) {
+
+	t.Helper()
+	if len(values) != len(expected) {
+		t.Errorf("%s: got %d values, expected %d", name, len(values), len(expected))
+		return
+	}
+	for i := range values {
+		if math32.Abs(values[i]-expected[i]) > 1e-6 {
+			t.Errorf("%s: got %v, expected %v", name, values, expected)
+			return
+		}
+	}
+}
@@ -370,6 +370,12 @@ func (mat *Material) HasTexture(tex *texture.Texture2D) bool {
 	return false
 }
 
+// Textures returns the textures of the material.
+func (mat *Material) Textures() []*texture.Texture2D {
+
+	return mat.textures
+}
+
 // TextureCount returns the current number of textures
 func (mat *Material) TextureCount() int {
 
@@ -55,6 +55,12 @@ func (m *Physical) SetBaseColorFactor(c *math32.Color4) *Physical {
 	return m
 }
 
+// BaseColorFactor returns this material base color.
+func (m *Physical) BaseColorFactor() math32.Color4 {
+
+	return m.udata.baseColorFactor
+}
+
 // SetMetallicFactor sets this material metallic factor.
 // Its default value is 1.
 // Returns pointer to this updated material.
@@ -64,6 +70,12 @@ func (m *Physical) SetMetallicFactor(v float32) *Physical {
 	return m
 }
 
+// MetallicFactor returns this material metallic factor.
+func (m *Physical) MetallicFactor() float32 {
+
+	return m.udata.metallicFactor
+}
+
 // SetRoughnessFactor sets this material roughness factor.
 // Its default value is 1.
 // Returns pointer to this updated material.
@@ -73,6 +85,12 @@ func (m *Physical) SetRoughnessFactor(v float32) *Physical {
 	return m
 }
 
+// RoughnessFactor returns this material roughness factor.
+func (m *Physical) RoughnessFactor() float32 {
+
+	return m.udata.roughnessFactor
+}
+
 // SetEmissiveFactor sets the emissive color of the material.
 // Its default is {1, 1, 1}.
 // Returns pointer to this updated material.
@@ -84,6 +102,12 @@ func (m *Physical) SetEmissiveFactor(c *math32.Color) *Physical {
 	return m
 }
 
+// EmissiveFactor returns the emissive color of the material.
+func (m *Physical) EmissiveFactor() math32.Color {
+
+	return math32.Color{m.udata.emissiveFactor.R, m.udata.emissiveFactor.G, m.udata.emissiveFactor.B}
+}
+
 // SetBaseColorMap sets this material optional texture base color.
 // Returns pointer to this updated material.
 func (m *Physical) SetBaseColorMap(tex *texture.Texture2D) *Physical {
@@ -165,6 +189,36 @@ func (m *Physical) SetEmissiveMap(tex *texture.Texture2D) *Physical {
 	return m
 }
 
+// BaseColorMap returns this material base color texture or nil if it has none.
+func (m *Physical) BaseColorMap() *texture.Texture2D {
+
+	return m.baseColorTex
+}
+
+// MetallicRoughnessMap returns this material metallic-roughness texture or nil if it has none.
+func (m *Physical) MetallicRoughnessMap() *texture.Texture2D {
+
+	return m.metallicRoughnessTex
+}
+
+// NormalMap returns this material normal texture or nil if it has none.
+func (m *Physical) NormalMap() *texture.Texture2D {
+
+	return m.normalTex
+}
+
+// OcclusionMap returns this material occlusion texture or nil if it has none.
+func (m *Physical) OcclusionMap() *texture.Texture2D {
+
+	return m.occlusionTex
+}
+
+// EmissiveMap returns this material emissive texture or nil if it has none.
+func (m *Physical) EmissiveMap() *texture.Texture2D {
+
+	return m.emissiveTex
+}
+
 // RenderSetup transfer this material uniforms and textures to the shader
 func (m *Physical) RenderSetup(gl *gls.GLS) {
 
@@ -0,0 +1,102 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package material
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/texture"
+)
+
+// TerrainMaxLayers is the maximum number of layer textures of a terrain material.
+const TerrainMaxLayers = 4
+
+// Terrain is a standard material which blends up to four layer textures tiled over
+// a terrain using the weights of a splat map texture stretched over the whole terrain.
+// The red, green, blue and alpha channels of the splat map are the weights of the
+// first, second, third and fourth layers. It is used with terrain geometries,
+// whose texture coordinates are the X and Z coordinates of their vertices.
+type Terrain struct {
+	Standard                      // Embedded standard material
+	splat    *texture.Texture2D   // Splat map texture
+	layers   []*texture.Texture2D // Layer textures
+}
+
+// NewTerrain creates and returns a pointer to a new terrain material with the specified splat map
+// and layer textures. The splat map covers a terrain of size 1x1 and each layer texture a tile
+// of size 1x1 until changed by SetSize and SetTileSize. If the splat map is nil only
+// the first layer is used.
+func NewTerrain(splat *texture.Texture2D, layers ...*texture.Texture2D) *Terrain {
+
+	mt := new(Terrain)
+	mt.Init(splat, layers...)
+	return mt
+}
+
+// Init initializes the material with the specified splat map and layer textures.
+// It is used mainly when the material is embedded in another type
+func (mt *Terrain) Init(splat *texture.Texture2D, layers ...*texture.Texture2D) {
+
+	mt.Standard.Init("terrain", &math32.Color{1, 1, 1})
+	if len(layers) > TerrainMaxLayers {
+		layers = layers[:TerrainMaxLayers]
+	}
+	mt.layers = layers
+	if len(layers) == 0 {
+		return
+	}
+	if splat == nil {
+		// Without a splat map only the first layer is used
+		rgba := image.NewRGBA(image.Rect(0, 0, 1, 1))
+		rgba.Set(0, 0, color.RGBA{255, 0, 0, 255})
+		splat = texture.NewTexture2DFromRGBA(rgba)
+	}
+	// The rows of the splat map are along the Z axis from the origin
+	splat.SetFlipY(false)
+	mt.splat = splat
+	mt.AddTexture(splat)
+	for _, tex := range layers {
+		mt.AddTexture(tex)
+	}
+}
+
+// Splat returns the splat map texture.
+func (mt *Terrain) Splat() *texture.Texture2D {
+
+	return mt.splat
+}
+
+// Layer returns the layer texture with the specified index or nil if there is none.
+func (mt *Terrain) Layer(i int) *texture.Texture2D {
+
+	if i < 0 || i >= len(mt.layers) {
+		return nil
+	}
+	return mt.layers[i]
+}
+
+// LayerCount returns the number of layer textures.
+func (mt *Terrain) LayerCount() int {
+
+	return len(mt.layers)
+}
+
+// SetSize sets the size of the terrain along the X and Z axes covered by the splat map.
+func (mt *Terrain) SetSize(width, depth float32) {
+
+	if mt.splat != nil && width > 0 && depth > 0 {
+		mt.splat.SetRepeat(1/width, 1/depth)
+	}
+}
+
+// SetTileSize sets the size of the tiles of the layer texture with the specified index.
+func (mt *Terrain) SetTileSize(i int, size float32) {
+
+	if tex := mt.Layer(i); tex != nil && size > 0 {
+		tex.SetRepeat(1/size, 1/size)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package material
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+	"github.com/g3n/engine/text"
+	"github.com/g3n/engine/texture"
+)
+
+// Text is the material used to draw text meshes built with the chars of a text.Atlas.
+// Text drawn with an atlas of signed distance fields stays sharp at any scale
+// and can have an outline and a drop shadow.
+type Text struct {
+	Material             // Embedded material
+	atlas    *text.Atlas // Atlas with the chars
+	uni      gls.Uniform // Uniform location cache
+	udata    struct {    // Combined uniform data in 6 vec4:
+		color          math32.Color4  // Text color
+		outlineColor   math32.Color4  // Outline color
+		shadowColor    math32.Color4  // Shadow color
+		outlineWidth   float32        // Outline width in distance field units
+		shadowSoftness float32        // Shadow softness in distance field units
+		shadowX        float32        // Shadow horizontal offset in atlas pixels
+		shadowY        float32        // Shadow vertical offset in atlas pixels
+		sdf            float32        // Atlas contains signed distance fields flag
+		pad            [3]float32     // Unused
+		bounds         math32.Vector4 // Clipping rectangle in window coordinates
+	}
+}
+
+// Number of glsl shader vec4 elements used by uniform data
+const textVec4Count = 6
+
+// atlasTextureRef is the texture of an atlas and the number of text materials using it
+type atlasTextureRef struct {
+	tex   *texture.Texture2D
+	count int
+}
+
+// atlasTextures keeps the texture of each atlas, which is shared by all text materials using the atlas,
+// while there are text materials using it
+var atlasTextures = make(map[*text.Atlas]*atlasTextureRef)
+
+// NewText creates and returns a pointer to a new text material for the specified atlas
+func NewText(atlas *text.Atlas) *Text {
+
+	mt := new(Text)
+	mt.Init(atlas)
+	return mt
+}
+
+// Init initializes the material with the specified atlas.
+// It is used mainly when the material is embedded in another type
+func (mt *Text) Init(atlas *text.Atlas) {
+
+	mt.Material.Init()
+	mt.SetShader("text")
+	mt.SetShaderUnique(true)
+	mt.SetUseLights(UseLightNone)
+	mt.SetTransparent(true)
+	mt.SetSide(SideDouble)
+	mt.atlas = atlas
+	mt.AddTexture(atlasTexture(atlas))
+
+	// Creates uniforms and set initial values
+	mt.uni.Init("Text")
+	mt.SetColor(&math32.Color4{1, 1, 1, 1})
+	if atlas.SDF {
+		mt.udata.sdf = 1
+	}
+}
+
+// Atlas returns the atlas used by this material
+func (mt *Text) Atlas() *text.Atlas {
+
+	return mt.atlas
+}
+
+// SetColor sets the text color
+func (mt *Text) SetColor(color *math32.Color4) {
+
+	mt.udata.color = *color
+}
+
+// Color returns the text color
+func (mt *Text) Color() math32.Color4 {
+
+	return mt.udata.color
+}
+
+// SetOutline sets the width in atlas pixels and the color of the text outline.
+// The width is limited to the atlas spread and outlines are only drawn
+// with atlases of signed distance fields.
+// A width of 0 removes the outline.
+func (mt *Text) SetOutline(width float32, color *math32.Color4) {
+
+	mt.udata.outlineWidth = mt.distance(width)
+	mt.udata.outlineColor = *color
+}
+
+// SetShadow sets the offset and softness in atlas pixels and the color of the text drop shadow.
+// The offset and softness are limited to the atlas spread and shadows are only drawn
+// with atlases of signed distance fields.
+// A transparent color removes the shadow.
+func (mt *Text) SetShadow(dx, dy, softness float32, color *math32.Color4) {
+
+	if mt.atlas.Spread > 0 {
+		spread := float32(mt.atlas.Spread)
+		dx = math32.Clamp(dx, -spread, spread)
+		dy = math32.Clamp(dy, -spread, spread)
+	}
+	mt.udata.shadowX = dx
+	mt.udata.shadowY = dy
+	mt.udata.shadowSoftness = mt.distance(softness)
+	mt.udata.shadowColor = *color
+}
+
+// SetBounds sets the rectangle in OpenGL window coordinates outside of which
+// the text is not drawn. An empty rectangle disables clipping.
+// It is used to clip GUI text to the bounds of its panel.
+func (mt *Text) SetBounds(xmin, ymin, xmax, ymax float32) {
+
+	mt.udata.bounds = math32.Vector4{xmin, ymin, xmax, ymax}
+}
+
+// Dispose decrements this material reference count and if necessary releases
+// its textures. The texture of the atlas is released by the last text material using it.
+func (mt *Text) Dispose() {
+
+	if mt.refcount == 1 && len(mt.textures) > 0 {
+		releaseAtlasTexture(mt.atlas)
+	}
+	mt.Material.Dispose()
+}
+
+// RenderSetup is called by the renderer before drawing objects with this material.
+func (mt *Text) RenderSetup(gs *gls.GLS) {
+
+	mt.Material.RenderSetup(gs)
+	location := mt.uni.Location(gs)
+	gs.Uniform4fv(location, textVec4Count, &mt.udata.color.R)
+}
+
+// distance converts the specified distance in atlas pixels to distance field units
+func (mt *Text) distance(pixels float32) float32 {
+
+	if mt.atlas.Spread == 0 {
+		return 0
+	}
+	spread := float32(mt.atlas.Spread)
+	return math32.Clamp(pixels, 0, spread) / (2 * spread)
+}
+
+// atlasTexture returns a new reference to the texture of the specified atlas,
+// creating it if no text material is using the atlas.
+func atlasTexture(atlas *text.Atlas) *texture.Texture2D {
+
+	ref, ok := atlasTextures[atlas]
+	if !ok {
+		tex := texture.NewTexture2DFromRGBA(atlas.Image)
+		tex.SetMagFilter(gls.LINEAR)
+		tex.SetMinFilter(gls.LINEAR)
+		atlasTextures[atlas] = &atlasTextureRef{tex: tex, count: 1}
+		return tex
+	}
+	ref.count++
+	return ref.tex.Incref()
+}
+
+// releaseAtlasTexture is called when a text material using the specified atlas is disposed.
+// The texture is removed from the textures map when no text material uses it, and it is
+// deleted when the material releases its last reference.
+func releaseAtlasTexture(atlas *text.Atlas) {
+
+	ref, ok := atlasTextures[atlas]
+	if !ok {
+		return
+	}
+	ref.count--
+	if ref.count == 0 {
+		delete(atlasTextures, atlas)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package material
+
+import (
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// LineCap specifies how the ends of thick lines are drawn.
+type LineCap int
+
+// The line caps.
+const (
+	LineCapButt   = LineCap(iota) // The line ends at its end points
+	LineCapSquare                 // The line extends beyond its end points by half its width
+	LineCapRound                  // The line ends with half circles centered at its end points
+)
+
+// LineJoin specifies how the corners between connected segments of thick lines are drawn.
+type LineJoin int
+
+// The line joins.
+const (
+	LineJoinRound = LineJoin(iota) // The corners are rounded
+	LineJoinBevel                  // The corners are cut
+	LineJoinMiter                  // The outer edges are extended until they meet, up to the miter limit
+	LineJoinNone                   // The segments overlap without filling the corners
+)
+
+// ThickLine is the material used to draw thick lines, which are built from quads
+// expanded in the vertex shader so their width is not limited by the OpenGL line width.
+// The width is in pixels or in world units and the lines can be dashed.
+// The material color is multiplied by the vertex colors of the lines.
+type ThickLine struct {
+	Material             // Embedded material
+	uni      gls.Uniform // Uniform location cache
+	udata    struct {    // Combined uniform data in 3 vec4:
+		color      math32.Color // Line color
+		opacity    float32      // Line opacity
+		width      float32      // Line width in pixels or world units
+		worldUnits float32      // Width in world units flag
+		cap        float32      // Line cap
+		join       float32      // Line join
+		dashSize   float32      // Length of the dashes in world units (0 for solid lines)
+		gapSize    float32      // Length of the gaps between dashes in world units
+		dashOffset float32      // Offset of the dash pattern along the lines
+		miterLimit float32      // Maximum ratio between the miter length and half the width
+	}
+}
+
+// Number of glsl shader vec4 elements used by uniform data
+const thickLineVec4Count = 3
+
+// NewThickLine creates and returns a pointer to a new thick line material
+// with the specified color and width in pixels.
+func NewThickLine(color *math32.Color, width float32) *ThickLine {
+
+	mt := new(ThickLine)
+	mt.Init(color, width)
+	return mt
+}
+
+// Init initializes the material with the specified color and width in pixels.
+// It is used mainly when the material is embedded in another type
+func (mt *ThickLine) Init(color *math32.Color, width float32) {
+
+	mt.Material.Init()
+	mt.SetShader("thickline")
+	mt.SetUseLights(UseLightNone)
+	mt.SetSide(SideDouble)
+
+	// Creates uniforms and set initial values
+	mt.uni.Init("ThickLine")
+	mt.udata.color = *color
+	mt.udata.opacity = 1
+	mt.udata.width = width
+	mt.udata.cap = float32(LineCapButt)
+	mt.udata.join = float32(LineJoinRound)
+	mt.udata.miterLimit = 4
+}
+
+// SetColor sets the line color, which is multiplied by the vertex colors.
+func (mt *ThickLine) SetColor(color *math32.Color) {
+
+	mt.udata.color = *color
+}
+
+// Color returns the line color.
+func (mt *ThickLine) Color() math32.Color {
+
+	return mt.udata.color
+}
+
+// SetOpacity sets the line opacity. Default is 1.0.
+func (mt *ThickLine) SetOpacity(opacity float32) {
+
+	mt.udata.opacity = opacity
+}
+
+// SetWidth sets the line width, in pixels or in world units as set by SetWorldUnits.
+func (mt *ThickLine) SetWidth(width float32) {
+
+	mt.udata.width = width
+}
+
+// Width returns the line width.
+func (mt *ThickLine) Width() float32 {
+
+	return mt.udata.width
+}
+
+// SetWorldUnits sets whether the line width is in world units, so that the lines get
+// thinner with the distance, instead of pixels. Default is false.
+func (mt *ThickLine) SetWorldUnits(state bool) {
+
+	if state {
+		mt.udata.worldUnits = 1
+	} else {
+		mt.udata.worldUnits = 0
+	}
+}
+
+// WorldUnits returns whether the line width is in world units.
+func (mt *ThickLine) WorldUnits() bool {
+
+	return mt.udata.worldUnits != 0
+}
+
+// SetCap sets how the ends of the lines are drawn. Default is LineCapButt.
+func (mt *ThickLine) SetCap(cap LineCap) {
+
+	mt.udata.cap = float32(cap)
+}
+
+// Cap returns how the ends of the lines are drawn.
+func (mt *ThickLine) Cap() LineCap {
+
+	return LineCap(mt.udata.cap)
+}
+
+// SetJoin sets how the corners between connected segments are drawn. Default is LineJoinRound.
+func (mt *ThickLine) SetJoin(join LineJoin) {
+
+	mt.udata.join = float32(join)
+}
+
+// Join returns how the corners between connected segments are drawn.
+func (mt *ThickLine) Join() LineJoin {
+
+	return LineJoin(mt.udata.join)
+}
+
+// SetMiterLimit sets the maximum ratio between the length of a miter and half the line width,
+// beyond which the corner is beveled. Default is 4.
+func (mt *ThickLine) SetMiterLimit(limit float32) {
+
+	mt.udata.miterLimit = limit
+}
+
+// SetDashes sets the lengths in world units of the dashes and of the gaps between them along the
+// lines and the offset of the pattern from the start of the lines. A dash size of 0 draws solid lines.
+func (mt *ThickLine) SetDashes(dashSize, gapSize, offset float32) {
+
+	mt.udata.dashSize = dashSize
+	mt.udata.gapSize = gapSize
+	mt.udata.dashOffset = offset
+}
+
+// Dashes returns the lengths of the dashes and of the gaps between them and the offset of the pattern.
+func (mt *ThickLine) Dashes() (float32, float32, float32) {
+
+	return mt.udata.dashSize, mt.udata.gapSize, mt.udata.dashOffset
+}
+
+// RenderSetup is called by the renderer before drawing objects with this material.
+func (mt *ThickLine) RenderSetup(gs *gls.GLS) {
+
+	mt.Material.RenderSetup(gs)
+	location := mt.uni.Location(gs)
+	gs.Uniform4fv(location, thickLineVec4Count, &mt.udata.color.R)
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package math32
+
+import (
+	"math/rand"
+)
+
+// Noise is a generator of two dimensional gradient (Perlin) noise,
+// which is smooth and repeatable for the same seed.
+type Noise struct {
+	perm [512]uint8 // Permutation table repeated twice
+}
+
+// Gradient directions of the noise
+var noiseGradients = [8][2]float32{
+	{1, 0}, {-1, 0}, {0, 1}, {0, -1},
+	{0.70710678, 0.70710678}, {-0.70710678, 0.70710678}, {0.70710678, -0.70710678}, {-0.70710678, -0.70710678},
+}
+
+// NewNoise creates and returns a pointer to a new noise generator with the specified seed.
+func NewNoise(seed int64) *Noise {
+
+	n := new(Noise)
+	r := rand.New(rand.NewSource(seed))
+	for i, p := range r.Perm(256) {
+		n.perm[i] = uint8(p)
+		n.perm[i+256] = uint8(p)
+	}
+	return n
+}
+
+// Noise2 returns the noise at the specified point, which is between -1 and 1
+// and is 0 at the points with integer coordinates.
+func (n *Noise) Noise2(x, y float32) float32 {
+
+	fx, fy := Floor(x), Floor(y)
+	ix, iy := int(fx)&255, int(fy)&255
+	x -= fx
+	y -= fy
+	grad := func(i, j int, dx, dy float32) float32 {
+		g := &noiseGradients[n.perm[int(n.perm[i])+j]&7]
+		return g[0]*dx + g[1]*dy
+	}
+	n00 := grad(ix, iy, x, y)
+	n10 := grad(ix+1, iy, x-1, y)
+	n01 := grad(ix, iy+1, x, y-1)
+	n11 := grad(ix+1, iy+1, x-1, y-1)
+	u, v := noiseFade(x), noiseFade(y)
+	nx0 := n00 + u*(n10-n00)
+	nx1 := n01 + u*(n11-n01)
+	return Clamp((nx0+v*(nx1-nx0))*1.41421356, -1, 1)
+}
+
+// Fractal2 returns the sum of the specified number of octaves of noise at the specified point.
+// The frequency of each octave is multiplied by the lacunarity (usually 2) and its amplitude
+// by the gain (usually 0.5). The result is normalized to be between -1 and 1.
+func (n *Noise) Fractal2(x, y float32, octaves int, lacunarity, gain float32) float32 {
+
+	var sum, total float32
+	amplitude := float32(1)
+	for i := 0; i < octaves; i++ {
+		sum += amplitude * n.Noise2(x, y)
+		total += amplitude
+		x *= lacunarity
+		y *= lacunarity
+		amplitude *= gain
+	}
+	if total == 0 {
+		return 0
+	}
+	return sum / total
+}
+
+// noiseFade returns the smootherstep of the specified value between 0 and 1.
+func noiseFade(t float32) float32 {
+
+	return t * t * t * (t*(t*6-15) + 10)
+}
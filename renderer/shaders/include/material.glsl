@@ -17,13 +17,16 @@ uniform vec3 Material[6];
 #if MAT_TEXTURES > 0
     // Texture unit sampler array
     uniform sampler2D MatTexture[MAT_TEXTURES];
-    // Texture parameters (3*vec2 per texture)
-    uniform vec2 MatTexinfo[3*MAT_TEXTURES];
+    // Texture parameters (4*vec2 per texture)
+    uniform vec2 MatTexinfo[4*MAT_TEXTURES];
     // Macros to access elements inside the MatTexinfo array
-    #define MatTexOffset(a)		MatTexinfo[(3*a)]
-    #define MatTexRepeat(a)		MatTexinfo[(3*a)+1]
-    #define MatTexFlipY(a)		bool(MatTexinfo[(3*a)+2].x)
-    #define MatTexVisible(a)	bool(MatTexinfo[(3*a)+2].y)
+    #define MatTexOffset(a)		MatTexinfo[(4*a)]
+    #define MatTexRepeat(a)		MatTexinfo[(4*a)+1]
+    #define MatTexFlipY(a)		bool(MatTexinfo[(4*a)+2].x)
+    #define MatTexVisible(a)	bool(MatTexinfo[(4*a)+2].y)
+    #define MatTexRotation(a)	MatTexinfo[(4*a)+3] // Cosine and sine of the rotation angle
+    // Texture coordinates uv repeated, rotated and offset by the parameters of the texture a
+    #define MatTexcoord(a, uv)	(mat2(MatTexRotation(a).x, -MatTexRotation(a).y, MatTexRotation(a).y, MatTexRotation(a).x) * ((uv) * MatTexRepeat(a)) + MatTexOffset(a))
     // Alpha compositing (see here: https://ciechanow.ski/alpha-compositing/)
     vec4 Blend(vec4 texMixed, vec4 texColor) {
         texMixed.rgb *= texMixed.a;
@@ -2,13 +2,14 @@ precision highp float;
 
 // Texture uniforms
 uniform sampler2D	MatTexture;
-uniform vec2		MatTexinfo[3];
+uniform vec2		MatTexinfo[4];
 
 // Macros to access elements inside the MatTexinfo array
 #define MatTexOffset		MatTexinfo[0]
 #define MatTexRepeat		MatTexinfo[1]
 #define MatTexFlipY	    	bool(MatTexinfo[2].x) // not used
 #define MatTexVisible	    bool(MatTexinfo[2].y) // not used
+#define MatTexRotation		MatTexinfo[3] // Cosine and sine of the rotation angle
 
 // Inputs from vertex shader
 in vec2 FragTexcoord;
@@ -79,7 +80,8 @@ void main() {
             vec2 offset = vec2(-Content[0], -Content[1]);
             vec2 factor = vec2(1.0/Content[2], 1.0/Content[3]);
             vec2 texcoord = (FragTexcoord + offset) * factor;
-            vec4 texColor = texture(MatTexture, texcoord * MatTexRepeat + MatTexOffset);
+            mat2 rotation = mat2(MatTexRotation.x, -MatTexRotation.y, MatTexRotation.y, MatTexRotation.x);
+            vec4 texColor = texture(MatTexture, rotation * (texcoord * MatTexRepeat) + MatTexOffset);
 
             // Mix content color with texture color.
             // Note that doing a simple linear interpolation (e.g. using mix()) is not correct!
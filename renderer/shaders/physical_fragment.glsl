@@ -28,19 +28,24 @@ precision highp float;
 
 #ifdef HAS_BASECOLORMAP
 uniform sampler2D uBaseColorSampler;
+uniform vec2 uBaseColorTexParams[4]; // Texture offset, repeat, flip and rotation
 #endif
 #ifdef HAS_METALROUGHNESSMAP
 uniform sampler2D uMetallicRoughnessSampler;
+uniform vec2 uMetallicRoughnessTexParams[4]; // Texture offset, repeat, flip and rotation
 #endif
 #ifdef HAS_NORMALMAP
 uniform sampler2D uNormalSampler;
+uniform vec2 uNormalTexParams[4]; // Texture offset, repeat, flip and rotation
 //uniform float uNormalScale;
 #endif
 #ifdef HAS_EMISSIVEMAP
 uniform sampler2D uEmissiveSampler;
+uniform vec2 uEmissiveTexParams[4]; // Texture offset, repeat, flip and rotation
 #endif
 #ifdef HAS_OCCLUSIONMAP
 uniform sampler2D uOcclusionSampler;
+uniform vec2 uOcclusionTexParams[4]; // Texture offset, repeat, flip and rotation
 uniform float uOcclusionStrength;
 #endif
 
@@ -60,6 +65,9 @@ in vec3 Normal;         // Vertex normal in camera coordinates.
 in vec3 CamDir;         // Direction from vertex to camera
 in vec2 FragTexcoord;
 
+// Texture coordinates of this fragment repeated, rotated and offset by the specified texture parameters
+#define TexCoord(params) (mat2(params[3].x, -params[3].y, params[3].y, params[3].x) * (FragTexcoord * params[1]) + params[0])
+
 // Final fragment color
 out vec4 FragColor;
 
@@ -111,8 +119,14 @@ vec3 getNormal()
 //#ifndef HAS_TANGENTS
     vec3 pos_dx = dFdx(Position);
     vec3 pos_dy = dFdy(Position);
-    vec3 tex_dx = dFdx(vec3(FragTexcoord, 0.0));
-    vec3 tex_dy = dFdy(vec3(FragTexcoord, 0.0));
+#ifdef HAS_NORMALMAP
+    // The tangent space follows the transformed normal map coordinates
+    vec2 texcoord = TexCoord(uNormalTexParams);
+#else
+    vec2 texcoord = FragTexcoord;
+#endif
+    vec3 tex_dx = dFdx(vec3(texcoord, 0.0));
+    vec3 tex_dy = dFdy(vec3(texcoord, 0.0));
     vec3 t = (tex_dy.t * pos_dx - tex_dx.t * pos_dy) / (tex_dx.s * tex_dy.t - tex_dy.s * tex_dx.t);
 
 //#ifdef HAS_NORMALS
@@ -130,7 +144,7 @@ vec3 getNormal()
 
 #ifdef HAS_NORMALMAP
     float uNormalScale = 1.0;
-    vec3 n = texture(uNormalSampler, FragTexcoord).rgb;
+    vec3 n = texture(uNormalSampler, texcoord).rgb;
     n = normalize(tbn * ((2.0 * n - 1.0) * vec3(uNormalScale, uNormalScale, 1.0)));
 #else
     // The tbn matrix is linearly interpolated, so we need to re-normalize
@@ -251,7 +265,7 @@ void main() {
 #ifdef HAS_METALROUGHNESSMAP
     // Roughness is stored in the 'g' channel, metallic is stored in the 'b' channel.
     // This layout intentionally reserves the 'r' channel for (optional) occlusion map data
-    vec4 mrSample = texture(uMetallicRoughnessSampler, FragTexcoord);
+    vec4 mrSample = texture(uMetallicRoughnessSampler, TexCoord(uMetallicRoughnessTexParams));
     perceptualRoughness = mrSample.g * perceptualRoughness;
     metallic = mrSample.b * metallic;
 #endif
@@ -264,7 +278,7 @@ void main() {
 
     // The albedo may be defined from a base texture or a flat color
 #ifdef HAS_BASECOLORMAP
-    vec4 baseColor = SRGBtoLINEAR(texture(uBaseColorSampler, FragTexcoord)) * uBaseColor;
+    vec4 baseColor = SRGBtoLINEAR(texture(uBaseColorSampler, TexCoord(uBaseColorTexParams))) * uBaseColor;
 #else
     vec4 baseColor = uBaseColor;
 #endif
@@ -367,12 +381,12 @@ void main() {
 
     // Apply optional PBR terms for additional (optional) shading
 #ifdef HAS_OCCLUSIONMAP
-    float ao = texture(uOcclusionSampler, FragTexcoord).r;
+    float ao = texture(uOcclusionSampler, TexCoord(uOcclusionTexParams)).r;
     color = mix(color, color * ao, 1.0);//, uOcclusionStrength);
 #endif
 
 #ifdef HAS_EMISSIVEMAP
-    vec3 emissive = SRGBtoLINEAR(texture(uEmissiveSampler, FragTexcoord)).rgb * vec3(uEmissiveColor);
+    vec3 emissive = SRGBtoLINEAR(texture(uEmissiveSampler, TexCoord(uEmissiveTexParams))).rgb * vec3(uEmissiveColor);
 #else
     vec3 emissive = vec3(uEmissiveColor);
 #endif
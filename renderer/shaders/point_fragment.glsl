@@ -17,7 +17,7 @@ void main() {
         vec2 pointCoord = Rotation * gl_PointCoord - vec2(0.5) + vec2(0.5);
         bool firstTex = true;
         if (MatTexVisible(0)) {
-            vec4 texColor = texture(MatTexture[0], pointCoord * MatTexRepeat(0) + MatTexOffset(0));
+            vec4 texColor = texture(MatTexture[0], MatTexcoord(0, pointCoord));
             if (firstTex) {
                 texMixed = texColor;
                 firstTex = false;
@@ -27,7 +27,7 @@ void main() {
         }
         #if MAT_TEXTURES > 1
             if (MatTexVisible(1)) {
-                vec4 texColor = texture(MatTexture[1], pointCoord * MatTexRepeat(1) + MatTexOffset(1));
+                vec4 texColor = texture(MatTexture[1], MatTexcoord(1, pointCoord));
                 if (firstTex) {
                     texMixed = texColor;
                     firstTex = false;
@@ -37,7 +37,7 @@ void main() {
             }
             #if MAT_TEXTURES > 2
                 if (MatTexVisible(2)) {
-                    vec4 texColor = texture(MatTexture[2], pointCoord * MatTexRepeat(2) + MatTexOffset(2));
+                    vec4 texColor = texture(MatTexture[2], MatTexcoord(2, pointCoord));
                     if (firstTex) {
                         texMixed = texColor;
                         firstTex = false;
@@ -118,13 +118,16 @@ uniform vec3 Material[6];
 #if MAT_TEXTURES > 0
     // Texture unit sampler array
     uniform sampler2D MatTexture[MAT_TEXTURES];
-    // Texture parameters (3*vec2 per texture)
-    uniform vec2 MatTexinfo[3*MAT_TEXTURES];
+    // Texture parameters (4*vec2 per texture)
+    uniform vec2 MatTexinfo[4*MAT_TEXTURES];
     // Macros to access elements inside the MatTexinfo array
-    #define MatTexOffset(a)		MatTexinfo[(3*a)]
-    #define MatTexRepeat(a)		MatTexinfo[(3*a)+1]
-    #define MatTexFlipY(a)		bool(MatTexinfo[(3*a)+2].x)
-    #define MatTexVisible(a)	bool(MatTexinfo[(3*a)+2].y)
+    #define MatTexOffset(a)		MatTexinfo[(4*a)]
+    #define MatTexRepeat(a)		MatTexinfo[(4*a)+1]
+    #define MatTexFlipY(a)		bool(MatTexinfo[(4*a)+2].x)
+    #define MatTexVisible(a)	bool(MatTexinfo[(4*a)+2].y)
+    #define MatTexRotation(a)	MatTexinfo[(4*a)+3] // Cosine and sine of the rotation angle
+    // Texture coordinates uv repeated, rotated and offset by the parameters of the texture a
+    #define MatTexcoord(a, uv)	(mat2(MatTexRotation(a).x, -MatTexRotation(a).y, MatTexRotation(a).y, MatTexRotation(a).x) * ((uv) * MatTexRepeat(a)) + MatTexOffset(a))
     // Alpha compositing (see here: https://ciechanow.ski/alpha-compositing/)
     vec4 Blend(vec4 texMixed, vec4 texColor) {
         texMixed.rgb *= texMixed.a;
@@ -291,13 +294,14 @@ const panel_fragment_source = `precision highp float;
 
 // Texture uniforms
 uniform sampler2D	MatTexture;
-uniform vec2		MatTexinfo[3];
+uniform vec2		MatTexinfo[4];
 
 // Macros to access elements inside the MatTexinfo array
 #define MatTexOffset		MatTexinfo[0]
 #define MatTexRepeat		MatTexinfo[1]
 #define MatTexFlipY	    	bool(MatTexinfo[2].x) // not used
 #define MatTexVisible	    bool(MatTexinfo[2].y) // not used
+#define MatTexRotation		MatTexinfo[3] // Cosine and sine of the rotation angle
 
 // Inputs from vertex shader
 in vec2 FragTexcoord;
@@ -368,7 +372,8 @@ void main() {
             vec2 offset = vec2(-Content[0], -Content[1]);
             vec2 factor = vec2(1.0/Content[2], 1.0/Content[3]);
             vec2 texcoord = (FragTexcoord + offset) * factor;
-            vec4 texColor = texture(MatTexture, texcoord * MatTexRepeat + MatTexOffset);
+            mat2 rotation = mat2(MatTexRotation.x, -MatTexRotation.y, MatTexRotation.y, MatTexRotation.x);
+            vec4 texColor = texture(MatTexture, rotation * (texcoord * MatTexRepeat) + MatTexOffset);
 
             // Mix content color with texture color.
             // Note that doing a simple linear interpolation (e.g. using mix()) is not correct!
@@ -464,19 +469,24 @@ precision highp float;
 
 #ifdef HAS_BASECOLORMAP
 uniform sampler2D uBaseColorSampler;
+uniform vec2 uBaseColorTexParams[4]; // Texture offset, repeat, flip and rotation
 #endif
 #ifdef HAS_METALROUGHNESSMAP
 uniform sampler2D uMetallicRoughnessSampler;
+uniform vec2 uMetallicRoughnessTexParams[4]; // Texture offset, repeat, flip and rotation
 #endif
 #ifdef HAS_NORMALMAP
 uniform sampler2D uNormalSampler;
+uniform vec2 uNormalTexParams[4]; // Texture offset, repeat, flip and rotation
 //uniform float uNormalScale;
 #endif
 #ifdef HAS_EMISSIVEMAP
 uniform sampler2D uEmissiveSampler;
+uniform vec2 uEmissiveTexParams[4]; // Texture offset, repeat, flip and rotation
 #endif
 #ifdef HAS_OCCLUSIONMAP
 uniform sampler2D uOcclusionSampler;
+uniform vec2 uOcclusionTexParams[4]; // Texture offset, repeat, flip and rotation
 uniform float uOcclusionStrength;
 #endif
 
@@ -496,6 +506,9 @@ in vec3 Normal;         // Vertex normal in camera coordinates.
 in vec3 CamDir;         // Direction from vertex to camera
 in vec2 FragTexcoord;
 
+// Texture coordinates of this fragment repeated, rotated and offset by the specified texture parameters
+#define TexCoord(params) (mat2(params[3].x, -params[3].y, params[3].y, params[3].x) * (FragTexcoord * params[1]) + params[0])
+
 // Final fragment color
 out vec4 FragColor;
 
@@ -547,8 +560,14 @@ vec3 getNormal()
 //#ifndef HAS_TANGENTS
     vec3 pos_dx = dFdx(Position);
     vec3 pos_dy = dFdy(Position);
-    vec3 tex_dx = dFdx(vec3(FragTexcoord, 0.0));
-    vec3 tex_dy = dFdy(vec3(FragTexcoord, 0.0));
+#ifdef HAS_NORMALMAP
+    // The tangent space follows the transformed normal map coordinates
+    vec2 texcoord = TexCoord(uNormalTexParams);
+#else
+    vec2 texcoord = FragTexcoord;
+#endif
+    vec3 tex_dx = dFdx(vec3(texcoord, 0.0));
+    vec3 tex_dy = dFdy(vec3(texcoord, 0.0));
     vec3 t = (tex_dy.t * pos_dx - tex_dx.t * pos_dy) / (tex_dx.s * tex_dy.t - tex_dy.s * tex_dx.t);
 
 //#ifdef HAS_NORMALS
@@ -566,7 +585,7 @@ vec3 getNormal()
 
 #ifdef HAS_NORMALMAP
     float uNormalScale = 1.0;
-    vec3 n = texture(uNormalSampler, FragTexcoord).rgb;
+    vec3 n = texture(uNormalSampler, texcoord).rgb;
     n = normalize(tbn * ((2.0 * n - 1.0) * vec3(uNormalScale, uNormalScale, 1.0)));
 #else
     // The tbn matrix is linearly interpolated, so we need to re-normalize
@@ -687,7 +706,7 @@ void main() {
 #ifdef HAS_METALROUGHNESSMAP
     // Roughness is stored in the 'g' channel, metallic is stored in the 'b' channel.
     // This layout intentionally reserves the 'r' channel for (optional) occlusion map data
-    vec4 mrSample = texture(uMetallicRoughnessSampler, FragTexcoord);
+    vec4 mrSample = texture(uMetallicRoughnessSampler, TexCoord(uMetallicRoughnessTexParams));
     perceptualRoughness = mrSample.g * perceptualRoughness;
     metallic = mrSample.b * metallic;
 #endif
@@ -700,7 +719,7 @@ void main() {
 
     // The albedo may be defined from a base texture or a flat color
 #ifdef HAS_BASECOLORMAP
-    vec4 baseColor = SRGBtoLINEAR(texture(uBaseColorSampler, FragTexcoord)) * uBaseColor;
+    vec4 baseColor = SRGBtoLINEAR(texture(uBaseColorSampler, TexCoord(uBaseColorTexParams))) * uBaseColor;
 #else
     vec4 baseColor = uBaseColor;
 #endif
@@ -803,12 +822,12 @@ void main() {
 
     // Apply optional PBR terms for additional (optional) shading
 #ifdef HAS_OCCLUSIONMAP
-    float ao = texture(uOcclusionSampler, FragTexcoord).r;
+    float ao = texture(uOcclusionSampler, TexCoord(uOcclusionTexParams)).r;
     color = mix(color, color * ao, 1.0);//, uOcclusionStrength);
 #endif
 
 #ifdef HAS_EMISSIVEMAP
-    vec3 emissive = SRGBtoLINEAR(texture(uEmissiveSampler, FragTexcoord)).rgb * vec3(uEmissiveColor);
+    vec3 emissive = SRGBtoLINEAR(texture(uEmissiveSampler, TexCoord(uEmissiveTexParams))).rgb * vec3(uEmissiveColor);
 #else
     vec3 emissive = vec3(uEmissiveColor);
 #endif
@@ -913,7 +932,7 @@ void main() {
         vec2 pointCoord = Rotation * gl_PointCoord - vec2(0.5) + vec2(0.5);
         bool firstTex = true;
         if (MatTexVisible(0)) {
-            vec4 texColor = texture(MatTexture[0], pointCoord * MatTexRepeat(0) + MatTexOffset(0));
+            vec4 texColor = texture(MatTexture[0], MatTexcoord(0, pointCoord));
             if (firstTex) {
                 texMixed = texColor;
                 firstTex = false;
@@ -923,7 +942,7 @@ void main() {
         }
         #if MAT_TEXTURES > 1
             if (MatTexVisible(1)) {
-                vec4 texColor = texture(MatTexture[1], pointCoord * MatTexRepeat(1) + MatTexOffset(1));
+                vec4 texColor = texture(MatTexture[1], MatTexcoord(1, pointCoord));
                 if (firstTex) {
                     texMixed = texColor;
                     firstTex = false;
@@ -933,7 +952,7 @@ void main() {
             }
             #if MAT_TEXTURES > 2
                 if (MatTexVisible(2)) {
-                    vec4 texColor = texture(MatTexture[2], pointCoord * MatTexRepeat(2) + MatTexOffset(2));
+                    vec4 texColor = texture(MatTexture[2], MatTexcoord(2, pointCoord));
                     if (firstTex) {
                         texMixed = texColor;
                         firstTex = false;
@@ -1005,7 +1024,7 @@ void main() {
     #if MAT_TEXTURES > 0
         bool firstTex = true;
         if (MatTexVisible(0)) {
-            vec4 texColor = texture(MatTexture[0], FragTexcoord * MatTexRepeat(0) + MatTexOffset(0));
+            vec4 texColor = texture(MatTexture[0], MatTexcoord(0, FragTexcoord));
             if (firstTex) {
                 texMixed = texColor;
                 firstTex = false;
@@ -1015,7 +1034,7 @@ void main() {
         }
         #if MAT_TEXTURES > 1
             if (MatTexVisible(1)) {
-                vec4 texColor = texture(MatTexture[1], FragTexcoord * MatTexRepeat(1) + MatTexOffset(1));
+                vec4 texColor = texture(MatTexture[1], MatTexcoord(1, FragTexcoord));
                 if (firstTex) {
                     texMixed = texColor;
                     firstTex = false;
@@ -1025,7 +1044,7 @@ void main() {
             }
             #if MAT_TEXTURES > 2
                 if (MatTexVisible(2)) {
-                    vec4 texColor = texture(MatTexture[2], FragTexcoord * MatTexRepeat(2) + MatTexOffset(2));
+                    vec4 texColor = texture(MatTexture[2], MatTexcoord(2, FragTexcoord));
                     if (firstTex) {
                         texMixed = texColor;
                         firstTex = false;
@@ -1106,6 +1125,404 @@ void main() {
 }
 `
 
+const terrain_fragment_source = `precision highp float;
+
+// Inputs from vertex shader
+in vec4 Position;     // Fragment position in camera coordinates
+in vec3 Normal;       // Fragment normal in camera coordinates
+in vec2 FragTexcoord; // Fragment terrain coordinates
+
+#include <lights>
+#include <material>
+#include <phong_model>
+
+// Final fragment color
+out vec4 FragColor;
+
+#if MAT_TEXTURES > 0
+// Returns the coordinates of the specified texture for this fragment
+vec2 terrainTexcoord(int i) {
+
+    vec2 texcoord = MatTexcoord(i, FragTexcoord);
+    if (MatTexFlipY(i)) {
+        texcoord.y = 1.0 - texcoord.y;
+    }
+    return texcoord;
+}
+#endif
+
+void main() {
+
+    // The first texture is the splat map, whose channels are the weights of the layer textures
+    vec4 texMixed = vec4(1);
+    #if MAT_TEXTURES > 1
+        vec4 weights = vec4(1, 0, 0, 0);
+        if (MatTexVisible(0)) {
+            weights = texture(MatTexture[0], terrainTexcoord(0));
+        }
+        vec4 layers = vec4(0);
+        float total = 0.0;
+        layers += weights.r * texture(MatTexture[1], terrainTexcoord(1));
+        total += weights.r;
+        #if MAT_TEXTURES > 2
+            layers += weights.g * texture(MatTexture[2], terrainTexcoord(2));
+            total += weights.g;
+        #endif
+        #if MAT_TEXTURES > 3
+            layers += weights.b * texture(MatTexture[3], terrainTexcoord(3));
+            total += weights.b;
+        #endif
+        #if MAT_TEXTURES > 4
+            layers += weights.a * texture(MatTexture[4], terrainTexcoord(4));
+            total += weights.a;
+        #endif
+        if (total > 0.0) {
+            texMixed = layers / total;
+        } else {
+            texMixed = texture(MatTexture[1], terrainTexcoord(1));
+        }
+    #endif
+
+    // Combine material with texture colors
+    vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
+    vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
+
+    // Normalize interpolated normal as it may have shrinked
+    vec3 fragNormal = normalize(Normal);
+
+    // Calculate the direction vector from the fragment to the camera (origin)
+    vec3 camDir = normalize(-Position.xyz);
+
+    // Calculates the Ambient+Diffuse and Specular colors for this fragment using the Phong model.
+    vec3 Ambdiff, Spec;
+    phongModel(Position, fragNormal, camDir, vec3(matAmbient), vec3(matDiffuse), Ambdiff, Spec);
+
+    // Final fragment color
+    FragColor = min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0));
+}
+`
+
+const terrain_vertex_source = `#include <attributes>
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat3 NormalMatrix;
+uniform mat4 MVP;
+
+// Output variables for Fragment shader
+out vec4 Position;
+out vec3 Normal;
+out vec2 FragTexcoord;
+
+void main() {
+
+    // Transform vertex position and normal to camera coordinates
+    Position = ModelViewMatrix * vec4(VertexPosition, 1.0);
+    Normal = normalize(NormalMatrix * VertexNormal);
+
+    // Texture coordinates are the terrain X and Z coordinates,
+    // which are scaled by the repeat of each texture
+    FragTexcoord = VertexTexcoord;
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
+`
+
+const text_fragment_source = `precision highp float;
+
+// Atlas texture uniform
+uniform sampler2D MatTexture;
+
+// Text parameters uniform array
+uniform vec4 Text[6];
+#define TextColor       Text[0]
+#define OutlineColor    Text[1]
+#define ShadowColor     Text[2]
+#define OutlineWidth    Text[3].x         // outline width in distance field units
+#define ShadowSoftness  Text[3].y         // shadow softness in distance field units
+#define ShadowOffset    Text[3].zw        // shadow offset in atlas pixels
+#define TextSDF         bool(Text[4].x)   // atlas contains signed distance fields
+#define Bounds          Text[5]           // clipping rectangle in window coordinates (disabled if empty)
+
+// Inputs from vertex shader
+in vec2 FragTexcoord;
+
+// Output
+out vec4 FragColor;
+
+// Returns the specified color with its alpha multiplied by the coverage and premultiplied
+vec4 premultiply(vec4 color, float coverage) {
+
+    float alpha = color.a * coverage;
+    return vec4(color.rgb * alpha, alpha);
+}
+
+void main() {
+
+    // Discard fragments outside of the clipping rectangle
+    if (Bounds.z > Bounds.x) {
+        if (gl_FragCoord.x < Bounds.x || gl_FragCoord.x > Bounds.z ||
+            gl_FragCoord.y < Bounds.y || gl_FragCoord.y > Bounds.w) {
+            discard;
+        }
+    }
+
+    float dist = texture(MatTexture, FragTexcoord).a;
+
+    // Atlas with coverage instead of distances
+    if (!TextSDF) {
+        if (dist <= 0.0) {
+            discard;
+        }
+        FragColor = vec4(TextColor.rgb, TextColor.a * dist);
+        return;
+    }
+
+    // Antialiasing width from the screen space derivative of the distance
+    float aa = max(fwidth(dist) * 0.75, 0.0001);
+
+    // Text fill over the outline
+    vec4 color = premultiply(TextColor, smoothstep(0.5 - aa, 0.5 + aa, dist));
+    if (OutlineWidth > 0.0) {
+        float edge = 0.5 - OutlineWidth;
+        vec4 outline = premultiply(OutlineColor, smoothstep(edge - aa, edge + aa, dist));
+        color = color + outline * (1.0 - color.a);
+    }
+
+    // Shadow under the text and outline
+    if (ShadowColor.a > 0.0) {
+        vec2 offset = ShadowOffset / vec2(textureSize(MatTexture, 0));
+        float sdist = texture(MatTexture, FragTexcoord - offset).a;
+        float edge = 0.5 - OutlineWidth;
+        float soft = ShadowSoftness + aa;
+        vec4 shadow = premultiply(ShadowColor, smoothstep(edge - soft, edge + soft, sdist));
+        color = color + shadow * (1.0 - color.a);
+    }
+
+    if (color.a <= 0.0) {
+        discard;
+    }
+    FragColor = vec4(color.rgb / color.a, color.a);
+}
+`
+
+const text_vertex_source = `#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+// Outputs for fragment shader
+out vec2 FragTexcoord;
+
+void main() {
+
+    // Texture coordinates are in atlas image coordinates and are not flipped
+    FragTexcoord = VertexTexcoord;
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
+`
+
+const thickline_fragment_source = `precision highp float;
+
+// Inputs from vertex shader
+in vec3 Color;
+in float LineDist;
+in vec4 LineFrag;
+in float LineW;
+in vec2 LineRound;
+
+// Material uniforms
+uniform vec4 ThickLine[3];
+#define LineColor       ThickLine[0].rgb
+#define LineOpacity     ThickLine[0].a
+#define LineDashSize    ThickLine[2].x
+#define LineGapSize     ThickLine[2].y
+#define LineDashOffset  ThickLine[2].z
+
+// Final fragment color
+out vec4 FragColor;
+
+void main() {
+
+    // Dashes along the line
+    if (LineDashSize > 0.0 && mod(LineDist + LineDashOffset, LineDashSize + LineGapSize) > LineDashSize) {
+        discard;
+    }
+
+    // Round caps and joins outside of the circles at the segment ends
+    vec4 frag = LineFrag / LineW;
+    float along = frag.x;
+    float across = frag.y;
+    float hw = frag.w;
+    if (LineRound.x > 0.5 && along < 0.0 && along * along + across * across > hw * hw) {
+        discard;
+    }
+    if (LineRound.y > 0.5 && along > frag.z) {
+        along -= frag.z;
+        if (along * along + across * across > hw * hw) {
+            discard;
+        }
+    }
+
+    FragColor = vec4(LineColor * Color, LineOpacity);
+}
+`
+
+const thickline_vertex_source = `#include <attributes>
+
+// Other vertex attributes
+in vec3 LinePrev; // Other end of the segment or previous point of the join
+in vec3 LineNext; // Next point of the join
+in vec4 LineInfo; // Side or join corner, segment end or join flag, distance along the line and interior end flags
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat4 ProjMatrix;
+uniform vec2 LineViewport; // Viewport size in pixels
+
+// Material uniforms
+uniform vec4 ThickLine[3];
+#define LineWidth       ThickLine[1].x
+#define LineWorldUnits  bool(ThickLine[1].y)
+#define LineCap         int(ThickLine[1].z)
+#define LineJoin        int(ThickLine[1].w)
+#define LineMiterLimit  ThickLine[2].w
+
+// Line caps and joins
+#define CAP_BUTT    0
+#define CAP_ROUND   2
+#define JOIN_ROUND  0
+#define JOIN_MITER  2
+
+// Outputs for fragment shader
+out vec3 Color;
+out float LineDist;  // Distance along the line in world units
+out vec4 LineFrag;   // Pixel coordinates along and across the segment, segment length and half width, times W
+out float LineW;     // Clip W used to undo the perspective interpolation of LineFrag
+out vec2 LineRound;  // Round start and end flags
+
+// Returns the view position of the specified point moved to the near plane
+// if it is behind the camera and the other point is in front of it
+vec4 clipNear(vec4 p, vec4 other) {
+
+    // Only perspective projections need clipping
+    if (ProjMatrix[2][3] != -1.0) {
+        return p;
+    }
+    float nearZ = -0.5 * ProjMatrix[3][2] / ProjMatrix[2][2];
+    if (p.z > nearZ && other.z < nearZ) {
+        float t = (nearZ - other.z) / (p.z - other.z);
+        p = mix(other, p, t);
+    }
+    return p;
+}
+
+// Returns the screen position in pixels relative to the center of the viewport
+vec2 toScreen(vec4 clip) {
+
+    return clip.xy / clip.w * 0.5 * LineViewport;
+}
+
+// Returns half the line width in pixels at the specified clip W
+float halfWidth(float w) {
+
+    if (LineWorldUnits) {
+        return 0.5 * LineWidth * ProjMatrix[1][1] * 0.5 * LineViewport.y / w;
+    }
+    return 0.5 * LineWidth;
+}
+
+// Returns the clip position moved by the specified offset in pixels
+vec4 offsetClip(vec4 clip, vec2 offset) {
+
+    clip.xy += offset / (0.5 * LineViewport) * clip.w;
+    return clip;
+}
+
+void main() {
+
+    Color = VertexColor;
+    LineDist = LineInfo.z;
+    LineRound = vec2(0);
+    int interior = int(LineInfo.w);
+
+    // Join vertices fill the corner between two segments
+    if (LineInfo.y > 1.5) {
+        vec4 view = ModelViewMatrix * vec4(VertexPosition, 1.0);
+        vec4 clip = ProjMatrix * view;
+        vec4 clipPrev = ProjMatrix * (ModelViewMatrix * vec4(LinePrev, 1.0));
+        vec4 clipNext = ProjMatrix * (ModelViewMatrix * vec4(LineNext, 1.0));
+        LineFrag = vec4(0, 0, 1, 1) * clip.w;
+        LineW = clip.w;
+        gl_Position = clip;
+        int corner = int(LineInfo.x);
+        if (corner == 0 || LineJoin == JOIN_ROUND || LineJoin > JOIN_MITER || clipPrev.w <= 0.0 || clipNext.w <= 0.0 || clip.w <= 0.0) {
+            return;
+        }
+        vec2 s = toScreen(clip);
+        vec2 d1 = s - toScreen(clipPrev);
+        vec2 d2 = toScreen(clipNext) - s;
+        if (length(d1) == 0.0 || length(d2) == 0.0) {
+            return;
+        }
+        d1 = normalize(d1);
+        d2 = normalize(d2);
+        vec2 n1 = vec2(-d1.y, d1.x);
+        vec2 n2 = vec2(-d2.y, d2.x);
+        // The corner is on the outer side of the turn
+        float side = (d1.x * d2.y - d1.y * d2.x) > 0.0 ? -1.0 : 1.0;
+        float hw = halfWidth(clip.w);
+        vec2 offset;
+        if (corner == 1) {
+            offset = side * n1 * hw;
+        } else if (corner == 2) {
+            offset = side * n2 * hw;
+        } else {
+            vec2 miter = n1 + n2;
+            float cosine = length(miter) * 0.5;
+            if (LineJoin == JOIN_MITER && cosine > 0.0 && 1.0 / cosine <= LineMiterLimit) {
+                offset = side * normalize(miter) * hw / cosine;
+            } else {
+                offset = side * (n1 + n2) * 0.5 * hw;
+            }
+        }
+        gl_Position = offsetClip(clip, offset);
+        return;
+    }
+
+    // Segment vertices are the corners of a quad around the segment
+    vec4 view = ModelViewMatrix * vec4(VertexPosition, 1.0);
+    vec4 otherView = ModelViewMatrix * vec4(LinePrev, 1.0);
+    vec4 clippedView = clipNear(view, otherView);
+    otherView = clipNear(otherView, view);
+    vec4 clip = ProjMatrix * clippedView;
+    vec4 otherClip = ProjMatrix * otherView;
+    vec2 dir = toScreen(otherClip) - toScreen(clip);
+    float len = length(dir);
+    dir = len > 0.0 ? dir / len : vec2(1, 0);
+    vec2 perp = vec2(-dir.y, dir.x);
+    float hw = halfWidth(clip.w);
+
+    // The ends are extended by half the width for square and round caps and round joins
+    bool isEnd = LineInfo.y > 0.5;
+    bool thisInterior = (interior & 1) != 0;
+    bool otherInterior = (interior & 2) != 0;
+    bool thisRound = thisInterior ? LineJoin == JOIN_ROUND : LineCap == CAP_ROUND;
+    bool otherRound = otherInterior ? LineJoin == JOIN_ROUND : LineCap == CAP_ROUND;
+    float ext = 0.0;
+    if (thisInterior ? LineJoin == JOIN_ROUND : LineCap != CAP_BUTT) {
+        ext = hw;
+    }
+    float along = isEnd ? len + ext : -ext;
+    LineRound = isEnd ? vec2(otherRound, thisRound) : vec2(thisRound, otherRound);
+    // The side is negated at the end, so it is negated again for the distance across
+    // the segment to have the same sign on the same edge at both ends
+    float across = (isEnd ? -LineInfo.x : LineInfo.x) * hw;
+    LineFrag = vec4(along, across, len, hw) * clip.w;
+    LineW = clip.w;
+    gl_Position = offsetClip(clip, perp * LineInfo.x * hw - dir * ext);
+}
+`
+
 // Maps include name with its source code
 var includeMap = map[string]string{
 
@@ -1124,24 +1541,33 @@ var includeMap = map[string]string{
 // Maps shader name with its source code
 var shaderMap = map[string]string{
 
-	"basic_fragment":    basic_fragment_source,
-	"basic_vertex":      basic_vertex_source,
-	"panel_fragment":    panel_fragment_source,
-	"panel_vertex":      panel_vertex_source,
-	"physical_fragment": physical_fragment_source,
-	"physical_vertex":   physical_vertex_source,
-	"point_fragment":    point_fragment_source,
-	"point_vertex":      point_vertex_source,
-	"standard_fragment": standard_fragment_source,
-	"standard_vertex":   standard_vertex_source,
+	"basic_fragment":     basic_fragment_source,
+	"basic_vertex":       basic_vertex_source,
+	"panel_fragment":     panel_fragment_source,
+	"panel_vertex":       panel_vertex_source,
+	"physical_fragment":  physical_fragment_source,
+	"physical_vertex":    physical_vertex_source,
+	"point_fragment":     point_fragment_source,
+	"point_vertex":       point_vertex_source,
+	"standard_fragment":  standard_fragment_source,
+	"standard_vertex":    standard_vertex_source,
+	"terrain_fragment":   terrain_fragment_source,
+	"terrain_vertex":     terrain_vertex_source,
+	"text_fragment":      text_fragment_source,
+	"text_vertex":        text_vertex_source,
+	"thickline_fragment": thickline_fragment_source,
+	"thickline_vertex":   thickline_vertex_source,
 }
 
 // Maps program name with Proginfo struct with shaders names
 var programMap = map[string]ProgramInfo{
 
-	"basic":    {"basic_vertex", "basic_fragment", ""},
-	"panel":    {"panel_vertex", "panel_fragment", ""},
-	"physical": {"physical_vertex", "physical_fragment", ""},
-	"point":    {"point_vertex", "point_fragment", ""},
-	"standard": {"standard_vertex", "standard_fragment", ""},
+	"basic":     {"basic_vertex", "basic_fragment", ""},
+	"panel":     {"panel_vertex", "panel_fragment", ""},
+	"physical":  {"physical_vertex", "physical_fragment", ""},
+	"point":     {"point_vertex", "point_fragment", ""},
+	"standard":  {"standard_vertex", "standard_fragment", ""},
+	"terrain":   {"terrain_vertex", "terrain_fragment", ""},
+	"text":      {"text_vertex", "text_fragment", ""},
+	"thickline": {"thickline_vertex", "thickline_fragment", ""},
 }
@@ -19,7 +19,7 @@ void main() {
     #if MAT_TEXTURES > 0
         bool firstTex = true;
         if (MatTexVisible(0)) {
-            vec4 texColor = texture(MatTexture[0], FragTexcoord * MatTexRepeat(0) + MatTexOffset(0));
+            vec4 texColor = texture(MatTexture[0], MatTexcoord(0, FragTexcoord));
             if (firstTex) {
                 texMixed = texColor;
                 firstTex = false;
@@ -29,7 +29,7 @@ void main() {
         }
         #if MAT_TEXTURES > 1
             if (MatTexVisible(1)) {
-                vec4 texColor = texture(MatTexture[1], FragTexcoord * MatTexRepeat(1) + MatTexOffset(1));
+                vec4 texColor = texture(MatTexture[1], MatTexcoord(1, FragTexcoord));
                 if (firstTex) {
                     texMixed = texColor;
                     firstTex = false;
@@ -39,7 +39,7 @@ void main() {
             }
             #if MAT_TEXTURES > 2
                 if (MatTexVisible(2)) {
-                    vec4 texColor = texture(MatTexture[2], FragTexcoord * MatTexRepeat(2) + MatTexOffset(2));
+                    vec4 texColor = texture(MatTexture[2], MatTexcoord(2, FragTexcoord));
                     if (firstTex) {
                         texMixed = texColor;
                         firstTex = false;
@@ -0,0 +1,75 @@
+precision highp float;
+
+// Inputs from vertex shader
+in vec4 Position;     // Fragment position in camera coordinates
+in vec3 Normal;       // Fragment normal in camera coordinates
+in vec2 FragTexcoord; // Fragment terrain coordinates
+
+#include <lights>
+#include <material>
+#include <phong_model>
+
+// Final fragment color
+out vec4 FragColor;
+
+#if MAT_TEXTURES > 0
+// Returns the coordinates of the specified texture for this fragment
+vec2 terrainTexcoord(int i) {
+
+    vec2 texcoord = MatTexcoord(i, FragTexcoord);
+    if (MatTexFlipY(i)) {
+        texcoord.y = 1.0 - texcoord.y;
+    }
+    return texcoord;
+}
+#endif
+
+void main() {
+
+    // The first texture is the splat map, whose channels are the weights of the layer textures
+    vec4 texMixed = vec4(1);
+    #if MAT_TEXTURES > 1
+        vec4 weights = vec4(1, 0, 0, 0);
+        if (MatTexVisible(0)) {
+            weights = texture(MatTexture[0], terrainTexcoord(0));
+        }
+        vec4 layers = vec4(0);
+        float total = 0.0;
+        layers += weights.r * texture(MatTexture[1], terrainTexcoord(1));
+        total += weights.r;
+        #if MAT_TEXTURES > 2
+            layers += weights.g * texture(MatTexture[2], terrainTexcoord(2));
+            total += weights.g;
+        #endif
+        #if MAT_TEXTURES > 3
+            layers += weights.b * texture(MatTexture[3], terrainTexcoord(3));
+            total += weights.b;
+        #endif
+        #if MAT_TEXTURES > 4
+            layers += weights.a * texture(MatTexture[4], terrainTexcoord(4));
+            total += weights.a;
+        #endif
+        if (total > 0.0) {
+            texMixed = layers / total;
+        } else {
+            texMixed = texture(MatTexture[1], terrainTexcoord(1));
+        }
+    #endif
+
+    // Combine material with texture colors
+    vec4 matDiffuse = vec4(MatDiffuseColor, MatOpacity) * texMixed;
+    vec4 matAmbient = vec4(MatAmbientColor, MatOpacity) * texMixed;
+
+    // Normalize interpolated normal as it may have shrinked
+    vec3 fragNormal = normalize(Normal);
+
+    // Calculate the direction vector from the fragment to the camera (origin)
+    vec3 camDir = normalize(-Position.xyz);
+
+    // Calculates the Ambient+Diffuse and Specular colors for this fragment using the Phong model.
+    vec3 Ambdiff, Spec;
+    phongModel(Position, fragNormal, camDir, vec3(matAmbient), vec3(matDiffuse), Ambdiff, Spec);
+
+    // Final fragment color
+    FragColor = min(vec4(Ambdiff + Spec, matDiffuse.a), vec4(1.0));
+}
@@ -0,0 +1,23 @@
+#include <attributes>
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat3 NormalMatrix;
+uniform mat4 MVP;
+
+// Output variables for Fragment shader
+out vec4 Position;
+out vec3 Normal;
+out vec2 FragTexcoord;
+
+void main() {
+
+    // Transform vertex position and normal to camera coordinates
+    Position = ModelViewMatrix * vec4(VertexPosition, 1.0);
+    Normal = normalize(NormalMatrix * VertexNormal);
+
+    // Texture coordinates are the terrain X and Z coordinates,
+    // which are scaled by the repeat of each texture
+    FragTexcoord = VertexTexcoord;
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
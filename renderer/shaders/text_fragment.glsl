@@ -0,0 +1,76 @@
+precision highp float;
+
+// Atlas texture uniform
+uniform sampler2D MatTexture;
+
+// Text parameters uniform array
+uniform vec4 Text[6];
+#define TextColor       Text[0]
+#define OutlineColor    Text[1]
+#define ShadowColor     Text[2]
+#define OutlineWidth    Text[3].x         // outline width in distance field units
+#define ShadowSoftness  Text[3].y         // shadow softness in distance field units
+#define ShadowOffset    Text[3].zw        // shadow offset in atlas pixels
+#define TextSDF         bool(Text[4].x)   // atlas contains signed distance fields
+#define Bounds          Text[5]           // clipping rectangle in window coordinates (disabled if empty)
+
+// Inputs from vertex shader
+in vec2 FragTexcoord;
+
+// Output
+out vec4 FragColor;
+
+// Returns the specified color with its alpha multiplied by the coverage and premultiplied
+vec4 premultiply(vec4 color, float coverage) {
+
+    float alpha = color.a * coverage;
+    return vec4(color.rgb * alpha, alpha);
+}
+
+void main() {
+
+    // Discard fragments outside of the clipping rectangle
+    if (Bounds.z > Bounds.x) {
+        if (gl_FragCoord.x < Bounds.x || gl_FragCoord.x > Bounds.z ||
+            gl_FragCoord.y < Bounds.y || gl_FragCoord.y > Bounds.w) {
+            discard;
+        }
+    }
+
+    float dist = texture(MatTexture, FragTexcoord).a;
+
+    // Atlas with coverage instead of distances
+    if (!TextSDF) {
+        if (dist <= 0.0) {
+            discard;
+        }
+        FragColor = vec4(TextColor.rgb, TextColor.a * dist);
+        return;
+    }
+
+    // Antialiasing width from the screen space derivative of the distance
+    float aa = max(fwidth(dist) * 0.75, 0.0001);
+
+    // Text fill over the outline
+    vec4 color = premultiply(TextColor, smoothstep(0.5 - aa, 0.5 + aa, dist));
+    if (OutlineWidth > 0.0) {
+        float edge = 0.5 - OutlineWidth;
+        vec4 outline = premultiply(OutlineColor, smoothstep(edge - aa, edge + aa, dist));
+        color = color + outline * (1.0 - color.a);
+    }
+
+    // Shadow under the text and outline
+    if (ShadowColor.a > 0.0) {
+        vec2 offset = ShadowOffset / vec2(textureSize(MatTexture, 0));
+        float sdist = texture(MatTexture, FragTexcoord - offset).a;
+        float edge = 0.5 - OutlineWidth;
+        float soft = ShadowSoftness + aa;
+        vec4 shadow = premultiply(ShadowColor, smoothstep(edge - soft, edge + soft, sdist));
+        color = color + shadow * (1.0 - color.a);
+    }
+
+    if (color.a <= 0.0) {
+        discard;
+    }
+    FragColor = vec4(color.rgb / color.a, color.a);
+}
@@ -0,0 +1,14 @@
+#include <attributes>
+
+// Model uniforms
+uniform mat4 MVP;
+
+// Outputs for fragment shader
+out vec2 FragTexcoord;
+
+void main() {
+
+    // Texture coordinates are in atlas image coordinates and are not flipped
+    FragTexcoord = VertexTexcoord;
+    gl_Position = MVP * vec4(VertexPosition, 1.0);
+}
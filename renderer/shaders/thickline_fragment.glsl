@@ -0,0 +1,44 @@
+precision highp float;
+
+// Inputs from vertex shader
+in vec3 Color;
+in float LineDist;
+in vec4 LineFrag;
+in float LineW;
+in vec2 LineRound;
+
+// Material uniforms
+uniform vec4 ThickLine[3];
+#define LineColor       ThickLine[0].rgb
+#define LineOpacity     ThickLine[0].a
+#define LineDashSize    ThickLine[2].x
+#define LineGapSize     ThickLine[2].y
+#define LineDashOffset  ThickLine[2].z
+
+// Final fragment color
+out vec4 FragColor;
+
+void main() {
+
+    // Dashes along the line
+    if (LineDashSize > 0.0 && mod(LineDist + LineDashOffset, LineDashSize + LineGapSize) > LineDashSize) {
+        discard;
+    }
+
+    // Round caps and joins outside of the circles at the segment ends
+    vec4 frag = LineFrag / LineW;
+    float along = frag.x;
+    float across = frag.y;
+    float hw = frag.w;
+    if (LineRound.x > 0.5 && along < 0.0 && along * along + across * across > hw * hw) {
+        discard;
+    }
+    if (LineRound.y > 0.5 && along > frag.z) {
+        along -= frag.z;
+        if (along * along + across * across > hw * hw) {
+            discard;
+        }
+    }
+
+    FragColor = vec4(LineColor * Color, LineOpacity);
+}
@@ -0,0 +1,154 @@
+#include <attributes>
+
+// Other vertex attributes
+in vec3 LinePrev; // Other end of the segment or previous point of the join
+in vec3 LineNext; // Next point of the join
+in vec4 LineInfo; // Side or join corner, segment end or join flag, distance along the line and interior end flags
+
+// Model uniforms
+uniform mat4 ModelViewMatrix;
+uniform mat4 ProjMatrix;
+uniform vec2 LineViewport; // Viewport size in pixels
+
+// Material uniforms
+uniform vec4 ThickLine[3];
+#define LineWidth       ThickLine[1].x
+#define LineWorldUnits  bool(ThickLine[1].y)
+#define LineCap         int(ThickLine[1].z)
+#define LineJoin        int(ThickLine[1].w)
+#define LineMiterLimit  ThickLine[2].w
+
+// Line caps and joins
+#define CAP_BUTT    0
+#define CAP_ROUND   2
+#define JOIN_ROUND  0
+#define JOIN_MITER  2
+
+// Outputs for fragment shader
+out vec3 Color;
+out float LineDist;  // Distance along the line in world units
+out vec4 LineFrag;   // Pixel coordinates along and across the segment, segment length and half width, times W
+out float LineW;     // Clip W used to undo the perspective interpolation of LineFrag
+out vec2 LineRound;  // Round start and end flags
+
+// Returns the view position of the specified point moved to the near plane
+// if it is behind the camera and the other point is in front of it
+vec4 clipNear(vec4 p, vec4 other) {
+
+    // Only perspective projections need clipping
+    if (ProjMatrix[2][3] != -1.0) {
+        return p;
+    }
+    float nearZ = -0.5 * ProjMatrix[3][2] / ProjMatrix[2][2];
+    if (p.z > nearZ && other.z < nearZ) {
+        float t = (nearZ - other.z) / (p.z - other.z);
+        p = mix(other, p, t);
+    }
+    return p;
+}
+
+// Returns the screen position in pixels relative to the center of the viewport
+vec2 toScreen(vec4 clip) {
+
+    return clip.xy / clip.w * 0.5 * LineViewport;
+}
+
+// Returns half the line width in pixels at the specified clip W
+float halfWidth(float w) {
+
+    if (LineWorldUnits) {
+        return 0.5 * LineWidth * ProjMatrix[1][1] * 0.5 * LineViewport.y / w;
+    }
+    return 0.5 * LineWidth;
+}
+
+// Returns the clip position moved by the specified offset in pixels
+vec4 offsetClip(vec4 clip, vec2 offset) {
+
+    clip.xy += offset / (0.5 * LineViewport) * clip.w;
+    return clip;
+}
+
+void main() {
+
+    Color = VertexColor;
+    LineDist = LineInfo.z;
+    LineRound = vec2(0);
+    int interior = int(LineInfo.w);
+
+    // Join vertices fill the corner between two segments
+    if (LineInfo.y > 1.5) {
+        vec4 view = ModelViewMatrix * vec4(VertexPosition, 1.0);
+        vec4 clip = ProjMatrix * view;
+        vec4 clipPrev = ProjMatrix * (ModelViewMatrix * vec4(LinePrev, 1.0));
+        vec4 clipNext = ProjMatrix * (ModelViewMatrix * vec4(LineNext, 1.0));
+        LineFrag = vec4(0, 0, 1, 1) * clip.w;
+        LineW = clip.w;
+        gl_Position = clip;
+        int corner = int(LineInfo.x);
+        if (corner == 0 || LineJoin == JOIN_ROUND || LineJoin > JOIN_MITER || clipPrev.w <= 0.0 || clipNext.w <= 0.0 || clip.w <= 0.0) {
+            return;
+        }
+        vec2 s = toScreen(clip);
+        vec2 d1 = s - toScreen(clipPrev);
+        vec2 d2 = toScreen(clipNext) - s;
+        if (length(d1) == 0.0 || length(d2) == 0.0) {
+            return;
+        }
+        d1 = normalize(d1);
+        d2 = normalize(d2);
+        vec2 n1 = vec2(-d1.y, d1.x);
+        vec2 n2 = vec2(-d2.y, d2.x);
+        // The corner is on the outer side of the turn
+        float side = (d1.x * d2.y - d1.y * d2.x) > 0.0 ? -1.0 : 1.0;
+        float hw = halfWidth(clip.w);
+        vec2 offset;
+        if (corner == 1) {
+            offset = side * n1 * hw;
+        } else if (corner == 2) {
+            offset = side * n2 * hw;
+        } else {
+            vec2 miter = n1 + n2;
+            float cosine = length(miter) * 0.5;
+            if (LineJoin == JOIN_MITER && cosine > 0.0 && 1.0 / cosine <= LineMiterLimit) {
+                offset = side * normalize(miter) * hw / cosine;
+            } else {
+                offset = side * (n1 + n2) * 0.5 * hw;
+            }
+        }
+        gl_Position = offsetClip(clip, offset);
+        return;
+    }
+
+    // Segment vertices are the corners of a quad around the segment
+    vec4 view = ModelViewMatrix * vec4(VertexPosition, 1.0);
+    vec4 otherView = ModelViewMatrix * vec4(LinePrev, 1.0);
+    vec4 clippedView = clipNear(view, otherView);
+    otherView = clipNear(otherView, view);
+    vec4 clip = ProjMatrix * clippedView;
+    vec4 otherClip = ProjMatrix * otherView;
+    vec2 dir = toScreen(otherClip) - toScreen(clip);
+    float len = length(dir);
+    dir = len > 0.0 ? dir / len : vec2(1, 0);
+    vec2 perp = vec2(-dir.y, dir.x);
+    float hw = halfWidth(clip.w);
+
+    // The ends are extended by half the width for square and round caps and round joins
+    bool isEnd = LineInfo.y > 0.5;
+    bool thisInterior = (interior & 1) != 0;
+    bool otherInterior = (interior & 2) != 0;
+    bool thisRound = thisInterior ? LineJoin == JOIN_ROUND : LineCap == CAP_ROUND;
+    bool otherRound = otherInterior ? LineJoin == JOIN_ROUND : LineCap == CAP_ROUND;
+    float ext = 0.0;
+    if (thisInterior ? LineJoin == JOIN_ROUND : LineCap != CAP_BUTT) {
+        ext = hw;
+    }
+    float along = isEnd ? len + ext : -ext;
+    LineRound = isEnd ? vec2(otherRound, thisRound) : vec2(thisRound, otherRound);
+    // The side is negated at the end, so it is negated again for the distance across
+    // the segment to have the same sign on the same edge at both ends
+    float across = (isEnd ? -LineInfo.x : LineInfo.x) * hw;
+    LineFrag = vec4(along, across, len, hw) * clip.w;
+    LineW = clip.w;
+    gl_Position = offsetClip(clip, perp * LineInfo.x * hw - dir * ext);
+}
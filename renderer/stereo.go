@@ -0,0 +1,73 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+)
+
+// StereoMode specifies how the eye views of a stereo rig are output.
+type StereoMode int
+
+// The stereo output modes.
+const (
+	StereoSideBySide = StereoMode(iota) // Left eye in the left half and right eye in the right half
+	StereoAnaglyph                      // Red/cyan anaglyph: left eye in the red channel and right eye in the green and blue channels
+)
+
+// RenderStereo renders the specified scene with the eye views of the specified stereo rig
+// in the current OpenGL viewport using the specified output mode.
+// The stereo rig is updated with the aspect ratio of the eye views before rendering.
+// The color and depth buffers should be cleared before calling it.
+func (r *Renderer) RenderStereo(scene core.INode, rig *camera.Stereo, mode StereoMode) error {
+
+	x, y, width, height := r.gs.GetViewport()
+	if width <= 0 || height <= 0 {
+		return nil
+	}
+
+	var stats Stats
+	switch mode {
+	case StereoSideBySide:
+		half := width / 2
+		rig.Update(float32(half) / float32(height))
+		defer func() {
+			r.gs.Disable(gls.SCISSOR_TEST)
+			r.gs.Viewport(x, y, width, height)
+		}()
+		err := r.renderViewport(scene, rig.Left(), x, y, half, height, nil, false, true, false)
+		if err != nil {
+			return err
+		}
+		stats.add(&r.stats)
+		err = r.renderViewport(scene, rig.Right(), x+half, y, width-half, height, nil, false, true, false)
+		if err != nil {
+			return err
+		}
+		stats.add(&r.stats)
+
+	case StereoAnaglyph:
+		rig.Update(float32(width) / float32(height))
+		defer r.gs.ColorMask(true, true, true, true)
+		r.gs.ColorMask(true, false, false, true)
+		err := r.Render(scene, rig.Left())
+		if err != nil {
+			return err
+		}
+		stats.add(&r.stats)
+		r.gs.DepthMask(true)
+		r.gs.Clear(gls.DEPTH_BUFFER_BIT)
+		r.gs.ColorMask(false, true, true, true)
+		err = r.Render(scene, rig.Right())
+		if err != nil {
+			return err
+		}
+		stats.add(&r.stats)
+	}
+	r.stats = stats
+	return nil
+}
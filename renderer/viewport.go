@@ -0,0 +1,167 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package renderer
+
+import (
+	"github.com/g3n/engine/camera"
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
+)
+
+// Viewport is a rectangle of the window in which a scene is rendered with a camera.
+// Multiple viewports rendered in the same frame by RenderViewports are used
+// for split screen, picture-in-picture and multiple view layouts.
+// The rectangle is specified in fractions of the window size, from the top left corner,
+// so that it follows the window size.
+type Viewport struct {
+	Scene      core.INode     // Scene to render
+	Camera     camera.ICamera // Camera used to render the scene
+	X          float32        // Left edge as a fraction of the window width
+	Y          float32        // Top edge as a fraction of the window height
+	Width      float32        // Width as a fraction of the window width
+	Height     float32        // Height as a fraction of the window height
+	ClearColor *math32.Color4 // Color used to clear the viewport before rendering or nil to not clear the color
+	ClearDepth bool           // Whether the depth buffer is cleared before rendering (default is true)
+	Scissor    bool           // Whether rendering and clearing is limited to the viewport (default is true)
+	Aspect     bool           // Whether the aspect ratio of a *camera.Camera is set from the viewport (default is true)
+	Visible    bool           // Whether the viewport is rendered (default is true)
+}
+
+// NewViewport creates and returns a pointer to a new viewport which renders the specified
+// scene with the specified camera in the specified rectangle, in fractions of the window size.
+func NewViewport(scene core.INode, cam camera.ICamera, x, y, width, height float32) *Viewport {
+
+	vp := new(Viewport)
+	vp.Scene = scene
+	vp.Camera = cam
+	vp.SetRect(x, y, width, height)
+	vp.ClearDepth = true
+	vp.Scissor = true
+	vp.Aspect = true
+	vp.Visible = true
+	return vp
+}
+
+// SetRect sets the rectangle of the viewport in fractions of the window size, from the top left corner.
+func (vp *Viewport) SetRect(x, y, width, height float32) {
+
+	vp.X = x
+	vp.Y = y
+	vp.Width = width
+	vp.Height = height
+}
+
+// pixels returns the rectangle of the viewport in OpenGL window coordinates
+// (from the bottom left corner) inside the specified window rectangle.
+func (vp *Viewport) pixels(wx, wy, wwidth, wheight int32) (x, y, width, height int32) {
+
+	x = wx + int32(math32.Round(vp.X*float32(wwidth)))
+	right := wx + int32(math32.Round((vp.X+vp.Width)*float32(wwidth)))
+	top := wy + wheight - int32(math32.Round(vp.Y*float32(wheight)))
+	y = wy + wheight - int32(math32.Round((vp.Y+vp.Height)*float32(wheight)))
+	return x, y, right - x, top - y
+}
+
+// LayoutSplit arranges the specified viewports side by side with equal sizes,
+// in columns or, if vertical is true, in rows. It is normally used for split screen.
+func LayoutSplit(viewports []*Viewport, vertical bool) {
+
+	n := float32(len(viewports))
+	for i, vp := range viewports {
+		if vertical {
+			vp.SetRect(0, float32(i)/n, 1, 1/n)
+		} else {
+			vp.SetRect(float32(i)/n, 0, 1/n, 1)
+		}
+	}
+}
+
+// LayoutGrid arranges the specified viewports in a grid with the specified number of columns,
+// from left to right and top to bottom. Four viewports in two columns is the usual CAD layout.
+func LayoutGrid(viewports []*Viewport, cols int) {
+
+	if cols < 1 {
+		cols = 1
+	}
+	rows := (len(viewports) + cols - 1) / cols
+	w := 1 / float32(cols)
+	h := 1 / float32(rows)
+	for i, vp := range viewports {
+		vp.SetRect(float32(i%cols)*w, float32(i/cols)*h, w, h)
+	}
+}
+
+// RenderViewports renders the visible viewports in the specified order in the current
+// OpenGL viewport, which is normally the whole window, and restores it and the clear color
+// at the end. Later viewports are rendered over the earlier ones, as needed for picture-in-picture.
+// The statistics of all the viewports are added.
+func (r *Renderer) RenderViewports(viewports ...*Viewport) error {
+
+	wx, wy, wwidth, wheight := r.gs.GetViewport()
+	cr, cg, cb, ca := r.gs.GetClearColor()
+	defer func() {
+		r.gs.Disable(gls.SCISSOR_TEST)
+		r.gs.Viewport(wx, wy, wwidth, wheight)
+		r.gs.ClearColor(cr, cg, cb, ca)
+	}()
+
+	var stats Stats
+	for _, vp := range viewports {
+		if !vp.Visible {
+			continue
+		}
+		x, y, width, height := vp.pixels(wx, wy, wwidth, wheight)
+		if width <= 0 || height <= 0 {
+			continue
+		}
+		err := r.renderViewport(vp.Scene, vp.Camera, x, y, width, height, vp.ClearColor, vp.ClearDepth, vp.Scissor, vp.Aspect)
+		if err != nil {
+			return err
+		}
+		stats.add(&r.stats)
+	}
+	r.stats = stats
+	return nil
+}
+
+// renderViewport renders the specified scene with the specified camera in the specified rectangle,
+// clearing it first as specified.
+func (r *Renderer) renderViewport(scene core.INode, cam camera.ICamera, x, y, width, height int32,
+	clearColor *math32.Color4, clearDepth, scissor, aspect bool) error {
+
+	r.gs.Viewport(x, y, width, height)
+	if scissor {
+		r.gs.Enable(gls.SCISSOR_TEST)
+		r.gs.Scissor(x, y, uint32(width), uint32(height))
+	} else {
+		r.gs.Disable(gls.SCISSOR_TEST)
+	}
+	var mask uint
+	if clearColor != nil {
+		r.gs.ClearColor(clearColor.R, clearColor.G, clearColor.B, clearColor.A)
+		mask |= gls.COLOR_BUFFER_BIT
+	}
+	if clearDepth {
+		r.gs.DepthMask(true)
+		mask |= gls.DEPTH_BUFFER_BIT
+	}
+	if mask != 0 {
+		r.gs.Clear(mask)
+	}
+	if c, ok := cam.(*camera.Camera); ok && aspect {
+		c.SetAspect(float32(width) / float32(height))
+	}
+	return r.Render(scene, cam)
+}
+
+// add adds the specified statistics to these statistics.
+func (s *Stats) add(other *Stats) {
+
+	s.GraphicMats += other.GraphicMats
+	s.Lights += other.Lights
+	s.Panels += other.Panels
+	s.Others += other.Others
+}
@@ -6,8 +6,8 @@ package text
 
 import (
 	"bufio"
-	"fmt"
 	"github.com/g3n/engine/math32"
+	"golang.org/x/image/font"
 	"image"
 	"image/png"
 	"os"
@@ -25,25 +25,40 @@ type CharInfo struct {
 	OffsetY float32
 	RepeatX float32
 	RepeatY float32
+	// Metrics in pixels used to position the char image relative to the pen position on the base line
+	Advance  float32 // Horizontal distance to the next char
+	BearingX float32 // Horizontal distance from the pen position to the left of the char image
+	BearingY float32 // Vertical distance from the base line to the top of the char image (negative above the base line)
 }
 
 // Atlas represents an image containing characters and the information about their location in the image
 type Atlas struct {
 	Chars   []CharInfo
 	Image   *image.RGBA
-	Height  int // Recommended vertical space between two lines of text
-	Ascent  int // Distance from the top of a line to its base line
-	Descent int // Distance from the bottom of a line to its baseline
+	Height  int       // Recommended vertical space between two lines of text
+	Ascent  int       // Distance from the top of a line to its base line
+	Descent int       // Distance from the bottom of a line to its baseline
+	First   rune      // First char in the atlas
+	Last    rune      // Last char in the atlas
+	Size    float32   // Size in pixels of the font used to draw the chars
+	SDF     bool      // Whether the image alpha channel contains signed distance fields instead of coverage
+	Spread  int       // Distance in pixels covered by the signed distance fields at each side of the glyph edges
+	face    font.Face // Font face used to get kerning
 }
 
-// NewAtlas returns a pointer to a new Atlas object
+// NewAtlas returns a pointer to a new Atlas object with the chars drawn in
+// white over a transparent background, using the current font attributes.
 func NewAtlas(font *Font, first, last rune) *Atlas {
 
 	a := new(Atlas)
 	a.Chars = make([]CharInfo, last+1)
+	a.First = first
+	a.Last = last
+	a.Size = float32(font.attrib.PointSize * font.attrib.DPI / 72)
 
 	// Get font metrics
 	metrics := font.Metrics()
+	a.face = font.face
 	a.Height = int(metrics.Height >> 6)
 	a.Ascent = int(metrics.Ascent >> 6)
 	a.Descent = int(metrics.Descent >> 6)
@@ -71,8 +86,9 @@ func NewAtlas(font *Font, first, last rune) *Atlas {
 		cinfo.Y = lastY
 		cinfo.Width = width - lastX - 1
 		cinfo.Height += a.Height
+		cinfo.Advance = float32(width - lastX)
+		cinfo.BearingY = -float32(a.Ascent)
 		lastX = width
-		fmt.Printf("%c: cinfo:%+v\n", code, cinfo)
 
 		// Checks end of the current line
 		col++
@@ -95,8 +111,11 @@ func NewAtlas(font *Font, first, last rune) *Atlas {
 	height := (nlines * a.Height) + a.Descent
 
 	// Draw atlas image
-	canvas := NewCanvas(maxWidth, height, &math32.Color4{1, 1, 1, 1})
+	canvas := NewCanvas(maxWidth, height, &math32.Color4{1, 1, 1, 0})
+	fg, bg := font.fg, font.bg
+	font.SetColor(&math32.Color4{1, 1, 1, 1})
 	canvas.DrawText(0, 0, lines, font)
+	font.fg, font.bg = fg, bg
 	a.Image = canvas.RGBA
 
 	// Calculate normalized char positions in the image
@@ -109,11 +128,28 @@ func NewAtlas(font *Font, first, last rune) *Atlas {
 		char.RepeatX = float32(char.Width) / fWidth
 		char.RepeatY = float32(char.Height) / fHeight
 	}
-
-	a.SavePNG("atlas.png")
 	return a
 }
 
+// Char returns a pointer to the information of the specified char
+// or nil if the char is not in the atlas.
+func (a *Atlas) Char(code rune) *CharInfo {
+
+	if code < a.First || code > a.Last {
+		return nil
+	}
+	return &a.Chars[code]
+}
+
+// Kern returns the horizontal adjustment in pixels for the specified pair of chars.
+func (a *Atlas) Kern(r0, r1 rune) float32 {
+
+	if a.face == nil {
+		return 0
+	}
+	return float32(a.face.Kern(r0, r1)) / 64
+}
+
 // SavePNG saves the current atlas image as a PNG image file
 func (a *Atlas) SavePNG(filename string) error {
 
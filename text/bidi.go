@@ -0,0 +1,407 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"sort"
+	"unicode"
+)
+
+// bidiClass is the bidirectional character type of a rune as defined by the
+// Unicode Bidirectional Algorithm (UAX #9).
+type bidiClass uint8
+
+const (
+	bidiL   bidiClass = iota // Left to right
+	bidiR                    // Right to left
+	bidiAL                   // Arabic letter
+	bidiEN                   // European number
+	bidiES                   // European number separator
+	bidiET                   // European number terminator
+	bidiAN                   // Arabic number
+	bidiCS                   // Common number separator
+	bidiNSM                  // Non spacing mark
+	bidiBN                   // Boundary neutral
+	bidiB                    // Paragraph separator
+	bidiS                    // Segment separator
+	bidiWS                   // Whitespace
+	bidiON                   // Other neutral
+)
+
+// BidiRun is a sequence of chars of a line of text with the same
+// bidirectional embedding level.
+type BidiRun struct {
+	Start int // Byte offset of the first char of the run in the line
+	End   int // Byte offset after the last char of the run in the line
+	Level int // Embedding level. Odd levels are right to left.
+}
+
+// RTL returns whether the chars of the run are displayed from right to left
+func (r BidiRun) RTL() bool {
+
+	return r.Level&1 == 1
+}
+
+// BidiRuns returns the runs of the specified line of text in visual order, from left to right,
+// resolved with the implicit rules of the Unicode Bidirectional Algorithm (UAX #9).
+// The paragraph direction is the direction of the first strong char of the line.
+// Explicit embedding, override and isolate formatting chars are ignored.
+func BidiRuns(line string) []BidiRun {
+
+	if line == "" {
+		return nil
+	}
+	offsets := make([]int, 0, len(line))
+	runes := make([]rune, 0, len(line))
+	for i, r := range line {
+		offsets = append(offsets, i)
+		runes = append(runes, r)
+	}
+	levels := BidiLevels(runes)
+
+	// Splits the line in runs of the same level
+	var runs []BidiRun
+	start := 0
+	for i := 1; i <= len(runes); i++ {
+		if i == len(runes) || levels[i] != levels[start] {
+			end := len(line)
+			if i < len(runes) {
+				end = offsets[i]
+			}
+			runs = append(runs, BidiRun{offsets[start], end, levels[start]})
+			start = i
+		}
+	}
+
+	// Reverses any sequence of runs at or above each odd level, from the highest level (L2)
+	maxLevel, minOdd := 0, 1<<30
+	for _, run := range runs {
+		if run.Level > maxLevel {
+			maxLevel = run.Level
+		}
+		if run.Level&1 == 1 && run.Level < minOdd {
+			minOdd = run.Level
+		}
+	}
+	for level := maxLevel; level >= minOdd; level-- {
+		for i := 0; i < len(runs); {
+			if runs[i].Level < level {
+				i++
+				continue
+			}
+			j := i
+			for j < len(runs) && runs[j].Level >= level {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				runs[a], runs[b] = runs[b], runs[a]
+			}
+			i = j
+		}
+	}
+	return runs
+}
+
+// BidiLevels returns the resolved embedding levels of the specified runes of a line of text.
+// The paragraph level is 1 if the first strong char is right to left and 0 otherwise.
+func BidiLevels(runes []rune) []int {
+
+	classes := make([]bidiClass, len(runes))
+	for i, r := range runes {
+		classes[i] = bidiClassOf(r)
+	}
+
+	// Paragraph level from the first strong char (P2, P3)
+	base := 0
+	for _, c := range classes {
+		if c == bidiL {
+			break
+		}
+		if c == bidiR || c == bidiAL {
+			base = 1
+			break
+		}
+	}
+	sos := bidiL
+	if base == 1 {
+		sos = bidiR
+	}
+
+	// Non spacing marks take the type of the previous char (W1)
+	prev := sos
+	for i, c := range classes {
+		if c == bidiNSM {
+			classes[i] = prev
+		} else if c != bidiBN {
+			prev = classes[i]
+		}
+	}
+
+	// European numbers after arabic letters are arabic numbers (W2) and arabic letters are right to left (W3)
+	strong := sos
+	for i, c := range classes {
+		switch c {
+		case bidiL, bidiR:
+			strong = c
+		case bidiAL:
+			strong = c
+			classes[i] = bidiR
+		case bidiEN:
+			if strong == bidiAL {
+				classes[i] = bidiAN
+			}
+		}
+	}
+
+	// Single separators between numbers of the same type (W4)
+	for i := 1; i+1 < len(classes); i++ {
+		c, before, after := classes[i], classes[i-1], classes[i+1]
+		if c == bidiES && before == bidiEN && after == bidiEN {
+			classes[i] = bidiEN
+		} else if c == bidiCS && before == after && (before == bidiEN || before == bidiAN) {
+			classes[i] = before
+		}
+	}
+
+	// Terminators adjacent to european numbers (W5)
+	for i := 0; i < len(classes); {
+		if classes[i] != bidiET {
+			i++
+			continue
+		}
+		j := i
+		for j < len(classes) && classes[j] == bidiET {
+			j++
+		}
+		if (i > 0 && classes[i-1] == bidiEN) || (j < len(classes) && classes[j] == bidiEN) {
+			for k := i; k < j; k++ {
+				classes[k] = bidiEN
+			}
+		}
+		i = j
+	}
+
+	// Remaining separators and terminators are neutrals (W6)
+	for i, c := range classes {
+		if c == bidiES || c == bidiET || c == bidiCS {
+			classes[i] = bidiON
+		}
+	}
+
+	// European numbers after left to right chars are left to right (W7)
+	strong = sos
+	for i, c := range classes {
+		if c == bidiL || c == bidiR {
+			strong = c
+		} else if c == bidiEN && strong == bidiL {
+			classes[i] = bidiL
+		}
+	}
+
+	// Paired brackets take the direction of their content or context (N0)
+	strongDir := func(c bidiClass) bidiClass {
+		if c == bidiEN || c == bidiAN {
+			return bidiR
+		}
+		return c
+	}
+	for _, pair := range bidiBracketPairs(runes, classes) {
+		open, end := pair[0], pair[1]
+		dir := bidiON
+		for k := open + 1; k < end; k++ {
+			if d := strongDir(classes[k]); d == sos {
+				dir = sos
+				break
+			} else if d == bidiL || d == bidiR {
+				dir = d
+			}
+		}
+		if dir == bidiON {
+			continue
+		}
+		if dir != sos {
+			// Opposite direction only if the preceding context also has it
+			context := sos
+			for k := open - 1; k >= 0; k-- {
+				if d := strongDir(classes[k]); d == bidiL || d == bidiR {
+					context = d
+					break
+				}
+			}
+			if context != dir {
+				dir = sos
+			}
+		}
+		classes[open], classes[end] = dir, dir
+	}
+
+	// Neutrals between chars of the same direction take their direction,
+	// otherwise they take the paragraph direction (N1, N2)
+	for i := 0; i < len(classes); {
+		if !classes[i].neutral() {
+			i++
+			continue
+		}
+		j := i
+		for j < len(classes) && classes[j].neutral() {
+			j++
+		}
+		before, after := sos, sos
+		if i > 0 {
+			before = strongDir(classes[i-1])
+		}
+		if j < len(classes) {
+			after = strongDir(classes[j])
+		}
+		dir := sos
+		if before == after {
+			dir = before
+		}
+		for k := i; k < j; k++ {
+			classes[k] = dir
+		}
+		i = j
+	}
+
+	// Implicit levels (I1, I2)
+	levels := make([]int, len(runes))
+	for i, c := range classes {
+		level := base
+		if base&1 == 0 {
+			if c == bidiR {
+				level++
+			} else if c == bidiAN || c == bidiEN {
+				level += 2
+			}
+		} else if c == bidiL || c == bidiEN || c == bidiAN {
+			level++
+		}
+		levels[i] = level
+	}
+
+	// Trailing whitespace and separators are reset to the paragraph level (L1)
+	for i := len(runes) - 1; i >= 0; i-- {
+		c := bidiClassOf(runes[i])
+		if c != bidiWS && c != bidiS && c != bidiB && c != bidiBN {
+			break
+		}
+		levels[i] = base
+	}
+	for i, r := range runes {
+		if c := bidiClassOf(r); c == bidiS || c == bidiB {
+			levels[i] = base
+		}
+	}
+	return levels
+}
+
+// neutral returns whether the class is resolved by the neutral rules
+func (c bidiClass) neutral() bool {
+
+	return c == bidiB || c == bidiS || c == bidiWS || c == bidiON || c == bidiBN
+}
+
+// bidiMirrors contains the mirrored chars of the most common paired punctuation
+var bidiMirrors = map[rune]rune{
+	'(': ')', ')': '(', '<': '>', '>': '<', '[': ']', ']': '[', '{': '}', '}': '{',
+	'«': '»', '»': '«', '‹': '›', '›': '‹', '⁅': '⁆', '⁆': '⁅', '⁽': '⁾', '⁾': '⁽',
+	'₍': '₎', '₎': '₍', '≤': '≥', '≥': '≤', '〈': '〉', '〉': '〈', '《': '》', '》': '《',
+	'「': '」', '」': '「', '『': '』', '』': '『', '【': '】', '】': '【', '﴾': '﴿', '﴿': '﴾',
+}
+
+// bidiBrackets maps the opening paired brackets to their closing brackets
+var bidiBrackets = map[rune]rune{
+	'(': ')', '[': ']', '{': '}', '⁅': '⁆', '⁽': '⁾', '₍': '₎',
+	'〈': '〉', '《': '》', '「': '」', '『': '』', '【': '】',
+}
+
+// bidiBracketPairs returns the indices of the opening and closing neutral
+// paired brackets of the specified runes, sorted by the opening bracket (BD16)
+func bidiBracketPairs(runes []rune, classes []bidiClass) [][2]int {
+
+	const maxDepth = 63
+	var pairs [][2]int
+	var stack []int
+	for i, r := range runes {
+		if classes[i] != bidiON {
+			continue
+		}
+		if _, ok := bidiBrackets[r]; ok {
+			if len(stack) == maxDepth {
+				break
+			}
+			stack = append(stack, i)
+			continue
+		}
+		for s := len(stack) - 1; s >= 0; s-- {
+			if bidiBrackets[runes[stack[s]]] == r {
+				pairs = append(pairs, [2]int{stack[s], i})
+				stack = stack[:s]
+				break
+			}
+		}
+	}
+	sort.Slice(pairs, func(a, b int) bool { return pairs[a][0] < pairs[b][0] })
+	return pairs
+}
+
+// BidiMirror returns the mirrored glyph char of the specified char when displayed
+// in a right to left run, or the char itself if it has no mirrored char.
+func BidiMirror(r rune) rune {
+
+	if m, ok := bidiMirrors[r]; ok {
+		return m
+	}
+	return r
+}
+
+// bidiClassOf returns the bidirectional character type of the specified rune
+func bidiClassOf(r rune) bidiClass {
+
+	switch {
+	case r == '\n' || r == '\r' || r == 0x1C || r == 0x1D || r == 0x1E || r == 0x85 || r == 0x2029:
+		return bidiB
+	case r == '\t' || r == 0x0B || r == 0x1F:
+		return bidiS
+	case r == ' ' || r == 0x0C || r == 0x2028:
+		return bidiWS
+	case r >= '0' && r <= '9', r == 0xB2 || r == 0xB3 || r == 0xB9, r >= 0x6F0 && r <= 0x6F9,
+		r >= 0x2070 && r <= 0x2079, r >= 0x2080 && r <= 0x2089, r >= 0xFF10 && r <= 0xFF19:
+		return bidiEN
+	case r == '+' || r == '-', r == 0x207A || r == 0x207B || r == 0x208A || r == 0x208B,
+		r == 0x2212 || r == 0xFB29 || r == 0xFE62 || r == 0xFE63 || r == 0xFF0B || r == 0xFF0D:
+		return bidiES
+	case r == '#' || r == '$' || r == '%' || r == 0xB0 || r == 0xB1, r >= 0x2030 && r <= 0x2034,
+		r == 0x609 || r == 0x60A || r == 0x66A, unicode.Is(unicode.Sc, r):
+		return bidiET
+	case r >= 0x600 && r <= 0x605, r >= 0x660 && r <= 0x669, r == 0x66B || r == 0x66C, r == 0x6DD, r == 0x8E2:
+		return bidiAN
+	case r == ',' || r == '.' || r == '/' || r == ':', r == 0xA0 || r == 0x60C || r == 0x202F || r == 0x2044,
+		r == 0xFE50 || r == 0xFE52 || r == 0xFE55 || r == 0xFF0C || r == 0xFF0E || r == 0xFF0F || r == 0xFF1A:
+		return bidiCS
+	case r == 0x200E:
+		return bidiL
+	case r == 0x200F:
+		return bidiR
+	case r == 0x61C:
+		return bidiAL
+	case r < 0x20, r >= 0x7F && r <= 0x9F, r >= 0x200B && r <= 0x200D, r >= 0x202A && r <= 0x202E,
+		r >= 0x2060 && r <= 0x206F, r == 0xFEFF:
+		return bidiBN
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return bidiNSM
+	case r >= 0x590 && r <= 0x5FF, r >= 0x7C0 && r <= 0x85F, r >= 0xFB1D && r <= 0xFB4F,
+		r >= 0x10800 && r <= 0x10CFF, r >= 0x10D40 && r <= 0x10EBF, r >= 0x1E800 && r <= 0x1EDFF:
+		return bidiR
+	case r >= 0x600 && r <= 0x7BF, r >= 0x860 && r <= 0x8FF, r >= 0xFB50 && r <= 0xFDFF,
+		r >= 0xFE70 && r <= 0xFEFF, r >= 0x10D00 && r <= 0x10D3F, r >= 0x1EE00 && r <= 0x1EEFF:
+		return bidiAL
+	case unicode.In(r, unicode.Zs):
+		return bidiWS
+	case unicode.In(r, unicode.P, unicode.S):
+		return bidiON
+	}
+	return bidiL
+}
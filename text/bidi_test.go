@@ -0,0 +1,78 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Tests the resolved embedding levels of the implicit rules of UAX #9
+func TestBidiLevels(t *testing.T) {
+
+	cases := []struct {
+		name   string
+		line   string
+		levels []int
+	}{
+		{"left to right", "abc", []int{0, 0, 0}},
+		{"right to left", "אבג", []int{1, 1, 1}},
+		{"neutrals between directions", "ab אב cd", []int{0, 0, 0, 1, 1, 0, 0, 0}},
+		{"number in right to left", "אב 12 גד", []int{1, 1, 1, 2, 2, 1, 1, 1}},
+		{"number in left to right after right to left", "a א 12", []int{0, 0, 1, 1, 2, 2}},
+		{"number after arabic letter", "ب12", []int{1, 2, 2}},
+		{"number separator", "א 1.5", []int{1, 1, 2, 2, 2}},
+		{"number terminator", "א 10% ב", []int{1, 1, 2, 2, 2, 1, 1}},
+		{"isolated separator", "א , ב", []int{1, 1, 1, 1, 1}},
+		{"non spacing marks", "áאָ", []int{0, 0, 1, 1}},
+		{"brackets with paragraph direction", "ab (גד) ef", []int{0, 0, 0, 0, 1, 1, 0, 0, 0, 0}},
+		{"brackets with context direction", "a ג(ד) b", []int{0, 0, 1, 1, 1, 1, 0, 0}},
+		{"brackets in right to left", "אב (cd) גד", []int{1, 1, 1, 1, 2, 2, 1, 1, 1, 1}},
+		{"segment separator", "a גד\tהו", []int{0, 0, 1, 1, 0, 1, 1}},
+		{"trailing whitespace", "אב cd  ", []int{1, 1, 1, 2, 2, 1, 1}},
+	}
+	for _, c := range cases {
+		if levels := BidiLevels([]rune(c.line)); !reflect.DeepEqual(levels, c.levels) {
+			t.Errorf("%s: levels:%v expected:%v", c.name, levels, c.levels)
+		}
+	}
+}
+
+// Tests the reordering of the runs of lines in visual order
+func TestBidiRuns(t *testing.T) {
+
+	cases := []struct {
+		name string
+		line string
+		runs []BidiRun
+	}{
+		{"empty", "", nil},
+		{"left to right", "abc", []BidiRun{{0, 3, 0}}},
+		{"right to left", "אבג", []BidiRun{{0, 6, 1}}},
+		{"embedded right to left", "ab אב cd", []BidiRun{{0, 3, 0}, {3, 7, 1}, {7, 10, 0}}},
+		{"number in right to left", "אב 12 גד", []BidiRun{{7, 12, 1}, {5, 7, 2}, {0, 5, 1}}},
+		{"nested levels", "ab אב 12 גד cd", []BidiRun{{0, 3, 0}, {10, 15, 1}, {8, 10, 2}, {3, 8, 1}, {15, 18, 0}}},
+	}
+	for _, c := range cases {
+		if runs := BidiRuns(c.line); !reflect.DeepEqual(runs, c.runs) {
+			t.Errorf("%s: runs:%v expected:%v", c.name, runs, c.runs)
+		}
+		for _, run := range BidiRuns(c.line) {
+			if run.RTL() != (run.Level == 1) {
+				t.Errorf("%s: run:%v rtl:%v", c.name, run, run.RTL())
+			}
+		}
+	}
+}
+
+// Tests the mirrored chars of right to left runs
+func TestBidiMirror(t *testing.T) {
+
+	for r, m := range map[rune]rune{'(': ')', ']': '[', '«': '»', '≤': '≥', 'a': 'a', 'א': 'א'} {
+		if mirror := BidiMirror(r); mirror != m {
+			t.Errorf("mirror of %q:%q expected:%q", r, mirror, m)
+		}
+	}
+}
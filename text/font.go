@@ -8,7 +8,6 @@ import (
 	"github.com/g3n/engine/math32"
 	"github.com/golang/freetype/truetype"
 	"golang.org/x/image/font"
-	"golang.org/x/image/math/fixed"
 	"image"
 	"image/color"
 	"image/draw"
@@ -18,13 +17,21 @@ import (
 
 // Font represents a TrueType font face.
 // Attributes must be set prior to drawing.
+// Text is shaped with the OpenType layout features of the font (see Shape)
+// and chars not in the font are drawn with its fallback fonts.
 type Font struct {
-	ttf     *truetype.Font // The TrueType font
-	face    font.Face      // The font face
-	attrib  FontAttributes // Internal attribute cache
-	fg      *image.Uniform // Text color cache
-	bg      *image.Uniform // Background color cache
-	changed bool           // Whether attributes have changed and the font face needs to be recreated
+	ttf       *truetype.Font          // The TrueType font
+	face      font.Face               // The font face
+	attrib    FontAttributes          // Internal attribute cache
+	fg        *image.Uniform          // Text color cache
+	bg        *image.Uniform          // Background color cache
+	changed   bool                    // Whether attributes have changed and the font face needs to be recreated
+	glyph     truetype.GlyphBuf       // Buffer for loading the outlines of glyphs by index
+	gsub      *otTable                // Glyph substitution table (may be nil)
+	gpos      *otTable                // Glyph positioning table (may be nil)
+	gdef      *otGDEF                 // Glyph definition table (may be nil)
+	fallbacks []*Font                 // Fonts used for the chars not in this font
+	masks     map[glyphKey]*glyphMask // Cache of rasterized glyphs
 }
 
 // FontAttributes contains tunable attributes of a font.
@@ -64,6 +71,9 @@ func NewFontFromData(fontData []byte) (*Font, error) {
 
 	f := new(Font)
 	f.ttf = ttf
+	f.gsub = parseOTTable(otFindTable(fontData, "GSUB"), false)
+	f.gpos = parseOTTable(otFindTable(fontData, "GPOS"), true)
+	f.gdef = parseGDEF(otFindTable(fontData, "GDEF"))
 
 	// Initialize with default values
 	f.attrib = FontAttributes{}
@@ -170,11 +180,7 @@ func (f *Font) updateFace() {
 // the specified text. The supplied text string can contain line break escape sequences (\n).
 func (f *Font) MeasureText(text string) (int, int) {
 
-	// Create font drawer
 	f.updateFace()
-	d := &font.Drawer{Dst: nil, Src: f.fg, Face: f.face}
-
-	// Draw text
 	var width, height int
 	metrics := f.face.Metrics()
 	lineHeight := (metrics.Ascent + metrics.Descent).Ceil()
@@ -182,8 +188,8 @@ func (f *Font) MeasureText(text string) (int, int) {
 
 	lines := strings.Split(text, "\n")
 	for i, s := range lines {
-		d.Dot = fixed.P(0, height)
-		lineWidth := d.MeasureString(s).Ceil()
+		_, w := f.Shape(s)
+		lineWidth := int(math32.Ceil(w))
 		if lineWidth > width {
 			width = lineWidth
 		}
@@ -217,17 +223,14 @@ func (f *Font) DrawText(text string) *image.RGBA {
 func (f *Font) DrawTextOnImage(text string, x, y int, dst *image.RGBA) {
 
 	f.updateFace()
-	d := &font.Drawer{Dst: dst, Src: f.fg, Face: f.face}
-
-	// Draw text
 	metrics := f.face.Metrics()
 	py := y + metrics.Ascent.Round()
 	lineHeight := (metrics.Ascent + metrics.Descent).Ceil()
 	lineGap := int((f.attrib.LineSpacing - float64(1)) * float64(lineHeight))
 	lines := strings.Split(text, "\n")
 	for i, s := range lines {
-		d.Dot = fixed.P(x, py)
-		d.DrawString(s)
+		glyphs, _ := f.Shape(s)
+		f.drawShaped(dst, glyphs, x, py)
 		py += lineHeight
 		if i > 1 {
 			py += lineGap
@@ -271,25 +274,21 @@ func (c Canvas) DrawText(x, y int, text string, f *Font) {
 // TODO Implement caret as a gui.Panel in gui.Edit
 func (c Canvas) DrawTextCaret(x, y int, text string, f *Font, line, col int) error {
 
-	// Creates drawer
 	f.updateFace()
-	d := &font.Drawer{Dst: c.RGBA, Src: f.fg, Face: f.face}
-
-	// Draw text
 	metrics := f.face.Metrics()
 	py := y + metrics.Ascent.Round()
 	lineHeight := (metrics.Ascent + metrics.Descent).Ceil()
 	lineGap := int((f.attrib.LineSpacing - float64(1)) * float64(lineHeight))
 	lines := strings.Split(text, "\n")
 	for l, s := range lines {
-		d.Dot = fixed.P(x, py)
-		d.DrawString(s)
+		glyphs, _ := f.Shape(s)
+		f.drawShaped(c.RGBA, glyphs, x, py)
 		// Checks for caret position
 		if l == line && col <= StrCount(s) {
-			width, _ := f.MeasureText(StrPrefix(s, col))
+			width := int(math32.Round(caretX(s, glyphs, len(StrPrefix(s, col)))))
 			// Draw caret vertical line
 			caretH := int(f.attrib.PointSize) + 2
-			caretY := int(d.Dot.Y>>6) - int(f.attrib.PointSize) + 2
+			caretY := py - int(f.attrib.PointSize) + 2
 			color := Color4RGBA(&math32.Color4{0, 0, 0, 1}) // Hardcoded to black
 			for j := caretY; j < caretY+caretH; j++ {
 				c.RGBA.Set(x+width, j, color)
@@ -329,6 +328,30 @@ func (c Canvas) DrawTextCaret(x, y int, text string, f *Font, line, col int) err
 	return nil
 }
 
+// DrawUnderline draws a line with the text color of the specified font under the chars
+// of the specified line of text drawn at the specified position (in pixels) of this canvas,
+// between the specified byte offsets of the line.
+// It is used to mark the composition text of an input method editor.
+func (c Canvas) DrawUnderline(x, y int, line string, f *Font, start, end int) {
+
+	f.updateFace()
+	py := y + f.face.Metrics().Ascent.Round() + 2
+	glyphs, _ := f.Shape(line)
+	// Each char is underlined separately as bidi text may be not contiguous
+	for pos := start; pos < end && pos < len(line); {
+		next := graphemeNext(line, pos)
+		x0 := int(math32.Round(caretX(line, glyphs, pos)))
+		x1 := int(math32.Round(caretX(line, glyphs, next)))
+		if x0 > x1 {
+			x0, x1 = x1, x0
+		}
+		for px := x0; px < x1; px++ {
+			c.RGBA.Set(x+px, py, f.fg)
+		}
+		pos = next
+	}
+}
+
 // Color4RGBA converts a math32.Color4 to Go's color.RGBA.
 func Color4RGBA(c *math32.Color4) color.RGBA {
 
@@ -0,0 +1,160 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// graphemeProp is the grapheme cluster break property of a rune (UAX #29)
+type graphemeProp uint8
+
+const (
+	gpOther graphemeProp = iota
+	gpCR
+	gpLF
+	gpControl
+	gpExtend
+	gpZWJ
+	gpRegional
+	gpSpacingMark
+	gpL
+	gpV
+	gpT
+	gpLV
+	gpLVT
+	gpPictographic
+	gpConsonant // Indic conjunct consonant
+	gpLinker    // Indic conjunct linker (virama)
+)
+
+// graphemeNext returns the byte offset of the end of the grapheme cluster (user perceived char)
+// which starts at the specified byte offset of the string, using the extended grapheme
+// cluster boundary rules of UAX #29.
+func graphemeNext(s string, start int) int {
+
+	if start >= len(s) {
+		return len(s)
+	}
+	r, size := utf8.DecodeRuneInString(s[start:])
+	prev := graphemePropOf(r)
+	pos := start + size
+	regional := 0         // Number of consecutive regional indicators
+	pictographic := false // Extended pictographic followed by extend chars
+	conjunct := prev == gpConsonant
+	linked := false // Indic consonant followed by a linker
+	if prev == gpRegional {
+		regional = 1
+	}
+	if prev == gpPictographic {
+		pictographic = true
+	}
+	for pos < len(s) {
+		r, size = utf8.DecodeRuneInString(s[pos:])
+		next := graphemePropOf(r)
+		if !graphemeJoins(prev, next, regional, pictographic, linked) {
+			break
+		}
+		switch next {
+		case gpRegional:
+			regional++
+		case gpExtend:
+		case gpZWJ:
+		case gpLinker:
+			if conjunct {
+				linked = true
+			}
+		case gpConsonant:
+			conjunct = true
+			linked = false
+		default:
+			pictographic = false
+			conjunct = false
+			linked = false
+		}
+		if next == gpPictographic {
+			pictographic = true
+		}
+		prev = next
+		pos += size
+	}
+	return pos
+}
+
+// graphemeJoins returns whether there is no grapheme cluster boundary between
+// two chars with the specified properties
+func graphemeJoins(prev, next graphemeProp, regional int, pictographic, linked bool) bool {
+
+	switch {
+	case prev == gpCR && next == gpLF: // GB3
+		return true
+	case prev == gpCR || prev == gpLF || prev == gpControl: // GB4
+		return false
+	case next == gpCR || next == gpLF || next == gpControl: // GB5
+		return false
+	case prev == gpL && (next == gpL || next == gpV || next == gpLV || next == gpLVT): // GB6
+		return true
+	case (prev == gpLV || prev == gpV) && (next == gpV || next == gpT): // GB7
+		return true
+	case (prev == gpLVT || prev == gpT) && next == gpT: // GB8
+		return true
+	case next == gpExtend || next == gpZWJ || next == gpLinker || next == gpSpacingMark: // GB9, GB9a
+		return true
+	case next == gpConsonant && linked: // GB9c
+		return true
+	case prev == gpZWJ && next == gpPictographic && pictographic: // GB11
+		return true
+	case prev == gpRegional && next == gpRegional: // GB12, GB13
+		return regional%2 == 1
+	}
+	return false
+}
+
+// graphemePropOf returns the grapheme cluster break property of the specified rune
+func graphemePropOf(r rune) graphemeProp {
+
+	switch {
+	case r == '\r':
+		return gpCR
+	case r == '\n':
+		return gpLF
+	case r == 0x200D:
+		return gpZWJ
+	case r == 0x200C, r >= 0xFF9E && r <= 0xFF9F, r >= 0x1F3FB && r <= 0x1F3FF, r >= 0xE0020 && r <= 0xE007F:
+		return gpExtend
+	case r == 0x94D || r == 0x9CD || r == 0xACD || r == 0xB4D || r == 0xC4D || r == 0xD4D:
+		return gpLinker
+	case r >= 0x915 && r <= 0x939, r >= 0x958 && r <= 0x95F, r >= 0x978 && r <= 0x97F,
+		r >= 0x995 && r <= 0x9B9, r >= 0xA95 && r <= 0xAB9, r >= 0xB15 && r <= 0xB39,
+		r >= 0xC15 && r <= 0xC39, r >= 0xD15 && r <= 0xD3A:
+		return gpConsonant
+	case r >= 0x1F1E6 && r <= 0x1F1FF:
+		return gpRegional
+	case r >= 0x1100 && r <= 0x115F, r >= 0xA960 && r <= 0xA97C:
+		return gpL
+	case r >= 0x1160 && r <= 0x11A7, r >= 0xD7B0 && r <= 0xD7C6:
+		return gpV
+	case r >= 0x11A8 && r <= 0x11FF, r >= 0xD7CB && r <= 0xD7FB:
+		return gpT
+	case r >= 0xAC00 && r <= 0xD7A3:
+		if (r-0xAC00)%28 == 0 {
+			return gpLV
+		}
+		return gpLVT
+	case r >= 0x1F000 && r <= 0x1FAFF, r >= 0x2600 && r <= 0x27BF, r >= 0x2300 && r <= 0x23FF,
+		r >= 0x2B00 && r <= 0x2BFF, r == 0xA9 || r == 0xAE || r == 0x203C || r == 0x2049 || r == 0x2122,
+		r == 0x2139, r >= 0x2194 && r <= 0x21AA, r == 0x3030 || r == 0x303D || r == 0x3297 || r == 0x3299:
+		return gpPictographic
+	case unicode.In(r, unicode.Mn, unicode.Me):
+		return gpExtend
+	case unicode.In(r, unicode.Mc):
+		return gpSpacingMark
+	case unicode.In(r, unicode.Cc, unicode.Zl, unicode.Zp), r == 0xFEFF, r >= 0x200E && r <= 0x200F,
+		r >= 0x202A && r <= 0x202E, r >= 0x2060 && r <= 0x206F:
+		return gpControl
+	}
+	return gpOther
+}
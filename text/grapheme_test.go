@@ -0,0 +1,77 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"reflect"
+	"testing"
+)
+
+// graphemes returns the grapheme clusters of the specified string.
+func graphemes(s string) []string {
+
+	var clusters []string
+	for pos := 0; pos < len(s); {
+		next := graphemeNext(s, pos)
+		clusters = append(clusters, s[pos:next])
+		pos = next
+	}
+	return clusters
+}
+
+// Tests the grapheme cluster boundaries of the UAX #29 rules
+func TestGraphemeNext(t *testing.T) {
+
+	cases := []struct {
+		name     string
+		s        string
+		clusters []string
+	}{
+		{"empty", "", nil},
+		{"ascii", "abc", []string{"a", "b", "c"}},
+		{"CR LF", "a\r\nb", []string{"a", "\r\n", "b"}},
+		{"LF CR", "\n\r", []string{"\n", "\r"}},
+		{"control", "a\x00́", []string{"a", "\x00", "́"}},
+		{"combining marks", "ẹ́x", []string{"ẹ́", "x"}},
+		{"spacing mark", "काa", []string{"का", "a"}},
+		{"hangul jamo", "각ᄀ", []string{"각", "ᄀ"}},
+		{"hangul syllables", "각한ᆨ가", []string{"각", "한ᆨ", "가"}},
+		{"emoji modifier", "👍🏽👍", []string{"👍🏽", "👍"}},
+		{"emoji ZWJ sequence", "👨‍👩‍👧a", []string{"👨‍👩‍👧", "a"}},
+		{"ZWJ after letter", "a‍👩", []string{"a‍", "👩"}},
+		{"regional indicators", "🇫🇷🇩🇪🇮", []string{"🇫🇷", "🇩🇪", "🇮"}},
+		{"indic conjunct", "क्षि", []string{"क्षि"}},
+		{"indic word", "नमस्ते", []string{"न", "म", "स्ते"}},
+	}
+	for _, c := range cases {
+		if clusters := graphemes(c.s); !reflect.DeepEqual(clusters, c.clusters) {
+			t.Errorf("%s: clusters:%q expected:%q", c.name, clusters, c.clusters)
+		}
+	}
+}
+
+// Tests that the string functions count, find, remove and insert grapheme clusters
+func TestStrGraphemes(t *testing.T) {
+
+	s := "aé🇫🇷b"
+	if count := StrCount(s); count != 4 {
+		t.Errorf("count:%d", count)
+	}
+	if start, length := StrFind(s, 2); start != 4 || length != 8 {
+		t.Errorf("find start:%d length:%d", start, length)
+	}
+	if start, length := StrFind(s, 4); start != len(s) || length != 0 {
+		t.Errorf("find after end start:%d length:%d", start, length)
+	}
+	if r := StrRemove(s, 1); r != "a🇫🇷b" {
+		t.Errorf("remove:%q", r)
+	}
+	if r := StrInsert(s, "x", 2); r != "aéx🇫🇷b" {
+		t.Errorf("insert:%q", r)
+	}
+	if r := StrPrefix(s, 3); r != "aé🇫🇷" {
+		t.Errorf("prefix:%q", r)
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"github.com/g3n/engine/math32"
+)
+
+// Glyph is the quad of a char of a text positioned by Atlas.Layout
+type Glyph struct {
+	Code   rune    // Char code
+	X      float32 // Position of the left of the quad
+	Y      float32 // Position of the top of the quad from the top of the text (increasing downwards)
+	Width  float32 // Width of the quad
+	Height float32 // Height of the quad
+	U0     float32 // Texture coordinates of the top left corner of the char in the atlas image
+	V0     float32
+	U1     float32 // Texture coordinates of the bottom right corner of the char in the atlas image
+	V1     float32
+}
+
+// Layout positions the quads of the chars of the specified text drawn with the
+// specified font size, applying kerning and breaking lines at line break escape sequences (\n).
+// The size is in the units of the returned quads, normally pixels or world units.
+// The quads are appended to glyphs, which can be reused between calls to avoid reallocations,
+// and the resulting slice is returned with the width and height of the text.
+// Chars not in the atlas are ignored.
+func (a *Atlas) Layout(text string, size, lineSpacing float32, glyphs []Glyph) ([]Glyph, float32, float32) {
+
+	scale := size / a.Size
+	lineHeight := float32(a.Height) * scale
+	lineGap := (lineSpacing - 1) * lineHeight
+	var width, penX float32
+	baseline := float32(a.Ascent) * scale
+	height := lineHeight
+	var prev rune = -1
+	for _, code := range text {
+		if code == '\n' {
+			penX = 0
+			baseline += lineHeight + lineGap
+			height += lineHeight + lineGap
+			prev = -1
+			continue
+		}
+		char := a.Char(code)
+		if char == nil {
+			continue
+		}
+		if prev >= 0 {
+			penX += a.Kern(prev, code) * scale
+		}
+		prev = code
+		if char.Width > 0 && char.Height > 0 {
+			glyphs = append(glyphs, Glyph{
+				Code:   code,
+				X:      penX + char.BearingX*scale,
+				Y:      baseline + char.BearingY*scale,
+				Width:  float32(char.Width) * scale,
+				Height: float32(char.Height) * scale,
+				U0:     char.OffsetX,
+				V0:     char.OffsetY,
+				U1:     char.OffsetX + char.RepeatX,
+				V1:     char.OffsetY + char.RepeatY,
+			})
+		}
+		penX += char.Advance * scale
+		if penX > width {
+			width = penX
+		}
+	}
+	return glyphs, width, height
+}
+
+// AppendGlyphVertices appends to the specified array the positions (x,y,z) and texture
+// coordinates (u,v) of the two triangles of each of the specified glyphs, displaced by dx and dy.
+// If flipY is true the Y axis points upwards, as used in 3D scenes.
+func AppendGlyphVertices(dst *math32.ArrayF32, glyphs []Glyph, dx, dy float32, flipY bool) {
+
+	sy := float32(1)
+	if flipY {
+		sy = -1
+	}
+	for i := range glyphs {
+		g := &glyphs[i]
+		x0 := g.X + dx
+		x1 := x0 + g.Width
+		y0 := (g.Y + dy) * sy
+		y1 := (g.Y + g.Height + dy) * sy
+		dst.Append(
+			x0, y0, 0, g.U0, g.V0,
+			x0, y1, 0, g.U0, g.V1,
+			x1, y1, 0, g.U1, g.V1,
+			x0, y0, 0, g.U0, g.V0,
+			x1, y1, 0, g.U1, g.V1,
+			x1, y0, 0, g.U1, g.V0,
+		)
+	}
+}
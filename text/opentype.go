@@ -0,0 +1,807 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"sort"
+)
+
+// This file contains a subset of the OpenType layout tables used to shape text:
+// GSUB single, multiple, ligature and contextual substitutions, GPOS single and pair
+// adjustments, mark attachments and contextual positioning, and the GDEF glyph classes.
+
+// otTable is a parsed GSUB or GPOS table
+type otTable struct {
+	data     []byte         // Table data
+	scripts  map[string]int // Offsets of the script tables by tag
+	features []otFeature    // Feature list
+	lookups  []otLookup     // Lookup list
+	gpos     bool           // Whether this is a GPOS table
+}
+
+// otFeature is a feature of an OpenType layout table
+type otFeature struct {
+	tag     string // Feature tag
+	lookups []int  // Indices of the feature lookups
+}
+
+// otLookup is a lookup of an OpenType layout table
+type otLookup struct {
+	kind      int   // Lookup type, with extension lookups resolved
+	flag      int   // Lookup flags
+	markSet   int   // Mark filtering set index
+	subtables []int // Offsets of the subtables in the table data
+}
+
+// Lookup flags
+const (
+	otIgnoreBase          = 0x02
+	otIgnoreLigatures     = 0x04
+	otIgnoreMarks         = 0x08
+	otUseMarkFilteringSet = 0x10
+)
+
+// GDEF glyph classes
+const (
+	otClassBase      = 1
+	otClassLigature  = 2
+	otClassMark      = 3
+	otClassComponent = 4
+)
+
+// otGDEF is a parsed GDEF table
+type otGDEF struct {
+	data       []byte // Table data
+	classDef   int    // Offset of the glyph class definitions
+	markAttach int    // Offset of the mark attachment class definitions
+	markSets   int    // Offset of the mark glyph sets
+}
+
+// otGlyph is a glyph of a shaping buffer
+type otGlyph struct {
+	index    uint16 // Glyph index in the font
+	char     rune   // Char which originated the glyph
+	cluster  int    // Byte offset in the text of the first char of the glyph cluster
+	mask     uint32 // Features which can be applied to this glyph
+	class    int    // GDEF glyph class
+	flags    int    // Shaper specific flags
+	syllable int    // Shaper specific syllable number
+	xAdvance int    // Horizontal advance in font units
+	xOffset  int    // Horizontal placement in font units
+	yOffset  int    // Vertical placement in font units (increasing upwards)
+	attach   int    // Index of the glyph this mark is attached to relative to this glyph (0 if none)
+}
+
+// u16 returns the big endian unsigned 16 bit integer at the specified offset or 0 if out of bounds
+func u16(b []byte, off int) int {
+
+	if off < 0 || off+2 > len(b) {
+		return 0
+	}
+	return int(b[off])<<8 | int(b[off+1])
+}
+
+// i16 returns the big endian signed 16 bit integer at the specified offset or 0 if out of bounds
+func i16(b []byte, off int) int {
+
+	return int(int16(u16(b, off)))
+}
+
+// u32 returns the big endian unsigned 32 bit integer at the specified offset or 0 if out of bounds
+func u32(b []byte, off int) int {
+
+	return u16(b, off)<<16 | u16(b, off+2)
+}
+
+// tagAt returns the 4 byte tag at the specified offset or an empty string if out of bounds
+func tagAt(b []byte, off int) string {
+
+	if off < 0 || off+4 > len(b) {
+		return ""
+	}
+	return string(b[off : off+4])
+}
+
+// otFindTable returns the data of the table with the specified tag in the specified font data
+func otFindTable(font []byte, tag string) []byte {
+
+	count := u16(font, 4)
+	for i := 0; i < count; i++ {
+		rec := 12 + 16*i
+		if tagAt(font, rec) != tag {
+			continue
+		}
+		off := u32(font, rec+8)
+		length := u32(font, rec+12)
+		if off+length > len(font) {
+			return nil
+		}
+		return font[off : off+length]
+	}
+	return nil
+}
+
+// parseOTTable parses the specified GSUB or GPOS table data
+func parseOTTable(data []byte, gpos bool) *otTable {
+
+	if len(data) < 10 {
+		return nil
+	}
+	t := &otTable{data: data, scripts: make(map[string]int), gpos: gpos}
+	extension := 7
+	if gpos {
+		extension = 9
+	}
+
+	scriptList := u16(data, 4)
+	for i := 0; i < u16(data, scriptList); i++ {
+		rec := scriptList + 2 + 6*i
+		t.scripts[tagAt(data, rec)] = scriptList + u16(data, rec+4)
+	}
+
+	featureList := u16(data, 6)
+	for i := 0; i < u16(data, featureList); i++ {
+		rec := featureList + 2 + 6*i
+		off := featureList + u16(data, rec+4)
+		f := otFeature{tag: tagAt(data, rec)}
+		for j := 0; j < u16(data, off+2); j++ {
+			f.lookups = append(f.lookups, u16(data, off+4+2*j))
+		}
+		t.features = append(t.features, f)
+	}
+
+	lookupList := u16(data, 8)
+	for i := 0; i < u16(data, lookupList); i++ {
+		off := lookupList + u16(data, lookupList+2+2*i)
+		l := otLookup{kind: u16(data, off), flag: u16(data, off+2)}
+		count := u16(data, off+4)
+		// All the subtables of an extension lookup are extension subtables
+		isExt := l.kind == extension
+		for j := 0; j < count; j++ {
+			sub := off + u16(data, off+6+2*j)
+			if isExt {
+				l.kind = u16(data, sub+2)
+				sub += u32(data, sub+4)
+			}
+			l.subtables = append(l.subtables, sub)
+		}
+		if l.flag&otUseMarkFilteringSet != 0 {
+			l.markSet = u16(data, off+6+2*count)
+		}
+		t.lookups = append(t.lookups, l)
+	}
+	return t
+}
+
+// langSys returns the offset of the default language system of the first of the
+// specified scripts found in the table, or 0 if none is found.
+func (t *otTable) langSys(scripts []string) int {
+
+	for _, tag := range scripts {
+		off, ok := t.scripts[tag]
+		if !ok {
+			continue
+		}
+		if def := u16(t.data, off); def != 0 {
+			return off + def
+		}
+		if u16(t.data, off+2) > 0 {
+			return off + u16(t.data, off+6)
+		}
+	}
+	return 0
+}
+
+// featureLookups returns the indices of the lookups of the feature with the specified
+// tag in the specified language system.
+func (t *otTable) featureLookups(langSys int, tag string) []int {
+
+	if langSys == 0 {
+		return nil
+	}
+	var lookups []int
+	check := func(index int) {
+		if index < len(t.features) && t.features[index].tag == tag {
+			lookups = append(lookups, t.features[index].lookups...)
+		}
+	}
+	if required := u16(t.data, langSys+2); required != 0xFFFF {
+		check(required)
+	}
+	for i := 0; i < u16(t.data, langSys+4); i++ {
+		check(u16(t.data, langSys+6+2*i))
+	}
+	return lookups
+}
+
+// otCoverage returns the coverage index of the specified glyph in the coverage table
+// at the specified offset, or -1 if the glyph is not covered.
+func otCoverage(b []byte, off int, g uint16) int {
+
+	glyph := int(g)
+	count := u16(b, off+2)
+	switch u16(b, off) {
+	case 1:
+		i := sort.Search(count, func(i int) bool { return u16(b, off+4+2*i) >= glyph })
+		if i < count && u16(b, off+4+2*i) == glyph {
+			return i
+		}
+	case 2:
+		i := sort.Search(count, func(i int) bool { return u16(b, off+4+6*i+2) >= glyph })
+		if i < count && u16(b, off+4+6*i) <= glyph {
+			rec := off + 4 + 6*i
+			return u16(b, rec+4) + glyph - u16(b, rec)
+		}
+	}
+	return -1
+}
+
+// otClass returns the class of the specified glyph in the class definition table
+// at the specified offset. Glyphs not defined have class 0.
+func otClass(b []byte, off int, g uint16) int {
+
+	if off == 0 {
+		return 0
+	}
+	glyph := int(g)
+	switch u16(b, off) {
+	case 1:
+		start := u16(b, off+2)
+		if glyph >= start && glyph < start+u16(b, off+4) {
+			return u16(b, off+6+2*(glyph-start))
+		}
+	case 2:
+		count := u16(b, off+2)
+		i := sort.Search(count, func(i int) bool { return u16(b, off+4+6*i+2) >= glyph })
+		if i < count && u16(b, off+4+6*i) <= glyph {
+			return u16(b, off+4+6*i+4)
+		}
+	}
+	return 0
+}
+
+// parseGDEF parses the specified GDEF table data
+func parseGDEF(data []byte) *otGDEF {
+
+	if len(data) < 12 {
+		return nil
+	}
+	g := &otGDEF{data: data}
+	g.classDef = u16(data, 4)
+	g.markAttach = u16(data, 10)
+	if u16(data, 2) >= 2 {
+		g.markSets = u16(data, 12)
+	}
+	return g
+}
+
+// glyphClass returns the GDEF class of the specified glyph, or 0 if it is not defined
+func (g *otGDEF) glyphClass(glyph uint16) int {
+
+	return otClass(g.data, g.classDef, glyph)
+}
+
+// otShaper applies OpenType layout lookups to a buffer of glyphs
+type otShaper struct {
+	gdef *otGDEF   // Glyph definitions (may be nil)
+	buf  []otGlyph // Glyphs being shaped
+}
+
+// ignored returns whether the glyph at the specified position of the buffer
+// is skipped by the specified lookup
+func (s *otShaper) ignored(l *otLookup, i int) bool {
+
+	g := &s.buf[i]
+	switch g.class {
+	case otClassBase:
+		return l.flag&otIgnoreBase != 0
+	case otClassLigature:
+		return l.flag&otIgnoreLigatures != 0
+	case otClassMark:
+		if l.flag&otIgnoreMarks != 0 {
+			return true
+		}
+		if s.gdef == nil {
+			return false
+		}
+		if l.flag&otUseMarkFilteringSet != 0 && s.gdef.markSets != 0 {
+			base := s.gdef.markSets
+			set := base + u32(s.gdef.data, base+4+4*l.markSet)
+			return otCoverage(s.gdef.data, set, g.index) < 0
+		}
+		if attach := l.flag >> 8; attach != 0 {
+			return otClass(s.gdef.data, s.gdef.markAttach, g.index) != attach
+		}
+	}
+	return false
+}
+
+// next returns the position of the next glyph after the specified position
+// not skipped by the specified lookup, or -1 if there is none.
+func (s *otShaper) next(l *otLookup, i int) int {
+
+	for i++; i < len(s.buf); i++ {
+		if !s.ignored(l, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// prev returns the position of the previous glyph before the specified position
+// not skipped by the specified lookup, or -1 if there is none.
+func (s *otShaper) prev(l *otLookup, i int) int {
+
+	for i--; i >= 0; i-- {
+		if !s.ignored(l, i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// applyLookup applies the specified lookup of the table to the glyphs
+// of the buffer whose masks include the specified mask.
+func (s *otShaper) applyLookup(t *otTable, index int, mask uint32) {
+
+	if index >= len(t.lookups) {
+		return
+	}
+	l := &t.lookups[index]
+	for i := 0; i < len(s.buf); {
+		if s.buf[i].mask&mask == 0 || s.ignored(l, i) {
+			i++
+			continue
+		}
+		next, ok := s.applyAt(t, l, i)
+		if !ok {
+			i++
+			continue
+		}
+		i = next
+	}
+}
+
+// applyAt applies the first subtable of the lookup which matches at the specified position.
+// Returns the position of the next glyph to process and whether any subtable was applied.
+func (s *otShaper) applyAt(t *otTable, l *otLookup, i int) (int, bool) {
+
+	for _, sub := range l.subtables {
+		var next int
+		var ok bool
+		if t.gpos {
+			next, ok = s.applyPos(t, l, sub, i)
+		} else {
+			next, ok = s.applySubst(t, l, sub, i)
+		}
+		if ok {
+			return next, true
+		}
+	}
+	return i + 1, false
+}
+
+// applySubst applies the specified GSUB subtable at the specified position of the buffer
+func (s *otShaper) applySubst(t *otTable, l *otLookup, sub, i int) (int, bool) {
+
+	d := t.data
+	g := &s.buf[i]
+	format := u16(d, sub)
+	switch l.kind {
+	case 1: // Single substitution
+		cov := otCoverage(d, sub+u16(d, sub+2), g.index)
+		if cov < 0 {
+			return 0, false
+		}
+		if format == 1 {
+			s.setGlyph(i, uint16(int(g.index)+i16(d, sub+4)))
+		} else {
+			s.setGlyph(i, uint16(u16(d, sub+6+2*cov)))
+		}
+		return i + 1, true
+
+	case 2: // Multiple substitution
+		cov := otCoverage(d, sub+u16(d, sub+2), g.index)
+		if cov < 0 || cov >= u16(d, sub+4) {
+			return 0, false
+		}
+		seq := sub + u16(d, sub+6+2*cov)
+		count := u16(d, seq)
+		orig := *g
+		s.buf = append(s.buf[:i], append(make([]otGlyph, count), s.buf[i+1:]...)...)
+		for j := 0; j < count; j++ {
+			s.buf[i+j] = orig
+			s.setGlyph(i+j, uint16(u16(d, seq+2+2*j)))
+		}
+		return i + count, true
+
+	case 4: // Ligature substitution
+		cov := otCoverage(d, sub+u16(d, sub+2), g.index)
+		if cov < 0 || cov >= u16(d, sub+4) {
+			return 0, false
+		}
+		set := sub + u16(d, sub+6+2*cov)
+		for k := 0; k < u16(d, set); k++ {
+			lig := set + u16(d, set+2+2*k)
+			comps := u16(d, lig+2)
+			positions := []int{i}
+			for c, j := 1, i; c < comps; c++ {
+				j = s.next(l, j)
+				if j < 0 || int(s.buf[j].index) != u16(d, lig+4+2*(c-1)) {
+					positions = nil
+					break
+				}
+				positions = append(positions, j)
+			}
+			if positions == nil {
+				continue
+			}
+			last := positions[len(positions)-1]
+			for j := i + 1; j <= last; j++ {
+				s.buf[j].cluster = g.cluster
+			}
+			s.setGlyph(i, uint16(u16(d, lig)))
+			if s.gdef == nil || s.buf[i].class == 0 {
+				s.buf[i].class = otClassLigature
+			}
+			for c := len(positions) - 1; c > 0; c-- {
+				p := positions[c]
+				s.buf = append(s.buf[:p], s.buf[p+1:]...)
+			}
+			return i + 1, true
+		}
+		return 0, false
+
+	case 5, 6: // Contextual and chained contextual substitution
+		return s.applyContext(t, l, sub, i)
+	}
+	return 0, false
+}
+
+// applyPos applies the specified GPOS subtable at the specified position of the buffer
+func (s *otShaper) applyPos(t *otTable, l *otLookup, sub, i int) (int, bool) {
+
+	d := t.data
+	g := &s.buf[i]
+	format := u16(d, sub)
+	switch l.kind {
+	case 1: // Single adjustment
+		cov := otCoverage(d, sub+u16(d, sub+2), g.index)
+		if cov < 0 {
+			return 0, false
+		}
+		vf := u16(d, sub+4)
+		if format == 1 {
+			s.applyValue(d, sub, sub+6, vf, i)
+		} else {
+			s.applyValue(d, sub, sub+8+cov*otValueSize(vf), vf, i)
+		}
+		return i + 1, true
+
+	case 2: // Pair adjustment
+		cov := otCoverage(d, sub+u16(d, sub+2), g.index)
+		if cov < 0 {
+			return 0, false
+		}
+		j := s.next(l, i)
+		if j < 0 {
+			return 0, false
+		}
+		vf1 := u16(d, sub+4)
+		vf2 := u16(d, sub+6)
+		size1 := otValueSize(vf1)
+		size2 := otValueSize(vf2)
+		second := int(s.buf[j].index)
+		rec := -1
+		if format == 1 {
+			if cov >= u16(d, sub+8) {
+				return 0, false
+			}
+			set := sub + u16(d, sub+10+2*cov)
+			recSize := 2 + size1 + size2
+			count := u16(d, set)
+			k := sort.Search(count, func(k int) bool { return u16(d, set+2+k*recSize) >= second })
+			if k < count && u16(d, set+2+k*recSize) == second {
+				rec = set + 2 + k*recSize + 2
+				s.applyValue(d, set, rec, vf1, i)
+				s.applyValue(d, set, rec+size1, vf2, j)
+			}
+		} else {
+			c1 := otClass(d, sub+u16(d, sub+8), g.index)
+			c2 := otClass(d, sub+u16(d, sub+10), s.buf[j].index)
+			if c1 >= u16(d, sub+12) || c2 >= u16(d, sub+14) {
+				return 0, false
+			}
+			rec = sub + 16 + (c1*u16(d, sub+14)+c2)*(size1+size2)
+			s.applyValue(d, sub, rec, vf1, i)
+			s.applyValue(d, sub, rec+size1, vf2, j)
+		}
+		if rec < 0 {
+			return 0, false
+		}
+		if vf2 != 0 {
+			return j + 1, true
+		}
+		return j, true
+
+	case 4, 5, 6: // Mark to base, mark to ligature and mark to mark attachment
+		if g.class != otClassMark {
+			return 0, false
+		}
+		markCov := otCoverage(d, sub+u16(d, sub+2), g.index)
+		if markCov < 0 {
+			return 0, false
+		}
+		// Finds the glyph the mark is attached to
+		j := i - 1
+		if l.kind == 6 {
+			j = s.prev(l, i)
+			if j < 0 || s.buf[j].class != otClassMark {
+				return 0, false
+			}
+		} else {
+			for j >= 0 && s.buf[j].class == otClassMark {
+				j--
+			}
+		}
+		if j < 0 {
+			return 0, false
+		}
+		baseCov := otCoverage(d, sub+u16(d, sub+4), s.buf[j].index)
+		if baseCov < 0 {
+			return 0, false
+		}
+		classCount := u16(d, sub+6)
+		markArray := sub + u16(d, sub+8)
+		class := u16(d, markArray+2+4*markCov)
+		markAnchor := markArray + u16(d, markArray+2+4*markCov+2)
+		baseArray := sub + u16(d, sub+10)
+		var baseAnchor int
+		if l.kind == 5 {
+			// Uses the anchor of the last component of the ligature
+			attach := baseArray + u16(d, baseArray+2+2*baseCov)
+			comps := u16(d, attach)
+			if comps == 0 {
+				return 0, false
+			}
+			off := u16(d, attach+2+2*((comps-1)*classCount+class))
+			if off == 0 {
+				return 0, false
+			}
+			baseAnchor = attach + off
+		} else {
+			off := u16(d, baseArray+2+2*(baseCov*classCount+class))
+			if off == 0 {
+				return 0, false
+			}
+			baseAnchor = baseArray + off
+		}
+		g.xOffset = i16(d, baseAnchor+2) - i16(d, markAnchor+2)
+		g.yOffset = i16(d, baseAnchor+4) - i16(d, markAnchor+4)
+		g.attach = j - i
+		return i + 1, true
+
+	case 7, 8: // Contextual and chained contextual positioning
+		return s.applyContext(t, l, sub, i)
+	}
+	return 0, false
+}
+
+// otValueSize returns the size in bytes of a value record with the specified format
+func otValueSize(format int) int {
+
+	size := 0
+	for ; format != 0; format >>= 1 {
+		size += 2 * (format & 1)
+	}
+	return size
+}
+
+// applyValue adds the value record at the specified offset to the glyph at the specified position.
+// Device tables are ignored.
+func (s *otShaper) applyValue(d []byte, base, rec, format, i int) {
+
+	g := &s.buf[i]
+	if format&0x01 != 0 {
+		g.xOffset += i16(d, rec)
+		rec += 2
+	}
+	if format&0x02 != 0 {
+		g.yOffset += i16(d, rec)
+		rec += 2
+	}
+	if format&0x04 != 0 {
+		g.xAdvance += i16(d, rec)
+	}
+}
+
+// setGlyph replaces the glyph index at the specified position of the buffer, updating its class
+func (s *otShaper) setGlyph(i int, index uint16) {
+
+	g := &s.buf[i]
+	g.index = index
+	if s.gdef != nil && s.gdef.classDef != 0 {
+		g.class = s.gdef.glyphClass(index)
+	}
+}
+
+// applyContext applies the specified contextual or chained contextual subtable
+// at the specified position of the buffer.
+func (s *otShaper) applyContext(t *otTable, l *otLookup, sub, i int) (int, bool) {
+
+	d := t.data
+	g := &s.buf[i]
+	chained := l.kind == 6 || l.kind == 8
+	format := u16(d, sub)
+	byGlyph := func(off int, glyph uint16, value int) bool { return int(glyph) == value }
+
+	switch format {
+	case 1, 2: // Glyph and class based rule sets
+		cov := otCoverage(d, sub+u16(d, sub+2), g.index)
+		if cov < 0 {
+			return 0, false
+		}
+		var classDefs [3]int // Backtrack, input and lookahead class definitions
+		setIndex := cov
+		sets := sub + 6
+		match := byGlyph
+		if format == 2 {
+			if chained {
+				classDefs = [3]int{sub + u16(d, sub+4), sub + u16(d, sub+6), sub + u16(d, sub+8)}
+				sets = sub + 12
+			} else {
+				classDefs[1] = sub + u16(d, sub+4)
+				classDefs[0], classDefs[2] = classDefs[1], classDefs[1]
+				sets = sub + 8
+			}
+			setIndex = otClass(d, classDefs[1], g.index)
+			match = func(off int, glyph uint16, value int) bool { return otClass(d, off, glyph) == value }
+		}
+		if setIndex >= u16(d, sets-2) {
+			return 0, false
+		}
+		set := u16(d, sets+2*setIndex)
+		if set == 0 {
+			return 0, false
+		}
+		set += sub
+		for r := 0; r < u16(d, set); r++ {
+			rule := set + u16(d, set+2+2*r)
+			var seqs [3][]int
+			if chained {
+				pos := rule
+				for k := 0; k < 3; k++ {
+					count := u16(d, pos)
+					if k == 1 {
+						count-- // The first input glyph is covered by the coverage table
+					}
+					for c := 0; c < count; c++ {
+						seqs[k] = append(seqs[k], u16(d, pos+2+2*c))
+					}
+					pos += 2 + 2*count
+				}
+				if next, ok := s.matchContext(t, l, i, seqs, classDefs, match, pos, pos+2); ok {
+					return next, true
+				}
+			} else {
+				count := u16(d, rule)
+				for c := 1; c < count; c++ {
+					seqs[1] = append(seqs[1], u16(d, rule+4+2*(c-1)))
+				}
+				if next, ok := s.matchContext(t, l, i, seqs, classDefs, match, rule+2, rule+2+2*count); ok {
+					return next, true
+				}
+			}
+		}
+
+	case 3: // Coverage based
+		var seqs [3][]int
+		if chained {
+			pos := sub + 2
+			for k := 0; k < 3; k++ {
+				count := u16(d, pos)
+				for c := 0; c < count; c++ {
+					seqs[k] = append(seqs[k], sub+u16(d, pos+2+2*c))
+				}
+				pos += 2 + 2*count
+			}
+			return s.matchCoverageContext(t, l, i, seqs, pos, pos+2)
+		}
+		count := u16(d, sub+2)
+		for c := 0; c < count; c++ {
+			seqs[1] = append(seqs[1], sub+u16(d, sub+6+2*c))
+		}
+		return s.matchCoverageContext(t, l, i, seqs, sub+4, sub+6+2*count)
+	}
+	return 0, false
+}
+
+// matchCoverageContext matches the coverage sequences of a format 3 contextual subtable,
+// whose input sequence includes the first glyph, and applies its lookup records.
+func (s *otShaper) matchCoverageContext(t *otTable, l *otLookup, i int, seqs [3][]int, countPos, recPos int) (int, bool) {
+
+	if len(seqs[1]) == 0 || otCoverage(t.data, seqs[1][0], s.buf[i].index) < 0 {
+		return 0, false
+	}
+	match := func(off int, glyph uint16, value int) bool { return otCoverage(t.data, value, glyph) >= 0 }
+	seqs[1] = seqs[1][1:]
+	positions, ok := s.matchSequences(l, i, seqs, [3]int{}, match)
+	if !ok {
+		return 0, false
+	}
+	return s.applyRecords(t, positions, u16(t.data, countPos), recPos), true
+}
+
+// matchContext matches the sequences of a format 1 or 2 contextual rule
+// and applies its lookup records.
+func (s *otShaper) matchContext(t *otTable, l *otLookup, i int, seqs [3][]int, classDefs [3]int,
+	match func(off int, glyph uint16, value int) bool, countPos, recPos int) (int, bool) {
+
+	positions, ok := s.matchSequences(l, i, seqs, classDefs, match)
+	if !ok {
+		return 0, false
+	}
+	return s.applyRecords(t, positions, u16(t.data, countPos), recPos), true
+}
+
+// matchSequences checks if the glyphs before the specified position match the backtrack sequence,
+// the glyphs after it match the input sequence and the following glyphs match the lookahead sequence.
+// Returns the positions of the input glyphs including the first.
+func (s *otShaper) matchSequences(l *otLookup, i int, seqs [3][]int, classDefs [3]int,
+	match func(off int, glyph uint16, value int) bool) ([]int, bool) {
+
+	positions := []int{i}
+	j := i
+	for _, value := range seqs[1] {
+		j = s.next(l, j)
+		if j < 0 || !match(classDefs[1], s.buf[j].index, value) {
+			return nil, false
+		}
+		positions = append(positions, j)
+	}
+	for _, value := range seqs[2] {
+		j = s.next(l, j)
+		if j < 0 || !match(classDefs[2], s.buf[j].index, value) {
+			return nil, false
+		}
+	}
+	j = i
+	for _, value := range seqs[0] {
+		j = s.prev(l, j)
+		if j < 0 || !match(classDefs[0], s.buf[j].index, value) {
+			return nil, false
+		}
+	}
+	return positions, true
+}
+
+// applyRecords applies the specified number of sequence lookup records at the specified offset
+// to the input glyphs at the specified positions. Returns the position after the last input glyph.
+func (s *otShaper) applyRecords(t *otTable, positions []int, count, rec int) int {
+
+	for r := 0; r < count; r++ {
+		seqIndex := u16(t.data, rec+4*r)
+		lookup := u16(t.data, rec+4*r+2)
+		if seqIndex >= len(positions) || lookup >= len(t.lookups) {
+			continue
+		}
+		p := positions[seqIndex]
+		before := len(s.buf)
+		s.applyAt(t, &t.lookups[lookup], p)
+		// Shifts the following positions by the number of inserted or removed glyphs
+		if delta := len(s.buf) - before; delta != 0 {
+			for k := range positions {
+				if positions[k] > p {
+					positions[k] += delta
+				}
+			}
+		}
+	}
+	last := positions[len(positions)-1] + 1
+	if last > len(s.buf) {
+		last = len(s.buf)
+	}
+	return last
+}
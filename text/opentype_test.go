@@ -0,0 +1,52 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// Tests that all the subtables of an extension lookup are resolved to their extended subtables
+func TestParseOTTableExtension(t *testing.T) {
+
+	data := make([]byte, 52)
+	put16 := func(off, v int) { binary.BigEndian.PutUint16(data[off:], uint16(v)) }
+	// Header with empty script and feature lists and a lookup list with one lookup
+	put16(0, 1)
+	put16(4, 10)
+	put16(6, 12)
+	put16(8, 14)
+	put16(14, 1)
+	put16(16, 4)
+	// Extension lookup (GSUB type 7) with two subtables
+	put16(18, 7)
+	put16(22, 2)
+	put16(24, 10)
+	put16(26, 18)
+	// Extension subtables of single substitutions (type 1) with 32 bits offsets
+	for _, ext := range [][2]int{{28, 16}, {36, 12}} {
+		put16(ext[0], 1)
+		put16(ext[0]+2, 1)
+		binary.BigEndian.PutUint32(data[ext[0]+4:], uint32(ext[1]))
+	}
+
+	table := parseOTTable(data, false)
+	if len(table.lookups) != 1 {
+		t.Fatalf("lookups:%d", len(table.lookups))
+	}
+	l := table.lookups[0]
+	if l.kind != 1 || !reflect.DeepEqual(l.subtables, []int{44, 48}) {
+		t.Errorf("kind:%d subtables:%v", l.kind, l.subtables)
+	}
+
+	// GPOS extension lookups are type 9
+	put16(18, 9)
+	l = parseOTTable(data, true).lookups[0]
+	if l.kind != 1 || !reflect.DeepEqual(l.subtables, []int{44, 48}) {
+		t.Errorf("gpos kind:%d subtables:%v", l.kind, l.subtables)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"strings"
+
+	"github.com/g3n/engine/math32"
+)
+
+// OutlineOp is the operation of an outline segment.
+type OutlineOp int
+
+// The outline segment operations.
+const (
+	OutlineMoveTo = OutlineOp(iota) // Starts a new contour at Points[0]
+	OutlineLineTo                   // Straight line to Points[0]
+	OutlineQuadTo                   // Quadratic Bézier curve with control point Points[0] to Points[1]
+	OutlineCubeTo                   // Cubic Bézier curve with control points Points[0] and Points[1] to Points[2]
+)
+
+// points returns the number of points used by the operation.
+func (op OutlineOp) points() int {
+
+	switch op {
+	case OutlineQuadTo:
+		return 2
+	case OutlineCubeTo:
+		return 3
+	}
+	return 1
+}
+
+// OutlineSegment is a segment of the outline of a glyph.
+type OutlineSegment struct {
+	Op     OutlineOp         // Segment operation
+	Points [3]math32.Vector2 // Control points and end point, as used by the operation
+}
+
+// Outline returns the outlines of the glyphs of the specified text, which can contain line breaks (\n),
+// shaped as done by Shape. The coordinates are in ems (multiples of the font size) with the Y axis
+// pointing upwards and the origin at the left of the baseline of the first line. Each contour starts
+// with an OutlineMoveTo segment and is implicitly closed.
+func (f *Font) Outline(text string) []OutlineSegment {
+
+	f.updateFace()
+	ppem := f.ppem()
+	pixels := float32(ppem) / 64
+	metrics := f.face.Metrics()
+	lineHeight := float32(metrics.Ascent+metrics.Descent) / 64 * float32(f.attrib.LineSpacing)
+
+	var outline []OutlineSegment
+	for i, line := range strings.Split(text, "\n") {
+		glyphs, _ := f.Shape(line)
+		baseline := float32(i) * lineHeight
+		for _, g := range glyphs {
+			for _, seg := range g.Font.glyphOutline(g.Index, ppem) {
+				os := OutlineSegment{Op: seg.op}
+				for k, p := range seg.args[:seg.op.points()] {
+					os.Points[k].X = (g.X + g.DX + float32(p.X)/64) / pixels
+					os.Points[k].Y = -(baseline + g.DY + float32(p.Y)/64) / pixels
+				}
+				outline = append(outline, os)
+			}
+		}
+	}
+	return outline
+}
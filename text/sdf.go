@@ -0,0 +1,238 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+
+	"github.com/g3n/engine/math32"
+	"golang.org/x/image/math/fixed"
+)
+
+// sdfGlyph contains the signed distance field of a char before it is packed in the atlas image
+type sdfGlyph struct {
+	code   rune    // Char code
+	width  int     // Field width in pixels
+	height int     // Field height in pixels
+	field  []uint8 // Field values
+}
+
+// sdfOffset is the offset from a pixel to the nearest seed pixel of a distance transform
+type sdfOffset struct {
+	dx int
+	dy int
+}
+
+// sdfFar is the initial offset of pixels without a known nearest seed
+const sdfFar = 1 << 14
+
+// NewSDFAtlas returns a pointer to a new Atlas with the signed distance fields
+// of the specified chars drawn with the specified font size in pixels.
+// The distance fields are stored in the alpha channel of the image. The value is 0.5 at the
+// glyph edges and increases inside the glyphs, covering spread pixels at each side of the edges.
+// The chars can then be drawn sharply at any scale, with outlines and shadows up to the spread distance.
+// A font size of 32 to 64 pixels with a spread of 4 to 8 pixels gives good results.
+// The font attributes are restored after the atlas is built.
+func NewSDFAtlas(f *Font, first, last rune, size float64, spread int) *Atlas {
+
+	if spread < 1 {
+		spread = 1
+	}
+
+	// Renders the glyphs with the requested size in pixels
+	saved := f.attrib
+	defer f.SetAttributes(&saved)
+	f.SetPointSize(size)
+	f.SetDPI(72)
+	f.SetHinting(HintingNone)
+	f.updateFace()
+
+	a := new(Atlas)
+	a.Chars = make([]CharInfo, last+1)
+	a.First = first
+	a.Last = last
+	a.Size = float32(size)
+	a.SDF = true
+	a.Spread = spread
+	a.face = f.face
+	metrics := f.face.Metrics()
+	a.Height = int(metrics.Height >> 6)
+	a.Ascent = int(metrics.Ascent >> 6)
+	a.Descent = int(metrics.Descent >> 6)
+
+	// Calculates the distance field of each char
+	glyphs := make([]sdfGlyph, 0, last-first+1)
+	area := 0
+	maxWidth := 0
+	for code := first; code <= last; code++ {
+		dr, mask, maskp, advance, ok := f.face.Glyph(fixed.Point26_6{}, code)
+		if !ok {
+			continue
+		}
+		cinfo := &a.Chars[code]
+		cinfo.Advance = float32(advance) / 64
+		if dr.Empty() {
+			continue
+		}
+		width := dr.Dx() + 2*spread
+		height := dr.Dy() + 2*spread
+		cinfo.Width = width
+		cinfo.Height = height
+		cinfo.BearingX = float32(dr.Min.X - spread)
+		cinfo.BearingY = float32(dr.Min.Y - spread)
+
+		// Pixels with coverage of at least half are inside the glyph
+		inside := make([]bool, width*height)
+		for y := 0; y < dr.Dy(); y++ {
+			for x := 0; x < dr.Dx(); x++ {
+				var cover uint8
+				if alpha, ok := mask.(*image.Alpha); ok {
+					cover = alpha.AlphaAt(maskp.X+x, maskp.Y+y).A
+				} else {
+					_, _, _, ca := mask.At(maskp.X+x, maskp.Y+y).RGBA()
+					cover = uint8(ca >> 8)
+				}
+				inside[(y+spread)*width+x+spread] = cover >= 0x80
+			}
+		}
+		glyphs = append(glyphs, sdfGlyph{code, width, height, signedDistance(inside, width, height, spread)})
+		area += (width + 1) * (height + 1)
+		if width > maxWidth {
+			maxWidth = width
+		}
+	}
+
+	// Packs the fields in rows of an image with power of two width
+	width := 64
+	for width < maxWidth || float32(width*width) < float32(area)*1.2 {
+		width *= 2
+	}
+	x, y, rowHeight := 0, 0, 0
+	for _, g := range glyphs {
+		if x+g.width > width {
+			x = 0
+			y += rowHeight + 1
+			rowHeight = 0
+		}
+		cinfo := &a.Chars[g.code]
+		cinfo.X = x
+		cinfo.Y = y
+		x += g.width + 1
+		if g.height > rowHeight {
+			rowHeight = g.height
+		}
+	}
+	height := y + rowHeight
+	if height == 0 {
+		height = 1
+	}
+
+	// Draws the fields in the alpha channel of a white image
+	a.Image = image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := 0; i < len(a.Image.Pix); i += 4 {
+		a.Image.Pix[i] = 0xFF
+		a.Image.Pix[i+1] = 0xFF
+		a.Image.Pix[i+2] = 0xFF
+	}
+	for _, g := range glyphs {
+		cinfo := &a.Chars[g.code]
+		for gy := 0; gy < g.height; gy++ {
+			for gx := 0; gx < g.width; gx++ {
+				a.Image.Pix[a.Image.PixOffset(cinfo.X+gx, cinfo.Y+gy)+3] = g.field[gy*g.width+gx]
+			}
+		}
+	}
+
+	// Calculates normalized char positions in the image
+	fWidth := float32(width)
+	fHeight := float32(height)
+	for i := range a.Chars {
+		char := &a.Chars[i]
+		char.OffsetX = float32(char.X) / fWidth
+		char.OffsetY = float32(char.Y) / fHeight
+		char.RepeatX = float32(char.Width) / fWidth
+		char.RepeatY = float32(char.Height) / fHeight
+	}
+	return a
+}
+
+// signedDistance returns the signed distance field of the specified glyph bitmap
+// normalized so the edges are at 0.5 and the values cover spread pixels at each side.
+func signedDistance(inside []bool, width, height, spread int) []uint8 {
+
+	distIn := distanceTransform(inside, width, height, true)
+	distOut := distanceTransform(inside, width, height, false)
+	field := make([]uint8, width*height)
+	for i := range field {
+		// The edge is half a pixel away from the centers of the pixels at both sides
+		var d float32
+		if inside[i] {
+			d = distOut[i] - 0.5
+		} else {
+			d = 0.5 - distIn[i]
+		}
+		v := math32.Clamp(0.5+d/float32(2*spread), 0, 1)
+		field[i] = uint8(v*255 + 0.5)
+	}
+	return field
+}
+
+// distanceTransform returns the euclidean distance of each pixel to the nearest pixel
+// whose inside state is the specified seed state, using the 8-point sequential
+// signed euclidean distance transform (8SSEDT).
+func distanceTransform(inside []bool, width, height int, seed bool) []float32 {
+
+	grid := make([]sdfOffset, width*height)
+	for i := range grid {
+		if inside[i] != seed {
+			grid[i] = sdfOffset{sdfFar, sdfFar}
+		}
+	}
+	compare := func(p *sdfOffset, x, y, ox, oy int) {
+		other := sdfOffset{sdfFar, sdfFar}
+		if x+ox >= 0 && x+ox < width && y+oy >= 0 && y+oy < height {
+			other = grid[(y+oy)*width+x+ox]
+		}
+		other.dx += ox
+		other.dy += oy
+		if other.dx*other.dx+other.dy*other.dy < p.dx*p.dx+p.dy*p.dy {
+			*p = other
+		}
+	}
+
+	// First pass from top to bottom
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := &grid[y*width+x]
+			compare(p, x, y, -1, 0)
+			compare(p, x, y, 0, -1)
+			compare(p, x, y, -1, -1)
+			compare(p, x, y, 1, -1)
+		}
+		for x := width - 1; x >= 0; x-- {
+			compare(&grid[y*width+x], x, y, 1, 0)
+		}
+	}
+
+	// Second pass from bottom to top
+	for y := height - 1; y >= 0; y-- {
+		for x := width - 1; x >= 0; x-- {
+			p := &grid[y*width+x]
+			compare(p, x, y, 1, 0)
+			compare(p, x, y, 0, 1)
+			compare(p, x, y, -1, 1)
+			compare(p, x, y, 1, 1)
+		}
+		for x := 0; x < width; x++ {
+			compare(&grid[y*width+x], x, y, -1, 0)
+		}
+	}
+
+	dist := make([]float32, width*height)
+	for i, p := range grid {
+		dist[i] = math32.Sqrt(float32(p.dx*p.dx + p.dy*p.dy))
+	}
+	return dist
+}
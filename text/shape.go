@@ -0,0 +1,890 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package text
+
+import (
+	"image"
+	"image/draw"
+	"math"
+	"sort"
+	"unicode"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/image/vector"
+)
+
+// ShapedGlyph is a glyph of a line of text positioned by Font.Shape
+type ShapedGlyph struct {
+	Font    *Font   // Font of the glyph: the shaped font or one of its fallback fonts
+	Index   uint16  // Glyph index in the font
+	Cluster int     // Byte offset in the line of the first char of the glyph cluster
+	X       float32 // Pen position of the glyph in pixels from the start of the line
+	Advance float32 // Horizontal advance in pixels
+	DX      float32 // Horizontal placement offset in pixels
+	DY      float32 // Vertical placement offset in pixels (increasing downwards)
+	RTL     bool    // Whether the glyph belongs to a right to left run
+}
+
+// otScript contains the OpenType script tags and shaper of a writing system
+type otScript struct {
+	tags   []string // OpenType script tags in order of preference
+	shaper int      // Script specific shaper
+}
+
+// Script specific shapers
+const (
+	shaperDefault = iota
+	shaperArabic
+	shaperIndic
+)
+
+var (
+	scriptLatin      = &otScript{[]string{"latn"}, shaperDefault}
+	scriptGreek      = &otScript{[]string{"grek"}, shaperDefault}
+	scriptCyrillic   = &otScript{[]string{"cyrl"}, shaperDefault}
+	scriptArabic     = &otScript{[]string{"arab"}, shaperArabic}
+	scriptHebrew     = &otScript{[]string{"hebr"}, shaperDefault}
+	scriptDevanagari = &otScript{[]string{"dev2", "deva"}, shaperIndic}
+	scriptHan        = &otScript{[]string{"hani"}, shaperDefault}
+	scriptKana       = &otScript{[]string{"kana"}, shaperDefault}
+	scriptHangul     = &otScript{[]string{"hang"}, shaperDefault}
+	scriptThai       = &otScript{[]string{"thai"}, shaperDefault}
+	scriptOther      = &otScript{nil, shaperDefault}
+)
+
+// Feature masks of the glyphs of a shaping buffer
+const (
+	maskGlobal uint32 = 1 << iota
+	maskIsol
+	maskFina
+	maskMedi
+	maskInit
+	maskRphf
+	maskHalf
+)
+
+// Shaper specific glyph flags
+const (
+	flagHidden = 1 << iota // Default ignorable char drawn as an empty glyph
+	flagReph               // Part of an Indic reph
+)
+
+// otStageFeature is a feature applied in a shaping stage to the glyphs with its mask
+type otStageFeature struct {
+	tag  string
+	mask uint32
+}
+
+// Features applied by each shaper, in stages. The lookups of the features of a stage
+// are applied together in lookup order.
+var (
+	gsubDefault = [][]otStageFeature{
+		{{"ccmp", maskGlobal}, {"locl", maskGlobal}, {"rlig", maskGlobal}, {"calt", maskGlobal},
+			{"clig", maskGlobal}, {"liga", maskGlobal}, {"rclt", maskGlobal}},
+	}
+	gsubArabic = [][]otStageFeature{
+		{{"ccmp", maskGlobal}, {"locl", maskGlobal}},
+		{{"isol", maskIsol}},
+		{{"fina", maskFina}},
+		{{"medi", maskMedi}},
+		{{"init", maskInit}},
+		{{"rlig", maskGlobal}},
+		{{"calt", maskGlobal}},
+		{{"liga", maskGlobal}, {"clig", maskGlobal}, {"mset", maskGlobal}},
+	}
+	gsubIndic = [][]otStageFeature{
+		{{"locl", maskGlobal}, {"ccmp", maskGlobal}},
+		{{"nukt", maskGlobal}},
+		{{"akhn", maskGlobal}},
+		{{"rphf", maskRphf}},
+		{{"rkrf", maskGlobal}},
+		{{"blwf", maskGlobal}},
+		{{"half", maskHalf}},
+		{{"pstf", maskGlobal}},
+		{{"vatu", maskGlobal}},
+		{{"cjct", maskGlobal}},
+		{{"pres", maskGlobal}, {"abvs", maskGlobal}, {"blws", maskGlobal}, {"psts", maskGlobal},
+			{"haln", maskGlobal}, {"calt", maskGlobal}, {"clig", maskGlobal}, {"liga", maskGlobal}},
+	}
+	gposFeatures = [][]otStageFeature{
+		{{"kern", maskGlobal}, {"mark", maskGlobal}, {"mkmk", maskGlobal}, {"dist", maskGlobal},
+			{"abvm", maskGlobal}, {"blwm", maskGlobal}},
+	}
+)
+
+// glyphKey identifies a rasterized glyph
+type glyphKey struct {
+	index uint16
+	ppem  fixed.Int26_6
+}
+
+// glyphMask is a rasterized glyph
+type glyphMask struct {
+	mask   *image.Alpha // Glyph coverage
+	offset image.Point  // Position of the mask relative to the glyph origin
+}
+
+// Maximum number of rasterized glyphs cached by a font
+const glyphCacheSize = 2048
+
+// shapeItem is a sequence of chars of a bidi run drawn with the same font and script
+type shapeItem struct {
+	start  int       // Byte offset of the first char
+	end    int       // Byte offset after the last char
+	font   *Font     // Font with the glyphs of the chars
+	script *otScript // Script of the chars
+}
+
+// SetFallbacks sets the fonts used, in order, to draw the chars of the text which are not in
+// this font, such as CJK ideographs or emoji. The fallback fonts are drawn with the attributes
+// and colors of this font. Only outline glyphs are supported, so color emoji fonts are not.
+func (f *Font) SetFallbacks(fonts ...*Font) {
+
+	f.fallbacks = fonts
+}
+
+// Fallbacks returns the fallback fonts of this font
+func (f *Font) Fallbacks() []*Font {
+
+	return f.fallbacks
+}
+
+// Shape converts the specified line of text to positioned glyphs in visual order, from left to right.
+// The chars are reordered with the Unicode Bidirectional Algorithm, drawn with this font or with
+// the first of its fallback fonts which contains them, and shaped with the OpenType GSUB and GPOS
+// features of the font for their script, which select ligatures, the contextual forms of Arabic
+// letters, Indic conjuncts, kerning and mark positions.
+// Returns the glyphs and the width of the line in pixels.
+func (f *Font) Shape(line string) ([]ShapedGlyph, float32) {
+
+	f.updateFace()
+	ppem := f.ppem()
+	var glyphs []ShapedGlyph
+	var pen float32
+	for _, run := range BidiRuns(line) {
+		items := f.itemize(line, run)
+		rtl := run.RTL()
+		if rtl {
+			for a, b := 0, len(items)-1; a < b; a, b = a+1, b-1 {
+				items[a], items[b] = items[b], items[a]
+			}
+		}
+		for _, item := range items {
+			buf := item.font.shape(line, item, rtl)
+			scale := float32(ppem) / 64 / float32(item.font.ttf.FUnitsPerEm())
+			for k := range buf {
+				g := &buf[k]
+				if rtl {
+					g = &buf[len(buf)-1-k]
+				}
+				sg := ShapedGlyph{
+					Font:    item.font,
+					Index:   g.index,
+					Cluster: g.cluster,
+					X:       pen,
+					Advance: float32(g.xAdvance) * scale,
+					DX:      float32(g.xOffset) * scale,
+					DY:      -float32(g.yOffset) * scale,
+					RTL:     rtl,
+				}
+				glyphs = append(glyphs, sg)
+				pen += sg.Advance
+			}
+		}
+	}
+	return glyphs, pen
+}
+
+// CaretX returns the horizontal position in pixels from the start of the line of text of the
+// caret placed before the char at the specified byte offset, which should be at a char boundary.
+// An offset equal to the length of the line places the caret after the last char.
+func (f *Font) CaretX(line string, offset int) float32 {
+
+	glyphs, _ := f.Shape(line)
+	return caretX(line, glyphs, offset)
+}
+
+// CaretOffset returns the byte offset of the char boundary of the line of text
+// whose caret is nearest to the specified horizontal position in pixels.
+func (f *Font) CaretOffset(line string, x float32) int {
+
+	glyphs, _ := f.Shape(line)
+	best := 0
+	bestDist := float32(math.MaxFloat32)
+	for pos := 0; ; pos = graphemeNext(line, pos) {
+		dist := caretX(line, glyphs, pos) - x
+		if dist < 0 {
+			dist = -dist
+		}
+		if dist < bestDist {
+			best = pos
+			bestDist = dist
+		}
+		if pos >= len(line) {
+			break
+		}
+	}
+	return best
+}
+
+// caretX returns the horizontal position of the caret placed before the char
+// at the specified byte offset of the line with the specified shaped glyphs
+func caretX(line string, glyphs []ShapedGlyph, offset int) float32 {
+
+	if len(glyphs) == 0 {
+		return 0
+	}
+	var starts []int
+	for _, g := range glyphs {
+		starts = append(starts, g.Cluster)
+	}
+	sort.Ints(starts)
+
+	// Finds the cluster which contains the offset and its extent
+	after := offset >= len(line)
+	i := sort.SearchInts(starts, offset+1) - 1
+	if i < 0 {
+		i = 0
+	}
+	cluster := starts[i]
+	end := len(line)
+	if k := sort.SearchInts(starts, cluster+1); k < len(starts) {
+		end = starts[k]
+	}
+	x0 := float32(math.MaxFloat32)
+	x1 := -x0
+	rtl := false
+	for _, g := range glyphs {
+		if g.Cluster == cluster {
+			if g.X < x0 {
+				x0 = g.X
+			}
+			if g.X+g.Advance > x1 {
+				x1 = g.X + g.Advance
+			}
+			rtl = g.RTL
+		}
+	}
+
+	// The caret at the end of the line is after the last cluster
+	var frac float32
+	if after {
+		frac = 1
+	} else if offset > cluster && end > cluster {
+		frac = float32(StrCount(line[cluster:offset])) / float32(StrCount(line[cluster:end]))
+	}
+	if rtl {
+		return x1 - frac*(x1-x0)
+	}
+	return x0 + frac*(x1-x0)
+}
+
+// ppem returns the size of the font em square in pixels
+func (f *Font) ppem() fixed.Int26_6 {
+
+	return fixed.Int26_6(f.attrib.PointSize*f.attrib.DPI/72*64 + 0.5)
+}
+
+// glyphIndex returns the index of the glyph of the specified rune, or 0 if the font does not contain it
+func (f *Font) glyphIndex(r rune) uint16 {
+
+	return uint16(f.ttf.Index(r))
+}
+
+// fontFor returns this font if it contains the specified rune, otherwise the first fallback
+// font which contains it, or this font if none does
+func (f *Font) fontFor(r rune) *Font {
+
+	if f.glyphIndex(r) != 0 {
+		return f
+	}
+	for _, fb := range f.fallbacks {
+		if fb.glyphIndex(r) != 0 {
+			return fb
+		}
+	}
+	return f
+}
+
+// itemize splits the specified bidi run of the line in items with the same font and script,
+// in logical order. Marks and other chars of no specific script belong to the item of the previous char.
+func (f *Font) itemize(line string, run BidiRun) []shapeItem {
+
+	var items []shapeItem
+	for i, r := range line[run.Start:run.End] {
+		pos := run.Start + i
+		script := scriptOf(r)
+		if len(items) == 0 {
+			items = append(items, shapeItem{start: pos, font: f.fontFor(r), script: script})
+			continue
+		}
+		cur := &items[len(items)-1]
+		fnt := cur.font
+		if !unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf, unicode.Variation_Selector) {
+			fnt = f.fontFor(r)
+		}
+		if fnt == cur.font && (script == nil || cur.script == nil || script == cur.script) {
+			if cur.script == nil {
+				cur.script = script
+			}
+			continue
+		}
+		items = append(items, shapeItem{start: pos, font: fnt, script: script})
+	}
+	for i := range items {
+		if i+1 < len(items) {
+			items[i].end = items[i+1].start
+		} else {
+			items[i].end = run.End
+		}
+		if items[i].script == nil {
+			if i > 0 {
+				items[i].script = items[i-1].script
+			} else {
+				items[i].script = scriptOther
+			}
+		}
+	}
+	return items
+}
+
+// scriptOf returns the script of the specified rune, or nil if it is common to several scripts
+func scriptOf(r rune) *otScript {
+
+	switch {
+	case r < 0x80:
+		if r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' {
+			return scriptLatin
+		}
+		return nil
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return nil
+	case unicode.Is(unicode.Latin, r):
+		return scriptLatin
+	case unicode.Is(unicode.Arabic, r):
+		if unicode.In(r, unicode.Nd, unicode.Po) {
+			return nil
+		}
+		return scriptArabic
+	case unicode.Is(unicode.Hebrew, r):
+		return scriptHebrew
+	case unicode.Is(unicode.Devanagari, r):
+		return scriptDevanagari
+	case unicode.Is(unicode.Greek, r):
+		return scriptGreek
+	case unicode.Is(unicode.Cyrillic, r):
+		return scriptCyrillic
+	case unicode.Is(unicode.Han, r):
+		return scriptHan
+	case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+		return scriptKana
+	case unicode.Is(unicode.Hangul, r):
+		return scriptHangul
+	case unicode.Is(unicode.Thai, r):
+		return scriptThai
+	case unicode.IsLetter(r):
+		return scriptOther
+	}
+	return nil
+}
+
+// shape shapes the chars of the specified item of the line with this font.
+// Returns the glyphs in logical order with their advances and offsets in font units.
+func (f *Font) shape(line string, item shapeItem, rtl bool) []otGlyph {
+
+	s := &otShaper{gdef: f.gdef}
+	space := f.glyphIndex(' ')
+	for i, r := range line[item.start:item.end] {
+		g := otGlyph{char: r, cluster: item.start + i, mask: maskGlobal}
+		if rtl {
+			r = BidiMirror(r)
+		}
+		g.index = f.glyphIndex(r)
+		if g.index == 0 && isDefaultIgnorable(r) {
+			g.index = space
+			g.flags |= flagHidden
+		}
+		if f.gdef != nil && f.gdef.classDef != 0 {
+			g.class = f.gdef.glyphClass(g.index)
+		} else if unicode.In(r, unicode.Mn, unicode.Me) {
+			g.class = otClassMark
+		} else {
+			g.class = otClassBase
+		}
+		s.buf = append(s.buf, g)
+	}
+
+	// Substitutions
+	stages := gsubDefault
+	switch item.script.shaper {
+	case shaperArabic:
+		s.setupArabic()
+		stages = gsubArabic
+	case shaperIndic:
+		s.setupIndic()
+		stages = gsubIndic
+	}
+	scripts := append(append([]string{}, item.script.tags...), "DFLT", "latn")
+	if f.gsub != nil {
+		s.applyFeatures(f.gsub, f.gsub.langSys(scripts), stages)
+	}
+	if item.script.shaper == shaperIndic {
+		s.reorderReph()
+	}
+
+	// Advances in font units, which are the metrics scaled to an em of the number of units per em
+	unitsPerEm := fixed.Int26_6(f.ttf.FUnitsPerEm())
+	for i := range s.buf {
+		g := &s.buf[i]
+		if g.class == otClassMark || g.flags&flagHidden != 0 {
+			continue
+		}
+		g.xAdvance = int(f.ttf.HMetric(unitsPerEm, truetype.Index(g.index)).AdvanceWidth)
+	}
+
+	// Positioning, with the legacy kerning table if the font has no kerning feature
+	kerned := false
+	if f.gpos != nil {
+		langSys := f.gpos.langSys(scripts)
+		kerned = len(f.gpos.featureLookups(langSys, "kern")) > 0
+		s.applyFeatures(f.gpos, langSys, gposFeatures)
+	}
+	if !kerned {
+		for i := 0; i+1 < len(s.buf); i++ {
+			if s.buf[i].class == otClassMark || s.buf[i+1].class == otClassMark {
+				continue
+			}
+			s.buf[i].xAdvance += int(f.ttf.Kern(unitsPerEm, truetype.Index(s.buf[i].index), truetype.Index(s.buf[i+1].index)))
+		}
+	}
+	s.resolveAttachments(rtl)
+	return s.buf
+}
+
+// applyFeatures applies the lookups of the features of each stage present in the
+// specified language system of the table.
+func (s *otShaper) applyFeatures(t *otTable, langSys int, stages [][]otStageFeature) {
+
+	if langSys == 0 {
+		return
+	}
+	type stageLookup struct {
+		index int
+		mask  uint32
+	}
+	for _, stage := range stages {
+		var lookups []stageLookup
+		for _, feature := range stage {
+			for _, index := range t.featureLookups(langSys, feature.tag) {
+				found := false
+				for k := range lookups {
+					if lookups[k].index == index {
+						lookups[k].mask |= feature.mask
+						found = true
+					}
+				}
+				if !found {
+					lookups = append(lookups, stageLookup{index, feature.mask})
+				}
+			}
+		}
+		sort.Slice(lookups, func(a, b int) bool { return lookups[a].index < lookups[b].index })
+		for _, l := range lookups {
+			s.applyLookup(t, l.index, l.mask)
+		}
+	}
+}
+
+// resolveAttachments converts the anchor offsets of the attached marks to offsets from
+// their pen positions, which depend on the direction the glyphs are drawn.
+func (s *otShaper) resolveAttachments(rtl bool) {
+
+	for i := range s.buf {
+		g := &s.buf[i]
+		if g.attach == 0 {
+			continue
+		}
+		j := i + g.attach
+		g.xOffset += s.buf[j].xOffset
+		g.yOffset += s.buf[j].yOffset
+		if rtl {
+			for k := j + 1; k <= i; k++ {
+				g.xOffset += s.buf[k].xAdvance
+			}
+		} else {
+			for k := j; k < i; k++ {
+				g.xOffset -= s.buf[k].xAdvance
+			}
+		}
+	}
+}
+
+// setupArabic sets the masks of the initial, medial, final and isolated forms
+// of the Arabic letters from their joining types.
+func (s *otShaper) setupArabic() {
+
+	forms := make([]uint32, len(s.buf))
+	prev := -1
+	var prevType byte
+	for i := range s.buf {
+		jt := arabicJoining(s.buf[i].char)
+		if jt == 'T' {
+			continue
+		}
+		if prev >= 0 && (prevType == 'D' || prevType == 'C') && (jt == 'R' || jt == 'D' || jt == 'C') {
+			switch forms[prev] {
+			case maskIsol:
+				forms[prev] = maskInit
+			case maskFina:
+				forms[prev] = maskMedi
+			}
+			forms[i] = maskFina
+		} else {
+			forms[i] = maskIsol
+		}
+		prev, prevType = i, jt
+	}
+	for i := range s.buf {
+		if jt := arabicJoining(s.buf[i].char); jt == 'R' || jt == 'D' {
+			s.buf[i].mask |= forms[i]
+		}
+	}
+}
+
+// arabicJoining returns the joining type of the specified rune: right joining (R), dual joining (D),
+// join causing (C), transparent (T) or non joining (U).
+func arabicJoining(r rune) byte {
+
+	switch {
+	case r == 0x200D || r == 0x640:
+		return 'C'
+	case r == 0x622 || r == 0x623 || r == 0x624 || r == 0x625 || r == 0x627 || r == 0x629,
+		r >= 0x62F && r <= 0x632, r == 0x648, r >= 0x671 && r <= 0x673, r >= 0x675 && r <= 0x677,
+		r >= 0x688 && r <= 0x699, r == 0x6C0, r >= 0x6C3 && r <= 0x6CB, r == 0x6CD, r == 0x6CF,
+		r == 0x6D2 || r == 0x6D3 || r == 0x6D5 || r == 0x6EE || r == 0x6EF,
+		r >= 0x759 && r <= 0x75B, r == 0x76B || r == 0x76C || r == 0x771 || r == 0x773 || r == 0x774,
+		r == 0x778 || r == 0x779:
+		return 'R'
+	case r == 0x620 || r == 0x626 || r == 0x628, r >= 0x62A && r <= 0x62E, r >= 0x633 && r <= 0x63F,
+		r >= 0x641 && r <= 0x647, r == 0x649 || r == 0x64A || r == 0x66E || r == 0x66F,
+		r >= 0x678 && r <= 0x687, r >= 0x69A && r <= 0x6BF, r == 0x6C1 || r == 0x6C2 || r == 0x6CC || r == 0x6CE,
+		r == 0x6D0 || r == 0x6D1, r >= 0x6FA && r <= 0x6FC, r == 0x6FF, r >= 0x750 && r <= 0x77F,
+		r >= 0x8A0 && r <= 0x8AC:
+		return 'D'
+	case r != 0x200C && unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 'T'
+	}
+	return 'U'
+}
+
+// Devanagari char categories
+const (
+	indicOther = iota
+	indicConsonant
+	indicRa
+	indicVowel
+	indicHalant
+	indicNukta
+	indicPreMatra
+	indicMatra
+)
+
+// indicCategory returns the category of the specified Devanagari rune
+func indicCategory(r rune) int {
+
+	switch {
+	case r == 0x930:
+		return indicRa
+	case r >= 0x915 && r <= 0x939, r >= 0x958 && r <= 0x95F, r >= 0x978 && r <= 0x97F:
+		return indicConsonant
+	case r >= 0x904 && r <= 0x914, r == 0x960 || r == 0x961, r >= 0x972 && r <= 0x977:
+		return indicVowel
+	case r == 0x94D:
+		return indicHalant
+	case r == 0x93C:
+		return indicNukta
+	case r == 0x93F || r == 0x94E:
+		return indicPreMatra
+	case r >= 0x900 && r <= 0x903, r >= 0x93A && r <= 0x94F, r >= 0x951 && r <= 0x957, r == 0x962 || r == 0x963:
+		return indicMatra
+	}
+	return indicOther
+}
+
+// setupIndic finds the Devanagari syllables, moves the pre-base matras before the
+// consonants and sets the masks of the reph and half forms.
+// The base consonant of a syllable is always its last consonant.
+func (s *otShaper) setupIndic() {
+
+	isConsonant := func(i int) bool {
+		c := indicCategory(s.buf[i].char)
+		return c == indicConsonant || c == indicRa
+	}
+	category := func(i int) int { return indicCategory(s.buf[i].char) }
+	syllable := 0
+	n := len(s.buf)
+	for i := 0; i < n; {
+		if !isConsonant(i) && category(i) != indicVowel {
+			i++
+			continue
+		}
+		syllable++
+		start := i
+		base := i
+		j := i + 1
+		if isConsonant(i) {
+			if j < n && category(j) == indicNukta {
+				j++
+			}
+			for j+1 < n && category(j) == indicHalant && isConsonant(j+1) {
+				base = j + 1
+				j += 2
+				if j < n && category(j) == indicNukta {
+					j++
+				}
+			}
+		}
+		for j < n {
+			c := category(j)
+			if c != indicHalant && c != indicNukta && c != indicPreMatra && c != indicMatra {
+				break
+			}
+			j++
+		}
+		end := j
+		cluster := s.buf[start].cluster
+		for k := start; k < end; k++ {
+			s.buf[k].syllable = syllable
+			s.buf[k].cluster = cluster
+		}
+		if isConsonant(start) {
+			from := start
+			if category(start) == indicRa && start+1 < end && category(start+1) == indicHalant && base > start+1 {
+				for k := start; k < start+2; k++ {
+					s.buf[k].mask |= maskRphf
+					s.buf[k].flags |= flagReph
+				}
+				from = start + 2
+			}
+			for k := from; k < base; k++ {
+				s.buf[k].mask |= maskHalf
+			}
+			for k := base + 1; k < end; k++ {
+				if category(k) == indicPreMatra {
+					matra := s.buf[k]
+					copy(s.buf[from+1:k+1], s.buf[from:k])
+					s.buf[from] = matra
+				}
+			}
+		}
+		i = end
+	}
+}
+
+// reorderReph moves the reph glyphs formed by the rphf feature to the end of their syllables
+func (s *otShaper) reorderReph() {
+
+	for i := 0; i < len(s.buf); i++ {
+		g := s.buf[i]
+		if g.flags&flagReph == 0 || (i > 0 && s.buf[i-1].syllable == g.syllable) {
+			continue
+		}
+		// The reph was not formed if the halant is still a separate glyph
+		if i+1 < len(s.buf) && s.buf[i+1].flags&flagReph != 0 {
+			continue
+		}
+		end := i + 1
+		for end < len(s.buf) && s.buf[end].syllable == g.syllable {
+			end++
+		}
+		copy(s.buf[i:end-1], s.buf[i+1:end])
+		s.buf[end-1] = g
+		i = end - 1
+	}
+}
+
+// isDefaultIgnorable returns whether the specified rune is a format char which is not displayed
+func isDefaultIgnorable(r rune) bool {
+
+	return r == 0xAD || r == 0x34F || r == 0x61C || r >= 0x200B && r <= 0x200F || r >= 0x202A && r <= 0x202E ||
+		r >= 0x2060 && r <= 0x206F || r == 0xFEFF || unicode.Is(unicode.Variation_Selector, r)
+}
+
+// drawShaped draws the specified shaped glyphs of a line on the destination image
+// with the specified origin at the left of the baseline
+func (f *Font) drawShaped(dst draw.Image, glyphs []ShapedGlyph, x, y int) {
+
+	ppem := f.ppem()
+	for _, g := range glyphs {
+		m := g.Font.glyphMask(g.Index, ppem)
+		if m == nil {
+			continue
+		}
+		gx := x + int(math.Floor(float64(g.X+g.DX)+0.5)) + m.offset.X
+		gy := y + int(math.Floor(float64(g.DY)+0.5)) + m.offset.Y
+		dr := image.Rect(gx, gy, gx+m.mask.Rect.Dx(), gy+m.mask.Rect.Dy())
+		draw.DrawMask(dst, dr, f.fg, image.ZP, m.mask, image.ZP, draw.Over)
+	}
+}
+
+// glyphMask returns the rasterized glyph with the specified index and size,
+// or nil if the glyph is empty
+func (f *Font) glyphMask(index uint16, ppem fixed.Int26_6) *glyphMask {
+
+	key := glyphKey{index, ppem}
+	if m, ok := f.masks[key]; ok {
+		return m
+	}
+	if f.masks == nil || len(f.masks) >= glyphCacheSize {
+		f.masks = make(map[glyphKey]*glyphMask)
+	}
+	segments := f.glyphOutline(index, ppem)
+	if len(segments) == 0 {
+		f.masks[key] = nil
+		return nil
+	}
+
+	// The bounds of the control points contain the glyph outline
+	lo := fixed.Point26_6{X: math.MaxInt32, Y: math.MaxInt32}
+	hi := fixed.Point26_6{X: math.MinInt32, Y: math.MinInt32}
+	for _, seg := range segments {
+		for _, p := range seg.args[:seg.op.points()] {
+			if p.X < lo.X {
+				lo.X = p.X
+			}
+			if p.Y < lo.Y {
+				lo.Y = p.Y
+			}
+			if p.X > hi.X {
+				hi.X = p.X
+			}
+			if p.Y > hi.Y {
+				hi.Y = p.Y
+			}
+		}
+	}
+	x0, y0 := lo.X.Floor(), lo.Y.Floor()
+	width, height := hi.X.Ceil()-x0, hi.Y.Ceil()-y0
+	if width <= 0 || height <= 0 {
+		f.masks[key] = nil
+		return nil
+	}
+
+	r := vector.NewRasterizer(width, height)
+	r.DrawOp = draw.Src
+	px := func(v fixed.Int26_6) float32 { return float32(v)/64 - float32(x0) }
+	py := func(v fixed.Int26_6) float32 { return float32(v)/64 - float32(y0) }
+	for _, seg := range segments {
+		a := seg.args
+		switch seg.op {
+		case OutlineMoveTo:
+			r.MoveTo(px(a[0].X), py(a[0].Y))
+		case OutlineLineTo:
+			r.LineTo(px(a[0].X), py(a[0].Y))
+		case OutlineQuadTo:
+			r.QuadTo(px(a[0].X), py(a[0].Y), px(a[1].X), py(a[1].Y))
+		}
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	r.Draw(mask, mask.Bounds(), image.Opaque, image.ZP)
+	m := &glyphMask{mask, image.Point{x0, y0}}
+	f.masks[key] = m
+	return m
+}
+
+// glyphSegment is a segment of the outline of a glyph in 26.6 fixed point pixels
+// with the Y axis pointing downwards
+type glyphSegment struct {
+	op   OutlineOp
+	args [3]fixed.Point26_6
+}
+
+// glyphOutline returns the outline of the glyph with the specified index and size,
+// or nil if the glyph is empty or can't be loaded
+func (f *Font) glyphOutline(index uint16, ppem fixed.Int26_6) []glyphSegment {
+
+	err := f.glyph.Load(f.ttf, ppem, truetype.Index(index), font.HintingNone)
+	if err != nil {
+		return nil
+	}
+	var segments []glyphSegment
+	start := 0
+	for _, end := range f.glyph.Ends {
+		segments = appendContour(segments, f.glyph.Points[start:end])
+		start = end
+	}
+	return segments
+}
+
+// appendContour appends the segments of the specified TrueType contour, whose points are on
+// the curve or are the control points of quadratic curves, to the specified segments.
+// Between two consecutive control points there is an implicit point on the curve at their middle.
+func appendContour(segments []glyphSegment, points []truetype.Point) []glyphSegment {
+
+	n := len(points)
+	if n == 0 {
+		return segments
+	}
+	pt := func(i int) fixed.Point26_6 {
+		p := points[i%n]
+		return fixed.Point26_6{X: p.X, Y: -p.Y}
+	}
+	onCurve := func(i int) bool { return points[i%n].Flags&1 != 0 }
+	mid := func(a, b fixed.Point26_6) fixed.Point26_6 {
+		return fixed.Point26_6{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+	}
+
+	// Starts at the first point on the curve, or at the middle of the last
+	// and first points if all the points are control points
+	first := 0
+	for first < n && !onCurve(first) {
+		first++
+	}
+	var start fixed.Point26_6
+	if first == n {
+		first = -1
+		start = mid(pt(n-1), pt(0))
+	} else {
+		start = pt(first)
+	}
+	segments = append(segments, glyphSegment{op: OutlineMoveTo, args: [3]fixed.Point26_6{start}})
+	var ctrl fixed.Point26_6
+	hasCtrl := false
+	for i := first + 1; i < first+n; i++ {
+		p := pt(i + n)
+		if onCurve(i + n) {
+			if hasCtrl {
+				segments = append(segments, glyphSegment{op: OutlineQuadTo, args: [3]fixed.Point26_6{ctrl, p}})
+			} else {
+				segments = append(segments, glyphSegment{op: OutlineLineTo, args: [3]fixed.Point26_6{p}})
+			}
+			hasCtrl = false
+			continue
+		}
+		if hasCtrl {
+			segments = append(segments, glyphSegment{op: OutlineQuadTo, args: [3]fixed.Point26_6{ctrl, mid(ctrl, p)}})
+		}
+		ctrl = p
+		hasCtrl = true
+	}
+	// Closes the contour
+	if first < 0 {
+		// The last point was not visited
+		p := pt(n - 1)
+		if hasCtrl {
+			segments = append(segments, glyphSegment{op: OutlineQuadTo, args: [3]fixed.Point26_6{ctrl, mid(ctrl, p)}})
+		}
+		ctrl = p
+		hasCtrl = true
+	}
+	if hasCtrl {
+		segments = append(segments, glyphSegment{op: OutlineQuadTo, args: [3]fixed.Point26_6{ctrl, start}})
+	} else {
+		segments = append(segments, glyphSegment{op: OutlineLineTo, args: [3]fixed.Point26_6{start}})
+	}
+	return segments
+}
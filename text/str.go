@@ -4,47 +4,44 @@
 
 package text
 
-// StrCount returns the number of runes in the specified string
+// The functions in this file work with user perceived chars (extended grapheme clusters),
+// so a char followed by combining marks, an emoji sequence or an Indic conjunct
+// is counted, found, removed and inserted as a single char.
+
+// StrCount returns the number of chars in the specified string
 func StrCount(s string) int {
 
 	count := 0
-	for range s {
+	for pos := 0; pos < len(s); pos = graphemeNext(s, pos) {
 		count++
 	}
 	return count
 }
 
-// StrFind returns the start and length of the rune at the
+// StrFind returns the start and length in bytes of the char at the
 // specified position in the string
 func StrFind(s string, pos int) (start, length int) {
 
 	count := 0
-	for index := range s {
+	for index := 0; index < len(s); {
+		next := graphemeNext(s, index)
 		if count == pos {
-			start = index
-			count++
-			continue
-		}
-		if count == pos+1 {
-			length = index - start
-			break
+			return index, next - index
 		}
+		index = next
 		count++
 	}
-	if length == 0 {
-		length = len(s) - start
-	}
-	return start, length
+	return len(s), 0
 }
 
-// StrRemove removes the rune from the specified string and position
+// StrRemove removes the char from the specified string and position
 func StrRemove(s string, col int) string {
 
 	start, length := StrFind(s, col)
 	return s[:start] + s[start+length:]
 }
 
-// StrInsert inserts a string at the specified character position
+// StrInsert inserts a string at the specified char position
 func StrInsert(s, data string, col int) string {
 
 	start, _ := StrFind(s, col)
@@ -52,15 +49,9 @@ func StrInsert(s, data string, col int) string {
 }
 
 // StrPrefix returns the prefix of the specified string up to
-// the specified character position
+// the specified char position
 func StrPrefix(text string, pos int) string {
 
-	count := 0
-	for index := range text {
-		if count == pos {
-			return text[:index]
-		}
-		count++
-	}
-	return text
+	start, _ := StrFind(text, pos)
+	return text[:start]
 }
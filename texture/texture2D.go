@@ -16,6 +16,7 @@ import (
 	"os"
 
 	"github.com/g3n/engine/gls"
+	"github.com/g3n/engine/math32"
 )
 
 // Package logger
@@ -43,13 +44,16 @@ type Texture2D struct {
 	data         interface{} // array with texture data
 	uniUnit      gls.Uniform // Texture unit uniform location cache
 	uniInfo      gls.Uniform // Texture info uniform location cache
-	udata        struct {    // Combined uniform data in 3 vec2:
+	rotation     float32     // Rotation angle of the texture coordinates in radians
+	udata        struct {    // Combined uniform data in 4 vec2:
 		offsetX float32
 		offsetY float32
 		repeatX float32
 		repeatY float32
 		flipY   float32
 		visible float32
+		cos     float32 // Cosine of the rotation angle
+		sin     float32 // Sine of the rotation angle
 	}
 }
 
@@ -72,6 +76,7 @@ func newTexture2D() *Texture2D {
 	t.uniInfo.Init("MatTexinfo")
 	t.SetOffset(0, 0)
 	t.SetRepeat(1, 1)
+	t.SetRotation(0)
 	t.SetFlipY(true)
 	t.SetVisible(true)
 	return t
@@ -233,6 +238,12 @@ func (t *Texture2D) SetMagFilter(magFilter uint32) {
 	t.updateParams = true
 }
 
+// MagFilter returns the magnification filter.
+func (t *Texture2D) MagFilter() uint32 {
+
+	return t.magFilter
+}
+
 // SetMinFilter sets the filter to be applied when the texture element
 // covers less than on pixel. The default value is gls.Linear.
 func (t *Texture2D) SetMinFilter(minFilter uint32) {
@@ -241,6 +252,12 @@ func (t *Texture2D) SetMinFilter(minFilter uint32) {
 	t.updateParams = true
 }
 
+// MinFilter returns the minification filter.
+func (t *Texture2D) MinFilter() uint32 {
+
+	return t.minFilter
+}
+
 // SetWrapS set the wrapping mode for texture S coordinate
 // The default value is GL_CLAMP_TO_EDGE;
 func (t *Texture2D) SetWrapS(wrapS uint32) {
@@ -249,6 +266,12 @@ func (t *Texture2D) SetWrapS(wrapS uint32) {
 	t.updateParams = true
 }
 
+// WrapS returns the wrapping mode for texture S coordinate.
+func (t *Texture2D) WrapS() uint32 {
+
+	return t.wrapS
+}
+
 // SetWrapT set the wrapping mode for texture T coordinate
 // The default value is GL_CLAMP_TO_EDGE;
 func (t *Texture2D) SetWrapT(wrapT uint32) {
@@ -257,6 +280,12 @@ func (t *Texture2D) SetWrapT(wrapT uint32) {
 	t.updateParams = true
 }
 
+// WrapT returns the wrapping mode for texture T coordinate.
+func (t *Texture2D) WrapT() uint32 {
+
+	return t.wrapT
+}
+
 // SetRepeat set the repeat factor
 func (t *Texture2D) SetRepeat(x, y float32) {
 
@@ -283,6 +312,22 @@ func (t *Texture2D) Offset() (float32, float32) {
 	return t.udata.offsetX, t.udata.offsetY
 }
 
+// SetRotation sets the angle in radians by which the texture coordinates are rotated
+// around the origin after being repeated and before being offset, as the rotation
+// of the glTF KHR_texture_transform extension.
+func (t *Texture2D) SetRotation(angle float32) {
+
+	t.rotation = angle
+	t.udata.cos = math32.Cos(angle)
+	t.udata.sin = math32.Sin(angle)
+}
+
+// Rotation returns the current rotation angle of the texture coordinates in radians
+func (t *Texture2D) Rotation() float32 {
+
+	return t.rotation
+}
+
 // SetFlipY set the state for flipping the Y coordinate
 func (t *Texture2D) SetFlipY(state bool) {
 
@@ -311,6 +356,21 @@ func (t *Texture2D) Compressed() bool {
 	return t.compressed
 }
 
+// RGBA returns the image of the texture if its data is an uncompressed RGBA8 image,
+// such as the textures created from image files or image.RGBA objects, or nil otherwise.
+// The returned image shares the texture data.
+func (t *Texture2D) RGBA() *image.RGBA {
+
+	pix, ok := t.data.([]uint8)
+	if !ok || t.compressed || t.format != gls.RGBA || t.formatType != gls.UNSIGNED_BYTE {
+		return nil
+	}
+	if len(pix) < int(t.width)*int(t.height)*4 {
+		return nil
+	}
+	return &image.RGBA{Pix: pix, Stride: int(t.width) * 4, Rect: image.Rect(0, 0, int(t.width), int(t.height))}
+}
+
 // DecodeImage reads and decodes the specified image file into RGBA8.
 // The supported image files are PNG, JPEG and GIF.
 func DecodeImage(imgfile string) (*image.RGBA, error) {
@@ -401,7 +461,7 @@ func (t *Texture2D) RenderSetup(gs *gls.GLS, slotIdx, uniIdx int) { // Could hav
 	gs.Uniform1i(location, int32(slotIdx))
 
 	// Transfer texture info combined uniform
-	const vec2count = 3
+	const vec2count = 4
 	location = t.uniInfo.LocationIdx(gs, vec2count*int32(uniIdx))
 	gs.Uniform2fv(location, vec2count, &t.udata.offsetX)
 }
@@ -12,6 +12,9 @@ import (
 	"github.com/g3n/engine/gls"
 	"github.com/g3n/engine/util/wasm"
 	_ "image/png"
+	"math"
+	"strconv"
+	"strings"
 	"syscall/js"
 )
 
@@ -326,6 +329,20 @@ type WebGlCanvas struct {
 	sizeEv   SizeEvent
 	cursorEv CursorEvent
 	scrollEv ScrollEvent
+	compEv   CompositionEvent
+	touchEv  TouchEvent
+
+	// Cursor mode requested or CursorNormal if the browser released the pointer lock
+	cursorMode CursorMode
+
+	// Input method editor
+	ime       js.Value // Hidden text area which receives the text input
+	composing bool     // IME composition in progress
+
+	// Gamepads
+	gamepads    gamepadSet
+	padMappings map[int]*gamepadMapping // Mappings of the connected non standard gamepads
+	padHatAxes  map[int][]int           // Axes of the connected non standard gamepads which are hats
 
 	// Callbacks
 	onCtxMenu  js.Func
@@ -336,6 +353,15 @@ type WebGlCanvas struct {
 	mouseMove  js.Func
 	mouseWheel js.Func
 	winResize  js.Func
+	imeInput   js.Func
+	compStart  js.Func
+	compUpdate js.Func
+	compEnd    js.Func
+	touchStart js.Func
+	touchMove  js.Func
+	touchEnd   js.Func
+	touchCncl  js.Func
+	lockChange js.Func
 }
 
 // Init initializes the WebGlCanvas singleton.
@@ -385,6 +411,13 @@ func Init(canvasId string) error {
 	// Set up key down callback to dispatch event
 	w.keyDown = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		event := args[0]
+		if isComposing(event) {
+			return nil
+		}
+		// Keeps the browser from moving the focus out of the IME text area
+		if wasm.Equal(event.Get("target"), w.ime) && event.Get("code").String() == "Tab" {
+			event.Call("preventDefault")
+		}
 		eventCode := event.Get("code").String()
 		w.keyEv.Key = Key(keyMap[eventCode])
 		w.keyEv.Mods = getModifiers(event)
@@ -396,6 +429,9 @@ func Init(canvasId string) error {
 	// Set up key up callback to dispatch event
 	w.keyUp = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		event := args[0]
+		if isComposing(event) {
+			return nil
+		}
 		eventCode := event.Get("code").String()
 		w.keyEv.Key = Key(keyMap[eventCode])
 		w.keyEv.Mods = getModifiers(event)
@@ -407,6 +443,7 @@ func Init(canvasId string) error {
 	// Set up mouse down callback to dispatch event
 	w.mouseDown = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		event := args[0]
+		event.Call("preventDefault") // Keeps the focus on the IME text area
 		w.mouseEv.Button = MouseButton(event.Get("button").Int())
 		w.mouseEv.Xpos = float32(event.Get("offsetX").Int()) //* float32(w.scaleX) TODO
 		w.mouseEv.Ypos = float32(event.Get("offsetY").Int()) //* float32(w.scaleY)
@@ -431,8 +468,14 @@ func Init(canvasId string) error {
 	// Set up mouse move callback to dispatch event
 	w.mouseMove = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		event := args[0]
-		w.cursorEv.Xpos = float32(event.Get("offsetX").Float()) //* float32(w.scaleX) TODO
-		w.cursorEv.Ypos = float32(event.Get("offsetY").Float()) //* float32(w.scaleY)
+		if w.pointerLocked() {
+			// The cursor position does not change while the pointer is locked
+			w.cursorEv.Xpos += float32(event.Get("movementX").Float())
+			w.cursorEv.Ypos += float32(event.Get("movementY").Float())
+		} else {
+			w.cursorEv.Xpos = float32(event.Get("offsetX").Float()) //* float32(w.scaleX) TODO
+			w.cursorEv.Ypos = float32(event.Get("offsetY").Float()) //* float32(w.scaleY)
+		}
 		w.cursorEv.Mods = getModifiers(event)
 		w.Dispatch(OnCursor, &w.cursorEv)
 		return nil
@@ -451,6 +494,40 @@ func Init(canvasId string) error {
 	})
 	w.canvas.Call("addEventListener", "wheel", w.mouseWheel)
 
+	// Set up touch callbacks to dispatch events. The browser panning and zooming gestures
+	// are disabled on the canvas, but the taps are still reported as mouse events.
+	w.canvas.Get("style").Set("touchAction", "none")
+	w.touchStart = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.dispatchTouch(OnTouchStart, args[0])
+		return nil
+	})
+	w.canvas.Call("addEventListener", "touchstart", w.touchStart)
+	w.touchMove = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.dispatchTouch(OnTouchMove, args[0])
+		return nil
+	})
+	w.canvas.Call("addEventListener", "touchmove", w.touchMove)
+	w.touchEnd = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.dispatchTouch(OnTouchEnd, args[0])
+		return nil
+	})
+	w.canvas.Call("addEventListener", "touchend", w.touchEnd)
+	w.touchCncl = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.dispatchTouch(OnTouchCancel, args[0])
+		return nil
+	})
+	w.canvas.Call("addEventListener", "touchcancel", w.touchCncl)
+
+	// Set up pointer lock callback to update the cursor mode when the lock is released by the browser
+	w.lockChange = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if !w.pointerLocked() && w.cursorMode == CursorDisabled {
+			w.cursorMode = CursorNormal
+		}
+		return nil
+	})
+	doc.Call("addEventListener", "pointerlockchange", w.lockChange)
+	doc.Call("addEventListener", "pointerlockerror", w.lockChange)
+
 	// Set up window resize callback to dispatch event
 	w.winResize = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		w.sizeEv.Width = w.canvas.Get("width").Int()
@@ -464,12 +541,69 @@ func Init(canvasId string) error {
 	})
 	js.Global().Get("window").Call("addEventListener", "resize", w.winResize)
 
-	//// Set up char callback to dispatch event TODO
-	//w.SetCharModsCallback(func(x *glfw.Window, char rune, mods glfw.ModifierKey) {	//
-	//	w.charEv.Char = char
-	//	w.charEv.Mods = ModifierKey(mods)
-	//	w.Dispatch(OnChar, &w.charEv)
-	//})
+	// Create the hidden text area which receives the text input and the IME composition
+	// while enabled by SetIMEEnabled(). The browser shows the IME candidate window next to it.
+	w.ime = doc.Call("createElement", "textarea")
+	w.ime.Call("setAttribute", "autocomplete", "off")
+	w.ime.Call("setAttribute", "autocorrect", "off")
+	w.ime.Call("setAttribute", "autocapitalize", "off")
+	w.ime.Call("setAttribute", "spellcheck", "false")
+	style := w.ime.Get("style")
+	style.Set("position", "fixed")
+	style.Set("left", "0px")
+	style.Set("top", "0px")
+	style.Set("width", "1px")
+	style.Set("height", "1px")
+	style.Set("padding", "0")
+	style.Set("border", "0")
+	style.Set("outline", "none")
+	style.Set("resize", "none")
+	style.Set("opacity", "0")
+	style.Set("pointerEvents", "none")
+	doc.Get("body").Call("appendChild", w.ime)
+
+	// Set up text input callback to dispatch char events for the text which is not being composed
+	w.imeInput = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		event := args[0]
+		if w.composing || isComposing(event) || event.Get("inputType").String() != "insertText" {
+			return nil
+		}
+		w.dispatchChars(eventData(event))
+		w.ime.Set("value", "")
+		return nil
+	})
+	w.ime.Call("addEventListener", "input", w.imeInput)
+
+	// Set up composition start callback to dispatch event
+	w.compStart = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.composing = true
+		w.compEv.Text = ""
+		w.compEv.Cursor = 0
+		w.Dispatch(OnCompositionStart, &w.compEv)
+		return nil
+	})
+	w.ime.Call("addEventListener", "compositionstart", w.compStart)
+
+	// Set up composition update callback to dispatch event
+	w.compUpdate = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.compEv.Text = eventData(args[0])
+		w.compEv.Cursor = len(w.compEv.Text)
+		w.Dispatch(OnCompositionUpdate, &w.compEv)
+		return nil
+	})
+	w.ime.Call("addEventListener", "compositionupdate", w.compUpdate)
+
+	// Set up composition end callback to dispatch event and the committed chars
+	w.compEnd = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		w.composing = false
+		w.compEv.Text = eventData(args[0])
+		w.compEv.Cursor = len(w.compEv.Text)
+		w.Dispatch(OnCompositionEnd, &w.compEv)
+		w.dispatchChars(w.compEv.Text)
+		w.ime.Set("value", "")
+		return nil
+	})
+	w.ime.Call("addEventListener", "compositionend", w.compEnd)
 
 	win = w // Set singleton
 	return nil
@@ -498,6 +632,343 @@ func getModifiers(event js.Value) ModifierKey {
 	return mods
 }
 
+// isComposing returns whether the specified Javascript keyboard or input event is part of an IME composition.
+func isComposing(event js.Value) bool {
+
+	if event.Get("isComposing").Bool() {
+		return true
+	}
+	// Key events processed by the IME in older browsers
+	keyCode := event.Get("keyCode")
+	return keyCode.Type() == js.TypeNumber && keyCode.Int() == 229
+}
+
+// eventData returns the text data of a Javascript input or composition event.
+func eventData(event js.Value) string {
+
+	data := event.Get("data")
+	if data.Type() != js.TypeString {
+		return ""
+	}
+	return data.String()
+}
+
+// dispatchChars dispatches an OnChar event for each char of the specified text.
+func (w *WebGlCanvas) dispatchChars(text string) {
+
+	for _, r := range text {
+		w.charEv.Char = r
+		w.charEv.Mods = 0
+		w.Dispatch(OnChar, &w.charEv)
+	}
+}
+
+// dispatchTouch dispatches the specified touch event for the specified Javascript touch event.
+func (w *WebGlCanvas) dispatchTouch(evname string, event js.Value) {
+
+	rect := w.canvas.Call("getBoundingClientRect")
+	w.touchEv.Touches = touchList(w.touchEv.Touches[:0], event.Get("touches"), rect)
+	w.touchEv.Changed = touchList(w.touchEv.Changed[:0], event.Get("changedTouches"), rect)
+	w.touchEv.Mods = getModifiers(event)
+	w.Dispatch(evname, &w.touchEv)
+}
+
+// touchList appends to the specified slice the touch points of the specified Javascript
+// touch list with their positions relative to the specified canvas rectangle.
+func touchList(touches []Touch, list js.Value, rect js.Value) []Touch {
+
+	left := float32(rect.Get("left").Float())
+	top := float32(rect.Get("top").Float())
+	for i := 0; i < list.Length(); i++ {
+		t := list.Index(i)
+		touches = append(touches, Touch{
+			ID:   t.Get("identifier").Int(),
+			Xpos: float32(t.Get("clientX").Float()) - left,
+			Ypos: float32(t.Get("clientY").Float()) - top,
+		})
+	}
+	return touches
+}
+
+// PollEvents polls the state of the connected gamepads and dispatches their events.
+// The browser dispatches the other events as they happen.
+func (w *WebGlCanvas) PollEvents() {
+
+	navigator := js.Global().Get("navigator")
+	if navigator.Get("getGamepads").Type() != js.TypeFunction {
+		return
+	}
+	pads := navigator.Call("getGamepads")
+	count := pads.Get("length").Int()
+	for id := range w.gamepads.pads {
+		if id >= count {
+			w.gamepads.disconnect(w, id)
+		}
+	}
+	for i := 0; i < count; i++ {
+		pad := pads.Index(i)
+		if pad.Type() != js.TypeObject || !pad.Get("connected").Bool() {
+			w.gamepads.disconnect(w, i)
+			continue
+		}
+		standard := pad.Get("mapping").String() == "standard"
+		connecting := !w.gamepads.connected(i)
+		if connecting {
+			name := pad.Get("id").String()
+			var mapping *gamepadMapping
+			if !standard {
+				if vendor, product, ok := browserGamepadIds(name); ok {
+					mapping = gamepadMappings[gamepadIdsKey(vendor, product)]
+				}
+			}
+			if w.padMappings == nil {
+				w.padMappings = make(map[int]*gamepadMapping)
+			}
+			w.padMappings[i] = mapping
+			w.gamepads.connect(w, i, name, standard || mapping != nil)
+		}
+
+		// Raw buttons and axes
+		jsButtons := pad.Get("buttons")
+		buttons := make([]float32, jsButtons.Get("length").Int())
+		for b := range buttons {
+			button := jsButtons.Index(b)
+			buttons[b] = float32(button.Get("value").Float())
+			if button.Get("pressed").Bool() && buttons[b] < 1 {
+				buttons[b] = 1
+			}
+		}
+		jsAxes := pad.Get("axes")
+		axes := make([]float32, jsAxes.Get("length").Int())
+		for a := range axes {
+			axes[a] = float32(jsAxes.Index(a).Float())
+		}
+
+		var pressed []bool
+		switch mapping := w.padMappings[i]; {
+		case standard:
+			pressed, axes = standardGamepad(buttons, axes)
+		case mapping != nil:
+			// The hats are centered when the gamepad is connected
+			if connecting {
+				if w.padHatAxes == nil {
+					w.padHatAxes = make(map[int][]int)
+				}
+				w.padHatAxes[i] = mapping.browserHatAxes(axes)
+			}
+			pressed, axes = mapping.apply(buttons, axes, mapping.browserHats(buttons, axes, w.padHatAxes[i]))
+		default:
+			pressed = make([]bool, len(buttons))
+			for b, v := range buttons {
+				pressed[b] = v > 0.5
+			}
+		}
+		w.gamepads.update(w, i, pressed, axes)
+	}
+}
+
+// standardButtons contains the browser standard gamepad button of each standard gamepad button
+var standardButtons = [GamepadButtonLast + 1]int{
+	GamepadButtonA:           0,
+	GamepadButtonB:           1,
+	GamepadButtonX:           2,
+	GamepadButtonY:           3,
+	GamepadButtonLeftBumper:  4,
+	GamepadButtonRightBumper: 5,
+	GamepadButtonBack:        8,
+	GamepadButtonStart:       9,
+	GamepadButtonGuide:       16,
+	GamepadButtonLeftThumb:   10,
+	GamepadButtonRightThumb:  11,
+	GamepadButtonDpadUp:      12,
+	GamepadButtonDpadRight:   15,
+	GamepadButtonDpadDown:    13,
+	GamepadButtonDpadLeft:    14,
+}
+
+// standardGamepad returns the standard gamepad buttons and axes from the
+// buttons and axes of a browser gamepad with the "standard" mapping.
+func standardGamepad(rawButtons, rawAxes []float32) ([]bool, []float32) {
+
+	value := func(values []float32, i int) float32 {
+		if i < len(values) {
+			return values[i]
+		}
+		return 0
+	}
+	buttons := make([]bool, GamepadButtonLast+1)
+	for b, raw := range standardButtons {
+		buttons[b] = value(rawButtons, raw) > 0.5
+	}
+	axes := make([]float32, GamepadAxisLast+1)
+	for a := GamepadAxisLeftX; a <= GamepadAxisRightY; a++ {
+		axes[a] = value(rawAxes, int(a))
+	}
+	// Triggers are analog buttons
+	axes[GamepadAxisLeftTrigger] = value(rawButtons, 6)
+	axes[GamepadAxisRightTrigger] = value(rawButtons, 7)
+	return buttons, axes
+}
+
+// hatDirections contains the SDL hat direction bits (1 up, 2 right, 4 down and 8 left)
+// of the 8 directions of a hat clockwise from up
+var hatDirections = [8]int{1, 1 | 2, 2, 2 | 4, 4, 4 | 8, 8, 8 | 1}
+
+// hatCount returns the number of hats used by the mapping
+func (m *gamepadMapping) hatCount() int {
+
+	count := 0
+	for _, sources := range [][]gamepadSource{m.buttons[:], m.axes[:]} {
+		for _, src := range sources {
+			if src.kind == 'h' && src.index >= count {
+				count = src.index + 1
+			}
+		}
+	}
+	return count
+}
+
+// unusedInputs returns the indices of the raw axes and buttons which are not used by the mapping
+func (m *gamepadMapping) unusedInputs(naxes, nbuttons int) (axes, buttons []int) {
+
+	used := make(map[gamepadSource]bool)
+	for _, sources := range [][]gamepadSource{m.buttons[:], m.axes[:]} {
+		for _, src := range sources {
+			used[gamepadSource{kind: src.kind, index: src.index}] = true
+		}
+	}
+	for a := 0; a < naxes; a++ {
+		if !used[gamepadSource{kind: 'a', index: a}] {
+			axes = append(axes, a)
+		}
+	}
+	for b := 0; b < nbuttons; b++ {
+		if !used[gamepadSource{kind: 'b', index: b}] {
+			buttons = append(buttons, b)
+		}
+	}
+	return axes, buttons
+}
+
+// browserHatAxes returns the raw axes of a browser gamepad, not used by the mapping, which
+// report a hat as a single axis. These axes are above 1 when their hat is centered.
+func (m *gamepadMapping) browserHatAxes(rawAxes []float32) []int {
+
+	if m.hatCount() == 0 {
+		return nil
+	}
+	var hatAxes []int
+	axes, _ := m.unusedInputs(len(rawAxes), 0)
+	for _, a := range axes {
+		if rawAxes[a] > 1.01 {
+			hatAxes = append(hatAxes, a)
+		}
+	}
+	return hatAxes
+}
+
+// browserHats returns the state of the hats used by the mapping, as SDL hat direction bits,
+// from the raw axes and buttons of a browser gamepad which the mapping does not use.
+// Browsers report each hat in one of three ways: as a single axis whose values from -1 to 1
+// are the 8 directions clockwise from up (hatAxes), as a pair of X and Y axes, or as
+// 4 buttons for up, right, down and left. The hats are taken in this order.
+func (m *gamepadMapping) browserHats(rawButtons, rawAxes []float32, hatAxes []int) []int {
+
+	count := m.hatCount()
+	if count == 0 {
+		return nil
+	}
+	hats := make([]int, count)
+	h := 0
+
+	// Single axis hats
+	single := make(map[int]bool)
+	for _, a := range hatAxes {
+		single[a] = true
+		if h == count || a >= len(rawAxes) {
+			continue
+		}
+		if v := rawAxes[a]; v >= -1 && v <= 1 {
+			hats[h] = hatDirections[int(math.Round(float64(v+1)*7/2))%8]
+		}
+		h++
+	}
+
+	// Pairs of X and Y axes
+	axes, buttons := m.unusedInputs(len(rawAxes), len(rawButtons))
+	var pair []int
+	for _, a := range axes {
+		if single[a] {
+			continue
+		}
+		pair = append(pair, a)
+		if len(pair) < 2 {
+			continue
+		}
+		if h == count {
+			break
+		}
+		x, y := rawAxes[pair[0]], rawAxes[pair[1]]
+		switch {
+		case x < -0.5:
+			hats[h] |= 8
+		case x > 0.5:
+			hats[h] |= 2
+		}
+		switch {
+		case y < -0.5:
+			hats[h] |= 1
+		case y > 0.5:
+			hats[h] |= 4
+		}
+		pair = pair[:0]
+		h++
+	}
+
+	// Up, right, down and left buttons
+	for ; h < count && len(buttons) >= 4; h++ {
+		for k, b := range buttons[:4] {
+			if rawButtons[b] > 0.5 {
+				hats[h] |= 1 << uint(k)
+			}
+		}
+		buttons = buttons[4:]
+	}
+	return hats
+}
+
+// browserGamepadIds returns the USB vendor and product ids from the id of a browser gamepad,
+// such as "Name (Vendor: 045e Product: 028e)" or "45e-28e-Name".
+func browserGamepadIds(id string) (vendor, product int, ok bool) {
+
+	hex := func(s string) int {
+		end := 0
+		for end < len(s) && end < 4 && strings.ContainsRune("0123456789abcdefABCDEF", rune(s[end])) {
+			end++
+		}
+		v, err := strconv.ParseInt(s[:end], 16, 32)
+		if err != nil {
+			return -1
+		}
+		return int(v)
+	}
+	if v := strings.Index(id, "Vendor: "); v >= 0 {
+		if p := strings.Index(id, "Product: "); p >= 0 {
+			vendor, product = hex(id[v+8:]), hex(id[p+9:])
+		}
+	} else if parts := strings.SplitN(id, "-", 3); len(parts) == 3 {
+		vendor, product = hex(parts[0]), hex(parts[1])
+	}
+	return vendor, product, vendor > 0 && product > 0
+}
+
+// updateGamepadMappings is called when gamepad mappings are added.
+// The browser uses the mappings for gamepads without the standard mapping.
+func updateGamepadMappings(db string) error {
+
+	return nil
+}
+
 // Canvas returns the associated WebGL WebGlCanvas.
 func (w *WebGlCanvas) Canvas() js.Value {
 
@@ -535,7 +1006,18 @@ func (w *WebGlCanvas) Destroy() {
 	w.canvas.Call("removeEventListener", "mouseup", w.mouseUp)
 	w.canvas.Call("removeEventListener", "mousemove", w.mouseMove)
 	w.canvas.Call("removeEventListener", "wheel", w.mouseWheel)
+	w.canvas.Call("removeEventListener", "touchstart", w.touchStart)
+	w.canvas.Call("removeEventListener", "touchmove", w.touchMove)
+	w.canvas.Call("removeEventListener", "touchend", w.touchEnd)
+	w.canvas.Call("removeEventListener", "touchcancel", w.touchCncl)
+	js.Global().Get("document").Call("removeEventListener", "pointerlockchange", w.lockChange)
+	js.Global().Get("document").Call("removeEventListener", "pointerlockerror", w.lockChange)
 	js.Global().Get("window").Call("removeEventListener", "resize", w.winResize)
+	w.ime.Call("removeEventListener", "input", w.imeInput)
+	w.ime.Call("removeEventListener", "compositionstart", w.compStart)
+	w.ime.Call("removeEventListener", "compositionupdate", w.compUpdate)
+	w.ime.Call("removeEventListener", "compositionend", w.compEnd)
+	w.ime.Get("parentNode").Call("removeChild", w.ime)
 
 	// Release callbacks
 	w.onCtxMenu.Release()
@@ -546,6 +1028,15 @@ func (w *WebGlCanvas) Destroy() {
 	w.mouseMove.Release()
 	w.mouseWheel.Release()
 	w.winResize.Release()
+	w.imeInput.Release()
+	w.compStart.Release()
+	w.compUpdate.Release()
+	w.compEnd.Release()
+	w.touchStart.Release()
+	w.touchMove.Release()
+	w.touchEnd.Release()
+	w.touchCncl.Release()
+	w.lockChange.Release()
 }
 
 // GetFramebufferSize returns the framebuffer size.
@@ -594,6 +1085,67 @@ func (w *WebGlCanvas) DisposeAllCustomCursors() {
 	// TODO
 }
 
+// SetCursorMode sets the cursor mode. CursorDisabled requests the browser to lock the pointer,
+// providing unlimited virtual cursor movement for mouse look controls. Browsers only grant the
+// lock in response to a user action such as a click and release it when Escape is pressed.
+func (w *WebGlCanvas) SetCursorMode(mode CursorMode) {
+
+	if w.pointerLocked() && mode != CursorDisabled {
+		js.Global().Get("document").Call("exitPointerLock")
+	}
+	style := w.canvas.Get("style")
+	switch mode {
+	case CursorNormal:
+		style.Set("cursor", "")
+	case CursorHidden:
+		style.Set("cursor", "none")
+	case CursorDisabled:
+		if !w.pointerLocked() {
+			w.canvas.Call("requestPointerLock")
+		}
+	}
+	w.cursorMode = mode
+}
+
+// CursorMode returns the current cursor mode.
+func (w *WebGlCanvas) CursorMode() CursorMode {
+
+	return w.cursorMode
+}
+
+// pointerLocked returns whether the pointer is locked to the canvas.
+func (w *WebGlCanvas) pointerLocked() bool {
+
+	return wasm.Equal(js.Global().Get("document").Get("pointerLockElement"), w.canvas)
+}
+
+// SetIMEEnabled sets whether the canvas is receiving text input through the input method editor (IME).
+// While enabled the keyboard focus is on a hidden text area which dispatches the
+// OnChar events and the IME composition events.
+func (w *WebGlCanvas) SetIMEEnabled(enabled bool) {
+
+	if enabled {
+		w.ime.Call("focus", map[string]interface{}{"preventScroll": true})
+		return
+	}
+	w.ime.Call("blur")
+	w.ime.Set("value", "")
+	w.composing = false
+}
+
+// SetIMERect sets the rectangle of the text being edited, in the same coordinates as the
+// mouse events, which is used to position the IME candidate window.
+func (w *WebGlCanvas) SetIMERect(x, y, width, height int) {
+
+	rect := w.canvas.Call("getBoundingClientRect")
+	style := w.ime.Get("style")
+	style.Set("left", fmt.Sprintf("%dpx", int(rect.Get("left").Float())+x))
+	style.Set("top", fmt.Sprintf("%dpx", int(rect.Get("top").Float())+y))
+	style.Set("width", fmt.Sprintf("%dpx", width))
+	style.Set("height", fmt.Sprintf("%dpx", height))
+	style.Set("fontSize", fmt.Sprintf("%dpx", height))
+}
+
 // SetInputMode changes specified input to specified state
 //func (w *WebGlCanvas) SetInputMode(mode InputMode, state int) {
 //
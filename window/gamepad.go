@@ -0,0 +1,633 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/g3n/engine/core"
+	"github.com/g3n/engine/math32"
+)
+
+// GamepadButton corresponds to a button of the standard gamepad layout,
+// or to a raw button index for joysticks which are not recognized as gamepads.
+type GamepadButton int
+
+// GamepadAxis corresponds to an axis of the standard gamepad layout,
+// or to a raw axis index for joysticks which are not recognized as gamepads.
+type GamepadAxis int
+
+// Standard gamepad buttons.
+// The face buttons are named after their position in the Xbox layout.
+const (
+	GamepadButtonA = GamepadButton(iota)
+	GamepadButtonB
+	GamepadButtonX
+	GamepadButtonY
+	GamepadButtonLeftBumper
+	GamepadButtonRightBumper
+	GamepadButtonBack
+	GamepadButtonStart
+	GamepadButtonGuide
+	GamepadButtonLeftThumb
+	GamepadButtonRightThumb
+	GamepadButtonDpadUp
+	GamepadButtonDpadRight
+	GamepadButtonDpadDown
+	GamepadButtonDpadLeft
+	GamepadButtonLast = GamepadButtonDpadLeft
+)
+
+// Standard gamepad axes.
+// Stick axes range from -1 to 1, with positive Y down. Trigger axes range from 0 to 1.
+const (
+	GamepadAxisLeftX = GamepadAxis(iota)
+	GamepadAxisLeftY
+	GamepadAxisRightX
+	GamepadAxisRightY
+	GamepadAxisLeftTrigger
+	GamepadAxisRightTrigger
+	GamepadAxisLast = GamepadAxisRightTrigger
+)
+
+// Gamepad event names. Gamepads are polled when the window events are processed.
+const (
+	OnGamepadConnect    = "w.OnGamepadConnect"    // A gamepad or joystick was connected
+	OnGamepadDisconnect = "w.OnGamepadDisconnect" // A gamepad or joystick was disconnected
+	OnGamepadButtonDown = "w.OnGamepadButtonDown" // A gamepad button is pressed
+	OnGamepadButtonUp   = "w.OnGamepadButtonUp"   // A gamepad button is released
+	OnGamepadAxis       = "w.OnGamepadAxis"       // A gamepad axis value changed
+)
+
+// GamepadEvent describes a gamepad connect or disconnect event
+type GamepadEvent struct {
+	Gamepad int    // Gamepad id
+	Name    string // Name of the gamepad
+	Mapped  bool   // Whether the buttons and axes follow the standard gamepad layout
+}
+
+// GamepadButtonEvent describes a gamepad button event
+type GamepadButtonEvent struct {
+	Gamepad int
+	Button  GamepadButton
+}
+
+// GamepadAxisEvent describes a gamepad axis event
+type GamepadAxisEvent struct {
+	Gamepad int
+	Axis    GamepadAxis
+	Value   float32 // Raw value, without dead zone
+}
+
+// gamepadInput keeps the last polled state of a connected gamepad
+type gamepadInput struct {
+	name    string
+	mapped  bool
+	buttons []bool
+	axes    []float32
+}
+
+// gamepadSet keeps the state of the connected gamepads of a window
+// and dispatches the events of their changes.
+type gamepadSet struct {
+	pads     map[int]*gamepadInput
+	padEv    GamepadEvent
+	buttonEv GamepadButtonEvent
+	axisEv   GamepadAxisEvent
+}
+
+// connected returns whether the gamepad with the specified id is connected
+func (gs *gamepadSet) connected(id int) bool {
+
+	_, ok := gs.pads[id]
+	return ok
+}
+
+// connect adds the gamepad with the specified id and dispatches OnGamepadConnect
+func (gs *gamepadSet) connect(d core.IDispatcher, id int, name string, mapped bool) {
+
+	if gs.pads == nil {
+		gs.pads = make(map[int]*gamepadInput)
+	}
+	gs.pads[id] = &gamepadInput{name: name, mapped: mapped}
+	gs.padEv = GamepadEvent{id, name, mapped}
+	d.Dispatch(OnGamepadConnect, &gs.padEv)
+}
+
+// disconnect removes the gamepad with the specified id and dispatches OnGamepadDisconnect
+func (gs *gamepadSet) disconnect(d core.IDispatcher, id int) {
+
+	pad, ok := gs.pads[id]
+	if !ok {
+		return
+	}
+	delete(gs.pads, id)
+	gs.padEv = GamepadEvent{id, pad.name, pad.mapped}
+	d.Dispatch(OnGamepadDisconnect, &gs.padEv)
+}
+
+// update sets the polled state of the gamepad with the specified id and
+// dispatches the events of the buttons and axes which changed
+func (gs *gamepadSet) update(d core.IDispatcher, id int, buttons []bool, axes []float32) {
+
+	pad, ok := gs.pads[id]
+	if !ok {
+		return
+	}
+	for i, pressed := range buttons {
+		if i < len(pad.buttons) && pad.buttons[i] == pressed {
+			continue
+		}
+		if i >= len(pad.buttons) && !pressed {
+			continue
+		}
+		gs.buttonEv = GamepadButtonEvent{id, GamepadButton(i)}
+		if pressed {
+			d.Dispatch(OnGamepadButtonDown, &gs.buttonEv)
+		} else {
+			d.Dispatch(OnGamepadButtonUp, &gs.buttonEv)
+		}
+	}
+	for i, value := range axes {
+		if i < len(pad.axes) && pad.axes[i] == value {
+			continue
+		}
+		gs.axisEv = GamepadAxisEvent{id, GamepadAxis(i), value}
+		d.Dispatch(OnGamepadAxis, &gs.axisEv)
+	}
+	pad.buttons = append(pad.buttons[:0], buttons...)
+	pad.axes = append(pad.axes[:0], axes...)
+}
+
+// gamepadSource is an input of a joystick which is mapped to a standard gamepad button or axis
+type gamepadSource struct {
+	kind   byte // 0 (unmapped), 'b' (button), 'a' (axis) or 'h' (hat)
+	index  int  // Button, axis or hat index
+	hatBit int  // Hat direction bit
+	half   int  // Half of the input axis which is used (-1, 0 or 1)
+	invert bool // Input axis is inverted
+	output int  // Half of the output axis which is set (-1, 0 or 1)
+}
+
+// gamepadMapping maps the inputs of a joystick to the standard gamepad layout
+type gamepadMapping struct {
+	guid    string
+	name    string
+	buttons [GamepadButtonLast + 1]gamepadSource
+	axes    [GamepadAxisLast + 1]gamepadSource
+}
+
+// gamepadMappings contains the mappings added by AddGamepadMappings indexed
+// by the GUID and by the vendor and product ids of the joystick
+var gamepadMappings = map[string]*gamepadMapping{}
+
+// gamepadTargets maps the names of the SDL gamepad buttons and axes to their
+// standard buttons (positive values) or axes (negative values minus one)
+var gamepadTargets = map[string]int{
+	"a":             int(GamepadButtonA),
+	"b":             int(GamepadButtonB),
+	"x":             int(GamepadButtonX),
+	"y":             int(GamepadButtonY),
+	"leftshoulder":  int(GamepadButtonLeftBumper),
+	"rightshoulder": int(GamepadButtonRightBumper),
+	"back":          int(GamepadButtonBack),
+	"start":         int(GamepadButtonStart),
+	"guide":         int(GamepadButtonGuide),
+	"leftstick":     int(GamepadButtonLeftThumb),
+	"rightstick":    int(GamepadButtonRightThumb),
+	"dpup":          int(GamepadButtonDpadUp),
+	"dpright":       int(GamepadButtonDpadRight),
+	"dpdown":        int(GamepadButtonDpadDown),
+	"dpleft":        int(GamepadButtonDpadLeft),
+	"leftx":         -int(GamepadAxisLeftX) - 1,
+	"lefty":         -int(GamepadAxisLeftY) - 1,
+	"rightx":        -int(GamepadAxisRightX) - 1,
+	"righty":        -int(GamepadAxisRightY) - 1,
+	"lefttrigger":   -int(GamepadAxisLeftTrigger) - 1,
+	"righttrigger":  -int(GamepadAxisRightTrigger) - 1,
+}
+
+// AddGamepadMappings adds gamepad mappings in the SDL game controller database format,
+// one per line, such as the lines of the gamecontrollerdb.txt community database:
+//
+//	030000005e0400008e02000000007801,Xbox 360 Controller,a:b0,b:b1,x:b2,...,platform:Linux,
+//
+// Mappings replace previous mappings with the same GUID. Empty lines and lines starting
+// with # are ignored. Joysticks with a mapping are reported as gamepads with
+// the standard layout of buttons and axes.
+func AddGamepadMappings(db string) error {
+
+	for n, line := range strings.Split(db, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		m, err := parseGamepadMapping(line)
+		if err != nil {
+			return fmt.Errorf("gamepad mapping line %d: %v", n+1, err)
+		}
+		gamepadMappings[m.guid] = m
+		if vendor, product, ok := gamepadGUIDIds(m.guid); ok {
+			gamepadMappings[gamepadIdsKey(vendor, product)] = m
+		}
+	}
+	return updateGamepadMappings(db)
+}
+
+// parseGamepadMapping parses a mapping line in the SDL game controller database format
+func parseGamepadMapping(line string) (*gamepadMapping, error) {
+
+	fields := strings.Split(line, ",")
+	if len(fields) < 2 || len(fields[0]) != 32 {
+		return nil, fmt.Errorf("invalid GUID")
+	}
+	m := &gamepadMapping{guid: strings.ToLower(fields[0]), name: fields[1]}
+	for _, field := range fields[2:] {
+		parts := strings.SplitN(field, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		name, value := parts[0], parts[1]
+		output := 0
+		if len(name) > 0 && (name[0] == '+' || name[0] == '-') {
+			output = 1
+			if name[0] == '-' {
+				output = -1
+			}
+			name = name[1:]
+		}
+		target, ok := gamepadTargets[name]
+		if !ok {
+			continue // Platform and unsupported buttons
+		}
+		src, err := parseGamepadSource(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", field, err)
+		}
+		src.output = output
+		if target >= 0 {
+			m.buttons[target] = src
+		} else {
+			m.axes[-target-1] = src
+		}
+	}
+	return m, nil
+}
+
+// parseGamepadSource parses a joystick input of a mapping such as b1, a2, +a3, a4~ or h0.4
+func parseGamepadSource(s string) (gamepadSource, error) {
+
+	var src gamepadSource
+	if strings.HasPrefix(s, "+") {
+		src.half = 1
+		s = s[1:]
+	} else if strings.HasPrefix(s, "-") {
+		src.half = -1
+		s = s[1:]
+	}
+	if strings.HasSuffix(s, "~") {
+		src.invert = true
+		s = s[:len(s)-1]
+	}
+	if len(s) < 2 || (s[0] != 'a' && s[0] != 'b' && s[0] != 'h') {
+		return src, fmt.Errorf("invalid input")
+	}
+	src.kind = s[0]
+	var err error
+	if src.kind == 'h' {
+		parts := strings.SplitN(s[1:], ".", 2)
+		if len(parts) != 2 {
+			return src, fmt.Errorf("invalid hat")
+		}
+		if src.index, err = strconv.Atoi(parts[0]); err != nil {
+			return src, err
+		}
+		src.hatBit, err = strconv.Atoi(parts[1])
+		return src, err
+	}
+	src.index, err = strconv.Atoi(s[1:])
+	return src, err
+}
+
+// value returns the value of the source input from the raw joystick inputs
+// in the range [-1,1] for axes and [0,1] for buttons and hats.
+func (src *gamepadSource) value(buttons []float32, axes []float32, hats []int) float32 {
+
+	var v float32
+	switch src.kind {
+	case 'b':
+		if src.index < len(buttons) {
+			v = buttons[src.index]
+		}
+	case 'h':
+		if src.index < len(hats) && hats[src.index]&src.hatBit != 0 {
+			v = 1
+		}
+	case 'a':
+		if src.index < len(axes) {
+			v = axes[src.index]
+		}
+		if src.invert {
+			v = -v
+		}
+		switch src.half {
+		case 1:
+			v = clampAxis(v)
+		case -1:
+			v = clampAxis(-v)
+		}
+	}
+	return v
+}
+
+// apply returns the standard buttons and axes from the raw joystick inputs
+func (m *gamepadMapping) apply(rawButtons []float32, rawAxes []float32, hats []int) ([]bool, []float32) {
+
+	buttons := make([]bool, GamepadButtonLast+1)
+	for i := range m.buttons {
+		src := &m.buttons[i]
+		if src.kind == 0 {
+			continue
+		}
+		v := src.value(rawButtons, rawAxes, hats)
+		if src.kind == 'a' && src.half == 0 {
+			v = (v + 1) / 2 // Full axis used as button
+		}
+		buttons[i] = v > 0.5
+	}
+	axes := make([]float32, GamepadAxisLast+1)
+	for i := range m.axes {
+		src := &m.axes[i]
+		if src.kind == 0 {
+			continue
+		}
+		v := src.value(rawButtons, rawAxes, hats)
+		switch {
+		case src.output != 0:
+			v = float32(src.output) * clampAxis(v)
+		case GamepadAxis(i) >= GamepadAxisLeftTrigger && src.kind == 'a' && src.half == 0:
+			v = (v + 1) / 2 // Triggers rest at -1
+		}
+		axes[i] = v
+	}
+	return buttons, axes
+}
+
+// clampAxis returns the specified value clamped to the range [0,1]
+func clampAxis(v float32) float32 {
+
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// gamepadGUIDIds returns the USB vendor and product ids encoded in a SDL joystick GUID
+func gamepadGUIDIds(guid string) (vendor, product int, ok bool) {
+
+	word := func(pos int) int {
+		v, err := strconv.ParseUint(guid[pos:pos+4], 16, 16)
+		if err != nil {
+			return -1
+		}
+		return int(v>>8 | (v&0xFF)<<8) // Little endian
+	}
+	// Old DirectInput GUIDs with the vendor and product first followed by "PIDVID"
+	if strings.HasSuffix(guid, "504944564944") {
+		vendor, product = word(0), word(4)
+	} else {
+		vendor, product = word(8), word(16)
+	}
+	return vendor, product, vendor > 0 && product > 0
+}
+
+// gamepadIdsKey returns the key of the mappings indexed by vendor and product ids
+func gamepadIdsKey(vendor, product int) string {
+
+	return fmt.Sprintf("%04x:%04x", vendor, product)
+}
+
+// GamepadState keeps track of the state of the connected gamepads.
+type GamepadState struct {
+	win      core.IDispatcher
+	pads     map[int]*gamepadInput
+	deadZone float32
+}
+
+// DefaultGamepadDeadZone is the default dead zone of the gamepad sticks and triggers
+const DefaultGamepadDeadZone = 0.15
+
+// NewGamepadState returns a new GamepadState object.
+func NewGamepadState(win core.IDispatcher) *GamepadState {
+
+	gs := new(GamepadState)
+	gs.win = win
+	gs.pads = make(map[int]*gamepadInput)
+	gs.deadZone = DefaultGamepadDeadZone
+
+	// Subscribe to window gamepad events
+	gs.win.SubscribeID(OnGamepadConnect, &gs, gs.onGamepad)
+	gs.win.SubscribeID(OnGamepadDisconnect, &gs, gs.onGamepad)
+	gs.win.SubscribeID(OnGamepadButtonDown, &gs, gs.onButton)
+	gs.win.SubscribeID(OnGamepadButtonUp, &gs, gs.onButton)
+	gs.win.SubscribeID(OnGamepadAxis, &gs, gs.onAxis)
+
+	return gs
+}
+
+// Dispose unsubscribes from the window events.
+func (gs *GamepadState) Dispose() {
+
+	gs.win.UnsubscribeID(OnGamepadConnect, &gs)
+	gs.win.UnsubscribeID(OnGamepadDisconnect, &gs)
+	gs.win.UnsubscribeID(OnGamepadButtonDown, &gs)
+	gs.win.UnsubscribeID(OnGamepadButtonUp, &gs)
+	gs.win.UnsubscribeID(OnGamepadAxis, &gs)
+}
+
+// SetDeadZone sets the dead zone of the sticks and triggers, from 0 to 1.
+// Stick positions whose distance from the center is less than the dead zone are
+// reported as centered and the remaining range is rescaled to start at zero.
+func (gs *GamepadState) SetDeadZone(deadZone float32) {
+
+	gs.deadZone = clampAxis(deadZone)
+}
+
+// DeadZone returns the dead zone of the sticks and triggers.
+func (gs *GamepadState) DeadZone() float32 {
+
+	return gs.deadZone
+}
+
+// Gamepads returns the ids of the connected gamepads.
+func (gs *GamepadState) Gamepads() []int {
+
+	ids := make([]int, 0, len(gs.pads))
+	for id := range gs.pads {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Connected returns whether the specified gamepad is connected.
+func (gs *GamepadState) Connected(gamepad int) bool {
+
+	_, ok := gs.pads[gamepad]
+	return ok
+}
+
+// Name returns the name of the specified gamepad.
+func (gs *GamepadState) Name(gamepad int) string {
+
+	if pad, ok := gs.pads[gamepad]; ok {
+		return pad.name
+	}
+	return ""
+}
+
+// Mapped returns whether the buttons and axes of the specified gamepad
+// follow the standard gamepad layout.
+func (gs *GamepadState) Mapped(gamepad int) bool {
+
+	if pad, ok := gs.pads[gamepad]; ok {
+		return pad.mapped
+	}
+	return false
+}
+
+// Pressed returns whether the specified button of the specified gamepad is currently pressed.
+func (gs *GamepadState) Pressed(gamepad int, b GamepadButton) bool {
+
+	pad, ok := gs.pads[gamepad]
+	if !ok || int(b) < 0 || int(b) >= len(pad.buttons) {
+		return false
+	}
+	return pad.buttons[b]
+}
+
+// Axis returns the value of the specified axis of the specified gamepad with the dead zone applied.
+// The stick axes are filtered with a radial dead zone together with the other axis of the stick.
+func (gs *GamepadState) Axis(gamepad int, a GamepadAxis) float32 {
+
+	pad, ok := gs.pads[gamepad]
+	if !ok || !pad.mapped {
+		return gs.RawAxis(gamepad, a)
+	}
+	switch a {
+	case GamepadAxisLeftX, GamepadAxisLeftY:
+		x, y := gs.LeftStick(gamepad)
+		if a == GamepadAxisLeftX {
+			return x
+		}
+		return y
+	case GamepadAxisRightX, GamepadAxisRightY:
+		x, y := gs.RightStick(gamepad)
+		if a == GamepadAxisRightX {
+			return x
+		}
+		return y
+	}
+	return gs.applyDeadZone(gs.RawAxis(gamepad, a))
+}
+
+// RawAxis returns the value of the specified axis of the specified gamepad without dead zone.
+func (gs *GamepadState) RawAxis(gamepad int, a GamepadAxis) float32 {
+
+	pad, ok := gs.pads[gamepad]
+	if !ok || int(a) < 0 || int(a) >= len(pad.axes) {
+		return 0
+	}
+	return pad.axes[a]
+}
+
+// LeftStick returns the position of the left stick of the specified gamepad with the dead zone applied.
+func (gs *GamepadState) LeftStick(gamepad int) (x, y float32) {
+
+	return gs.stick(gamepad, GamepadAxisLeftX, GamepadAxisLeftY)
+}
+
+// RightStick returns the position of the right stick of the specified gamepad with the dead zone applied.
+func (gs *GamepadState) RightStick(gamepad int) (x, y float32) {
+
+	return gs.stick(gamepad, GamepadAxisRightX, GamepadAxisRightY)
+}
+
+// stick returns the position of a stick with a radial dead zone
+func (gs *GamepadState) stick(gamepad int, ax, ay GamepadAxis) (float32, float32) {
+
+	x, y := gs.RawAxis(gamepad, ax), gs.RawAxis(gamepad, ay)
+	length := float32(0)
+	if x != 0 || y != 0 {
+		length = math32.Sqrt(x*x + y*y)
+	}
+	scaled := gs.applyDeadZone(length)
+	if scaled == 0 {
+		return 0, 0
+	}
+	return x * scaled / length, y * scaled / length
+}
+
+// applyDeadZone returns the magnitude of an input rescaled from the dead zone to 1
+func (gs *GamepadState) applyDeadZone(v float32) float32 {
+
+	sign := float32(1)
+	if v < 0 {
+		sign, v = -1, -v
+	}
+	if v <= gs.deadZone {
+		return 0
+	}
+	if gs.deadZone >= 1 {
+		return 0
+	}
+	return sign * clampAxis((v-gs.deadZone)/(1-gs.deadZone))
+}
+
+// onGamepad receives gamepad connect and disconnect events
+func (gs *GamepadState) onGamepad(evname string, ev interface{}) {
+
+	gev := ev.(*GamepadEvent)
+	switch evname {
+	case OnGamepadConnect:
+		gs.pads[gev.Gamepad] = &gamepadInput{name: gev.Name, mapped: gev.Mapped}
+	case OnGamepadDisconnect:
+		delete(gs.pads, gev.Gamepad)
+	}
+}
+
+// onButton receives gamepad button events and updates the state of the gamepad buttons
+func (gs *GamepadState) onButton(evname string, ev interface{}) {
+
+	bev := ev.(*GamepadButtonEvent)
+	pad, ok := gs.pads[bev.Gamepad]
+	if !ok || bev.Button < 0 {
+		return
+	}
+	for int(bev.Button) >= len(pad.buttons) {
+		pad.buttons = append(pad.buttons, false)
+	}
+	pad.buttons[bev.Button] = evname == OnGamepadButtonDown
+}
+
+// onAxis receives gamepad axis events and updates the state of the gamepad axes
+func (gs *GamepadState) onAxis(evname string, ev interface{}) {
+
+	aev := ev.(*GamepadAxisEvent)
+	pad, ok := gs.pads[aev.Gamepad]
+	if !ok || aev.Axis < 0 {
+		return
+	}
+	for int(aev.Axis) >= len(pad.axes) {
+		pad.axes = append(pad.axes, 0)
+	}
+	pad.axes[aev.Axis] = aev.Value
+}
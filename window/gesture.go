@@ -0,0 +1,318 @@
+// Copyright 2016 The G3N Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package window
+
+import (
+	"math"
+	"time"
+
+	"github.com/g3n/engine/core"
+)
+
+// Gesture event names dispatched by the GestureRecognizer
+const (
+	OnTap       = "w.OnTap"       // Single finger tap
+	OnLongPress = "w.OnLongPress" // Single finger held without moving
+	OnDrag      = "w.OnDrag"      // Single finger drag
+	OnPinch     = "w.OnPinch"     // Two finger pinch
+	OnRotate    = "w.OnRotate"    // Two finger rotation
+	OnPan       = "w.OnPan"       // Two finger pan
+)
+
+// GesturePhase indicates whether a continuous gesture began, changed or ended
+type GesturePhase int
+
+// Gesture phases
+const (
+	GestureBegin = GesturePhase(iota)
+	GestureChange
+	GestureEnd
+)
+
+// Default gesture recognizer parameters
+const (
+	DefaultTapSlop        = 10                     // Maximum movement in pixels of a tap or long press
+	DefaultLongPressDelay = 500 * time.Millisecond // Time a touch must be held to be a long press
+)
+
+// GestureEvent describes a gesture recognized from touch events.
+// The position is the touch point of single finger gestures or the middle point
+// between the fingers of two finger gestures. The deltas are relative to the previous
+// event of the same gesture, so they can be applied directly to controls.
+type GestureEvent struct {
+	Phase    GesturePhase
+	Xpos     float32
+	Ypos     float32
+	DX       float32 // Horizontal movement of the position for OnDrag and OnPan
+	DY       float32 // Vertical movement of the position for OnDrag and OnPan
+	Scale    float32 // Ratio of the distance between the fingers to the previous distance for OnPinch
+	Rotation float32 // Angle in radians of the fingers relative to the previous angle for OnRotate
+}
+
+// GestureRecognizer recognizes taps, long presses, drags and two finger pinch,
+// rotate and pan gestures from the touch events of a window and dispatches them as
+// gesture events. Long presses are detected by the recognizer timers,
+// so ProcessTimers must be called periodically for them to be dispatched.
+type GestureRecognizer struct {
+	core.Dispatcher                    // Embedded event dispatcher
+	core.TimerManager                  // Embedded timer manager
+	win               core.IDispatcher // Dispatcher of the touch events
+	tapSlop           float32          // Maximum movement of a tap or long press
+	longPressDelay    time.Duration    // Time a touch must be held to be a long press
+	ev                GestureEvent     // Dispatched event
+
+	// Single finger state
+	single   bool      // Single finger gesture in progress
+	tap      bool      // Single finger gesture may still be a tap or long press
+	dragging bool      // Drag began
+	start    Touch     // Initial touch point
+	last     Touch     // Last touch point
+	timer    int       // Long press timer id or zero
+	begin    time.Time // Time of the initial touch
+
+	// Two finger state
+	double   bool    // Two finger gesture in progress
+	twoBegun bool    // Two finger gestures began
+	dist     float32 // Last distance between the fingers
+	angle    float32 // Last angle of the fingers
+	cx, cy   float32 // Last middle point between the fingers
+}
+
+// NewGestureRecognizer creates and returns a pointer to a new gesture recognizer
+// for the touch events dispatched by the specified window dispatcher.
+func NewGestureRecognizer(win core.IDispatcher) *GestureRecognizer {
+
+	gr := new(GestureRecognizer)
+	gr.Dispatcher.Initialize()
+	gr.TimerManager.Initialize()
+	gr.win = win
+	gr.tapSlop = DefaultTapSlop
+	gr.longPressDelay = DefaultLongPressDelay
+	win.SubscribeID(OnTouchStart, gr, gr.onTouch)
+	win.SubscribeID(OnTouchMove, gr, gr.onTouch)
+	win.SubscribeID(OnTouchEnd, gr, gr.onTouch)
+	win.SubscribeID(OnTouchCancel, gr, gr.onTouch)
+	return gr
+}
+
+// Dispose unsubscribes from the window touch events.
+func (gr *GestureRecognizer) Dispose() {
+
+	gr.win.UnsubscribeID(OnTouchStart, gr)
+	gr.win.UnsubscribeID(OnTouchMove, gr)
+	gr.win.UnsubscribeID(OnTouchEnd, gr)
+	gr.win.UnsubscribeID(OnTouchCancel, gr)
+	gr.clearTimer()
+}
+
+// SetTapSlop sets the maximum distance in pixels a touch can move and still be a tap or long press.
+func (gr *GestureRecognizer) SetTapSlop(slop float32) {
+
+	gr.tapSlop = slop
+}
+
+// TapSlop returns the maximum distance in pixels a touch can move and still be a tap or long press.
+func (gr *GestureRecognizer) TapSlop() float32 {
+
+	return gr.tapSlop
+}
+
+// SetLongPressDelay sets the time a touch must be held to be a long press.
+func (gr *GestureRecognizer) SetLongPressDelay(delay time.Duration) {
+
+	gr.longPressDelay = delay
+}
+
+// LongPressDelay returns the time a touch must be held to be a long press.
+func (gr *GestureRecognizer) LongPressDelay() time.Duration {
+
+	return gr.longPressDelay
+}
+
+// onTouch is called when a touch event is received.
+func (gr *GestureRecognizer) onTouch(evname string, ev interface{}) {
+
+	tev := ev.(*TouchEvent)
+	count := len(tev.Touches)
+	switch evname {
+	case OnTouchStart:
+		if count == 1 && !gr.single && !gr.double {
+			gr.startSingle(tev.Touches[0])
+		} else if count >= 2 && !gr.double {
+			gr.endSingle(false)
+			gr.startDouble(tev.Touches[0], tev.Touches[1])
+		}
+	case OnTouchMove:
+		if gr.single && count == 1 {
+			gr.moveSingle(tev.Touches[0])
+		} else if gr.double && count >= 2 {
+			gr.moveDouble(tev.Touches[0], tev.Touches[1])
+		}
+	case OnTouchEnd, OnTouchCancel:
+		if gr.double && count < 2 {
+			gr.endDouble()
+		}
+		if count == 0 {
+			gr.endSingle(evname == OnTouchEnd)
+		}
+	}
+}
+
+// startSingle starts a single finger gesture with the specified touch point.
+func (gr *GestureRecognizer) startSingle(t Touch) {
+
+	gr.single = true
+	gr.tap = true
+	gr.dragging = false
+	gr.start = t
+	gr.last = t
+	gr.begin = time.Now()
+	gr.clearTimer()
+	gr.timer = gr.SetTimeout(gr.longPressDelay, nil, gr.onLongPress)
+}
+
+// moveSingle updates the single finger gesture with the specified touch point.
+func (gr *GestureRecognizer) moveSingle(t Touch) {
+
+	if gr.tap {
+		if distance(t.Xpos-gr.start.Xpos, t.Ypos-gr.start.Ypos) <= gr.tapSlop {
+			return
+		}
+		gr.tap = false
+		gr.clearTimer()
+	}
+	phase := GestureChange
+	if !gr.dragging {
+		gr.dragging = true
+		phase = GestureBegin
+	}
+	gr.reset(phase, t.Xpos, t.Ypos)
+	gr.ev.DX = t.Xpos - gr.last.Xpos
+	gr.ev.DY = t.Ypos - gr.last.Ypos
+	gr.last = t
+	gr.Dispatch(OnDrag, &gr.ev)
+}
+
+// endSingle ends the single finger gesture dispatching a tap if
+// the gesture is still a tap and the specified flag is true.
+func (gr *GestureRecognizer) endSingle(tap bool) {
+
+	if !gr.single {
+		return
+	}
+	gr.clearTimer()
+	if gr.dragging {
+		gr.reset(GestureEnd, gr.last.Xpos, gr.last.Ypos)
+		gr.Dispatch(OnDrag, &gr.ev)
+	} else if gr.tap && tap && time.Since(gr.begin) < gr.longPressDelay {
+		gr.reset(GestureEnd, gr.start.Xpos, gr.start.Ypos)
+		gr.Dispatch(OnTap, &gr.ev)
+	}
+	gr.single = false
+	gr.tap = false
+	gr.dragging = false
+}
+
+// onLongPress is called by the long press timer.
+func (gr *GestureRecognizer) onLongPress(arg interface{}) {
+
+	gr.timer = 0
+	if !gr.single || !gr.tap {
+		return
+	}
+	gr.tap = false
+	gr.reset(GestureBegin, gr.start.Xpos, gr.start.Ypos)
+	gr.Dispatch(OnLongPress, &gr.ev)
+}
+
+// startDouble starts a two finger gesture with the specified touch points.
+func (gr *GestureRecognizer) startDouble(t0, t1 Touch) {
+
+	gr.double = true
+	gr.twoBegun = false
+	gr.dist, gr.angle, gr.cx, gr.cy = fingers(t0, t1)
+}
+
+// moveDouble updates the two finger gesture with the specified touch points
+// dispatching the pinch, rotate and pan events.
+func (gr *GestureRecognizer) moveDouble(t0, t1 Touch) {
+
+	dist, angle, cx, cy := fingers(t0, t1)
+	phase := GestureChange
+	if !gr.twoBegun {
+		gr.twoBegun = true
+		phase = GestureBegin
+	}
+
+	gr.reset(phase, cx, cy)
+	if gr.dist > 0 {
+		gr.ev.Scale = dist / gr.dist
+	}
+	gr.Dispatch(OnPinch, &gr.ev)
+
+	gr.reset(phase, cx, cy)
+	rot := angle - gr.angle
+	if rot > math.Pi {
+		rot -= 2 * math.Pi
+	} else if rot < -math.Pi {
+		rot += 2 * math.Pi
+	}
+	gr.ev.Rotation = rot
+	gr.Dispatch(OnRotate, &gr.ev)
+
+	gr.reset(phase, cx, cy)
+	gr.ev.DX = cx - gr.cx
+	gr.ev.DY = cy - gr.cy
+	gr.Dispatch(OnPan, &gr.ev)
+
+	gr.dist, gr.angle, gr.cx, gr.cy = dist, angle, cx, cy
+}
+
+// endDouble ends the two finger gesture. The remaining finger, if any,
+// does not start a new gesture until all the fingers are lifted.
+func (gr *GestureRecognizer) endDouble() {
+
+	if gr.twoBegun {
+		for _, evname := range []string{OnPinch, OnRotate, OnPan} {
+			gr.reset(GestureEnd, gr.cx, gr.cy)
+			gr.Dispatch(evname, &gr.ev)
+		}
+	}
+	gr.double = false
+	gr.twoBegun = false
+}
+
+// reset resets the dispatched gesture event with the specified phase and position.
+func (gr *GestureRecognizer) reset(phase GesturePhase, x, y float32) {
+
+	gr.ev = GestureEvent{Phase: phase, Xpos: x, Ypos: y, Scale: 1}
+}
+
+// clearTimer clears the long press timer if set.
+func (gr *GestureRecognizer) clearTimer() {
+
+	if gr.timer != 0 {
+		gr.ClearTimeout(gr.timer)
+		gr.timer = 0
+	}
+}
+
+// fingers returns the distance, the angle and the middle point of the specified touch points.
+func fingers(t0, t1 Touch) (dist, angle, cx, cy float32) {
+
+	dx := t1.Xpos - t0.Xpos
+	dy := t1.Ypos - t0.Ypos
+	dist = distance(dx, dy)
+	angle = float32(math.Atan2(float64(dy), float64(dx)))
+	cx = (t0.Xpos + t1.Xpos) / 2
+	cy = (t0.Ypos + t1.Ypos) / 2
+	return
+}
+
+// distance returns the length of the specified vector.
+func distance(dx, dy float32) float32 {
+
+	return float32(math.Sqrt(float64(dx*dx + dy*dy)))
+}
@@ -208,6 +208,12 @@ type GlfwWindow struct {
 
 	mods ModifierKey // Current modifier keys
 
+	// Gamepads
+	gamepads       gamepadSet
+	gamepadsPolled bool      // Whether the joysticks connected at startup were added
+	padButtons     []bool    // Polled buttons buffer
+	padAxes        []float32 // Polled axes buffer
+
 	// Cursors
 	cursors       map[Cursor]*glfw.Cursor
 	lastCursorKey Cursor
@@ -361,6 +367,15 @@ func Init(width, height int, title string) error {
 		w.Dispatch(OnScroll, &w.scrollEv)
 	})
 
+	// Set up joystick callback to dispatch gamepad connect and disconnect events
+	glfw.SetJoystickCallback(func(joy glfw.Joystick, event glfw.PeripheralEvent) {
+		if event == glfw.Connected {
+			w.connectJoystick(joy)
+		} else if event == glfw.Disconnected {
+			w.gamepads.disconnect(w, int(joy))
+		}
+	})
+
 	win = w // Set singleton
 	return nil
 }
@@ -431,6 +446,64 @@ func (w *GlfwWindow) ScreenResolution(p interface{}) (width, height int) {
 func (w *GlfwWindow) PollEvents() {
 
 	glfw.PollEvents()
+	w.pollGamepads()
+}
+
+// connectJoystick adds the specified joystick to the connected gamepads
+func (w *GlfwWindow) connectJoystick(joy glfw.Joystick) {
+
+	if w.gamepads.connected(int(joy)) {
+		return
+	}
+	if joy.IsGamepad() {
+		w.gamepads.connect(w, int(joy), joy.GetGamepadName(), true)
+	} else {
+		w.gamepads.connect(w, int(joy), joy.GetName(), false)
+	}
+}
+
+// pollGamepads polls the state of the connected gamepads and joysticks and dispatches their events.
+// Gamepads use the standard layout from the GLFW gamepad mappings and
+// other joysticks report their raw buttons and axes.
+func (w *GlfwWindow) pollGamepads() {
+
+	// Joysticks connected before the first poll don't generate callbacks
+	if !w.gamepadsPolled {
+		w.gamepadsPolled = true
+		for joy := glfw.Joystick1; joy <= glfw.JoystickLast; joy++ {
+			if joy.Present() {
+				w.connectJoystick(joy)
+			}
+		}
+	}
+
+	for id, pad := range w.gamepads.pads {
+		joy := glfw.Joystick(id)
+		w.padButtons = w.padButtons[:0]
+		w.padAxes = w.padAxes[:0]
+		if pad.mapped {
+			state := joy.GetGamepadState()
+			if state == nil {
+				continue
+			}
+			for b := glfw.ButtonA; b <= glfw.ButtonLast; b++ {
+				w.padButtons = append(w.padButtons, state.Buttons[b] == glfw.Press)
+			}
+			for a := glfw.AxisLeftX; a <= glfw.AxisLast; a++ {
+				v := state.Axes[a]
+				if a == glfw.AxisLeftTrigger || a == glfw.AxisRightTrigger {
+					v = (v + 1) / 2 // GLFW triggers rest at -1
+				}
+				w.padAxes = append(w.padAxes, v)
+			}
+		} else {
+			for _, action := range joy.GetButtons() {
+				w.padButtons = append(w.padButtons, action == glfw.Press)
+			}
+			w.padAxes = append(w.padAxes, joy.GetAxes()...)
+		}
+		w.gamepads.update(w, id, w.padButtons, w.padAxes)
+	}
 }
 
 // SetSwapInterval sets the number of screen updates to wait from the time SwapBuffer()
@@ -495,6 +568,28 @@ func (w *GlfwWindow) DisposeAllCustomCursors() {
 	w.lastCursorKey = CursorLast
 }
 
+// SetCursorMode sets the cursor mode. CursorDisabled hides and captures the cursor,
+// providing unlimited virtual cursor movement for mouse look controls.
+func (w *GlfwWindow) SetCursorMode(mode CursorMode) {
+
+	w.SetInputMode(glfw.CursorMode, int(mode))
+}
+
+// CursorMode returns the current cursor mode.
+func (w *GlfwWindow) CursorMode() CursorMode {
+
+	return CursorMode(w.GetInputMode(glfw.CursorMode))
+}
+
+// updateGamepadMappings adds mappings in the SDL game controller database format to GLFW.
+func updateGamepadMappings(db string) error {
+
+	if !glfw.UpdateGamepadMappings(db) {
+		return fmt.Errorf("invalid gamepad mappings")
+	}
+	return nil
+}
+
 // Center centers the window on the screen.
 //func (w *GlfwWindow) Center() {
 //
@@ -38,9 +38,22 @@ type IWindow interface {
 	CreateCursor(imgFile string, xhot, yhot int) (Cursor, error)
 	SetCursor(cursor Cursor)
 	DisposeAllCustomCursors()
+	SetCursorMode(mode CursorMode)
+	CursorMode() CursorMode
 	Destroy()
 }
 
+// IIMEWindow is the interface for the windows which receive text through the input
+// method editor (IME) and dispatch its composition events. Only the browser canvas
+// implements it: GLFW 3.3 doesn't expose the IME composition, so on the desktop the
+// platform IME shows the composition text in its own window and the committed text
+// is received as OnChar events.
+type IIMEWindow interface {
+	IWindow
+	SetIMEEnabled(enabled bool)
+	SetIMERect(x, y, width, height int)
+}
+
 // Key corresponds to a keyboard key.
 type Key int
 
@@ -84,6 +97,15 @@ const ( //                             Desktop | Browser |
 	OnMouseUp    = "w.OnMouseUp"    //    x    |    x    |
 	OnMouseDown  = "w.OnMouseDown"  //    x    |    x    |
 	OnScroll     = "w.OnScroll"     //    x    |    x    |
+
+	OnCompositionStart  = "w.OnCompositionStart"  //         |    x    |
+	OnCompositionUpdate = "w.OnCompositionUpdate" //         |    x    |
+	OnCompositionEnd    = "w.OnCompositionEnd"    //         |    x    |
+
+	OnTouchStart  = "w.OnTouchStart"  //         |    x    |
+	OnTouchMove   = "w.OnTouchMove"   //         |    x    |
+	OnTouchEnd    = "w.OnTouchEnd"    //         |    x    |
+	OnTouchCancel = "w.OnTouchCancel" //         |    x    |
 )
 
 // PosEvent describes a windows position changed event
@@ -131,3 +153,27 @@ type ScrollEvent struct {
 	Yoffset float32
 	Mods    ModifierKey
 }
+
+// CompositionEvent describes an input method editor (IME) composition event.
+// The composition (pre-edit) text is not yet part of the edited text: it is replaced
+// on each OnCompositionUpdate event and is committed or discarded by OnCompositionEnd.
+// The committed text is also sent as OnChar events.
+type CompositionEvent struct {
+	Text   string // Current composition text or committed text for OnCompositionEnd
+	Cursor int    // Byte offset of the caret in the composition text
+}
+
+// Touch describes a touch point over the window
+type Touch struct {
+	ID   int // Identifier of the touch point, unique while the touch point is active
+	Xpos float32
+	Ypos float32
+}
+
+// TouchEvent describes a touch event over the window.
+// The taps of single touches are also reported as mouse events by the browsers.
+type TouchEvent struct {
+	Touches []Touch // Touch points currently on the surface (not including the ended ones)
+	Changed []Touch // Touch points which started, moved or ended with this event
+	Mods    ModifierKey
+}